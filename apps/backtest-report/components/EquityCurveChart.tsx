@@ -0,0 +1,122 @@
+import React, {useEffect, useRef} from 'react';
+import {tsvParse} from "d3-dsv";
+import {createChart, CrosshairMode} from 'lightweight-charts';
+import {Title} from '@mantine/core';
+
+import {SymbolReport} from "../types";
+
+interface EquityCurveEntry {
+  time: Date;
+  equity: number;
+  drawdown: number;
+  rolling_sharpe: number;
+}
+
+const parseEquityCurve = () => {
+  return (d: any) => {
+    d.time = new Date(d.time);
+    d.equity = +d.equity;
+    d.drawdown = +d.drawdown;
+    d.rolling_sharpe = +d.rolling_sharpe;
+    return d;
+  };
+};
+
+const fetchEquityCurve = (basePath: string, runID: string, filename: string) => {
+  return fetch(
+    `${basePath}/${runID}/${filename}`,
+  )
+    .then((response) => response.text())
+    .then((data) => tsvParse(data, parseEquityCurve()) as Array<EquityCurveEntry>)
+    .catch((e) => {
+      console.error("failed to fetch equity curve", e)
+    });
+};
+
+const toLineData = (entries: Array<EquityCurveEntry>, value: (entry: EquityCurveEntry) => number) => {
+  return entries.map((entry) => {
+    return {
+      time: entry.time.getTime() / 1000,
+      value: value(entry),
+    };
+  });
+};
+
+interface EquityCurveChartProps {
+  basePath: string;
+  runID: string;
+  symbolReport: SymbolReport;
+}
+
+const EquityCurveChart = (props: EquityCurveChartProps) => {
+  const chartContainerRef = useRef<any>();
+  const chart = useRef<any>();
+
+  useEffect(() => {
+    if (!chartContainerRef.current || !props.symbolReport.equityCurve) {
+      return;
+    }
+
+    fetchEquityCurve(props.basePath, props.runID, props.symbolReport.equityCurve).then((entries) => {
+      if (!entries || !chartContainerRef.current) {
+        return;
+      }
+
+      chart.current = createChart(chartContainerRef.current, {
+        width: chartContainerRef.current.clientWidth,
+        height: chartContainerRef.current.clientHeight,
+        timeScale: {
+          timeVisible: true,
+          borderColor: '#D1D4DC',
+        },
+        leftPriceScale: {
+          visible: true,
+          borderColor: 'rgba(197, 203, 206, 1)',
+        },
+        layout: {
+          backgroundColor: '#ffffff',
+          textColor: '#000',
+        },
+        crosshair: {
+          mode: CrosshairMode.Normal,
+        },
+      });
+
+      const equitySeries = chart.current.addAreaSeries({
+        priceScaleId: 'right',
+        lineColor: 'rgba(38, 166, 154, 1)',
+        topColor: 'rgba(38, 166, 154, 0.4)',
+        bottomColor: 'rgba(38, 166, 154, 0.0)',
+        lineWidth: 1,
+      });
+      equitySeries.setData(toLineData(entries, (entry) => entry.equity));
+
+      const drawdownSeries = chart.current.addLineSeries({
+        priceScaleId: 'left',
+        color: 'rgba(239, 83, 80, 1)',
+        lineWidth: 1,
+      });
+      drawdownSeries.setData(toLineData(entries, (entry) => -entry.drawdown * 100));
+
+      chart.current.timeScale().fitContent();
+    });
+
+    return () => {
+      if (chart.current) {
+        chart.current.remove();
+        chart.current = null;
+      }
+    };
+  }, [props.runID, props.symbolReport.equityCurve]);
+
+  return <div>
+    <Title order={6}>
+      {props.symbolReport.exchange} {props.symbolReport.symbol} Equity ({props.symbolReport.market.quoteCurrency}) and
+      Drawdown (%)
+    </Title>
+    <div ref={chartContainerRef} style={{'flex': 1, 'minHeight': 300}}>
+    </div>
+  </div>;
+};
+
+export default EquityCurveChart;
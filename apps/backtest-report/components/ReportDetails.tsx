@@ -3,6 +3,7 @@ import React, {useEffect, useState} from 'react';
 import moment from 'moment';
 
 import TradingViewChart from './TradingViewChart';
+import EquityCurveChart from './EquityCurveChart';
 
 import {BalanceMap, ReportSummary} from "../types";
 
@@ -209,6 +210,12 @@ const ReportDetails = (props: ReportDetailsProps) => {
         {title: "Trades", value: totalTrades.toString()},
         {title: "Buy Vol", value: totalBuyVolume.toString() + ` ${volumeUnit}`},
         {title: "Sell Vol", value: totalSellVolume.toString() + ` ${volumeUnit}`},
+        {title: "Max Drawdown", value: (Math.round((reportSummary.maxDrawdown || 0) * 10000) / 100).toString() + "%"},
+        {
+          title: "Max Drawdown Duration",
+          value: moment.duration((reportSummary.maxDrawdownDuration || 0) / 1000000).humanize()
+        },
+        {title: "Sharpe Ratio", value: (Math.round((reportSummary.sharpeRatio || 0) * 100) / 100).toString()},
       ]}/>
 
       <Grid py="xl">
@@ -240,6 +247,14 @@ const ReportDetails = (props: ReportDetailsProps) => {
           })
         }
       </div>
+      <div>
+        {
+          reportSummary.symbolReports.filter((symbolReport) => symbolReport.equityCurve).map((symbolReport, i: number) => {
+            return <EquityCurveChart key={i} basePath={props.basePath} runID={props.runID}
+                                     symbolReport={symbolReport}/>
+          })
+        }
+      </div>
 
     </Container>;
 };
@@ -33,6 +33,10 @@ export interface ReportSummary {
   finalTotalBalances: BalanceMap;
   symbolReports: SymbolReport[];
   manifests: Manifest[];
+  maxDrawdown: number;
+  // maxDrawdownDuration is in nanoseconds
+  maxDrawdownDuration: number;
+  sharpeRatio: number;
 }
 
 export interface SymbolReport {
@@ -44,6 +48,11 @@ export interface SymbolReport {
   pnl: PnL;
   initialBalances: BalanceMap;
   finalBalances: BalanceMap;
+  equityCurve?: string;
+  maxDrawdown: number;
+  // maxDrawdownDuration is in nanoseconds
+  maxDrawdownDuration: number;
+  rollingSharpeRatio: number;
 }
 
 
@@ -0,0 +1,10 @@
+-- +up
+-- +begin
+CREATE TABLE `coinbase_klines` LIKE `binance_klines`;
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE `coinbase_klines`;
+-- +end
@@ -0,0 +1,22 @@
+-- +up
+CREATE TABLE `market_trades`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+    `id`             INTEGER        NOT NULL,
+    `exchange`       TEXT           NOT NULL DEFAULT '',
+    `symbol`         TEXT           NOT NULL,
+    `price`          DECIMAL(16, 8) NOT NULL,
+    `quantity`       DECIMAL(16, 8) NOT NULL,
+    `quote_quantity` DECIMAL(16, 8) NOT NULL,
+    `side`           VARCHAR(4)     NOT NULL DEFAULT '',
+    `is_buyer`       BOOLEAN        NOT NULL DEFAULT FALSE,
+    `is_maker`       BOOLEAN        NOT NULL DEFAULT FALSE,
+    `traded_at`      DATETIME(3)    NOT NULL
+);
+
+CREATE UNIQUE INDEX market_trades_unique_id ON market_trades (exchange, symbol, id);
+
+CREATE INDEX market_trades_traded_at_symbol ON market_trades (exchange, traded_at, symbol);
+
+-- +down
+DROP TABLE IF EXISTS `market_trades`;
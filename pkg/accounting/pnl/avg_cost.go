@@ -21,6 +21,7 @@ func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, c
 	var feeUSD = fixedpoint.Zero
 	var grossProfit = fixedpoint.Zero
 	var grossLoss = fixedpoint.Zero
+	var numOfProfitTrades, numOfLossTrades int
 
 	if len(trades) == 0 {
 		return &AverageCostPnlReport{
@@ -68,8 +69,10 @@ func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, c
 
 		if profit.Sign() > 0 {
 			grossProfit = grossProfit.Add(profit)
+			numOfProfitTrades++
 		} else if profit.Sign() < 0 {
 			grossLoss = grossLoss.Add(profit)
+			numOfLossTrades++
 		}
 
 		if trade.IsBuyer {
@@ -108,6 +111,9 @@ func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, c
 		GrossProfit: grossProfit,
 		GrossLoss:   grossLoss,
 
+		NumOfProfitTrades: numOfProfitTrades,
+		NumOfLossTrades:   numOfLossTrades,
+
 		AverageCost:  position.AverageCost,
 		FeeInUSD:     totalProfit.Sub(totalNetProfit),
 		CurrencyFees: currencyFees,
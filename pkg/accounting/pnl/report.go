@@ -28,6 +28,10 @@ type AverageCostPnlReport struct {
 	GrossProfit fixedpoint.Value `json:"grossProfit"`
 	GrossLoss   fixedpoint.Value `json:"grossLoss"`
 
+	// NumOfProfitTrades and NumOfLossTrades are the number of the trades that realized profit or loss
+	NumOfProfitTrades int `json:"numOfProfitTrades"`
+	NumOfLossTrades   int `json:"numOfLossTrades"`
+
 	AverageCost       fixedpoint.Value            `json:"averageCost"`
 	BuyVolume         fixedpoint.Value            `json:"buyVolume,omitempty"`
 	SellVolume        fixedpoint.Value            `json:"sellVolume,omitempty"`
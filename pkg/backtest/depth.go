@@ -0,0 +1,219 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type DepthEventType string
+
+const (
+	DepthEventTypeSnapshot DepthEventType = "snapshot"
+	DepthEventTypeUpdate   DepthEventType = "update"
+)
+
+// DepthEvent is the recorded depth message, it has the same shape of depth.Update,
+// a snapshot event replaces the whole book while an update event upserts the price levels (zero volume removes the level)
+type DepthEvent struct {
+	Time   time.Time      `json:"time"`
+	Type   DepthEventType `json:"type"`
+	Symbol string         `json:"symbol"`
+
+	FirstUpdateID int64 `json:"firstUpdateId,omitempty"`
+	FinalUpdateID int64 `json:"finalUpdateId,omitempty"`
+
+	Bids types.PriceVolumeSlice `json:"bids"`
+	Asks types.PriceVolumeSlice `json:"asks"`
+}
+
+// MarshalJSON encodes the price levels into the same 2 dimensional array format that
+// types.PriceVolumeSlice.UnmarshalJSON accepts, so that the recorded files can be decoded back into DepthEvent
+func (e DepthEvent) MarshalJSON() ([]byte, error) {
+	type depthEvent DepthEvent
+	return json.Marshal(struct {
+		depthEvent
+		Bids [][]fixedpoint.Value `json:"bids"`
+		Asks [][]fixedpoint.Value `json:"asks"`
+	}{
+		depthEvent: depthEvent(e),
+		Bids:       encodePriceVolumeSlice(e.Bids),
+		Asks:       encodePriceVolumeSlice(e.Asks),
+	})
+}
+
+func encodePriceVolumeSlice(slice types.PriceVolumeSlice) [][]fixedpoint.Value {
+	as := make([][]fixedpoint.Value, len(slice))
+	for i, pv := range slice {
+		as[i] = []fixedpoint.Value{pv.Price, pv.Volume}
+	}
+
+	return as
+}
+
+func (e DepthEvent) Book() types.SliceOrderBook {
+	return types.SliceOrderBook{
+		Symbol: e.Symbol,
+		Bids:   e.Bids,
+		Asks:   e.Asks,
+	}
+}
+
+func depthFileName(dir, symbol string) string {
+	return filepath.Join(dir, symbol+".jsonl")
+}
+
+// DepthRecorder records the book snapshots and updates from the market data stream into
+// json line files, which can be replayed by the depth matching engine.
+type DepthRecorder struct {
+	OutputDirectory string
+
+	mu      sync.Mutex
+	files   map[string]*os.File
+	encoder map[string]*json.Encoder
+}
+
+func NewDepthRecorder(outputDirectory string) *DepthRecorder {
+	return &DepthRecorder{
+		OutputDirectory: outputDirectory,
+		files:           make(map[string]*os.File),
+		encoder:         make(map[string]*json.Encoder),
+	}
+}
+
+// BindStream records the book events of the given stream, the stream should subscribe the book channel
+func (r *DepthRecorder) BindStream(stream types.Stream) {
+	stream.OnBookSnapshot(func(book types.SliceOrderBook) {
+		if err := r.Record(DepthEventTypeSnapshot, book, time.Now()); err != nil {
+			log.WithError(err).Errorf("can not record the depth snapshot of %s", book.Symbol)
+		}
+	})
+
+	stream.OnBookUpdate(func(book types.SliceOrderBook) {
+		if err := r.Record(DepthEventTypeUpdate, book, time.Now()); err != nil {
+			log.WithError(err).Errorf("can not record the depth update of %s", book.Symbol)
+		}
+	})
+}
+
+func (r *DepthRecorder) Record(eventType DepthEventType, book types.SliceOrderBook, t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	encoder, ok := r.encoder[book.Symbol]
+	if !ok {
+		f, err := os.OpenFile(depthFileName(r.OutputDirectory, book.Symbol), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+
+		encoder = json.NewEncoder(f)
+		r.files[book.Symbol] = f
+		r.encoder[book.Symbol] = encoder
+	}
+
+	return encoder.Encode(DepthEvent{
+		Time:   t,
+		Type:   eventType,
+		Symbol: book.Symbol,
+		Bids:   book.Bids,
+		Asks:   book.Asks,
+	})
+}
+
+func (r *DepthRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for _, f := range r.files {
+		if err2 := f.Close(); err2 != nil {
+			err = multierr.Append(err, err2)
+		}
+	}
+
+	return err
+}
+
+// DepthFeed provides the recorded depth events in time order
+type DepthFeed interface {
+	// Next returns the next depth event, io.EOF is returned when there is no more event
+	Next() (*DepthEvent, error)
+}
+
+// DepthEventSlice is an in-memory depth feed
+type DepthEventSlice []DepthEvent
+
+func (s *DepthEventSlice) Next() (*DepthEvent, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+
+	e := (*s)[0]
+	*s = (*s)[1:]
+	return &e, nil
+}
+
+// lazyDepthFeed opens the depth file when the first event is requested,
+// a symbol without the recorded depth file is treated as an empty feed
+type lazyDepthFeed struct {
+	dir, symbol string
+	feed        DepthFeed
+}
+
+func (f *lazyDepthFeed) Next() (*DepthEvent, error) {
+	if f.feed == nil {
+		feed, err := openDepthFileFeed(f.dir, f.symbol)
+		if os.IsNotExist(err) {
+			log.Warnf("depth file of %s is not found in %s, orders will be matched without the order book", f.symbol, f.dir)
+			return nil, io.EOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		f.feed = feed
+	}
+
+	return f.feed.Next()
+}
+
+// depthFileFeed reads the depth events from the file written by DepthRecorder
+type depthFileFeed struct {
+	file    *os.File
+	decoder *json.Decoder
+}
+
+func openDepthFileFeed(dir, symbol string) (*depthFileFeed, error) {
+	f, err := os.Open(depthFileName(dir, symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	return &depthFileFeed{
+		file:    f,
+		decoder: json.NewDecoder(f),
+	}, nil
+}
+
+func (f *depthFileFeed) Next() (*DepthEvent, error) {
+	var e DepthEvent
+	if err := f.decoder.Decode(&e); err != nil {
+		if err == io.EOF {
+			_ = f.file.Close()
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("depth file %s decode error: %w", f.file.Name(), err)
+	}
+
+	return &e, nil
+}
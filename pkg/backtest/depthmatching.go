@@ -0,0 +1,781 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// depthOrder is the resting order in the depth matching engine
+type depthOrder struct {
+	types.Order
+
+	// queueAhead is the order book volume queued in front of this order at the same price level
+	queueAhead fixedpoint.Value
+
+	// locked is the remaining balance locked by this order
+	locked fixedpoint.Value
+}
+
+func (o *depthOrder) remaining() fixedpoint.Value {
+	return o.Quantity.Sub(o.ExecutedQuantity)
+}
+
+// DepthMatching implements an order book aware matching engine for backtest.
+//
+// It replays the recorded depth snapshots and updates, taker orders walk through the price levels of the
+// opposite side (with price slippage and partial fills when the book is not deep enough),
+// and maker orders wait in the queue of their price level: a maker order is only filled
+// when the volume queued in front of it is consumed, or when the market trades through its price.
+//
+// When there is no depth data loaded, taker orders are filled at the last price like SimplePriceMatching.
+//go:generate callbackgen -type DepthMatching
+type DepthMatching struct {
+	Symbol string
+	Market types.Market
+
+	mu           sync.Mutex
+	bidOrders    []*depthOrder
+	askOrders    []*depthOrder
+	stopOrders   []*depthOrder
+	closedOrders map[uint64]types.Order
+
+	// Feed is the recorded depth events of the symbol
+	Feed      DepthFeed
+	nextEvent *DepthEvent
+	book      *types.SliceOrderBook
+
+	LastPrice   fixedpoint.Value
+	LastKLine   types.KLine
+	CurrentTime time.Time
+
+	Account *types.Account
+
+	tradeUpdateCallbacks   []func(trade types.Trade)
+	orderUpdateCallbacks   []func(order types.Order)
+	balanceUpdateCallbacks []func(balances types.BalanceMap)
+}
+
+func NewDepthMatching(market types.Market, account *types.Account, feed DepthFeed, currentTime time.Time) *DepthMatching {
+	return &DepthMatching{
+		Symbol:       market.Symbol,
+		Market:       market,
+		Account:      account,
+		Feed:         feed,
+		CurrentTime:  currentTime,
+		book:         types.NewSliceOrderBook(market.Symbol),
+		closedOrders: make(map[uint64]types.Order),
+	}
+}
+
+// PlaceOrder returns the created order object, the first executed trade (if any) and error
+func (m *DepthMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *types.Trade, error) {
+	price := o.Price
+	switch o.Type {
+	case types.OrderTypeMarket:
+		if m.LastPrice.IsZero() {
+			return nil, nil, fmt.Errorf("can not place market order, the last price of %s is not loaded yet", m.Market.Symbol)
+		}
+		price = m.LastPrice
+
+	case types.OrderTypeStopMarket:
+		price = o.StopPrice
+	}
+
+	if o.Quantity.Compare(m.Market.MinQuantity) < 0 {
+		return nil, nil, fmt.Errorf("order quantity %s is less than minQuantity %s, order: %+v", o.Quantity.String(), m.Market.MinQuantity.String(), o)
+	}
+
+	quoteQuantity := o.Quantity.Mul(price)
+	if quoteQuantity.Compare(m.Market.MinNotional) < 0 {
+		return nil, nil, fmt.Errorf("order amount %s is less than minNotional %s, order: %+v", quoteQuantity.String(), m.Market.MinNotional.String(), o)
+	}
+
+	order := &depthOrder{Order: m.newOrder(o, incOrderID())}
+
+	switch o.Type {
+	case types.OrderTypeStopMarket, types.OrderTypeStopLimit:
+		if err := m.lock(order, price); err != nil {
+			return nil, nil, err
+		}
+
+		m.mu.Lock()
+		m.stopOrders = append(m.stopOrders, order)
+		m.mu.Unlock()
+
+		m.EmitBalanceUpdate(m.Account.Balances())
+		m.EmitOrderUpdate(order.Order)
+		return &order.Order, nil, nil
+
+	case types.OrderTypeMarket:
+		trades, err := m.executeTakerOrder(order)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &order.Order, firstTrade(trades), nil
+	}
+
+	if m.isTaker(o.Side, o.Price) {
+		if o.Type == types.OrderTypeLimitMaker {
+			return nil, nil, fmt.Errorf("limit maker order %s %s @ %s would immediately match", o.Side, o.Quantity.String(), o.Price.String())
+		}
+
+		trades, err := m.executeTakerOrder(order)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &order.Order, firstTrade(trades), nil
+	}
+
+	if err := m.lock(order, price); err != nil {
+		return nil, nil, err
+	}
+
+	m.EmitBalanceUpdate(m.Account.Balances())
+	m.addMakerOrder(order)
+	m.EmitOrderUpdate(order.Order)
+	return &order.Order, nil, nil
+}
+
+func (m *DepthMatching) CancelOrder(o types.Order) (types.Order, error) {
+	var found *depthOrder
+	m.mu.Lock()
+	m.bidOrders, found = removeDepthOrder(m.bidOrders, o.OrderID, found)
+	m.askOrders, found = removeDepthOrder(m.askOrders, o.OrderID, found)
+	m.stopOrders, found = removeDepthOrder(m.stopOrders, o.OrderID, found)
+	m.mu.Unlock()
+
+	if found == nil {
+		return o, fmt.Errorf("cancel order failed, order %d not found: %+v", o.OrderID, o)
+	}
+
+	if err := m.unlock(found, found.locked); err != nil {
+		return o, err
+	}
+
+	found.Status = types.OrderStatusCanceled
+	found.IsWorking = false
+	found.UpdateTime = types.Time(m.CurrentTime)
+	m.addClosedOrder(found)
+
+	m.EmitOrderUpdate(found.Order)
+	m.EmitBalanceUpdate(m.Account.Balances())
+	return found.Order, nil
+}
+
+func (m *DepthMatching) getOrder(orderID uint64) (types.Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if o, ok := m.closedOrders[orderID]; ok {
+		return o, true
+	}
+
+	for _, orders := range [][]*depthOrder{m.bidOrders, m.askOrders, m.stopOrders} {
+		for _, o := range orders {
+			if o.OrderID == orderID {
+				return o.Order, true
+			}
+		}
+	}
+
+	return types.Order{}, false
+}
+
+func (m *DepthMatching) openOrders() (orders []types.Order) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, list := range [][]*depthOrder{m.bidOrders, m.askOrders, m.stopOrders} {
+		for _, o := range list {
+			orders = append(orders, o.Order)
+		}
+	}
+
+	return orders
+}
+
+func (m *DepthMatching) ticker() types.Ticker {
+	kline := m.LastKLine
+	ticker := types.Ticker{
+		Time:   m.CurrentTime,
+		Volume: kline.Volume,
+		Last:   m.LastPrice,
+		Open:   kline.Open,
+		High:   kline.High,
+		Low:    kline.Low,
+		Buy:    m.LastPrice,
+		Sell:   m.LastPrice,
+	}
+
+	if bid, ok := m.book.BestBid(); ok {
+		ticker.Buy = bid.Price
+	}
+
+	if ask, ok := m.book.BestAsk(); ok {
+		ticker.Sell = ask.Price
+	}
+
+	return ticker
+}
+
+func (m *DepthMatching) processKLine(kline types.KLine) {
+	if m.LastPrice.IsZero() {
+		m.LastPrice = kline.Open
+	}
+
+	if err := m.replayDepth(kline.EndTime.Time()); err != nil {
+		log.WithError(err).Errorf("%s depth replay error", m.Market.Symbol)
+	}
+
+	m.CurrentTime = kline.EndTime.Time()
+
+	m.triggerStopOrders(kline.High, kline.Low)
+
+	// the market traded through the price of the resting orders
+	for _, o := range m.bidOrders {
+		if kline.Low.Compare(o.Price) < 0 {
+			m.fillMakerOrder(o, o.remaining())
+		}
+	}
+
+	for _, o := range m.askOrders {
+		if kline.High.Compare(o.Price) > 0 {
+			m.fillMakerOrder(o, o.remaining())
+		}
+	}
+
+	m.removeClosedOrders()
+
+	m.LastPrice = kline.Close
+	m.LastKLine = kline
+}
+
+// replayDepth applies the depth events until the given time
+func (m *DepthMatching) replayDepth(until time.Time) error {
+	if m.Feed == nil {
+		return nil
+	}
+
+	for {
+		if m.nextEvent == nil {
+			e, err := m.Feed.Next()
+			if err == io.EOF {
+				m.Feed = nil
+				return nil
+			} else if err != nil {
+				m.Feed = nil
+				return err
+			}
+
+			m.nextEvent = e
+		}
+
+		if m.nextEvent.Time.After(until) {
+			return nil
+		}
+
+		m.applyDepthEvent(*m.nextEvent)
+		m.nextEvent = nil
+	}
+}
+
+func (m *DepthMatching) applyDepthEvent(e DepthEvent) {
+	if e.Time.After(m.CurrentTime) {
+		m.CurrentTime = e.Time
+	}
+
+	// consume the queues of the resting orders by the volume changes of their price levels
+	isSnapshot := e.Type == DepthEventTypeSnapshot
+	m.consumeQueues(m.bidOrders, m.book.Bids, e.Bids, true, isSnapshot)
+	m.consumeQueues(m.askOrders, m.book.Asks, e.Asks, false, isSnapshot)
+
+	if isSnapshot {
+		m.book.Load(e.Book())
+	} else {
+		m.book.Update(e.Book())
+	}
+
+	// the opposite side crossed the resting orders, which means the orders were taken
+	if ask, ok := m.book.BestAsk(); ok {
+		for _, o := range m.bidOrders {
+			if o.Price.Compare(ask.Price) >= 0 {
+				m.fillMakerOrder(o, o.remaining())
+			}
+		}
+	}
+
+	if bid, ok := m.book.BestBid(); ok {
+		for _, o := range m.askOrders {
+			if o.Price.Compare(bid.Price) <= 0 {
+				m.fillMakerOrder(o, o.remaining())
+			}
+		}
+	}
+
+	m.removeClosedOrders()
+}
+
+// consumeQueues assumes the volume decrease of a price level is consumed from the front of the queue,
+// once the volume in front of an order is consumed, the rest of the decrease fills the order.
+func (m *DepthMatching) consumeQueues(orders []*depthOrder, current, changes types.PriceVolumeSlice, descending, isSnapshot bool) {
+	filledAtLevel := map[string]fixedpoint.Value{}
+	for _, o := range orders {
+		newLevel, idx := changes.Find(o.Price, descending)
+		if idx >= len(changes) || newLevel.Price.Compare(o.Price) != 0 {
+			// a snapshot only covers the top levels of the book, the levels deeper than
+			// the last level of the snapshot are unknown instead of being removed
+			if !isSnapshot || !coversPrice(changes, o.Price, descending) {
+				continue
+			}
+
+			// the price level is gone in the new snapshot
+			newLevel = types.PriceVolume{Price: o.Price, Volume: fixedpoint.Zero}
+		}
+
+		oldLevel, _ := current.Find(o.Price, descending)
+		decrease := oldLevel.Volume.Sub(newLevel.Volume)
+		if decrease.Sign() <= 0 {
+			continue
+		}
+
+		consumedAhead := fixedpoint.Min(decrease, o.queueAhead)
+		o.queueAhead = o.queueAhead.Sub(consumedAhead)
+
+		fillable := decrease.Sub(consumedAhead).Sub(filledAtLevel[o.Price.String()])
+		if fillable.Sign() <= 0 {
+			continue
+		}
+
+		quantity := fixedpoint.Min(fillable, o.remaining())
+		filledAtLevel[o.Price.String()] = filledAtLevel[o.Price.String()].Add(quantity)
+		m.fillMakerOrder(o, quantity)
+	}
+}
+
+// coversPrice returns true if the price is within the price range of the snapshot levels
+func coversPrice(levels types.PriceVolumeSlice, price fixedpoint.Value, descending bool) bool {
+	if len(levels) == 0 {
+		return false
+	}
+
+	last := levels[len(levels)-1].Price
+	if descending {
+		return price.Compare(last) >= 0
+	}
+
+	return price.Compare(last) <= 0
+}
+
+func (m *DepthMatching) triggerStopOrders(high, low fixedpoint.Value) {
+	var stopOrders []*depthOrder
+	var triggered []*depthOrder
+	m.mu.Lock()
+	for _, o := range m.stopOrders {
+		if (o.Side == types.SideTypeBuy && high.Compare(o.StopPrice) >= 0) ||
+			(o.Side == types.SideTypeSell && low.Compare(o.StopPrice) <= 0) {
+			triggered = append(triggered, o)
+		} else {
+			stopOrders = append(stopOrders, o)
+		}
+	}
+	m.stopOrders = stopOrders
+	m.mu.Unlock()
+
+	for _, o := range triggered {
+		// release the balance locked by the stop price, the triggered order locks its balance again
+		if err := m.unlock(o, o.locked); err != nil {
+			log.WithError(err).Errorf("can not unlock the balance of the stop order %d", o.OrderID)
+		}
+
+		if o.Type == types.OrderTypeStopMarket {
+			o.Type = types.OrderTypeMarket
+		} else {
+			o.Type = types.OrderTypeLimit
+		}
+
+		if o.Type == types.OrderTypeMarket || m.isTaker(o.Side, o.Price) {
+			if _, err := m.executeTakerOrder(o); err != nil {
+				m.rejectOrder(o, err)
+			}
+			continue
+		}
+
+		if err := m.lock(o, o.Price); err != nil {
+			m.rejectOrder(o, err)
+			continue
+		}
+
+		m.addMakerOrder(o)
+		m.EmitOrderUpdate(o.Order)
+	}
+}
+
+func (m *DepthMatching) rejectOrder(o *depthOrder, err error) {
+	log.WithError(err).Errorf("order %d is rejected", o.OrderID)
+	o.Status = types.OrderStatusRejected
+	o.IsWorking = false
+	o.UpdateTime = types.Time(m.CurrentTime)
+	m.addClosedOrder(o)
+	m.EmitOrderUpdate(o.Order)
+}
+
+// isTaker checks if the order at the given price would be matched immediately
+func (m *DepthMatching) isTaker(side types.SideType, price fixedpoint.Value) bool {
+	switch side {
+	case types.SideTypeBuy:
+		if ask, ok := m.book.BestAsk(); ok {
+			return price.Compare(ask.Price) >= 0
+		}
+
+	case types.SideTypeSell:
+		if bid, ok := m.book.BestBid(); ok {
+			return price.Compare(bid.Price) <= 0
+		}
+	}
+
+	return isLimitTakerOrder(types.SubmitOrder{Side: side, Type: types.OrderTypeLimit, Price: price}, m.LastPrice)
+}
+
+// quote returns the price levels that a taker order could take from the opposite side of the book.
+// zero limit price means the order is a market order.
+func (m *DepthMatching) quote(side types.SideType, quantity, limitPrice fixedpoint.Value) (fills types.PriceVolumeSlice) {
+	var levels types.PriceVolumeSlice
+	switch side {
+	case types.SideTypeBuy:
+		levels = m.book.Asks
+	case types.SideTypeSell:
+		levels = m.book.Bids
+	}
+
+	if len(levels) == 0 {
+		// no depth data, fill at the last price
+		if limitPrice.IsZero() || isLimitTakerOrder(types.SubmitOrder{Side: side, Type: types.OrderTypeLimit, Price: limitPrice}, m.LastPrice) {
+			return types.PriceVolumeSlice{{Price: m.LastPrice, Volume: quantity}}
+		}
+		return nil
+	}
+
+	remaining := quantity
+	for _, level := range levels {
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		if !limitPrice.IsZero() {
+			if (side == types.SideTypeBuy && level.Price.Compare(limitPrice) > 0) ||
+				(side == types.SideTypeSell && level.Price.Compare(limitPrice) < 0) {
+				break
+			}
+		}
+
+		volume := fixedpoint.Min(level.Volume, remaining)
+		fills = append(fills, types.PriceVolume{Price: level.Price, Volume: volume})
+		remaining = remaining.Sub(volume)
+	}
+
+	return fills
+}
+
+// takeLiquidity removes the taken volume from the book, the next depth event overrides the levels anyway
+func (m *DepthMatching) takeLiquidity(side types.SideType, fills types.PriceVolumeSlice) {
+	for _, fill := range fills {
+		switch side {
+		case types.SideTypeBuy:
+			if level, idx := m.book.Asks.Find(fill.Price, false); idx < len(m.book.Asks) && level.Price.Compare(fill.Price) == 0 {
+				m.book.Asks[idx].Volume = level.Volume.Sub(fill.Volume)
+				if m.book.Asks[idx].Volume.Sign() <= 0 {
+					m.book.Asks = m.book.Asks.Remove(fill.Price, false)
+				}
+			}
+
+		case types.SideTypeSell:
+			if level, idx := m.book.Bids.Find(fill.Price, true); idx < len(m.book.Bids) && level.Price.Compare(fill.Price) == 0 {
+				m.book.Bids[idx].Volume = level.Volume.Sub(fill.Volume)
+				if m.book.Bids[idx].Volume.Sign() <= 0 {
+					m.book.Bids = m.book.Bids.Remove(fill.Price, true)
+				}
+			}
+		}
+	}
+}
+
+// executeTakerOrder matches the order against the opposite side of the book,
+// the remaining quantity of a limit order is placed as a maker order, and the remaining quantity of a market order is canceled.
+func (m *DepthMatching) executeTakerOrder(o *depthOrder) ([]types.Trade, error) {
+	limitPrice := fixedpoint.Zero
+	if o.Type != types.OrderTypeMarket {
+		limitPrice = o.Price
+	}
+
+	fills := m.quote(o.Side, o.remaining(), limitPrice)
+
+	// lock the balance before we execute the trades
+	switch o.Side {
+	case types.SideTypeBuy:
+		amount := fixedpoint.Zero
+		if limitPrice.IsZero() {
+			for _, fill := range fills {
+				amount = amount.Add(fill.Price.Mul(fill.Volume))
+			}
+		} else {
+			amount = limitPrice.Mul(o.remaining())
+		}
+
+		if err := m.Account.LockBalance(m.Market.QuoteCurrency, amount); err != nil {
+			return nil, err
+		}
+		o.locked = amount
+
+	case types.SideTypeSell:
+		if err := m.Account.LockBalance(m.Market.BaseCurrency, o.remaining()); err != nil {
+			return nil, err
+		}
+		o.locked = o.remaining()
+	}
+
+	m.EmitBalanceUpdate(m.Account.Balances())
+
+	if o.Type == types.OrderTypeMarket && len(fills) > 0 {
+		o.Price = fills[len(fills)-1].Price
+	}
+
+	// emit the order update for Status:New
+	m.EmitOrderUpdate(o.Order)
+
+	m.takeLiquidity(o.Side, fills)
+
+	var trades []types.Trade
+	for _, fill := range fills {
+		trade := m.newTrade(&o.Order, false, fill.Price, fill.Volume)
+		m.executeTrade(o, trade)
+		trades = append(trades, trade)
+	}
+
+	switch {
+	case o.remaining().Sign() <= 0:
+		o.Status = types.OrderStatusFilled
+		o.IsWorking = false
+		m.addClosedOrder(o)
+
+	case o.Type == types.OrderTypeMarket:
+		// the book is not deep enough, the rest of the market order is expired
+		if err := m.unlock(o, o.locked); err != nil {
+			return trades, err
+		}
+
+		o.Status = types.OrderStatusCanceled
+		o.IsWorking = false
+		m.addClosedOrder(o)
+		m.EmitBalanceUpdate(m.Account.Balances())
+
+	default:
+		if o.ExecutedQuantity.Sign() > 0 {
+			o.Status = types.OrderStatusPartiallyFilled
+		}
+
+		m.addMakerOrder(o)
+	}
+
+	m.EmitOrderUpdate(o.Order)
+	return trades, nil
+}
+
+// fillMakerOrder fills the resting order at its order price
+func (m *DepthMatching) fillMakerOrder(o *depthOrder, quantity fixedpoint.Value) {
+	if quantity.Sign() <= 0 || o.remaining().Sign() <= 0 {
+		return
+	}
+
+	trade := m.newTrade(&o.Order, true, o.Price, quantity)
+	m.executeTrade(o, trade)
+
+	if o.remaining().Sign() <= 0 {
+		o.Status = types.OrderStatusFilled
+		o.IsWorking = false
+	} else {
+		o.Status = types.OrderStatusPartiallyFilled
+	}
+
+	m.EmitOrderUpdate(o.Order)
+}
+
+func (m *DepthMatching) executeTrade(o *depthOrder, trade types.Trade) {
+	if err := settleTrade(m.Account, m.Market, trade); err != nil {
+		panic(errors.Wrapf(err, "executeTrade exception, wanted to use more than the locked balance"))
+	}
+
+	if trade.IsBuyer {
+		o.locked = o.locked.Sub(trade.QuoteQuantity)
+
+		// release the over-locked balance when the trade price is better than the limit price
+		if o.Type != types.OrderTypeMarket && trade.Price.Compare(o.Price) < 0 {
+			if err := m.unlock(o, o.Price.Sub(trade.Price).Mul(trade.Quantity)); err != nil {
+				log.WithError(err).Errorf("can not unlock the over-locked balance of order %d", o.OrderID)
+			}
+		}
+	} else {
+		o.locked = o.locked.Sub(trade.Quantity)
+	}
+
+	o.ExecutedQuantity = o.ExecutedQuantity.Add(trade.Quantity)
+	o.UpdateTime = trade.Time
+
+	m.EmitTradeUpdate(trade)
+	m.EmitBalanceUpdate(m.Account.Balances())
+}
+
+func (m *DepthMatching) addMakerOrder(o *depthOrder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch o.Side {
+	case types.SideTypeBuy:
+		level, _ := m.book.Bids.Find(o.Price, true)
+		o.queueAhead = level.Volume
+		m.bidOrders = append(m.bidOrders, o)
+
+	case types.SideTypeSell:
+		level, _ := m.book.Asks.Find(o.Price, false)
+		o.queueAhead = level.Volume
+		m.askOrders = append(m.askOrders, o)
+	}
+}
+
+func (m *DepthMatching) addClosedOrder(o *depthOrder) {
+	m.mu.Lock()
+	m.closedOrders[o.OrderID] = o.Order
+	m.mu.Unlock()
+}
+
+func (m *DepthMatching) removeClosedOrders() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	filter := func(orders []*depthOrder) (working []*depthOrder) {
+		for _, o := range orders {
+			if o.Status == types.OrderStatusFilled {
+				m.closedOrders[o.OrderID] = o.Order
+				continue
+			}
+			working = append(working, o)
+		}
+		return working
+	}
+
+	m.bidOrders = filter(m.bidOrders)
+	m.askOrders = filter(m.askOrders)
+}
+
+func (m *DepthMatching) lock(o *depthOrder, price fixedpoint.Value) error {
+	switch o.Side {
+	case types.SideTypeBuy:
+		amount := price.Mul(o.remaining())
+		if err := m.Account.LockBalance(m.Market.QuoteCurrency, amount); err != nil {
+			return err
+		}
+		o.locked = amount
+
+	case types.SideTypeSell:
+		if err := m.Account.LockBalance(m.Market.BaseCurrency, o.remaining()); err != nil {
+			return err
+		}
+		o.locked = o.remaining()
+	}
+
+	return nil
+}
+
+func (m *DepthMatching) unlock(o *depthOrder, amount fixedpoint.Value) error {
+	if amount.Sign() <= 0 {
+		return nil
+	}
+
+	currency := m.Market.BaseCurrency
+	if o.Side == types.SideTypeBuy {
+		currency = m.Market.QuoteCurrency
+	}
+
+	if err := m.Account.UnlockBalance(currency, amount); err != nil {
+		return err
+	}
+
+	o.locked = o.locked.Sub(amount)
+	return nil
+}
+
+func (m *DepthMatching) newTrade(order *types.Order, isMaker bool, price, quantity fixedpoint.Value) types.Trade {
+	var feeRate = m.Account.TakerFeeRate
+	if isMaker {
+		feeRate = m.Account.MakerFeeRate
+	}
+
+	var quoteQuantity = quantity.Mul(price)
+	var fee fixedpoint.Value
+	var feeCurrency string
+
+	if useFeeToken {
+		feeCurrency = FeeToken
+		fee = quoteQuantity.Mul(feeRate)
+	} else {
+		// the fee is calculated by the executed part of the order
+		executed := *order
+		executed.Price = price
+		executed.Quantity = quantity
+		fee, feeCurrency = calculateNativeOrderFee(&executed, m.Market, feeRate)
+	}
+
+	return types.Trade{
+		ID:            incTradeID(),
+		OrderID:       order.OrderID,
+		Exchange:      types.ExchangeBacktest,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          types.Time(m.CurrentTime),
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	}
+}
+
+func (m *DepthMatching) newOrder(o types.SubmitOrder, orderID uint64) types.Order {
+	return types.Order{
+		OrderID:          orderID,
+		SubmitOrder:      o,
+		Exchange:         types.ExchangeBacktest,
+		Status:           types.OrderStatusNew,
+		ExecutedQuantity: fixedpoint.Zero,
+		IsWorking:        true,
+		CreationTime:     types.Time(m.CurrentTime),
+		UpdateTime:       types.Time(m.CurrentTime),
+	}
+}
+
+func removeDepthOrder(orders []*depthOrder, orderID uint64, found *depthOrder) ([]*depthOrder, *depthOrder) {
+	var rest []*depthOrder
+	for _, o := range orders {
+		if o.OrderID == orderID {
+			found = o
+			continue
+		}
+		rest = append(rest, o)
+	}
+	return rest, found
+}
+
+func firstTrade(trades []types.Trade) *types.Trade {
+	if len(trades) == 0 {
+		return nil
+	}
+	return &trades[0]
+}
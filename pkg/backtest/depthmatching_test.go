@@ -0,0 +1,243 @@
+package backtest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func pv(price, volume float64) types.PriceVolume {
+	return types.PriceVolume{Price: fixedpoint.NewFromFloat(price), Volume: fixedpoint.NewFromFloat(volume)}
+}
+
+func newTestDepthMatching(t1 time.Time, events ...DepthEvent) *DepthMatching {
+	feed := DepthEventSlice(events)
+	engine := NewDepthMatching(getTestMarket(), getTestAccount(), &feed, t1)
+	engine.LastPrice = fixedpoint.NewFromFloat(20000.0)
+	return engine
+}
+
+func TestDepthMatching_MarketOrderSlippage(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine := newTestDepthMatching(t1, DepthEvent{
+		Time: t1.Add(10 * time.Second),
+		Type: DepthEventTypeSnapshot,
+		Bids: types.PriceVolumeSlice{pv(19999, 1.0), pv(19998, 2.0)},
+		Asks: types.PriceVolumeSlice{pv(20001, 0.5), pv(20002, 0.3), pv(20005, 1.0)},
+	})
+
+	var trades []types.Trade
+	engine.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	order, trade, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(1.0),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, trade)
+	assert.Equal(t, types.OrderStatusFilled, order.Status)
+	assert.Equal(t, "1", order.ExecutedQuantity.String())
+
+	// 0.5 @ 20001, 0.3 @ 20002, 0.2 @ 20005
+	if assert.Len(t, trades, 3) {
+		assert.Equal(t, "20001", trades[0].Price.String())
+		assert.Equal(t, "0.5", trades[0].Quantity.String())
+		assert.Equal(t, "20002", trades[1].Price.String())
+		assert.Equal(t, "0.3", trades[1].Quantity.String())
+		assert.Equal(t, "20005", trades[2].Price.String())
+		assert.Equal(t, "0.2", trades[2].Quantity.String())
+	}
+
+	// the taken liquidity is removed from the book
+	ask, ok := engine.book.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, "20005", ask.Price.String())
+	assert.Equal(t, "0.8", ask.Volume.String())
+
+	usdt, ok := engine.Account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "0", usdt.Locked.String())
+}
+
+func TestDepthMatching_LimitTakerOrderRemainingAsMaker(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine := newTestDepthMatching(t1, DepthEvent{
+		Time: t1.Add(10 * time.Second),
+		Type: DepthEventTypeSnapshot,
+		Bids: types.PriceVolumeSlice{pv(19999, 1.0)},
+		Asks: types.PriceVolumeSlice{pv(20001, 0.5), pv(20010, 1.0)},
+	})
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	order, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 20005, 1.0))
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.Equal(t, "0.5", order.ExecutedQuantity.String())
+	assert.Len(t, engine.bidOrders, 1)
+
+	// the rest of the order is locked by the limit price
+	usdt, ok := engine.Account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "10002.5", usdt.Locked.String())
+
+	_, err = engine.CancelOrder(*order)
+	assert.NoError(t, err)
+
+	usdt, ok = engine.Account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "0", usdt.Locked.String())
+}
+
+func TestDepthMatching_MakerOrderQueuePosition(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	engine := newTestDepthMatching(t1,
+		DepthEvent{
+			Time: t1.Add(10 * time.Second),
+			Type: DepthEventTypeSnapshot,
+			Bids: types.PriceVolumeSlice{pv(19999, 1.0), pv(19998, 2.0)},
+			Asks: types.PriceVolumeSlice{pv(20001, 1.0)},
+		},
+		// new orders are queued behind our order
+		DepthEvent{
+			Time: t2.Add(5 * time.Second),
+			Type: DepthEventTypeUpdate,
+			Bids: types.PriceVolumeSlice{pv(19999, 2.0)},
+		},
+		// 1.5 of the queue is consumed, the first 1.0 is in front of our order
+		DepthEvent{
+			Time: t2.Add(10 * time.Second),
+			Type: DepthEventTypeUpdate,
+			Bids: types.PriceVolumeSlice{pv(19999, 0.5)},
+		},
+		// the ask side crosses our price
+		DepthEvent{
+			Time: t2.Add(20 * time.Second),
+			Type: DepthEventTypeUpdate,
+			Bids: types.PriceVolumeSlice{pv(19999, 0)},
+			Asks: types.PriceVolumeSlice{pv(20001, 0), pv(19999, 0.3)},
+		},
+	)
+
+	var orderUpdates []types.Order
+	engine.OnOrderUpdate(func(order types.Order) {
+		orderUpdates = append(orderUpdates, order)
+	})
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	order, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19999, 1.0))
+	assert.NoError(t, err)
+	assert.Nil(t, trade)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+
+	// the kline does not trade through the price, the fills come from the queue
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t2, 20000, 20001, 19999, 20000))
+
+	if assert.Len(t, orderUpdates, 3) {
+		assert.Equal(t, types.OrderStatusNew, orderUpdates[0].Status)
+		assert.Equal(t, types.OrderStatusPartiallyFilled, orderUpdates[1].Status)
+		assert.Equal(t, "0.5", orderUpdates[1].ExecutedQuantity.String())
+		assert.Equal(t, types.OrderStatusFilled, orderUpdates[2].Status)
+		assert.Equal(t, "1", orderUpdates[2].ExecutedQuantity.String())
+	}
+
+	assert.Len(t, engine.bidOrders, 0)
+	closedOrder, ok := engine.getOrder(order.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderStatusFilled, closedOrder.Status)
+}
+
+func TestDepthMatching_SnapshotDepthLimit(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	engine := newTestDepthMatching(t1,
+		DepthEvent{
+			Time: t1.Add(10 * time.Second),
+			Type: DepthEventTypeSnapshot,
+			Bids: types.PriceVolumeSlice{pv(19999, 1.0), pv(19998, 1.0), pv(19997, 2.0)},
+			Asks: types.PriceVolumeSlice{pv(20001, 1.0)},
+		},
+		// new orders are queued behind our order
+		DepthEvent{
+			Time: t2.Add(5 * time.Second),
+			Type: DepthEventTypeUpdate,
+			Bids: types.PriceVolumeSlice{pv(19997, 3.0)},
+		},
+		// the snapshot only contains the top 2 bid levels, the level of our order is not covered
+		DepthEvent{
+			Time: t2.Add(10 * time.Second),
+			Type: DepthEventTypeSnapshot,
+			Bids: types.PriceVolumeSlice{pv(19999, 1.0), pv(19998, 1.0)},
+			Asks: types.PriceVolumeSlice{pv(20001, 1.0)},
+		},
+	)
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	order, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19997, 1.0))
+	assert.NoError(t, err)
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t2, 20000, 20001, 19998, 20000))
+
+	restingOrder, ok := engine.getOrder(order.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderStatusNew, restingOrder.Status)
+	assert.Len(t, engine.bidOrders, 1)
+}
+
+func TestDepthMatching_WithoutDepthData(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine := newTestDepthMatching(t1)
+
+	order, trade, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(0.1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusFilled, order.Status)
+	if assert.NotNil(t, trade) {
+		assert.Equal(t, "20000", trade.Price.String())
+	}
+
+	_, _, err = engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeSell, 21000, 0.1))
+	assert.NoError(t, err)
+	assert.Len(t, engine.askOrders, 1)
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 21500, 20000, 21000))
+	assert.Len(t, engine.askOrders, 0)
+}
+
+func TestDepthEvent_MarshalJSON(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	event := DepthEvent{
+		Time:   t1,
+		Type:   DepthEventTypeSnapshot,
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{pv(19000, 0.5), pv(18990, 1.25)},
+		Asks:   types.PriceVolumeSlice{pv(19010, 2.0)},
+	}
+
+	data, err := json.Marshal(event)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"time":"2021-07-01T00:00:00Z","type":"snapshot","symbol":"BTCUSDT","bids":[[19000,0.5],[18990,1.25]],"asks":[[19010,2]]}`, string(data))
+
+	var event2 DepthEvent
+	err = json.Unmarshal(data, &event2)
+	assert.NoError(t, err)
+	assert.Equal(t, event.Bids, event2.Bids)
+	assert.Equal(t, event.Asks, event2.Asks)
+	assert.Equal(t, event.Symbol, event2.Symbol)
+}
@@ -0,0 +1,201 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/data/tsv"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/statistics"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultRollingSharpeDays is the default window of the rolling sharpe ratio
+const DefaultRollingSharpeDays = 30
+
+// EquityCurveRecorder samples the equity value of a session symbol in its quote currency at every kline close.
+// It tracks the drawdown and the rolling sharpe ratio of the daily returns,
+// and writes the time series into the tsv file when the file is opened.
+type EquityCurveRecorder struct {
+	Market types.Market
+
+	// RollingSharpeDays is the number of the daily returns used by the rolling sharpe ratio
+	RollingSharpeDays int
+
+	writer *tsv.Writer
+
+	numOfSamples int
+	lastTime     time.Time
+
+	peak                fixedpoint.Value
+	peakTime            time.Time
+	drawdown            fixedpoint.Value
+	maxDrawdown         fixedpoint.Value
+	maxDrawdownDuration time.Duration
+
+	// dailyCloses keeps the last equity of each day in the rolling window,
+	// the first element is the close of the day before the window
+	dailyCloses   []fixedpoint.Value
+	lastDay       time.Time
+	rollingSharpe fixedpoint.Value
+
+	// samples keeps the last equity sample of each hour for the return ratios of the whole back-test range
+	samples []EquitySample
+}
+
+func NewEquityCurveRecorder(market types.Market) *EquityCurveRecorder {
+	return &EquityCurveRecorder{
+		Market:            market,
+		RollingSharpeDays: DefaultRollingSharpeDays,
+	}
+}
+
+// OpenFile creates the tsv file and writes the header
+func (r *EquityCurveRecorder) OpenFile(filename string) error {
+	writer, err := tsv.NewWriterFile(filename)
+	if err != nil {
+		return err
+	}
+
+	r.writer = writer
+	return r.writer.Write([]string{
+		"time",
+		"equity",
+		"drawdown",
+		"rolling_sharpe",
+	})
+}
+
+func (r *EquityCurveRecorder) Close() error {
+	if r.writer == nil {
+		return nil
+	}
+
+	return r.writer.Close()
+}
+
+// Record samples the equity value of the balances at the given price,
+// the samples that are not after the last sample time are ignored,
+// so the same close time of the klines in different intervals is recorded only once.
+func (r *EquityCurveRecorder) Record(t time.Time, balances types.BalanceMap, price fixedpoint.Value) error {
+	if r.numOfSamples > 0 && !t.After(r.lastTime) {
+		return nil
+	}
+
+	equity := InQuoteAsset(balances, r.Market, price)
+	r.numOfSamples++
+	r.lastTime = t
+
+	r.updateDrawdown(t, equity)
+	r.updateRollingSharpe(t, equity)
+	r.updateSamples(t, equity)
+
+	if r.writer == nil {
+		return nil
+	}
+
+	return r.writer.Write([]string{
+		t.Format(time.RFC3339),
+		equity.String(),
+		r.drawdown.String(),
+		r.rollingSharpe.String(),
+	})
+}
+
+func (r *EquityCurveRecorder) updateDrawdown(t time.Time, equity fixedpoint.Value) {
+	if r.numOfSamples == 1 || equity.Compare(r.peak) >= 0 {
+		r.peak = equity
+		r.peakTime = t
+		r.drawdown = fixedpoint.Zero
+		return
+	}
+
+	if r.peak.Sign() > 0 {
+		r.drawdown = r.peak.Sub(equity).Div(r.peak)
+		if r.drawdown.Compare(r.maxDrawdown) > 0 {
+			r.maxDrawdown = r.drawdown
+		}
+	}
+
+	if duration := t.Sub(r.peakTime); duration > r.maxDrawdownDuration {
+		r.maxDrawdownDuration = duration
+	}
+}
+
+func (r *EquityCurveRecorder) updateSamples(t time.Time, equity fixedpoint.Value) {
+	sample := EquitySample{Time: t, Equity: equity}
+	if n := len(r.samples); n > 0 && r.samples[n-1].Time.Truncate(time.Hour).Equal(t.Truncate(time.Hour)) {
+		r.samples[n-1] = sample
+		return
+	}
+
+	r.samples = append(r.samples, sample)
+}
+
+// updateRollingSharpe re-calculates the rolling sharpe ratio when a day is closed
+func (r *EquityCurveRecorder) updateRollingSharpe(t time.Time, equity fixedpoint.Value) {
+	day := t.UTC().Truncate(24 * time.Hour)
+	if r.numOfSamples == 1 {
+		// the first sample is the base of the first daily return
+		r.dailyCloses = append(r.dailyCloses, equity, equity)
+		r.lastDay = day
+		return
+	}
+
+	if day.Equal(r.lastDay) {
+		r.dailyCloses[len(r.dailyCloses)-1] = equity
+		return
+	}
+
+	r.lastDay = day
+	r.rollingSharpe = calculateSharpe(r.dailyCloses)
+
+	r.dailyCloses = append(r.dailyCloses, equity)
+	if n := len(r.dailyCloses) - r.RollingSharpeDays - 1; n > 0 {
+		r.dailyCloses = r.dailyCloses[n:]
+	}
+}
+
+// calculateSharpe returns the annualized sharpe ratio of the daily closes
+func calculateSharpe(closes []fixedpoint.Value) fixedpoint.Value {
+	var returns types.Float64Slice
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1].Sign() > 0 {
+			returns.Push(closes[i].Div(closes[i-1]).Float64() - 1.0)
+		}
+	}
+
+	if returns.Length() < 2 {
+		return fixedpoint.Zero
+	}
+
+	return newFromFloat(statistics.Sharpe(&returns, 365, true, false))
+}
+
+func (r *EquityCurveRecorder) MaxDrawdown() fixedpoint.Value {
+	return r.maxDrawdown
+}
+
+func (r *EquityCurveRecorder) MaxDrawdownDuration() time.Duration {
+	return r.maxDrawdownDuration
+}
+
+// RollingSharpeRatio returns the rolling sharpe ratio including the last unclosed day
+func (r *EquityCurveRecorder) RollingSharpeRatio() fixedpoint.Value {
+	return calculateSharpe(r.dailyCloses)
+}
+
+// Samples returns the hourly equity samples
+func (r *EquityCurveRecorder) Samples() []EquitySample {
+	return r.samples
+}
+
+// SetEquityCurveMetrics copies the metrics of the equity curve recorder into the symbol report,
+// the return ratios are calculated from the hourly samples while the max drawdown is tracked at every sample
+func (r *SessionSymbolReport) SetEquityCurveMetrics(recorder *EquityCurveRecorder) {
+	r.MaxDrawdown = recorder.MaxDrawdown()
+	r.MaxDrawdownDuration = recorder.MaxDrawdownDuration()
+	r.RollingSharpeRatio = recorder.RollingSharpeRatio()
+	if samples := recorder.Samples(); len(samples) >= 2 {
+		r.SharpeRatio, r.SortinoRatio, r.CalmarRatio = calculateReturnRatios(samples, r.MaxDrawdown)
+	}
+}
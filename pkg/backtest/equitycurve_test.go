@@ -0,0 +1,76 @@
+package backtest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEquityCurveRecorder(t *testing.T) {
+	market := types.Market{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+	}
+
+	balances := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+	}
+
+	filename := filepath.Join(t.TempDir(), "equity_curve.tsv")
+	recorder := NewEquityCurveRecorder(market)
+	recorder.RollingSharpeDays = 3
+	assert.NoError(t, recorder.OpenFile(filename))
+
+	// the equity values are 2000, 2200, 1800, 2100 and 2300 in 5 days
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	prices := []float64{1000, 1200, 800, 1100, 1300}
+	for i, price := range prices {
+		kLineEndTime := t1.Add(time.Duration(i) * 24 * time.Hour)
+		assert.NoError(t, recorder.Record(kLineEndTime, balances, fixedpoint.NewFromFloat(price)))
+
+		// the kline of the other interval closed at the same time is ignored
+		assert.NoError(t, recorder.Record(kLineEndTime, balances, fixedpoint.NewFromFloat(price*2)))
+	}
+	assert.NoError(t, recorder.Close())
+
+	assert.InDelta(t, 400.0/2200.0, recorder.MaxDrawdown().Float64(), 1e-8)
+	assert.Equal(t, 48*time.Hour, recorder.MaxDrawdownDuration())
+
+	// the rolling window contains the last 3 daily returns: 1800 -> 2100 -> 2300 after 2200
+	expected := calculateSharpe([]fixedpoint.Value{
+		fixedpoint.NewFromFloat(2200),
+		fixedpoint.NewFromFloat(1800),
+		fixedpoint.NewFromFloat(2100),
+		fixedpoint.NewFromFloat(2300),
+	})
+	assert.True(t, expected.Sign() > 0)
+	assert.Equal(t, expected, recorder.RollingSharpeRatio())
+
+	// the return ratios of the symbol report are calculated from the whole range
+	var report SessionSymbolReport
+	report.SetEquityCurveMetrics(recorder)
+	assert.Len(t, recorder.Samples(), 5)
+	assert.Equal(t, recorder.MaxDrawdown(), report.MaxDrawdown)
+	assert.Equal(t, recorder.RollingSharpeRatio(), report.RollingSharpeRatio)
+	assert.True(t, report.SharpeRatio.Sign() > 0)
+	assert.True(t, report.SortinoRatio.Sign() > 0)
+	assert.True(t, report.CalmarRatio.Sign() > 0)
+
+	content, err := ioutil.ReadFile(filename)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if assert.Len(t, lines, 6) {
+		assert.Equal(t, "time\tequity\tdrawdown\trolling_sharpe", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "2022-06-01T00:00:00Z\t2000\t0\t"), lines[1])
+	}
+}
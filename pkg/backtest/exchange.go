@@ -50,6 +50,9 @@ var log = logrus.WithField("cmd", "backtest")
 var ErrUnimplemented = errors.New("unimplemented method")
 
 type Exchange struct {
+	types.MarginSettings
+	types.FuturesSettings
+
 	sourceName     types.ExchangeName
 	publicExchange types.Exchange
 	srv            *service.BacktestService
@@ -66,10 +69,27 @@ type Exchange struct {
 	closedOrders      map[string][]types.Order
 	closedOrdersMutex sync.Mutex
 
-	matchingBooks      map[string]*SimplePriceMatching
+	matchingBooks      map[string]matchingEngine
 	matchingBooksMutex sync.Mutex
 
 	markets types.MarketMap
+
+	userDataStream types.StandardStreamEmitter
+
+	// marketTrades is the queued market trades of the trade replay
+	marketTrades      []types.Trade
+	marketTradesMutex sync.Mutex
+
+	// margin simulation
+	marginConfig     bbgo.BacktestMargin
+	marginMutex      sync.Mutex
+	autoRepayOrders  map[uint64]struct{}
+	fundingRates     map[string][]types.FundingRate
+	lastInterestTime time.Time
+	loans            []types.MarginLoan
+	repays           []types.MarginRepay
+	interests        []types.MarginInterest
+	liquidations     []types.MarginLiquidation
 }
 
 func NewExchange(sourceName types.ExchangeName, sourceExchange types.Exchange, srv *service.BacktestService, config *bbgo.Backtest) (*Exchange, error) {
@@ -102,6 +122,10 @@ func NewExchange(sourceName types.ExchangeName, sourceExchange types.Exchange, s
 		currentTime:    startTime,
 		closedOrders:   make(map[string][]types.Order),
 		trades:         make(map[string][]types.Trade),
+
+		marginConfig:    configAccount.Margin,
+		autoRepayOrders: make(map[uint64]struct{}),
+		fundingRates:    make(map[string][]types.FundingRate),
 	}
 
 	e.resetMatchingBooks()
@@ -122,7 +146,7 @@ func (e *Exchange) addClosedOrder(order types.Order) {
 
 func (e *Exchange) resetMatchingBooks() {
 	e.matchingBooksMutex.Lock()
-	e.matchingBooks = make(map[string]*SimplePriceMatching)
+	e.matchingBooks = make(map[string]matchingEngine)
 	for symbol, market := range e.markets {
 		e._addMatchingBook(symbol, market)
 	}
@@ -136,11 +160,23 @@ func (e *Exchange) addMatchingBook(symbol string, market types.Market) {
 }
 
 func (e *Exchange) _addMatchingBook(symbol string, market types.Market) {
-	e.matchingBooks[symbol] = &SimplePriceMatching{
-		CurrentTime:  e.currentTime,
-		Account:      e.account,
-		Market:       market,
-		closedOrders: make(map[uint64]types.Order),
+	matchingConfig := e.config.GetMatching(e.sourceName.String())
+	switch matchingConfig.Engine {
+	case bbgo.BacktestMatchingEngineDepth:
+		// the depth file is opened lazily since we create the matching books for all the markets
+		e.matchingBooks[symbol] = NewDepthMatching(market, e.account, &lazyDepthFeed{
+			dir:    matchingConfig.DepthDataDir,
+			symbol: symbol,
+		}, e.currentTime)
+
+	default:
+		e.matchingBooks[symbol] = &SimplePriceMatching{
+			CurrentTime:  e.currentTime,
+			Account:      e.account,
+			Market:       market,
+			FillModel:    matchingConfig.FillModel,
+			closedOrders: make(map[uint64]types.Order),
+		}
 	}
 }
 
@@ -151,7 +187,11 @@ func (e *Exchange) NewStream() types.Stream {
 }
 
 func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
-	book := e.matchingBooks[q.Symbol]
+	book, ok := e.matchingBook(q.Symbol)
+	if !ok {
+		return nil, fmt.Errorf("matching engine is not initialized for symbol %s", q.Symbol)
+	}
+
 	oid, err := strconv.ParseUint(q.OrderID, 10, 64)
 	if err != nil {
 		return nil, err
@@ -172,6 +212,12 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			return nil, fmt.Errorf("matching engine is not initialized for symbol %s", symbol)
 		}
 
+		if e.isMarginAccount() {
+			if err := e.borrowForOrder(matching, order); err != nil {
+				return nil, err
+			}
+		}
+
 		createdOrder, _, err := matching.PlaceOrder(order)
 		if err != nil {
 			return nil, err
@@ -180,6 +226,15 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 		if createdOrder != nil {
 			createdOrders = append(createdOrders, *createdOrder)
 
+			// the taker trades are settled before the order is returned,
+			// the trades of the remaining quantity are repaid by the trade updates
+			if e.isAutoRepayOrder(order) {
+				e.addAutoRepayOrder(*createdOrder)
+				if createdOrder.ExecutedQuantity.Sign() > 0 {
+					e.repayReceivedAsset(createdOrder.Symbol, createdOrder.Side)
+				}
+			}
+
 			// market order can be closed immediately.
 			switch createdOrder.Status {
 			case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
@@ -197,7 +252,7 @@ func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders [
 		return nil, fmt.Errorf("matching engine is not initialized for symbol %s", symbol)
 	}
 
-	return append(matching.bidOrders, matching.askOrders...), nil
+	return matching.openOrders(), nil
 }
 
 func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
@@ -255,17 +310,8 @@ func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticke
 		return nil, fmt.Errorf("matching engine is not initialized for symbol %s", symbol)
 	}
 
-	kline := matching.LastKLine
-	return &types.Ticker{
-		Time:   kline.EndTime.Time(),
-		Volume: kline.Volume,
-		Last:   kline.Close,
-		Open:   kline.Open,
-		High:   kline.High,
-		Low:    kline.Low,
-		Buy:    kline.Close,
-		Sell:   kline.Close,
-	}, nil
+	ticker := matching.ticker()
+	return &ticker, nil
 }
 
 func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
@@ -293,7 +339,7 @@ func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since
 	return nil, nil
 }
 
-func (e *Exchange) matchingBook(symbol string) (*SimplePriceMatching, bool) {
+func (e *Exchange) matchingBook(symbol string) (matchingEngine, bool) {
 	e.matchingBooksMutex.Lock()
 	m, ok := e.matchingBooks[symbol]
 	e.matchingBooksMutex.Unlock()
@@ -301,8 +347,11 @@ func (e *Exchange) matchingBook(symbol string) (*SimplePriceMatching, bool) {
 }
 
 func (e *Exchange) BindUserData(userDataStream types.StandardStreamEmitter) {
+	e.userDataStream = userDataStream
+
 	userDataStream.OnTradeUpdate(func(trade types.Trade) {
 		e.addTrade(trade)
+		e.handleAutoRepayTrade(trade)
 	})
 
 	e.matchingBooksMutex.Lock()
@@ -335,6 +384,11 @@ func (e *Exchange) SubscribeMarketData(startTime, endTime time.Time, extraInterv
 		case types.KLineChannel:
 			loadedIntervals[sub.Options.Interval] = struct{}{}
 
+		case types.MarketTradeChannel:
+			if !e.config.ReplayMarketTrades {
+				log.Errorf("stream channel %s is only supported when backtest.replayMarketTrades is enabled", sub.Channel)
+			}
+
 		default:
 			// Since Environment is not yet been injected at this point, no hard error
 			log.Errorf("stream channel %s is not supported in backtest", sub.Channel)
@@ -352,6 +406,12 @@ func (e *Exchange) SubscribeMarketData(startTime, endTime time.Time, extraInterv
 	}
 
 	log.Infof("using symbols: %v and intervals: %v for back-testing", symbols, intervals)
+
+	if e.config.ReplayMarketTrades {
+		log.Infof("querying market trades from database...")
+		return e.subscribeMarketTrades(startTime, endTime, symbols, intervals), nil
+	}
+
 	log.Infof("querying klines from database...")
 	klineC, errC := e.srv.QueryKLinesCh(startTime, endTime, e, symbols, intervals)
 	go func() {
@@ -364,16 +424,28 @@ func (e *Exchange) SubscribeMarketData(startTime, endTime time.Time, extraInterv
 
 func (e *Exchange) ConsumeKLine(k types.KLine) {
 	if k.Interval == types.Interval1m {
-		e.currentTime = k.EndTime.Time()
+		if e.config.ReplayMarketTrades {
+			// the orders are matched by the market trades
+			e.consumeMarketTrades(k.EndTime.Time())
+			e.currentTime = k.EndTime.Time()
+		} else {
+			e.currentTime = k.EndTime.Time()
+
+			matching, ok := e.matchingBook(k.Symbol)
+			if !ok {
+				log.Errorf("matching book of %s is not initialized", k.Symbol)
+				return
+			}
 
-		matching, ok := e.matchingBook(k.Symbol)
-		if !ok {
-			log.Errorf("matching book of %s is not initialized", k.Symbol)
-			return
+			// here we generate trades and order updates
+			matching.processKLine(k)
 		}
 
-		// here we generate trades and order updates
-		matching.processKLine(k)
+		if e.isMarginAccount() {
+			e.accrueInterest(e.currentTime)
+			e.payFunding(k)
+			e.updateMarginLevel()
+		}
 	}
 
 	e.MarketDataStream.EmitKLineClosed(k)
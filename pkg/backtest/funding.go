@@ -0,0 +1,48 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func fundingRateFileName(dir, symbol string) string {
+	return filepath.Join(dir, symbol+".jsonl")
+}
+
+// loadFundingRates loads the recorded funding rates of the symbol, one types.FundingRate json object per line,
+// the returned funding rates are sorted by the funding time.
+func loadFundingRates(dir, symbol string) ([]types.FundingRate, error) {
+	f, err := os.Open(fundingRateFileName(dir, symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var rates []types.FundingRate
+	decoder := json.NewDecoder(f)
+	for {
+		var rate types.FundingRate
+		if err := decoder.Decode(&rate); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return nil, fmt.Errorf("funding rate file %s decode error: %w", f.Name(), err)
+		}
+
+		rates = append(rates, rate)
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].FundingTime.Before(rates[j].FundingTime)
+	})
+
+	return rates, nil
+}
@@ -0,0 +1,637 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// The margin simulation of the backtest exchange
+//
+// The margin and futures sessions share the same cross margin account model, the positions are kept in the spot balances,
+// a short position is the borrowed base asset and a leveraged long position is the borrowed quote asset.
+//
+// 1) the margin session borrows the assets by BorrowMarginAsset or the MARGIN_BUY side effect,
+//    and the borrowed assets are charged with the hourly interest.
+// 2) the futures session borrows and repays the assets automatically, and pays the funding fee from the recorded funding rates.
+// 3) the account is liquidated when the margin level (total asset value / total debt value) falls below the threshold.
+
+func (e *Exchange) UseMargin() {
+	e.MarginSettings.UseMargin()
+	e.account.AccountType = types.AccountTypeMargin
+}
+
+func (e *Exchange) UseIsolatedMargin(symbol string) {
+	e.MarginSettings.UseIsolatedMargin(symbol)
+	e.account.AccountType = types.AccountTypeIsolatedMargin
+}
+
+func (e *Exchange) UseFutures() {
+	e.FuturesSettings.UseFutures()
+	e.account.AccountType = types.AccountTypeFutures
+}
+
+func (e *Exchange) UseIsolatedFutures(symbol string) {
+	e.FuturesSettings.UseIsolatedFutures(symbol)
+	e.account.AccountType = types.AccountTypeFutures
+}
+
+func (e *Exchange) isMarginAccount() bool {
+	return e.IsMargin || e.IsFutures
+}
+
+func (e *Exchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if !e.isMarginAccount() {
+		return fmt.Errorf("can not borrow %s, the backtest account is not a margin account", asset)
+	}
+
+	return e.borrow(asset, amount)
+}
+
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if !e.isMarginAccount() {
+		return fmt.Errorf("can not repay %s, the backtest account is not a margin account", asset)
+	}
+
+	return e.repay(asset, amount)
+}
+
+// QueryMarginAssetMaxBorrowable returns the max borrowable amount limited by the leverage
+func (e *Exchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (amount fixedpoint.Value, err error) {
+	price, ok := e.assetPrice(asset)
+	if !ok {
+		return fixedpoint.Zero, fmt.Errorf("can not find the price of %s in %s", asset, e.marginConfig.GetValuationCurrency())
+	}
+
+	_, netValue, debtValue := e.accountValues()
+	maxDebtValue := netValue.Mul(e.marginConfig.GetLeverage().Sub(fixedpoint.One))
+	if maxDebtValue.Compare(debtValue) <= 0 {
+		return fixedpoint.Zero, nil
+	}
+
+	return maxDebtValue.Sub(debtValue).Div(price), nil
+}
+
+func (e *Exchange) QueryLoanHistory(ctx context.Context, asset string, startTime, endTime *time.Time) (loans []types.MarginLoan, err error) {
+	e.marginMutex.Lock()
+	defer e.marginMutex.Unlock()
+
+	for _, loan := range e.loans {
+		if loan.Asset == asset && inTimeRange(loan.Time.Time(), startTime, endTime) {
+			loans = append(loans, loan)
+		}
+	}
+
+	return loans, nil
+}
+
+func (e *Exchange) QueryRepayHistory(ctx context.Context, asset string, startTime, endTime *time.Time) (repays []types.MarginRepay, err error) {
+	e.marginMutex.Lock()
+	defer e.marginMutex.Unlock()
+
+	for _, repay := range e.repays {
+		if repay.Asset == asset && inTimeRange(repay.Time.Time(), startTime, endTime) {
+			repays = append(repays, repay)
+		}
+	}
+
+	return repays, nil
+}
+
+func (e *Exchange) QueryLiquidationHistory(ctx context.Context, startTime, endTime *time.Time) (liquidations []types.MarginLiquidation, err error) {
+	e.marginMutex.Lock()
+	defer e.marginMutex.Unlock()
+
+	for _, liquidation := range e.liquidations {
+		if inTimeRange(liquidation.UpdatedTime.Time(), startTime, endTime) {
+			liquidations = append(liquidations, liquidation)
+		}
+	}
+
+	return liquidations, nil
+}
+
+func (e *Exchange) QueryInterestHistory(ctx context.Context, asset string, startTime, endTime *time.Time) (interests []types.MarginInterest, err error) {
+	e.marginMutex.Lock()
+	defer e.marginMutex.Unlock()
+
+	for _, interest := range e.interests {
+		if interest.Asset == asset && inTimeRange(interest.Time.Time(), startTime, endTime) {
+			interests = append(interests, interest)
+		}
+	}
+
+	return interests, nil
+}
+
+func inTimeRange(t time.Time, startTime, endTime *time.Time) bool {
+	if startTime != nil && t.Before(*startTime) {
+		return false
+	}
+
+	if endTime != nil && t.After(*endTime) {
+		return false
+	}
+
+	return true
+}
+
+func (e *Exchange) borrow(asset string, amount fixedpoint.Value) error {
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("invalid borrow amount %s %s", amount.String(), asset)
+	}
+
+	maxBorrowable, err := e.QueryMarginAssetMaxBorrowable(context.Background(), asset)
+	if err != nil {
+		return err
+	}
+
+	if amount.Compare(maxBorrowable) > 0 {
+		return fmt.Errorf("can not borrow %s %s, exceeds the max borrowable amount %s with leverage %s",
+			amount.String(), asset, maxBorrowable.String(), e.marginConfig.GetLeverage().String())
+	}
+
+	e.account.BorrowBalance(asset, amount)
+
+	e.marginMutex.Lock()
+	e.loans = append(e.loans, types.MarginLoan{
+		Exchange:       e.sourceName,
+		TransactionID:  incTradeID(),
+		Asset:          asset,
+		Principle:      amount,
+		Time:           types.Time(e.currentTime),
+		IsolatedSymbol: e.IsolatedMarginSymbol,
+	})
+	e.marginMutex.Unlock()
+
+	e.emitBalanceUpdate()
+	return nil
+}
+
+func (e *Exchange) repay(asset string, amount fixedpoint.Value) error {
+	if err := e.account.RepayBalance(asset, amount); err != nil {
+		return err
+	}
+
+	e.marginMutex.Lock()
+	e.repays = append(e.repays, types.MarginRepay{
+		Exchange:       e.sourceName,
+		TransactionID:  incTradeID(),
+		Asset:          asset,
+		Principle:      amount,
+		Time:           types.Time(e.currentTime),
+		IsolatedSymbol: e.IsolatedMarginSymbol,
+	})
+	e.marginMutex.Unlock()
+
+	e.emitBalanceUpdate()
+	return nil
+}
+
+// repayAll repays the debt of the asset as much as the available balance can
+func (e *Exchange) repayAll(asset string) {
+	balance, ok := e.account.Balance(asset)
+	if !ok {
+		return
+	}
+
+	amount := fixedpoint.Min(balance.Borrowed.Add(balance.Interest), balance.Available)
+	if amount.Sign() <= 0 {
+		return
+	}
+
+	if err := e.repay(asset, amount); err != nil {
+		log.WithError(err).Errorf("can not repay %s %s", amount.String(), asset)
+	}
+}
+
+func (e *Exchange) emitBalanceUpdate() {
+	if e.userDataStream != nil {
+		e.userDataStream.EmitBalanceUpdate(e.account.Balances())
+	}
+}
+
+// borrowForOrder borrows the insufficient balance for the order, the futures session always borrows,
+// and the margin session borrows for the order with the MARGIN_BUY side effect.
+func (e *Exchange) borrowForOrder(matching matchingEngine, o types.SubmitOrder) error {
+	if !e.IsFutures && o.MarginSideEffect != types.SideEffectTypeMarginBuy {
+		return nil
+	}
+
+	market, ok := e.markets[o.Symbol]
+	if !ok {
+		return fmt.Errorf("market %s is not defined", o.Symbol)
+	}
+
+	var currency string
+	var required fixedpoint.Value
+	switch o.Side {
+	case types.SideTypeBuy:
+		price := o.Price
+		switch o.Type {
+		case types.OrderTypeMarket:
+			price = matching.ticker().Last
+		case types.OrderTypeStopMarket:
+			price = o.StopPrice
+		}
+
+		currency = market.QuoteCurrency
+		required = o.Quantity.Mul(price)
+
+	case types.SideTypeSell:
+		currency = market.BaseCurrency
+		required = o.Quantity
+	}
+
+	balance, _ := e.account.Balance(currency)
+	if balance.Available.Compare(required) >= 0 {
+		return nil
+	}
+
+	return e.borrow(currency, required.Sub(balance.Available))
+}
+
+// isAutoRepayOrder returns true if the received asset of the order should be used to repay the debt
+func (e *Exchange) isAutoRepayOrder(o types.SubmitOrder) bool {
+	return e.IsFutures || (e.IsMargin && o.MarginSideEffect == types.SideEffectTypeAutoRepay)
+}
+
+func (e *Exchange) addAutoRepayOrder(order types.Order) {
+	// the futures session repays all the trades
+	if e.IsFutures {
+		return
+	}
+
+	e.marginMutex.Lock()
+	e.autoRepayOrders[order.OrderID] = struct{}{}
+	e.marginMutex.Unlock()
+}
+
+func (e *Exchange) handleAutoRepayTrade(trade types.Trade) {
+	if !e.isMarginAccount() {
+		return
+	}
+
+	if !e.IsFutures {
+		e.marginMutex.Lock()
+		_, ok := e.autoRepayOrders[trade.OrderID]
+		e.marginMutex.Unlock()
+		if !ok {
+			return
+		}
+	}
+
+	e.repayReceivedAsset(trade.Symbol, trade.Side)
+}
+
+// repayReceivedAsset repays the debt of the asset received by the order side
+func (e *Exchange) repayReceivedAsset(symbol string, side types.SideType) {
+	market, ok := e.markets[symbol]
+	if !ok {
+		return
+	}
+
+	switch side {
+	case types.SideTypeBuy:
+		e.repayAll(market.BaseCurrency)
+	case types.SideTypeSell:
+		e.repayAll(market.QuoteCurrency)
+	}
+}
+
+// assetPrice returns the price of the asset in the valuation currency
+func (e *Exchange) assetPrice(asset string) (fixedpoint.Value, bool) {
+	valuationCurrency := e.marginConfig.GetValuationCurrency()
+	if asset == valuationCurrency {
+		return fixedpoint.One, true
+	}
+
+	for symbol, market := range e.markets {
+		if market.BaseCurrency != asset || market.QuoteCurrency != valuationCurrency {
+			continue
+		}
+
+		matching, ok := e.matchingBook(symbol)
+		if !ok {
+			continue
+		}
+
+		if price := matching.ticker().Last; price.Sign() > 0 {
+			return price, true
+		}
+	}
+
+	return fixedpoint.Zero, false
+}
+
+// accountValues returns the total asset value, net asset value and the total debt value in the valuation currency,
+// the assets without the price are not counted.
+func (e *Exchange) accountValues() (totalValue, netValue, debtValue fixedpoint.Value) {
+	for currency, balance := range e.account.Balances() {
+		price, ok := e.assetPrice(currency)
+		if !ok {
+			continue
+		}
+
+		totalValue = totalValue.Add(balance.Total().Mul(price))
+		netValue = netValue.Add(balance.Net().Mul(price))
+		debtValue = debtValue.Add(balance.Borrowed.Add(balance.Interest).Mul(price))
+	}
+
+	return totalValue, netValue, debtValue
+}
+
+// accrueInterest charges the hourly interest of the borrowed assets, only the margin session pays the interest
+func (e *Exchange) accrueInterest(t time.Time) {
+	if !e.IsMargin {
+		return
+	}
+
+	hour := t.Truncate(time.Hour)
+	if e.lastInterestTime.IsZero() {
+		e.lastInterestTime = hour
+		return
+	}
+
+	for e.lastInterestTime.Before(hour) {
+		e.lastInterestTime = e.lastInterestTime.Add(time.Hour)
+
+		for currency, balance := range e.account.Balances() {
+			if balance.Borrowed.Sign() <= 0 {
+				continue
+			}
+
+			rate := e.marginConfig.GetInterestRate(currency)
+			interest := balance.Borrowed.Mul(rate)
+			if interest.Sign() <= 0 {
+				continue
+			}
+
+			e.account.AddInterest(currency, interest)
+
+			e.marginMutex.Lock()
+			e.interests = append(e.interests, types.MarginInterest{
+				Exchange:       e.sourceName,
+				Asset:          currency,
+				Principle:      balance.Borrowed,
+				Interest:       interest,
+				InterestRate:   rate,
+				IsolatedSymbol: e.IsolatedMarginSymbol,
+				Time:           types.Time(e.lastInterestTime),
+			})
+			e.marginMutex.Unlock()
+		}
+
+		e.emitBalanceUpdate()
+	}
+}
+
+// payFunding pays or receives the funding fee of the futures position by the recorded funding rates,
+// the position is the net base asset, the long position pays the funding fee when the funding rate is positive.
+func (e *Exchange) payFunding(k types.KLine) {
+	if !e.IsFutures || len(e.marginConfig.FundingRateDataDir) == 0 {
+		return
+	}
+
+	market, ok := e.markets[k.Symbol]
+	if !ok {
+		return
+	}
+
+	e.marginMutex.Lock()
+	rates, loaded := e.fundingRates[k.Symbol]
+	if !loaded {
+		var err error
+		rates, err = loadFundingRates(e.marginConfig.FundingRateDataDir, k.Symbol)
+		if os.IsNotExist(err) {
+			log.Warnf("funding rate file of %s is not found in %s, the funding fee will not be paid", k.Symbol, e.marginConfig.FundingRateDataDir)
+		} else if err != nil {
+			log.WithError(err).Errorf("can not load the funding rates of %s", k.Symbol)
+		}
+	}
+
+	var dueRates []types.FundingRate
+	for len(rates) > 0 && !rates[0].FundingTime.After(k.EndTime.Time()) {
+		// the funding rates before the backtest are skipped
+		if !rates[0].FundingTime.Before(k.StartTime.Time()) {
+			dueRates = append(dueRates, rates[0])
+		}
+		rates = rates[1:]
+	}
+	e.fundingRates[k.Symbol] = rates
+	e.marginMutex.Unlock()
+
+	if len(dueRates) == 0 {
+		return
+	}
+
+	for _, rate := range dueRates {
+		balance, _ := e.account.Balance(market.BaseCurrency)
+		position := balance.Net()
+		if position.IsZero() {
+			continue
+		}
+
+		fee := position.Mul(k.Close).Mul(rate.FundingRate)
+		e.account.AddBalance(market.QuoteCurrency, fee.Neg())
+		log.Infof("funding fee %s %s paid for %s position %s at funding rate %s",
+			fee.String(), market.QuoteCurrency, k.Symbol, position.String(), rate.FundingRate.String())
+	}
+
+	e.emitBalanceUpdate()
+}
+
+// updateMarginLevel updates the margin level of the account and liquidates the account if the margin level is too low
+func (e *Exchange) updateMarginLevel() {
+	if !e.isMarginAccount() {
+		return
+	}
+
+	totalValue, _, debtValue := e.accountValues()
+	if debtValue.Sign() <= 0 {
+		return
+	}
+
+	marginLevel := totalValue.Div(debtValue)
+	e.account.Lock()
+	e.account.MarginLevel = marginLevel
+	e.account.Unlock()
+
+	if marginLevel.Compare(e.marginConfig.GetLiquidationMarginLevel()) < 0 {
+		log.Warnf("margin level %s is below the liquidation margin level %s, liquidating the account",
+			marginLevel.String(), e.marginConfig.GetLiquidationMarginLevel().String())
+		if err := e.liquidate(); err != nil {
+			log.WithError(err).Error("liquidation failed")
+		}
+	}
+}
+
+// liquidate cancels all the open orders, sells the assets without debt, buys back the borrowed assets
+// with market orders and then repays all the debts. The quantity that can not be filled by the market orders
+// is force-closed at the mark price.
+func (e *Exchange) liquidate() error {
+	var errs error
+	valuationCurrency := e.marginConfig.GetValuationCurrency()
+
+	e.matchingBooksMutex.Lock()
+	books := make(map[string]matchingEngine, len(e.matchingBooks))
+	for symbol, matching := range e.matchingBooks {
+		books[symbol] = matching
+	}
+	e.matchingBooksMutex.Unlock()
+
+	for _, matching := range books {
+		for _, order := range matching.openOrders() {
+			if _, err := matching.CancelOrder(order); err != nil {
+				log.WithError(err).Errorf("liquidation: can not cancel order %d", order.OrderID)
+			}
+		}
+	}
+
+	// sell the assets without debt
+	for symbol, matching := range books {
+		market := e.markets[symbol]
+		if market.QuoteCurrency != valuationCurrency {
+			continue
+		}
+
+		balance, ok := e.account.Balance(market.BaseCurrency)
+		if !ok || balance.Borrowed.Sign() > 0 || balance.Interest.Sign() > 0 {
+			continue
+		}
+
+		quantity := balance.Available
+		if market.StepSize.Sign() > 0 {
+			quantity = market.TruncateQuantity(quantity)
+		}
+
+		if quantity.Compare(market.MinQuantity) < 0 {
+			continue
+		}
+
+		errs = multierr.Append(errs, e.placeLiquidationOrder(matching, market, types.SideTypeSell, quantity))
+	}
+
+	// buy back the borrowed assets
+	for symbol, matching := range books {
+		market := e.markets[symbol]
+		if market.QuoteCurrency != valuationCurrency {
+			continue
+		}
+
+		balance, ok := e.account.Balance(market.BaseCurrency)
+		if !ok {
+			continue
+		}
+
+		need := balance.Borrowed.Add(balance.Interest).Sub(balance.Available)
+		if need.Sign() <= 0 {
+			continue
+		}
+
+		// round up the quantity to cover the debt
+		quantity := need
+		if market.StepSize.Sign() > 0 {
+			quantity = market.TruncateQuantity(need)
+			if quantity.Compare(need) < 0 {
+				quantity = quantity.Add(market.StepSize)
+			}
+		}
+		quantity = fixedpoint.Max(quantity, market.MinQuantity)
+
+		errs = multierr.Append(errs, e.placeLiquidationOrder(matching, market, types.SideTypeBuy, quantity))
+	}
+
+	for currency := range e.account.Balances() {
+		e.repayAll(currency)
+	}
+
+	return errs
+}
+
+func (e *Exchange) placeLiquidationOrder(matching matchingEngine, market types.Market, side types.SideType, quantity fixedpoint.Value) error {
+	order, _, err := matching.PlaceOrder(types.SubmitOrder{
+		Symbol:   market.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   market,
+		Tag:      "liquidation",
+	})
+	if err != nil {
+		log.WithError(err).Warnf("liquidation: can not %s %s %s, force closing at the mark price", side, quantity.String(), market.Symbol)
+		return e.forceClose(matching, market, side, quantity)
+	}
+
+	switch order.Status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		e.addClosedOrder(*order)
+	}
+
+	e.addLiquidation(types.MarginLiquidation{
+		Exchange:         e.sourceName,
+		AveragePrice:     order.Price,
+		ExecutedQuantity: order.ExecutedQuantity,
+		OrderID:          order.OrderID,
+		Price:            order.Price,
+		Quantity:         order.Quantity,
+		Side:             order.Side,
+		Symbol:           order.Symbol,
+		TimeInForce:      order.TimeInForce,
+		IsIsolated:       e.IsIsolatedMargin || e.IsIsolatedFutures,
+		UpdatedTime:      types.Time(e.currentTime),
+	})
+
+	if remaining := quantity.Sub(order.ExecutedQuantity); remaining.Sign() > 0 {
+		return e.forceClose(matching, market, side, remaining)
+	}
+
+	return nil
+}
+
+// forceClose closes the position at the mark price without an order, it's used when the liquidation order can not
+// be placed or filled, e.g., the quote balance is not enough to buy back the borrowed asset after a price gap.
+// The quote balance can become negative, which is the loss beyond the account value.
+func (e *Exchange) forceClose(matching matchingEngine, market types.Market, side types.SideType, quantity fixedpoint.Value) error {
+	price := matching.ticker().Last
+	if price.Sign() <= 0 {
+		return fmt.Errorf("liquidation: can not force close %s %s %s, the mark price is unknown", side, quantity.String(), market.Symbol)
+	}
+
+	quoteQuantity := quantity.Mul(price)
+	switch side {
+	case types.SideTypeBuy:
+		e.account.AddBalance(market.BaseCurrency, quantity)
+		e.account.AddBalance(market.QuoteCurrency, quoteQuantity.Neg())
+	case types.SideTypeSell:
+		e.account.AddBalance(market.BaseCurrency, quantity.Neg())
+		e.account.AddBalance(market.QuoteCurrency, quoteQuantity)
+	}
+
+	e.addLiquidation(types.MarginLiquidation{
+		Exchange:         e.sourceName,
+		AveragePrice:     price,
+		ExecutedQuantity: quantity,
+		Price:            price,
+		Quantity:         quantity,
+		Side:             side,
+		Symbol:           market.Symbol,
+		IsIsolated:       e.IsIsolatedMargin || e.IsIsolatedFutures,
+		UpdatedTime:      types.Time(e.currentTime),
+	})
+
+	e.emitBalanceUpdate()
+	return nil
+}
+
+func (e *Exchange) addLiquidation(liquidation types.MarginLiquidation) {
+	e.marginMutex.Lock()
+	e.liquidations = append(e.liquidations, liquidation)
+	e.marginMutex.Unlock()
+}
@@ -0,0 +1,217 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestExchange(config *bbgo.Backtest, marginConfig bbgo.BacktestMargin) *Exchange {
+	account := &types.Account{AccountType: types.AccountTypeSpot}
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	market := getTestMarket()
+	e := &Exchange{
+		sourceName:       types.ExchangeBinance,
+		config:           config,
+		account:          account,
+		markets:          types.MarketMap{market.Symbol: market},
+		closedOrders:     make(map[string][]types.Order),
+		trades:           make(map[string][]types.Trade),
+		marginConfig:     marginConfig,
+		autoRepayOrders:  make(map[uint64]struct{}),
+		fundingRates:     make(map[string][]types.FundingRate),
+		MarketDataStream: &types.StandardStream{},
+	}
+	e.resetMatchingBooks()
+	e.BindUserData(&types.StandardStream{})
+	return e
+}
+
+func newMarketOrder(side types.SideType, quantity float64, sideEffect types.MarginOrderSideEffectType) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:           "BTCUSDT",
+		Side:             side,
+		Type:             types.OrderTypeMarket,
+		Quantity:         fixedpoint.NewFromFloat(quantity),
+		MarginSideEffect: sideEffect,
+	}
+}
+
+func TestExchange_MarginBorrowAndInterest(t *testing.T) {
+	ctx := context.Background()
+	e := newTestExchange(&bbgo.Backtest{}, bbgo.BacktestMargin{
+		DefaultInterestRate: fixedpoint.NewFromFloat(0.0001),
+	})
+	e.UseMargin()
+
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	// net asset value 10000 USDT with the default 3x leverage
+	maxBorrowable, err := e.QueryMarginAssetMaxBorrowable(ctx, "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", maxBorrowable.String())
+
+	_, err = e.SubmitOrders(ctx, newMarketOrder(types.SideTypeSell, 1.5, types.SideEffectTypeMarginBuy))
+	assert.Error(t, err)
+
+	_, err = e.SubmitOrders(ctx, newMarketOrder(types.SideTypeSell, 1.0, types.SideEffectTypeMarginBuy))
+	assert.NoError(t, err)
+
+	btc, ok := e.account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "1", btc.Borrowed.String())
+	assert.Equal(t, "0", btc.Available.String())
+
+	// the interest is charged hourly
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1.Add(time.Hour), 20000, 20000, 20000, 20000))
+
+	btc, ok = e.account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "0.0001", btc.Interest.String())
+
+	interests, err := e.QueryInterestHistory(ctx, "BTC", nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, interests, 1)
+
+	_, err = e.SubmitOrders(ctx, newMarketOrder(types.SideTypeBuy, 1.0001, types.SideEffectTypeAutoRepay))
+	assert.NoError(t, err)
+
+	btc, ok = e.account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "0", btc.Borrowed.String())
+	assert.Equal(t, "0", btc.Interest.String())
+
+	repays, err := e.QueryRepayHistory(ctx, "BTC", nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, repays, 1)
+}
+
+func TestExchange_MarginLiquidation(t *testing.T) {
+	ctx := context.Background()
+	e := newTestExchange(&bbgo.Backtest{}, bbgo.BacktestMargin{})
+	e.UseMargin()
+
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	_, err := e.SubmitOrders(ctx, newMarketOrder(types.SideTypeSell, 1.0, types.SideEffectTypeMarginBuy))
+	assert.NoError(t, err)
+
+	// margin level = 30000 / 25000 = 1.2
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1.Add(time.Minute), 20000, 25000, 20000, 25000))
+	assert.Equal(t, "1.2", e.account.MarginLevel.String())
+
+	liquidations, err := e.QueryLiquidationHistory(ctx, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, liquidations, 0)
+
+	// margin level = 30000 / 28000 < 1.1
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1.Add(2*time.Minute), 25000, 28000, 25000, 28000))
+
+	liquidations, err = e.QueryLiquidationHistory(ctx, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, liquidations, 1) {
+		assert.Equal(t, types.SideTypeBuy, liquidations[0].Side)
+		assert.Equal(t, "1", liquidations[0].ExecutedQuantity.String())
+	}
+
+	btc, ok := e.account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "0", btc.Borrowed.String())
+
+	usdt, ok := e.account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "2000", usdt.Available.String())
+}
+
+func TestExchange_MarginLiquidation_ForceClose(t *testing.T) {
+	ctx := context.Background()
+	e := newTestExchange(&bbgo.Backtest{}, bbgo.BacktestMargin{})
+	e.UseMargin()
+
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	_, err := e.SubmitOrders(ctx, newMarketOrder(types.SideTypeSell, 1.0, types.SideEffectTypeMarginBuy))
+	assert.NoError(t, err)
+
+	// the price gaps to 40000, 30000 USDT can not buy back the borrowed 1 BTC
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1.Add(time.Minute), 40000, 40000, 40000, 40000))
+
+	liquidations, err := e.QueryLiquidationHistory(ctx, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, liquidations, 1) {
+		assert.Equal(t, types.SideTypeBuy, liquidations[0].Side)
+		assert.Equal(t, "1", liquidations[0].ExecutedQuantity.String())
+		assert.Equal(t, "40000", liquidations[0].Price.String())
+	}
+
+	btc, ok := e.account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "0", btc.Borrowed.String())
+	assert.Equal(t, "0", btc.Available.String())
+
+	usdt, ok := e.account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "-10000", usdt.Available.String())
+}
+
+func TestExchange_FuturesFunding(t *testing.T) {
+	ctx := context.Background()
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "BTCUSDT.jsonl"))
+	assert.NoError(t, err)
+
+	encoder := json.NewEncoder(f)
+	for _, rate := range []types.FundingRate{
+		{FundingRate: fixedpoint.NewFromFloat(0.0001), FundingTime: t1.Add(-8 * time.Hour)},
+		{FundingRate: fixedpoint.NewFromFloat(0.0001), FundingTime: t1.Add(8 * time.Hour)},
+	} {
+		assert.NoError(t, encoder.Encode(rate))
+	}
+	assert.NoError(t, f.Close())
+
+	e := newTestExchange(&bbgo.Backtest{}, bbgo.BacktestMargin{FundingRateDataDir: dir})
+	e.UseFutures()
+
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 20000, 20000))
+
+	// the short position borrows the base asset automatically
+	_, err = e.SubmitOrders(ctx, newMarketOrder(types.SideTypeSell, 0.5, types.SideEffectTypeNoSideEffect))
+	assert.NoError(t, err)
+
+	btc, ok := e.account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "0.5", btc.Borrowed.String())
+
+	// the short position receives the funding fee: 0.5 * 20000 * 0.0001
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1.Add(8*time.Hour), 20000, 20000, 20000, 20000))
+
+	usdt, ok := e.account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "20001", usdt.Available.String())
+
+	// closing the short position repays the debt automatically
+	_, err = e.SubmitOrders(ctx, newMarketOrder(types.SideTypeBuy, 0.5, types.SideEffectTypeNoSideEffect))
+	assert.NoError(t, err)
+
+	btc, ok = e.account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "0", btc.Borrowed.String())
+	assert.Equal(t, "0", btc.Available.String())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
@@ -47,6 +48,22 @@ func init() {
 	}
 }
 
+// matchingEngine is the interface of the backtest matching engines,
+// the engine emits the same order, trade and balance updates as the real exchange user data stream does.
+type matchingEngine interface {
+	PlaceOrder(o types.SubmitOrder) (*types.Order, *types.Trade, error)
+	CancelOrder(o types.Order) (types.Order, error)
+
+	OnTradeUpdate(cb func(trade types.Trade))
+	OnOrderUpdate(cb func(order types.Order))
+	OnBalanceUpdate(cb func(balances types.BalanceMap))
+
+	getOrder(orderID uint64) (types.Order, bool)
+	openOrders() []types.Order
+	ticker() types.Ticker
+	processKLine(kline types.KLine)
+}
+
 // SimplePriceMatching implements a simple kline data driven matching engine for backtest
 //go:generate callbackgen -type SimplePriceMatching
 type SimplePriceMatching struct {
@@ -64,6 +81,16 @@ type SimplePriceMatching struct {
 
 	Account *types.Account
 
+	// FillModel controls how the resting limit orders are filled by the kline,
+	// the zero value fills the whole order once the price touches it.
+	FillModel bbgo.BacktestFillModel
+
+	// currentKLine is the kline being processed
+	currentKLine types.KLine
+
+	// klineFilled is the quantity filled by the current kline, indexed by order id
+	klineFilled map[uint64]fixedpoint.Value
+
 	tradeUpdateCallbacks   []func(trade types.Trade)
 	orderUpdateCallbacks   []func(order types.Order)
 	balanceUpdateCallbacks []func(balances types.BalanceMap)
@@ -79,6 +106,8 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		var orders []types.Order
 		for _, order := range m.bidOrders {
 			if o.OrderID == order.OrderID {
+				// use the order in the book, which has the latest executed quantity
+				o = order
 				found = true
 				continue
 			}
@@ -92,6 +121,7 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		var orders []types.Order
 		for _, order := range m.askOrders {
 			if o.OrderID == order.OrderID {
+				o = order
 				found = true
 				continue
 			}
@@ -105,14 +135,16 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		return o, fmt.Errorf("cancel order failed, order %d not found: %+v", o.OrderID, o)
 	}
 
+	// only the remaining quantity is still locked
+	remaining := o.Quantity.Sub(o.ExecutedQuantity)
 	switch o.Side {
 	case types.SideTypeBuy:
-		if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, o.Price.Mul(o.Quantity)); err != nil {
+		if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, o.Price.Mul(remaining)); err != nil {
 			return o, err
 		}
 
 	case types.SideTypeSell:
-		if err := m.Account.UnlockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
+		if err := m.Account.UnlockBalance(m.Market.BaseCurrency, remaining); err != nil {
 			return o, err
 		}
 	}
@@ -133,6 +165,12 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 
 	isTaker := o.Type == types.OrderTypeMarket || isLimitTakerOrder(o, m.LastPrice)
 
+	// like the real exchanges, the limit maker order is rejected if it would immediately match,
+	// an order at the last price is accepted since the last price might be the price of the other side of the book
+	if o.Type == types.OrderTypeLimitMaker && isCrossingPrice(o.Side, o.Price, m.LastPrice) && o.Price.Compare(m.LastPrice) != 0 {
+		return nil, nil, fmt.Errorf("limit maker order would immediately match and take, last price %s, order: %+v", m.LastPrice.String(), o)
+	}
+
 	// price for checking account balance, default price
 	price := o.Price
 
@@ -180,31 +218,67 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 			order.Price = m.LastPrice
 		}
 
+		// copy the order object to avoid side effect (for different callbacks)
+		var order2 = order
+
+		// the last price is the best price we have, the limit taker order takes the liquidity at the last price,
+		// and the remaining quantity (if any) is placed as a maker order at the limit price.
+		quantity := m.takerFillQuantity(order2)
+		if quantity.IsZero() {
+			m.addMakerOrder(order2)
+			m.EmitOrderUpdate(order2) // emit order New status
+			return &order2, nil, nil
+		}
+
 		// emit the order update for Status:New
 		m.EmitOrderUpdate(order)
 
-		// copy the order object to avoid side effect (for different callbacks)
-		var order2 = order
+		// the buy order locked the balance by the limit price, release the price difference
+		if order2.Side == types.SideTypeBuy && order2.Price.Compare(m.LastPrice) > 0 {
+			if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, order2.Price.Sub(m.LastPrice).Mul(quantity)); err != nil {
+				return nil, nil, err
+			}
+		}
 
 		// emit trade before we publish order
-		trade := m.newTradeFromOrder(&order2, false, m.LastPrice)
+		trade := m.newTradeFromOrder(&order2, false, m.LastPrice, quantity)
 		m.executeTrade(trade)
 
 		// update the order status
-		order2.Status = types.OrderStatusFilled
-		order2.ExecutedQuantity = order2.Quantity
-		order2.IsWorking = false
+		m.fillOrder(&order2, quantity)
+		if order2.Status == types.OrderStatusFilled {
+			m.closedOrders[order2.OrderID] = order2
+		} else {
+			m.addMakerOrder(order2)
+		}
 
 		m.EmitOrderUpdate(order2)
-
-		// let the exchange emit the "FILLED" order update (we need the closed order)
-		// m.EmitOrderUpdate(order2)
 		return &order2, &trade, nil
 	}
 
 	// For limit maker orders (open status)
-	// TODO: handle limit taker order
-	switch o.Side {
+	m.addMakerOrder(order)
+	m.EmitOrderUpdate(order) // emit order New status
+	return &order, nil, nil
+}
+
+// takerFillQuantity returns the quantity of the taker order that can be filled immediately,
+// with the volume ratio of the fill model, a limit order can only take the ratio of the last kline volume.
+func (m *SimplePriceMatching) takerFillQuantity(o types.Order) fixedpoint.Value {
+	if o.Type == types.OrderTypeMarket || m.FillModel.VolumeRatio.Sign() <= 0 || m.LastKLine.Volume.IsZero() {
+		return o.Quantity
+	}
+
+	quantity := m.LastKLine.Volume.Mul(m.FillModel.VolumeRatio)
+	if m.Market.StepSize.Sign() > 0 {
+		quantity = m.Market.TruncateQuantity(quantity)
+	}
+
+	return fixedpoint.Max(fixedpoint.Zero, fixedpoint.Min(quantity, o.Quantity))
+}
+
+func (m *SimplePriceMatching) addMakerOrder(order types.Order) {
+	switch order.Side {
 
 	case types.SideTypeBuy:
 		m.mu.Lock()
@@ -216,41 +290,41 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 		m.askOrders = append(m.askOrders, order)
 		m.mu.Unlock()
 	}
-
-	m.EmitOrderUpdate(order) // emit order New status
-	return &order, nil, nil
 }
 
 func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
-	var err error
-	// execute trade, update account balances
+	if err := settleTrade(m.Account, m.Market, trade); err != nil {
+		panic(errors.Wrapf(err, "executeTrade exception, wanted to use more than the locked balance"))
+	}
+
+	m.EmitTradeUpdate(trade)
+	m.EmitBalanceUpdate(m.Account.Balances())
+}
+
+// settleTrade uses the locked balance of the trade and adds the received asset (fee excluded) to the account
+func settleTrade(account *types.Account, market types.Market, trade types.Trade) (err error) {
 	if trade.IsBuyer {
-		err = m.Account.UseLockedBalance(m.Market.QuoteCurrency, trade.QuoteQuantity)
+		err = account.UseLockedBalance(market.QuoteCurrency, trade.QuoteQuantity)
 
 		// here the fee currency is the base currency
 		q := trade.Quantity
-		if trade.FeeCurrency == m.Market.BaseCurrency {
+		if trade.FeeCurrency == market.BaseCurrency {
 			q = q.Sub(trade.Fee)
 		}
 
-		m.Account.AddBalance(m.Market.BaseCurrency, q)
+		account.AddBalance(market.BaseCurrency, q)
 	} else {
-		err = m.Account.UseLockedBalance(m.Market.BaseCurrency, trade.Quantity)
+		err = account.UseLockedBalance(market.BaseCurrency, trade.Quantity)
 
 		// here the fee currency is the quote currency
 		qq := trade.QuoteQuantity
-		if trade.FeeCurrency == m.Market.QuoteCurrency {
+		if trade.FeeCurrency == market.QuoteCurrency {
 			qq = qq.Sub(trade.Fee)
 		}
-		m.Account.AddBalance(m.Market.QuoteCurrency, qq)
+		account.AddBalance(market.QuoteCurrency, qq)
 	}
 
-	if err != nil {
-		panic(errors.Wrapf(err, "executeTrade exception, wanted to use more than the locked balance"))
-	}
-
-	m.EmitTradeUpdate(trade)
-	m.EmitBalanceUpdate(m.Account.Balances())
+	return err
 }
 
 func (m *SimplePriceMatching) getFeeRate(isMaker bool) (feeRate fixedpoint.Value) {
@@ -264,11 +338,11 @@ func (m *SimplePriceMatching) getFeeRate(isMaker bool) (feeRate fixedpoint.Value
 	return feeRate
 }
 
-func (m *SimplePriceMatching) newTradeFromOrder(order *types.Order, isMaker bool, price fixedpoint.Value) types.Trade {
+func (m *SimplePriceMatching) newTradeFromOrder(order *types.Order, isMaker bool, price, quantity fixedpoint.Value) types.Trade {
 	// BINANCE uses 0.1% for both maker and taker
 	// MAX uses 0.050% for maker and 0.15% for taker
 	var feeRate = m.getFeeRate(isMaker)
-	var quoteQuantity = order.Quantity.Mul(price)
+	var quoteQuantity = quantity.Mul(price)
 	var fee fixedpoint.Value
 	var feeCurrency string
 
@@ -276,7 +350,11 @@ func (m *SimplePriceMatching) newTradeFromOrder(order *types.Order, isMaker bool
 		feeCurrency = FeeToken
 		fee = quoteQuantity.Mul(feeRate)
 	} else {
-		fee, feeCurrency = calculateNativeOrderFee(order, m.Market, feeRate)
+		// the fee is calculated by the executed part of the order
+		executed := *order
+		executed.Price = price
+		executed.Quantity = quantity
+		fee, feeCurrency = calculateNativeOrderFee(&executed, m.Market, feeRate)
 	}
 
 	// update order time
@@ -288,7 +366,7 @@ func (m *SimplePriceMatching) newTradeFromOrder(order *types.Order, isMaker bool
 		OrderID:       order.OrderID,
 		Exchange:      types.ExchangeBacktest,
 		Price:         price,
-		Quantity:      order.Quantity,
+		Quantity:      quantity,
 		QuoteQuantity: quoteQuantity,
 		Symbol:        order.Symbol,
 		Side:          order.Side,
@@ -300,10 +378,98 @@ func (m *SimplePriceMatching) newTradeFromOrder(order *types.Order, isMaker bool
 	}
 }
 
+// orderFill is the quantity of an order executed in one matching round
+type orderFill struct {
+	order    types.Order
+	quantity fixedpoint.Value
+}
+
+// fillOrder updates the executed quantity and the status of the order
+func (m *SimplePriceMatching) fillOrder(o *types.Order, quantity fixedpoint.Value) orderFill {
+	o.ExecutedQuantity = o.ExecutedQuantity.Add(quantity)
+	o.UpdateTime = types.Time(m.CurrentTime)
+	if o.ExecutedQuantity.Compare(o.Quantity) >= 0 {
+		o.ExecutedQuantity = o.Quantity
+		o.Status = types.OrderStatusFilled
+		o.IsWorking = false
+	} else {
+		o.Status = types.OrderStatusPartiallyFilled
+	}
+
+	return orderFill{order: *o, quantity: quantity}
+}
+
+// fillRemaining fills the rest of the order completely
+func (m *SimplePriceMatching) fillRemaining(o *types.Order) orderFill {
+	return m.fillOrder(o, o.Quantity.Sub(o.ExecutedQuantity))
+}
+
+// makerFillQuantity returns the quantity of the resting limit order that can be filled when the price moves to the given price,
+// according to the fill model.
+func (m *SimplePriceMatching) makerFillQuantity(o types.Order, price fixedpoint.Value) fixedpoint.Value {
+	threshold := o.Price
+	if m.FillModel.TradeThroughTicks > 0 {
+		offset := m.Market.TickSize.Mul(fixedpoint.NewFromInt(int64(m.FillModel.TradeThroughTicks)))
+		if o.Side == types.SideTypeBuy {
+			threshold = threshold.Sub(offset)
+		} else {
+			threshold = threshold.Add(offset)
+		}
+	}
+
+	switch o.Side {
+	case types.SideTypeBuy:
+		if price.Compare(threshold) > 0 {
+			return fixedpoint.Zero
+		}
+
+	case types.SideTypeSell:
+		if price.Compare(threshold) < 0 {
+			return fixedpoint.Zero
+		}
+	}
+
+	remaining := o.Quantity.Sub(o.ExecutedQuantity)
+	if m.FillModel.VolumeRatio.Sign() <= 0 {
+		return remaining
+	}
+
+	// estimate the volume traded beyond the order price by the price range of the kline
+	k := m.currentKLine
+	volume := k.Volume
+	if priceRange := k.High.Sub(k.Low); priceRange.Sign() > 0 {
+		var distance fixedpoint.Value
+		if o.Side == types.SideTypeBuy {
+			distance = o.Price.Sub(k.Low)
+		} else {
+			distance = k.High.Sub(o.Price)
+		}
+
+		volume = volume.Mul(fixedpoint.Min(distance, priceRange)).Div(priceRange)
+	}
+
+	quantity := volume.Mul(m.FillModel.VolumeRatio).Sub(m.klineFilled[o.OrderID])
+	if m.Market.StepSize.Sign() > 0 {
+		quantity = m.Market.TruncateQuantity(quantity)
+	}
+
+	if quantity.Sign() <= 0 {
+		return fixedpoint.Zero
+	}
+
+	quantity = fixedpoint.Min(quantity, remaining)
+	if m.klineFilled == nil {
+		m.klineFilled = make(map[uint64]fixedpoint.Value)
+	}
+	m.klineFilled[o.OrderID] = m.klineFilled[o.OrderID].Add(quantity)
+	return quantity
+}
+
 // buyToPrice means price go up and the limit sell should be triggered
 func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders []types.Order, trades []types.Trade) {
 	klineMatchingLogger.Debugf("kline buy to price %s", price.String())
 
+	var fills []orderFill
 	var bidOrders []types.Order
 	for _, o := range m.bidOrders {
 		switch o.Type {
@@ -317,10 +483,8 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 			}
 
 			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
 			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
+			fills = append(fills, m.fillRemaining(&o))
 
 		case types.OrderTypeStopLimit:
 			// the price is still lower than the stop price, we will put the order back to the list
@@ -340,9 +504,7 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 				// we assume that we have no price slippage here, so the latest price will be the executed price
 				// TODO: simulate slippage here
 				o.Price = price
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
+				fills = append(fills, m.fillRemaining(&o))
 			} else {
 				// keep it as a maker order
 				bidOrders = append(bidOrders, o)
@@ -366,10 +528,8 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 			}
 
 			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
 			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
+			fills = append(fills, m.fillRemaining(&o))
 
 		case types.OrderTypeStopLimit:
 			// should we trigger the order?
@@ -387,21 +547,18 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 				// we assume that we have no price slippage here, so the latest price will be the executed price
 				// TODO: simulate slippage here
 				o.Price = price
-
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
+				fills = append(fills, m.fillRemaining(&o))
 			} else {
 				// maker order
 				askOrders = append(askOrders, o)
 			}
 
 		case types.OrderTypeLimit, types.OrderTypeLimitMaker:
-			if price.Compare(o.Price) >= 0 {
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-			} else {
+			if quantity := m.makerFillQuantity(o, price); quantity.Sign() > 0 {
+				fills = append(fills, m.fillOrder(&o, quantity))
+			}
+
+			if o.Status != types.OrderStatusFilled {
 				askOrders = append(askOrders, o)
 			}
 
@@ -414,20 +571,7 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 	m.askOrders = askOrders
 	m.LastPrice = price
 
-	for i := range closedOrders {
-		o := closedOrders[i]
-		trade := m.newTradeFromOrder(&o, true, o.Price)
-		m.executeTrade(trade)
-		closedOrders[i] = o
-
-		trades = append(trades, trade)
-
-		m.EmitOrderUpdate(o)
-
-		m.closedOrders[o.OrderID] = o
-	}
-
-	return closedOrders, trades
+	return m.executeFills(fills)
 }
 
 // sellToPrice simulates the price trend in down direction.
@@ -436,6 +580,7 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 	klineMatchingLogger.Debugf("kline sell to price %s", price.String())
 
 	// in this section we handle --- the price goes lower, and we trigger the stop sell
+	var fills []orderFill
 	var askOrders []types.Order
 	for _, o := range m.askOrders {
 		switch o.Type {
@@ -448,10 +593,8 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 			}
 
 			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
 			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
+			fills = append(fills, m.fillRemaining(&o))
 
 		case types.OrderTypeStopLimit:
 			// if the price is lower than the stop price
@@ -468,9 +611,7 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 			// it's a taker order
 			if o.Price.Compare(price) <= 0 {
 				o.Price = price
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
+				fills = append(fills, m.fillRemaining(&o))
 			} else {
 				askOrders = append(askOrders, o)
 			}
@@ -495,10 +636,8 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 			}
 
 			o.Type = types.OrderTypeMarket
-			o.ExecutedQuantity = o.Quantity
 			o.Price = price
-			o.Status = types.OrderStatusFilled
-			closedOrders = append(closedOrders, o)
+			fills = append(fills, m.fillRemaining(&o))
 
 		case types.OrderTypeStopLimit:
 			// price goes down and if the stop price is still lower than the current price
@@ -514,19 +653,17 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 			// taker order?
 			if o.Price.Compare(price) >= 0 {
 				o.Price = price
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
+				fills = append(fills, m.fillRemaining(&o))
 			} else {
 				bidOrders = append(bidOrders, o)
 			}
 
 		case types.OrderTypeLimit, types.OrderTypeLimitMaker:
-			if price.Compare(o.Price) <= 0 {
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-			} else {
+			if quantity := m.makerFillQuantity(o, price); quantity.Sign() > 0 {
+				fills = append(fills, m.fillOrder(&o, quantity))
+			}
+
+			if o.Status != types.OrderStatusFilled {
 				bidOrders = append(bidOrders, o)
 			}
 
@@ -538,17 +675,24 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 	m.bidOrders = bidOrders
 	m.LastPrice = price
 
-	for i := range closedOrders {
-		o := closedOrders[i]
-		trade := m.newTradeFromOrder(&o, true, o.Price)
-		m.executeTrade(trade)
-		closedOrders[i] = o
+	return m.executeFills(fills)
+}
 
+// executeFills executes the trades of the filled quantities,
+// the fully filled orders are moved to the closed orders and returned.
+func (m *SimplePriceMatching) executeFills(fills []orderFill) (closedOrders []types.Order, trades []types.Trade) {
+	for _, fill := range fills {
+		o := fill.order
+		trade := m.newTradeFromOrder(&o, true, o.Price, fill.quantity)
+		m.executeTrade(trade)
 		trades = append(trades, trade)
 
 		m.EmitOrderUpdate(o)
 
-		m.closedOrders[o.OrderID] = o
+		if o.Status == types.OrderStatusFilled {
+			closedOrders = append(closedOrders, o)
+			m.closedOrders[o.OrderID] = o
+		}
 	}
 
 	return closedOrders, trades
@@ -574,8 +718,30 @@ func (m *SimplePriceMatching) getOrder(orderID uint64) (types.Order, bool) {
 	return types.Order{}, false
 }
 
+func (m *SimplePriceMatching) openOrders() []types.Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append(append([]types.Order{}, m.bidOrders...), m.askOrders...)
+}
+
+func (m *SimplePriceMatching) ticker() types.Ticker {
+	kline := m.LastKLine
+	return types.Ticker{
+		Time:   kline.EndTime.Time(),
+		Volume: kline.Volume,
+		Last:   kline.Close,
+		Open:   kline.Open,
+		High:   kline.High,
+		Low:    kline.Low,
+		Buy:    kline.Close,
+		Sell:   kline.Close,
+	}
+}
+
 func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 	m.CurrentTime = kline.EndTime.Time()
+	m.currentKLine = kline
+	m.klineFilled = make(map[uint64]fixedpoint.Value)
 
 	if m.LastPrice.IsZero() {
 		m.LastPrice = kline.Open
@@ -651,10 +817,15 @@ func calculateNativeOrderFee(order *types.Order, market types.Market, feeRate fi
 }
 
 func isLimitTakerOrder(o types.SubmitOrder, currentPrice fixedpoint.Value) bool {
+	return o.Type == types.OrderTypeLimit && isCrossingPrice(o.Side, o.Price, currentPrice)
+}
+
+// isCrossingPrice checks if the order price crosses the current price, which means the order would be matched immediately
+func isCrossingPrice(side types.SideType, price, currentPrice fixedpoint.Value) bool {
 	if currentPrice.IsZero() {
 		return false
 	}
 
-	return o.Type == types.OrderTypeLimit && ((o.Side == types.SideTypeBuy && o.Price.Compare(currentPrice) >= 0) ||
-		(o.Side == types.SideTypeSell && o.Price.Compare(currentPrice) <= 0))
+	return (side == types.SideTypeBuy && price.Compare(currentPrice) >= 0) ||
+		(side == types.SideTypeSell && price.Compare(currentPrice) <= 0)
 }
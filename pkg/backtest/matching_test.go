@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -521,3 +522,263 @@ func TestSimplePriceMatching_LimitTakerOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestSimplePriceMatching_FillModelTradeThroughTicks(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	market.TickSize = fixedpoint.NewFromFloat(0.01)
+
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		CurrentTime:  t1,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+		FillModel:    bbgo.BacktestFillModel{TradeThroughTicks: 2},
+	}
+
+	_, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19000.0, 0.1))
+	assert.NoError(t, err)
+
+	// touching the price or trading through by 1 tick does not fill the order
+	closedOrders, trades := engine.sellToPrice(fixedpoint.NewFromFloat(18999.99))
+	assert.Len(t, closedOrders, 0)
+	assert.Len(t, trades, 0)
+
+	closedOrders, trades = engine.sellToPrice(fixedpoint.NewFromFloat(18999.98))
+	assert.Len(t, closedOrders, 1)
+	assert.Len(t, trades, 1)
+}
+
+func TestSimplePriceMatching_FillModelVolumeRatio(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		CurrentTime:  t1,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+		FillModel:    bbgo.BacktestFillModel{VolumeRatio: fixedpoint.NewFromFloat(0.1)},
+	}
+
+	var orderUpdates []types.Order
+	engine.OnOrderUpdate(func(order types.Order) {
+		orderUpdates = append(orderUpdates, order)
+	})
+
+	var trades []types.Trade
+	engine.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	createdOrder, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19500.0, 1.0))
+	assert.NoError(t, err)
+
+	// half of the price range is below the order price: 10.0 * 0.5 * 0.1 = 0.5
+	k1 := newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 19000, 19500)
+	k1.Volume = fixedpoint.NewFromFloat(10.0)
+	engine.processKLine(k1)
+
+	assert.Len(t, engine.bidOrders, 1)
+	assert.Len(t, trades, 1)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, orderUpdates[len(orderUpdates)-1].Status)
+	assert.Equal(t, "0.5", orderUpdates[len(orderUpdates)-1].ExecutedQuantity.String())
+
+	k2 := newKLine("BTCUSDT", types.Interval1m, t1.Add(time.Minute), 19600, 20000, 19000, 19500)
+	k2.Volume = fixedpoint.NewFromFloat(20.0)
+	engine.processKLine(k2)
+
+	assert.Len(t, engine.bidOrders, 0)
+	assert.Len(t, trades, 2)
+	assert.Equal(t, "0.5", trades[1].Quantity.String())
+	assert.Equal(t, types.OrderStatusFilled, orderUpdates[len(orderUpdates)-1].Status)
+
+	closedOrder, ok := engine.getOrder(createdOrder.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, "1", closedOrder.ExecutedQuantity.String())
+
+	usdt, ok := account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "0", usdt.Locked.String())
+}
+
+func TestSimplePriceMatching_CancelPartiallyFilledOrder(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		CurrentTime:  t1,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+		FillModel:    bbgo.BacktestFillModel{VolumeRatio: fixedpoint.NewFromFloat(0.1)},
+	}
+
+	createdOrder, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeSell, 20500.0, 1.0))
+	assert.NoError(t, err)
+
+	k := newKLine("BTCUSDT", types.Interval1m, t1, 20000, 21000, 20000, 20500)
+	k.Volume = fixedpoint.NewFromFloat(4.0)
+	engine.processKLine(k)
+
+	// cancel with the stale order object, only the remaining quantity should be unlocked
+	canceledOrder, err := engine.CancelOrder(*createdOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.2", canceledOrder.ExecutedQuantity.String())
+
+	btc, ok := account.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "0", btc.Locked.String())
+	assert.Equal(t, "99.8", btc.Available.String())
+}
+
+func TestSimplePriceMatching_LimitTakerOrderBalance(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+	}
+
+	var orderUpdates []types.Order
+	engine.OnOrderUpdate(func(order types.Order) {
+		orderUpdates = append(orderUpdates, order)
+	})
+
+	createdOrder, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 21000.0, 1.0))
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusFilled, createdOrder.Status)
+	assert.Equal(t, "21000", createdOrder.Price.String())
+	assert.Equal(t, "20000", trade.QuoteQuantity.String())
+
+	if assert.Len(t, orderUpdates, 2) {
+		assert.Equal(t, types.OrderStatusNew, orderUpdates[0].Status)
+		assert.Equal(t, types.OrderStatusFilled, orderUpdates[1].Status)
+	}
+
+	// the price difference locked by the limit price should be released
+	usdt, ok := account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "0", usdt.Locked.String())
+	assert.Equal(t, "980000", usdt.Available.String())
+
+	closedOrder, ok := engine.getOrder(createdOrder.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderStatusFilled, closedOrder.Status)
+}
+
+func TestSimplePriceMatching_LimitTakerOrderPartiallyFilled(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+		FillModel:    bbgo.BacktestFillModel{VolumeRatio: fixedpoint.NewFromFloat(0.1)},
+	}
+	engine.LastKLine = newKLine("BTCUSDT", types.Interval1m, time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), 20000, 20100, 19900, 20000)
+	engine.LastKLine.Volume = fixedpoint.NewFromFloat(3.0)
+
+	createdOrder, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 20050.0, 1.0))
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, createdOrder.Status)
+	assert.Equal(t, "0.3", createdOrder.ExecutedQuantity.String())
+	if assert.NotNil(t, trade) {
+		assert.False(t, trade.IsMaker)
+		assert.Equal(t, "20000", trade.Price.String())
+		assert.Equal(t, "0.3", trade.Quantity.String())
+	}
+
+	// the remaining quantity is placed as a maker order
+	assert.Len(t, engine.bidOrders, 1)
+
+	usdt, ok := account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "14035", usdt.Locked.String())
+
+	_, err = engine.CancelOrder(*createdOrder)
+	assert.NoError(t, err)
+
+	usdt, ok = account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "0", usdt.Locked.String())
+}
+
+func TestSimplePriceMatching_LimitMakerOrderRejected(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+	}
+
+	o := newLimitOrder("BTCUSDT", types.SideTypeBuy, 20001.0, 1.0)
+	o.Type = types.OrderTypeLimitMaker
+	_, _, err := engine.PlaceOrder(o)
+	assert.Error(t, err)
+	assert.Len(t, engine.bidOrders, 0)
+
+	usdt, ok := account.Balance("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "0", usdt.Locked.String())
+}
+
+func TestSimplePriceMatching_LimitMakerOrderAtLastPrice(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+	}
+
+	o := newLimitOrder("BTCUSDT", types.SideTypeBuy, 20000.0, 1.0)
+	o.Type = types.OrderTypeLimitMaker
+	createdOrder, trade, err := engine.PlaceOrder(o)
+	assert.NoError(t, err)
+	assert.Nil(t, trade)
+	assert.Equal(t, types.OrderStatusNew, createdOrder.Status)
+	assert.Len(t, engine.bidOrders, 1)
+}
+
+func TestSimplePriceMatching_LimitTakerOrderWithoutFillVolume(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	engine := &SimplePriceMatching{
+		Account:      account,
+		Market:       market,
+		closedOrders: make(map[uint64]types.Order),
+		LastPrice:    fixedpoint.NewFromFloat(20000.0),
+		FillModel:    bbgo.BacktestFillModel{VolumeRatio: fixedpoint.NewFromFloat(0.1)},
+	}
+	engine.LastKLine = newKLine("BTCUSDT", types.Interval1m, time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), 20000, 20100, 19900, 20000)
+	engine.LastKLine.Volume = fixedpoint.NewFromFloat(3.0)
+	engine.Market.StepSize = fixedpoint.NewFromFloat(1.0)
+
+	var orderUpdates []types.Order
+	engine.OnOrderUpdate(func(order types.Order) {
+		orderUpdates = append(orderUpdates, order)
+	})
+
+	// the fill quantity 0.3 is truncated to zero by the step size, the whole order rests in the book
+	createdOrder, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 20050.0, 1.0))
+	assert.NoError(t, err)
+	assert.Nil(t, trade)
+	assert.Equal(t, types.OrderStatusNew, createdOrder.Status)
+	assert.Len(t, engine.bidOrders, 1)
+	if assert.Len(t, orderUpdates, 1) {
+		assert.Equal(t, types.OrderStatusNew, orderUpdates[0].Status)
+	}
+}
@@ -0,0 +1,176 @@
+package backtest
+
+import (
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/statistics"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// EquitySample is the total equity value of the back-test sessions at the given time
+type EquitySample struct {
+	Time   time.Time        `json:"time"`
+	Equity fixedpoint.Value `json:"equity"`
+}
+
+// CalculateMaxDrawdown returns the max drawdown ratio of the equity samples, 0.2 means the equity dropped 20% from its peak
+func CalculateMaxDrawdown(samples []EquitySample) fixedpoint.Value {
+	var peak, maxDrawdown fixedpoint.Value
+	for _, sample := range samples {
+		if sample.Equity.Compare(peak) > 0 {
+			peak = sample.Equity
+		}
+
+		if peak.Sign() <= 0 {
+			continue
+		}
+
+		drawdown := peak.Sub(sample.Equity).Div(peak)
+		if drawdown.Compare(maxDrawdown) > 0 {
+			maxDrawdown = drawdown
+		}
+	}
+
+	return maxDrawdown
+}
+
+// CalculateMaxDrawdownDuration returns the longest duration the equity stayed below its previous peak,
+// the drawdown that is not recovered lasts until the last equity sample
+func CalculateMaxDrawdownDuration(samples []EquitySample) time.Duration {
+	var peak fixedpoint.Value
+	var peakTime time.Time
+	var maxDuration time.Duration
+	for _, sample := range samples {
+		if sample.Equity.Compare(peak) >= 0 {
+			peak = sample.Equity
+			peakTime = sample.Time
+			continue
+		}
+
+		if duration := sample.Time.Sub(peakTime); duration > maxDuration {
+			maxDuration = duration
+		}
+	}
+
+	return maxDuration
+}
+
+// CalculateDailyReturns returns the daily returns calculated from the last equity sample of each day,
+// the return of the first day is calculated from the first equity sample
+func CalculateDailyReturns(samples []EquitySample) (returns types.Float64Slice) {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var closes []fixedpoint.Value
+	var lastDay time.Time
+	for i, sample := range samples {
+		day := sample.Time.UTC().Truncate(24 * time.Hour)
+		if i > 0 && day.Equal(lastDay) {
+			closes[len(closes)-1] = sample.Equity
+		} else {
+			closes = append(closes, sample.Equity)
+		}
+		lastDay = day
+	}
+
+	prev := samples[0].Equity
+	for _, c := range closes {
+		if prev.Sign() > 0 {
+			returns.Push(c.Div(prev).Float64() - 1.0)
+		}
+		prev = c
+	}
+
+	return returns
+}
+
+// SetEquityMetrics calculates the max drawdown and its duration, the annualized sharpe ratio, sortino ratio and calmar ratio from the equity samples
+func (r *SummaryReport) SetEquityMetrics(samples []EquitySample) {
+	if len(samples) < 2 {
+		return
+	}
+
+	r.MaxDrawdown = CalculateMaxDrawdown(samples)
+	r.MaxDrawdownDuration = CalculateMaxDrawdownDuration(samples)
+	r.SharpeRatio, r.SortinoRatio, r.CalmarRatio = calculateReturnRatios(samples, r.MaxDrawdown)
+}
+
+// calculateReturnRatios returns the sharpe ratio and the sortino ratio annualized from the daily returns,
+// and the calmar ratio, which is the annualized return divided by the max drawdown
+func calculateReturnRatios(samples []EquitySample, maxDrawdown fixedpoint.Value) (sharpe, sortino, calmar fixedpoint.Value) {
+	returns := CalculateDailyReturns(samples)
+	if returns.Length() > 1 {
+		sharpe = newFromFloat(statistics.Sharpe(&returns, 365, true, false))
+		sortino = newFromFloat(statistics.Sortino(&returns, 365, true, false))
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	days := last.Time.Sub(first.Time).Hours() / 24.0
+	if first.Equity.Sign() > 0 && days > 0 && maxDrawdown.Sign() > 0 {
+		annualReturn := math.Pow(last.Equity.Div(first.Equity).Float64(), 365.0/days) - 1.0
+		calmar = newFromFloat(annualReturn / maxDrawdown.Float64())
+	}
+
+	return sharpe, sortino, calmar
+}
+
+// SetTradeMetrics calculates the win rate and the profit factor of the symbol report from its pnl report
+func (r *SessionSymbolReport) SetTradeMetrics() {
+	if r.PnL == nil {
+		return
+	}
+
+	r.NumOfTrades = r.PnL.NumTrades
+	r.WinRate = winRate(r.PnL.NumOfProfitTrades, r.PnL.NumOfLossTrades)
+	r.ProfitFactor = profitFactor(r.PnL.GrossProfit, r.PnL.GrossLoss)
+}
+
+// SetTradeMetrics aggregates the trade metrics of the symbol reports
+func (r *SummaryReport) SetTradeMetrics() {
+	var numOfProfitTrades, numOfLossTrades int
+	var grossProfit, grossLoss fixedpoint.Value
+	r.NumOfTrades = 0
+	for _, symbolReport := range r.SymbolReports {
+		if symbolReport.PnL == nil {
+			continue
+		}
+
+		r.NumOfTrades += symbolReport.PnL.NumTrades
+		numOfProfitTrades += symbolReport.PnL.NumOfProfitTrades
+		numOfLossTrades += symbolReport.PnL.NumOfLossTrades
+		grossProfit = grossProfit.Add(symbolReport.PnL.GrossProfit)
+		grossLoss = grossLoss.Add(symbolReport.PnL.GrossLoss)
+	}
+
+	r.WinRate = winRate(numOfProfitTrades, numOfLossTrades)
+	r.ProfitFactor = profitFactor(grossProfit, grossLoss)
+}
+
+func winRate(numOfProfitTrades, numOfLossTrades int) fixedpoint.Value {
+	if numOfProfitTrades+numOfLossTrades == 0 {
+		return fixedpoint.Zero
+	}
+
+	return fixedpoint.NewFromInt(int64(numOfProfitTrades)).Div(fixedpoint.NewFromInt(int64(numOfProfitTrades + numOfLossTrades)))
+}
+
+// profitFactor returns gross profit / gross loss, the gross loss is negative
+func profitFactor(grossProfit, grossLoss fixedpoint.Value) fixedpoint.Value {
+	if grossLoss.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	return grossProfit.Div(grossLoss.Abs())
+}
+
+// newFromFloat converts the float value and replaces NaN and Inf by zero, they can not be encoded into JSON
+func newFromFloat(f float64) fixedpoint.Value {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fixedpoint.Zero
+	}
+
+	return fixedpoint.NewFromFloat(f)
+}
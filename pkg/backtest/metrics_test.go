@@ -0,0 +1,94 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func newEquitySamples(startTime time.Time, interval time.Duration, equities ...float64) (samples []EquitySample) {
+	for i, equity := range equities {
+		samples = append(samples, EquitySample{
+			Time:   startTime.Add(time.Duration(i) * interval),
+			Equity: fixedpoint.NewFromFloat(equity),
+		})
+	}
+	return samples
+}
+
+func TestCalculateMaxDrawdown(t *testing.T) {
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	samples := newEquitySamples(t1, time.Hour, 1000, 1200, 900, 1100, 1300, 1040)
+	assert.Equal(t, "0.25", CalculateMaxDrawdown(samples).String())
+}
+
+func TestCalculateMaxDrawdownDuration(t *testing.T) {
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// 1200 is recovered after 3 hours, 1300 is not recovered until the end
+	samples := newEquitySamples(t1, time.Hour, 1000, 1200, 900, 1100, 1300, 1040, 1100, 1200, 1250)
+	assert.Equal(t, 4*time.Hour, CalculateMaxDrawdownDuration(samples))
+}
+
+func TestCalculateDailyReturns(t *testing.T) {
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	samples := newEquitySamples(t1, 12*time.Hour, 1000, 1050, 1100, 1000, 1210)
+
+	// the last equity of each day: 1050, 1000, 1210
+	returns := CalculateDailyReturns(samples)
+	if assert.Len(t, returns, 3) {
+		assert.InDelta(t, 0.05, returns[0], 1e-6)
+		assert.InDelta(t, 1000.0/1050.0-1.0, returns[1], 1e-6)
+		assert.InDelta(t, 0.21, returns[2], 1e-6)
+	}
+}
+
+func TestSummaryReport_SetEquityMetrics(t *testing.T) {
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	samples := newEquitySamples(t1, 24*time.Hour, 1000, 1010, 990, 1020, 1030, 1000, 1050)
+
+	report := &SummaryReport{}
+	report.SetEquityMetrics(samples)
+	assert.InDelta(t, (1030.0-1000.0)/1030.0, report.MaxDrawdown.Float64(), 1e-8)
+	assert.True(t, report.SharpeRatio.Sign() > 0)
+	assert.True(t, report.SortinoRatio.Compare(report.SharpeRatio) > 0)
+	assert.True(t, report.CalmarRatio.Sign() > 0)
+}
+
+func TestSummaryReport_SetTradeMetrics(t *testing.T) {
+	report := &SummaryReport{
+		SymbolReports: []SessionSymbolReport{
+			{PnL: &pnl.AverageCostPnlReport{
+				NumTrades:         10,
+				NumOfProfitTrades: 3,
+				NumOfLossTrades:   1,
+				GrossProfit:       fixedpoint.NewFromInt(300),
+				GrossLoss:         fixedpoint.NewFromInt(-100),
+			}},
+			{PnL: &pnl.AverageCostPnlReport{
+				NumTrades:         6,
+				NumOfProfitTrades: 1,
+				NumOfLossTrades:   3,
+				GrossProfit:       fixedpoint.NewFromInt(100),
+				GrossLoss:         fixedpoint.NewFromInt(-100),
+			}},
+		},
+	}
+
+	for i := range report.SymbolReports {
+		report.SymbolReports[i].SetTradeMetrics()
+	}
+	report.SetTradeMetrics()
+
+	assert.Equal(t, 10, report.SymbolReports[0].NumOfTrades)
+	assert.Equal(t, "0.75", report.SymbolReports[0].WinRate.String())
+	assert.Equal(t, "3", report.SymbolReports[0].ProfitFactor.String())
+
+	assert.Equal(t, 16, report.NumOfTrades)
+	assert.Equal(t, "0.5", report.WinRate.String())
+	assert.Equal(t, "2", report.ProfitFactor.String())
+}
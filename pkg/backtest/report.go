@@ -49,6 +49,23 @@ type SummaryReport struct {
 	TotalGrossProfit fixedpoint.Value `json:"totalGrossProfit,omitempty"`
 	TotalGrossLoss   fixedpoint.Value `json:"totalGrossLoss,omitempty"`
 
+	// MaxDrawdown is the max drawdown ratio of the total equity value
+	MaxDrawdown fixedpoint.Value `json:"maxDrawdown"`
+
+	// MaxDrawdownDuration is the longest duration the total equity value stayed below its previous peak
+	MaxDrawdownDuration time.Duration `json:"maxDrawdownDuration"`
+
+	// SharpeRatio and SortinoRatio are annualized from the daily returns of the total equity value
+	SharpeRatio  fixedpoint.Value `json:"sharpeRatio"`
+	SortinoRatio fixedpoint.Value `json:"sortinoRatio"`
+
+	// CalmarRatio is the annualized return divided by the max drawdown
+	CalmarRatio fixedpoint.Value `json:"calmarRatio"`
+
+	ProfitFactor fixedpoint.Value `json:"profitFactor"`
+	WinRate      fixedpoint.Value `json:"winRate"`
+	NumOfTrades  int              `json:"numOfTrades"`
+
 	SymbolReports []SessionSymbolReport `json:"symbolReports,omitempty"`
 
 	Manifests Manifests `json:"manifests,omitempty"`
@@ -79,6 +96,25 @@ type SessionSymbolReport struct {
 	InitialBalances types.BalanceMap          `json:"initialBalances,omitempty"`
 	FinalBalances   types.BalanceMap          `json:"finalBalances,omitempty"`
 	Manifests       Manifests                 `json:"manifests,omitempty"`
+
+	NumOfTrades  int              `json:"numOfTrades"`
+	WinRate      fixedpoint.Value `json:"winRate"`
+	ProfitFactor fixedpoint.Value `json:"profitFactor"`
+
+	// EquityCurve is the tsv file of the equity time series in the quote currency, relative to the report directory
+	EquityCurve         string           `json:"equityCurve,omitempty"`
+	MaxDrawdown         fixedpoint.Value `json:"maxDrawdown"`
+	MaxDrawdownDuration time.Duration    `json:"maxDrawdownDuration"`
+
+	// SharpeRatio and SortinoRatio are annualized from the daily returns of the equity value
+	SharpeRatio  fixedpoint.Value `json:"sharpeRatio"`
+	SortinoRatio fixedpoint.Value `json:"sortinoRatio"`
+
+	// CalmarRatio is the annualized return divided by the max drawdown
+	CalmarRatio fixedpoint.Value `json:"calmarRatio"`
+
+	// RollingSharpeRatio is the sharpe ratio of the daily returns in the last rolling window
+	RollingSharpeRatio fixedpoint.Value `json:"rollingSharpeRatio"`
 }
 
 func (r *SessionSymbolReport) InitialEquityValue() fixedpoint.Value {
@@ -117,6 +153,12 @@ func (r *SessionSymbolReport) Print(wantBaseAssetBaseline bool) {
 		color.Red("ASSET DECREASED: %v %s (%s)", finalQuoteAsset.Sub(initQuoteAsset), r.Market.QuoteCurrency, finalQuoteAsset.Sub(initQuoteAsset).Div(initQuoteAsset).FormatPercentage(2))
 	}
 
+	color.Green("MAX DRAWDOWN: %s (%s)", r.MaxDrawdown.FormatPercentage(2), r.MaxDrawdownDuration)
+	color.Green("SHARPE RATIO: %s", r.SharpeRatio.FormatString(4))
+	color.Green("SORTINO RATIO: %s", r.SortinoRatio.FormatString(4))
+	color.Green("CALMAR RATIO: %s", r.CalmarRatio.FormatString(4))
+	color.Green("ROLLING SHARPE RATIO: %s", r.RollingSharpeRatio.FormatString(4))
+
 	if wantBaseAssetBaseline {
 		if r.LastPrice.Compare(r.StartPrice) > 0 {
 			color.Green("%s BASE ASSET PERFORMANCE: +%s (= (%s - %s) / %s)",
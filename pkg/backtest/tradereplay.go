@@ -0,0 +1,205 @@
+package backtest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// klineSynthesizer builds the klines of the given intervals from the market trades,
+// the intervals without any trade are filled with the flat klines of the last close price.
+type klineSynthesizer struct {
+	exchange  types.ExchangeName
+	intervals []types.Interval
+
+	// klines is the current kline indexed by symbol and interval
+	klines map[string]map[types.Interval]*types.KLine
+}
+
+func newKLineSynthesizer(exchange types.ExchangeName, intervals []types.Interval) *klineSynthesizer {
+	return &klineSynthesizer{
+		exchange:  exchange,
+		intervals: intervals,
+		klines:    make(map[string]map[types.Interval]*types.KLine),
+	}
+}
+
+// closeUntil closes the klines that end before the given time, the closed klines are sorted by the end time
+func (s *klineSynthesizer) closeUntil(t time.Time) (closed []types.KLine) {
+	for _, klines := range s.klines {
+		for interval, k := range klines {
+			d := interval.Duration()
+			for k != nil && !t.Before(k.StartTime.Time().Add(d)) {
+				closed = append(closed, *k)
+
+				nextStartTime := k.StartTime.Time().Add(d)
+				if !t.Before(nextStartTime.Add(d)) {
+					k = s.newKLine(k.Symbol, interval, nextStartTime, k.Close)
+				} else {
+					k = nil
+				}
+			}
+
+			klines[interval] = k
+		}
+	}
+
+	sortKLines(closed)
+	return closed
+}
+
+// flush closes all the klines
+func (s *klineSynthesizer) flush() (closed []types.KLine) {
+	for _, klines := range s.klines {
+		for interval, k := range klines {
+			if k != nil {
+				closed = append(closed, *k)
+			}
+			klines[interval] = nil
+		}
+	}
+
+	sortKLines(closed)
+	return closed
+}
+
+func (s *klineSynthesizer) update(trade types.Trade) {
+	klines, ok := s.klines[trade.Symbol]
+	if !ok {
+		klines = make(map[types.Interval]*types.KLine)
+		s.klines[trade.Symbol] = klines
+	}
+
+	for _, interval := range s.intervals {
+		k := klines[interval]
+		if k == nil {
+			startTime := trade.Time.Time().Truncate(interval.Duration())
+			k = s.newKLine(trade.Symbol, interval, startTime, trade.Price)
+			klines[interval] = k
+		}
+
+		k.High = fixedpoint.Max(k.High, trade.Price)
+		k.Low = fixedpoint.Min(k.Low, trade.Price)
+		k.Close = trade.Price
+		k.Volume = k.Volume.Add(trade.Quantity)
+		k.QuoteVolume = k.QuoteVolume.Add(trade.QuoteQuantity)
+		if trade.Side == types.SideTypeBuy {
+			k.TakerBuyBaseAssetVolume = k.TakerBuyBaseAssetVolume.Add(trade.Quantity)
+			k.TakerBuyQuoteAssetVolume = k.TakerBuyQuoteAssetVolume.Add(trade.QuoteQuantity)
+		}
+		k.LastTradeID = trade.ID
+		k.NumberOfTrades++
+	}
+}
+
+func (s *klineSynthesizer) newKLine(symbol string, interval types.Interval, startTime time.Time, price fixedpoint.Value) *types.KLine {
+	return &types.KLine{
+		Exchange:  s.exchange,
+		Symbol:    symbol,
+		StartTime: types.Time(startTime),
+		EndTime:   types.Time(startTime.Add(interval.Duration() - time.Millisecond)),
+		Interval:  interval,
+		Open:      price,
+		Close:     price,
+		High:      price,
+		Low:       price,
+		Closed:    true,
+	}
+}
+
+// sortKLines sorts the klines by the end time, the smaller interval goes first when the end times are the same
+func sortKLines(klines []types.KLine) {
+	sort.Slice(klines, func(i, j int) bool {
+		a, b := klines[i], klines[j]
+		if !a.EndTime.Time().Equal(b.EndTime.Time()) {
+			return a.EndTime.Time().Before(b.EndTime.Time())
+		}
+
+		if a.Interval != b.Interval {
+			return a.Interval.Duration() < b.Interval.Duration()
+		}
+
+		return a.Symbol < b.Symbol
+	})
+}
+
+// newKLineFromTrade creates the kline of a single trade, which is used for matching the orders by the trade
+func newKLineFromTrade(trade types.Trade) types.KLine {
+	return types.KLine{
+		Exchange:    trade.Exchange,
+		Symbol:      trade.Symbol,
+		StartTime:   trade.Time,
+		EndTime:     trade.Time,
+		Open:        trade.Price,
+		Close:       trade.Price,
+		High:        trade.Price,
+		Low:         trade.Price,
+		Volume:      trade.Quantity,
+		QuoteVolume: trade.QuoteQuantity,
+		Closed:      true,
+	}
+}
+
+// subscribeMarketTrades replays the market trades from the database,
+// the trades are queued for the matching and the synthesized klines are sent to the returned channel.
+// A kline is sent after all the trades within its time range are queued,
+// so that ConsumeKLine can process the trades before the kline.
+func (e *Exchange) subscribeMarketTrades(startTime, endTime time.Time, symbols []string, intervals []types.Interval) chan types.KLine {
+	tradeC, errC := e.srv.QueryMarketTradesCh(startTime, endTime, e.sourceName, symbols)
+	klineC := make(chan types.KLine, 100)
+	synthesizer := newKLineSynthesizer(e.sourceName, intervals)
+
+	go func() {
+		defer close(klineC)
+
+		for trade := range tradeC {
+			for _, k := range synthesizer.closeUntil(trade.Time.Time()) {
+				klineC <- k
+			}
+
+			synthesizer.update(trade)
+
+			e.marketTradesMutex.Lock()
+			e.marketTrades = append(e.marketTrades, trade)
+			e.marketTradesMutex.Unlock()
+		}
+
+		for _, k := range synthesizer.flush() {
+			klineC <- k
+		}
+
+		if err := <-errC; err != nil {
+			log.WithError(err).Error("backtest market trade feed error")
+		}
+	}()
+
+	return klineC
+}
+
+// consumeMarketTrades matches the orders by the queued market trades until the given time,
+// and then pushes the trades to the market data stream.
+func (e *Exchange) consumeMarketTrades(until time.Time) {
+	for {
+		e.marketTradesMutex.Lock()
+		if len(e.marketTrades) == 0 || e.marketTrades[0].Time.Time().After(until) {
+			e.marketTradesMutex.Unlock()
+			return
+		}
+
+		trade := e.marketTrades[0]
+		e.marketTrades = e.marketTrades[1:]
+		e.marketTradesMutex.Unlock()
+
+		e.currentTime = trade.Time.Time()
+
+		if matching, ok := e.matchingBook(trade.Symbol); ok {
+			matching.processKLine(newKLineFromTrade(trade))
+		} else {
+			log.Errorf("matching book of %s is not initialized", trade.Symbol)
+		}
+
+		e.MarketDataStream.EmitMarketTrade(trade)
+	}
+}
@@ -0,0 +1,106 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newMarketTrade(t time.Time, side types.SideType, price, quantity float64) types.Trade {
+	return types.Trade{
+		Exchange:      types.ExchangeBinance,
+		Symbol:        "BTCUSDT",
+		Side:          side,
+		Price:         fixedpoint.NewFromFloat(price),
+		Quantity:      fixedpoint.NewFromFloat(quantity),
+		QuoteQuantity: fixedpoint.NewFromFloat(price * quantity),
+		Time:          types.Time(t),
+	}
+}
+
+func TestKLineSynthesizer(t *testing.T) {
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	synthesizer := newKLineSynthesizer(types.ExchangeBinance, []types.Interval{types.Interval1m, types.Interval5m})
+
+	synthesizer.update(newMarketTrade(t1.Add(10*time.Second), types.SideTypeBuy, 20000, 0.1))
+	synthesizer.update(newMarketTrade(t1.Add(30*time.Second), types.SideTypeSell, 19900, 0.2))
+	synthesizer.update(newMarketTrade(t1.Add(50*time.Second), types.SideTypeBuy, 20100, 0.3))
+
+	closed := synthesizer.closeUntil(t1.Add(59 * time.Second))
+	assert.Len(t, closed, 0)
+
+	// the 1m kline between the trades is filled with the last close price
+	closed = synthesizer.closeUntil(t1.Add(150 * time.Second))
+	if assert.Len(t, closed, 2) {
+		k := closed[0]
+		assert.Equal(t, types.Interval1m, k.Interval)
+		assert.Equal(t, t1, k.StartTime.Time())
+		assert.Equal(t, "20000", k.Open.String())
+		assert.Equal(t, "20100", k.High.String())
+		assert.Equal(t, "19900", k.Low.String())
+		assert.Equal(t, "20100", k.Close.String())
+		assert.Equal(t, "0.6", k.Volume.String())
+		assert.Equal(t, "0.4", k.TakerBuyBaseAssetVolume.String())
+		assert.Equal(t, uint64(3), k.NumberOfTrades)
+
+		k = closed[1]
+		assert.Equal(t, t1.Add(time.Minute), k.StartTime.Time())
+		assert.Equal(t, "20100", k.Open.String())
+		assert.Equal(t, "20100", k.Close.String())
+		assert.Equal(t, "0", k.Volume.String())
+	}
+
+	synthesizer.update(newMarketTrade(t1.Add(150*time.Second), types.SideTypeBuy, 20200, 0.1))
+
+	closed = synthesizer.flush()
+	if assert.Len(t, closed, 2) {
+		assert.Equal(t, types.Interval1m, closed[0].Interval)
+		assert.Equal(t, t1.Add(2*time.Minute), closed[0].StartTime.Time())
+		assert.Equal(t, types.Interval5m, closed[1].Interval)
+		assert.Equal(t, "0.7", closed[1].Volume.String())
+		assert.Equal(t, "20200", closed[1].Close.String())
+	}
+}
+
+func TestExchange_ReplayMarketTrades(t *testing.T) {
+	e := newTestExchange(&bbgo.Backtest{ReplayMarketTrades: true}, bbgo.BacktestMargin{})
+
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	e.marketTrades = []types.Trade{
+		newMarketTrade(t1.Add(10*time.Second), types.SideTypeBuy, 20000, 0.1),
+		newMarketTrade(t1.Add(30*time.Second), types.SideTypeSell, 19800, 0.2),
+		newMarketTrade(t1.Add(70*time.Second), types.SideTypeSell, 19700, 0.2),
+	}
+
+	var marketTrades []types.Trade
+	e.MarketDataStream.OnMarketTrade(func(trade types.Trade) {
+		marketTrades = append(marketTrades, trade)
+	})
+
+	var trades []types.Trade
+	e.userDataStream.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	e.consumeMarketTrades(t1.Add(10 * time.Second))
+	assert.Len(t, marketTrades, 1)
+
+	_, err := e.SubmitOrders(nil, newLimitOrder("BTCUSDT", types.SideTypeBuy, 19900, 0.1))
+	assert.NoError(t, err)
+
+	// the order is matched by the trade in the middle of the kline
+	e.ConsumeKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 19800, 19800))
+	assert.Len(t, marketTrades, 2)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "19900", trades[0].Price.String())
+		assert.Equal(t, t1.Add(30*time.Second), trades[0].Time.Time())
+	}
+
+	// the trade of the next kline is still queued
+	assert.Len(t, e.marketTrades, 1)
+}
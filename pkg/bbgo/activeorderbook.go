@@ -174,6 +174,23 @@ func (b *ActiveOrderBook) Add(orders ...types.Order) {
 	}
 }
 
+// Replace removes the replaced order and adds the new order, the order ID is kept if the order is amended in place
+func (b *ActiveOrderBook) Replace(order, newOrder types.Order) {
+	if order.OrderID != newOrder.OrderID {
+		b.Remove(order)
+	}
+
+	if hasSymbol := len(b.Symbol) > 0; hasSymbol && b.Symbol != newOrder.Symbol {
+		return
+	}
+
+	if b.orders.Exists(newOrder.OrderID) {
+		b.orders.Update(newOrder)
+	} else {
+		b.orders.Add(newOrder)
+	}
+}
+
 func (b *ActiveOrderBook) Exists(order types.Order) bool {
 	return b.orders.Exists(order.OrderID)
 }
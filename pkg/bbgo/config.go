@@ -115,6 +115,54 @@ type Backtest struct {
 	Accounts map[string]BacktestAccount `json:"accounts" yaml:"accounts"`
 	Symbols  []string                   `json:"symbols" yaml:"symbols"`
 	Sessions []string                   `json:"sessions" yaml:"sessions"`
+
+	// Matching is the matching engine config by exchange name, e.g. binance, max
+	Matching map[string]BacktestMatching `json:"matching,omitempty" yaml:"matching,omitempty"`
+
+	// ReplayMarketTrades replays the synced market trades instead of the klines,
+	// the orders are matched by each trade and the klines are synthesized from the trades.
+	ReplayMarketTrades bool `json:"replayMarketTrades,omitempty" yaml:"replayMarketTrades,omitempty"`
+}
+
+// GetMatching returns the matching engine config of the given exchange name, the kline engine is used by default
+func (b *Backtest) GetMatching(n string) BacktestMatching {
+	if matchingConfig, ok := b.Matching[n]; ok {
+		return matchingConfig
+	}
+
+	return BacktestMatching{Engine: BacktestMatchingEngineKLine}
+}
+
+type BacktestMatchingEngine string
+
+const (
+	// BacktestMatchingEngineKLine fills the orders by the kline prices
+	BacktestMatchingEngineKLine BacktestMatchingEngine = "kline"
+
+	// BacktestMatchingEngineDepth fills the orders against the recorded depth snapshots and updates
+	BacktestMatchingEngineDepth BacktestMatchingEngine = "depth"
+)
+
+type BacktestMatching struct {
+	Engine BacktestMatchingEngine `json:"engine" yaml:"engine"`
+
+	// DepthDataDir is the directory of the recorded depth files, one file per symbol: {DepthDataDir}/{SYMBOL}.jsonl
+	// This is required by the depth matching engine
+	DepthDataDir string `json:"depthDataDir,omitempty" yaml:"depthDataDir,omitempty"`
+
+	// FillModel is used by the kline matching engine
+	FillModel BacktestFillModel `json:"fillModel,omitempty" yaml:"fillModel,omitempty"`
+}
+
+// BacktestFillModel controls how the kline matching engine fills the resting limit orders
+type BacktestFillModel struct {
+	// TradeThroughTicks is the number of ticks that the price has to trade through the order price before the order is filled,
+	// zero means the order is filled once the price touches it.
+	TradeThroughTicks int `json:"tradeThroughTicks,omitempty" yaml:"tradeThroughTicks,omitempty"`
+
+	// VolumeRatio is the max ratio of the kline volume traded beyond the order price that an order can take in one kline,
+	// the rest of the order stays partially filled. zero means no volume limit.
+	VolumeRatio fixedpoint.Value `json:"volumeRatio,omitempty" yaml:"volumeRatio,omitempty"`
 }
 
 func (b *Backtest) GetAccount(n string) BacktestAccount {
@@ -136,6 +184,69 @@ type BacktestAccount struct {
 	TakerFeeRate fixedpoint.Value `json:"takerFeeRate,omitempty" yaml:"takerFeeRate,omitempty"`
 
 	Balances BacktestAccountBalanceMap `json:"balances" yaml:"balances"`
+
+	// Margin is used when the session is a margin or futures session
+	Margin BacktestMargin `json:"margin,omitempty" yaml:"margin,omitempty"`
+}
+
+var DefaultBacktestLeverage = fixedpoint.NewFromInt(3)
+
+var DefaultBacktestLiquidationMarginLevel = fixedpoint.MustNewFromString("1.1")
+
+const DefaultBacktestValuationCurrency = "USDT"
+
+// BacktestMargin configures the margin and futures simulation of the backtest account
+type BacktestMargin struct {
+	// Leverage is the max leverage, the total debt value can not exceed net asset value * (leverage - 1), default 3
+	Leverage fixedpoint.Value `json:"leverage,omitempty" yaml:"leverage,omitempty"`
+
+	// InterestRates is the hourly interest rate of the borrowed assets, indexed by the currency
+	InterestRates map[string]fixedpoint.Value `json:"interestRates,omitempty" yaml:"interestRates,omitempty"`
+
+	// DefaultInterestRate is the hourly interest rate of the currencies that are not defined in InterestRates
+	DefaultInterestRate fixedpoint.Value `json:"defaultInterestRate,omitempty" yaml:"defaultInterestRate,omitempty"`
+
+	// LiquidationMarginLevel is the margin level (total asset value / total debt value) that triggers the liquidation, default 1.1
+	LiquidationMarginLevel fixedpoint.Value `json:"liquidationMarginLevel,omitempty" yaml:"liquidationMarginLevel,omitempty"`
+
+	// ValuationCurrency is the currency that the asset values are calculated in, default USDT
+	ValuationCurrency string `json:"valuationCurrency,omitempty" yaml:"valuationCurrency,omitempty"`
+
+	// FundingRateDataDir is the directory of the recorded funding rates, one file per symbol: {FundingRateDataDir}/{SYMBOL}.jsonl
+	// The funding fee is only paid by the futures session
+	FundingRateDataDir string `json:"fundingRateDataDir,omitempty" yaml:"fundingRateDataDir,omitempty"`
+}
+
+func (m BacktestMargin) GetLeverage() fixedpoint.Value {
+	if m.Leverage.IsZero() {
+		return DefaultBacktestLeverage
+	}
+
+	return m.Leverage
+}
+
+func (m BacktestMargin) GetInterestRate(currency string) fixedpoint.Value {
+	if rate, ok := m.InterestRates[currency]; ok {
+		return rate
+	}
+
+	return m.DefaultInterestRate
+}
+
+func (m BacktestMargin) GetLiquidationMarginLevel() fixedpoint.Value {
+	if m.LiquidationMarginLevel.IsZero() {
+		return DefaultBacktestLiquidationMarginLevel
+	}
+
+	return m.LiquidationMarginLevel
+}
+
+func (m BacktestMargin) GetValuationCurrency() string {
+	if len(m.ValuationCurrency) == 0 {
+		return DefaultBacktestValuationCurrency
+	}
+
+	return m.ValuationCurrency
 }
 
 var DefaultBacktestAccount = BacktestAccount{
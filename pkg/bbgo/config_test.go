@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -169,6 +170,21 @@ func TestLoadConfig(t *testing.T) {
 				assert.NotNil(t, riskControls)
 				assert.NotNil(t, riskControls.SessionBasedRiskControl)
 
+				if assert.NotNil(t, riskControls.KillSwitch) {
+					assert.Equal(t, fixedpoint.NewFromFloat(1000.0), riskControls.KillSwitch.DailyLossLimit)
+					assert.Equal(t, fixedpoint.NewFromFloat(0.1), riskControls.KillSwitch.MaxDrawdown)
+					assert.Equal(t, 24*time.Hour, riskControls.KillSwitch.DrawdownWindow.Duration())
+					assert.True(t, riskControls.KillSwitch.FlattenPositions)
+				}
+
+				if assert.NotNil(t, riskControls.Exposure) {
+					assert.Equal(t, fixedpoint.NewFromFloat(50000.0), riskControls.Exposure.MaxTotalExposure)
+					assert.True(t, riskControls.Exposure.Downsize)
+					if assert.Contains(t, riskControls.Exposure.Assets, "BTC") {
+						assert.Equal(t, fixedpoint.NewFromFloat(30000.0), riskControls.Exposure.Assets["BTC"].MaxNetValue)
+					}
+				}
+
 				conf, ok := riskControls.SessionBasedRiskControl["max"]
 				assert.True(t, ok)
 				assert.NotNil(t, conf)
@@ -178,6 +194,16 @@ func TestLoadConfig(t *testing.T) {
 				executorConf, ok := conf.OrderExecutor.BySymbol["BTCUSDT"]
 				assert.True(t, ok)
 				assert.NotNil(t, executorConf)
+
+				if assert.NotNil(t, conf.PreTrade) {
+					assert.Equal(t, fixedpoint.NewFromFloat(5000.0), conf.PreTrade.MaxOrderNotional)
+					assert.Equal(t, 60, conf.PreTrade.MaxOrderRate)
+
+					rules := conf.PreTrade.rules("BTCUSDT")
+					assert.Equal(t, fixedpoint.NewFromFloat(0.05), rules.PriceCollar)
+					assert.Equal(t, fixedpoint.NewFromFloat(1.0), rules.MaxPosition)
+					assert.Equal(t, 10, rules.MaxOpenOrders)
+				}
 			},
 		},
 		{
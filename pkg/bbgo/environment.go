@@ -516,6 +516,11 @@ func (environ *Environment) BindSync(config *SyncConfig) {
 	}
 
 	for _, session := range environ.sessions {
+		// the paper trades are not written into the database
+		if session.PaperTrade {
+			continue
+		}
+
 		// avoid using the iterator variable.
 		s2 := session
 		// if trade sync is on, we will write all received trades
@@ -552,6 +557,8 @@ func (environ *Environment) Connect(ctx context.Context) error {
 			}
 		}
 
+		session.monitorStreamHealth(ctx)
+
 		logger.Infof("connecting %s market data stream...", session.Name)
 		if err := session.MarketDataStream.Connect(ctx); err != nil {
 			return err
@@ -595,6 +602,10 @@ func (environ *Environment) syncWithUserConfig(ctx context.Context, userConfig *
 
 	syncSymbolMap, restSymbols := categorizeSyncSymbol(userConfig.Sync.Symbols)
 	for _, session := range sessions {
+		if session.PaperTrade {
+			continue
+		}
+
 		syncSymbols := restSymbols
 		if ss, ok := syncSymbolMap[session.Name]; ok {
 			syncSymbols = append(syncSymbols, ss...)
@@ -658,6 +669,11 @@ func (environ *Environment) Sync(ctx context.Context, userConfig ...*Config) err
 
 	// the default sync logics
 	for _, session := range environ.sessions {
+		// the paper trading session has no trading history on the exchange
+		if session.PaperTrade {
+			continue
+		}
+
 		if err := environ.syncSession(ctx, session); err != nil {
 			return err
 		}
@@ -35,14 +35,14 @@ func TestTrailingStop_ShortPosition(t *testing.T) {
 
 	mockEx := mocks.NewMockExchange(mockCtrl)
 	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
-	mockEx.EXPECT().SubmitOrders(gomock.Any(), types.SubmitOrder{
+	mockEx.EXPECT().SubmitOrders(gomock.Any(), strategySubmitOrder("test-01", types.SubmitOrder{
 		Symbol:   "BTCUSDT",
 		Side:     types.SideTypeBuy,
 		Type:     types.OrderTypeMarket,
 		Market:   market,
 		Quantity: fixedpoint.NewFromFloat(1.0),
 		Tag:      "trailingStop",
-	})
+	}))
 
 	session := NewExchangeSession("test", mockEx)
 	assert.NotNil(t, session)
@@ -112,14 +112,14 @@ func TestTrailingStop_LongPosition(t *testing.T) {
 
 	mockEx := mocks.NewMockExchange(mockCtrl)
 	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
-	mockEx.EXPECT().SubmitOrders(gomock.Any(), types.SubmitOrder{
+	mockEx.EXPECT().SubmitOrders(gomock.Any(), strategySubmitOrder("test-01", types.SubmitOrder{
 		Symbol:   "BTCUSDT",
 		Side:     types.SideTypeSell,
 		Type:     types.OrderTypeMarket,
 		Market:   market,
 		Quantity: fixedpoint.NewFromFloat(1.0),
 		Tag:      "trailingStop",
-	})
+	}))
 
 	session := NewExchangeSession("test", mockEx)
 	assert.NotNil(t, session)
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/interact"
@@ -387,6 +388,107 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		reply.Message(fmt.Sprintf("Strategy %s stopped and the position closed.", signature))
 		return nil
 	})
+
+	i.PrivateCommand("/exposure", "Show Net Exposure", func(reply interact.Reply) error {
+		manager := it.exposureManager()
+		if manager == nil {
+			reply.Message("Exposure manager is not configured")
+			return nil
+		}
+
+		reply.Message(exposureReportMessage(manager.Report()))
+		return nil
+	})
+
+	i.PrivateCommand("/killswitch", "Show Kill Switch State", func(reply interact.Reply) error {
+		killSwitch := it.killSwitch()
+		if killSwitch == nil {
+			reply.Message("Kill switch is not configured")
+			return nil
+		}
+
+		reply.Message(killSwitchStateMessage(killSwitch.State()))
+		return nil
+	})
+
+	i.PrivateCommand("/resetkillswitch", "Reset Kill Switch", func(reply interact.Reply) error {
+		killSwitch := it.killSwitch()
+		if killSwitch == nil {
+			reply.Message("Kill switch is not configured")
+			return nil
+		}
+
+		reply.Message(killSwitchStateMessage(killSwitch.State()) + "\nAre you sure to reset the kill switch?")
+		reply.AddButton("Yes", "confirm", "yes")
+		reply.AddButton("No", "confirm", "no")
+		return nil
+	}).Next(func(confirm string, reply interact.Reply) error {
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		if confirm != "yes" {
+			reply.Message("Kill switch is not reset")
+			return nil
+		}
+
+		if err := it.killSwitch().Reset(); err != nil {
+			reply.Message(fmt.Sprintf("Failed to reset the kill switch, %s", err.Error()))
+			return err
+		}
+
+		reply.Message("Kill switch is reset, use /resume to resume the suspended strategies.")
+		return nil
+	})
+}
+
+func (it *CoreInteraction) exposureManager() *ExposureManager {
+	if it.trader == nil {
+		return nil
+	}
+
+	return it.trader.ExposureManager()
+}
+
+func (it *CoreInteraction) killSwitch() *KillSwitch {
+	if it.trader == nil || it.trader.riskControls == nil {
+		return nil
+	}
+
+	return it.trader.riskControls.KillSwitch
+}
+
+func exposureReportMessage(report ExposureReport) string {
+	if len(report.Assets) == 0 {
+		return "No strategy position"
+	}
+
+	message := fmt.Sprintf("Net exposure: %s %s\n", report.TotalExposure.String(), report.ReferenceCurrency)
+	for _, asset := range report.Assets {
+		value := "no price"
+		if asset.Priced {
+			value = asset.Value.String() + " " + report.ReferenceCurrency
+		}
+
+		message += fmt.Sprintf("- %s: %s (%s)\n", asset.Asset, asset.NetQuantity.String(), value)
+		for _, position := range asset.Positions {
+			message += fmt.Sprintf("  - %s %s %s: %s\n", position.Session, position.StrategyInstanceID, position.Symbol, position.Base.String())
+		}
+	}
+
+	return message
+}
+
+func killSwitchStateMessage(state KillSwitchState) string {
+	message := "Kill switch is not tripped.\n"
+	if state.Tripped {
+		message = fmt.Sprintf("Kill switch was tripped at %s: %s\n", state.TrippedAt.Format(time.RFC3339), state.Reason)
+	}
+
+	message += fmt.Sprintf("- date: %s\n", state.Date)
+	message += fmt.Sprintf("- day start equity: %s USD\n", state.DayStartEquity.String())
+	message += fmt.Sprintf("- daily realized profit: %s USD\n", state.DailyRealizedProfit.String())
+	return message
 }
 
 func (it *CoreInteraction) Initialize() error {
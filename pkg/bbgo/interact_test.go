@@ -31,3 +31,9 @@ func Test_getStrategySignature(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "mystrategy:BTCUSDT", signature)
 }
+
+func TestCoreInteraction_withoutTrader(t *testing.T) {
+	it := &CoreInteraction{}
+	assert.Nil(t, it.exposureManager())
+	assert.Nil(t, it.killSwitch())
+}
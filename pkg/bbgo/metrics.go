@@ -1,6 +1,10 @@
 package bbgo
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
 
 var (
 	metricsConnectionStatus = prometheus.NewGaugeVec(
@@ -97,6 +101,97 @@ var (
 			"currency",  // for balance
 		},
 	)
+
+	metricsStreamLastMessageAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_stream_last_message_age_seconds",
+			Help: "bbgo stream seconds since the last message of the subscription",
+		},
+		[]string{
+			"exchange",  // exchange name
+			"margin",    // margin of connection. none, margin or isolated
+			"channel",   // channel: user, market
+			"data_type", // type: kline, book, bookticker, trade or user
+			"symbol",    // for market data
+			"interval",  // for kline
+		},
+	)
+
+	metricsStreamStaleTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_stream_stale_total",
+			Help: "bbgo stream stale subscriptions that trigger the re-connection",
+		},
+		[]string{
+			"exchange",  // exchange name
+			"margin",    // margin of connection. none, margin or isolated
+			"channel",   // channel: user, market
+			"data_type", // type: kline, book, bookticker, trade or user
+			"symbol",    // for market data
+			"interval",  // for kline
+		},
+	)
+
+	metricsStreamResyncTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_stream_resync_total",
+			Help: "bbgo stream state re-synchronizations after the re-connection",
+		},
+		[]string{
+			"exchange", // exchange name
+			"margin",   // margin of connection. none, margin or isolated
+			"channel",  // channel: user, market
+			"result",   // result: success or error
+		},
+	)
+
+	metricsKillSwitchTripped = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "bbgo_kill_switch_tripped",
+			Help: "bbgo kill switch state, 1 if it's tripped",
+		},
+	)
+
+	metricsKillSwitchEquity = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "bbgo_kill_switch_equity",
+			Help: "bbgo mark-to-market equity in USD of all the sessions watched by the kill switch",
+		},
+	)
+
+	metricsRateLimitUsedWeight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_rate_limit_used_weight",
+			Help: "bbgo exchange api used request weight of the current rate limit window",
+		},
+		[]string{
+			"exchange", // exchange name of the limiter, e.g., binance or binance_futures
+			"rule",     // rate limit rule, e.g., request_weight or orders
+		},
+	)
+
+	metricsRateLimitWeightLimit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_rate_limit_weight_limit",
+			Help: "bbgo exchange api request weight limit of the rate limit window",
+		},
+		[]string{
+			"exchange", // exchange name of the limiter
+			"rule",     // rate limit rule
+		},
+	)
+
+	metricsRateLimitThrottledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_rate_limit_throttled_total",
+			Help: "bbgo exchange api requests that are queued or rejected by the rate limiter",
+		},
+		[]string{
+			"exchange", // exchange name of the limiter
+			"endpoint", // method and path of the request
+			"action",   // action: wait or reject
+		},
+	)
 )
 
 func init() {
@@ -108,5 +203,28 @@ func init() {
 		metricsTradesTotal,
 		metricsTradingVolume,
 		metricsLastUpdateTimeBalance,
+		metricsStreamLastMessageAge,
+		metricsStreamStaleTotal,
+		metricsStreamResyncTotal,
+		metricsKillSwitchTripped,
+		metricsKillSwitchEquity,
+		metricsRateLimitUsedWeight,
+		metricsRateLimitWeightLimit,
+		metricsRateLimitThrottledTotal,
 	)
+
+	ratelimit.OnUsage(func(name, rule string, used, limit int) {
+		labels := prometheus.Labels{"exchange": name, "rule": rule}
+		metricsRateLimitUsedWeight.With(labels).Set(float64(used))
+		metricsRateLimitWeightLimit.With(labels).Set(float64(limit))
+	})
+
+	ratelimit.OnThrottle(func(name, endpoint string, rejected bool) {
+		action := "wait"
+		if rejected {
+			action = "reject"
+		}
+
+		metricsRateLimitThrottledTotal.With(prometheus.Labels{"exchange": name, "endpoint": endpoint, "action": action}).Inc()
+	})
 }
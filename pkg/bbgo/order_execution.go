@@ -6,6 +6,7 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -42,7 +43,14 @@ func (e *ExchangeOrderExecutionRouter) SubmitOrdersTo(ctx context.Context, sessi
 		return nil, err
 	}
 
-	return es.Exchange.SubmitOrders(ctx, formattedOrders...)
+	formattedOrders, riskErr := es.checkPreTradeRisk("", nil, formattedOrders)
+	if len(formattedOrders) == 0 {
+		return nil, riskErr
+	}
+
+	createdOrders, err := es.Exchange.SubmitOrders(ctx, formattedOrders...)
+	es.recordSubmittedOrders(createdOrders...)
+	return createdOrders, multierr.Append(riskErr, err)
 }
 
 func (e *ExchangeOrderExecutionRouter) CancelOrdersTo(ctx context.Context, session string, orders ...types.Order) error {
@@ -91,6 +99,11 @@ func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...type
 		return nil, err
 	}
 
+	formattedOrders, riskErr := e.Session.checkPreTradeRisk("", nil, formattedOrders)
+	if len(formattedOrders) == 0 {
+		return nil, riskErr
+	}
+
 	for _, order := range formattedOrders {
 		// pass submit order as an interface object.
 		channel, ok := e.RouteObject(&order)
@@ -105,7 +118,9 @@ func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...type
 
 	e.notifySubmitOrders(formattedOrders...)
 
-	return e.Session.Exchange.SubmitOrders(ctx, formattedOrders...)
+	createdOrders, err := e.Session.Exchange.SubmitOrders(ctx, formattedOrders...)
+	e.Session.recordSubmittedOrders(createdOrders...)
+	return createdOrders, multierr.Append(riskErr, err)
 }
 
 func (e *ExchangeOrderExecutor) CancelOrders(ctx context.Context, orders ...types.Order) error {
@@ -2,9 +2,13 @@ package bbgo
 
 import (
 	"context"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -12,6 +16,23 @@ import (
 
 type NotifyFunc func(obj interface{}, args ...interface{})
 
+var (
+	// ErrReplacedOrderFilled is returned when the replaced order is fully filled before it's canceled, the new order is not placed
+	ErrReplacedOrderFilled = errors.New("the replaced order is filled")
+
+	// ErrReplacedOrderUnknown is returned when the state of the canceled order can not be verified, the new order is not placed
+	ErrReplacedOrderUnknown = errors.New("the state of the replaced order is unknown")
+
+	// ErrReplacedOrderRemainingTooSmall is returned when the remaining quantity of the replaced order is below the minimal
+	// quantity of the market after the quantity filled during the cancellation is deducted, the new order is not placed
+	ErrReplacedOrderRemainingTooSmall = errors.New("the remaining quantity of the replaced order is too small")
+
+	// ErrReplacedOrderOverfilled is returned with the new order when the replaced order is partially filled during the
+	// cancellation of the cancel-replace endpoint, the new order is placed with the full quantity and the caller decides
+	// how to adjust it
+	ErrReplacedOrderOverfilled = errors.New("the replaced order is partially filled during the cancellation")
+)
+
 // GeneralOrderExecutor implements the general order executor for strategy
 type GeneralOrderExecutor struct {
 	session            *ExchangeSession
@@ -30,7 +51,8 @@ func NewGeneralOrderExecutor(session *ExchangeSession, symbol, strategy, strateg
 	position.StrategyInstanceID = strategyInstanceID
 
 	orderStore := NewOrderStore(symbol)
-	return &GeneralOrderExecutor{
+	orderStore.StrategyInstanceID = strategyInstanceID
+	executor := &GeneralOrderExecutor{
 		session:            session,
 		symbol:             symbol,
 		strategy:           strategy,
@@ -40,6 +62,19 @@ func NewGeneralOrderExecutor(session *ExchangeSession, symbol, strategy, strateg
 		orderStore:         orderStore,
 		tradeCollector:     NewTradeCollector(symbol, position, orderStore),
 	}
+
+	session.addStrategyPosition(strategyInstanceID, position)
+
+	// the realized profits are counted by the kill switch of the session
+	executor.tradeCollector.OnProfit(func(trade types.Trade, profit *types.Profit) {
+		if profit == nil || session.killSwitch == nil {
+			return
+		}
+
+		session.killSwitch.AddProfit(*profit)
+	})
+
+	return executor
 }
 
 func (e *GeneralOrderExecutor) BindEnvironment(environ *Environment) {
@@ -94,21 +129,231 @@ func (e *GeneralOrderExecutor) CancelOrders(ctx context.Context, orders ...types
 	return e.session.Exchange.CancelOrders(ctx, orders...)
 }
 
+// tagOrder sets the client order ID of the strategy instance if the client order ID is not specified
+func (e *GeneralOrderExecutor) tagOrder(order types.SubmitOrder) types.SubmitOrder {
+	if len(order.ClientOrderID) == 0 && len(e.strategyInstanceID) > 0 {
+		order.ClientOrderID = types.NewStrategyClientOrderID(e.strategyInstanceID)
+	}
+
+	return order
+}
+
 func (e *GeneralOrderExecutor) SubmitOrders(ctx context.Context, submitOrders ...types.SubmitOrder) (types.OrderSlice, error) {
 	formattedOrders, err := e.session.FormatOrders(submitOrders)
 	if err != nil {
 		return nil, err
 	}
 
+	for i := range formattedOrders {
+		formattedOrders[i] = e.tagOrder(formattedOrders[i])
+	}
+
+	formattedOrders, riskErr := e.session.checkPreTradeRisk(e.strategyInstanceID, e.position, formattedOrders)
+	if len(formattedOrders) == 0 {
+		return nil, riskErr
+	}
+
 	createdOrders, err := e.session.Exchange.SubmitOrders(ctx, formattedOrders...)
 	if err != nil {
 		log.WithError(err).Errorf("can not place orders")
 	}
 
+	e.session.recordSubmittedOrders(createdOrders...)
 	e.orderStore.Add(createdOrders...)
 	e.activeMakerOrders.Add(createdOrders...)
 	e.tradeCollector.Process()
-	return createdOrders, err
+	return createdOrders, multierr.Append(riskErr, err)
+}
+
+// AmendOrder replaces the open order with the new order, the quantity of the new order is the remaining quantity to execute.
+// The native amend or cancel-replace endpoint of the exchange is used if it's supported, otherwise the order is canceled and
+// the new order is placed after the canceled order is reconciled. The new order is downsized by the quantity that was filled
+// during the cancellation, and it's not placed if the order is already filled or the order state can not be verified.
+// When the cancel-replace endpoint reports fills during the cancellation, the new order is returned with
+// ErrReplacedOrderOverfilled since it's placed with the full quantity.
+func (e *GeneralOrderExecutor) AmendOrder(ctx context.Context, order types.Order, newOrder types.SubmitOrder) (*types.Order, error) {
+	formattedOrder, err := e.session.FormatOrder(e.tagOrder(newOrder))
+	if err != nil {
+		return nil, err
+	}
+
+	acceptedOrders, err := e.session.checkPreTradeRisk(e.strategyInstanceID, e.position, []types.SubmitOrder{formattedOrder}, order)
+	if len(acceptedOrders) == 0 {
+		return nil, err
+	}
+
+	formattedOrder = acceptedOrders[0]
+
+	if service, ok := e.session.Exchange.(types.ExchangeOrderReplaceService); ok {
+		canceledOrder, replacedOrder, err := service.ReplaceOrder(ctx, order, formattedOrder)
+		if err == nil {
+			e.session.recordSubmittedOrders(*replacedOrder)
+			e.orderStore.Replace(order, *replacedOrder)
+			e.activeMakerOrders.Replace(order, *replacedOrder)
+			if canceledOrder != nil {
+				e.orderStore.Update(*canceledOrder)
+			}
+			e.tradeCollector.Process()
+
+			// the new order of the cancel-replace endpoint is placed with the full quantity,
+			// let the caller decide whether to reduce or cancel it
+			if canceledOrder != nil {
+				if filled := canceledOrder.ExecutedQuantity.Sub(order.ExecutedQuantity); filled.Sign() > 0 {
+					return replacedOrder, errors.Wrapf(ErrReplacedOrderOverfilled, "order %d, filled %s, new order %d",
+						order.OrderID, filled.String(), replacedOrder.OrderID)
+				}
+			}
+
+			return replacedOrder, nil
+		}
+
+		if !errors.Is(err, types.ErrOrderReplaceNotSupported) {
+			log.WithError(err).Errorf("can not replace order %d", order.OrderID)
+			return nil, err
+		}
+	}
+
+	return e.cancelAndPlace(ctx, order, formattedOrder)
+}
+
+// cancelAndPlace cancels the order, reconciles the canceled order and places the new order
+func (e *GeneralOrderExecutor) cancelAndPlace(ctx context.Context, order types.Order, newOrder types.SubmitOrder) (*types.Order, error) {
+	if err := e.session.Exchange.CancelOrders(ctx, order); err != nil {
+		log.WithError(err).Errorf("can not cancel order %d for replacing", order.OrderID)
+		return nil, err
+	}
+
+	e.activeMakerOrders.Remove(order)
+
+	if service, ok := e.session.Exchange.(types.ExchangeOrderQueryService); ok {
+		canceledOrder, err := service.QueryOrder(ctx, types.OrderQuery{
+			Symbol:  order.Symbol,
+			OrderID: strconv.FormatUint(order.OrderID, 10),
+		})
+		if err != nil {
+			log.WithError(err).Errorf("can not query the canceled order %d", order.OrderID)
+			return nil, errors.Wrapf(ErrReplacedOrderUnknown, "order %d: %s", order.OrderID, err.Error())
+		}
+
+		e.orderStore.Update(*canceledOrder)
+
+		switch canceledOrder.Status {
+		case types.OrderStatusFilled:
+			e.tradeCollector.Process()
+			return nil, errors.Wrapf(ErrReplacedOrderFilled, "order %d", order.OrderID)
+
+		case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+			return nil, errors.Wrapf(ErrReplacedOrderUnknown, "order %d is still %s", order.OrderID, canceledOrder.Status)
+		}
+
+		// downsize the new order by the quantity filled during the cancellation
+		if filled := canceledOrder.ExecutedQuantity.Sub(order.ExecutedQuantity); filled.Sign() > 0 {
+			newOrder.Quantity = newOrder.Quantity.Sub(filled)
+			e.tradeCollector.Process()
+		}
+	} else {
+		log.Warnf("%s does not support querying orders, placing the new order without reconciling the canceled order %d",
+			e.session.ExchangeName, order.OrderID)
+	}
+
+	if newOrder.Quantity.Sign() <= 0 {
+		return nil, errors.Wrapf(ErrReplacedOrderFilled, "order %d", order.OrderID)
+	}
+
+	if newOrder.Quantity.Compare(newOrder.Market.MinQuantity) < 0 {
+		return nil, errors.Wrapf(ErrReplacedOrderRemainingTooSmall, "order %d, remaining quantity %s, min quantity %s",
+			order.OrderID, newOrder.Quantity.String(), newOrder.Market.MinQuantity.String())
+	}
+
+	createdOrders, err := e.session.Exchange.SubmitOrders(ctx, newOrder)
+	if err != nil {
+		log.WithError(err).Errorf("can not place the new order for replacing order %d", order.OrderID)
+		return nil, err
+	}
+
+	if len(createdOrders) == 0 {
+		return nil, errors.Errorf("the new order for replacing order %d is not created", order.OrderID)
+	}
+
+	e.session.recordSubmittedOrders(createdOrders[0])
+	e.orderStore.Replace(order, createdOrders[0])
+	e.activeMakerOrders.Add(createdOrders[0])
+	e.tradeCollector.Process()
+	return &createdOrders[0], nil
+}
+
+// ReplaceOrders replaces the open orders with the submit orders, the orders are paired by index.
+// The extra open orders are canceled and the extra submit orders are placed.
+func (e *GeneralOrderExecutor) ReplaceOrders(ctx context.Context, orders []types.Order, submitOrders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var replacedOrders types.OrderSlice
+	var errs error
+
+	for i := 0; i < len(orders) && i < len(submitOrders); i++ {
+		replacedOrder, err := e.AmendOrder(ctx, orders[i], submitOrders[i])
+		if err != nil {
+			errs = multierr.Append(errs, err)
+		}
+
+		if replacedOrder == nil {
+			continue
+		}
+
+		replacedOrders = append(replacedOrders, *replacedOrder)
+	}
+
+	if len(orders) > len(submitOrders) {
+		extraOrders := orders[len(submitOrders):]
+		if err := e.CancelOrders(ctx, extraOrders...); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	if len(submitOrders) > len(orders) {
+		createdOrders, err := e.SubmitOrders(ctx, submitOrders[len(orders):]...)
+		replacedOrders = append(replacedOrders, createdOrders...)
+		errs = multierr.Append(errs, err)
+	}
+
+	return replacedOrders, errs
+}
+
+// Recover recovers the ownership of the open orders and the trades of the strategy instance after a restart.
+// The open orders with the client order IDs of the strategy instance are added to the order store and the active maker
+// orders, and the trades executed after the last position change are added to the position.
+func (e *GeneralOrderExecutor) Recover(ctx context.Context) error {
+	openOrders, err := e.session.Exchange.QueryOpenOrders(ctx, e.symbol)
+	if err != nil {
+		return err
+	}
+
+	recoveredOrders := e.orderStore.Recover(openOrders...)
+	e.activeMakerOrders.Add(recoveredOrders...)
+	if len(recoveredOrders) > 0 {
+		log.Infof("recovered %d %s open orders of %s", len(recoveredOrders), e.symbol, e.strategyInstanceID)
+	}
+
+	api, ok := e.session.Exchange.(types.ExchangeTradeHistoryService)
+	if !ok {
+		return nil
+	}
+
+	// the trade at the last position change time is already in the position
+	var since time.Time
+	if !e.position.ChangedAt.IsZero() {
+		since = e.position.ChangedAt.Add(time.Millisecond)
+	} else {
+		for _, o := range recoveredOrders {
+			if since.IsZero() || o.CreationTime.Time().Before(since) {
+				since = o.CreationTime.Time()
+			}
+		}
+	}
+
+	if since.IsZero() {
+		return nil
+	}
+
+	return e.tradeCollector.Recover(ctx, api, e.symbol, since)
 }
 
 // GracefulCancelActiveOrderBook cancels the orders from the active orderbook.
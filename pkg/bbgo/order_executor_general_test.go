@@ -0,0 +1,346 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+// amendTestExchange amends the orders in place like the okex amend endpoint
+type amendTestExchange struct {
+	*mocks.MockExchange
+}
+
+func (e *amendTestExchange) ReplaceOrder(ctx context.Context, order types.Order, newOrder types.SubmitOrder) (*types.Order, *types.Order, error) {
+	order.Quantity = order.ExecutedQuantity.Add(newOrder.Quantity)
+	order.Price = newOrder.Price
+	return nil, &order, nil
+}
+
+// cancelReplaceTestExchange replaces the orders like the binance cancel-replace endpoint,
+// the canceled order is filled by the given quantity during the cancellation
+type cancelReplaceTestExchange struct {
+	*mocks.MockExchange
+
+	filledQuantity fixedpoint.Value
+}
+
+func (e *cancelReplaceTestExchange) ReplaceOrder(ctx context.Context, order types.Order, newOrder types.SubmitOrder) (*types.Order, *types.Order, error) {
+	canceledOrder := order
+	canceledOrder.Status = types.OrderStatusCanceled
+	canceledOrder.ExecutedQuantity = order.ExecutedQuantity.Add(e.filledQuantity)
+	createdOrder := openOrder(order.OrderID+1, newOrder)
+	return &canceledOrder, &createdOrder, nil
+}
+
+// queryTestExchange reconciles the canceled orders with the given order state
+type queryTestExchange struct {
+	*mocks.MockExchange
+
+	canceledOrder types.Order
+}
+
+func (e *queryTestExchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	order := e.canceledOrder
+	return &order, nil
+}
+
+// strategySubmitOrderMatcher matches the submit order with the client order ID of the strategy instance
+type strategySubmitOrderMatcher struct {
+	strategyInstanceID string
+	order              types.SubmitOrder
+}
+
+func strategySubmitOrder(strategyInstanceID string, order types.SubmitOrder) gomock.Matcher {
+	return &strategySubmitOrderMatcher{strategyInstanceID: strategyInstanceID, order: order}
+}
+
+func (m *strategySubmitOrderMatcher) Matches(x interface{}) bool {
+	order, ok := x.(types.SubmitOrder)
+	if !ok || !types.IsStrategyClientOrderID(order.ClientOrderID, m.strategyInstanceID) {
+		return false
+	}
+
+	order.ClientOrderID = m.order.ClientOrderID
+	return gomock.Eq(m.order).Matches(order)
+}
+
+func (m *strategySubmitOrderMatcher) String() string {
+	return fmt.Sprintf("is equal to %v with the client order ID of %s", m.order, m.strategyInstanceID)
+}
+
+func openOrder(orderID uint64, submitOrder types.SubmitOrder) types.Order {
+	return types.Order{
+		SubmitOrder: submitOrder,
+		OrderID:     orderID,
+		Status:      types.OrderStatusNew,
+		IsWorking:   true,
+	}
+}
+
+func TestGeneralOrderExecutor_AmendOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+
+	t.Run("amend in place", func(t *testing.T) {
+		session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+		session.Exchange = &amendTestExchange{MockExchange: mockEx}
+
+		executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", types.NewPositionFromMarket(getTestMarket()))
+		order := openOrder(1, limitOrder(types.SideTypeBuy, 0.1, 19000.0))
+		executor.orderStore.Add(order)
+		executor.activeMakerOrders.Add(order)
+
+		amendedOrder, err := executor.AmendOrder(ctx, order, limitOrder(types.SideTypeBuy, 0.2, 19500.0))
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint64(1), amendedOrder.OrderID)
+			assert.Equal(t, fixedpoint.NewFromFloat(19500.0), amendedOrder.Price)
+		}
+
+		assert.Equal(t, 1, executor.activeMakerOrders.NumOfOrders())
+		storedOrder, ok := executor.orderStore.Get(1)
+		if assert.True(t, ok) {
+			assert.Equal(t, fixedpoint.NewFromFloat(0.2), storedOrder.Quantity)
+		}
+	})
+
+	t.Run("cancel and place", func(t *testing.T) {
+		session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+		exchange := &queryTestExchange{MockExchange: mockEx}
+		session.Exchange = exchange
+
+		executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", types.NewPositionFromMarket(getTestMarket()))
+		order := openOrder(1, limitOrder(types.SideTypeBuy, 0.1, 19000.0))
+		executor.orderStore.Add(order)
+		executor.activeMakerOrders.Add(order)
+
+		// 0.03 is filled during the cancellation
+		exchange.canceledOrder = order
+		exchange.canceledOrder.Status = types.OrderStatusCanceled
+		exchange.canceledOrder.ExecutedQuantity = fixedpoint.NewFromFloat(0.03)
+
+		mockEx.EXPECT().CancelOrders(ctx, order).Return(nil)
+		mockEx.EXPECT().SubmitOrders(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+			assert.Equal(t, fixedpoint.NewFromFloat(0.07), orders[0].Quantity)
+			return types.OrderSlice{openOrder(2, orders[0])}, nil
+		})
+
+		newOrder, err := executor.AmendOrder(ctx, order, limitOrder(types.SideTypeBuy, 0.1, 19500.0))
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint64(2), newOrder.OrderID)
+		}
+
+		activeOrders := executor.activeMakerOrders.Orders()
+		if assert.Len(t, activeOrders, 1) {
+			assert.Equal(t, uint64(2), activeOrders[0].OrderID)
+		}
+
+		// the canceled order is kept for matching its trades
+		canceledOrder, ok := executor.orderStore.Get(1)
+		if assert.True(t, ok) {
+			assert.Equal(t, types.OrderStatusCanceled, canceledOrder.Status)
+		}
+		assert.True(t, executor.orderStore.Exists(2))
+	})
+
+	t.Run("filled before canceled", func(t *testing.T) {
+		session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+		exchange := &queryTestExchange{MockExchange: mockEx}
+		session.Exchange = exchange
+
+		executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", types.NewPositionFromMarket(getTestMarket()))
+		order := openOrder(1, limitOrder(types.SideTypeBuy, 0.1, 19000.0))
+		executor.orderStore.Add(order)
+		executor.activeMakerOrders.Add(order)
+
+		exchange.canceledOrder = order
+		exchange.canceledOrder.Status = types.OrderStatusFilled
+		exchange.canceledOrder.ExecutedQuantity = order.Quantity
+
+		mockEx.EXPECT().CancelOrders(ctx, order).Return(nil)
+
+		_, err := executor.AmendOrder(ctx, order, limitOrder(types.SideTypeBuy, 0.1, 19500.0))
+		assert.True(t, errors.Is(err, ErrReplacedOrderFilled))
+		assert.Equal(t, 0, executor.activeMakerOrders.NumOfOrders())
+	})
+
+	t.Run("remaining quantity too small", func(t *testing.T) {
+		session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+		exchange := &queryTestExchange{MockExchange: mockEx}
+		session.Exchange = exchange
+
+		executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", types.NewPositionFromMarket(getTestMarket()))
+		order := openOrder(1, limitOrder(types.SideTypeBuy, 0.1, 19000.0))
+		executor.orderStore.Add(order)
+		executor.activeMakerOrders.Add(order)
+
+		// the remaining quantity 0.0005 is below the min quantity 0.001
+		exchange.canceledOrder = order
+		exchange.canceledOrder.Status = types.OrderStatusCanceled
+		exchange.canceledOrder.ExecutedQuantity = fixedpoint.NewFromFloat(0.0995)
+
+		mockEx.EXPECT().CancelOrders(ctx, order).Return(nil)
+
+		_, err := executor.AmendOrder(ctx, order, limitOrder(types.SideTypeBuy, 0.1, 19500.0))
+		assert.True(t, errors.Is(err, ErrReplacedOrderRemainingTooSmall))
+		assert.False(t, errors.Is(err, ErrReplacedOrderFilled))
+		assert.Equal(t, 0, executor.activeMakerOrders.NumOfOrders())
+	})
+
+	t.Run("cancel-replace filled during the cancellation", func(t *testing.T) {
+		session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+		exchange := &cancelReplaceTestExchange{MockExchange: mockEx, filledQuantity: fixedpoint.NewFromFloat(0.03)}
+		session.Exchange = exchange
+
+		executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", types.NewPositionFromMarket(getTestMarket()))
+		order := openOrder(1, limitOrder(types.SideTypeBuy, 0.1, 19000.0))
+		executor.orderStore.Add(order)
+		executor.activeMakerOrders.Add(order)
+
+		// the new order 2 is placed with the full quantity, it's returned with the error and not replaced again
+		newOrder, err := executor.AmendOrder(ctx, order, limitOrder(types.SideTypeBuy, 0.1, 19500.0))
+		assert.True(t, errors.Is(err, ErrReplacedOrderOverfilled))
+		if assert.NotNil(t, newOrder) {
+			assert.Equal(t, uint64(2), newOrder.OrderID)
+			assert.Equal(t, fixedpoint.NewFromFloat(0.1), newOrder.Quantity)
+		}
+
+		activeOrders := executor.activeMakerOrders.Orders()
+		if assert.Len(t, activeOrders, 1) {
+			assert.Equal(t, uint64(2), activeOrders[0].OrderID)
+		}
+
+		canceledOrder, ok := executor.orderStore.Get(1)
+		if assert.True(t, ok) {
+			assert.Equal(t, types.OrderStatusCanceled, canceledOrder.Status)
+			assert.Equal(t, fixedpoint.NewFromFloat(0.03), canceledOrder.ExecutedQuantity)
+		}
+	})
+}
+
+func TestGeneralOrderExecutor_ReplaceOrders(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+	session.Exchange = &amendTestExchange{MockExchange: mockEx}
+
+	executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", types.NewPositionFromMarket(getTestMarket()))
+	orders := []types.Order{
+		openOrder(1, limitOrder(types.SideTypeBuy, 0.1, 19000.0)),
+		openOrder(2, limitOrder(types.SideTypeBuy, 0.1, 18000.0)),
+	}
+	executor.orderStore.Add(orders...)
+	executor.activeMakerOrders.Add(orders...)
+
+	// the extra open order is canceled
+	mockEx.EXPECT().CancelOrders(ctx, orders[1]).Return(nil)
+
+	replacedOrders, err := executor.ReplaceOrders(ctx, orders, limitOrder(types.SideTypeBuy, 0.1, 19500.0))
+	assert.NoError(t, err)
+	if assert.Len(t, replacedOrders, 1) {
+		assert.Equal(t, uint64(1), replacedOrders[0].OrderID)
+		assert.Equal(t, fixedpoint.NewFromFloat(19500.0), replacedOrders[0].Price)
+	}
+}
+
+func TestPreTradeRiskControl_ReplacingOrders(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, _ := newPreTradeRiskTestSession(mockCtrl)
+	session.SetPreTradeRiskControl(&PreTradeRiskControl{
+		PreTradeRiskRules: PreTradeRiskRules{MaxOpenOrders: 1},
+	})
+
+	order := openOrder(1, limitOrder(types.SideTypeBuy, 0.1, 19000.0))
+	orderStore, _ := session.OrderStore("BTCUSDT")
+	orderStore.Add(order)
+
+	_, err := session.checkPreTradeRisk("test", nil, []types.SubmitOrder{limitOrder(types.SideTypeBuy, 0.1, 19500.0)})
+	assert.True(t, errors.Is(err, ErrRiskOpenOrdersLimitExceeded))
+
+	// the replaced order is not counted
+	accepted, err := session.checkPreTradeRisk("test", nil, []types.SubmitOrder{limitOrder(types.SideTypeBuy, 0.1, 19500.0)}, order)
+	assert.NoError(t, err)
+	assert.Len(t, accepted, 1)
+}
+
+// historyTestExchange returns the given closed orders and trades
+type historyTestExchange struct {
+	*mocks.MockExchange
+
+	closedOrders []types.Order
+	trades       []types.Trade
+}
+
+func (e *historyTestExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	return e.trades, nil
+}
+
+func (e *historyTestExchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	return e.closedOrders, nil
+}
+
+func TestGeneralOrderExecutor_Recover(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+	exchange := &historyTestExchange{MockExchange: mockEx}
+	session.Exchange = exchange
+
+	position := types.NewPositionFromMarket(getTestMarket())
+	position.ChangedAt = time.Now().Add(-time.Hour)
+	executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", position)
+
+	ownedOrder := func(orderID uint64, status types.OrderStatus) types.Order {
+		submitOrder := limitOrder(types.SideTypeBuy, 0.1, 19000.0)
+		submitOrder.ClientOrderID = "x-NSUYEBKM" + types.NewStrategyClientOrderID("test:BTCUSDT")
+		order := openOrder(orderID, submitOrder)
+		order.Status = status
+		return order
+	}
+
+	// the orders of the other strategy instance and the manual orders are not recovered
+	otherOrder := openOrder(3, limitOrder(types.SideTypeBuy, 0.1, 18000.0))
+	otherOrder.ClientOrderID = types.NewStrategyClientOrderID("test:ETHUSDT")
+
+	mockEx.EXPECT().QueryOpenOrders(ctx, "BTCUSDT").Return([]types.Order{ownedOrder(1, types.OrderStatusNew), otherOrder}, nil)
+	exchange.closedOrders = []types.Order{ownedOrder(2, types.OrderStatusFilled)}
+	exchange.trades = []types.Trade{
+		{ID: 1, OrderID: 2, Symbol: "BTCUSDT", Side: types.SideTypeBuy, IsBuyer: true, Price: fixedpoint.NewFromFloat(19000.0), Quantity: fixedpoint.NewFromFloat(0.1), Time: types.Time(time.Now())},
+		{ID: 2, OrderID: 4, Symbol: "BTCUSDT", Side: types.SideTypeBuy, IsBuyer: true, Price: fixedpoint.NewFromFloat(19000.0), Quantity: fixedpoint.NewFromFloat(0.2), Time: types.Time(time.Now())},
+	}
+
+	assert.NoError(t, executor.Recover(ctx))
+
+	activeOrders := executor.activeMakerOrders.Orders()
+	if assert.Len(t, activeOrders, 1) {
+		assert.Equal(t, uint64(1), activeOrders[0].OrderID)
+	}
+	assert.True(t, executor.orderStore.Exists(2))
+	assert.False(t, executor.orderStore.Exists(3))
+
+	// only the trade of the owned order is added to the position
+	assert.Equal(t, fixedpoint.NewFromFloat(0.1), position.Base)
+
+	// the owned orders are also recovered from the order updates
+	executor.orderStore.handleOrderUpdate(ownedOrder(5, types.OrderStatusPartiallyFilled))
+	executor.orderStore.handleOrderUpdate(otherOrder)
+	assert.True(t, executor.orderStore.Exists(5))
+	assert.False(t, executor.orderStore.Exists(3))
+}
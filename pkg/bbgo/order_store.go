@@ -15,6 +15,10 @@ type OrderStore struct {
 	RemoveCancelled bool
 	RemoveFilled    bool
 	AddOrderUpdate  bool
+
+	// StrategyInstanceID is the owner of the orders, the orders with the client order IDs of the strategy instance
+	// are added from the order updates, so that the ownership of the orders is recovered after a restart
+	StrategyInstanceID string
 }
 
 func NewOrderStore(symbol string) *OrderStore {
@@ -93,6 +97,34 @@ func (s *OrderStore) Add(orders ...types.Order) {
 	}
 }
 
+// Owns checks if the order is submitted by the strategy instance of the order store via the client order ID
+func (s *OrderStore) Owns(o types.Order) bool {
+	hasSymbol := s.Symbol != ""
+	if hasSymbol && o.Symbol != s.Symbol {
+		return false
+	}
+
+	return types.IsStrategyClientOrderID(o.ClientOrderID, s.StrategyInstanceID)
+}
+
+// Recover adds the orders owned by the strategy instance that are not in the order store, the recovered orders are returned
+func (s *OrderStore) Recover(orders ...types.Order) (recovered []types.Order) {
+	for _, o := range orders {
+		if !s.Owns(o) {
+			continue
+		}
+
+		s.mu.Lock()
+		if _, ok := s.orders[o.OrderID]; !ok {
+			s.orders[o.OrderID] = o
+			recovered = append(recovered, o)
+		}
+		s.mu.Unlock()
+	}
+
+	return recovered
+}
+
 func (s *OrderStore) Remove(o types.Order) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -111,6 +143,24 @@ func (s *OrderStore) Update(o types.Order) bool {
 	return ok
 }
 
+// Replace tracks the order ID change of a replaced order.
+// The amended order with the same order ID is updated in place. Otherwise, the new order is added, and the replaced
+// order is marked as canceled and kept for matching its late trades, the order update of the stream removes it.
+func (s *OrderStore) Replace(order, newOrder types.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if order.OrderID != newOrder.OrderID {
+		if o, ok := s.orders[order.OrderID]; ok {
+			o.Status = types.OrderStatusCanceled
+			o.IsWorking = false
+			s.orders[order.OrderID] = o
+		}
+	}
+
+	s.orders[newOrder.OrderID] = newOrder
+}
+
 func (s *OrderStore) BindStream(stream types.Stream) {
 	hasSymbol := s.Symbol != ""
 	stream.OnOrderUpdate(func(order types.Order) {
@@ -124,6 +174,11 @@ func (s *OrderStore) BindStream(stream types.Stream) {
 }
 
 func (s *OrderStore) handleOrderUpdate(order types.Order) {
+	// recover the orders that are submitted by the strategy instance before a restart
+	if !s.AddOrderUpdate {
+		s.Recover(order)
+	}
+
 	switch order.Status {
 
 	case types.OrderStatusNew, types.OrderStatusPartiallyFilled, types.OrderStatusFilled:
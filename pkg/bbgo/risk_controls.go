@@ -38,11 +38,12 @@ func (e *RiskControlOrderExecutor) SubmitOrders(ctx context.Context, orders ...t
 		}
 
 		retOrders2, err := e.ExchangeOrderExecutor.SubmitOrders(ctx, formattedOrders...)
+
+		// the orders accepted by the pre-trade risk control are created even if some orders are rejected
+		retOrders = append(retOrders, retOrders2...)
 		if err != nil {
 			return retOrders, err
 		}
-
-		retOrders = append(retOrders, retOrders2...)
 	}
 
 	return
@@ -50,6 +51,9 @@ func (e *RiskControlOrderExecutor) SubmitOrders(ctx context.Context, orders ...t
 
 type SessionBasedRiskControl struct {
 	OrderExecutor *RiskControlOrderExecutor `json:"orderExecutor,omitempty" yaml:"orderExecutor"`
+
+	// PreTrade is the pre-trade risk pipeline of the session, all the order executors of the session go through it
+	PreTrade *PreTradeRiskControl `json:"preTrade,omitempty" yaml:"preTrade,omitempty"`
 }
 
 func (control *SessionBasedRiskControl) SetBaseOrderExecutor(executor *ExchangeOrderExecutor) {
@@ -71,4 +75,10 @@ func groupSubmitOrdersBySymbol(orders []types.SubmitOrder) map[string][]types.Su
 
 type RiskControls struct {
 	SessionBasedRiskControl map[string]*SessionBasedRiskControl `json:"sessionBased,omitempty" yaml:"sessionBased,omitempty"`
+
+	// KillSwitch is the account-level daily loss limit and drawdown circuit breaker across all the sessions
+	KillSwitch *KillSwitch `json:"killSwitch,omitempty" yaml:"killSwitch,omitempty"`
+
+	// Exposure is the cross-strategy net exposure caps across all the sessions
+	Exposure *ExposureManager `json:"exposure,omitempty" yaml:"exposure,omitempty"`
 }
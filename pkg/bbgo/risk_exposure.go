@@ -0,0 +1,441 @@
+package bbgo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrRiskExposureLimitExceeded = errors.New("net exposure limit exceeded")
+
+// ExposureLimit is the net exposure cap of an asset, the zero values disable the caps
+type ExposureLimit struct {
+	// MaxNetQuantity is the max absolute net position quantity of the asset
+	MaxNetQuantity fixedpoint.Value `json:"maxNetQuantity,omitempty" yaml:"maxNetQuantity,omitempty"`
+
+	// MaxNetValue is the max absolute net position value of the asset in the reference currency
+	MaxNetValue fixedpoint.Value `json:"maxNetValue,omitempty" yaml:"maxNetValue,omitempty"`
+}
+
+// ExposureManager aggregates the strategy positions of the general order executors across the strategies and the
+// sessions, and blocks or downsizes the orders that would exceed the net exposure caps.
+type ExposureManager struct {
+	// ReferenceCurrency is the currency of the exposure values, default USDT
+	ReferenceCurrency string `json:"referenceCurrency,omitempty" yaml:"referenceCurrency,omitempty"`
+
+	// Assets are the net exposure caps of the assets, map: asset -> limit
+	Assets map[string]*ExposureLimit `json:"assets,omitempty" yaml:"assets,omitempty"`
+
+	// MaxTotalExposure is the max sum of the absolute net values of all the assets in the reference currency
+	MaxTotalExposure fixedpoint.Value `json:"maxTotalExposure,omitempty" yaml:"maxTotalExposure,omitempty"`
+
+	// Downsize reduces the order quantity to fit the caps instead of rejecting the order
+	Downsize bool `json:"downsize,omitempty" yaml:"downsize,omitempty"`
+
+	mu       sync.Mutex
+	sessions map[string]*ExchangeSession
+}
+
+// PositionExposure is the position of a strategy instance in the exposure breakdown
+type PositionExposure struct {
+	Session            string `json:"session"`
+	StrategyInstanceID string `json:"strategyInstanceID"`
+	Symbol             string `json:"symbol"`
+
+	// Base is the net quantity of the asset held by the position, it's the quote quantity for the quote leg
+	Base fixedpoint.Value `json:"base"`
+}
+
+// AssetExposure is the net position of an asset across the strategies and the sessions
+type AssetExposure struct {
+	Asset       string           `json:"asset"`
+	NetQuantity fixedpoint.Value `json:"netQuantity"`
+	Price       fixedpoint.Value `json:"price"`
+	Value       fixedpoint.Value `json:"value"`
+
+	// Priced is false when the price of the asset in the reference currency is not found
+	Priced bool `json:"priced"`
+
+	Positions []PositionExposure `json:"positions"`
+}
+
+// ExposureReport is the exposure breakdown by asset
+type ExposureReport struct {
+	ReferenceCurrency string           `json:"referenceCurrency"`
+	TotalExposure     fixedpoint.Value `json:"totalExposure"`
+	Assets            []AssetExposure  `json:"assets"`
+}
+
+func (m *ExposureManager) referenceCurrency() string {
+	if len(m.ReferenceCurrency) > 0 {
+		return m.ReferenceCurrency
+	}
+
+	return "USDT"
+}
+
+// Setup binds the sessions to the exposure manager
+func (m *ExposureManager) Setup(sessions map[string]*ExchangeSession) {
+	m.mu.Lock()
+	m.sessions = sessions
+	m.mu.Unlock()
+
+	for _, session := range sessions {
+		session.exposureManager = m
+	}
+}
+
+// NetPositions returns the net base positions by asset
+func (m *ExposureManager) NetPositions() map[string]fixedpoint.Value {
+	netPositions := make(map[string]fixedpoint.Value)
+	for _, p := range m.positions() {
+		netPositions[p.asset] = netPositions[p.asset].Add(p.Base)
+	}
+
+	return netPositions
+}
+
+// Report returns the exposure breakdown valued in the reference currency
+func (m *ExposureManager) Report() ExposureReport {
+	report := ExposureReport{ReferenceCurrency: m.referenceCurrency()}
+
+	assets := make(map[string]*AssetExposure)
+	for _, p := range m.positions() {
+		exposure, ok := assets[p.asset]
+		if !ok {
+			exposure = &AssetExposure{Asset: p.asset}
+			assets[p.asset] = exposure
+		}
+
+		exposure.NetQuantity = exposure.NetQuantity.Add(p.Base)
+		exposure.Positions = append(exposure.Positions, p.PositionExposure)
+	}
+
+	prices := m.prices()
+	for _, exposure := range assets {
+		if price, ok := m.price(prices, exposure.Asset); ok {
+			exposure.Priced = true
+			exposure.Price = price
+			exposure.Value = exposure.NetQuantity.Mul(price)
+			report.TotalExposure = report.TotalExposure.Add(exposure.Value.Abs())
+		}
+
+		report.Assets = append(report.Assets, *exposure)
+	}
+
+	sort.Slice(report.Assets, func(i, j int) bool {
+		return report.Assets[i].Asset < report.Assets[j].Asset
+	})
+
+	return report
+}
+
+// CheckOrders rejects or downsizes the orders that would exceed the net exposure caps, the open orders of the sessions
+// and the accepted orders of the same batch are counted
+func (m *ExposureManager) CheckOrders(session *ExchangeSession, orders ...types.SubmitOrder) (accepted []types.SubmitOrder, rejections []*RiskRejection) {
+	return m.checkOrders(session, nil, orders)
+}
+
+// checkOrders checks the orders against the worst net positions, which assume all the pending buy orders or all the
+// pending sell orders are filled. The replacing orders are not counted as the pending orders.
+func (m *ExposureManager) checkOrders(session *ExchangeSession, replacing []types.Order, orders []types.SubmitOrder) (accepted []types.SubmitOrder, rejections []*RiskRejection) {
+	netPositions := m.NetPositions()
+	prices := m.prices()
+	longs, shorts := m.worstPositions(netPositions, replacing)
+
+	for _, order := range orders {
+		market, ok := session.Market(order.Symbol)
+		if !ok {
+			accepted = append(accepted, order)
+			continue
+		}
+
+		price, hasPrice := orderPrice(session, order)
+		legs := m.orderLegs(market, order.Side, price, hasPrice)
+
+		quantity := order.Quantity
+		var reason string
+		for _, leg := range legs {
+			limit, limitReason, ok := m.quantityLimit(leg.asset, netPositions, prices)
+			if !ok {
+				continue
+			}
+
+			// the worst position in the order direction, a flip to the other side is capped by the limit as well
+			worst := longs[leg.asset]
+			if leg.ratio.Sign() < 0 {
+				worst = shorts[leg.asset].Neg()
+			}
+
+			ratio := leg.ratio.Abs()
+			after := worst.Add(ratio.Mul(quantity))
+			if after.Compare(limit) <= 0 {
+				continue
+			}
+
+			if len(reason) == 0 {
+				if leg.ratio.Sign() < 0 {
+					after = after.Neg()
+				}
+
+				reason = fmt.Sprintf("net %s position would be %s, %s", leg.asset, after.String(), limitReason)
+			}
+
+			quantity = fixedpoint.Min(quantity, limit.Sub(worst).Div(ratio))
+		}
+
+		if len(reason) > 0 {
+			if market.StepSize.Sign() > 0 {
+				quantity = market.TruncateQuantity(quantity)
+			}
+
+			if !m.Downsize || quantity.Sign() <= 0 || quantity.Compare(market.MinQuantity) < 0 || market.IsDustQuantity(quantity, price) {
+				rejections = append(rejections, &RiskRejection{
+					Session: session.Name,
+					Order:   order,
+					Err:     ErrRiskExposureLimitExceeded,
+					Reason:  reason,
+				})
+				continue
+			}
+
+			order.Quantity = quantity
+		}
+
+		addPendingLegs(longs, shorts, legs, order.Quantity)
+		accepted = append(accepted, order)
+	}
+
+	return accepted, rejections
+}
+
+// exposureLeg is the net quantity change of an asset per unit of the order quantity
+type exposureLeg struct {
+	asset string
+	ratio fixedpoint.Value
+}
+
+// orderLegs returns the base leg and the quote leg of an order, the quote leg of the reference currency is skipped,
+// and so is the quote leg of an order without a price
+func (m *ExposureManager) orderLegs(market types.Market, side types.SideType, price fixedpoint.Value, hasPrice bool) []exposureLeg {
+	sign := fixedpoint.One
+	if side == types.SideTypeSell {
+		sign = sign.Neg()
+	}
+
+	legs := []exposureLeg{{asset: market.BaseCurrency, ratio: sign}}
+	if market.QuoteCurrency != m.referenceCurrency() && hasPrice && price.Sign() > 0 {
+		legs = append(legs, exposureLeg{asset: market.QuoteCurrency, ratio: sign.Neg().Mul(price)})
+	}
+
+	return legs
+}
+
+func addPendingLegs(longs, shorts map[string]fixedpoint.Value, legs []exposureLeg, quantity fixedpoint.Value) {
+	for _, leg := range legs {
+		delta := leg.ratio.Mul(quantity)
+		if delta.Sign() > 0 {
+			longs[leg.asset] = longs[leg.asset].Add(delta)
+		} else {
+			shorts[leg.asset] = shorts[leg.asset].Add(delta)
+		}
+	}
+}
+
+// worstPositions returns the net positions of the assets if all the pending buy legs (longs) or all the pending
+// sell legs (shorts) of the open orders are filled
+func (m *ExposureManager) worstPositions(netPositions map[string]fixedpoint.Value, replacing []types.Order) (longs, shorts map[string]fixedpoint.Value) {
+	longs = make(map[string]fixedpoint.Value, len(netPositions))
+	shorts = make(map[string]fixedpoint.Value, len(netPositions))
+	for asset, quantity := range netPositions {
+		longs[asset] = quantity
+		shorts[asset] = quantity
+	}
+
+	m.mu.Lock()
+	sessions := m.sessions
+	m.mu.Unlock()
+
+	isReplacing := func(order types.Order) bool {
+		for _, o := range replacing {
+			if o.OrderID == order.OrderID && o.Symbol == order.Symbol {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, session := range sessions {
+		for symbol, store := range session.OrderStores() {
+			market, ok := session.Market(symbol)
+			if !ok {
+				continue
+			}
+
+			for _, order := range store.Orders() {
+				switch order.Status {
+				case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+				default:
+					continue
+				}
+
+				if isReplacing(order) {
+					continue
+				}
+
+				price, hasPrice := orderPrice(session, order.SubmitOrder)
+				remaining := order.Quantity.Sub(order.ExecutedQuantity)
+				addPendingLegs(longs, shorts, m.orderLegs(market, order.Side, price, hasPrice), remaining)
+			}
+		}
+	}
+
+	return longs, shorts
+}
+
+// quantityLimit returns the max absolute net quantity of the asset from the caps
+func (m *ExposureManager) quantityLimit(asset string, netPositions map[string]fixedpoint.Value, prices map[string]fixedpoint.Value) (limit fixedpoint.Value, reason string, ok bool) {
+	update := func(l fixedpoint.Value, r string) {
+		if !ok || l.Compare(limit) < 0 {
+			limit, reason, ok = fixedpoint.Max(l, fixedpoint.Zero), r, true
+		}
+	}
+
+	price, hasPrice := m.price(prices, asset)
+
+	if assetLimit, found := m.Assets[asset]; found && assetLimit != nil {
+		if assetLimit.MaxNetQuantity.Sign() > 0 {
+			update(assetLimit.MaxNetQuantity, fmt.Sprintf("max net quantity %s", assetLimit.MaxNetQuantity.String()))
+		}
+
+		if assetLimit.MaxNetValue.Sign() > 0 && hasPrice && price.Sign() > 0 {
+			update(assetLimit.MaxNetValue.Div(price), fmt.Sprintf("max net value %s %s", assetLimit.MaxNetValue.String(), m.referenceCurrency()))
+		}
+	}
+
+	if m.MaxTotalExposure.Sign() > 0 && hasPrice && price.Sign() > 0 {
+		// the exposure of the other assets
+		others := fixedpoint.Zero
+		for a, quantity := range netPositions {
+			if a == asset {
+				continue
+			}
+
+			if p, ok := m.price(prices, a); ok {
+				others = others.Add(quantity.Mul(p).Abs())
+			}
+		}
+
+		update(m.MaxTotalExposure.Sub(others).Div(price), fmt.Sprintf("max total exposure %s %s", m.MaxTotalExposure.String(), m.referenceCurrency()))
+	}
+
+	return limit, reason, ok
+}
+
+type assetPosition struct {
+	PositionExposure
+	asset string
+}
+
+// positions returns the base legs and the quote legs of the strategy positions of the sessions, e.g., a long ETHBTC
+// position is short BTC. The legs of the reference currency are skipped.
+func (m *ExposureManager) positions() (positions []assetPosition) {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.mu.Unlock()
+
+	ref := m.referenceCurrency()
+	for sessionName, session := range sessions {
+		for key, position := range session.copyStrategyPositions() {
+			position.Lock()
+			base, quote := position.Base, position.Quote
+			position.Unlock()
+
+			legs := []assetPosition{
+				{asset: position.BaseCurrency, PositionExposure: PositionExposure{Base: base}},
+				{asset: position.QuoteCurrency, PositionExposure: PositionExposure{Base: quote}},
+			}
+
+			for _, leg := range legs {
+				if leg.asset == ref {
+					continue
+				}
+
+				leg.Session = sessionName
+				leg.StrategyInstanceID = key.strategyInstanceID
+				leg.Symbol = position.Symbol
+				positions = append(positions, leg)
+			}
+		}
+	}
+
+	return positions
+}
+
+func (m *ExposureManager) prices() map[string]fixedpoint.Value {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.mu.Unlock()
+
+	prices := make(map[string]fixedpoint.Value)
+	for _, session := range sessions {
+		for symbol, price := range session.LastPrices() {
+			prices[symbol] = price
+		}
+	}
+
+	return prices
+}
+
+// price returns the price of the asset in the reference currency
+func (m *ExposureManager) price(prices map[string]fixedpoint.Value, asset string) (fixedpoint.Value, bool) {
+	ref := m.referenceCurrency()
+	if asset == ref {
+		return fixedpoint.One, true
+	}
+
+	if price, ok := prices[asset+ref]; ok {
+		return price, true
+	}
+
+	if price, ok := prices[ref+asset]; ok && price.Sign() > 0 {
+		return fixedpoint.One.Div(price), true
+	}
+
+	return fixedpoint.Zero, false
+}
+
+type strategyPositionKey struct {
+	strategyInstanceID string
+	symbol             string
+}
+
+// addStrategyPosition registers the position of a strategy instance, the positions are aggregated by the exposure manager
+func (session *ExchangeSession) addStrategyPosition(strategyInstanceID string, position *types.Position) {
+	session.strategyPositionsMutex.Lock()
+	defer session.strategyPositionsMutex.Unlock()
+
+	if session.strategyPositions == nil {
+		session.strategyPositions = make(map[strategyPositionKey]*types.Position)
+	}
+
+	session.strategyPositions[strategyPositionKey{strategyInstanceID: strategyInstanceID, symbol: position.Symbol}] = position
+}
+
+// copyStrategyPositions returns a copy of the strategy positions of the general order executors of the session
+func (session *ExchangeSession) copyStrategyPositions() map[strategyPositionKey]*types.Position {
+	session.strategyPositionsMutex.Lock()
+	defer session.strategyPositionsMutex.Unlock()
+
+	positions := make(map[strategyPositionKey]*types.Position, len(session.strategyPositions))
+	for key, position := range session.strategyPositions {
+		positions[key] = position
+	}
+
+	return positions
+}
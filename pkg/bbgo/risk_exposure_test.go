@@ -0,0 +1,183 @@
+package bbgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExposureManager(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, _ := newPreTradeRiskTestSession(mockCtrl)
+
+	market := getTestMarket()
+	position1 := types.NewPositionFromMarket(market)
+	position1.Base = fixedpoint.NewFromFloat(0.3)
+	session.addStrategyPosition("grid:BTCUSDT", position1)
+
+	position2 := types.NewPositionFromMarket(market)
+	position2.Base = fixedpoint.NewFromFloat(-0.1)
+	session.addStrategyPosition("pivotshort:BTCUSDT", position2)
+
+	manager := &ExposureManager{
+		Assets: map[string]*ExposureLimit{
+			"BTC": {MaxNetQuantity: fixedpoint.NewFromFloat(0.5), MaxNetValue: fixedpoint.NewFromFloat(8000.0)},
+		},
+	}
+	manager.Setup(map[string]*ExchangeSession{session.Name: session})
+
+	report := manager.Report()
+	assert.Equal(t, "USDT", report.ReferenceCurrency)
+	assert.Equal(t, fixedpoint.NewFromFloat(4000.0), report.TotalExposure)
+	if assert.Len(t, report.Assets, 1) {
+		assert.Equal(t, "BTC", report.Assets[0].Asset)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.2), report.Assets[0].NetQuantity)
+		assert.True(t, report.Assets[0].Priced)
+		assert.Len(t, report.Assets[0].Positions, 2)
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		// the max net value 8000 USDT at 20000 is 0.4 BTC, which is lower than the max net quantity
+		accepted, rejections := manager.CheckOrders(session,
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+			limitOrder(types.SideTypeBuy, 0.2, 20000.0),
+			limitOrder(types.SideTypeSell, 0.5, 20000.0),
+		)
+		assert.Len(t, accepted, 2)
+		if assert.Len(t, rejections, 1) {
+			assert.True(t, errors.Is(rejections[0], ErrRiskExposureLimitExceeded))
+			assert.Equal(t, fixedpoint.NewFromFloat(0.2), rejections[0].Order.Quantity)
+		}
+	})
+
+	t.Run("downsize", func(t *testing.T) {
+		manager.Downsize = true
+		defer func() { manager.Downsize = false }()
+
+		accepted, rejections := manager.CheckOrders(session,
+			limitOrder(types.SideTypeBuy, 0.5, 20000.0),
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+			limitOrder(types.SideTypeSell, 1.0, 20000.0),
+		)
+
+		// 0.5 is downsized to 0.2 for the 0.4 cap, the next buy has no room, and the sell is downsized to 0.6 (0.2 -> -0.4)
+		// since the accepted buy order may not be filled
+		if assert.Len(t, accepted, 2) {
+			assert.Equal(t, fixedpoint.NewFromFloat(0.2), accepted[0].Quantity)
+			assert.Equal(t, fixedpoint.NewFromFloat(0.6), accepted[1].Quantity)
+		}
+		assert.Len(t, rejections, 1)
+	})
+
+	t.Run("open orders", func(t *testing.T) {
+		store, _ := session.OrderStore("BTCUSDT")
+		openBuyOrder := openOrder(1, limitOrder(types.SideTypeBuy, 0.15, 19000.0))
+		store.Add(openBuyOrder)
+		defer store.Remove(openBuyOrder)
+
+		// 0.2 + 0.15 (open) + 0.1 exceeds the 0.4 cap
+		_, rejections := manager.CheckOrders(session, limitOrder(types.SideTypeBuy, 0.1, 20000.0))
+		assert.Len(t, rejections, 1)
+
+		// the replaced open order is not counted
+		accepted, err := session.checkPreTradeRisk("", nil, []types.SubmitOrder{limitOrder(types.SideTypeBuy, 0.1, 20000.0)}, openBuyOrder)
+		assert.NoError(t, err)
+		assert.Len(t, accepted, 1)
+	})
+
+	t.Run("position flip", func(t *testing.T) {
+		manager := &ExposureManager{
+			Assets: map[string]*ExposureLimit{
+				"BTC": {MaxNetQuantity: fixedpoint.NewFromFloat(0.1)},
+			},
+		}
+		manager.Setup(map[string]*ExchangeSession{session.Name: session})
+
+		// 0.2 -> -0.15 reduces the absolute position, but the short side exceeds the 0.1 cap
+		_, rejections := manager.CheckOrders(session, limitOrder(types.SideTypeSell, 0.35, 20000.0))
+		if assert.Len(t, rejections, 1) {
+			assert.Contains(t, rejections[0].Reason, "net BTC position would be -0.15")
+		}
+
+		accepted, rejections := manager.CheckOrders(session, limitOrder(types.SideTypeSell, 0.3, 20000.0))
+		assert.Len(t, accepted, 1)
+		assert.Empty(t, rejections)
+	})
+
+	t.Run("total exposure", func(t *testing.T) {
+		manager := &ExposureManager{MaxTotalExposure: fixedpoint.NewFromFloat(5000.0)}
+		manager.Setup(map[string]*ExchangeSession{session.Name: session})
+
+		_, rejections := manager.CheckOrders(session, limitOrder(types.SideTypeBuy, 0.1, 20000.0))
+		if assert.Len(t, rejections, 1) {
+			assert.Contains(t, rejections[0].Reason, "max total exposure")
+		}
+	})
+
+	// the orders of the session executors go through the exposure manager
+	_, err := session.checkPreTradeRisk("", nil, []types.SubmitOrder{limitOrder(types.SideTypeBuy, 0.3, 20000.0)})
+	assert.True(t, errors.Is(err, ErrRiskExposureLimitExceeded))
+}
+
+func TestExposureManager_QuoteLeg(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, _ := newPreTradeRiskTestSession(mockCtrl)
+
+	market := types.Market{
+		Symbol:          "ETHBTC",
+		PricePrecision:  6,
+		VolumePrecision: 4,
+		BaseCurrency:    "ETH",
+		QuoteCurrency:   "BTC",
+		MinQuantity:     fixedpoint.NewFromFloat(0.001),
+		StepSize:        fixedpoint.NewFromFloat(0.0001),
+	}
+	session.markets[market.Symbol] = market
+	session.lastPrices[market.Symbol] = fixedpoint.NewFromFloat(0.05)
+
+	// the long ETHBTC position is short BTC
+	position := types.NewPositionFromMarket(market)
+	position.Base = fixedpoint.NewFromFloat(10.0)
+	position.Quote = fixedpoint.NewFromFloat(-0.5)
+	session.addStrategyPosition("grid:ETHBTC", position)
+
+	manager := &ExposureManager{
+		Assets: map[string]*ExposureLimit{
+			"BTC": {MaxNetQuantity: fixedpoint.NewFromFloat(0.6)},
+		},
+	}
+	manager.Setup(map[string]*ExchangeSession{session.Name: session})
+
+	netPositions := manager.NetPositions()
+	assert.Equal(t, fixedpoint.NewFromFloat(10.0), netPositions["ETH"])
+	assert.Equal(t, fixedpoint.NewFromFloat(-0.5), netPositions["BTC"])
+
+	order := types.SubmitOrder{
+		Symbol:   "ETHBTC",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(3.0),
+		Price:    fixedpoint.NewFromFloat(0.05),
+	}
+
+	// buying 3 ETH with 0.15 BTC makes the BTC position -0.65
+	_, rejections := manager.CheckOrders(session, order)
+	if assert.Len(t, rejections, 1) {
+		assert.Contains(t, rejections[0].Reason, "net BTC position would be -0.65")
+	}
+
+	manager.Downsize = true
+	accepted, _ := manager.CheckOrders(session, order)
+	if assert.Len(t, accepted, 1) {
+		assert.Equal(t, fixedpoint.NewFromFloat(2.0), accepted[0].Quantity)
+	}
+}
@@ -0,0 +1,573 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrRiskKillSwitchTripped = errors.New("kill switch is tripped")
+
+const killSwitchStoreID = "kill-switch"
+
+// KillSwitchState is the persistent state of the kill switch, the tripped state is kept across restarts until it's reset
+type KillSwitchState struct {
+	Tripped   bool      `json:"tripped"`
+	TrippedAt time.Time `json:"trippedAt,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+
+	// Date is the UTC date of the daily counters, format: 2006-01-02
+	Date string `json:"date"`
+
+	// DayStartEquity is the equity in USD of the first check of the day
+	DayStartEquity fixedpoint.Value `json:"dayStartEquity"`
+
+	// DailyRealizedProfit is the sum of the realized net profits in USD of the day
+	DailyRealizedProfit fixedpoint.Value `json:"dailyRealizedProfit"`
+
+	// EquitySamples are the equity samples of the drawdown window in time order, only the samples that can be the peak
+	// are kept, a sample is dropped when a later sample has a higher or equal equity
+	EquitySamples []EquitySample `json:"equitySamples,omitempty"`
+}
+
+type EquitySample struct {
+	Time   time.Time        `json:"time"`
+	Equity fixedpoint.Value `json:"equity"`
+}
+
+// KillSwitch is the account-level circuit breaker. It watches the realized profits of the order executors and the
+// mark-to-market equity of all the sessions, when the daily loss limit or the max drawdown is breached, it suspends the
+// strategies, cancels the open orders and optionally flattens the positions.
+type KillSwitch struct {
+	// DailyLossLimit is the max loss in USD of the day (UTC), either the realized net loss or the equity loss since the
+	// start of the day
+	DailyLossLimit fixedpoint.Value `json:"dailyLossLimit,omitempty" yaml:"dailyLossLimit,omitempty"`
+
+	// MaxDrawdown is the max drawdown ratio of the equity from its peak in the rolling drawdown window, e.g., 0.1 for 10%
+	MaxDrawdown fixedpoint.Value `json:"maxDrawdown,omitempty" yaml:"maxDrawdown,omitempty"`
+
+	// DrawdownWindow is the rolling window of the max drawdown, default 24h
+	DrawdownWindow types.Duration `json:"drawdownWindow,omitempty" yaml:"drawdownWindow,omitempty"`
+
+	// CheckInterval is the interval of the equity check, default 1m
+	CheckInterval types.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+
+	// FlattenPositions submits the market orders to close the session positions when the kill switch is tripped
+	FlattenPositions bool `json:"flattenPositions,omitempty" yaml:"flattenPositions,omitempty"`
+
+	mu    sync.Mutex
+	state KillSwitchState
+
+	// saveMu serializes the saves of the state, the store is written outside mu
+	saveMu sync.Mutex
+
+	store      service.Store
+	sessions   map[string]*ExchangeSession
+	strategies []interface{}
+
+	checkC chan struct{}
+}
+
+func (k *KillSwitch) drawdownWindow() time.Duration {
+	if k.DrawdownWindow > 0 {
+		return k.DrawdownWindow.Duration()
+	}
+
+	return 24 * time.Hour
+}
+
+func (k *KillSwitch) checkInterval() time.Duration {
+	if k.CheckInterval > 0 {
+		return k.CheckInterval.Duration()
+	}
+
+	return time.Minute
+}
+
+// Setup loads the persisted state and binds the sessions and the strategies to the kill switch
+func (k *KillSwitch) Setup(store service.Store, sessions map[string]*ExchangeSession, strategies []interface{}) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.store = store
+	k.sessions = sessions
+	k.strategies = strategies
+	k.checkC = make(chan struct{}, 1)
+
+	if store != nil {
+		if err := store.Load(&k.state); err != nil && err != service.ErrPersistenceNotExists {
+			return errors.Wrap(err, "failed to load the kill switch state")
+		}
+	}
+
+	for _, session := range sessions {
+		session.killSwitch = k
+	}
+
+	k.updateMetrics()
+	return nil
+}
+
+// Start halts the strategies if the kill switch was tripped before the restart, and runs the equity check in the background
+func (k *KillSwitch) Start(ctx context.Context) {
+	if state := k.State(); state.Tripped {
+		log.Warnf("kill switch was tripped at %s: %s, the strategies are suspended until it's reset", state.TrippedAt, state.Reason)
+		Notify(":rotating_light: kill switch was tripped at %s: %s, the strategies are suspended until it's reset", state.TrippedAt.Format(time.RFC3339), state.Reason)
+		k.halt(ctx, false)
+	}
+
+	go k.run(ctx)
+}
+
+func (k *KillSwitch) run(ctx context.Context) {
+	ticker := time.NewTicker(k.checkInterval())
+	defer ticker.Stop()
+
+	k.check(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-k.checkC:
+			k.check(ctx, time.Now())
+
+		case now := <-ticker.C:
+			k.check(ctx, now)
+		}
+	}
+}
+
+// State returns a copy of the kill switch state
+func (k *KillSwitch) State() KillSwitchState {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.copyState()
+}
+
+func (k *KillSwitch) copyState() KillSwitchState {
+	state := k.state
+	state.EquitySamples = append([]EquitySample(nil), k.state.EquitySamples...)
+	return state
+}
+
+// Tripped returns true if the kill switch is tripped
+func (k *KillSwitch) Tripped() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.state.Tripped
+}
+
+// Reset resets the tripped state, the suspended strategies are not resumed automatically
+func (k *KillSwitch) Reset() error {
+	k.mu.Lock()
+	k.state = KillSwitchState{}
+	k.updateMetrics()
+	k.mu.Unlock()
+
+	err := k.save()
+
+	log.Infof("kill switch is reset")
+	Notify(":white_check_mark: kill switch is reset")
+	return err
+}
+
+// AddProfit adds the realized profit of a trade to the daily realized profit,
+// the state is saved by the check triggered after the profit is added.
+func (k *KillSwitch) AddProfit(profit types.Profit) {
+	netProfit, ok := k.inUSD(profit.QuoteCurrency, profit.NetProfit)
+	if !ok {
+		log.Warnf("kill switch: can not convert the profit %s %s to USD", profit.NetProfit.String(), profit.QuoteCurrency)
+		return
+	}
+
+	k.mu.Lock()
+	k.rollDay(time.Now())
+	k.state.DailyRealizedProfit = k.state.DailyRealizedProfit.Add(netProfit)
+	k.mu.Unlock()
+
+	// trigger the check without blocking the trade collector
+	select {
+	case k.checkC <- struct{}{}:
+	default:
+	}
+}
+
+// CheckOrders rejects the orders that increase the position when the kill switch is tripped
+func (k *KillSwitch) CheckOrders(session *ExchangeSession, position *types.Position, orders ...types.SubmitOrder) (accepted []types.SubmitOrder, rejections []*RiskRejection) {
+	if !k.Tripped() {
+		return orders, nil
+	}
+
+	for _, order := range orders {
+		base := fixedpoint.Zero
+		if position != nil && position.Symbol == order.Symbol {
+			base = position.GetBase()
+		} else if p, ok := session.Position(order.Symbol); ok {
+			base = p.GetBase()
+		}
+
+		// with zero limit, only the orders that reduce the position are allowed
+		if _, exceeded := exceedsPositionLimit(base, order, accepted, fixedpoint.Zero); exceeded {
+			rejections = append(rejections, &RiskRejection{
+				Session: session.Name,
+				Order:   order,
+				Err:     ErrRiskKillSwitchTripped,
+				Reason:  "only the orders that reduce the position are allowed",
+			})
+			continue
+		}
+
+		accepted = append(accepted, order)
+	}
+
+	return accepted, rejections
+}
+
+func (k *KillSwitch) check(ctx context.Context, now time.Time) {
+	equity, hasEquity := k.equity(now)
+	reason := k.update(equity, hasEquity, now)
+
+	if err := k.save(); err != nil {
+		log.WithError(err).Error("failed to save the kill switch state")
+	}
+
+	if reason == "" {
+		return
+	}
+
+	log.Errorf("kill switch is tripped: %s", reason)
+	Notify(":rotating_light: kill switch is tripped: %s", reason)
+	k.halt(ctx, true)
+}
+
+// update updates the daily counters and the equity samples, and returns the reason if the kill switch is just tripped
+func (k *KillSwitch) update(equity fixedpoint.Value, hasEquity bool, now time.Time) string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.state.Tripped {
+		return ""
+	}
+
+	k.rollDay(now)
+	if hasEquity {
+		k.addEquity(equity, now)
+	}
+
+	reason := k.breach(equity, hasEquity)
+	if reason == "" {
+		return ""
+	}
+
+	k.state.Tripped = true
+	k.state.TrippedAt = now
+	k.state.Reason = reason
+	k.updateMetrics()
+	return reason
+}
+
+// rollDay resets the daily counters when the UTC date changes
+func (k *KillSwitch) rollDay(now time.Time) {
+	date := now.UTC().Format("2006-01-02")
+	if k.state.Date == date {
+		return
+	}
+
+	k.state.Date = date
+	k.state.DayStartEquity = fixedpoint.Zero
+	k.state.DailyRealizedProfit = fixedpoint.Zero
+}
+
+func (k *KillSwitch) addEquity(equity fixedpoint.Value, now time.Time) {
+	if k.state.DayStartEquity.IsZero() {
+		k.state.DayStartEquity = equity
+	}
+
+	since := now.Add(-k.drawdownWindow())
+	samples := k.state.EquitySamples
+	for len(samples) > 0 && samples[0].Time.Before(since) {
+		samples = samples[1:]
+	}
+
+	// the samples lower than the new sample can't be the peak of the window anymore
+	for len(samples) > 0 && samples[len(samples)-1].Equity.Compare(equity) <= 0 {
+		samples = samples[:len(samples)-1]
+	}
+
+	k.state.EquitySamples = append(samples, EquitySample{Time: now, Equity: equity})
+
+	if viper.GetBool("metrics") {
+		metricsKillSwitchEquity.Set(equity.Float64())
+	}
+}
+
+// breach returns the reason if any of the limits is breached
+func (k *KillSwitch) breach(equity fixedpoint.Value, hasEquity bool) string {
+	if k.DailyLossLimit.Sign() > 0 {
+		if loss := k.state.DailyRealizedProfit.Neg(); loss.Compare(k.DailyLossLimit) >= 0 {
+			return fmt.Sprintf("daily realized loss %s USD reached the daily loss limit %s USD", loss.String(), k.DailyLossLimit.String())
+		}
+
+		if hasEquity && k.state.DayStartEquity.Sign() > 0 {
+			if loss := k.state.DayStartEquity.Sub(equity); loss.Compare(k.DailyLossLimit) >= 0 {
+				return fmt.Sprintf("daily equity loss %s USD (from %s to %s) reached the daily loss limit %s USD",
+					loss.String(), k.state.DayStartEquity.String(), equity.String(), k.DailyLossLimit.String())
+			}
+		}
+	}
+
+	if k.MaxDrawdown.Sign() > 0 && hasEquity {
+		peak := fixedpoint.Zero
+		for _, sample := range k.state.EquitySamples {
+			peak = fixedpoint.Max(peak, sample.Equity)
+		}
+
+		if peak.Sign() > 0 {
+			if drawdown := peak.Sub(equity).Div(peak); drawdown.Compare(k.MaxDrawdown) >= 0 {
+				return fmt.Sprintf("equity drawdown %s (from %s to %s) reached the max drawdown %s in %s",
+					drawdown.Percentage(), peak.String(), equity.String(), k.MaxDrawdown.Percentage(), k.drawdownWindow())
+			}
+		}
+	}
+
+	return ""
+}
+
+// halt suspends the strategies and cancels the open orders, the emergency stop and the position flattening are only
+// executed when the kill switch is just tripped
+func (k *KillSwitch) halt(ctx context.Context, tripped bool) {
+	for _, strategy := range k.strategies {
+		if toggler, ok := strategy.(StrategyToggler); ok && toggler.GetStatus() == types.StrategyStatusRunning {
+			if err := toggler.Suspend(); err != nil {
+				log.WithError(err).Errorf("kill switch: failed to suspend the strategy %T", strategy)
+			}
+		}
+
+		if !tripped {
+			continue
+		}
+
+		if stopper, ok := strategy.(EmergencyStopper); ok {
+			if err := stopper.EmergencyStop(); err != nil {
+				log.WithError(err).Errorf("kill switch: failed to emergency stop the strategy %T", strategy)
+			}
+		}
+	}
+
+	for _, session := range k.sessions {
+		if session.PublicOnly {
+			continue
+		}
+
+		if err := k.cancelOpenOrders(ctx, session); err != nil {
+			log.WithError(err).Errorf("kill switch: failed to cancel the open orders of session %s", session.Name)
+			Notify("kill switch: failed to cancel the open orders of session %s: %v", session.Name, err)
+		}
+
+		if tripped && k.FlattenPositions {
+			if err := k.flattenPositions(ctx, session); err != nil {
+				log.WithError(err).Errorf("kill switch: failed to flatten the positions of session %s", session.Name)
+				Notify("kill switch: failed to flatten the positions of session %s: %v", session.Name, err)
+			}
+		}
+	}
+}
+
+// symbols returns the symbols traded by the session
+func (k *KillSwitch) symbols(session *ExchangeSession) map[string]struct{} {
+	symbols := make(map[string]struct{})
+	for symbol := range session.OrderStores() {
+		symbols[symbol] = struct{}{}
+	}
+
+	for symbol := range session.Positions() {
+		symbols[symbol] = struct{}{}
+	}
+
+	return symbols
+}
+
+func (k *KillSwitch) cancelOpenOrders(ctx context.Context, session *ExchangeSession) error {
+	var errs []error
+	for symbol := range k.symbols(session) {
+		openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if len(openOrders) == 0 {
+			continue
+		}
+
+		log.Infof("kill switch: canceling %d open orders of %s on session %s", len(openOrders), symbol, session.Name)
+		if err := session.Exchange.CancelOrders(ctx, openOrders...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d errors: %v", len(errs), errs)
+	}
+
+	return nil
+}
+
+func (k *KillSwitch) flattenPositions(ctx context.Context, session *ExchangeSession) error {
+	var errs []error
+	for symbol, position := range session.Positions() {
+		base := position.GetBase()
+		if base.IsZero() {
+			continue
+		}
+
+		market, ok := session.Market(symbol)
+		if !ok {
+			continue
+		}
+
+		price, _ := session.LastPrice(symbol)
+		quantity := base.Abs()
+		if market.IsDustQuantity(quantity, price) {
+			continue
+		}
+
+		order := types.SubmitOrder{
+			Symbol:   symbol,
+			Market:   market,
+			Type:     types.OrderTypeMarket,
+			Side:     types.SideTypeSell,
+			Quantity: quantity,
+		}
+
+		if base.Sign() < 0 {
+			order.Side = types.SideTypeBuy
+		}
+
+		if session.Futures {
+			order.ReduceOnly = true
+		} else if order.Side == types.SideTypeSell {
+			// the session position is built from the trades, don't sell more than the balance
+			if balance, ok := session.GetAccount().Balance(market.BaseCurrency); ok {
+				order.Quantity = fixedpoint.Min(order.Quantity, balance.Available)
+			}
+
+			if market.IsDustQuantity(order.Quantity, price) {
+				continue
+			}
+		}
+
+		formattedOrder, err := session.FormatOrder(order)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Infof("kill switch: flattening the %s position %s on session %s", symbol, base.String(), session.Name)
+		if _, err := session.Exchange.SubmitOrders(ctx, formattedOrder); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d errors: %v", len(errs), errs)
+	}
+
+	return nil
+}
+
+// equity returns the mark-to-market equity in USD of all the sessions, the assets without the USD prices are skipped
+func (k *KillSwitch) equity(now time.Time) (fixedpoint.Value, bool) {
+	balances := types.BalanceMap{}
+	prices := k.prices()
+	for _, session := range k.sessions {
+		if session.PublicOnly {
+			continue
+		}
+
+		account := session.GetAccount()
+		if account == nil {
+			continue
+		}
+
+		balances = balances.Add(account.Balances())
+	}
+
+	if len(balances) == 0 {
+		return fixedpoint.Zero, false
+	}
+
+	equity := fixedpoint.Zero
+	for _, asset := range balances.Assets(prices, now) {
+		equity = equity.Add(asset.InUSD)
+	}
+
+	return equity, true
+}
+
+func (k *KillSwitch) prices() map[string]fixedpoint.Value {
+	prices := make(map[string]fixedpoint.Value)
+	for _, session := range k.sessions {
+		for symbol, price := range session.LastPrices() {
+			prices[symbol] = price
+		}
+	}
+
+	return prices
+}
+
+func (k *KillSwitch) inUSD(currency string, amount fixedpoint.Value) (fixedpoint.Value, bool) {
+	if strings.HasPrefix(currency, "USD") {
+		return amount, true
+	}
+
+	prices := k.prices()
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if price, ok := prices[currency+quote]; ok {
+			return amount.Mul(price), true
+		}
+	}
+
+	return fixedpoint.Zero, false
+}
+
+// save writes a copy of the state to the store, it must be called without holding mu.
+// The copy is taken under saveMu so that a newer state is never overwritten by an older one.
+func (k *KillSwitch) save() error {
+	k.saveMu.Lock()
+	defer k.saveMu.Unlock()
+
+	k.mu.Lock()
+	store := k.store
+	state := k.copyState()
+	k.mu.Unlock()
+
+	if store == nil {
+		return nil
+	}
+
+	return store.Save(state)
+}
+
+func (k *KillSwitch) updateMetrics() {
+	if !viper.GetBool("metrics") {
+		return
+	}
+
+	if k.state.Tripped {
+		metricsKillSwitchTripped.Set(1)
+	} else {
+		metricsKillSwitchTripped.Set(0)
+	}
+}
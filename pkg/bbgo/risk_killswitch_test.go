@@ -0,0 +1,171 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type killSwitchTestStrategy struct {
+	*StrategyController
+
+	emergencyStopped bool
+}
+
+func (s *killSwitchTestStrategy) ID() string {
+	return "test"
+}
+
+func (s *killSwitchTestStrategy) EmergencyStop() error {
+	s.emergencyStopped = true
+	return s.StrategyController.EmergencyStop()
+}
+
+func TestKillSwitch_DailyLossLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+	})
+
+	strategy := &killSwitchTestStrategy{StrategyController: &StrategyController{Status: types.StrategyStatusRunning}}
+	persistence := &service.JsonPersistenceService{Directory: t.TempDir()}
+	sessions := map[string]*ExchangeSession{session.Name: session}
+
+	killSwitch := &KillSwitch{DailyLossLimit: fixedpoint.NewFromFloat(1000.0)}
+	assert.NoError(t, killSwitch.Setup(persistence.NewStore(killSwitchStoreID), sessions, []interface{}{strategy}))
+
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	killSwitch.check(ctx, now)
+
+	state := killSwitch.State()
+	assert.False(t, state.Tripped)
+	assert.Equal(t, "2022-06-01", state.Date)
+	assert.Equal(t, fixedpoint.NewFromFloat(30000.0), state.DayStartEquity)
+
+	// the realized loss is counted in USD
+	killSwitch.AddProfit(types.Profit{QuoteCurrency: "USDT", NetProfit: fixedpoint.NewFromFloat(-400.0)})
+	killSwitch.check(ctx, now.Add(time.Minute))
+	assert.False(t, killSwitch.Tripped())
+
+	// the equity loss since the start of the day reaches the limit
+	mockEx.EXPECT().QueryOpenOrders(gomock.Any(), "BTCUSDT").Return(nil, nil)
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromFloat(19000.0)
+	killSwitch.check(ctx, now.Add(2*time.Minute))
+
+	state = killSwitch.State()
+	assert.True(t, state.Tripped)
+	assert.Contains(t, state.Reason, "daily equity loss")
+	assert.Equal(t, types.StrategyStatusStopped, strategy.GetStatus())
+	assert.True(t, strategy.emergencyStopped)
+
+	// only the orders that reduce the position are allowed after the kill switch is tripped
+	position := types.NewPositionFromMarket(getTestMarket())
+	position.Base = fixedpoint.NewFromFloat(0.5)
+	accepted, err := session.checkPreTradeRisk("test", position, []types.SubmitOrder{
+		limitOrder(types.SideTypeBuy, 0.1, 19000.0),
+		limitOrder(types.SideTypeSell, 0.5, 19000.0),
+	})
+	assert.True(t, errors.Is(err, ErrRiskKillSwitchTripped))
+	if assert.Len(t, accepted, 1) {
+		assert.Equal(t, types.SideTypeSell, accepted[0].Side)
+	}
+
+	// the tripped state is loaded after the restart
+	restarted := &KillSwitch{DailyLossLimit: fixedpoint.NewFromFloat(1000.0)}
+	assert.NoError(t, restarted.Setup(persistence.NewStore(killSwitchStoreID), sessions, nil))
+	assert.True(t, restarted.Tripped())
+	assert.Equal(t, state.Reason, restarted.State().Reason)
+
+	assert.NoError(t, restarted.Reset())
+	assert.False(t, restarted.Tripped())
+
+	restarted = &KillSwitch{DailyLossLimit: fixedpoint.NewFromFloat(1000.0)}
+	assert.NoError(t, restarted.Setup(persistence.NewStore(killSwitchStoreID), sessions, nil))
+	assert.False(t, restarted.Tripped())
+}
+
+func TestKillSwitch_breach(t *testing.T) {
+	killSwitch := &KillSwitch{
+		DailyLossLimit: fixedpoint.NewFromFloat(1000.0),
+		MaxDrawdown:    fixedpoint.NewFromFloat(0.1),
+		DrawdownWindow: types.Duration(time.Hour),
+	}
+
+	now := time.Date(2022, 6, 1, 23, 0, 0, 0, time.UTC)
+	killSwitch.rollDay(now)
+	killSwitch.state.DailyRealizedProfit = fixedpoint.NewFromFloat(-999.0)
+	assert.Empty(t, killSwitch.breach(fixedpoint.Zero, false))
+
+	killSwitch.state.DailyRealizedProfit = fixedpoint.NewFromFloat(-1000.0)
+	assert.Contains(t, killSwitch.breach(fixedpoint.Zero, false), "daily realized loss")
+
+	// the daily counters are reset on the next day
+	killSwitch.rollDay(now.Add(2 * time.Hour))
+	assert.True(t, killSwitch.state.DailyRealizedProfit.IsZero())
+
+	now = now.Add(2 * time.Hour)
+	killSwitch.addEquity(fixedpoint.NewFromFloat(20000.0), now)
+	killSwitch.addEquity(fixedpoint.NewFromFloat(25000.0), now.Add(30*time.Minute))
+	assert.Empty(t, killSwitch.breach(fixedpoint.NewFromFloat(25000.0), true))
+
+	// 12% drawdown from the peak 25000, the daily equity loss is still under the limit
+	killSwitch.addEquity(fixedpoint.NewFromFloat(22000.0), now.Add(45*time.Minute))
+	assert.Contains(t, killSwitch.breach(fixedpoint.NewFromFloat(22000.0), true), "equity drawdown")
+
+	// the sample 20000 is dropped since it can't be the peak anymore
+	if assert.Len(t, killSwitch.state.EquitySamples, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(25000.0), killSwitch.state.EquitySamples[0].Equity)
+	}
+
+	// the peak is dropped out of the rolling window
+	killSwitch.addEquity(fixedpoint.NewFromFloat(22000.0), now.Add(100*time.Minute))
+	assert.Empty(t, killSwitch.breach(fixedpoint.NewFromFloat(22000.0), true))
+}
+
+func TestKillSwitch_MaxDrawdownRestart(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+	})
+
+	persistence := &service.JsonPersistenceService{Directory: t.TempDir()}
+	sessions := map[string]*ExchangeSession{session.Name: session}
+
+	killSwitch := &KillSwitch{MaxDrawdown: fixedpoint.NewFromFloat(0.1)}
+	assert.NoError(t, killSwitch.Setup(persistence.NewStore(killSwitchStoreID), sessions, nil))
+
+	ctx := context.Background()
+	now := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	killSwitch.check(ctx, now)
+	assert.False(t, killSwitch.Tripped())
+
+	// the peak equity 30000 is loaded after the restart, the 10% drawdown trips the kill switch
+	restarted := &KillSwitch{MaxDrawdown: fixedpoint.NewFromFloat(0.1)}
+	assert.NoError(t, restarted.Setup(persistence.NewStore(killSwitchStoreID), sessions, nil))
+	if assert.Len(t, restarted.State().EquitySamples, 1) {
+		assert.Equal(t, fixedpoint.NewFromFloat(30000.0), restarted.State().EquitySamples[0].Equity)
+	}
+
+	mockEx.EXPECT().QueryOpenOrders(gomock.Any(), "BTCUSDT").Return(nil, nil)
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromFloat(17000.0)
+	restarted.check(ctx, now.Add(time.Minute))
+	assert.True(t, restarted.Tripped())
+	assert.Contains(t, restarted.State().Reason, "equity drawdown")
+}
@@ -0,0 +1,465 @@
+package bbgo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var (
+	ErrRiskOrderNotionalTooLarge         = errors.New("order notional is too large")
+	ErrRiskPositionLimitExceeded         = errors.New("position limit exceeded")
+	ErrRiskStrategyPositionLimitExceeded = errors.New("strategy position limit exceeded")
+	ErrRiskOpenOrdersLimitExceeded       = errors.New("open orders limit exceeded")
+	ErrRiskPriceOutOfCollar              = errors.New("order price is out of the price collar")
+	ErrRiskOrderRateLimitExceeded        = errors.New("order rate limit exceeded")
+)
+
+// RiskRejection is returned when a submit order is rejected by the pre-trade risk checks,
+// errors.Is can be used with the ErrRisk* errors to find out which rule rejects the order.
+type RiskRejection struct {
+	Session string
+	Order   types.SubmitOrder
+	Err     error
+	Reason  string
+}
+
+func (r *RiskRejection) Error() string {
+	return fmt.Sprintf("risk control rejected %s %s %s order (quantity %s, price %s) of session %s: %s: %s",
+		r.Order.Symbol, r.Order.Type, r.Order.Side, r.Order.Quantity.String(), r.Order.Price.String(), r.Session, r.Err.Error(), r.Reason)
+}
+
+func (r *RiskRejection) Unwrap() error {
+	return r.Err
+}
+
+func newRiskRejection(err error, reason string, args ...interface{}) *RiskRejection {
+	return &RiskRejection{Err: err, Reason: fmt.Sprintf(reason, args...)}
+}
+
+// PreTradeRiskRules are the pre-trade risk rules of a symbol, the zero values disable the rules
+type PreTradeRiskRules struct {
+	// MaxOrderNotional is the max quote amount of an order, the last price is used for the market orders
+	MaxOrderNotional fixedpoint.Value `json:"maxOrderNotional,omitempty" yaml:"maxOrderNotional,omitempty"`
+
+	// MaxPosition is the max absolute base position of the symbol in the session,
+	// the orders that reduce the position are always allowed.
+	MaxPosition fixedpoint.Value `json:"maxPosition,omitempty" yaml:"maxPosition,omitempty"`
+
+	// MaxStrategyPosition is the max absolute base position of a strategy, it's checked for the orders of the strategy order executors
+	MaxStrategyPosition fixedpoint.Value `json:"maxStrategyPosition,omitempty" yaml:"maxStrategyPosition,omitempty"`
+
+	// MaxOpenOrders is the max number of the open orders of the symbol in the session
+	MaxOpenOrders int `json:"maxOpenOrders,omitempty" yaml:"maxOpenOrders,omitempty"`
+
+	// PriceCollar is the max deviation ratio of the order price from the last price, e.g., 0.05 for 5%
+	PriceCollar fixedpoint.Value `json:"priceCollar,omitempty" yaml:"priceCollar,omitempty"`
+
+	// MaxOrderRate is the max number of the submitted orders of the symbol per minute
+	MaxOrderRate int `json:"maxOrderRate,omitempty" yaml:"maxOrderRate,omitempty"`
+}
+
+// merge returns the rules with the zero fields filled by the default rules
+func (r PreTradeRiskRules) merge(defaults PreTradeRiskRules) PreTradeRiskRules {
+	if r.MaxOrderNotional.IsZero() {
+		r.MaxOrderNotional = defaults.MaxOrderNotional
+	}
+	if r.MaxPosition.IsZero() {
+		r.MaxPosition = defaults.MaxPosition
+	}
+	if r.MaxStrategyPosition.IsZero() {
+		r.MaxStrategyPosition = defaults.MaxStrategyPosition
+	}
+	if r.MaxOpenOrders == 0 {
+		r.MaxOpenOrders = defaults.MaxOpenOrders
+	}
+	if r.PriceCollar.IsZero() {
+		r.PriceCollar = defaults.PriceCollar
+	}
+	if r.MaxOrderRate == 0 {
+		r.MaxOrderRate = defaults.MaxOrderRate
+	}
+	return r
+}
+
+// PreTradeRiskContext is the context of the risk check of an order
+type PreTradeRiskContext struct {
+	Session *ExchangeSession
+	Rules   PreTradeRiskRules
+
+	// Strategy is the strategy instance id of the order executor, it's empty for the session order executor
+	Strategy string
+
+	// Position is the position of the strategy order executor, it's nil for the session order executor
+	Position *types.Position
+
+	// Accepted is the accepted orders of the same batch before the order
+	Accepted []types.SubmitOrder
+
+	// Replacing is the open orders being replaced by the orders, they are not counted as the open orders
+	Replacing []types.Order
+
+	Now time.Time
+}
+
+// PreTradeRiskCheck checks a submit order before it's sent to the exchange, an error rejects the order
+type PreTradeRiskCheck interface {
+	CheckOrder(ctx *PreTradeRiskContext, order types.SubmitOrder) error
+}
+
+// PreTradeRiskCheckFunc is the function adapter of PreTradeRiskCheck
+type PreTradeRiskCheckFunc func(ctx *PreTradeRiskContext, order types.SubmitOrder) error
+
+func (f PreTradeRiskCheckFunc) CheckOrder(ctx *PreTradeRiskContext, order types.SubmitOrder) error {
+	return f(ctx, order)
+}
+
+// PreTradeRiskControl is the pre-trade risk pipeline of a session, all the order executors of the session
+// check the orders through it before the orders are submitted.
+type PreTradeRiskControl struct {
+	// the default rules of all the symbols
+	PreTradeRiskRules `json:",inline" yaml:",inline"`
+
+	// BySymbol overrides the default rules of the symbol
+	BySymbol map[string]*PreTradeRiskRules `json:"bySymbol,omitempty" yaml:"bySymbol,omitempty"`
+
+	mu     sync.Mutex
+	checks []PreTradeRiskCheck
+
+	// orderTimes is the submission times of the submitted orders in the last minute, map: symbol -> times
+	orderTimes map[string][]time.Time
+}
+
+// AddCheck adds a custom check to the pipeline, the custom checks run after the built-in rules
+func (c *PreTradeRiskControl) AddCheck(check PreTradeRiskCheck) {
+	c.mu.Lock()
+	c.checks = append(c.checks, check)
+	c.mu.Unlock()
+}
+
+func (c *PreTradeRiskControl) rules(symbol string) PreTradeRiskRules {
+	if rules, ok := c.BySymbol[symbol]; ok && rules != nil {
+		return rules.merge(c.PreTradeRiskRules)
+	}
+
+	return c.PreTradeRiskRules
+}
+
+// CheckOrders runs the risk checks of the orders, the accepted orders and the rejections are returned
+func (c *PreTradeRiskControl) CheckOrders(session *ExchangeSession, strategy string, position *types.Position, orders ...types.SubmitOrder) (accepted []types.SubmitOrder, rejections []*RiskRejection) {
+	return c.checkOrders(session, strategy, position, nil, orders)
+}
+
+func (c *PreTradeRiskControl) checkOrders(session *ExchangeSession, strategy string, position *types.Position, replacing []types.Order, orders []types.SubmitOrder) (accepted []types.SubmitOrder, rejections []*RiskRejection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, order := range orders {
+		ctx := &PreTradeRiskContext{
+			Session:   session,
+			Rules:     c.rules(order.Symbol),
+			Strategy:  strategy,
+			Position:  position,
+			Accepted:  accepted,
+			Replacing: replacing,
+			Now:       now,
+		}
+
+		if rejection := c.checkOrder(ctx, order); rejection != nil {
+			rejection.Session = session.Name
+			rejection.Order = order
+			rejections = append(rejections, rejection)
+			continue
+		}
+
+		accepted = append(accepted, order)
+	}
+
+	return accepted, rejections
+}
+
+func (c *PreTradeRiskControl) checkOrder(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection {
+	builtInChecks := []func(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection{
+		checkOrderNotional,
+		checkPriceCollar,
+		checkPosition,
+		checkStrategyPosition,
+		checkOpenOrders,
+		c.checkOrderRate,
+	}
+
+	for _, check := range builtInChecks {
+		if rejection := check(ctx, order); rejection != nil {
+			return rejection
+		}
+	}
+
+	for _, check := range c.checks {
+		if err := check.CheckOrder(ctx, order); err != nil {
+			if rejection, ok := err.(*RiskRejection); ok {
+				return rejection
+			}
+
+			return &RiskRejection{Err: err, Reason: "custom risk check"}
+		}
+	}
+
+	return nil
+}
+
+// RecordOrders counts the orders submitted to the exchange in the order rate limit,
+// the accepted orders are not counted until they are submitted successfully.
+func (c *PreTradeRiskControl) RecordOrders(orders ...types.Order) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.orderTimes == nil {
+		c.orderTimes = make(map[string][]time.Time)
+	}
+
+	now := time.Now()
+	for _, order := range orders {
+		c.orderTimes[order.Symbol] = append(c.orderTimes[order.Symbol], now)
+	}
+}
+
+func (c *PreTradeRiskControl) checkOrderRate(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection {
+	if ctx.Rules.MaxOrderRate <= 0 {
+		return nil
+	}
+
+	// drop the submission times before the one-minute window
+	since := ctx.Now.Add(-time.Minute)
+	times := c.orderTimes[order.Symbol]
+	for len(times) > 0 && !times[0].After(since) {
+		times = times[1:]
+	}
+
+	if c.orderTimes != nil {
+		c.orderTimes[order.Symbol] = times
+	}
+
+	numOfOrders := len(times)
+	for _, accepted := range ctx.Accepted {
+		if accepted.Symbol == order.Symbol {
+			numOfOrders++
+		}
+	}
+
+	if numOfOrders >= ctx.Rules.MaxOrderRate {
+		return newRiskRejection(ErrRiskOrderRateLimitExceeded, "%d orders are submitted in the last minute, max rate %d", numOfOrders, ctx.Rules.MaxOrderRate)
+	}
+
+	return nil
+}
+
+// orderPrice returns the price of the order, the last price is used for the market orders
+func orderPrice(session *ExchangeSession, order types.SubmitOrder) (fixedpoint.Value, bool) {
+	switch order.Type {
+	case types.OrderTypeMarket, types.OrderTypeStopMarket:
+		return session.LastPrice(order.Symbol)
+	}
+
+	if order.Price.IsZero() {
+		return session.LastPrice(order.Symbol)
+	}
+
+	return order.Price, true
+}
+
+func checkOrderNotional(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection {
+	if ctx.Rules.MaxOrderNotional.IsZero() {
+		return nil
+	}
+
+	price, ok := orderPrice(ctx.Session, order)
+	if !ok {
+		return newRiskRejection(ErrRiskOrderNotionalTooLarge, "the last price of %s is not found", order.Symbol)
+	}
+
+	notional := order.Quantity.Mul(price)
+	if notional.Compare(ctx.Rules.MaxOrderNotional) > 0 {
+		return newRiskRejection(ErrRiskOrderNotionalTooLarge, "notional %s > max order notional %s", notional.String(), ctx.Rules.MaxOrderNotional.String())
+	}
+
+	return nil
+}
+
+func checkPriceCollar(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection {
+	if ctx.Rules.PriceCollar.IsZero() || order.Price.IsZero() {
+		return nil
+	}
+
+	switch order.Type {
+	case types.OrderTypeMarket, types.OrderTypeStopMarket:
+		return nil
+	}
+
+	lastPrice, ok := ctx.Session.LastPrice(order.Symbol)
+	if !ok || lastPrice.IsZero() {
+		return newRiskRejection(ErrRiskPriceOutOfCollar, "the last price of %s is not found", order.Symbol)
+	}
+
+	deviation := order.Price.Sub(lastPrice).Abs().Div(lastPrice)
+	if deviation.Compare(ctx.Rules.PriceCollar) > 0 {
+		return newRiskRejection(ErrRiskPriceOutOfCollar, "price %s deviates %s from the last price %s, collar %s",
+			order.Price.String(), deviation.Percentage(), lastPrice.String(), ctx.Rules.PriceCollar.Percentage())
+	}
+
+	return nil
+}
+
+// positionAfter returns the base position after the order and the accepted orders of the same batch are filled
+func positionAfter(base fixedpoint.Value, order types.SubmitOrder, accepted []types.SubmitOrder) (before, after fixedpoint.Value) {
+	for _, o := range accepted {
+		if o.Symbol == order.Symbol {
+			base = addOrderQuantity(base, o)
+		}
+	}
+
+	return base, addOrderQuantity(base, order)
+}
+
+func addOrderQuantity(base fixedpoint.Value, order types.SubmitOrder) fixedpoint.Value {
+	switch order.Side {
+	case types.SideTypeBuy:
+		return base.Add(order.Quantity)
+	case types.SideTypeSell:
+		return base.Sub(order.Quantity)
+	}
+
+	return base
+}
+
+func exceedsPositionLimit(base fixedpoint.Value, order types.SubmitOrder, accepted []types.SubmitOrder, limit fixedpoint.Value) (fixedpoint.Value, bool) {
+	before, after := positionAfter(base, order, accepted)
+
+	// the orders that reduce the position are always allowed
+	if after.Abs().Compare(before.Abs()) <= 0 {
+		return after, false
+	}
+
+	return after, after.Abs().Compare(limit) > 0
+}
+
+func checkPosition(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection {
+	if ctx.Rules.MaxPosition.IsZero() {
+		return nil
+	}
+
+	position, ok := ctx.Session.Position(order.Symbol)
+	if !ok {
+		return nil
+	}
+
+	if after, exceeded := exceedsPositionLimit(position.GetBase(), order, ctx.Accepted, ctx.Rules.MaxPosition); exceeded {
+		return newRiskRejection(ErrRiskPositionLimitExceeded, "session position %s would be %s, max position %s",
+			order.Symbol, after.String(), ctx.Rules.MaxPosition.String())
+	}
+
+	return nil
+}
+
+func checkStrategyPosition(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection {
+	if ctx.Rules.MaxStrategyPosition.IsZero() || ctx.Position == nil || ctx.Position.Symbol != order.Symbol {
+		return nil
+	}
+
+	if after, exceeded := exceedsPositionLimit(ctx.Position.GetBase(), order, ctx.Accepted, ctx.Rules.MaxStrategyPosition); exceeded {
+		return newRiskRejection(ErrRiskStrategyPositionLimitExceeded, "strategy %s position %s would be %s, max strategy position %s",
+			ctx.Strategy, order.Symbol, after.String(), ctx.Rules.MaxStrategyPosition.String())
+	}
+
+	return nil
+}
+
+func checkOpenOrders(ctx *PreTradeRiskContext, order types.SubmitOrder) *RiskRejection {
+	if ctx.Rules.MaxOpenOrders <= 0 {
+		return nil
+	}
+
+	numOfOpenOrders := 0
+	if store, ok := ctx.Session.OrderStore(order.Symbol); ok {
+		for _, o := range store.Orders() {
+			switch o.Status {
+			case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+				numOfOpenOrders++
+			}
+		}
+	}
+
+	for _, replacing := range ctx.Replacing {
+		if replacing.Symbol == order.Symbol {
+			numOfOpenOrders--
+		}
+	}
+
+	for _, accepted := range ctx.Accepted {
+		if accepted.Symbol == order.Symbol {
+			numOfOpenOrders++
+		}
+	}
+
+	if numOfOpenOrders >= ctx.Rules.MaxOpenOrders {
+		return newRiskRejection(ErrRiskOpenOrdersLimitExceeded, "%d open orders of %s, max open orders %d", numOfOpenOrders, order.Symbol, ctx.Rules.MaxOpenOrders)
+	}
+
+	return nil
+}
+
+// checkPreTradeRisk runs the pre-trade risk control of the session, the rejections are notified and combined into the returned error.
+// The accepted orders should still be submitted when the error is not nil.
+// The replacing orders are the open orders that will be replaced by the orders.
+func (session *ExchangeSession) checkPreTradeRisk(strategy string, position *types.Position, orders []types.SubmitOrder, replacing ...types.Order) ([]types.SubmitOrder, error) {
+	var rejections []*RiskRejection
+
+	accepted := orders
+	if session.killSwitch != nil {
+		accepted, rejections = session.killSwitch.CheckOrders(session, position, accepted...)
+	}
+
+	if manager := session.exposureManager; manager != nil && len(accepted) > 0 {
+		var exposureRejections []*RiskRejection
+		accepted, exposureRejections = manager.checkOrders(session, replacing, accepted)
+		rejections = append(rejections, exposureRejections...)
+	}
+
+	if control := session.preTradeRiskControl; control != nil && len(accepted) > 0 {
+		var controlRejections []*RiskRejection
+		accepted, controlRejections = control.checkOrders(session, strategy, position, replacing, accepted)
+		rejections = append(rejections, controlRejections...)
+	}
+
+	var err error
+	for _, rejection := range rejections {
+		session.logger.Warn(rejection.Error())
+		Notify(":no_entry: %s", rejection.Error())
+		err = multierr.Append(err, rejection)
+	}
+
+	return accepted, err
+}
+
+// recordSubmittedOrders counts the submitted orders in the order rate limit of the pre-trade risk control
+func (session *ExchangeSession) recordSubmittedOrders(orders ...types.Order) {
+	if control := session.preTradeRiskControl; control != nil && len(orders) > 0 {
+		control.RecordOrders(orders...)
+	}
+}
+
+// SetPreTradeRiskControl sets the pre-trade risk pipeline of the order executors of the session
+func (session *ExchangeSession) SetPreTradeRiskControl(control *PreTradeRiskControl) {
+	session.preTradeRiskControl = control
+}
+
+// PreTradeRiskControl returns the pre-trade risk pipeline of the session, it's nil if the pre-trade risk control is not configured
+func (session *ExchangeSession) PreTradeRiskControl() *PreTradeRiskControl {
+	return session.preTradeRiskControl
+}
@@ -0,0 +1,212 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func newPreTradeRiskTestSession(mockCtrl *gomock.Controller) (*ExchangeSession, *mocks.MockExchange) {
+	market := getTestMarket()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("test", mockEx)
+	session.markets[market.Symbol] = market
+	session.lastPrices[market.Symbol] = fixedpoint.NewFromFloat(20000.0)
+	session.positions[market.Symbol] = types.NewPositionFromMarket(market)
+	session.orderStores[market.Symbol] = NewOrderStore(market.Symbol)
+	return session, mockEx
+}
+
+func limitOrder(side types.SideType, quantity, price float64) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     side,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(quantity),
+		Price:    fixedpoint.NewFromFloat(price),
+	}
+}
+
+func TestPreTradeRiskControl_CheckOrders(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, _ := newPreTradeRiskTestSession(mockCtrl)
+
+	control := &PreTradeRiskControl{
+		PreTradeRiskRules: PreTradeRiskRules{
+			MaxOrderNotional: fixedpoint.NewFromFloat(5000.0),
+			PriceCollar:      fixedpoint.NewFromFloat(0.05),
+		},
+		BySymbol: map[string]*PreTradeRiskRules{
+			"BTCUSDT": {
+				MaxPosition:   fixedpoint.NewFromFloat(0.3),
+				MaxOpenOrders: 3,
+			},
+		},
+	}
+
+	t.Run("notional and price collar", func(t *testing.T) {
+		accepted, rejections := control.CheckOrders(session, "", nil,
+			limitOrder(types.SideTypeBuy, 0.1, 19900.0),
+			limitOrder(types.SideTypeBuy, 0.3, 19900.0),
+			limitOrder(types.SideTypeBuy, 0.1, 18000.0),
+		)
+		assert.Len(t, accepted, 1)
+		if assert.Len(t, rejections, 2) {
+			assert.True(t, errors.Is(rejections[0], ErrRiskOrderNotionalTooLarge))
+			assert.True(t, errors.Is(rejections[1], ErrRiskPriceOutOfCollar))
+		}
+	})
+
+	t.Run("position and open orders", func(t *testing.T) {
+		// the accepted orders of the same batch are counted
+		accepted, rejections := control.CheckOrders(session, "", nil,
+			limitOrder(types.SideTypeBuy, 0.2, 20000.0),
+			limitOrder(types.SideTypeBuy, 0.2, 20000.0),
+			limitOrder(types.SideTypeSell, 0.2, 20000.0),
+			limitOrder(types.SideTypeSell, 0.1, 20000.0),
+		)
+		assert.Len(t, accepted, 3)
+		if assert.Len(t, rejections, 1) {
+			assert.True(t, errors.Is(rejections[0], ErrRiskPositionLimitExceeded))
+		}
+
+		accepted, rejections = control.CheckOrders(session, "", nil,
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+			limitOrder(types.SideTypeSell, 0.1, 20000.0),
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+			limitOrder(types.SideTypeSell, 0.1, 20000.0),
+		)
+		assert.Len(t, accepted, 3)
+		if assert.Len(t, rejections, 1) {
+			assert.True(t, errors.Is(rejections[0], ErrRiskOpenOrdersLimitExceeded))
+		}
+	})
+
+	t.Run("strategy position", func(t *testing.T) {
+		control := &PreTradeRiskControl{
+			PreTradeRiskRules: PreTradeRiskRules{MaxStrategyPosition: fixedpoint.NewFromFloat(0.1)},
+		}
+
+		position := types.NewPositionFromMarket(getTestMarket())
+		position.Base = fixedpoint.NewFromFloat(0.1)
+
+		accepted, rejections := control.CheckOrders(session, "strategy:test", position,
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+			limitOrder(types.SideTypeSell, 0.1, 20000.0),
+		)
+		assert.Len(t, accepted, 1)
+		if assert.Len(t, rejections, 1) {
+			var rejection *RiskRejection
+			assert.True(t, errors.As(rejections[0], &rejection))
+			assert.True(t, errors.Is(rejection, ErrRiskStrategyPositionLimitExceeded))
+			assert.Equal(t, types.SideTypeBuy, rejection.Order.Side)
+		}
+	})
+
+	t.Run("order rate", func(t *testing.T) {
+		control := &PreTradeRiskControl{
+			PreTradeRiskRules: PreTradeRiskRules{MaxOrderRate: 2},
+		}
+
+		accepted, rejections := control.CheckOrders(session, "", nil, limitOrder(types.SideTypeBuy, 0.1, 20000.0))
+		assert.Len(t, accepted, 1)
+		assert.Empty(t, rejections)
+
+		// the checked orders are not counted until they are submitted
+		accepted, rejections = control.CheckOrders(session, "", nil,
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+		)
+		assert.Len(t, accepted, 2)
+		assert.Empty(t, rejections)
+
+		control.RecordOrders(types.Order{SubmitOrder: limitOrder(types.SideTypeBuy, 0.1, 20000.0)})
+
+		accepted, rejections = control.CheckOrders(session, "", nil,
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+			limitOrder(types.SideTypeBuy, 0.1, 20000.0),
+		)
+		assert.Len(t, accepted, 1)
+		if assert.Len(t, rejections, 1) {
+			assert.True(t, errors.Is(rejections[0], ErrRiskOrderRateLimitExceeded))
+		}
+	})
+
+	t.Run("custom check", func(t *testing.T) {
+		errNoSell := errors.New("sell is disabled")
+		control := &PreTradeRiskControl{}
+		control.AddCheck(PreTradeRiskCheckFunc(func(ctx *PreTradeRiskContext, order types.SubmitOrder) error {
+			if order.Side == types.SideTypeSell {
+				return errNoSell
+			}
+			return nil
+		}))
+
+		_, rejections := control.CheckOrders(session, "", nil, limitOrder(types.SideTypeSell, 0.1, 20000.0))
+		if assert.Len(t, rejections, 1) {
+			assert.True(t, errors.Is(rejections[0], errNoSell))
+		}
+	})
+}
+
+func TestPreTradeRiskControl_JSON(t *testing.T) {
+	control := &PreTradeRiskControl{
+		PreTradeRiskRules: PreTradeRiskRules{MaxOrderRate: 60},
+		BySymbol: map[string]*PreTradeRiskRules{
+			"BTCUSDT": {MaxOpenOrders: 10},
+		},
+	}
+
+	// the default rules are flattened like the yaml config
+	data, err := json.Marshal(control)
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"maxOrderRate": 60, "bySymbol": {"BTCUSDT": {"maxOpenOrders": 10}}}`, string(data))
+	}
+
+	var decoded PreTradeRiskControl
+	if assert.NoError(t, json.Unmarshal(data, &decoded)) {
+		assert.Equal(t, 60, decoded.MaxOrderRate)
+	}
+}
+
+func TestGeneralOrderExecutor_SubmitOrders_PreTradeRisk(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session, mockEx := newPreTradeRiskTestSession(mockCtrl)
+	session.SetPreTradeRiskControl(&PreTradeRiskControl{
+		PreTradeRiskRules: PreTradeRiskRules{MaxOrderNotional: fixedpoint.NewFromFloat(5000.0)},
+	})
+
+	position := types.NewPositionFromMarket(getTestMarket())
+	executor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test:BTCUSDT", position)
+
+	acceptedOrder := limitOrder(types.SideTypeBuy, 0.1, 20000.0)
+	acceptedOrder.Market = getTestMarket()
+	mockEx.EXPECT().SubmitOrders(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+		assert.Len(t, orders, 1)
+		return types.OrderSlice{{SubmitOrder: orders[0], OrderID: 1, Status: types.OrderStatusNew}}, nil
+	})
+
+	createdOrders, err := executor.SubmitOrders(context.Background(), acceptedOrder, limitOrder(types.SideTypeBuy, 1.0, 20000.0))
+	assert.Len(t, createdOrders, 1)
+	assert.True(t, errors.Is(err, ErrRiskOrderNotionalTooLarge))
+
+	// no order is submitted if all the orders are rejected
+	createdOrders, err = executor.SubmitOrders(context.Background(), limitOrder(types.SideTypeBuy, 1.0, 20000.0))
+	assert.Empty(t, createdOrders)
+	assert.True(t, errors.Is(err, ErrRiskOrderNotionalTooLarge))
+}
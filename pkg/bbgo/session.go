@@ -16,6 +16,8 @@ import (
 	"github.com/c9s/bbgo/pkg/cache"
 
 	exchange2 "github.com/c9s/bbgo/pkg/exchange"
+	"github.com/c9s/bbgo/pkg/exchange/paper"
+	"github.com/c9s/bbgo/pkg/exchange/replay"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/service"
@@ -187,6 +189,25 @@ type ExchangeSession struct {
 	IsolatedFutures       bool   `json:"isolatedFutures,omitempty" yaml:"isolatedFutures,omitempty"`
 	IsolatedFuturesSymbol string `json:"isolatedFuturesSymbol,omitempty" yaml:"isolatedFuturesSymbol,omitempty"`
 
+	// FuturesConfig is applied to the futures account when the futures mode is enabled,
+	// the positions of the futures session are maintained by the exchange instead of the trades.
+	FuturesConfig *FuturesConfig `json:"futuresConfig,omitempty" yaml:"futuresConfig,omitempty"`
+
+	// RecordCassette is the cassette file that records the exchange API calls and the websocket messages,
+	// the cassette can be replayed by the "replay" exchange with the env var REPLAY_CASSETTE
+	RecordCassette string `json:"recordCassette,omitempty" yaml:"recordCassette,omitempty"`
+
+	// PaperTrade routes the orders to the paper trading matching engine fed by the live market data of the exchange,
+	// the balances are virtual and stored in the persistence service
+	PaperTrade bool `json:"paperTrade,omitempty" yaml:"paperTrade,omitempty"`
+
+	// PaperTradeBalances are the initial balances of the paper trading account
+	PaperTradeBalances map[string]fixedpoint.Value `json:"paperTradeBalances,omitempty" yaml:"paperTradeBalances,omitempty"`
+
+	// StreamHealth enables the stale data detection of the streams,
+	// the stale streams are re-connected and the states are re-synchronized after the re-connection
+	StreamHealth *StreamHealthConfig `json:"streamHealth,omitempty" yaml:"streamHealth,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -240,6 +261,22 @@ type ExchangeSession struct {
 	usedSymbols        map[string]struct{}
 	initializedSymbols map[string]struct{}
 
+	// paperExchange is the paper trading exchange of the session, it's nil if paper trading is disabled
+	paperExchange *paper.Exchange
+
+	// preTradeRiskControl checks the orders of the order executors before the orders are submitted
+	preTradeRiskControl *PreTradeRiskControl
+
+	// killSwitch is the account-level circuit breaker shared by all the sessions
+	killSwitch *KillSwitch
+
+	// exposureManager aggregates the strategy positions across the sessions
+	exposureManager *ExposureManager
+
+	// strategyPositions are the positions of the general order executors, map: strategy instance id, symbol -> position
+	strategyPositions      map[strategyPositionKey]*types.Position
+	strategyPositionsMutex sync.Mutex
+
 	logger *log.Entry
 }
 
@@ -343,6 +380,13 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 		}
 	}
 
+	if session.paperExchange != nil {
+		store := PersistenceServiceFacade.Get().NewStore("paper-trade", session.Name)
+		if err := session.paperExchange.LoadState(store); err != nil {
+			return err
+		}
+	}
+
 	// query and initialize the balances
 	if !session.PublicOnly {
 		account, err := session.Exchange.QueryAccount(ctx)
@@ -373,6 +417,12 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 			session.accountMutex.Unlock()
 		})
 
+		if session.isFuturesPositionBacked() {
+			if err := session.initFuturesPositions(ctx); err != nil {
+				return err
+			}
+		}
+
 		session.bindConnectionStatusNotification(session.UserDataStream, "user data")
 
 		// if metrics mode is enabled, we bind the callbacks to update metrics
@@ -485,14 +535,19 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 		}
 	})
 
-	position := &types.Position{
-		Symbol:        symbol,
-		BaseCurrency:  market.BaseCurrency,
-		QuoteCurrency: market.QuoteCurrency,
+	// the futures positions are synchronized from the exchange, so we don't build the position from the trades
+	if !session.isFuturesPositionBacked() {
+		position := &types.Position{
+			Symbol:        symbol,
+			BaseCurrency:  market.BaseCurrency,
+			QuoteCurrency: market.QuoteCurrency,
+		}
+		position.AddTrades(trades)
+		position.BindStream(session.UserDataStream)
+		session.positions[symbol] = position
+	} else {
+		session.Position(symbol)
 	}
-	position.AddTrades(trades)
-	position.BindStream(session.UserDataStream)
-	session.positions[symbol] = position
 
 	orderStore := NewOrderStore(symbol)
 	orderStore.AddOrderUpdate = true
@@ -751,7 +806,8 @@ func (session *ExchangeSession) InitExchange(name string, ex types.Exchange) err
 	var err error
 	var exchangeName = session.ExchangeName
 	if ex == nil {
-		if session.PublicOnly {
+		// paper trading only uses the public APIs of the exchange
+		if session.PublicOnly || session.PaperTrade {
 			ex, err = exchange2.NewPublic(exchangeName)
 		} else {
 			if session.Key != "" && session.Secret != "" {
@@ -780,6 +836,10 @@ func (session *ExchangeSession) InitExchange(name string, ex types.Exchange) err
 		}
 	}
 
+	if session.FuturesConfig != nil && !session.Futures {
+		return fmt.Errorf("futuresConfig of session %s requires the futures mode", name)
+	}
+
 	if session.Futures {
 		futuresExchange, ok := ex.(types.FuturesExchange)
 		if !ok {
@@ -793,6 +853,22 @@ func (session *ExchangeSession) InitExchange(name string, ex types.Exchange) err
 		}
 	}
 
+	if session.PaperTrade {
+		if session.Margin || session.Futures {
+			return fmt.Errorf("paper trading of session %s does not support margin or futures", name)
+		}
+
+		session.paperExchange = paper.NewExchange(ex, session.PaperTradeBalances, session.MakerFeeRate, session.TakerFeeRate)
+		ex = session.paperExchange
+	}
+
+	if session.RecordCassette != "" {
+		ex, err = replay.NewRecordingExchange(ex, session.RecordCassette)
+		if err != nil {
+			return err
+		}
+	}
+
 	session.Name = name
 	session.Exchange = ex
 	session.UserDataStream = ex.NewStream()
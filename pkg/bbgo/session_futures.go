@@ -0,0 +1,100 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// FuturesConfig is the futures section of the session config, the settings are applied to the futures account on session init
+type FuturesConfig struct {
+	// Leverage is the initial leverage of the symbols, map: symbol -> leverage
+	Leverage map[string]int `json:"leverage,omitempty" yaml:"leverage,omitempty"`
+
+	// MarginType is the margin type of the symbols, map: symbol -> margin type (isolated or cross)
+	MarginType map[string]types.MarginType `json:"marginType,omitempty" yaml:"marginType,omitempty"`
+}
+
+// isFuturesPositionBacked returns true if the positions of the session are maintained by the futures exchange
+func (session *ExchangeSession) isFuturesPositionBacked() bool {
+	if !session.Futures || session.PublicOnly {
+		return false
+	}
+
+	_, ok := session.Exchange.(types.FuturesService)
+	return ok
+}
+
+func (session *ExchangeSession) applyFuturesConfig(ctx context.Context, service types.FuturesService) error {
+	if session.FuturesConfig == nil {
+		return nil
+	}
+
+	for symbol, marginType := range session.FuturesConfig.MarginType {
+		if err := service.SetFuturesMarginType(ctx, symbol, marginType); err != nil {
+			return fmt.Errorf("can not set the futures margin type of %s to %s: %w", symbol, marginType, err)
+		}
+	}
+
+	for symbol, leverage := range session.FuturesConfig.Leverage {
+		if leverage <= 0 {
+			return fmt.Errorf("invalid futures leverage %d of %s", leverage, symbol)
+		}
+
+		if err := service.SetFuturesLeverage(ctx, symbol, leverage); err != nil {
+			return fmt.Errorf("can not set the futures leverage of %s to %d: %w", symbol, leverage, err)
+		}
+	}
+
+	return nil
+}
+
+// initFuturesPositions applies the futures config, loads the positions from the exchange
+// and keeps the session positions in sync with the futures position events of the user data stream.
+func (session *ExchangeSession) initFuturesPositions(ctx context.Context) error {
+	service := session.Exchange.(types.FuturesService)
+	if err := session.applyFuturesConfig(ctx, service); err != nil {
+		return err
+	}
+
+	positions, err := service.QueryFuturesPositions(ctx)
+	if err != nil {
+		return err
+	}
+
+	session.syncFuturesPositions(positions, true)
+
+	session.UserDataStream.OnFuturesPositionSnapshot(func(positions types.FuturesPositionMap) {
+		session.syncFuturesPositions(positions, true)
+	})
+
+	session.UserDataStream.OnFuturesPositionUpdate(func(positions types.FuturesPositionMap) {
+		session.syncFuturesPositions(positions, false)
+	})
+	return nil
+}
+
+// syncFuturesPositions updates the session positions by the futures positions,
+// when the positions is a snapshot, the session positions that are not in the snapshot are closed.
+func (session *ExchangeSession) syncFuturesPositions(positions types.FuturesPositionMap, snapshot bool) {
+	if snapshot {
+		for symbol, position := range session.positions {
+			if _, ok := positions[symbol]; !ok {
+				position.Lock()
+				position.Reset()
+				position.Unlock()
+			}
+		}
+	}
+
+	for symbol, futuresPosition := range positions {
+		position, ok := session.Position(symbol)
+		if !ok {
+			session.logger.Warnf("futures position %s is ignored, market is not found", symbol)
+			continue
+		}
+
+		position.SyncFuturesPosition(futuresPosition)
+	}
+}
@@ -0,0 +1,58 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestExchangeSession_syncFuturesPositions(t *testing.T) {
+	market := getTestMarket()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("test", mockEx)
+	session.markets[market.Symbol] = market
+
+	session.syncFuturesPositions(types.FuturesPositionMap{
+		"BTCUSDT": &types.FuturesPosition{
+			Symbol:      "BTCUSDT",
+			Base:        fixedpoint.NewFromFloat(-0.5),
+			AverageCost: fixedpoint.NewFromFloat(20000.0),
+			UpdateTime:  1639933384755,
+		},
+		"ETHUSDT": &types.FuturesPosition{
+			Symbol: "ETHUSDT",
+			Base:   fixedpoint.One,
+		},
+	}, true)
+
+	position, ok := session.Position("BTCUSDT")
+	if assert.True(t, ok) {
+		assert.Equal(t, fixedpoint.NewFromFloat(-0.5), position.Base)
+		assert.Equal(t, fixedpoint.NewFromFloat(20000.0), position.AverageCost)
+		assert.Equal(t, fixedpoint.NewFromFloat(10000.0), position.Quote)
+		assert.True(t, position.IsShort())
+	}
+
+	// the market of ETHUSDT is not defined
+	_, ok = session.Position("ETHUSDT")
+	assert.False(t, ok)
+
+	// the position update only contains the changed positions
+	session.syncFuturesPositions(types.FuturesPositionMap{}, false)
+	assert.True(t, position.IsShort())
+
+	// the position is closed if it's not in the snapshot
+	session.syncFuturesPositions(types.FuturesPositionMap{}, true)
+	assert.True(t, position.IsClosed())
+}
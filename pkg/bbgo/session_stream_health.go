@@ -0,0 +1,436 @@
+package bbgo
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultStreamStaleThreshold = 2 * time.Minute
+const defaultStreamHealthCheckInterval = 10 * time.Second
+
+// userDataChannel is the channel of the user data stream messages, the user data stream does not have subscriptions
+const userDataChannel = types.Channel("user")
+
+// StreamHealthConfig is the stream health section of the session config,
+// the streams that stay connected without delivering any data are re-connected and the states are re-synchronized.
+type StreamHealthConfig struct {
+	// StaleThreshold is the max duration without any message of a market data subscription, default to 2 minutes.
+	// The kline interval is added to the threshold of the kline subscriptions, since some exchanges only push the closed klines.
+	StaleThreshold types.Duration `json:"staleThreshold,omitempty" yaml:"staleThreshold,omitempty"`
+
+	// UserDataStaleThreshold is the max duration without any message of the user data stream,
+	// it's disabled by default because the user data stream is quiet when there is no order activity.
+	UserDataStaleThreshold types.Duration `json:"userDataStaleThreshold,omitempty" yaml:"userDataStaleThreshold,omitempty"`
+
+	// CheckInterval is the interval of the staleness check, default to 10 seconds
+	CheckInterval types.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+
+	// DisableResync disables re-querying the open orders, the balances and the order book snapshots after the streams are re-connected
+	DisableResync bool `json:"disableResync,omitempty" yaml:"disableResync,omitempty"`
+}
+
+type streamDataKey struct {
+	channel  types.Channel
+	symbol   string
+	interval types.Interval
+}
+
+// streamHealthMonitor tracks the last message time of the stream subscriptions,
+// and re-connects the stream when a subscription is stale.
+type streamHealthMonitor struct {
+	session    *ExchangeSession
+	stream     types.Stream
+	streamName string
+
+	// channel is the metrics channel label: market or user
+	channel string
+
+	threshold time.Duration
+
+	mu               sync.Mutex
+	connected        bool
+	connectedAt      time.Time
+	numOfConnects    int
+	lastMessageTimes map[streamDataKey]time.Time
+}
+
+func newStreamHealthMonitor(session *ExchangeSession, stream types.Stream, streamName, channel string, threshold time.Duration) *streamHealthMonitor {
+	m := &streamHealthMonitor{
+		session:          session,
+		stream:           stream,
+		streamName:       streamName,
+		channel:          channel,
+		threshold:        threshold,
+		lastMessageTimes: make(map[streamDataKey]time.Time),
+	}
+
+	stream.OnConnect(func() {
+		m.mu.Lock()
+		m.connected = true
+		m.connectedAt = time.Now()
+		m.numOfConnects++
+		m.mu.Unlock()
+	})
+
+	stream.OnDisconnect(func() {
+		m.mu.Lock()
+		m.connected = false
+		m.mu.Unlock()
+	})
+
+	return m
+}
+
+func (m *streamHealthMonitor) touch(channel types.Channel, symbol string, interval types.Interval) {
+	m.mu.Lock()
+	m.lastMessageTimes[streamDataKey{channel: channel, symbol: symbol, interval: interval}] = time.Now()
+	m.mu.Unlock()
+}
+
+// reconnected returns true if the stream is connected more than once
+func (m *streamHealthMonitor) reconnected() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.numOfConnects > 1
+}
+
+func (m *streamHealthMonitor) bindMarketData() {
+	m.stream.OnKLine(func(kline types.KLine) {
+		m.touch(types.KLineChannel, kline.Symbol, kline.Interval)
+	})
+	m.stream.OnKLineClosed(func(kline types.KLine) {
+		m.touch(types.KLineChannel, kline.Symbol, kline.Interval)
+	})
+	m.stream.OnBookSnapshot(func(book types.SliceOrderBook) {
+		m.touch(types.BookChannel, book.Symbol, "")
+	})
+	m.stream.OnBookUpdate(func(book types.SliceOrderBook) {
+		m.touch(types.BookChannel, book.Symbol, "")
+	})
+	m.stream.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+		m.touch(types.BookTickerChannel, bookTicker.Symbol, "")
+	})
+	m.stream.OnMarketTrade(func(trade types.Trade) {
+		m.touch(types.MarketTradeChannel, trade.Symbol, "")
+	})
+}
+
+func (m *streamHealthMonitor) bindUserData() {
+	m.stream.OnRawMessage(func(raw []byte) {
+		m.touch(userDataChannel, "", "")
+	})
+}
+
+// keys returns the keys of the data that are expected from the stream
+func (m *streamHealthMonitor) keys() (keys []streamDataKey) {
+	if m.channel == "user" {
+		return []streamDataKey{{channel: userDataChannel}}
+	}
+
+	for _, sub := range m.stream.GetSubscriptions() {
+		key := streamDataKey{channel: sub.Channel, symbol: sub.Symbol}
+		if sub.Channel == types.KLineChannel {
+			key.interval = sub.Options.Interval
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// staleKeys returns the subscriptions that have no message within the threshold since the stream is connected,
+// nothing is returned if the stream is disconnected since the stream is re-connecting.
+func (m *streamHealthMonitor) staleKeys(now time.Time) (stale []streamDataKey) {
+	keys := m.keys()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.connected {
+		return nil
+	}
+
+	for _, key := range keys {
+		lastTime := m.lastMessageTimes[key]
+		if lastTime.Before(m.connectedAt) {
+			lastTime = m.connectedAt
+		}
+
+		threshold := m.threshold
+		if key.channel == types.KLineChannel && key.interval != "" {
+			threshold += key.interval.Duration()
+		}
+
+		age := now.Sub(lastTime)
+		if viper.GetBool("metrics") {
+			metricsStreamLastMessageAge.With(m.labels(key)).Set(age.Seconds())
+		}
+
+		if age > threshold {
+			stale = append(stale, key)
+		}
+	}
+
+	return stale
+}
+
+func (m *streamHealthMonitor) labels(key streamDataKey) prometheus.Labels {
+	return prometheus.Labels{
+		"exchange":  m.session.ExchangeName.String(),
+		"margin":    m.session.MarginType(),
+		"channel":   m.channel,
+		"data_type": string(key.channel),
+		"symbol":    key.symbol,
+		"interval":  string(key.interval),
+	}
+}
+
+func (m *streamHealthMonitor) check(now time.Time) {
+	stale := m.staleKeys(now)
+	if len(stale) == 0 {
+		return
+	}
+
+	for _, key := range stale {
+		m.session.logger.Warnf("%s stream %s %s %s has no message within %s", m.streamName, key.channel, key.symbol, key.interval, m.threshold)
+
+		if viper.GetBool("metrics") {
+			metricsStreamStaleTotal.With(m.labels(key)).Inc()
+		}
+	}
+
+	Notify("session %s %s stream is stale, %d subscriptions have no message within %s, re-connecting...", m.session.Name, m.streamName, len(stale), m.threshold)
+
+	// reset the connected time, so that the stream won't be re-connected again before the re-connection is done
+	m.mu.Lock()
+	m.connectedAt = now
+	m.mu.Unlock()
+
+	reconnector, ok := streamReconnector(m.stream)
+	if !ok {
+		m.session.logger.Warnf("%s stream %T does not support re-connecting", m.streamName, m.stream)
+		return
+	}
+
+	reconnector.Reconnect()
+}
+
+func (m *streamHealthMonitor) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			m.check(now)
+		}
+	}
+}
+
+// streamReconnector returns the reconnector of the stream, the heikin-ashi stream is unwrapped to the exchange stream
+func streamReconnector(stream types.Stream) (types.Reconnector, bool) {
+	if heikinAshiStream, ok := stream.(*types.HeikinAshiStream); ok {
+		stream = heikinAshiStream.StandardStreamEmitter
+	}
+
+	reconnector, ok := stream.(types.Reconnector)
+	return reconnector, ok
+}
+
+// monitorStreamHealth starts the stale data detection of the session streams,
+// and re-synchronizes the session states after the streams are re-connected.
+// It should be called before the streams are connected.
+func (session *ExchangeSession) monitorStreamHealth(ctx context.Context) {
+	config := session.StreamHealth
+	if config == nil {
+		return
+	}
+
+	threshold := config.StaleThreshold.Duration()
+	if threshold == 0 {
+		threshold = defaultStreamStaleThreshold
+	}
+
+	checkInterval := config.CheckInterval.Duration()
+	if checkInterval == 0 {
+		checkInterval = defaultStreamHealthCheckInterval
+	}
+
+	marketDataMonitor := newStreamHealthMonitor(session, session.MarketDataStream, "market data", "market", threshold)
+	marketDataMonitor.bindMarketData()
+	go marketDataMonitor.run(ctx, checkInterval)
+
+	if !config.DisableResync {
+		session.MarketDataStream.OnConnect(func() {
+			if marketDataMonitor.reconnected() {
+				go session.resyncMarketData(ctx)
+			}
+		})
+	}
+
+	if session.PublicOnly {
+		return
+	}
+
+	userDataMonitor := newStreamHealthMonitor(session, session.UserDataStream, "user data", "user", config.UserDataStaleThreshold.Duration())
+	if config.UserDataStaleThreshold > 0 {
+		userDataMonitor.bindUserData()
+		go userDataMonitor.run(ctx, checkInterval)
+	}
+
+	if !config.DisableResync {
+		session.UserDataStream.OnConnect(func() {
+			if userDataMonitor.reconnected() {
+				go session.resyncUserData(ctx)
+			}
+		})
+	}
+}
+
+func (session *ExchangeSession) metricsResyncUpdater(channel string, err error) {
+	if !viper.GetBool("metrics") {
+		return
+	}
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	metricsStreamResyncTotal.With(prometheus.Labels{
+		"exchange": session.ExchangeName.String(),
+		"margin":   session.MarginType(),
+		"channel":  channel,
+		"result":   result,
+	}).Inc()
+}
+
+// resyncUserData re-queries the balances, the open orders and the futures positions after the user data stream is re-connected,
+// the query results are emitted as the stream events, so that the account, the order stores and the positions are updated
+// through the same callbacks of the stream.
+func (session *ExchangeSession) resyncUserData(ctx context.Context) {
+	err := session.syncUserData(ctx)
+	session.metricsResyncUpdater("user", err)
+
+	if err != nil {
+		session.logger.WithError(err).Errorf("user data resync error")
+		Notify("session %s user data resync error: %v", session.Name, err)
+		return
+	}
+
+	session.logger.Infof("user data is re-synchronized")
+}
+
+func (session *ExchangeSession) syncUserData(ctx context.Context) error {
+	emitter, ok := session.UserDataStream.(types.StandardStreamEmitter)
+	if !ok {
+		return nil
+	}
+
+	balances, err := session.Exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	emitter.EmitBalanceSnapshot(balances)
+
+	orderQueryService, hasOrderQueryService := session.Exchange.(types.ExchangeOrderQueryService)
+	for symbol, store := range session.orderStores {
+		openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			return err
+		}
+
+		openOrderIDs := make(map[uint64]struct{}, len(openOrders))
+		for _, order := range openOrders {
+			openOrderIDs[order.OrderID] = struct{}{}
+			emitter.EmitOrderUpdate(order)
+		}
+
+		if !hasOrderQueryService {
+			continue
+		}
+
+		// the orders that were closed during the disconnection are updated by querying the order status
+		for _, order := range store.Orders() {
+			if _, ok := openOrderIDs[order.OrderID]; ok {
+				continue
+			}
+
+			switch order.Status {
+			case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+			default:
+				continue
+			}
+
+			updatedOrder, err := orderQueryService.QueryOrder(ctx, types.OrderQuery{
+				Symbol:  order.Symbol,
+				OrderID: strconv.FormatUint(order.OrderID, 10),
+			})
+			if err != nil {
+				return err
+			}
+
+			emitter.EmitOrderUpdate(*updatedOrder)
+		}
+	}
+
+	if session.isFuturesPositionBacked() {
+		positions, err := session.Exchange.(types.FuturesService).QueryFuturesPositions(ctx)
+		if err != nil {
+			return err
+		}
+
+		emitter.EmitFuturesPositionSnapshot(positions)
+	}
+
+	return nil
+}
+
+// resyncMarketData re-queries the order book snapshots after the market data stream is re-connected
+func (session *ExchangeSession) resyncMarketData(ctx context.Context) {
+	err := session.syncOrderBooks(ctx)
+	session.metricsResyncUpdater("market", err)
+
+	if err != nil {
+		session.logger.WithError(err).Errorf("market data resync error")
+		Notify("session %s market data resync error: %v", session.Name, err)
+		return
+	}
+
+	session.logger.Infof("market data is re-synchronized")
+}
+
+func (session *ExchangeSession) syncOrderBooks(ctx context.Context) error {
+	depthService, ok := session.Exchange.(types.ExchangeDepthService)
+	if !ok {
+		return nil
+	}
+
+	emitter, ok := session.MarketDataStream.(types.StandardStreamEmitter)
+	if !ok {
+		return nil
+	}
+
+	for symbol := range session.orderBooks {
+		snapshot, _, err := depthService.QueryDepth(ctx, symbol)
+		if err != nil {
+			return err
+		}
+
+		emitter.EmitBookSnapshot(snapshot)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestStreamHealthMonitor_check(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	stream := types.NewStandardStream()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&stream).Times(2)
+
+	session := NewExchangeSession("test", mockEx)
+
+	monitor := newStreamHealthMonitor(session, &stream, "market data", "market", time.Minute)
+	monitor.bindMarketData()
+
+	stream.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval1m})
+	stream.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{})
+	stream.EmitConnect()
+	assert.False(t, monitor.reconnected())
+
+	now := time.Now()
+	assert.Empty(t, monitor.staleKeys(now))
+
+	stream.EmitBookUpdate(types.SliceOrderBook{Symbol: "BTCUSDT"})
+
+	// the kline interval is added to the threshold of the kline subscription
+	assert.Equal(t, []streamDataKey{
+		{channel: types.BookChannel, symbol: "BTCUSDT"},
+	}, monitor.staleKeys(now.Add(90*time.Second)))
+
+	monitor.check(now.Add(90 * time.Second))
+	select {
+	case <-stream.ReconnectC:
+	default:
+		t.Error("the stale stream should be re-connected")
+	}
+
+	// the connected time is reset after the re-connect signal is sent
+	assert.Empty(t, monitor.staleKeys(now.Add(120*time.Second)))
+
+	// the disconnected stream is not checked since it's re-connecting
+	stream.EmitDisconnect()
+	assert.Empty(t, monitor.staleKeys(now.Add(time.Hour)))
+
+	stream.EmitConnect()
+	assert.True(t, monitor.reconnected())
+}
@@ -81,7 +81,21 @@ func (c *TradeCollector) Emit() {
 	c.orderSig.Emit()
 }
 
+// Recover queries the trades from the given time and processes the trades that are not processed yet.
+// If the order store has the strategy instance ID, the closed orders of the strategy instance are recovered into
+// the order store by their client order IDs first, so that the trades executed before a restart are matched.
 func (c *TradeCollector) Recover(ctx context.Context, ex types.ExchangeTradeHistoryService, symbol string, from time.Time) error {
+	if c.orderStore.StrategyInstanceID != "" {
+		closedOrders, err := ex.QueryClosedOrders(ctx, symbol, from, time.Now(), 0)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range c.orderStore.Recover(closedOrders...) {
+			log.Infof("recovered order: %s", o.String())
+		}
+	}
+
 	trades, err := ex.QueryTrades(ctx, symbol, &types.TradeQueryOptions{
 		StartTime: &from,
 	})
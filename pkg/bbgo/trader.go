@@ -292,6 +292,71 @@ func (trader *Trader) getSessionOrderExecutor(sessionName string) OrderExecutor
 	return orderExecutor
 }
 
+// setPreTradeRiskControls sets the pre-trade risk pipelines of the sessions from the risk controls config
+func (trader *Trader) setPreTradeRiskControls() {
+	if trader.riskControls == nil {
+		return
+	}
+
+	for sessionName, control := range trader.riskControls.SessionBasedRiskControl {
+		if control == nil || control.PreTrade == nil {
+			continue
+		}
+
+		session, ok := trader.environment.sessions[sessionName]
+		if !ok {
+			log.Warnf("pre-trade risk control: session %s is not found", sessionName)
+			continue
+		}
+
+		session.SetPreTradeRiskControl(control.PreTrade)
+	}
+}
+
+// setupExposureManager binds the sessions to the exposure manager from the risk controls config
+func (trader *Trader) setupExposureManager() {
+	if manager := trader.ExposureManager(); manager != nil {
+		manager.Setup(trader.environment.sessions)
+	}
+}
+
+// ExposureManager returns the cross-strategy exposure manager, it's nil if the exposure is not configured
+func (trader *Trader) ExposureManager() *ExposureManager {
+	if trader.riskControls == nil {
+		return nil
+	}
+
+	return trader.riskControls.Exposure
+}
+
+// setupKillSwitch loads the kill switch state and binds the sessions and the strategies to the kill switch
+func (trader *Trader) setupKillSwitch() (*KillSwitch, error) {
+	if trader.riskControls == nil || trader.riskControls.KillSwitch == nil {
+		return nil, nil
+	}
+
+	// the kill switch watches the live equity, it's not used in back-testing
+	if trader.environment.BacktestService != nil {
+		return nil, nil
+	}
+
+	var strategies []interface{}
+	if err := trader.IterateStrategies(func(st StrategyID) error {
+		strategies = append(strategies, st)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	store := PersistenceServiceFacade.Get().NewStore(killSwitchStoreID)
+	killSwitch := trader.riskControls.KillSwitch
+	if err := killSwitch.Setup(store, trader.environment.sessions, strategies); err != nil {
+		return nil, err
+	}
+
+	return killSwitch, nil
+}
+
 func (trader *Trader) RunAllSingleExchangeStrategy(ctx context.Context) error {
 	// load and run Session strategies
 	for sessionName, strategies := range trader.exchangeStrategies {
@@ -319,6 +384,14 @@ func (trader *Trader) Run(ctx context.Context) error {
 		return err
 	}
 
+	trader.setPreTradeRiskControls()
+	trader.setupExposureManager()
+
+	killSwitch, err := trader.setupKillSwitch()
+	if err != nil {
+		return err
+	}
+
 	if err := trader.RunAllSingleExchangeStrategy(ctx); err != nil {
 		return err
 	}
@@ -350,6 +423,11 @@ func (trader *Trader) Run(ctx context.Context) error {
 		}
 	}
 
+	// start the kill switch after the strategies are running, so that the suspend callbacks are registered
+	if killSwitch != nil {
+		killSwitch.Start(ctx)
+	}
+
 	return trader.environment.Connect(ctx)
 }
 
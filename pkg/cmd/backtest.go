@@ -24,6 +24,7 @@ import (
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
 	"github.com/c9s/bbgo/pkg/data/tsv"
 	"github.com/c9s/bbgo/pkg/exchange"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
@@ -260,6 +261,29 @@ var BacktestCmd = &cobra.Command{
 			exchangeFromConfig := userConfig.Sessions[name.String()]
 			if exchangeFromConfig != nil {
 				session.UseHeikinAshi = exchangeFromConfig.UseHeikinAshi
+
+				// simulate the margin and futures account of the session
+				if exchangeFromConfig.Margin {
+					session.Margin = true
+					session.IsolatedMargin = exchangeFromConfig.IsolatedMargin
+					session.IsolatedMarginSymbol = exchangeFromConfig.IsolatedMarginSymbol
+					if session.IsolatedMargin {
+						backtestExchange.UseIsolatedMargin(session.IsolatedMarginSymbol)
+					} else {
+						backtestExchange.UseMargin()
+					}
+				}
+
+				if exchangeFromConfig.Futures {
+					session.Futures = true
+					session.IsolatedFutures = exchangeFromConfig.IsolatedFutures
+					session.IsolatedFuturesSymbol = exchangeFromConfig.IsolatedFuturesSymbol
+					if session.IsolatedFutures {
+						backtestExchange.UseIsolatedFutures(session.IsolatedFuturesSymbol)
+					} else {
+						backtestExchange.UseFutures()
+					}
+				}
 			}
 		}
 
@@ -298,6 +322,57 @@ var BacktestCmd = &cobra.Command{
 		var runID = userConfig.GetSignature() + "_" + uuid.NewString()
 		var reportDir = outputDirectory
 
+		// sample the total equity value hourly for the drawdown and the return ratios
+		var equitySamples []backtest.EquitySample
+		kLineHandlers = append(kLineHandlers, func(k types.KLine, _ *backtest.ExchangeDataSource) {
+			if k.Interval != types.Interval1h {
+				return
+			}
+
+			t := k.EndTime.Time()
+			if n := len(equitySamples); n > 0 && !t.After(equitySamples[n-1].Time) {
+				return
+			}
+
+			equitySamples = append(equitySamples, backtest.EquitySample{
+				Time:   t,
+				Equity: totalEquityInUSD(environ.Sessions(), t),
+			})
+		})
+
+		// sample the equity value of each session symbol at every kline close
+		var equityCurveRecorders = map[string]*backtest.EquityCurveRecorder{}
+		defer func() {
+			for _, recorder := range equityCurveRecorders {
+				if err := recorder.Close(); err != nil {
+					log.WithError(err).Errorf("can not close the equity curve file")
+				}
+			}
+		}()
+
+		kLineHandlers = append(kLineHandlers, func(k types.KLine, exSource *backtest.ExchangeDataSource) {
+			key := equityCurveKey(exSource.Session.Name, k.Symbol)
+			recorder, ok := equityCurveRecorders[key]
+			if !ok {
+				market, ok := exSource.Session.Market(k.Symbol)
+				if !ok {
+					return
+				}
+
+				recorder = backtest.NewEquityCurveRecorder(market)
+				if generatingReport {
+					if err := recorder.OpenFile(filepath.Join(reportDir, key+".tsv")); err != nil {
+						log.WithError(err).Errorf("can not create the equity curve file")
+					}
+				}
+				equityCurveRecorders[key] = recorder
+			}
+
+			if err := recorder.Record(k.EndTime.Time(), exSource.Session.GetAccount().Balances(), k.Close); err != nil {
+				log.WithError(err).Errorf("can not write the equity curve")
+			}
+		})
+
 		if generatingReport {
 			if reportFileInSubDir {
 				// reportDir = filepath.Join(reportDir, backtestSessionName)
@@ -499,14 +574,22 @@ var BacktestCmd = &cobra.Command{
 					return err
 				}
 
+				key := equityCurveKey(session.Name, symbol)
+				if recorder, ok := equityCurveRecorders[key]; ok {
+					symbolReport.SetEquityCurveMetrics(recorder)
+					if generatingReport {
+						symbolReport.EquityCurve = key + ".tsv"
+					}
+				}
+
 				summaryReport.Symbols = append(summaryReport.Symbols, symbol)
 				summaryReport.SymbolReports = append(summaryReport.SymbolReports, *symbolReport)
 				summaryReport.TotalProfit = symbolReport.PnL.Profit
 				summaryReport.TotalUnrealizedProfit = symbolReport.PnL.UnrealizedProfit
 				summaryReport.InitialEquityValue = summaryReport.InitialEquityValue.Add(symbolReport.InitialEquityValue())
 				summaryReport.FinalEquityValue = summaryReport.FinalEquityValue.Add(symbolReport.FinalEquityValue())
-				summaryReport.TotalGrossProfit.Add(symbolReport.PnL.GrossProfit)
-				summaryReport.TotalGrossLoss.Add(symbolReport.PnL.GrossLoss)
+				summaryReport.TotalGrossProfit = summaryReport.TotalGrossProfit.Add(symbolReport.PnL.GrossProfit)
+				summaryReport.TotalGrossLoss = summaryReport.TotalGrossLoss.Add(symbolReport.PnL.GrossLoss)
 
 				// write report to a file
 				if generatingReport {
@@ -518,6 +601,9 @@ var BacktestCmd = &cobra.Command{
 			}
 		}
 
+		summaryReport.SetTradeMetrics()
+		summaryReport.SetEquityMetrics(equitySamples)
+
 		if generatingReport {
 			summaryReportFile := filepath.Join(reportDir, "summary.json")
 
@@ -545,6 +631,12 @@ var BacktestCmd = &cobra.Command{
 			color.Green("END TIME: %s\n", endTime.Format(time.RFC1123))
 			color.Green("INITIAL TOTAL BALANCE: %v\n", initTotalBalances)
 			color.Green("FINAL TOTAL BALANCE: %v\n", finalTotalBalances)
+			color.Green("MAX DRAWDOWN: %s (%s)\n", summaryReport.MaxDrawdown.FormatPercentage(2), summaryReport.MaxDrawdownDuration)
+			color.Green("SHARPE RATIO: %s\n", summaryReport.SharpeRatio.FormatString(4))
+			color.Green("SORTINO RATIO: %s\n", summaryReport.SortinoRatio.FormatString(4))
+			color.Green("CALMAR RATIO: %s\n", summaryReport.CalmarRatio.FormatString(4))
+			color.Green("PROFIT FACTOR: %s\n", summaryReport.ProfitFactor.FormatString(4))
+			color.Green("WIN RATE: %s\n", summaryReport.WinRate.FormatPercentage(2))
 
 			for _, symbolReport := range summaryReport.SymbolReports {
 				symbolReport.Print(wantBaseAssetBaseline)
@@ -597,6 +689,8 @@ func createSymbolReport(userConfig *bbgo.Config, session *bbgo.ExchangeSession,
 		// Manifests:       manifests,
 	}
 
+	symbolReport.SetTradeMetrics()
+
 	for _, s := range session.Subscriptions {
 		symbolReport.Subscriptions = append(symbolReport.Subscriptions, s)
 	}
@@ -615,6 +709,19 @@ func createSymbolReport(userConfig *bbgo.Config, session *bbgo.ExchangeSession,
 	return &symbolReport, nil
 }
 
+func equityCurveKey(sessionName, symbol string) string {
+	return "equity_curve_" + sessionName + "_" + symbol
+}
+
+// totalEquityInUSD returns the total net asset value of the sessions in USD
+func totalEquityInUSD(sessions map[string]*bbgo.ExchangeSession, t time.Time) (total fixedpoint.Value) {
+	for _, session := range sessions {
+		balances := session.GetAccount().Balances()
+		total = total.Add(balances.Assets(session.AllLastPrices(), t).InUSD())
+	}
+	return total
+}
+
 func verify(userConfig *bbgo.Config, backtestService *service.BacktestService, sourceExchanges map[types.ExchangeName]types.Exchange, startTime, endTime time.Time) error {
 	for _, sourceExchange := range sourceExchanges {
 		err := backtestService.Verify(sourceExchange, userConfig.Backtest.Symbols, startTime, endTime)
@@ -692,6 +799,12 @@ func sync(ctx context.Context, userConfig *bbgo.Config, backtestService *service
 					return err
 				}
 			}
+
+			if userConfig.Backtest.ReplayMarketTrades {
+				if err := backtestService.SyncMarketTrades(ctx, sourceExchange, symbol, syncFrom, syncTo); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return nil
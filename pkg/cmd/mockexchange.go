@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/exchange/mockexchange"
+)
+
+func init() {
+	mockExchangeCmd.Flags().String("listen", ":8081", "the address the mock exchange listens on")
+	mockExchangeCmd.Flags().String("mock-config", "config/mock-exchange.yaml", "the markets and the balances of the mock exchange")
+	RootCmd.AddCommand(mockExchangeCmd)
+}
+
+// mockExchangeCmd runs the in-process exchange simulator that speaks the binance REST and websocket protocols
+var mockExchangeCmd = &cobra.Command{
+	Use:   "mock-exchange",
+	Short: "run the mock exchange server with the binance compatible api",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			return err
+		}
+
+		configFile, err := cmd.Flags().GetString("mock-config")
+		if err != nil {
+			return err
+		}
+
+		config, err := mockexchange.LoadConfig(configFile)
+		if err != nil {
+			return err
+		}
+
+		engine, err := mockexchange.NewEngineFromConfig(config)
+		if err != nil {
+			return err
+		}
+
+		server := mockexchange.NewServer(engine)
+		srv := &http.Server{
+			Addr:    listen,
+			Handler: server.Handler(),
+		}
+
+		go func() {
+			log.Infof("mock exchange listening on %s", listen)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.WithError(err).Fatalf("mock exchange server error")
+			}
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+
+		server.DisconnectStreams()
+
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		return srv.Shutdown(shutdownCtx)
+	},
+}
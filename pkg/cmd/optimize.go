@@ -15,6 +15,7 @@ import (
 	"github.com/c9s/bbgo/pkg/data/tsv"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/optimizer"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func init() {
@@ -89,22 +90,60 @@ var optimizeCmd = &cobra.Command{
 			return err
 		}
 
-		executor := &optimizer.LocalProcessExecutor{
-			Config:    optConfig.Executor.LocalExecutorConfig,
-			Bin:       os.Args[0],
-			WorkDir:   ".",
-			ConfigDir: configDir,
-			OutputDir: outputDirectory,
-		}
+		var executor optimizer.Executor
+		switch optConfig.Executor.Type {
+		case "remote":
+			remoteExecutor := &optimizer.RemoteExecutor{
+				Config: optConfig.Executor.RemoteExecutorConfig,
+			}
+
+			if err := remoteExecutor.Prepare(configJson); err != nil {
+				return err
+			}
+
+			executor = remoteExecutor
+
+		default:
+			localExecutor := &optimizer.LocalProcessExecutor{
+				Config:    optConfig.Executor.LocalExecutorConfig,
+				Bin:       os.Args[0],
+				WorkDir:   ".",
+				ConfigDir: configDir,
+				OutputDir: outputDirectory,
+			}
 
-		optz := &optimizer.GridOptimizer{
-			Config: optConfig,
+			if err := localExecutor.Prepare(configJson); err != nil {
+				return err
+			}
+
+			executor = localExecutor
 		}
 
-		if err := executor.Prepare(configJson); err != nil {
-			return err
+		if optConfig.WalkForward != nil {
+			wfOptz := &optimizer.WalkForwardOptimizer{
+				Config: optConfig,
+			}
+
+			report, err := wfOptz.Run(executor, configJson)
+			if err != nil {
+				return err
+			}
+
+			if printJsonFormat {
+				out, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+
+				fmt.Println(string(out))
+			} else {
+				printWalkForwardReport(report)
+			}
+
+			return nil
 		}
 
+		optz := optimizer.NewOptimizer(optConfig)
 		metrics, err := optz.Run(executor, configJson)
 		if err != nil {
 			return err
@@ -139,6 +178,28 @@ var optimizeCmd = &cobra.Command{
 	},
 }
 
+func printWalkForwardReport(report *optimizer.WalkForwardReport) {
+	for i, w := range report.Windows {
+		fmt.Printf("window #%d in-sample %s ~ %s => %v %v %s %v\n", i+1,
+			w.InSampleStartTime.Format(types.DateFormat), w.InSampleEndTime.Format(types.DateFormat),
+			w.Labels, w.Params, report.Objective, w.InSampleValue)
+		fmt.Printf("window #%d out-of-sample %s ~ %s => %s %v, equity %v -> %v\n", i+1,
+			w.OutOfSampleStartTime.Format(types.DateFormat), w.OutOfSampleEndTime.Format(types.DateFormat),
+			report.Objective, w.OutOfSampleValue, w.InitialEquityValue, w.FinalEquityValue)
+	}
+
+	fmt.Println("out-of-sample equity curve:")
+	for _, p := range report.EquityCurve {
+		fmt.Printf("%s %v\n", p.Time.Format(types.DateFormat), p.Equity)
+	}
+
+	fmt.Println("parameter stability:")
+	for _, s := range report.ParamStability {
+		fmt.Printf("%s => values %v, distinct %d, most frequent %v (%.0f%%)\n",
+			s.Label, s.Values, s.NumOfDistinctValues, s.MostFrequentValue, s.Stability*100.0)
+	}
+}
+
 func transformMetricsToRows(metrics map[string][]optimizer.Metric) (headers []string, rows [][]interface{}) {
 	var metricsKeys []string
 	for k := range metrics {
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/optimizer"
+)
+
+func init() {
+	optimizerWorkerCmd.Flags().String("listen", "127.0.0.1:8090", "the address the worker listens on, use :8090 to accept the connections from the other machines")
+	optimizerWorkerCmd.Flags().String("token", "", "the shared token of the optimizer, defaults to the "+optimizer.WorkerTokenEnvKey+" environment variable")
+	optimizerWorkerCmd.Flags().Int("max-processes", 1, "the max number of the backtest processes")
+	optimizerWorkerCmd.Flags().String("output", "output", "backtest report output directory")
+	RootCmd.AddCommand(optimizerWorkerCmd)
+}
+
+// optimizerWorkerCmd runs the backtests dispatched by the remote executor of the optimizer
+var optimizerWorkerCmd = &cobra.Command{
+	Use:   "optimizer-worker",
+	Short: "run optimizer worker for the remote executor",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			return err
+		}
+
+		maxProcesses, err := cmd.Flags().GetInt("max-processes")
+		if err != nil {
+			return err
+		}
+
+		outputDirectory, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		token, err := cmd.Flags().GetString("token")
+		if err != nil {
+			return err
+		}
+
+		if token == "" {
+			token = os.Getenv(optimizer.WorkerTokenEnvKey)
+		}
+
+		if token == "" {
+			return fmt.Errorf("--token or the %s environment variable is required", optimizer.WorkerTokenEnvKey)
+		}
+
+		configDir, err := os.MkdirTemp("", "bbgo-config-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(configDir)
+
+		runner := &optimizer.LocalProcessExecutor{
+			Config:    &optimizer.LocalExecutorConfig{MaxNumberOfProcesses: maxProcesses},
+			Bin:       os.Args[0],
+			WorkDir:   ".",
+			ConfigDir: configDir,
+			OutputDir: outputDirectory,
+		}
+
+		worker := optimizer.NewWorkerServer(runner, maxProcesses, token)
+		srv := &http.Server{
+			Addr:    listen,
+			Handler: worker.Handler(),
+		}
+
+		go func() {
+			log.Infof("optimizer worker listening on %s", listen)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.WithError(err).Fatalf("optimizer worker server error")
+			}
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		return srv.Shutdown(shutdownCtx)
+	},
+}
@@ -9,9 +9,11 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/c9s/bbgo/pkg/backtest"
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
 )
 
 // go run ./cmd/bbgo orderbook --session=ftx --symbol=BTCUSDT
@@ -44,6 +46,11 @@ var orderbookCmd = &cobra.Command{
 			return err
 		}
 
+		recordDepthDir, err := cmd.Flags().GetString("record-depth")
+		if err != nil {
+			return err
+		}
+
 		environ := bbgo.NewEnvironment()
 		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
 			return err
@@ -59,6 +66,20 @@ var orderbookCmd = &cobra.Command{
 		s := session.Exchange.NewStream()
 		s.SetPublicOnly()
 		s.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{})
+
+		if len(recordDepthDir) > 0 {
+			if err := util.SafeMkdirAll(recordDepthDir); err != nil {
+				return err
+			}
+
+			recorder := backtest.NewDepthRecorder(recordDepthDir)
+			recorder.BindStream(s)
+			defer func() {
+				if err := recorder.Close(); err != nil {
+					log.WithError(err).Errorf("depth recorder close error")
+				}
+			}()
+		}
 		s.OnBookSnapshot(func(book types.SliceOrderBook) {
 			if dumpDepthUpdate {
 				log.Infof("orderbook snapshot: %s", book.String())
@@ -163,6 +184,7 @@ func init() {
 	orderbookCmd.Flags().String("session", "", "session name")
 	orderbookCmd.Flags().String("symbol", "", "the trading pair. e.g, BTCUSDT, LTCUSDT...")
 	orderbookCmd.Flags().Bool("dump-update", false, "dump the depth update")
+	orderbookCmd.Flags().String("record-depth", "", "record the depth snapshots and updates into the given directory for the back-test depth matching engine")
 
 	orderUpdateCmd.Flags().String("session", "", "session name")
 	RootCmd.AddCommand(orderbookCmd)
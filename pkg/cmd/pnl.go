@@ -24,6 +24,7 @@ func init() {
 	PnLCmd.Flags().Bool("sync", false, "sync before loading trades")
 	PnLCmd.Flags().String("since", "", "query trades from a time point")
 	PnLCmd.Flags().Uint64("limit", 0, "number of trades")
+	PnLCmd.Flags().String("strategy-instance", "", "only calculate the trades of the strategy instance ID")
 	RootCmd.AddCommand(PnLCmd)
 }
 
@@ -86,6 +87,11 @@ var PnLCmd = &cobra.Command{
 			return err
 		}
 
+		strategyInstanceID, err := cmd.Flags().GetString("strategy-instance")
+		if err != nil {
+			return err
+		}
+
 		environ := bbgo.NewEnvironment()
 
 		if err := environ.ConfigureDatabase(ctx); err != nil {
@@ -150,15 +156,16 @@ var PnLCmd = &cobra.Command{
 
 		var trades []types.Trade
 		tradingFeeCurrency := exchange.PlatformFeeCurrency()
-		if strings.HasPrefix(symbol, tradingFeeCurrency) {
+		if strings.HasPrefix(symbol, tradingFeeCurrency) && len(strategyInstanceID) == 0 {
 			log.Infof("loading all trading fee currency related trades: %s", symbol)
 			trades, err = environ.TradeService.QueryForTradingFeeCurrency(exchange.Name(), symbol, tradingFeeCurrency)
 		} else {
 			trades, err = environ.TradeService.Query(service.QueryTradesOptions{
-				Symbol:   symbol,
-				Limit:    limit,
-				Sessions: sessionNames,
-				Since:    &since,
+				Symbol:             symbol,
+				Limit:              limit,
+				Sessions:           sessionNames,
+				Since:              &since,
+				StrategyInstanceID: strategyInstanceID,
 			})
 		}
 
@@ -0,0 +1,38 @@
+package batch
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type MarketTradeBatchQuery struct {
+	types.ExchangeMarketTradeService
+}
+
+func (e *MarketTradeBatchQuery) Query(ctx context.Context, symbol string, startTime, endTime time.Time) (c chan types.Trade, errC chan error) {
+	query := &AsyncTimeRangedBatchQuery{
+		Type: types.Trade{},
+		Q: func(startTime, endTime time.Time) (interface{}, error) {
+			return e.ExchangeMarketTradeService.QueryMarketTrades(ctx, symbol, &types.TradeQueryOptions{
+				StartTime: &startTime,
+				EndTime:   &endTime,
+			})
+		},
+		T: func(obj interface{}) time.Time {
+			return time.Time(obj.(types.Trade).Time)
+		},
+		ID: func(obj interface{}) string {
+			trade := obj.(types.Trade)
+			return strconv.FormatUint(trade.ID, 10)
+		},
+		// the exchange may limit the time range of the market trade query
+		JumpIfEmpty: time.Hour,
+	}
+
+	c = make(chan types.Trade, 3000)
+	errC = query.Query(ctx, c, startTime, endTime)
+	return c, errC
+}
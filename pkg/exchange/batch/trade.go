@@ -18,25 +18,32 @@ type TradeBatchQuery struct {
 }
 
 func (e TradeBatchQuery) Query(ctx context.Context, symbol string, options *types.TradeQueryOptions) (c chan types.Trade, errC chan error) {
-	if options.EndTime == nil {
-		now := time.Now()
-		options.EndTime = &now
+	startTime := *options.StartTime
+	endTime := time.Now()
+	if options.EndTime != nil {
+		endTime = *options.EndTime
 	}
 
-	startTime := *options.StartTime
-	endTime := *options.EndTime
+	lastTradeID := options.LastTradeID
 	query := &AsyncTimeRangedBatchQuery{
 		Type: types.Trade{},
 		Q: func(startTime, endTime time.Time) (interface{}, error) {
-			return e.ExchangeTradeHistoryService.QueryTrades(ctx, symbol, options)
+			// the start time is moved forward to the time of the last received trade,
+			// so that the exchanges without the last trade ID query can query the next page by the time range.
+			// the options are copied per page, the options of the caller are not modified
+			pageOptions := *options
+			pageOptions.StartTime = &startTime
+			pageOptions.EndTime = &endTime
+			pageOptions.LastTradeID = lastTradeID
+			return e.ExchangeTradeHistoryService.QueryTrades(ctx, symbol, &pageOptions)
 		},
 		T: func(obj interface{}) time.Time {
 			return time.Time(obj.(types.Trade).Time)
 		},
 		ID: func(obj interface{}) string {
 			trade := obj.(types.Trade)
-			if trade.ID > options.LastTradeID {
-				options.LastTradeID = trade.ID
+			if trade.ID > lastTradeID {
+				lastTradeID = trade.ID
 			}
 			return trade.Key().String()
 		},
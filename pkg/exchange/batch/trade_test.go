@@ -0,0 +1,72 @@
+package batch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// timeRangedTradeHistoryService returns at most 2 trades since the start time of the query options
+type timeRangedTradeHistoryService struct {
+	trades []types.Trade
+}
+
+func (s *timeRangedTradeHistoryService) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	for _, trade := range s.trades {
+		if trade.Time.Before(*options.StartTime) || trade.Time.After(*options.EndTime) {
+			continue
+		}
+
+		trades = append(trades, trade)
+		if len(trades) == 2 {
+			break
+		}
+	}
+
+	return trades, nil
+}
+
+func (s *timeRangedTradeHistoryService) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	return nil, nil
+}
+
+func TestTradeBatchQuery_TimeRangePagination(t *testing.T) {
+	startTime := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	service := &timeRangedTradeHistoryService{}
+	for i := 0; i < 5; i++ {
+		service.trades = append(service.trades, types.Trade{
+			ID:       uint64(i + 1),
+			Exchange: types.ExchangeKucoin,
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Time:     types.Time(startTime.Add(time.Duration(i) * time.Hour)),
+		})
+	}
+
+	endTime := startTime.Add(24 * time.Hour)
+	options := &types.TradeQueryOptions{
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	}
+
+	q := &TradeBatchQuery{ExchangeTradeHistoryService: service}
+	dataC, errC := q.Query(context.Background(), "BTCUSDT", options)
+
+	var ids []uint64
+	for trade := range dataC {
+		ids = append(ids, trade.ID)
+	}
+
+	assert.NoError(t, <-errC)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, ids)
+
+	// the query options of the caller are not modified
+	assert.Equal(t, startTime, *options.StartTime)
+	assert.Equal(t, endTime, *options.EndTime)
+	assert.Equal(t, uint64(0), options.LastTradeID)
+}
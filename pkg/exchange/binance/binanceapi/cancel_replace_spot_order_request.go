@@ -0,0 +1,69 @@
+package binanceapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type CancelReplaceModeType string
+
+const (
+	// CancelReplaceModeStopOnFailure does not place the new order if the cancel request fails
+	CancelReplaceModeStopOnFailure CancelReplaceModeType = "STOP_ON_FAILURE"
+
+	// CancelReplaceModeAllowFailure places the new order even if the cancel request fails
+	CancelReplaceModeAllowFailure CancelReplaceModeType = "ALLOW_FAILURE"
+)
+
+type CancelReplaceOrderResult struct {
+	Symbol              string                     `json:"symbol"`
+	OrderID             uint64                     `json:"orderId"`
+	ClientOrderID       string                     `json:"clientOrderId"`
+	OrigClientOrderID   string                     `json:"origClientOrderId,omitempty"`
+	Price               fixedpoint.Value           `json:"price"`
+	OrigQty             fixedpoint.Value           `json:"origQty"`
+	ExecutedQty         fixedpoint.Value           `json:"executedQty"`
+	CummulativeQuoteQty fixedpoint.Value           `json:"cummulativeQuoteQty"`
+	Status              string                     `json:"status"`
+	TimeInForce         string                     `json:"timeInForce"`
+	Type                string                     `json:"type"`
+	Side                string                     `json:"side"`
+	TransactTime        types.MillisecondTimestamp `json:"transactTime,omitempty"`
+}
+
+type CancelReplaceSpotOrderResponse struct {
+	// CancelResult and NewOrderResult are SUCCESS, FAILURE or NOT_ATTEMPTED
+	CancelResult     string                    `json:"cancelResult"`
+	NewOrderResult   string                    `json:"newOrderResult"`
+	CancelResponse   *CancelReplaceOrderResult `json:"cancelResponse"`
+	NewOrderResponse *CancelReplaceOrderResult `json:"newOrderResponse"`
+}
+
+// CancelReplaceSpotOrderRequest is the request of POST /api/v3/order/cancelReplace,
+// the accessors are maintained by hand in cancel_replace_spot_order_request_accessors.go
+type CancelReplaceSpotOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	symbol            string                `param:"symbol"`
+	side              string                `param:"side"`
+	orderType         string                `param:"type"`
+	cancelReplaceMode CancelReplaceModeType `param:"cancelReplaceMode"`
+
+	timeInForce *string `param:"timeInForce"`
+	quantity    *string `param:"quantity"`
+	price       *string `param:"price"`
+	stopPrice   *string `param:"stopPrice"`
+
+	cancelOrderId           *uint64 `param:"cancelOrderId"`
+	cancelOrigClientOrderId *string `param:"cancelOrigClientOrderId"`
+	newClientOrderId        *string `param:"newClientOrderId"`
+	newOrderRespType        *string `param:"newOrderRespType"`
+}
+
+// NewCancelReplaceSpotOrderRequest cancels an existing spot order and places a new order on the same symbol,
+// the new order is not placed if the cancel request fails by default.
+func (c *RestClient) NewCancelReplaceSpotOrderRequest() *CancelReplaceSpotOrderRequest {
+	return &CancelReplaceSpotOrderRequest{client: c, cancelReplaceMode: CancelReplaceModeStopOnFailure}
+}
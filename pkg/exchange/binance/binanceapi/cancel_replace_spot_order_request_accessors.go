@@ -0,0 +1,290 @@
+package binanceapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+func (c *CancelReplaceSpotOrderRequest) Symbol(symbol string) *CancelReplaceSpotOrderRequest {
+	c.symbol = symbol
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) Side(side string) *CancelReplaceSpotOrderRequest {
+	c.side = side
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) OrderType(orderType string) *CancelReplaceSpotOrderRequest {
+	c.orderType = orderType
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) CancelReplaceMode(cancelReplaceMode CancelReplaceModeType) *CancelReplaceSpotOrderRequest {
+	c.cancelReplaceMode = cancelReplaceMode
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) TimeInForce(timeInForce string) *CancelReplaceSpotOrderRequest {
+	c.timeInForce = &timeInForce
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) Quantity(quantity string) *CancelReplaceSpotOrderRequest {
+	c.quantity = &quantity
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) Price(price string) *CancelReplaceSpotOrderRequest {
+	c.price = &price
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) StopPrice(stopPrice string) *CancelReplaceSpotOrderRequest {
+	c.stopPrice = &stopPrice
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) CancelOrderId(cancelOrderId uint64) *CancelReplaceSpotOrderRequest {
+	c.cancelOrderId = &cancelOrderId
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) CancelOrigClientOrderId(cancelOrigClientOrderId string) *CancelReplaceSpotOrderRequest {
+	c.cancelOrigClientOrderId = &cancelOrigClientOrderId
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) NewClientOrderId(newClientOrderId string) *CancelReplaceSpotOrderRequest {
+	c.newClientOrderId = &newClientOrderId
+	return c
+}
+
+func (c *CancelReplaceSpotOrderRequest) NewOrderRespType(newOrderRespType string) *CancelReplaceSpotOrderRequest {
+	c.newOrderRespType = &newOrderRespType
+	return c
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (c *CancelReplaceSpotOrderRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (c *CancelReplaceSpotOrderRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check symbol field -> json key symbol
+	symbol := c.symbol
+
+	// assign parameter of symbol
+	params["symbol"] = symbol
+	// check side field -> json key side
+	side := c.side
+
+	// assign parameter of side
+	params["side"] = side
+	// check orderType field -> json key type
+	orderType := c.orderType
+
+	// assign parameter of orderType
+	params["type"] = orderType
+	// check cancelReplaceMode field -> json key cancelReplaceMode
+	cancelReplaceMode := c.cancelReplaceMode
+
+	// TEMPLATE check-valid-values
+	switch cancelReplaceMode {
+	case CancelReplaceModeStopOnFailure, CancelReplaceModeAllowFailure:
+		params["cancelReplaceMode"] = cancelReplaceMode
+
+	default:
+		return nil, fmt.Errorf("cancelReplaceMode value %v is invalid", cancelReplaceMode)
+
+	}
+	// END TEMPLATE check-valid-values
+
+	// assign parameter of cancelReplaceMode
+	params["cancelReplaceMode"] = cancelReplaceMode
+	// check timeInForce field -> json key timeInForce
+	if c.timeInForce != nil {
+		timeInForce := *c.timeInForce
+
+		// assign parameter of timeInForce
+		params["timeInForce"] = timeInForce
+	} else {
+	}
+	// check quantity field -> json key quantity
+	if c.quantity != nil {
+		quantity := *c.quantity
+
+		// assign parameter of quantity
+		params["quantity"] = quantity
+	} else {
+	}
+	// check price field -> json key price
+	if c.price != nil {
+		price := *c.price
+
+		// assign parameter of price
+		params["price"] = price
+	} else {
+	}
+	// check stopPrice field -> json key stopPrice
+	if c.stopPrice != nil {
+		stopPrice := *c.stopPrice
+
+		// assign parameter of stopPrice
+		params["stopPrice"] = stopPrice
+	} else {
+	}
+	// check cancelOrderId field -> json key cancelOrderId
+	if c.cancelOrderId != nil {
+		cancelOrderId := *c.cancelOrderId
+
+		// assign parameter of cancelOrderId
+		params["cancelOrderId"] = cancelOrderId
+	} else {
+	}
+	// check cancelOrigClientOrderId field -> json key cancelOrigClientOrderId
+	if c.cancelOrigClientOrderId != nil {
+		cancelOrigClientOrderId := *c.cancelOrigClientOrderId
+
+		// assign parameter of cancelOrigClientOrderId
+		params["cancelOrigClientOrderId"] = cancelOrigClientOrderId
+	} else {
+	}
+	// check newClientOrderId field -> json key newClientOrderId
+	if c.newClientOrderId != nil {
+		newClientOrderId := *c.newClientOrderId
+
+		// assign parameter of newClientOrderId
+		params["newClientOrderId"] = newClientOrderId
+	} else {
+	}
+	// check newOrderRespType field -> json key newOrderRespType
+	if c.newOrderRespType != nil {
+		newOrderRespType := *c.newOrderRespType
+
+		// assign parameter of newOrderRespType
+		params["newOrderRespType"] = newOrderRespType
+	} else {
+	}
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (c *CancelReplaceSpotOrderRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := c.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if c.isVarSlice(_v) {
+			c.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (c *CancelReplaceSpotOrderRequest) GetParametersJSON() ([]byte, error) {
+	params, err := c.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (c *CancelReplaceSpotOrderRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (c *CancelReplaceSpotOrderRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (c *CancelReplaceSpotOrderRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (c *CancelReplaceSpotOrderRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (c *CancelReplaceSpotOrderRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := c.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+func (c *CancelReplaceSpotOrderRequest) Do(ctx context.Context) (*CancelReplaceSpotOrderResponse, error) {
+
+	params, err := c.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	apiURL := "/api/v3/order/cancelReplace"
+
+	req, err := c.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse CancelReplaceSpotOrderResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+	return &apiResponse, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
@@ -25,7 +26,15 @@ const SandboxRestBaseURL = "https://testnet.binance.vision"
 const DebugRequestResponse = false
 
 var DefaultHttpClient = &http.Client{
-	Timeout: defaultHTTPTimeout,
+	Timeout:   defaultHTTPTimeout,
+	Transport: ratelimit.NewTransport(SpotLimiter, nil),
+}
+
+// FuturesHttpClient is the http client of the futures endpoints,
+// the futures endpoints have their own rate limits so a different limiter is used.
+var FuturesHttpClient = &http.Client{
+	Timeout:   defaultHTTPTimeout,
+	Transport: ratelimit.NewTransport(FuturesLimiter, nil),
 }
 
 type RestClient struct {
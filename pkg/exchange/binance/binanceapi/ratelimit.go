@@ -0,0 +1,123 @@
+package binanceapi
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+// SpotLimiter tracks the request weights of the spot and the margin (sapi) endpoints,
+// the limits are set lower than the ones of the /api/v3/exchangeInfo rateLimits,
+// so that the requests are queued before the api key or the ip is banned.
+var SpotLimiter = ratelimit.Register(ratelimit.New("binance",
+	ratelimit.Rule{
+		Name:          "request_weight",
+		Limit:         1100,
+		Interval:      time.Minute,
+		PathPrefix:    "/api/",
+		DefaultWeight: 1,
+		UsedHeader:    "X-Mbx-Used-Weight-1m",
+	},
+	ratelimit.Rule{
+		Name:       "orders",
+		Limit:      45,
+		Interval:   10 * time.Second,
+		UsedHeader: "X-Mbx-Order-Count-10s",
+	},
+	ratelimit.Rule{
+		Name:          "sapi_ip_weight",
+		Limit:         11000,
+		Interval:      time.Minute,
+		PathPrefix:    "/sapi/",
+		DefaultWeight: 1,
+		UsedHeader:    "X-Sapi-Used-Ip-Weight-1m",
+	},
+))
+
+// FuturesLimiter tracks the request weights of the usdt-m futures endpoints
+var FuturesLimiter = ratelimit.Register(ratelimit.New("binance_futures",
+	ratelimit.Rule{
+		Name:          "request_weight",
+		Limit:         2200,
+		Interval:      time.Minute,
+		PathPrefix:    "/fapi/",
+		DefaultWeight: 1,
+		UsedHeader:    "X-Mbx-Used-Weight-1m",
+	},
+	ratelimit.Rule{
+		Name:       "orders",
+		Limit:      1100,
+		Interval:   time.Minute,
+		PathPrefix: "/fapi/",
+		UsedHeader: "X-Mbx-Order-Count-1m",
+	},
+))
+
+// symbolWeight returns the weight by whether the symbol parameter is given,
+// e.g., querying the open orders of all the symbols is much heavier than querying one symbol.
+func symbolWeight(rule string, withSymbol, withoutSymbol int) ratelimit.WeightFunc {
+	return func(params url.Values) ratelimit.Weight {
+		if params.Get("symbol") != "" {
+			return ratelimit.Weight{rule: withSymbol}
+		}
+
+		return ratelimit.Weight{rule: withoutSymbol}
+	}
+}
+
+// depthWeight returns the weight of the order book query by the limit parameter
+func depthWeight(rule string, steps []int, weights []int) ratelimit.WeightFunc {
+	return func(params url.Values) ratelimit.Weight {
+		limit, err := strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			// the default limit is 100 for spot and 500 for futures, use the heaviest weight if it's not given
+			return ratelimit.Weight{rule: weights[len(weights)-1]}
+		}
+
+		for i, step := range steps {
+			if limit <= step {
+				return ratelimit.Weight{rule: weights[i]}
+			}
+		}
+
+		return ratelimit.Weight{rule: weights[len(weights)-1]}
+	}
+}
+
+func init() {
+	SpotLimiter.RegisterWeightFunc("GET /api/v3/openOrders", symbolWeight("request_weight", 3, 40))
+	SpotLimiter.RegisterWeightFunc("GET /api/v3/ticker/24hr", symbolWeight("request_weight", 1, 40))
+	SpotLimiter.RegisterWeightFunc("GET /api/v3/depth", depthWeight("request_weight", []int{100, 500, 1000}, []int{1, 5, 10, 50}))
+	SpotLimiter.RegisterWeight("GET /api/v3/order", ratelimit.Weight{"request_weight": 2})
+	SpotLimiter.RegisterWeight("POST /api/v3/order", ratelimit.Weight{"orders": 1})
+	SpotLimiter.RegisterWeight("POST /api/v3/order/oco", ratelimit.Weight{"orders": 2})
+	SpotLimiter.RegisterWeight("POST /api/v3/order/cancelReplace", ratelimit.Weight{"orders": 1})
+	SpotLimiter.RegisterWeight("GET /api/v3/allOrders", ratelimit.Weight{"request_weight": 10})
+	SpotLimiter.RegisterWeight("GET /api/v3/myTrades", ratelimit.Weight{"request_weight": 10})
+	SpotLimiter.RegisterWeight("GET /api/v3/account", ratelimit.Weight{"request_weight": 10})
+	SpotLimiter.RegisterWeight("GET /api/v3/exchangeInfo", ratelimit.Weight{"request_weight": 10})
+
+	SpotLimiter.RegisterWeight("POST /sapi/v1/margin/order", ratelimit.Weight{"orders": 1})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/account", ratelimit.Weight{"sapi_ip_weight": 10})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/isolated/account", ratelimit.Weight{"sapi_ip_weight": 10})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/openOrders", ratelimit.Weight{"sapi_ip_weight": 10})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/myTrades", ratelimit.Weight{"sapi_ip_weight": 10})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/order", ratelimit.Weight{"sapi_ip_weight": 10})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/allOrders", ratelimit.Weight{"sapi_ip_weight": 200})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/maxBorrowable", ratelimit.Weight{"sapi_ip_weight": 50})
+	SpotLimiter.RegisterWeight("GET /sapi/v1/margin/interestRateHistory", ratelimit.Weight{"sapi_ip_weight": 1})
+
+	FuturesLimiter.RegisterWeightFunc("GET /fapi/v1/openOrders", symbolWeight("request_weight", 1, 40))
+	FuturesLimiter.RegisterWeightFunc("GET /fapi/v1/ticker/24hr", symbolWeight("request_weight", 1, 40))
+	FuturesLimiter.RegisterWeightFunc("GET /fapi/v1/depth", depthWeight("request_weight", []int{50, 100, 500, 1000}, []int{2, 5, 10, 20}))
+	FuturesLimiter.RegisterWeight("POST /fapi/v1/order", ratelimit.Weight{"orders": 1})
+	FuturesLimiter.RegisterWeight("POST /fapi/v1/batchOrders", ratelimit.Weight{"request_weight": 5, "orders": 5})
+	FuturesLimiter.RegisterWeight("GET /fapi/v1/allOrders", ratelimit.Weight{"request_weight": 5})
+	FuturesLimiter.RegisterWeight("GET /fapi/v1/userTrades", ratelimit.Weight{"request_weight": 5})
+	FuturesLimiter.RegisterWeight("GET /fapi/v2/account", ratelimit.Weight{"request_weight": 5})
+	FuturesLimiter.RegisterWeight("GET /fapi/v2/balance", ratelimit.Weight{"request_weight": 5})
+	FuturesLimiter.RegisterWeight("GET /fapi/v2/positionRisk", ratelimit.Weight{"request_weight": 5})
+	FuturesLimiter.RegisterWeight("GET /fapi/v1/income", ratelimit.Weight{"request_weight": 30})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/pkg/errors"
 
+	"github.com/c9s/bbgo/pkg/exchange/binance/binanceapi"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -177,10 +178,77 @@ func toGlobalOrder(binanceOrder *binance.Order, isMargin bool) (*types.Order, er
 	}, nil
 }
 
+func toGlobalCancelReplaceOrder(result *binanceapi.CancelReplaceOrderResult) *types.Order {
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: result.ClientOrderID,
+			Symbol:        result.Symbol,
+			Side:          toGlobalSideType(binance.SideType(result.Side)),
+			Type:          toGlobalOrderType(binance.OrderType(result.Type)),
+			Quantity:      result.OrigQty,
+			Price:         result.Price,
+			TimeInForce:   types.TimeInForce(result.TimeInForce),
+		},
+		Exchange:         types.ExchangeBinance,
+		IsWorking:        true,
+		OrderID:          result.OrderID,
+		Status:           toGlobalOrderStatus(binance.OrderStatusType(result.Status)),
+		ExecutedQuantity: result.ExecutedQty,
+		CreationTime:     types.Time(result.TransactTime.Time()),
+		UpdateTime:       types.Time(result.TransactTime.Time()),
+	}
+}
+
+// toGlobalCanceledOrder updates the canceled order by the cancel response of the cancel-replace request,
+// the client order ID of the cancel response is the ID of the cancel request, so the order is copied from the replaced order.
+func toGlobalCanceledOrder(order types.Order, result *binanceapi.CancelReplaceOrderResult) *types.Order {
+	order.Status = types.OrderStatusCanceled
+	order.IsWorking = false
+	if result != nil {
+		order.Status = toGlobalOrderStatus(binance.OrderStatusType(result.Status))
+		order.ExecutedQuantity = result.ExecutedQty
+		if !result.TransactTime.Time().IsZero() {
+			order.UpdateTime = types.Time(result.TransactTime.Time())
+		}
+	}
+	return &order
+}
+
 func millisecondTime(t int64) time.Time {
 	return time.Unix(0, t*int64(time.Millisecond))
 }
 
+// toGlobalAggTrade converts the aggregate trade to the market trade, the side is the taker side
+func toGlobalAggTrade(symbol string, t binance.AggTrade) (*types.Trade, error) {
+	price, err := fixedpoint.NewFromString(t.Price)
+	if err != nil {
+		return nil, errors.Wrapf(err, "price parse error, price: %+v", t.Price)
+	}
+
+	quantity, err := fixedpoint.NewFromString(t.Quantity)
+	if err != nil {
+		return nil, errors.Wrapf(err, "quantity parse error, quantity: %+v", t.Quantity)
+	}
+
+	side := types.SideTypeBuy
+	if t.IsBuyerMaker {
+		side = types.SideTypeSell
+	}
+
+	return &types.Trade{
+		ID:            uint64(t.AggTradeID),
+		Exchange:      types.ExchangeBinance,
+		Symbol:        symbol,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		Side:          side,
+		IsBuyer:       !t.IsBuyerMaker,
+		IsMaker:       t.IsBuyerMaker,
+		Time:          types.Time(millisecondTime(t.Timestamp)),
+	}, nil
+}
+
 func toGlobalTrade(t binance.TradeV3, isMargin bool) (*types.Trade, error) {
 	// skip trade ID that is the same. however this should not happen
 	var side types.SideType
@@ -2,6 +2,7 @@ package binance
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
@@ -40,12 +41,18 @@ func toGlobalFuturesBalance(balances []*futures.Balance) types.BalanceMap {
 func toGlobalFuturesPositions(futuresPositions []*futures.AccountPosition) types.FuturesPositionMap {
 	retFuturesPositions := make(types.FuturesPositionMap)
 	for _, futuresPosition := range futuresPositions {
-		retFuturesPositions[futuresPosition.Symbol] = types.FuturesPosition{ // TODO: types.FuturesPosition
-			Isolated: futuresPosition.Isolated,
+		base := fixedpoint.MustNewFromString(futuresPosition.PositionAmt)
+		averageCost := fixedpoint.MustNewFromString(futuresPosition.EntryPrice)
+		retFuturesPositions[futuresPosition.Symbol] = &types.FuturesPosition{
+			Symbol:      futuresPosition.Symbol,
+			Base:        base,
+			Quote:       base.Neg().Mul(averageCost),
+			AverageCost: averageCost,
+			Isolated:    futuresPosition.Isolated,
 			PositionRisk: &types.PositionRisk{
-				Leverage: fixedpoint.MustNewFromString(futuresPosition.Leverage),
+				Leverage:         fixedpoint.MustNewFromString(futuresPosition.Leverage),
+				UnrealizedProfit: fixedpoint.MustNewFromString(futuresPosition.UnrealizedProfit),
 			},
-			Symbol:     futuresPosition.Symbol,
 			UpdateTime: futuresPosition.UpdateTime,
 		}
 	}
@@ -53,6 +60,93 @@ func toGlobalFuturesPositions(futuresPositions []*futures.AccountPosition) types
 	return retFuturesPositions
 }
 
+// errFuturesHedgeMode is returned when the futures account is in the hedge (dual side) position mode,
+// the session keeps one position per symbol, so only the one-way position mode is supported.
+var errFuturesHedgeMode = errors.New("binance futures hedge mode (dual side position) is not supported, please switch to the one-way position mode")
+
+// isOneWayPositionSide returns true if the position side is the position of the one-way position mode
+func isOneWayPositionSide(side string) bool {
+	return side == "" || side == string(futures.PositionSideTypeBoth)
+}
+
+func toGlobalFuturesPositionRisks(risks []*futures.PositionRisk) (types.FuturesPositionMap, error) {
+	retFuturesPositions := make(types.FuturesPositionMap)
+	for _, risk := range risks {
+		// in the hedge mode, the LONG and the SHORT positions of the same symbol are returned
+		if !isOneWayPositionSide(risk.PositionSide) {
+			return nil, errFuturesHedgeMode
+		}
+
+		base, err := fixedpoint.NewFromString(risk.PositionAmt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "position amount parse error, positionAmt: %+v", risk.PositionAmt)
+		}
+
+		averageCost, err := fixedpoint.NewFromString(risk.EntryPrice)
+		if err != nil {
+			return nil, errors.Wrapf(err, "entry price parse error, entryPrice: %+v", risk.EntryPrice)
+		}
+
+		positionRisk, err := convertPositionRisk(risk)
+		if err != nil {
+			return nil, err
+		}
+
+		retFuturesPositions[risk.Symbol] = &types.FuturesPosition{
+			Symbol:       risk.Symbol,
+			Base:         base,
+			Quote:        base.Neg().Mul(averageCost),
+			AverageCost:  averageCost,
+			Isolated:     strings.EqualFold(risk.MarginType, string(types.MarginTypeIsolated)),
+			PositionRisk: positionRisk,
+		}
+	}
+
+	return retFuturesPositions, nil
+}
+
+// toGlobalFuturesWsPositions converts the positions of the ACCOUNT_UPDATE event,
+// the event only contains the positions changed by the event.
+func toGlobalFuturesWsPositions(positions []futures.WsPosition, updateTime int64) types.FuturesPositionMap {
+	retFuturesPositions := make(types.FuturesPositionMap)
+	for _, position := range positions {
+		if !isOneWayPositionSide(string(position.Side)) {
+			log.Warnf("futures position %s %s is ignored: %v", position.Symbol, position.Side, errFuturesHedgeMode)
+			continue
+		}
+
+		base := fixedpoint.MustNewFromString(position.Amount)
+		averageCost := fixedpoint.MustNewFromString(position.EntryPrice)
+		retFuturesPositions[position.Symbol] = &types.FuturesPosition{
+			Symbol:      position.Symbol,
+			Base:        base,
+			Quote:       base.Neg().Mul(averageCost),
+			AverageCost: averageCost,
+			Isolated:    strings.EqualFold(string(position.MarginType), string(types.MarginTypeIsolated)),
+			PositionRisk: &types.PositionRisk{
+				MarkPrice:        fixedpoint.MustNewFromString(position.MarkPrice),
+				UnrealizedProfit: fixedpoint.MustNewFromString(position.UnrealizedPnL),
+			},
+			UpdateTime: updateTime,
+		}
+	}
+
+	return retFuturesPositions
+}
+
+// toGlobalFuturesWsBalances converts the balances of the ACCOUNT_UPDATE event,
+// the event does not contain the available balance, the cross wallet balance is used instead.
+func toGlobalFuturesWsBalances(balances []futures.WsBalance) types.BalanceMap {
+	retBalances := make(types.BalanceMap)
+	for _, balance := range balances {
+		retBalances[balance.Asset] = types.Balance{
+			Currency:  balance.Asset,
+			Available: fixedpoint.MustNewFromString(balance.CrossWalletBalance),
+		}
+	}
+	return retBalances
+}
+
 func toGlobalFuturesUserAssets(assets []*futures.AccountAsset) (retAssets types.FuturesAssetMap) {
 	retFuturesAssets := make(types.FuturesAssetMap)
 	for _, futuresAsset := range assets {
@@ -75,17 +169,15 @@ func toGlobalFuturesUserAssets(assets []*futures.AccountAsset) (retAssets types.
 func toLocalFuturesOrderType(orderType types.OrderType) (futures.OrderType, error) {
 	switch orderType {
 
-	// case types.OrderTypeLimitMaker:
-	// 	return futures.OrderTypeLimitMaker, nil //TODO
-
+	// the limit maker order is a limit order with the GTX (post only) time in force
 	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
 		return futures.OrderTypeLimit, nil
 
-	// case types.OrderTypeStopLimit:
-	// 	return futures.OrderTypeStopLossLimit, nil //TODO
+	case types.OrderTypeStopLimit:
+		return futures.OrderTypeStop, nil
 
-	// case types.OrderTypeStopMarket:
-	// 	return futures.OrderTypeStopLoss, nil //TODO
+	case types.OrderTypeStopMarket:
+		return futures.OrderTypeStopMarket, nil
 
 	case types.OrderTypeMarket:
 		return futures.OrderTypeMarket, nil
@@ -94,6 +186,18 @@ func toLocalFuturesOrderType(orderType types.OrderType) (futures.OrderType, erro
 	return "", fmt.Errorf("can not convert to local order, order type %s not supported", orderType)
 }
 
+func toLocalFuturesMarginType(marginType types.MarginType) (futures.MarginType, error) {
+	switch marginType {
+	case types.MarginTypeIsolated:
+		return futures.MarginTypeIsolated, nil
+
+	case types.MarginTypeCross:
+		return futures.MarginTypeCrossed, nil
+	}
+
+	return "", fmt.Errorf("can not convert to local margin type, margin type %q not supported", marginType)
+}
+
 func toGlobalFuturesOrders(futuresOrders []*futures.Order) (orders []types.Order, err error) {
 	for _, futuresOrder := range futuresOrders {
 		order, err := toGlobalFuturesOrder(futuresOrder, false)
@@ -114,15 +218,18 @@ func toGlobalFuturesOrder(futuresOrder *futures.Order, isMargin bool) (*types.Or
 			Symbol:        futuresOrder.Symbol,
 			Side:          toGlobalFuturesSideType(futuresOrder.Side),
 			Type:          toGlobalFuturesOrderType(futuresOrder.Type),
+			IsFutures:     true,
 			ReduceOnly:    futuresOrder.ReduceOnly,
 			ClosePosition: futuresOrder.ClosePosition,
 			Quantity:      fixedpoint.MustNewFromString(futuresOrder.OrigQuantity),
 			Price:         fixedpoint.MustNewFromString(futuresOrder.Price),
+			StopPrice:     fixedpoint.MustNewFromString(futuresOrder.StopPrice),
 			TimeInForce:   types.TimeInForce(futuresOrder.TimeInForce),
 		},
 		Exchange:         types.ExchangeBinance,
 		OrderID:          uint64(futuresOrder.OrderID),
 		Status:           toGlobalFuturesOrderStatus(futuresOrder.Status),
+		IsWorking:        futuresOrder.Status == futures.OrderStatusTypeNew || futuresOrder.Status == futures.OrderStatusTypePartiallyFilled,
 		ExecutedQuantity: fixedpoint.MustNewFromString(futuresOrder.ExecutedQuantity),
 		CreationTime:     types.Time(millisecondTime(futuresOrder.Time)),
 		UpdateTime:       types.Time(millisecondTime(futuresOrder.UpdateTime)),
@@ -198,18 +305,17 @@ func toGlobalFuturesSideType(side futures.SideType) types.SideType {
 
 func toGlobalFuturesOrderType(orderType futures.OrderType) types.OrderType {
 	switch orderType {
-	// TODO
-	case futures.OrderTypeLimit: // , futures.OrderTypeLimitMaker, futures.OrderTypeTakeProfitLimit:
+	case futures.OrderTypeLimit:
 		return types.OrderTypeLimit
 
 	case futures.OrderTypeMarket:
 		return types.OrderTypeMarket
-	// TODO
-	// case futures.OrderTypeStopLossLimit:
-	// 	return types.OrderTypeStopLimit
-	// TODO
-	// case futures.OrderTypeStopLoss:
-	// 	return types.OrderTypeStopMarket
+
+	case futures.OrderTypeStop, futures.OrderTypeTakeProfit:
+		return types.OrderTypeStopLimit
+
+	case futures.OrderTypeStopMarket, futures.OrderTypeTakeProfitMarket, futures.OrderTypeTrailingStopMarket:
+		return types.OrderTypeStopMarket
 
 	default:
 		log.Errorf("unsupported order type: %v", orderType)
@@ -272,8 +378,36 @@ func convertPositionRisk(risk *futures.PositionRisk) (*types.PositionRisk, error
 		return nil, err
 	}
 
+	markPrice, err := fixedpoint.NewFromString(risk.MarkPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	unrealizedProfit, err := fixedpoint.NewFromString(risk.UnRealizedProfit)
+	if err != nil {
+		return nil, err
+	}
+
 	return &types.PositionRisk{
 		Leverage:         leverage,
 		LiquidationPrice: liquidationPrice,
+		MarkPrice:        markPrice,
+		UnrealizedProfit: unrealizedProfit,
+	}, nil
+}
+
+func toGlobalFundingFee(income *futures.IncomeHistory) (*types.FundingFee, error) {
+	amount, err := fixedpoint.NewFromString(income.Income)
+	if err != nil {
+		return nil, errors.Wrapf(err, "income parse error, income: %+v", income.Income)
+	}
+
+	return &types.FundingFee{
+		Exchange:      types.ExchangeBinance,
+		Symbol:        income.Symbol,
+		Asset:         income.Asset,
+		Amount:        amount,
+		TransactionID: income.TranID,
+		Time:          types.Time(millisecondTime(income.Time)),
 	}, nil
 }
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/adshao/go-binance/v2/common"
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/spf13/viper"
 
@@ -80,17 +81,52 @@ type Exchange struct {
 
 	// client2 is a newer version of the binance api client implemented by ourselves.
 	client2 *binanceapi.RestClient
+
+	// webSocketURL and futuresWebSocketURL are the base urls of the websocket streams
+	webSocketURL        string
+	futuresWebSocketURL string
+}
+
+// Option overrides the default settings of the exchange
+type Option func(e *Exchange)
+
+// WithBaseURL overrides the spot & margin REST API base url, e.g., http://localhost:8081 for the mock exchange server
+func WithBaseURL(url string) Option {
+	return func(e *Exchange) {
+		e.client.BaseURL = url
+	}
+}
+
+// WithFuturesBaseURL overrides the futures REST API base url
+func WithFuturesBaseURL(url string) Option {
+	return func(e *Exchange) {
+		e.futuresClient.BaseURL = url
+	}
+}
+
+// WithWebSocketURL overrides the spot & margin websocket base url, e.g., ws://localhost:8081
+func WithWebSocketURL(url string) Option {
+	return func(e *Exchange) {
+		e.webSocketURL = url
+	}
+}
+
+// WithFuturesWebSocketURL overrides the futures websocket base url
+func WithFuturesWebSocketURL(url string) Option {
+	return func(e *Exchange) {
+		e.futuresWebSocketURL = url
+	}
 }
 
 var timeSetter sync.Once
 
-func New(key, secret string) *Exchange {
+func New(key, secret string, options ...Option) *Exchange {
 	var client = binance.NewClient(key, secret)
 	client.HTTPClient = binanceapi.DefaultHttpClient
 	client.Debug = viper.GetBool("debug-binance-client")
 
 	var futuresClient = binance.NewFuturesClient(key, secret)
-	futuresClient.HTTPClient = binanceapi.DefaultHttpClient
+	futuresClient.HTTPClient = binanceapi.FuturesHttpClient
 	futuresClient.Debug = viper.GetBool("debug-binance-futures-client")
 
 	if isBinanceUs() {
@@ -102,7 +138,35 @@ func New(key, secret string) *Exchange {
 		futuresClient.BaseURL = FutureTestBaseURL
 	}
 
+	ex := &Exchange{
+		key: key,
+		// pragma: allowlist nextline secret
+		secret:              secret,
+		client:              client,
+		futuresClient:       futuresClient,
+		webSocketURL:        WebSocketURL,
+		futuresWebSocketURL: FuturesWebSocketURL,
+	}
+
+	if isBinanceUs() {
+		ex.webSocketURL = BinanceUSWebSocketURL
+	}
+
+	// BINANCE_API_BASE_URL and BINANCE_WEBSOCKET_URL point the exchange to a compatible server, e.g., the mock exchange server
+	if v, ok := os.LookupEnv("BINANCE_API_BASE_URL"); ok && v != "" {
+		client.BaseURL = v
+	}
+
+	if v, ok := os.LookupEnv("BINANCE_WEBSOCKET_URL"); ok && v != "" {
+		ex.webSocketURL = v
+	}
+
+	for _, o := range options {
+		o(ex)
+	}
+
 	client2 := binanceapi.NewClient(client.BaseURL)
+	ex.client2 = client2
 
 	var err error
 	if len(key) > 0 && len(secret) > 0 {
@@ -121,14 +185,7 @@ func New(key, secret string) *Exchange {
 		})
 	}
 
-	return &Exchange{
-		key: key,
-		// pragma: allowlist nextline secret
-		secret:        secret,
-		client:        client,
-		futuresClient: futuresClient,
-		client2:       client2,
-	}
+	return ex
 }
 
 func (e *Exchange) Name() types.ExchangeName {
@@ -628,13 +685,7 @@ func (e *Exchange) QueryFuturesAccount(ctx context.Context) (*types.Account, err
 		return nil, err
 	}
 
-	var balances = map[string]types.Balance{}
-	for _, b := range accountBalances {
-		balances[b.Asset] = types.Balance{
-			Currency:  b.Asset,
-			Available: fixedpoint.Must(fixedpoint.NewFromString(b.AvailableBalance)),
-		}
-	}
+	balances := toGlobalFuturesBalance(accountBalances)
 
 	a := &types.Account{
 		AccountType: types.AccountTypeFutures,
@@ -701,6 +752,15 @@ func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.O
 		return nil, err
 	}
 
+	if e.IsFutures {
+		order, err := e.futuresClient.NewGetOrderService().Symbol(q.Symbol).OrderID(orderID).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return toGlobalFuturesOrder(order, false)
+	}
+
 	var order *binance.Order
 	if e.IsMargin {
 		order, err = e.client.NewGetMarginOrderService().Symbol(q.Symbol).OrderID(orderID).Do(ctx)
@@ -1020,7 +1080,10 @@ func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrd
 	}
 
 	// could be IOC or FOK
-	if len(order.TimeInForce) > 0 {
+	if order.Type == types.OrderTypeLimitMaker {
+		// GTX is the post only time in force of the futures orders
+		req.TimeInForce(futures.TimeInForceTypeGTX)
+	} else if len(order.TimeInForce) > 0 {
 		// TODO: check the TimeInForce value
 		req.TimeInForce(futures.TimeInForceType(order.TimeInForce))
 	} else {
@@ -1042,6 +1105,7 @@ func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrd
 		OrderID:          response.OrderID,
 		ClientOrderID:    response.ClientOrderID,
 		Price:            response.Price,
+		StopPrice:        response.StopPrice,
 		OrigQuantity:     response.OrigQuantity,
 		ExecutedQuantity: response.ExecutedQuantity,
 		Status:           response.Status,
@@ -1049,7 +1113,9 @@ func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrd
 		Type:             response.Type,
 		Side:             response.Side,
 		ReduceOnly:       response.ReduceOnly,
-	}, true)
+		ClosePosition:    response.ClosePosition,
+		UpdateTime:       response.UpdateTime,
+	}, false)
 
 	return createdOrder, err
 }
@@ -1197,6 +1263,87 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 	return createdOrder, err
 }
 
+// ReplaceOrder replaces the spot order via the cancel-replace endpoint, the new order is not placed if the cancel request fails.
+// The margin and the futures orders are not supported.
+func (e *Exchange) ReplaceOrder(ctx context.Context, order types.Order, newOrder types.SubmitOrder) (*types.Order, *types.Order, error) {
+	if e.IsMargin || e.IsFutures {
+		return nil, nil, types.ErrOrderReplaceNotSupported
+	}
+
+	if order.Symbol != newOrder.Symbol {
+		return nil, nil, fmt.Errorf("can not replace the %s order %d with a %s order", order.Symbol, order.OrderID, newOrder.Symbol)
+	}
+
+	orderType, err := toLocalOrderType(newOrder.Type)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := e.client2.NewCancelReplaceSpotOrderRequest().
+		Symbol(newOrder.Symbol).
+		Side(string(newOrder.Side)).
+		OrderType(string(orderType)).
+		CancelOrderId(order.OrderID)
+
+	clientOrderID := newSpotClientOrderID(newOrder.ClientOrderID)
+	if len(clientOrderID) > 0 {
+		req.NewClientOrderId(clientOrderID)
+	}
+
+	if newOrder.Market.Symbol != "" {
+		req.Quantity(newOrder.Market.FormatQuantity(newOrder.Quantity))
+	} else {
+		req.Quantity(newOrder.Quantity.FormatString(8))
+	}
+
+	switch newOrder.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		if newOrder.Market.Symbol != "" {
+			req.Price(newOrder.Market.FormatPrice(newOrder.Price))
+		} else {
+			req.Price(newOrder.Price.FormatString(8))
+		}
+	}
+
+	switch newOrder.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		if newOrder.Market.Symbol != "" {
+			req.StopPrice(newOrder.Market.FormatPrice(newOrder.StopPrice))
+		} else {
+			req.StopPrice(newOrder.StopPrice.FormatString(8))
+		}
+	}
+
+	if len(newOrder.TimeInForce) > 0 {
+		req.TimeInForce(string(newOrder.TimeInForce))
+	} else {
+		switch newOrder.Type {
+		case types.OrderTypeLimit, types.OrderTypeStopLimit:
+			req.TimeInForce(string(binance.TimeInForceTypeGTC))
+		}
+	}
+
+	req.NewOrderRespType(string(binance.NewOrderRespTypeRESULT))
+
+	if err := orderLimiter.Wait(ctx); err != nil {
+		log.WithError(err).Errorf("order rate limiter wait error")
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Infof("spot order cancel-replace response: %+v", response)
+
+	if response.NewOrderResponse == nil {
+		return nil, nil, fmt.Errorf("cancel-replace of order %d failed, cancel result: %s, new order result: %s",
+			order.OrderID, response.CancelResult, response.NewOrderResult)
+	}
+
+	return toGlobalCanceledOrder(order, response.CancelResponse), toGlobalCancelReplaceOrder(response.NewOrderResponse), nil
+}
+
 func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
 	for _, order := range orders {
 		if err := orderLimiter.Wait(ctx); err != nil {
@@ -1501,6 +1648,89 @@ func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *type
 	}
 }
 
+// QueryMarketTrades queries the public aggregate trades of the symbol,
+// binance only allows 1 hour time range when the start time and end time are given.
+func (e *Exchange) QueryMarketTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	var aggTrades []binance.AggTrade
+
+	if e.IsFutures {
+		req := e.futuresClient.NewAggTradesService().Symbol(symbol)
+		if options.Limit > 0 {
+			req.Limit(int(options.Limit))
+		} else {
+			req.Limit(1000)
+		}
+
+		if options.LastTradeID > 0 {
+			req.FromID(int64(options.LastTradeID))
+		} else if options.StartTime != nil {
+			req.StartTime(options.StartTime.UnixMilli())
+			req.EndTime(aggTradesEndTime(*options.StartTime, options.EndTime).UnixMilli())
+		}
+
+		futuresTrades, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range futuresTrades {
+			aggTrades = append(aggTrades, binance.AggTrade{
+				AggTradeID:   t.AggTradeID,
+				Price:        t.Price,
+				Quantity:     t.Quantity,
+				FirstTradeID: t.FirstTradeID,
+				LastTradeID:  t.LastTradeID,
+				Timestamp:    t.Timestamp,
+				IsBuyerMaker: t.IsBuyerMaker,
+			})
+		}
+	} else {
+		req := e.client.NewAggTradesService().Symbol(symbol)
+		if options.Limit > 0 {
+			req.Limit(int(options.Limit))
+		} else {
+			req.Limit(1000)
+		}
+
+		if options.LastTradeID > 0 {
+			req.FromID(int64(options.LastTradeID))
+		} else if options.StartTime != nil {
+			req.StartTime(options.StartTime.UnixMilli())
+			req.EndTime(aggTradesEndTime(*options.StartTime, options.EndTime).UnixMilli())
+		}
+
+		spotTrades, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range spotTrades {
+			aggTrades = append(aggTrades, *t)
+		}
+	}
+
+	for _, t := range aggTrades {
+		trade, err := toGlobalAggTrade(symbol, t)
+		if err != nil {
+			return nil, err
+		}
+
+		trade.IsFutures = e.IsFutures
+		trades = append(trades, *trade)
+	}
+
+	return trades, nil
+}
+
+func aggTradesEndTime(startTime time.Time, endTime *time.Time) time.Time {
+	maxEndTime := startTime.Add(time.Hour - time.Millisecond)
+	if endTime == nil || endTime.After(maxEndTime) {
+		return maxEndTime
+	}
+
+	return *endTime
+}
+
 // DefaultFeeRates returns the Binance VIP 0 fee schedule
 // See also https://www.binance.com/en/fee/schedule
 func (e *Exchange) DefaultFeeRates() types.ExchangeFee {
@@ -1611,6 +1841,106 @@ func (e *Exchange) QueryPositionRisk(ctx context.Context, symbol string) (*types
 	return convertPositionRisk(risks[0])
 }
 
+// QueryFuturesPositions returns the non-zero positions of the futures account, the positions are queried from the position risk api,
+// the mark price and the unrealized profit are included in the position risk.
+func (e *Exchange) QueryFuturesPositions(ctx context.Context) (types.FuturesPositionMap, error) {
+	risks, err := e.futuresClient.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := toGlobalFuturesPositionRisks(risks)
+	if err != nil {
+		return nil, err
+	}
+
+	for symbol, position := range positions {
+		if position.Base.IsZero() {
+			delete(positions, symbol)
+		}
+	}
+
+	return positions, nil
+}
+
+func (e *Exchange) SetFuturesLeverage(ctx context.Context, symbol string, leverage int) error {
+	res, err := e.futuresClient.NewChangeLeverageService().
+		Symbol(symbol).
+		Leverage(leverage).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("futures leverage of %s is changed to %d, max notional value: %s", res.Symbol, res.Leverage, res.MaxNotionalValue)
+	return nil
+}
+
+// noNeedToChangeMarginTypeErrorCode is returned when the margin type of the symbol is already the given one
+const noNeedToChangeMarginTypeErrorCode = -4046
+
+func (e *Exchange) SetFuturesMarginType(ctx context.Context, symbol string, marginType types.MarginType) error {
+	localMarginType, err := toLocalFuturesMarginType(marginType)
+	if err != nil {
+		return err
+	}
+
+	err = e.futuresClient.NewChangeMarginTypeService().
+		Symbol(symbol).
+		MarginType(localMarginType).
+		Do(ctx)
+	if err != nil {
+		if apiErr, ok := err.(*common.APIError); ok && apiErr.Code == noNeedToChangeMarginTypeErrorCode {
+			return nil
+		}
+
+		return err
+	}
+
+	log.Infof("futures margin type of %s is changed to %s", symbol, marginType)
+	return nil
+}
+
+// QueryFundingFeeHistory queries the funding fee incomes of the futures account,
+// the income history api returns at most 1000 records in the ascending order.
+func (e *Exchange) QueryFundingFeeHistory(ctx context.Context, symbol string, since, until time.Time) (fees []types.FundingFee, err error) {
+	const limit = 1000
+	startTime := since
+	for startTime.Before(until) {
+		req := e.futuresClient.NewGetIncomeHistoryService().
+			IncomeType("FUNDING_FEE").
+			StartTime(startTime.UnixMilli()).
+			EndTime(until.UnixMilli()).
+			Limit(limit)
+
+		if len(symbol) > 0 {
+			req.Symbol(symbol)
+		}
+
+		incomes, err := req.Do(ctx)
+		if err != nil {
+			return fees, err
+		}
+
+		for _, income := range incomes {
+			fee, err := toGlobalFundingFee(income)
+			if err != nil {
+				return fees, err
+			}
+
+			fees = append(fees, *fee)
+		}
+
+		if len(incomes) < limit {
+			break
+		}
+
+		startTime = millisecondTime(incomes[len(incomes)-1].Time).Add(time.Millisecond)
+	}
+
+	return fees, nil
+}
+
 func getLaunchDate() (time.Time, error) {
 	// binance launch date 12:00 July 14th, 2017
 	loc, err := time.LoadLocation("Asia/Shanghai")
@@ -709,8 +709,9 @@ type OrderTrade struct {
 	BidsNotional string `json:"b"`
 	AskNotional  string `json:"a"`
 
-	IsMaker      bool `json:"m"`
-	IsReduceOnly bool ` json:"r"`
+	IsMaker         bool `json:"m"`
+	IsReduceOnly    bool `json:"R"`
+	IsClosePosition bool `json:"cp"`
 
 	StopPriceWorkingType string `json:"wt"`
 	OriginalOrderType    string `json:"ot"`
@@ -778,6 +779,7 @@ func (e *OrderTradeUpdateEvent) OrderFutures() (*types.Order, error) {
 	}
 
 	orderCreationTime := time.Unix(0, e.OrderTrade.OrderTradeTime*int64(time.Millisecond))
+	status := toGlobalFuturesOrderStatus(futures.OrderStatusType(e.OrderTrade.CurrentOrderStatus))
 	return &types.Order{
 		Exchange: types.ExchangeBinance,
 		SubmitOrder: types.SubmitOrder{
@@ -787,12 +789,18 @@ func (e *OrderTradeUpdateEvent) OrderFutures() (*types.Order, error) {
 			Type:          toGlobalFuturesOrderType(futures.OrderType(e.OrderTrade.OrderType)),
 			Quantity:      e.OrderTrade.OriginalQuantity,
 			Price:         e.OrderTrade.OriginalPrice,
+			StopPrice:     e.OrderTrade.StopPrice,
 			TimeInForce:   types.TimeInForce(e.OrderTrade.TimeInForce),
+			IsFutures:     true,
+			ReduceOnly:    e.OrderTrade.IsReduceOnly,
+			ClosePosition: e.OrderTrade.IsClosePosition,
 		},
 		OrderID:          uint64(e.OrderTrade.OrderId),
-		Status:           toGlobalFuturesOrderStatus(futures.OrderStatusType(e.OrderTrade.CurrentOrderStatus)),
+		Status:           status,
 		ExecutedQuantity: e.OrderTrade.OrderFilledAccumulatedQuantity,
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
 		CreationTime:     types.Time(orderCreationTime),
+		UpdateTime:       types.Time(time.Unix(0, e.Transaction*int64(time.Millisecond))),
 	}, nil
 }
 
@@ -816,13 +824,16 @@ func (e *OrderTradeUpdateEvent) TradeFutures() (*types.Trade, error) {
 		Time:          types.Time(tt),
 		Fee:           e.OrderTrade.CommissionAmount,
 		FeeCurrency:   e.OrderTrade.CommissionAsset,
+		IsFutures:     true,
 	}, nil
 }
 
+// AccountUpdate uses the websocket balance and position of the futures user data stream,
+// the json keys are different from the rest api ones.
 type AccountUpdate struct {
-	EventReasonType string                     `json:"m"`
-	Balances        []*futures.Balance         `json:"B,omitempty"`
-	Positions       []*futures.AccountPosition `json:"P,omitempty"`
+	EventReasonType string               `json:"m"`
+	Balances        []futures.WsBalance  `json:"B,omitempty"`
+	Positions       []futures.WsPosition `json:"P,omitempty"`
 }
 
 type AccountUpdateEvent struct {
@@ -4,9 +4,11 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 var jsCommentTrimmer = regexp.MustCompile("(?m)//.*$")
@@ -409,4 +411,112 @@ func TestParseOrderFuturesUpdate(t *testing.T) {
 	orderUpdate, err := orderTradeEvent.OrderFutures()
 	assert.NoError(t, err)
 	assert.NotNil(t, orderUpdate)
+	assert.True(t, orderUpdate.IsFutures)
+	assert.False(t, orderUpdate.IsWorking)
+	assert.Equal(t, types.OrderStatusFilled, orderUpdate.Status)
+	assert.Equal(t, int64(1639933384755), orderUpdate.UpdateTime.Time().UnixMilli())
+
+	trade, err := orderTradeEvent.TradeFutures()
+	assert.NoError(t, err)
+	assert.True(t, trade.IsFutures)
+}
+
+func TestParseAccountUpdate(t *testing.T) {
+	payload := `{
+	  "e": "ACCOUNT_UPDATE",
+	  "T": 1639933384755,
+	  "E": 1639933384763,
+	  "a": {
+		"B": [
+		  {
+			"a": "USDT",
+			"wb": "86.94966888",
+			"cw": "86.94966888",
+			"bc": "0"
+		  }
+		],
+		"P": [
+		  {
+			"s": "BTCUSDT",
+			"pa": "-0.001",
+			"ep": "47202.40000",
+			"cr": "7.78107001",
+			"up": "-0.00233523",
+			"mt": "isolated",
+			"iw": "0",
+			"ps": "BOTH",
+			"ma": "USDT"
+		  }
+		],
+		"m": "ORDER"
+	  }
+	}`
+
+	event, err := parseWebSocketEvent([]byte(payload))
+	assert.NoError(t, err)
+
+	accountUpdateEvent, ok := event.(*AccountUpdateEvent)
+	if assert.True(t, ok) {
+		assert.Equal(t, "ORDER", accountUpdateEvent.AccountUpdate.EventReasonType)
+
+		positions := toGlobalFuturesWsPositions(accountUpdateEvent.AccountUpdate.Positions, accountUpdateEvent.Transaction)
+		if assert.Contains(t, positions, "BTCUSDT") {
+			position := positions["BTCUSDT"]
+			assert.Equal(t, fixedpoint.MustNewFromString("-0.001"), position.Base)
+			assert.Equal(t, fixedpoint.MustNewFromString("47202.4"), position.AverageCost)
+			assert.Equal(t, fixedpoint.MustNewFromString("47.2024"), position.Quote)
+			assert.Equal(t, fixedpoint.MustNewFromString("-0.00233523"), position.PositionRisk.UnrealizedProfit)
+			assert.True(t, position.Isolated)
+			assert.Equal(t, int64(1639933384755), position.UpdateTime)
+		}
+
+		balances := toGlobalFuturesWsBalances(accountUpdateEvent.AccountUpdate.Balances)
+		if assert.Contains(t, balances, "USDT") {
+			assert.Equal(t, fixedpoint.MustNewFromString("86.94966888"), balances["USDT"].Available)
+		}
+	}
+}
+
+func TestToGlobalFuturesWsPositions_HedgeMode(t *testing.T) {
+	positions := toGlobalFuturesWsPositions([]futures.WsPosition{
+		{Symbol: "BTCUSDT", Side: futures.PositionSideTypeLong, Amount: "0.001", EntryPrice: "47202.4", MarkPrice: "0", UnrealizedPnL: "0"},
+		{Symbol: "BTCUSDT", Side: futures.PositionSideTypeShort, Amount: "0", EntryPrice: "0", MarkPrice: "0", UnrealizedPnL: "0"},
+	}, 1639933384755)
+	assert.Empty(t, positions)
+}
+
+func TestToGlobalFuturesPositionRisks(t *testing.T) {
+	risk := func(symbol, side, amount, entryPrice string) *futures.PositionRisk {
+		return &futures.PositionRisk{
+			Symbol:           symbol,
+			PositionSide:     side,
+			PositionAmt:      amount,
+			EntryPrice:       entryPrice,
+			MarkPrice:        "47000",
+			Leverage:         "3",
+			LiquidationPrice: "0",
+			UnRealizedProfit: "0",
+			MarginType:       "cross",
+		}
+	}
+
+	t.Run("one-way mode", func(t *testing.T) {
+		positions, err := toGlobalFuturesPositionRisks([]*futures.PositionRisk{
+			risk("BTCUSDT", "BOTH", "-0.001", "47202.4"),
+			risk("ETHUSDT", "BOTH", "0", "0"),
+		})
+		if assert.NoError(t, err) && assert.Len(t, positions, 2) {
+			assert.Equal(t, fixedpoint.MustNewFromString("-0.001"), positions["BTCUSDT"].Base)
+			assert.Equal(t, fixedpoint.MustNewFromString("47.2024"), positions["BTCUSDT"].Quote)
+			assert.True(t, positions["ETHUSDT"].Base.IsZero())
+		}
+	})
+
+	t.Run("hedge mode", func(t *testing.T) {
+		_, err := toGlobalFuturesPositionRisks([]*futures.PositionRisk{
+			risk("BTCUSDT", "LONG", "0.001", "47202.4"),
+			risk("BTCUSDT", "SHORT", "0", "0"),
+		})
+		assert.ErrorIs(t, err, errFuturesHedgeMode)
+	})
 }
@@ -3,8 +3,11 @@ package binance
 import (
 	"context"
 	"net"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"github.com/c9s/bbgo/pkg/depth"
 	"github.com/c9s/bbgo/pkg/util"
 
@@ -25,6 +28,13 @@ import (
 // A JSON controlled message (e.g. subscribe, unsubscribe)
 const listenKeyKeepAliveInterval = 15 * time.Minute
 
+// queryFuturesPositionsTimeout is the timeout of the futures position snapshot query on connect
+const queryFuturesPositionsTimeout = 10 * time.Second
+
+// futuresPositionSnapshotInterval is the minimal interval of the futures position snapshot queries,
+// the stream may reconnect repeatedly when the network is unstable.
+const futuresPositionSnapshotInterval = 10 * time.Second
+
 type WebSocketCommand struct {
 	// request ID is required
 	ID     int      `json:"id"`
@@ -38,9 +48,18 @@ type Stream struct {
 	types.FuturesSettings
 	types.StandardStream
 
+	exchange      *Exchange
 	client        *binance.Client
 	futuresClient *futures.Client
 
+	webSocketURL        string
+	futuresWebSocketURL string
+
+	// positionSnapshotLimiter limits the futures position snapshot queries on reconnect,
+	// positionSnapshotPending is set when a snapshot query is waiting for the limiter or running
+	positionSnapshotLimiter *rate.Limiter
+	positionSnapshotPending int32
+
 	// custom callbacks
 	depthEventCallbacks       []func(e *DepthEvent)
 	kLineEventCallbacks       []func(e *KLineEvent)
@@ -68,9 +87,15 @@ type Stream struct {
 func NewStream(ex *Exchange, client *binance.Client, futuresClient *futures.Client) *Stream {
 	stream := &Stream{
 		StandardStream: types.NewStandardStream(),
+		exchange:       ex,
 		client:         client,
 		futuresClient:  futuresClient,
 		depthBuffers:   make(map[string]*depth.Buffer),
+
+		webSocketURL:        ex.webSocketURL,
+		futuresWebSocketURL: ex.futuresWebSocketURL,
+
+		positionSnapshotLimiter: rate.NewLimiter(rate.Every(futuresPositionSnapshotInterval), 1),
 	}
 
 	stream.SetParser(parseWebSocketEvent)
@@ -137,6 +162,9 @@ func (s *Stream) handleDisconnect() {
 
 func (s *Stream) handleConnect() {
 	if !s.PublicOnly {
+		if s.IsFutures {
+			go s.emitFuturesPositionSnapshot()
+		}
 		return
 	}
 
@@ -235,16 +263,50 @@ func (s *Stream) handleOutboundAccountPositionEvent(e *OutboundAccountPositionEv
 	s.EmitBalanceSnapshot(snapshot)
 }
 
+// emitFuturesPositionSnapshot queries the positions from the rest api,
+// the ACCOUNT_UPDATE event only pushes the positions changed after the connection is established.
+// It's called in a goroutine on connect, the query is skipped if another snapshot query is already pending.
+func (s *Stream) emitFuturesPositionSnapshot() {
+	if s.exchange == nil {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.positionSnapshotPending, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.positionSnapshotPending, 0)
+
+	if err := s.positionSnapshotLimiter.Wait(context.Background()); err != nil {
+		log.WithError(err).Error("futures position snapshot rate limiter wait error")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryFuturesPositionsTimeout)
+	defer cancel()
+
+	positions, err := s.exchange.QueryFuturesPositions(ctx)
+	if err != nil {
+		log.WithError(err).Error("can not query futures positions")
+		return
+	}
+
+	s.EmitFuturesPositionSnapshot(positions)
+}
+
 func (s *Stream) handleAccountUpdateEvent(e *AccountUpdateEvent) {
-	futuresPositionSnapshot := toGlobalFuturesPositions(e.AccountUpdate.Positions)
-	s.EmitFuturesPositionSnapshot(futuresPositionSnapshot)
+	if len(e.AccountUpdate.Positions) > 0 {
+		s.EmitFuturesPositionUpdate(toGlobalFuturesWsPositions(e.AccountUpdate.Positions, e.Transaction))
+	}
 
-	balanceSnapshot := toGlobalFuturesBalance(e.AccountUpdate.Balances)
-	s.EmitBalanceSnapshot(balanceSnapshot)
+	if len(e.AccountUpdate.Balances) > 0 {
+		s.EmitBalanceUpdate(toGlobalFuturesWsBalances(e.AccountUpdate.Balances))
+	}
 }
 
-// TODO: emit account config leverage updates
 func (s *Stream) handleAccountConfigUpdateEvent(e *AccountConfigUpdateEvent) {
+	if len(e.AccountConfig.Symbol) > 0 {
+		log.Infof("futures leverage of %s is updated to %s", e.AccountConfig.Symbol, e.AccountConfig.Leverage.String())
+	}
 }
 
 func (s *Stream) handleOrderTradeUpdateEvent(e *OrderTradeUpdateEvent) {
@@ -268,6 +330,15 @@ func (s *Stream) handleOrderTradeUpdateEvent(e *OrderTradeUpdateEvent) {
 
 		s.EmitTradeUpdate(*trade)
 
+		// update the executed quantity of the partially filled orders as well
+		order, err := e.OrderFutures()
+		if err != nil {
+			log.WithError(err).Error("futures order convert error")
+			return
+		}
+
+		s.EmitOrderUpdate(*order)
+
 	case "CALCULATED - Liquidation Execution":
 		log.Infof("CALCULATED - Liquidation Execution not support yet.")
 	}
@@ -278,11 +349,9 @@ func (s *Stream) getEndpointUrl(listenKey string) string {
 	var url string
 
 	if s.IsFutures {
-		url = FuturesWebSocketURL + "/ws"
-	} else if isBinanceUs() {
-		url = BinanceUSWebSocketURL + "/ws"
+		url = s.futuresWebSocketURL + "/ws"
 	} else {
-		url = WebSocketURL + "/ws"
+		url = s.webSocketURL + "/ws"
 	}
 
 	if !s.PublicOnly {
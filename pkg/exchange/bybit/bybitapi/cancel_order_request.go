@@ -0,0 +1,30 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type CancelOrderResponse struct {
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+}
+
+// CancelOrderRequest cancels the order by the order id or the order link id,
+// the order id takes precedence when both of them are given.
+//
+//go:generate requestgen -method POST -url "/v5/order/cancel" -type CancelOrderRequest -responseType .APIResponse -responseDataField Result -responseDataType .CancelOrderResponse
+type CancelOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category `param:"category" validValues:"spot"`
+	symbol      string   `param:"symbol,required"`
+	orderID     *string  `param:"orderId"`
+	orderLinkID *string  `param:"orderLinkId"`
+}
+
+func (c *RestClient) NewCancelOrderRequest() *CancelOrderRequest {
+	return &CancelOrderRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
@@ -0,0 +1,212 @@
+package bybitapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/requestgen"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+const defaultHTTPTimeout = time.Second * 15
+const RestBaseURL = "https://api.bybit.com"
+const TestnetRestBaseURL = "https://api-testnet.bybit.com"
+const PublicWebSocketURL = "wss://stream.bybit.com/v5/public/spot"
+const PrivateWebSocketURL = "wss://stream.bybit.com/v5/private"
+const DebugRequestResponse = false
+
+// defaultRecvWindow is the time window in milliseconds for the server to accept the signed request
+const defaultRecvWindow = 5000
+
+var DefaultHttpClient = &http.Client{
+	Timeout:   defaultHTTPTimeout,
+	Transport: ratelimit.NewTransport(Limiter, nil),
+}
+
+type RestClient struct {
+	requestgen.BaseAPIClient
+
+	Key, Secret string
+
+	recvWindow int
+}
+
+func NewClient(baseURL string) *RestClient {
+	if len(baseURL) == 0 {
+		baseURL = RestBaseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	return &RestClient{
+		BaseAPIClient: requestgen.BaseAPIClient{
+			BaseURL:    u,
+			HttpClient: DefaultHttpClient,
+		},
+		recvWindow: defaultRecvWindow,
+	}
+}
+
+func (c *RestClient) Auth(key, secret string) {
+	c.Key = key
+	// pragma: allowlist nextline secret
+	c.Secret = secret
+}
+
+// NewRequest create new API request. Relative url can be provided in refURL.
+func (c *RestClient) NewRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	body, err := castPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+	return http.NewRequestWithContext(ctx, method, pathURL.String(), bytes.NewReader(body))
+}
+
+// SendRequest sends the request and checks the return code of the response,
+// bybit responds the business errors with the 200 status code and a non-zero retCode.
+func (c *RestClient) SendRequest(req *http.Request) (*requestgen.Response, error) {
+	if DebugRequestResponse {
+		logrus.Debugf("-> request: %+v", req)
+	}
+
+	response, err := c.BaseAPIClient.SendRequest(req)
+	if err != nil {
+		return response, err
+	}
+
+	if DebugRequestResponse {
+		logrus.Debugf("<- response: %s", string(response.Body))
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return response, err
+	}
+
+	if apiResponse.RetCode != 0 {
+		return response, &APIError{Code: apiResponse.RetCode, Message: apiResponse.RetMsg}
+	}
+
+	return response, nil
+}
+
+// NewAuthenticatedRequest creates new http request for authenticated routes.
+//
+// The signature is the hex encoded HMAC-SHA256 of timestamp + api key + recv window + payload,
+// the payload is the query string for the GET requests and the JSON body for the POST requests.
+func (c *RestClient) NewAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	if len(c.Key) == 0 {
+		return nil, errors.New("empty api key")
+	}
+
+	if len(c.Secret) == 0 {
+		return nil, errors.New("empty api secret")
+	}
+
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	body, err := castPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	signPayload := rel.RawQuery
+	if method != http.MethodGet {
+		signPayload = string(body)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recvWindow := strconv.Itoa(c.recvWindow)
+	signature := Sign(c.Secret, timestamp+c.Key+recvWindow+signPayload)
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+	req, err := http.NewRequestWithContext(ctx, method, pathURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("X-BAPI-API-KEY", c.Key)
+	req.Header.Add("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Add("X-BAPI-RECV-WINDOW", recvWindow)
+	req.Header.Add("X-BAPI-SIGN", signature)
+	return req, nil
+}
+
+// Sign uses sha256 to sign the payload with the given secret
+func Sign(secret, payload string) string {
+	var sig = hmac.New(sha256.New, []byte(secret))
+	_, err := sig.Write([]byte(payload))
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(sig.Sum(nil))
+}
+
+func castPayload(payload interface{}) ([]byte, error) {
+	if payload == nil {
+		return nil, nil
+	}
+
+	switch v := payload.(type) {
+	case string:
+		return []byte(v), nil
+
+	case []byte:
+		return v, nil
+
+	}
+	return json.Marshal(payload)
+}
+
+type APIResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+	Time    int64           `json:"time"`
+}
+
+// APIError is the error of the non-zero return code
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bybit api error: retCode=%d, retMsg=%s", e.Code, e.Message)
+}
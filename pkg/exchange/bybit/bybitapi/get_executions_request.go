@@ -0,0 +1,62 @@
+package bybitapi
+
+import (
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ExecutionsResponse struct {
+	Category       Category    `json:"category"`
+	List           []Execution `json:"list"`
+	NextPageCursor string      `json:"nextPageCursor"`
+}
+
+type Execution struct {
+	Symbol      string           `json:"symbol"`
+	OrderID     string           `json:"orderId"`
+	OrderLinkID string           `json:"orderLinkId"`
+	Side        Side             `json:"side"`
+	OrderPrice  fixedpoint.Value `json:"orderPrice"`
+	OrderQty    fixedpoint.Value `json:"orderQty"`
+	LeavesQty   fixedpoint.Value `json:"leavesQty"`
+	OrderType   OrderType        `json:"orderType"`
+	ExecFee     fixedpoint.Value `json:"execFee"`
+	ExecID      string           `json:"execId"`
+	ExecPrice   fixedpoint.Value `json:"execPrice"`
+	ExecQty     fixedpoint.Value `json:"execQty"`
+	ExecType    string           `json:"execType"`
+	ExecValue   fixedpoint.Value `json:"execValue"`
+	FeeRate     fixedpoint.Value `json:"feeRate"`
+	IsMaker     bool             `json:"isMaker"`
+
+	ExecTime types.MillisecondTimestamp `json:"execTime"`
+}
+
+// GetExecutionsRequest queries the trades of the user, the time range between the start time and the end time
+// can not exceed 7 days, and the trades of the last 7 days are returned if the time range is not given.
+//
+//go:generate requestgen -method GET -url "/v5/execution/list" -type GetExecutionsRequest -responseType .APIResponse -responseDataField Result -responseDataType .ExecutionsResponse
+type GetExecutionsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category  Category   `param:"category" validValues:"spot"`
+	symbol    *string    `param:"symbol"`
+	orderID   *string    `param:"orderId"`
+	startTime *time.Time `param:"startTime,milliseconds"`
+	endTime   *time.Time `param:"endTime,milliseconds"`
+
+	// limit is the page size, the max value is 100 and the default is 50
+	limit  *uint64 `param:"limit"`
+	cursor *string `param:"cursor"`
+}
+
+func (c *RestClient) NewGetExecutionsRequest() *GetExecutionsRequest {
+	return &GetExecutionsRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
@@ -0,0 +1,50 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type InstrumentsInfo struct {
+	Category       Category     `json:"category"`
+	List           []Instrument `json:"list"`
+	NextPageCursor string       `json:"nextPageCursor"`
+}
+
+type Instrument struct {
+	Symbol        string `json:"symbol"`
+	BaseCoin      string `json:"baseCoin"`
+	QuoteCoin     string `json:"quoteCoin"`
+	Innovation    string `json:"innovation"`
+	Status        string `json:"status"`
+	MarginTrading string `json:"marginTrading"`
+	LotSizeFilter struct {
+		BasePrecision  fixedpoint.Value `json:"basePrecision"`
+		QuotePrecision fixedpoint.Value `json:"quotePrecision"`
+		MinOrderQty    fixedpoint.Value `json:"minOrderQty"`
+		MaxOrderQty    fixedpoint.Value `json:"maxOrderQty"`
+		MinOrderAmt    fixedpoint.Value `json:"minOrderAmt"`
+		MaxOrderAmt    fixedpoint.Value `json:"maxOrderAmt"`
+	} `json:"lotSizeFilter"`
+	PriceFilter struct {
+		TickSize fixedpoint.Value `json:"tickSize"`
+	} `json:"priceFilter"`
+}
+
+//go:generate requestgen -method GET -url "/v5/market/instruments-info" -type GetInstrumentsInfoRequest -responseType .APIResponse -responseDataField Result -responseDataType .InstrumentsInfo
+type GetInstrumentsInfoRequest struct {
+	client requestgen.APIClient
+
+	category Category `param:"category" validValues:"spot"`
+	symbol   *string  `param:"symbol"`
+	limit    *uint64  `param:"limit"`
+	cursor   *string  `param:"cursor"`
+}
+
+func (c *RestClient) NewGetInstrumentsInfoRequest() *GetInstrumentsInfoRequest {
+	return &GetInstrumentsInfoRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
@@ -0,0 +1,77 @@
+package bybitapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type KLinesResponse struct {
+	Symbol   string   `json:"symbol"`
+	Category Category `json:"category"`
+
+	// List is sorted in the reverse order by the start time
+	List []KLine `json:"list"`
+}
+
+type KLine struct {
+	StartTime types.MillisecondTimestamp
+	Open      fixedpoint.Value
+	High      fixedpoint.Value
+	Low       fixedpoint.Value
+	Close     fixedpoint.Value
+	Volume    fixedpoint.Value
+	Turnover  fixedpoint.Value
+}
+
+// UnmarshalJSON parses the kline array [startTime, openPrice, highPrice, lowPrice, closePrice, volume, turnover]
+func (k *KLine) UnmarshalJSON(data []byte) error {
+	var values []json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	if len(values) != 7 {
+		return fmt.Errorf("unexpected kline length: %d, data: %s", len(values), data)
+	}
+
+	if err := json.Unmarshal(values[0], &k.StartTime); err != nil {
+		return err
+	}
+
+	for i, v := range []*fixedpoint.Value{&k.Open, &k.High, &k.Low, &k.Close, &k.Volume, &k.Turnover} {
+		if err := json.Unmarshal(values[i+1], v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//go:generate requestgen -method GET -url "/v5/market/kline" -type GetKLinesRequest -responseType .APIResponse -responseDataField Result -responseDataType .KLinesResponse
+type GetKLinesRequest struct {
+	client requestgen.APIClient
+
+	category Category `param:"category" validValues:"spot"`
+	symbol   string   `param:"symbol"`
+
+	// interval is one of 1,3,5,15,30,60,120,240,360,720,D,M,W
+	interval  string     `param:"interval"`
+	startTime *time.Time `param:"start,milliseconds"`
+	endTime   *time.Time `param:"end,milliseconds"`
+
+	// limit is the number of the klines, the max value is 1000 and the default is 200
+	limit *uint64 `param:"limit"`
+}
+
+func (c *RestClient) NewGetKLinesRequest() *GetKLinesRequest {
+	return &GetKLinesRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
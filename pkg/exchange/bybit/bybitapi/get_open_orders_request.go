@@ -0,0 +1,64 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type OrdersResponse struct {
+	Category       Category `json:"category"`
+	List           []Order  `json:"list"`
+	NextPageCursor string   `json:"nextPageCursor"`
+}
+
+type Order struct {
+	OrderID      string           `json:"orderId"`
+	OrderLinkID  string           `json:"orderLinkId"`
+	Symbol       string           `json:"symbol"`
+	Price        fixedpoint.Value `json:"price"`
+	Qty          fixedpoint.Value `json:"qty"`
+	Side         Side             `json:"side"`
+	OrderStatus  OrderStatus      `json:"orderStatus"`
+	CancelType   string           `json:"cancelType"`
+	RejectReason string           `json:"rejectReason"`
+	AvgPrice     fixedpoint.Value `json:"avgPrice"`
+	LeavesQty    fixedpoint.Value `json:"leavesQty"`
+	LeavesValue  fixedpoint.Value `json:"leavesValue"`
+	CumExecQty   fixedpoint.Value `json:"cumExecQty"`
+	CumExecValue fixedpoint.Value `json:"cumExecValue"`
+	CumExecFee   fixedpoint.Value `json:"cumExecFee"`
+	TimeInForce  TimeInForce      `json:"timeInForce"`
+	OrderType    OrderType        `json:"orderType"`
+	TriggerPrice fixedpoint.Value `json:"triggerPrice"`
+
+	CreatedTime types.MillisecondTimestamp `json:"createdTime"`
+	UpdatedTime types.MillisecondTimestamp `json:"updatedTime"`
+}
+
+// GetOpenOrdersRequest queries the unfilled or partially filled orders,
+// the recently closed orders can also be queried by the order id or the order link id.
+//
+//go:generate requestgen -method GET -url "/v5/order/realtime" -type GetOpenOrdersRequest -responseType .APIResponse -responseDataField Result -responseDataType .OrdersResponse
+type GetOpenOrdersRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category `param:"category" validValues:"spot"`
+	symbol      *string  `param:"symbol"`
+	orderID     *string  `param:"orderId"`
+	orderLinkID *string  `param:"orderLinkId"`
+
+	// openOnly 0 returns the open orders only, the closed orders of the last 10 minutes are returned
+	// when the order id or the order link id is given
+	openOnly *int    `param:"openOnly"`
+	limit    *uint64 `param:"limit"`
+	cursor   *string `param:"cursor"`
+}
+
+func (c *RestClient) NewGetOpenOrdersRequest() *GetOpenOrdersRequest {
+	return &GetOpenOrdersRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
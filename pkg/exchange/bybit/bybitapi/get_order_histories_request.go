@@ -0,0 +1,34 @@
+package bybitapi
+
+import (
+	"time"
+
+	"github.com/c9s/requestgen"
+)
+
+// GetOrderHistoriesRequest queries the closed orders, the time range between the start time and the end time
+// can not exceed 7 days, and the orders of the last 7 days are returned if the time range is not given.
+//
+//go:generate requestgen -method GET -url "/v5/order/history" -type GetOrderHistoriesRequest -responseType .APIResponse -responseDataField Result -responseDataType .OrdersResponse
+type GetOrderHistoriesRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category    Category     `param:"category" validValues:"spot"`
+	symbol      *string      `param:"symbol"`
+	orderID     *string      `param:"orderId"`
+	orderLinkID *string      `param:"orderLinkId"`
+	orderStatus *OrderStatus `param:"orderStatus"`
+	startTime   *time.Time   `param:"startTime,milliseconds"`
+	endTime     *time.Time   `param:"endTime,milliseconds"`
+
+	// limit is the page size, the max value is 50 and the default is 20
+	limit  *uint64 `param:"limit"`
+	cursor *string `param:"cursor"`
+}
+
+func (c *RestClient) NewGetOrderHistoriesRequest() *GetOrderHistoriesRequest {
+	return &GetOrderHistoriesRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
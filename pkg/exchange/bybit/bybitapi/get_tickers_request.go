@@ -0,0 +1,43 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type Tickers struct {
+	Category Category `json:"category"`
+	List     []Ticker `json:"list"`
+}
+
+type Ticker struct {
+	Symbol        string           `json:"symbol"`
+	Bid1Price     fixedpoint.Value `json:"bid1Price"`
+	Bid1Size      fixedpoint.Value `json:"bid1Size"`
+	Ask1Price     fixedpoint.Value `json:"ask1Price"`
+	Ask1Size      fixedpoint.Value `json:"ask1Size"`
+	LastPrice     fixedpoint.Value `json:"lastPrice"`
+	PrevPrice24H  fixedpoint.Value `json:"prevPrice24h"`
+	Price24HPcnt  fixedpoint.Value `json:"price24hPcnt"`
+	HighPrice24H  fixedpoint.Value `json:"highPrice24h"`
+	LowPrice24H   fixedpoint.Value `json:"lowPrice24h"`
+	Turnover24H   fixedpoint.Value `json:"turnover24h"`
+	Volume24H     fixedpoint.Value `json:"volume24h"`
+	UsdIndexPrice fixedpoint.Value `json:"usdIndexPrice"`
+}
+
+//go:generate requestgen -method GET -url "/v5/market/tickers" -type GetTickersRequest -responseType .APIResponse -responseDataField Result -responseDataType .Tickers
+type GetTickersRequest struct {
+	client requestgen.APIClient
+
+	category Category `param:"category" validValues:"spot"`
+	symbol   *string  `param:"symbol"`
+}
+
+func (c *RestClient) NewGetTickersRequest() *GetTickersRequest {
+	return &GetTickersRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
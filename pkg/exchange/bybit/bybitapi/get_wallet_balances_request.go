@@ -0,0 +1,48 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type WalletBalances struct {
+	List []WalletBalance `json:"list"`
+}
+
+type WalletBalance struct {
+	AccountType           AccountType      `json:"accountType"`
+	TotalEquity           fixedpoint.Value `json:"totalEquity"`
+	TotalWalletBalance    fixedpoint.Value `json:"totalWalletBalance"`
+	TotalAvailableBalance fixedpoint.Value `json:"totalAvailableBalance"`
+	AccountIMRate         fixedpoint.Value `json:"accountIMRate"`
+	AccountMMRate         fixedpoint.Value `json:"accountMMRate"`
+	Coins                 []CoinBalance    `json:"coin"`
+}
+
+type CoinBalance struct {
+	Coin                string           `json:"coin"`
+	Equity              fixedpoint.Value `json:"equity"`
+	UsdValue            fixedpoint.Value `json:"usdValue"`
+	WalletBalance       fixedpoint.Value `json:"walletBalance"`
+	Free                fixedpoint.Value `json:"free"`
+	Locked              fixedpoint.Value `json:"locked"`
+	BorrowAmount        fixedpoint.Value `json:"borrowAmount"`
+	AccruedInterest     fixedpoint.Value `json:"accruedInterest"`
+	AvailableToWithdraw fixedpoint.Value `json:"availableToWithdraw"`
+}
+
+//go:generate requestgen -method GET -url "/v5/account/wallet-balance" -type GetWalletBalancesRequest -responseType .APIResponse -responseDataField Result -responseDataType .WalletBalances
+type GetWalletBalancesRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	accountType AccountType `param:"accountType" validValues:"UNIFIED,SPOT"`
+	coin        *string     `param:"coin"`
+}
+
+func (c *RestClient) NewGetWalletBalancesRequest() *GetWalletBalancesRequest {
+	return &GetWalletBalancesRequest{
+		client:      c,
+		accountType: AccountTypeUnified,
+	}
+}
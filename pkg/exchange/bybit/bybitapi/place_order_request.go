@@ -0,0 +1,36 @@
+package bybitapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type PlaceOrderResponse struct {
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+}
+
+//go:generate requestgen -method POST -url "/v5/order/create" -type PlaceOrderRequest -responseType .APIResponse -responseDataField Result -responseDataType .PlaceOrderResponse
+type PlaceOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	category  Category  `param:"category" validValues:"spot"`
+	symbol    string    `param:"symbol,required"`
+	side      Side      `param:"side" validValues:"Buy,Sell"`
+	orderType OrderType `param:"orderType" validValues:"Market,Limit"`
+
+	// quantity is the base coin quantity when marketUnit is baseCoin
+	quantity    string       `param:"qty,required"`
+	marketUnit  *MarketUnit  `param:"marketUnit"`
+	price       *string      `param:"price"`
+	timeInForce *TimeInForce `param:"timeInForce"`
+
+	// orderLinkID is the user customised order ID, max 36 characters
+	orderLinkID *string `param:"orderLinkId"`
+}
+
+func (c *RestClient) NewPlaceOrderRequest() *PlaceOrderRequest {
+	return &PlaceOrderRequest{
+		client:   c,
+		category: CategorySpot,
+	}
+}
@@ -0,0 +1,59 @@
+package bybitapi
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+const (
+	rateLimitRemainingHeader = "X-Bapi-Limit-Status"
+	rateLimitLimitHeader     = "X-Bapi-Limit"
+)
+
+// Limiter tracks the requests of the Bybit v5 api, the ip is limited to 600 requests per 5 seconds,
+// and the private endpoints are limited separately with the remaining requests returned in the response headers.
+var Limiter = ratelimit.Register(ratelimit.New("bybit",
+	ratelimit.Rule{Name: "ip", Limit: 550, Interval: 5 * time.Second, PathPrefix: "/v5/", DefaultWeight: 1},
+	ratelimit.Rule{
+		Name:            "create_order",
+		Limit:           9,
+		Interval:        time.Second,
+		PathPrefix:      "/v5/order/",
+		RemainingHeader: rateLimitRemainingHeader,
+		LimitHeader:     rateLimitLimitHeader,
+	},
+	ratelimit.Rule{
+		Name:            "cancel_order",
+		Limit:           9,
+		Interval:        time.Second,
+		PathPrefix:      "/v5/order/",
+		RemainingHeader: rateLimitRemainingHeader,
+		LimitHeader:     rateLimitLimitHeader,
+	},
+	ratelimit.Rule{
+		Name:            "query_order",
+		Limit:           45,
+		Interval:        time.Second,
+		PathPrefix:      "/v5/",
+		RemainingHeader: rateLimitRemainingHeader,
+		LimitHeader:     rateLimitLimitHeader,
+	},
+	ratelimit.Rule{
+		Name:            "wallet_balance",
+		Limit:           45,
+		Interval:        time.Second,
+		PathPrefix:      "/v5/account/",
+		RemainingHeader: rateLimitRemainingHeader,
+		LimitHeader:     rateLimitLimitHeader,
+	},
+))
+
+func init() {
+	Limiter.RegisterWeight("POST /v5/order/create", ratelimit.Weight{"create_order": 1})
+	Limiter.RegisterWeight("POST /v5/order/cancel", ratelimit.Weight{"cancel_order": 1})
+	Limiter.RegisterWeight("GET /v5/order/realtime", ratelimit.Weight{"query_order": 1})
+	Limiter.RegisterWeight("GET /v5/order/history", ratelimit.Weight{"query_order": 1})
+	Limiter.RegisterWeight("GET /v5/execution/list", ratelimit.Weight{"query_order": 1})
+	Limiter.RegisterWeight("GET /v5/account/wallet-balance", ratelimit.Weight{"wallet_balance": 1})
+}
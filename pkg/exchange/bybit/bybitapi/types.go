@@ -0,0 +1,64 @@
+package bybitapi
+
+// Category is the product type of the v5 API, only the spot category is used for now
+type Category string
+
+const (
+	CategorySpot    Category = "spot"
+	CategoryLinear  Category = "linear"
+	CategoryInverse Category = "inverse"
+)
+
+type AccountType string
+
+const (
+	AccountTypeUnified AccountType = "UNIFIED"
+	AccountTypeSpot    AccountType = "SPOT"
+)
+
+type Side string
+
+const (
+	SideBuy  Side = "Buy"
+	SideSell Side = "Sell"
+)
+
+type OrderType string
+
+const (
+	OrderTypeMarket OrderType = "Market"
+	OrderTypeLimit  OrderType = "Limit"
+)
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC      TimeInForce = "GTC"
+	TimeInForceIOC      TimeInForce = "IOC"
+	TimeInForceFOK      TimeInForce = "FOK"
+	TimeInForcePostOnly TimeInForce = "PostOnly"
+)
+
+// MarketUnit is the unit of the quantity of the spot market orders,
+// the quantity of the market buy orders is in the quote coin by default.
+type MarketUnit string
+
+const (
+	MarketUnitBaseCoin  MarketUnit = "baseCoin"
+	MarketUnitQuoteCoin MarketUnit = "quoteCoin"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusCreated                 OrderStatus = "Created"
+	OrderStatusNew                     OrderStatus = "New"
+	OrderStatusRejected                OrderStatus = "Rejected"
+	OrderStatusPartiallyFilled         OrderStatus = "PartiallyFilled"
+	OrderStatusPartiallyFilledCanceled OrderStatus = "PartiallyFilledCanceled"
+	OrderStatusFilled                  OrderStatus = "Filled"
+	OrderStatusCancelled               OrderStatus = "Cancelled"
+	OrderStatusUntriggered             OrderStatus = "Untriggered"
+	OrderStatusTriggered               OrderStatus = "Triggered"
+	OrderStatusDeactivated             OrderStatus = "Deactivated"
+)
@@ -0,0 +1,316 @@
+package bybit
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var globalSymbolMap = make(map[string]string, len(spotSymbolMap))
+
+func init() {
+	for global, local := range spotSymbolMap {
+		globalSymbolMap[local] = global
+	}
+}
+
+func toGlobalSymbol(symbol string) string {
+	if s, ok := globalSymbolMap[symbol]; ok {
+		return s
+	}
+
+	return symbol
+}
+
+//go:generate go run gensymbols.go
+func toLocalSymbol(symbol string) string {
+	if s, ok := spotSymbolMap[symbol]; ok {
+		return s
+	}
+
+	// the spot symbols of bybit are the same as the global symbols, the listed symbols after the map is generated
+	// can be used directly
+	return strings.ToUpper(symbol)
+}
+
+func toGlobalMarket(m bybitapi.Instrument) types.Market {
+	return types.Market{
+		Symbol:          toGlobalSymbol(m.Symbol),
+		LocalSymbol:     m.Symbol,
+		PricePrecision:  m.PriceFilter.TickSize.NumFractionalDigits(),
+		VolumePrecision: m.LotSizeFilter.BasePrecision.NumFractionalDigits(),
+		QuoteCurrency:   m.QuoteCoin,
+		BaseCurrency:    m.BaseCoin,
+		MinNotional:     m.LotSizeFilter.MinOrderAmt,
+		MinAmount:       m.LotSizeFilter.MinOrderAmt,
+		MinQuantity:     m.LotSizeFilter.MinOrderQty,
+		MaxQuantity:     m.LotSizeFilter.MaxOrderQty,
+		StepSize:        m.LotSizeFilter.BasePrecision,
+
+		MinPrice: fixedpoint.Zero, // not used
+		MaxPrice: fixedpoint.Zero, // not used
+		TickSize: m.PriceFilter.TickSize,
+	}
+}
+
+func toGlobalTicker(t bybitapi.Ticker, ts time.Time) types.Ticker {
+	return types.Ticker{
+		Time:   ts,
+		Volume: t.Volume24H,
+		Last:   t.LastPrice,
+		Open:   t.PrevPrice24H,
+		High:   t.HighPrice24H,
+		Low:    t.LowPrice24H,
+		Buy:    t.Bid1Price,
+		Sell:   t.Ask1Price,
+	}
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, k bybitapi.KLine) types.KLine {
+	return types.KLine{
+		Exchange:    types.ExchangeBybit,
+		Symbol:      toGlobalSymbol(symbol),
+		StartTime:   types.Time(k.StartTime),
+		EndTime:     types.Time(k.StartTime.Time().Add(interval.Duration() - time.Millisecond)),
+		Interval:    interval,
+		Open:        k.Open,
+		Close:       k.Close,
+		High:        k.High,
+		Low:         k.Low,
+		Volume:      k.Volume,
+		QuoteVolume: k.Turnover,
+		Closed:      true,
+	}
+}
+
+func toLocalInterval(interval types.Interval) (string, error) {
+	if _, ok := supportedIntervals[interval]; !ok {
+		return "", fmt.Errorf("interval %s is not supported by bybit", interval)
+	}
+
+	switch interval {
+	case types.Interval1d:
+		return "D", nil
+	}
+
+	return strconv.Itoa(interval.Minutes()), nil
+}
+
+func toGlobalInterval(interval string) (types.Interval, error) {
+	if interval == "D" {
+		return types.Interval1d, nil
+	}
+
+	minutes, err := strconv.Atoi(interval)
+	if err != nil {
+		return "", fmt.Errorf("unsupported bybit interval %s", interval)
+	}
+
+	for i := range supportedIntervals {
+		if i.Minutes() == minutes {
+			return i, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported bybit interval %s", interval)
+}
+
+func toGlobalBalanceMap(accounts []bybitapi.WalletBalance) types.BalanceMap {
+	balances := types.BalanceMap{}
+	for _, account := range accounts {
+		for _, c := range account.Coins {
+			balances[c.Coin] = types.Balance{
+				Currency:  c.Coin,
+				Available: c.WalletBalance.Sub(c.Locked),
+				Locked:    c.Locked,
+				Borrowed:  c.BorrowAmount,
+				Interest:  c.AccruedInterest,
+				NetAsset:  c.Equity,
+			}
+		}
+	}
+
+	return balances
+}
+
+func toLocalSide(side types.SideType) (bybitapi.Side, error) {
+	switch side {
+	case types.SideTypeBuy:
+		return bybitapi.SideBuy, nil
+
+	case types.SideTypeSell:
+		return bybitapi.SideSell, nil
+
+	}
+
+	return "", fmt.Errorf("side type %s is not supported by bybit", side)
+}
+
+func toGlobalSide(side bybitapi.Side) types.SideType {
+	switch side {
+	case bybitapi.SideBuy:
+		return types.SideTypeBuy
+
+	case bybitapi.SideSell:
+		return types.SideTypeSell
+
+	}
+
+	return types.SideTypeSelf
+}
+
+func toGlobalOrderType(o bybitapi.Order) types.OrderType {
+	switch o.OrderType {
+	case bybitapi.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case bybitapi.OrderTypeLimit:
+		if o.TimeInForce == bybitapi.TimeInForcePostOnly {
+			return types.OrderTypeLimitMaker
+		}
+		return types.OrderTypeLimit
+
+	}
+
+	return types.OrderType(o.OrderType)
+}
+
+func toGlobalOrderStatus(status bybitapi.OrderStatus) types.OrderStatus {
+	switch status {
+	case bybitapi.OrderStatusCreated, bybitapi.OrderStatusNew, bybitapi.OrderStatusUntriggered, bybitapi.OrderStatusTriggered:
+		return types.OrderStatusNew
+
+	case bybitapi.OrderStatusPartiallyFilled:
+		return types.OrderStatusPartiallyFilled
+
+	case bybitapi.OrderStatusFilled:
+		return types.OrderStatusFilled
+
+	case bybitapi.OrderStatusCancelled, bybitapi.OrderStatusPartiallyFilledCanceled, bybitapi.OrderStatusDeactivated:
+		return types.OrderStatusCanceled
+
+	case bybitapi.OrderStatusRejected:
+		return types.OrderStatusRejected
+
+	}
+
+	return types.OrderStatus(status)
+}
+
+func isWorkingOrderStatus(status bybitapi.OrderStatus) bool {
+	switch status {
+	case bybitapi.OrderStatusCreated, bybitapi.OrderStatusNew, bybitapi.OrderStatusPartiallyFilled,
+		bybitapi.OrderStatusUntriggered, bybitapi.OrderStatusTriggered:
+		return true
+	}
+
+	return false
+}
+
+func toGlobalOrder(o bybitapi.Order) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.OrderLinkID,
+			Symbol:        toGlobalSymbol(o.Symbol),
+			Side:          toGlobalSide(o.Side),
+			Type:          toGlobalOrderType(o),
+			Quantity:      o.Qty,
+			Price:         o.Price,
+			StopPrice:     o.TriggerPrice,
+			TimeInForce:   toGlobalTimeInForce(o.TimeInForce),
+		},
+		Exchange:         types.ExchangeBybit,
+		OrderID:          parseID(o.OrderID),
+		UUID:             o.OrderID,
+		Status:           toGlobalOrderStatus(o.OrderStatus),
+		ExecutedQuantity: o.CumExecQty,
+		IsWorking:        isWorkingOrderStatus(o.OrderStatus),
+		CreationTime:     types.Time(o.CreatedTime.Time()),
+		UpdateTime:       types.Time(o.UpdatedTime.Time()),
+	}
+}
+
+func toGlobalTimeInForce(tif bybitapi.TimeInForce) types.TimeInForce {
+	switch tif {
+	case bybitapi.TimeInForceIOC:
+		return types.TimeInForceIOC
+
+	case bybitapi.TimeInForceFOK:
+		return types.TimeInForceFOK
+
+	}
+
+	return types.TimeInForceGTC
+}
+
+// toGlobalTrade converts the execution to the trade, the fee of the spot trade is charged in the received asset,
+// the base coin for the buy trades and the quote coin for the sell trades.
+func toGlobalTrade(e bybitapi.Execution, market types.Market) types.Trade {
+	feeCurrency := market.QuoteCurrency
+	if e.Side == bybitapi.SideBuy {
+		feeCurrency = market.BaseCurrency
+	}
+
+	return types.Trade{
+		ID:            parseID(e.ExecID),
+		OrderID:       parseID(e.OrderID),
+		Exchange:      types.ExchangeBybit,
+		Price:         e.ExecPrice,
+		Quantity:      e.ExecQty,
+		QuoteQuantity: e.ExecValue,
+		Symbol:        toGlobalSymbol(e.Symbol),
+		Side:          toGlobalSide(e.Side),
+		IsBuyer:       e.Side == bybitapi.SideBuy,
+		IsMaker:       e.IsMaker,
+		Time:          types.Time(e.ExecTime.Time()),
+		Fee:           e.ExecFee,
+		FeeCurrency:   feeCurrency,
+	}
+}
+
+// parseID parses the numeric id of the spot orders and trades, the uuid is hashed into the uint64 id
+func parseID(id string) uint64 {
+	if v, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return v
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return h.Sum64()
+}
+
+// convertSubscription converts the global subscription to the topic of the public stream
+func convertSubscription(s types.Subscription) (string, error) {
+	symbol := toLocalSymbol(s.Symbol)
+	switch s.Channel {
+	case types.BookChannel:
+		depth := 50
+		switch s.Options.Depth {
+		case types.DepthLevelFull, types.DepthLevelMedium:
+			depth = 200
+		}
+		return fmt.Sprintf("orderbook.%d.%s", depth, symbol), nil
+
+	case types.BookTickerChannel:
+		return "orderbook.1." + symbol, nil
+
+	case types.MarketTradeChannel:
+		return "publicTrade." + symbol, nil
+
+	case types.KLineChannel:
+		interval, err := toLocalInterval(s.Options.Interval)
+		if err != nil {
+			return "", err
+		}
+		return "kline." + interval + "." + symbol, nil
+
+	}
+
+	return "", fmt.Errorf("websocket channel %s is not supported by bybit", s.Channel)
+}
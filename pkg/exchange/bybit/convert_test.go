@@ -0,0 +1,136 @@
+package bybit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_toLocalInterval(t *testing.T) {
+	interval, err := toLocalInterval(types.Interval1m)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", interval)
+
+	interval, err = toLocalInterval(types.Interval4h)
+	assert.NoError(t, err)
+	assert.Equal(t, "240", interval)
+
+	interval, err = toLocalInterval(types.Interval1d)
+	assert.NoError(t, err)
+	assert.Equal(t, "D", interval)
+
+	_, err = toLocalInterval(types.Interval3d)
+	assert.Error(t, err)
+}
+
+func Test_toGlobalInterval(t *testing.T) {
+	for interval := range supportedIntervals {
+		local, err := toLocalInterval(interval)
+		assert.NoError(t, err)
+
+		global, err := toGlobalInterval(local)
+		assert.NoError(t, err)
+		assert.Equal(t, interval, global)
+	}
+
+	_, err := toGlobalInterval("W")
+	assert.Error(t, err)
+}
+
+func Test_toGlobalOrderType(t *testing.T) {
+	assert.Equal(t, types.OrderTypeLimitMaker, toGlobalOrderType(bybitapi.Order{
+		OrderType:   bybitapi.OrderTypeLimit,
+		TimeInForce: bybitapi.TimeInForcePostOnly,
+	}))
+	assert.Equal(t, types.OrderTypeLimit, toGlobalOrderType(bybitapi.Order{
+		OrderType:   bybitapi.OrderTypeLimit,
+		TimeInForce: bybitapi.TimeInForceGTC,
+	}))
+	assert.Equal(t, types.OrderTypeMarket, toGlobalOrderType(bybitapi.Order{
+		OrderType: bybitapi.OrderTypeMarket,
+	}))
+}
+
+func Test_toGlobalTrade(t *testing.T) {
+	market := types.Market{
+		Symbol:        "ETHUSDT",
+		BaseCurrency:  "ETH",
+		QuoteCurrency: "USDT",
+	}
+
+	execution := bybitapi.Execution{
+		Symbol:    "ETHUSDT",
+		OrderID:   "1321052653536515584",
+		Side:      bybitapi.SideBuy,
+		ExecFee:   fixedpoint.MustNewFromString("0.0001"),
+		ExecID:    "2100000000007764262",
+		ExecPrice: fixedpoint.MustNewFromString("1550"),
+		ExecQty:   fixedpoint.MustNewFromString("0.1"),
+		ExecValue: fixedpoint.MustNewFromString("155"),
+		IsMaker:   true,
+	}
+
+	trade := toGlobalTrade(execution, market)
+	assert.Equal(t, uint64(2100000000007764262), trade.ID)
+	assert.Equal(t, uint64(1321052653536515584), trade.OrderID)
+	assert.Equal(t, "ETH", trade.FeeCurrency)
+	assert.True(t, trade.IsBuyer)
+	assert.True(t, trade.IsMaker)
+
+	execution.Side = bybitapi.SideSell
+	trade = toGlobalTrade(execution, market)
+	assert.Equal(t, "USDT", trade.FeeCurrency)
+	assert.False(t, trade.IsBuyer)
+}
+
+func Test_parseID(t *testing.T) {
+	assert.Equal(t, uint64(1321003749386327552), parseID("1321003749386327552"))
+
+	// the uuid is hashed into a stable id
+	id := parseID("20f43950-d8dd-5b31-9112-a178eb6023af")
+	assert.NotZero(t, id)
+	assert.Equal(t, id, parseID("20f43950-d8dd-5b31-9112-a178eb6023af"))
+}
+
+func Test_convertSubscription(t *testing.T) {
+	topic, err := convertSubscription(types.Subscription{
+		Symbol:  "BTCUSDT",
+		Channel: types.BookChannel,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "orderbook.50.BTCUSDT", topic)
+
+	topic, err = convertSubscription(types.Subscription{
+		Symbol:  "BTCUSDT",
+		Channel: types.BookChannel,
+		Options: types.SubscribeOptions{Depth: types.DepthLevelFull},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "orderbook.200.BTCUSDT", topic)
+
+	topic, err = convertSubscription(types.Subscription{
+		Symbol:  "BTCUSDT",
+		Channel: types.BookTickerChannel,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "orderbook.1.BTCUSDT", topic)
+
+	topic, err = convertSubscription(types.Subscription{
+		Symbol:  "BTCUSDT",
+		Channel: types.MarketTradeChannel,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "publicTrade.BTCUSDT", topic)
+
+	topic, err = convertSubscription(types.Subscription{
+		Symbol:  "BTCUSDT",
+		Channel: types.KLineChannel,
+		Options: types.SubscribeOptions{Interval: types.Interval1h},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "kline.60.BTCUSDT", topic)
+}
@@ -0,0 +1,590 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// https://bybit-exchange.github.io/docs/v5/rate-limit
+var marketDataLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+var queryOrderLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+var queryTradeLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+var submitOrderLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 10)
+var cancelOrderLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 10)
+
+// maxQueryRange is the max time range of the order history and the execution list query
+const maxQueryRange = 7 * 24 * time.Hour
+
+const (
+	// maxKLineLimit is the max number of the klines of one query
+	maxKLineLimit = 1000
+
+	// maxOrderHistoryLimit is the max page size of the order queries
+	maxOrderHistoryLimit = 50
+
+	// maxExecutionLimit is the max page size of the execution query
+	maxExecutionLimit = 100
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+
+var log = logrus.WithFields(logrus.Fields{
+	"exchange": "bybit",
+})
+
+type Exchange struct {
+	key, secret string
+	client      *bybitapi.RestClient
+
+	// markets is used for the fee currency of the trades
+	marketsMutex sync.Mutex
+	markets      types.MarketMap
+}
+
+func New(key, secret string) *Exchange {
+	client := bybitapi.NewClient("")
+
+	// for public access mode
+	if len(key) > 0 && len(secret) > 0 {
+		client.Auth(key, secret)
+	}
+
+	return &Exchange{
+		key: key,
+		// pragma: allowlist nextline secret
+		secret: secret,
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeBybit
+}
+
+// PlatformFeeCurrency returns the empty string, bybit does not deduct the trading fee from the platform token
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) DefaultFeeRates() types.ExchangeFee {
+	return types.ExchangeFee{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.100), // 0.1%
+		TakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.100), // 0.1%
+	}
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	instruments, err := e.client.NewGetInstrumentsInfoRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	marketMap := types.MarketMap{}
+	for _, s := range instruments.List {
+		marketMap.Add(toGlobalMarket(s))
+	}
+
+	e.marketsMutex.Lock()
+	e.markets = marketMap
+	e.marketsMutex.Unlock()
+	return marketMap, nil
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	tickers, err := e.client.NewGetTickersRequest().Symbol(toLocalSymbol(symbol)).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tickers.List) == 0 {
+		return nil, fmt.Errorf("ticker of %s not found", symbol)
+	}
+
+	ticker := toGlobalTicker(tickers.List[0], time.Now())
+	return &ticker, nil
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	tickers, err := e.client.NewGetTickersRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter = make(map[string]struct{}, len(symbols))
+	for _, s := range symbols {
+		filter[s] = struct{}{}
+	}
+
+	now := time.Now()
+	tickerMap := make(map[string]types.Ticker)
+	for _, t := range tickers.List {
+		symbol := toGlobalSymbol(t.Symbol)
+		if _, ok := filter[symbol]; len(filter) > 0 && !ok {
+			continue
+		}
+
+		tickerMap[symbol] = toGlobalTicker(t, now)
+	}
+
+	return tickerMap, nil
+}
+
+// supportedIntervals is the interval seconds of the kline intervals,
+// bybit supports 1,3,5,15,30,60,120,240,360,720,D,W,M
+var supportedIntervals = map[types.Interval]int{
+	types.Interval1m:  60,
+	types.Interval5m:  60 * 5,
+	types.Interval15m: 60 * 15,
+	types.Interval30m: 60 * 30,
+	types.Interval1h:  60 * 60,
+	types.Interval2h:  60 * 60 * 2,
+	types.Interval4h:  60 * 60 * 4,
+	types.Interval6h:  60 * 60 * 6,
+	types.Interval12h: 60 * 60 * 12,
+	types.Interval1d:  60 * 60 * 24,
+}
+
+func (e *Exchange) SupportedInterval() map[types.Interval]int {
+	return supportedIntervals
+}
+
+func (e *Exchange) IsSupportedInterval(interval types.Interval) bool {
+	_, ok := supportedIntervals[interval]
+	return ok
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	localInterval, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	limit := uint64(options.Limit)
+	if limit == 0 || limit > maxKLineLimit {
+		limit = maxKLineLimit
+	}
+
+	req := e.client.NewGetKLinesRequest().
+		Symbol(toLocalSymbol(symbol)).
+		Interval(localInterval).
+		Limit(limit)
+
+	if options.StartTime != nil {
+		req.StartTime(*options.StartTime)
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(*options.EndTime)
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var klines []types.KLine
+	for _, k := range resp.List {
+		klines = append(klines, toGlobalKLine(resp.Symbol, interval, k))
+	}
+
+	// bybit returns the klines in the reverse order
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].StartTime.Before(klines[j].StartTime.Time())
+	})
+
+	return klines, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	wallets, err := e.client.NewGetWalletBalancesRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalBalanceMap(wallets.List), nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		req := e.client.NewPlaceOrderRequest()
+		req.Symbol(toLocalSymbol(order.Symbol))
+
+		side, err := toLocalSide(order.Side)
+		if err != nil {
+			return createdOrders, err
+		}
+		req.Side(side)
+
+		if order.Market.Symbol != "" {
+			req.Quantity(order.Market.FormatQuantity(order.Quantity))
+		} else {
+			// TODO: report error?
+			req.Quantity(order.Quantity.FormatString(8))
+		}
+
+		switch order.Type {
+		case types.OrderTypeMarket:
+			req.OrderType(bybitapi.OrderTypeMarket)
+			// the quantity of the market buy order is in the quote coin by default
+			req.MarketUnit(bybitapi.MarketUnitBaseCoin)
+
+		case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+			req.OrderType(bybitapi.OrderTypeLimit)
+			if order.Market.Symbol != "" {
+				req.Price(order.Market.FormatPrice(order.Price))
+			} else {
+				// TODO: report error?
+				req.Price(order.Price.FormatString(8))
+			}
+
+		default:
+			return createdOrders, fmt.Errorf("order type %s is not supported by bybit", order.Type)
+		}
+
+		switch {
+		case order.Type == types.OrderTypeLimitMaker:
+			req.TimeInForce(bybitapi.TimeInForcePostOnly)
+		case order.TimeInForce == types.TimeInForceFOK:
+			req.TimeInForce(bybitapi.TimeInForceFOK)
+		case order.TimeInForce == types.TimeInForceIOC:
+			req.TimeInForce(bybitapi.TimeInForceIOC)
+		case order.Type == types.OrderTypeLimit:
+			req.TimeInForce(bybitapi.TimeInForceGTC)
+		}
+
+		if order.ClientOrderID != "" && order.ClientOrderID != types.NoClientOrderID {
+			req.OrderLinkID(order.ClientOrderID)
+		}
+
+		if err := submitOrderLimiter.Wait(ctx); err != nil {
+			return createdOrders, err
+		}
+
+		orderResponse, err := req.Do(ctx)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder:      order,
+			Exchange:         types.ExchangeBybit,
+			OrderID:          parseID(orderResponse.OrderID),
+			UUID:             orderResponse.OrderID,
+			Status:           types.OrderStatusNew,
+			ExecutedQuantity: fixedpoint.Zero,
+			IsWorking:        true,
+			CreationTime:     types.Time(time.Now()),
+			UpdateTime:       types.Time(time.Now()),
+		})
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	cursor := ""
+	for {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return orders, err
+		}
+
+		req := e.client.NewGetOpenOrdersRequest().
+			Symbol(toLocalSymbol(symbol)).
+			OpenOnly(0).
+			Limit(maxOrderHistoryLimit)
+		if cursor != "" {
+			req.Cursor(cursor)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		for _, o := range resp.List {
+			orders = append(orders, toGlobalOrder(o))
+		}
+
+		if resp.NextPageCursor == "" || len(resp.List) < maxOrderHistoryLimit {
+			return orders, nil
+		}
+
+		cursor = resp.NextPageCursor
+	}
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (errs error) {
+	for _, o := range orders {
+		req := e.client.NewCancelOrderRequest()
+		req.Symbol(toLocalSymbol(o.Symbol))
+
+		if o.UUID != "" {
+			req.OrderID(o.UUID)
+		} else if o.OrderID > 0 {
+			req.OrderID(strconv.FormatUint(o.OrderID, 10))
+		} else if o.ClientOrderID != "" {
+			req.OrderLinkID(o.ClientOrderID)
+		} else {
+			errs = multierr.Append(
+				errs,
+				fmt.Errorf("the order uuid, order id or client order id is empty, order: %#v", o),
+			)
+			continue
+		}
+
+		if err := cancelOrderLimiter.Wait(ctx); err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		response, err := req.Do(ctx)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		log.Infof("cancelled order: %s", response.OrderID)
+	}
+
+	if errs != nil {
+		return errors.Wrap(errs, "order cancel error")
+	}
+
+	return nil
+}
+
+// QueryOrder queries the open orders and the orders closed in the last 10 minutes first,
+// and then queries the order history.
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	if q.OrderID == "" && q.ClientOrderID == "" {
+		return nil, errors.New("one of the order id and the client order id is required")
+	}
+
+	if err := queryOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req := e.client.NewGetOpenOrdersRequest()
+	if q.Symbol != "" {
+		req.Symbol(toLocalSymbol(q.Symbol))
+	}
+	if q.OrderID != "" {
+		req.OrderID(q.OrderID)
+	} else {
+		req.OrderLinkID(q.ClientOrderID)
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.List) == 0 {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		historyReq := e.client.NewGetOrderHistoriesRequest()
+		if q.Symbol != "" {
+			historyReq.Symbol(toLocalSymbol(q.Symbol))
+		}
+		if q.OrderID != "" {
+			historyReq.OrderID(q.OrderID)
+		} else {
+			historyReq.OrderLinkID(q.ClientOrderID)
+		}
+
+		resp, err = historyReq.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(resp.List) == 0 {
+		return nil, fmt.Errorf("%w: %+v", ErrOrderNotFound, q)
+	}
+
+	order := toGlobalOrder(resp.List[0])
+	return &order, nil
+}
+
+// QueryClosedOrders queries the closed orders between since and until, the time range of one query is limited to 7 days
+// by bybit, hence the orders of the first 7 days after since are returned when the time range exceeds the limit.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	if until.Sub(since) > maxQueryRange {
+		until = since.Add(maxQueryRange)
+	}
+
+	cursor := ""
+	for {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return orders, err
+		}
+
+		req := e.client.NewGetOrderHistoriesRequest().
+			Symbol(toLocalSymbol(symbol)).
+			StartTime(since).
+			EndTime(until).
+			Limit(maxOrderHistoryLimit)
+		if cursor != "" {
+			req.Cursor(cursor)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		for _, o := range resp.List {
+			if isWorkingOrderStatus(o.OrderStatus) {
+				continue
+			}
+
+			orders = append(orders, toGlobalOrder(o))
+		}
+
+		if resp.NextPageCursor == "" || len(resp.List) < maxOrderHistoryLimit {
+			break
+		}
+
+		cursor = resp.NextPageCursor
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime.Time())
+	})
+
+	return orders, nil
+}
+
+// QueryTrades queries the trades of the user, bybit does not support querying the trades by the last trade ID,
+// the trades are queried by the time range, which is limited to 7 days by bybit.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	market, err := e.market(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var startTime, endTime time.Time
+	if options.StartTime != nil && options.EndTime != nil {
+		startTime, endTime = *options.StartTime, *options.EndTime
+		if endTime.Sub(startTime) > maxQueryRange {
+			endTime = startTime.Add(maxQueryRange)
+		}
+	} else if options.StartTime != nil {
+		startTime = *options.StartTime
+		endTime = startTime.Add(maxQueryRange)
+	} else if options.EndTime != nil {
+		endTime = *options.EndTime
+		startTime = endTime.Add(-maxQueryRange)
+	}
+
+	cursor := ""
+	for {
+		if err := queryTradeLimiter.Wait(ctx); err != nil {
+			return trades, err
+		}
+
+		req := e.client.NewGetExecutionsRequest().
+			Symbol(toLocalSymbol(symbol)).
+			Limit(maxExecutionLimit)
+		if !startTime.IsZero() {
+			req.StartTime(startTime)
+			req.EndTime(endTime)
+		}
+		if cursor != "" {
+			req.Cursor(cursor)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return trades, err
+		}
+
+		for _, execution := range resp.List {
+			trades = append(trades, toGlobalTrade(execution, market))
+		}
+
+		if resp.NextPageCursor == "" || len(resp.List) < maxExecutionLimit {
+			break
+		}
+
+		cursor = resp.NextPageCursor
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time.Time())
+	})
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, nil
+}
+
+// market returns the market of the symbol for the fee currency of the trades
+func (e *Exchange) market(ctx context.Context, symbol string) (types.Market, error) {
+	e.marketsMutex.Lock()
+	markets := e.markets
+	e.marketsMutex.Unlock()
+
+	if markets == nil {
+		var err error
+		markets, err = e.QueryMarkets(ctx)
+		if err != nil {
+			return types.Market{}, err
+		}
+	}
+
+	market, ok := markets[symbol]
+	if !ok {
+		return market, fmt.Errorf("market %s is not defined", symbol)
+	}
+
+	return market, nil
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e.client, e)
+}
@@ -0,0 +1,245 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// newFixtureExchange points the rest client to a test server, the server responds the testdata
+// fixture of the v5 api path like the websocket fixtures of the stream tests.
+func newFixtureExchange(t *testing.T, fixtures map[string]string, inspect func(r *http.Request)) *Exchange {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inspect != nil {
+			inspect(r)
+		}
+
+		name, ok := fixtures[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(readFixture(t, name))
+	}))
+	t.Cleanup(ts.Close)
+
+	ex := New("key", "secret")
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	ex.client.BaseURL = u
+	return ex
+}
+
+func TestExchange_QueryMarkets(t *testing.T) {
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/market/instruments-info": "instruments_info.json",
+	}, nil)
+
+	markets, err := ex.QueryMarkets(context.Background())
+	require.NoError(t, err)
+
+	market, ok := markets["BTCUSDT"]
+	require.True(t, ok)
+	assert.Equal(t, "BTC", market.BaseCurrency)
+	assert.Equal(t, "USDT", market.QuoteCurrency)
+	assert.Equal(t, 2, market.PricePrecision)
+	assert.Equal(t, 6, market.VolumePrecision)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.000048"), market.MinQuantity)
+	assert.Equal(t, fixedpoint.One, market.MinNotional)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.01"), market.TickSize)
+}
+
+func TestExchange_QueryTickers(t *testing.T) {
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/market/tickers": "tickers.json",
+	}, nil)
+
+	tickers, err := ex.QueryTickers(context.Background(), "ETHUSDT")
+	require.NoError(t, err)
+	require.Len(t, tickers, 1)
+
+	ticker := tickers["ETHUSDT"]
+	assert.Equal(t, fixedpoint.MustNewFromString("1541.06"), ticker.Last)
+	assert.Equal(t, fixedpoint.MustNewFromString("1541.05"), ticker.Buy)
+	assert.Equal(t, fixedpoint.MustNewFromString("1541.06"), ticker.Sell)
+	assert.Equal(t, fixedpoint.MustNewFromString("58062.31"), ticker.Volume)
+}
+
+func TestExchange_QueryKLines(t *testing.T) {
+	var query url.Values
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/market/kline": "klines.json",
+	}, func(r *http.Request) {
+		query = r.URL.Query()
+	})
+
+	klines, err := ex.QueryKLines(context.Background(), "BTCUSDT", types.Interval1h, types.KLineQueryOptions{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, klines, 2)
+
+	assert.Equal(t, "60", query.Get("interval"))
+	assert.Equal(t, "BTCUSDT", query.Get("symbol"))
+
+	// bybit responds the klines in the descending order
+	assert.Equal(t, time.UnixMilli(1670605200000), klines[0].StartTime.Time())
+	assert.Equal(t, time.UnixMilli(1670608800000), klines[1].StartTime.Time())
+	assert.Equal(t, time.UnixMilli(1670608800000+3600000-1), klines[1].EndTime.Time())
+	assert.Equal(t, fixedpoint.MustNewFromString("17055.5"), klines[1].Close)
+	assert.Equal(t, fixedpoint.MustNewFromString("15.74462667"), klines[1].QuoteVolume)
+}
+
+func TestExchange_QueryAccountBalances(t *testing.T) {
+	var headers http.Header
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/account/wallet-balance": "wallet_balance.json",
+	}, func(r *http.Request) {
+		headers = r.Header
+	})
+
+	balances, err := ex.QueryAccountBalances(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "key", headers.Get("X-BAPI-API-KEY"))
+	assert.NotEmpty(t, headers.Get("X-BAPI-SIGN"))
+
+	btc := balances["BTC"]
+	assert.Equal(t, fixedpoint.MustNewFromString("0.00082964"), btc.Available)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.0002"), btc.Locked)
+
+	usdt := balances["USDT"]
+	assert.Equal(t, fixedpoint.MustNewFromString("5.5"), usdt.Borrowed)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.0001"), usdt.Interest)
+	assert.Equal(t, fixedpoint.MustNewFromString("-5.5"), usdt.NetAsset)
+}
+
+func TestExchange_SubmitOrders(t *testing.T) {
+	var body map[string]interface{}
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/order/create":   "place_order.json",
+		"/v5/order/realtime": "open_orders.json",
+	}, func(r *http.Request) {
+		if r.URL.Path != "/v5/order/create" {
+			return
+		}
+
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &body))
+	})
+
+	createdOrders, err := ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		ClientOrderID: "spot-test-postonly",
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeLimitMaker,
+		Quantity:      fixedpoint.MustNewFromString("0.001"),
+		Price:         fixedpoint.NewFromInt(16000),
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 6,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, createdOrders, 1)
+
+	assert.Equal(t, "spot", body["category"])
+	assert.Equal(t, "Limit", body["orderType"])
+	assert.Equal(t, "PostOnly", body["timeInForce"])
+	assert.Equal(t, "Buy", body["side"])
+	assert.Equal(t, "spot-test-postonly", body["orderLinkId"])
+
+	order := createdOrders[0]
+	assert.Equal(t, uint64(1321003749386327552), order.OrderID)
+	assert.Equal(t, types.OrderTypeLimitMaker, order.Type)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+}
+
+func TestExchange_SubmitOrders_APIError(t *testing.T) {
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/order/create": "place_order_error.json",
+	}, nil)
+
+	_, err := ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.MustNewFromString("0.001"),
+		Price:    fixedpoint.NewFromInt(16000),
+		Market:   types.Market{Symbol: "BTCUSDT"},
+	})
+	require.Error(t, err)
+
+	var apiErr *bybitapi.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 170131, apiErr.Code)
+}
+
+func TestExchange_QueryOpenOrders(t *testing.T) {
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/order/realtime": "open_orders.json",
+	}, nil)
+
+	orders, err := ex.QueryOpenOrders(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	order := orders[0]
+	assert.Equal(t, "spot-test-postonly", order.ClientOrderID)
+	assert.Equal(t, types.OrderTypeLimitMaker, order.Type)
+	assert.Equal(t, fixedpoint.NewFromInt(16000), order.Price)
+	assert.True(t, order.IsWorking)
+}
+
+func TestExchange_QueryClosedOrders(t *testing.T) {
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/order/history": "order_history.json",
+	}, nil)
+
+	since := time.UnixMilli(1672217000000)
+	orders, err := ex.QueryClosedOrders(context.Background(), "ETHUSDT", since, since.Add(time.Hour), 0)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+
+	// the orders are sorted by the creation time
+	assert.Equal(t, types.OrderStatusCanceled, orders[0].Status)
+	assert.Equal(t, types.OrderStatusFilled, orders[1].Status)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.1"), orders[1].ExecutedQuantity)
+}
+
+func TestExchange_QueryTrades(t *testing.T) {
+	ex := newFixtureExchange(t, map[string]string{
+		"/v5/market/instruments-info": "instruments_info.json",
+		"/v5/execution/list":          "executions.json",
+	}, nil)
+
+	since := time.UnixMilli(1672217000000)
+	trades, err := ex.QueryTrades(context.Background(), "ETHUSDT", &types.TradeQueryOptions{
+		StartTime: &since,
+	})
+	require.NoError(t, err)
+	require.Len(t, trades, 2)
+
+	assert.Equal(t, uint64(2100000000007764262), trades[0].ID)
+	assert.Equal(t, "ETH", trades[0].FeeCurrency)
+	assert.True(t, trades[0].IsMaker)
+
+	assert.Equal(t, uint64(2100000000007764263), trades[1].ID)
+	assert.Equal(t, "USDT", trades[1].FeeCurrency)
+	assert.Equal(t, fixedpoint.MustNewFromString("160.05"), trades[1].QuoteQuantity)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.16005"), trades[1].Fee)
+}
@@ -0,0 +1,51 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"text/template"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+)
+
+var packageTemplate = template.Must(template.New("").Parse(`// Code generated by go generate; DO NOT EDIT.
+package bybit
+
+var spotSymbolMap = map[string]string{
+{{- range $k, $v := . }}
+	{{ printf "%q" $k }}: {{ printf "%q" $v }},
+{{- end }}
+}
+
+`))
+
+func main() {
+	ctx := context.Background()
+	client := bybitapi.NewClient("")
+	instruments, err := client.NewGetInstrumentsInfoRequest().Do(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var data = map[string]string{}
+	for _, instrument := range instruments.List {
+		symbol := instrument.BaseCoin + instrument.QuoteCoin
+		data[symbol] = instrument.Symbol
+	}
+
+	f, err := os.Create("symbols.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer f.Close()
+
+	err = packageTemplate.Execute(f, data)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
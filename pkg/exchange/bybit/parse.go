@@ -0,0 +1,224 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// WebSocketOpEvent is the response of the operation requests, e.g. auth, subscribe and ping
+type WebSocketOpEvent struct {
+	Success bool     `json:"success"`
+	RetMsg  string   `json:"ret_msg"`
+	ConnID  string   `json:"conn_id"`
+	ReqID   string   `json:"req_id"`
+	Op      string   `json:"op"`
+	Args    []string `json:"args"`
+}
+
+// WebSocketTopicEvent is the push message of the subscribed topic
+type WebSocketTopicEvent struct {
+	Topic string                     `json:"topic"`
+	Type  string                     `json:"type"`
+	Ts    types.MillisecondTimestamp `json:"ts"`
+	Data  json.RawMessage            `json:"data"`
+}
+
+type BookEvent struct {
+	Symbol   string                 `json:"s"`
+	Bids     types.PriceVolumeSlice `json:"b"`
+	Asks     types.PriceVolumeSlice `json:"a"`
+	UpdateID int64                  `json:"u"`
+	Sequence int64                  `json:"seq"`
+
+	// the following fields are parsed from the topic
+	Depth int    `json:"-"`
+	Type  string `json:"-"`
+}
+
+func (e *BookEvent) Book() types.SliceOrderBook {
+	return types.SliceOrderBook{
+		Symbol: toGlobalSymbol(e.Symbol),
+		Bids:   e.Bids,
+		Asks:   e.Asks,
+	}
+}
+
+type MarketTradeEvent struct {
+	Time   types.MillisecondTimestamp `json:"T"`
+	Symbol string                     `json:"s"`
+	Side   bybitapi.Side              `json:"S"`
+	Volume fixedpoint.Value           `json:"v"`
+	Price  fixedpoint.Value           `json:"p"`
+	ID     string                     `json:"i"`
+}
+
+func (e *MarketTradeEvent) Trade() types.Trade {
+	return types.Trade{
+		ID:            parseID(e.ID),
+		Exchange:      types.ExchangeBybit,
+		Price:         e.Price,
+		Quantity:      e.Volume,
+		QuoteQuantity: e.Price.Mul(e.Volume),
+		Symbol:        toGlobalSymbol(e.Symbol),
+		Side:          toGlobalSide(e.Side),
+		IsBuyer:       e.Side == bybitapi.SideBuy,
+		Time:          types.Time(e.Time.Time()),
+	}
+}
+
+type KLineEvent struct {
+	Symbol string
+	KLines []KLineData
+}
+
+type KLineData struct {
+	Start    types.MillisecondTimestamp `json:"start"`
+	End      types.MillisecondTimestamp `json:"end"`
+	Interval string                     `json:"interval"`
+	Open     fixedpoint.Value           `json:"open"`
+	Close    fixedpoint.Value           `json:"close"`
+	High     fixedpoint.Value           `json:"high"`
+	Low      fixedpoint.Value           `json:"low"`
+	Volume   fixedpoint.Value           `json:"volume"`
+	Turnover fixedpoint.Value           `json:"turnover"`
+	Confirm  bool                       `json:"confirm"`
+}
+
+func (k *KLineData) KLine(symbol string) (types.KLine, error) {
+	interval, err := toGlobalInterval(k.Interval)
+	if err != nil {
+		return types.KLine{}, err
+	}
+
+	return types.KLine{
+		Exchange:    types.ExchangeBybit,
+		Symbol:      toGlobalSymbol(symbol),
+		StartTime:   types.Time(k.Start.Time()),
+		EndTime:     types.Time(k.End.Time()),
+		Interval:    interval,
+		Open:        k.Open,
+		Close:       k.Close,
+		High:        k.High,
+		Low:         k.Low,
+		Volume:      k.Volume,
+		QuoteVolume: k.Turnover,
+		Closed:      k.Confirm,
+	}, nil
+}
+
+// OrderEvent is the order update of the private stream, the orders of all the categories are pushed
+type OrderEvent struct {
+	bybitapi.Order
+
+	Category bybitapi.Category `json:"category"`
+}
+
+// ExecutionEvent is the trade update of the private stream, the executions of all the categories are pushed
+type ExecutionEvent struct {
+	bybitapi.Execution
+
+	Category bybitapi.Category `json:"category"`
+}
+
+func parseWebSocketEvent(message []byte) (interface{}, error) {
+	var header struct {
+		Op    string `json:"op"`
+		Topic string `json:"topic"`
+	}
+
+	if err := json.Unmarshal(message, &header); err != nil {
+		return nil, err
+	}
+
+	if header.Op != "" {
+		var e WebSocketOpEvent
+		if err := json.Unmarshal(message, &e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+
+	if header.Topic == "" {
+		return nil, nil
+	}
+
+	var e WebSocketTopicEvent
+	if err := json.Unmarshal(message, &e); err != nil {
+		return nil, err
+	}
+
+	return parseTopicEvent(&e)
+}
+
+func parseTopicEvent(e *WebSocketTopicEvent) (interface{}, error) {
+	// the public topics are in the format of {topic}.{symbol} or {topic}.{depth or interval}.{symbol}
+	parts := strings.Split(e.Topic, ".")
+	switch parts[0] {
+	case "orderbook":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("unexpected orderbook topic: %s", e.Topic)
+		}
+
+		depth, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected orderbook topic: %s", e.Topic)
+		}
+
+		var book BookEvent
+		if err := json.Unmarshal(e.Data, &book); err != nil {
+			return nil, err
+		}
+
+		book.Depth = depth
+		book.Type = e.Type
+		return &book, nil
+
+	case "publicTrade":
+		var trades []MarketTradeEvent
+		if err := json.Unmarshal(e.Data, &trades); err != nil {
+			return nil, err
+		}
+		return trades, nil
+
+	case "kline":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("unexpected kline topic: %s", e.Topic)
+		}
+
+		var klines []KLineData
+		if err := json.Unmarshal(e.Data, &klines); err != nil {
+			return nil, err
+		}
+		return &KLineEvent{Symbol: parts[2], KLines: klines}, nil
+
+	case "order":
+		var orders []OrderEvent
+		if err := json.Unmarshal(e.Data, &orders); err != nil {
+			return nil, err
+		}
+		return orders, nil
+
+	case "execution":
+		var executions []ExecutionEvent
+		if err := json.Unmarshal(e.Data, &executions); err != nil {
+			return nil, err
+		}
+		return executions, nil
+
+	case "wallet":
+		var wallets []bybitapi.WalletBalance
+		if err := json.Unmarshal(e.Data, &wallets); err != nil {
+			return nil, err
+		}
+		return wallets, nil
+
+	}
+
+	return nil, fmt.Errorf("unsupported topic: %s", e.Topic)
+}
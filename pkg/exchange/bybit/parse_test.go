@@ -0,0 +1,145 @@
+package bybit
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+func Test_parseWebSocketEvent_Op(t *testing.T) {
+	e, err := parseWebSocketEvent(readFixture(t, "ws_auth.json"))
+	require.NoError(t, err)
+
+	op, ok := e.(*WebSocketOpEvent)
+	require.True(t, ok)
+	assert.Equal(t, "auth", op.Op)
+	assert.True(t, op.Success)
+}
+
+func Test_parseWebSocketEvent_Book(t *testing.T) {
+	e, err := parseWebSocketEvent(readFixture(t, "ws_orderbook_snapshot.json"))
+	require.NoError(t, err)
+
+	book, ok := e.(*BookEvent)
+	require.True(t, ok)
+	assert.Equal(t, 50, book.Depth)
+	assert.Equal(t, "snapshot", book.Type)
+	assert.Equal(t, int64(18521288), book.UpdateID)
+
+	orderBook := book.Book()
+	assert.Equal(t, "BTCUSDT", orderBook.Symbol)
+	if assert.Len(t, orderBook.Bids, 2) && assert.Len(t, orderBook.Asks, 2) {
+		assert.Equal(t, fixedpoint.MustNewFromString("16493.50"), orderBook.Bids[0].Price)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.006"), orderBook.Bids[0].Volume)
+		assert.Equal(t, fixedpoint.MustNewFromString("16611.00"), orderBook.Asks[0].Price)
+	}
+
+	e, err = parseWebSocketEvent(readFixture(t, "ws_orderbook_delta.json"))
+	require.NoError(t, err)
+
+	book, ok = e.(*BookEvent)
+	require.True(t, ok)
+	assert.Equal(t, "delta", book.Type)
+	if assert.Len(t, book.Bids, 1) {
+		assert.True(t, book.Bids[0].Volume.IsZero())
+	}
+}
+
+func Test_parseWebSocketEvent_MarketTrade(t *testing.T) {
+	e, err := parseWebSocketEvent(readFixture(t, "ws_public_trade.json"))
+	require.NoError(t, err)
+
+	events, ok := e.([]MarketTradeEvent)
+	require.True(t, ok)
+	require.Len(t, events, 1)
+
+	trade := events[0].Trade()
+	assert.Equal(t, types.ExchangeBybit, trade.Exchange)
+	assert.Equal(t, "BTCUSDT", trade.Symbol)
+	assert.Equal(t, types.SideTypeBuy, trade.Side)
+	assert.Equal(t, fixedpoint.MustNewFromString("16578.50"), trade.Price)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.001"), trade.Quantity)
+	assert.Equal(t, time.UnixMilli(1672304486865), trade.Time.Time())
+	assert.NotZero(t, trade.ID)
+}
+
+func Test_parseWebSocketEvent_KLine(t *testing.T) {
+	e, err := parseWebSocketEvent(readFixture(t, "ws_kline.json"))
+	require.NoError(t, err)
+
+	event, ok := e.(*KLineEvent)
+	require.True(t, ok)
+	assert.Equal(t, "BTCUSDT", event.Symbol)
+	require.Len(t, event.KLines, 1)
+
+	kline, err := event.KLines[0].KLine(event.Symbol)
+	require.NoError(t, err)
+	assert.Equal(t, types.Interval5m, kline.Interval)
+	assert.Equal(t, time.UnixMilli(1672324800000), kline.StartTime.Time())
+	assert.Equal(t, fixedpoint.MustNewFromString("16677"), kline.Close)
+	assert.Equal(t, fixedpoint.MustNewFromString("34666.4005"), kline.QuoteVolume)
+	assert.True(t, kline.Closed)
+}
+
+func Test_parseWebSocketEvent_Order(t *testing.T) {
+	e, err := parseWebSocketEvent(readFixture(t, "ws_order.json"))
+	require.NoError(t, err)
+
+	events, ok := e.([]OrderEvent)
+	require.True(t, ok)
+	require.Len(t, events, 2)
+	assert.Equal(t, bybitapi.CategorySpot, events[0].Category)
+	assert.Equal(t, bybitapi.CategoryLinear, events[1].Category)
+
+	order := toGlobalOrder(events[0].Order)
+	assert.Equal(t, uint64(1321052653536515584), order.OrderID)
+	assert.Equal(t, "1672217748277652", order.ClientOrderID)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.05"), order.ExecutedQuantity)
+	assert.True(t, order.IsWorking)
+}
+
+func Test_parseWebSocketEvent_Execution(t *testing.T) {
+	e, err := parseWebSocketEvent(readFixture(t, "ws_execution.json"))
+	require.NoError(t, err)
+
+	events, ok := e.([]ExecutionEvent)
+	require.True(t, ok)
+	require.Len(t, events, 1)
+	assert.Equal(t, bybitapi.CategorySpot, events[0].Category)
+	assert.Equal(t, "Trade", events[0].ExecType)
+	assert.Equal(t, "2100000000007764264", events[0].ExecID)
+	assert.True(t, events[0].IsMaker)
+}
+
+func Test_parseWebSocketEvent_Wallet(t *testing.T) {
+	e, err := parseWebSocketEvent(readFixture(t, "ws_wallet.json"))
+	require.NoError(t, err)
+
+	wallets, ok := e.([]bybitapi.WalletBalance)
+	require.True(t, ok)
+
+	balances := toGlobalBalanceMap(wallets)
+	usdt, ok := balances["USDT"]
+	require.True(t, ok)
+	assert.Equal(t, fixedpoint.MustNewFromString("127.85731614"), usdt.Locked)
+	assert.Equal(t, fixedpoint.MustNewFromString("9556.6056555"), usdt.Available)
+}
+
+func Test_parseWebSocketEvent_UnsupportedTopic(t *testing.T) {
+	_, err := parseWebSocketEvent([]byte(`{"topic":"tickers.BTCUSDT","type":"snapshot","data":{}}`))
+	assert.Error(t, err)
+}
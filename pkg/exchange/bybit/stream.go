@@ -0,0 +1,304 @@
+package bybit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bybit/bybitapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// pingInterval is the interval of the ping operation, bybit recommends sending the ping every 20 seconds
+// to keep the connection alive.
+const pingInterval = 20 * time.Second
+
+// maxSubscriptionArgs is the max number of the topics in one subscribe request of the spot public stream
+const maxSubscriptionArgs = 10
+
+type WebsocketOp struct {
+	Op   string        `json:"op"`
+	Args []interface{} `json:"args,omitempty"`
+}
+
+//go:generate callbackgen -type Stream -interface
+type Stream struct {
+	types.StandardStream
+
+	client   *bybitapi.RestClient
+	exchange *Exchange
+
+	// writeMutex is used for the concurrent writes of the subscriptions and the ping worker
+	writeMutex sync.Mutex
+
+	bookTickers map[string]types.BookTicker
+
+	opEventCallbacks          []func(e WebSocketOpEvent)
+	bookEventCallbacks        []func(e BookEvent)
+	marketTradeEventCallbacks []func(e []MarketTradeEvent)
+	kLineEventCallbacks       []func(e KLineEvent)
+	orderEventCallbacks       []func(e []OrderEvent)
+	executionEventCallbacks   []func(e []ExecutionEvent)
+	walletEventCallbacks      []func(e []bybitapi.WalletBalance)
+}
+
+func NewStream(client *bybitapi.RestClient, ex *Exchange) *Stream {
+	stream := &Stream{
+		StandardStream: types.NewStandardStream(),
+		client:         client,
+		exchange:       ex,
+		bookTickers:    make(map[string]types.BookTicker),
+	}
+
+	stream.SetParser(parseWebSocketEvent)
+	stream.SetDispatcher(stream.dispatchEvent)
+	stream.SetEndpointCreator(stream.createEndpoint)
+
+	stream.OnConnect(stream.handleConnect)
+	stream.OnOpEvent(stream.handleOpEvent)
+	stream.OnBookEvent(stream.handleBookEvent)
+	stream.OnMarketTradeEvent(stream.handleMarketTradeEvent)
+	stream.OnKLineEvent(stream.handleKLineEvent)
+	stream.OnOrderEvent(stream.handleOrderEvent)
+	stream.OnExecutionEvent(stream.handleExecutionEvent)
+	stream.OnWalletEvent(stream.handleWalletEvent)
+	return stream
+}
+
+func (s *Stream) createEndpoint(ctx context.Context) (string, error) {
+	if s.PublicOnly {
+		return bybitapi.PublicWebSocketURL, nil
+	}
+	return bybitapi.PrivateWebSocketURL, nil
+}
+
+func (s *Stream) writeJSON(v interface{}) error {
+	s.ConnLock.Lock()
+	conn := s.Conn
+	s.ConnLock.Unlock()
+
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	return conn.WriteJSON(v)
+}
+
+func (s *Stream) handleConnect() {
+	s.ConnLock.Lock()
+	ctx := s.ConnCtx
+	s.ConnLock.Unlock()
+
+	go s.ping(ctx)
+
+	if s.PublicOnly {
+		if err := s.sendSubscriptions(); err != nil {
+			log.WithError(err).Errorf("subscription error")
+		}
+		return
+	}
+
+	// the signature of the private stream is the hex encoded HMAC-SHA256 of "GET/realtime" + expires
+	expires := strconv.FormatInt(time.Now().Add(10*time.Second).UnixMilli(), 10)
+	signature := bybitapi.Sign(s.client.Secret, "GET/realtime"+expires)
+
+	log.Infof("sending bybit auth request")
+	if err := s.writeJSON(WebsocketOp{
+		Op:   "auth",
+		Args: []interface{}{s.client.Key, expires, signature},
+	}); err != nil {
+		log.WithError(err).Errorf("can not send auth message")
+	}
+}
+
+func (s *Stream) sendSubscriptions() error {
+	var topics []interface{}
+	for _, subscription := range s.Subscriptions {
+		topic, err := convertSubscription(subscription)
+		if err != nil {
+			log.WithError(err).Errorf("subscription convert error")
+			continue
+		}
+
+		topics = append(topics, topic)
+	}
+
+	for len(topics) > 0 {
+		n := len(topics)
+		if n > maxSubscriptionArgs {
+			n = maxSubscriptionArgs
+		}
+
+		log.Infof("subscribing topics: %v", topics[:n])
+		if err := s.writeJSON(WebsocketOp{Op: "subscribe", Args: topics[:n]}); err != nil {
+			return err
+		}
+
+		topics = topics[n:]
+	}
+
+	return nil
+}
+
+func (s *Stream) ping(ctx context.Context) {
+	pingTicker := time.NewTicker(pingInterval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			log.Debug("ping worker stopped")
+			return
+
+		case <-s.CloseC:
+			return
+
+		case <-pingTicker.C:
+			if err := s.writeJSON(WebsocketOp{Op: "ping"}); err != nil {
+				log.WithError(err).Error("websocket ping error")
+				s.Reconnect()
+				return
+			}
+		}
+	}
+}
+
+func (s *Stream) handleOpEvent(e WebSocketOpEvent) {
+	switch e.Op {
+	case "auth":
+		if !e.Success {
+			log.Errorf("bybit auth failed: %s", e.RetMsg)
+			return
+		}
+
+		topics := []interface{}{"order", "execution", "wallet"}
+		log.Infof("subscribing private topics: %v", topics)
+		if err := s.writeJSON(WebsocketOp{Op: "subscribe", Args: topics}); err != nil {
+			log.WithError(err).Error("private topic subscribe error")
+		}
+
+	case "subscribe":
+		if !e.Success {
+			log.Errorf("bybit subscribe failed: %s", e.RetMsg)
+		}
+
+	}
+}
+
+func (s *Stream) handleBookEvent(e BookEvent) {
+	// the level 1 order book is used for the book ticker
+	if e.Depth == 1 {
+		s.EmitBookTickerUpdate(s.updateBookTicker(e))
+		return
+	}
+
+	book := e.Book()
+	switch e.Type {
+	case "snapshot":
+		s.EmitBookSnapshot(book)
+	case "delta":
+		s.EmitBookUpdate(book)
+	}
+}
+
+// updateBookTicker merges the level 1 order book event into the last book ticker of the symbol,
+// the delta event only contains the changed side.
+func (s *Stream) updateBookTicker(e BookEvent) types.BookTicker {
+	symbol := toGlobalSymbol(e.Symbol)
+	bookTicker, ok := s.bookTickers[symbol]
+	if !ok || e.Type == "snapshot" {
+		bookTicker = types.BookTicker{Symbol: symbol}
+	}
+
+	if len(e.Bids) > 0 {
+		bookTicker.Buy = e.Bids[0].Price
+		bookTicker.BuySize = e.Bids[0].Volume
+	}
+
+	if len(e.Asks) > 0 {
+		bookTicker.Sell = e.Asks[0].Price
+		bookTicker.SellSize = e.Asks[0].Volume
+	}
+
+	s.bookTickers[symbol] = bookTicker
+	return bookTicker
+}
+
+func (s *Stream) handleMarketTradeEvent(events []MarketTradeEvent) {
+	for _, e := range events {
+		s.EmitMarketTrade(e.Trade())
+	}
+}
+
+func (s *Stream) handleKLineEvent(e KLineEvent) {
+	for _, k := range e.KLines {
+		kline, err := k.KLine(e.Symbol)
+		if err != nil {
+			log.WithError(err).Errorf("kline convert error")
+			continue
+		}
+
+		if kline.Closed {
+			s.EmitKLineClosed(kline)
+		} else {
+			s.EmitKLine(kline)
+		}
+	}
+}
+
+func (s *Stream) handleOrderEvent(events []OrderEvent) {
+	for _, e := range events {
+		if e.Category != bybitapi.CategorySpot {
+			continue
+		}
+
+		s.EmitOrderUpdate(toGlobalOrder(e.Order))
+	}
+}
+
+func (s *Stream) handleExecutionEvent(events []ExecutionEvent) {
+	for _, e := range events {
+		if e.Category != bybitapi.CategorySpot || e.ExecType != "Trade" {
+			continue
+		}
+
+		market, err := s.exchange.market(context.Background(), toGlobalSymbol(e.Symbol))
+		if err != nil {
+			log.WithError(err).Errorf("can not convert the execution %s", e.ExecID)
+			continue
+		}
+
+		s.EmitTradeUpdate(toGlobalTrade(e.Execution, market))
+	}
+}
+
+func (s *Stream) handleWalletEvent(wallets []bybitapi.WalletBalance) {
+	s.EmitBalanceUpdate(toGlobalBalanceMap(wallets))
+}
+
+func (s *Stream) dispatchEvent(event interface{}) {
+	switch e := event.(type) {
+
+	case *WebSocketOpEvent:
+		s.EmitOpEvent(*e)
+
+	case *BookEvent:
+		s.EmitBookEvent(*e)
+
+	case []MarketTradeEvent:
+		s.EmitMarketTradeEvent(e)
+
+	case *KLineEvent:
+		s.EmitKLineEvent(*e)
+
+	case []OrderEvent:
+		s.EmitOrderEvent(e)
+
+	case []ExecutionEvent:
+		s.EmitExecutionEvent(e)
+
+	case []bybitapi.WalletBalance:
+		s.EmitWalletEvent(e)
+
+	}
+}
@@ -0,0 +1,92 @@
+package bybit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func dispatchFixture(t *testing.T, s *Stream, name string) {
+	e, err := parseWebSocketEvent(readFixture(t, name))
+	require.NoError(t, err)
+	s.dispatchEvent(e)
+}
+
+func TestStream_BookTicker(t *testing.T) {
+	s := NewStream(nil, New("", ""))
+
+	var bookTickers []types.BookTicker
+	s.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+		bookTickers = append(bookTickers, bookTicker)
+	})
+
+	var snapshots int
+	s.OnBookSnapshot(func(book types.SliceOrderBook) {
+		snapshots++
+	})
+
+	dispatchFixture(t, s, "ws_orderbook_1.json")
+	dispatchFixture(t, s, "ws_orderbook_snapshot.json")
+
+	assert.Equal(t, 1, snapshots)
+	require.Len(t, bookTickers, 1)
+	assert.Equal(t, "BTCUSDT", bookTickers[0].Symbol)
+	assert.Equal(t, fixedpoint.MustNewFromString("16493.50"), bookTickers[0].Buy)
+	assert.Equal(t, fixedpoint.MustNewFromString("16611.00"), bookTickers[0].Sell)
+
+	// the delta of the level 1 order book only updates the changed side
+	s.dispatchEvent(&BookEvent{
+		Symbol: "BTCUSDT",
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromInt(16600), Volume: fixedpoint.One},
+		},
+		Depth: 1,
+		Type:  "delta",
+	})
+
+	require.Len(t, bookTickers, 2)
+	assert.Equal(t, fixedpoint.MustNewFromString("16493.50"), bookTickers[1].Buy)
+	assert.Equal(t, fixedpoint.NewFromInt(16600), bookTickers[1].Sell)
+}
+
+func TestStream_UserData(t *testing.T) {
+	ex := New("", "")
+	ex.markets = types.MarketMap{
+		"ETHUSDT": types.Market{Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT"},
+	}
+
+	s := NewStream(nil, ex)
+
+	var orders []types.Order
+	s.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+
+	var trades []types.Trade
+	s.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	var balances types.BalanceMap
+	s.OnBalanceUpdate(func(b types.BalanceMap) {
+		balances = b
+	})
+
+	dispatchFixture(t, s, "ws_order.json")
+	dispatchFixture(t, s, "ws_execution.json")
+	dispatchFixture(t, s, "ws_wallet.json")
+
+	// the linear order is filtered out
+	require.Len(t, orders, 1)
+	assert.Equal(t, "ETHUSDT", orders[0].Symbol)
+
+	require.Len(t, trades, 1)
+	assert.Equal(t, "USDT", trades[0].FeeCurrency)
+	assert.Equal(t, uint64(1321052653536515584), trades[0].OrderID)
+
+	assert.Contains(t, balances, "USDT")
+}
@@ -0,0 +1,28 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type CancelOrderResult struct {
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failure_reason"`
+	OrderID       string `json:"order_id"`
+}
+
+type CancelOrdersResponse struct {
+	Results []CancelOrderResult `json:"results"`
+}
+
+// CancelOrdersRequest cancels the orders by the order ids, at most 100 orders can be canceled in one request
+//
+//go:generate requestgen -method POST -url "/api/v3/brokerage/orders/batch_cancel" -type CancelOrdersRequest -responseType .CancelOrdersResponse
+type CancelOrdersRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	orderIDs []string `param:"order_ids,required"`
+}
+
+func (c *RestClient) NewCancelOrdersRequest() *CancelOrdersRequest {
+	return &CancelOrdersRequest{client: c}
+}
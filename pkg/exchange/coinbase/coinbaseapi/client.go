@@ -0,0 +1,200 @@
+package coinbaseapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/requestgen"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+const defaultHTTPTimeout = time.Second * 15
+const RestBaseURL = "https://api.coinbase.com"
+const WebSocketURL = "wss://advanced-trade-ws.coinbase.com"
+const DebugRequestResponse = false
+
+// advancedTradePathPrefix is the path prefix of the advanced trade API,
+// the rest of the endpoints (deposits, withdrawals) are served by the v2 API.
+const advancedTradePathPrefix = "/api/v3/brokerage"
+
+var DefaultHttpClient = &http.Client{
+	Timeout:   defaultHTTPTimeout,
+	Transport: ratelimit.NewTransport(Limiter, nil),
+}
+
+type RestClient struct {
+	requestgen.BaseAPIClient
+
+	Key, Secret string
+}
+
+func NewClient() *RestClient {
+	u, err := url.Parse(RestBaseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	return &RestClient{
+		BaseAPIClient: requestgen.BaseAPIClient{
+			BaseURL:    u,
+			HttpClient: DefaultHttpClient,
+		},
+	}
+}
+
+func (c *RestClient) Auth(key, secret string) {
+	c.Key = key
+	// pragma: allowlist nextline secret
+	c.Secret = secret
+}
+
+// NewRequest create new API request. Relative url can be provided in refURL.
+func (c *RestClient) NewRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	body, err := castPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+	req, err := http.NewRequestWithContext(ctx, method, pathURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+// SendRequest sends the request and converts the error response into the APIError
+func (c *RestClient) SendRequest(req *http.Request) (*requestgen.Response, error) {
+	if DebugRequestResponse {
+		logrus.Debugf("-> request: %+v", req)
+	}
+
+	response, err := c.BaseAPIClient.SendRequest(req)
+	if DebugRequestResponse && response != nil {
+		logrus.Debugf("<- response: %s", string(response.Body))
+	}
+
+	if err != nil && response != nil && response.IsError() {
+		apiErr := &APIError{StatusCode: response.StatusCode}
+		if json.Unmarshal(response.Body, apiErr) == nil && (apiErr.ErrorType != "" || apiErr.Message != "") {
+			return response, apiErr
+		}
+	}
+
+	return response, err
+}
+
+// NewAuthenticatedRequest creates new http request for authenticated routes.
+//
+// The signature is the hex encoded HMAC-SHA256 of timestamp + method + request path + body,
+// the query string is not signed for the advanced trade endpoints but it's signed for the v2 endpoints.
+func (c *RestClient) NewAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	if len(c.Key) == 0 {
+		return nil, errors.New("empty api key")
+	}
+
+	if len(c.Secret) == 0 {
+		return nil, errors.New("empty api secret")
+	}
+
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	body, err := castPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+
+	requestPath := pathURL.Path
+	if !strings.HasPrefix(requestPath, advancedTradePathPrefix) && pathURL.RawQuery != "" {
+		requestPath += "?" + pathURL.RawQuery
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := Sign(c.Secret, timestamp+method+requestPath+string(body))
+
+	req, err := http.NewRequestWithContext(ctx, method, pathURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("CB-ACCESS-KEY", c.Key)
+	req.Header.Add("CB-ACCESS-SIGN", signature)
+	req.Header.Add("CB-ACCESS-TIMESTAMP", timestamp)
+	return req, nil
+}
+
+// Sign uses sha256 to sign the payload with the given secret
+func Sign(secret, payload string) string {
+	var sig = hmac.New(sha256.New, []byte(secret))
+	_, err := sig.Write([]byte(payload))
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(sig.Sum(nil))
+}
+
+func castPayload(payload interface{}) ([]byte, error) {
+	if payload == nil {
+		return nil, nil
+	}
+
+	switch v := payload.(type) {
+	case string:
+		return []byte(v), nil
+
+	case []byte:
+		return v, nil
+
+	}
+	return json.Marshal(payload)
+}
+
+// APIError is the error response of the non-2xx status code
+type APIError struct {
+	StatusCode int `json:"-"`
+
+	ErrorType    string `json:"error"`
+	Message      string `json:"message"`
+	ErrorDetails string `json:"error_details"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("coinbase api error: status=%d, error=%s, message=%s", e.StatusCode, e.ErrorType, e.Message)
+}
@@ -0,0 +1,49 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+	"github.com/google/uuid"
+)
+
+type CreateOrderSuccessResponse struct {
+	OrderID       string    `json:"order_id"`
+	ProductID     string    `json:"product_id"`
+	Side          OrderSide `json:"side"`
+	ClientOrderID string    `json:"client_order_id"`
+}
+
+type CreateOrderErrorResponse struct {
+	Error                 string `json:"error"`
+	Message               string `json:"message"`
+	ErrorDetails          string `json:"error_details"`
+	PreviewFailureReason  string `json:"preview_failure_reason"`
+	NewOrderFailureReason string `json:"new_order_failure_reason"`
+}
+
+// CreateOrderResponse is responded with the 200 status code even if the order is rejected,
+// the Success field must be checked.
+type CreateOrderResponse struct {
+	Success         bool                       `json:"success"`
+	FailureReason   string                     `json:"failure_reason"`
+	OrderID         string                     `json:"order_id"`
+	SuccessResponse CreateOrderSuccessResponse `json:"success_response"`
+	ErrorResponse   CreateOrderErrorResponse   `json:"error_response"`
+}
+
+//go:generate requestgen -method POST -url "/api/v3/brokerage/orders" -type CreateOrderRequest -responseType .CreateOrderResponse
+type CreateOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	clientOrderID      string             `param:"client_order_id,required"`
+	productID          string             `param:"product_id,required"`
+	side               OrderSide          `param:"side,required" validValues:"BUY,SELL"`
+	orderConfiguration OrderConfiguration `param:"order_configuration,required"`
+}
+
+// NewCreateOrderRequest creates the order request with a random client order id, which is required by coinbase
+func (c *RestClient) NewCreateOrderRequest() *CreateOrderRequest {
+	return &CreateOrderRequest{
+		client:        c,
+		clientOrderID: uuid.New().String(),
+	}
+}
@@ -0,0 +1,45 @@
+package coinbaseapi
+
+import (
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Candle struct {
+	// Start is the unix timestamp in seconds
+	Start  types.MillisecondTimestamp `json:"start"`
+	Low    fixedpoint.Value           `json:"low"`
+	High   fixedpoint.Value           `json:"high"`
+	Open   fixedpoint.Value           `json:"open"`
+	Close  fixedpoint.Value           `json:"close"`
+	Volume fixedpoint.Value           `json:"volume"`
+
+	// ProductID is only available in the websocket candles channel
+	ProductID string `json:"product_id,omitempty"`
+}
+
+type CandlesResponse struct {
+	// Candles is sorted in the reverse order by the start time
+	Candles []Candle `json:"candles"`
+}
+
+// GetCandlesRequest queries the candles of the product, at most 350 candles can be returned in one request
+//
+//go:generate requestgen -method GET -url "/api/v3/brokerage/market/products/:productID/candles" -type GetCandlesRequest -responseType .CandlesResponse
+type GetCandlesRequest struct {
+	client requestgen.APIClient
+
+	productID   string      `param:"productID,slug,required"`
+	granularity Granularity `param:"granularity,required"`
+	startTime   time.Time   `param:"start,seconds,required"`
+	endTime     time.Time   `param:"end,seconds,required"`
+	limit       *int        `param:"limit"`
+}
+
+func (c *RestClient) NewGetCandlesRequest() *GetCandlesRequest {
+	return &GetCandlesRequest{client: c}
+}
@@ -0,0 +1,20 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type OrderResponse struct {
+	Order Order `json:"order"`
+}
+
+//go:generate requestgen -method GET -url "/api/v3/brokerage/orders/historical/:orderID" -type GetOrderRequest -responseType .OrderResponse
+type GetOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	orderID string `param:"orderID,slug,required"`
+}
+
+func (c *RestClient) NewGetOrderRequest() *GetOrderRequest {
+	return &GetOrderRequest{client: c}
+}
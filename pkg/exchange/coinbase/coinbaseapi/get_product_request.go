@@ -0,0 +1,16 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+//go:generate requestgen -method GET -url "/api/v3/brokerage/market/products/:productID" -type GetProductRequest -responseType .Product
+type GetProductRequest struct {
+	client requestgen.APIClient
+
+	productID string `param:"productID,slug,required"`
+}
+
+func (c *RestClient) NewGetProductRequest() *GetProductRequest {
+	return &GetProductRequest{client: c}
+}
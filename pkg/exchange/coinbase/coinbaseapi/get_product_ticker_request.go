@@ -0,0 +1,37 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type MarketTrade struct {
+	TradeID   string                     `json:"trade_id"`
+	ProductID string                     `json:"product_id"`
+	Price     fixedpoint.Value           `json:"price"`
+	Size      fixedpoint.Value           `json:"size"`
+	Time      types.MillisecondTimestamp `json:"time"`
+	Side      OrderSide                  `json:"side"`
+}
+
+type ProductTicker struct {
+	Trades  []MarketTrade    `json:"trades"`
+	BestBid fixedpoint.Value `json:"best_bid"`
+	BestAsk fixedpoint.Value `json:"best_ask"`
+}
+
+// GetProductTickerRequest queries the best bid/ask and the latest market trades of the product
+//
+//go:generate requestgen -method GET -url "/api/v3/brokerage/market/products/:productID/ticker" -type GetProductTickerRequest -responseType .ProductTicker
+type GetProductTickerRequest struct {
+	client requestgen.APIClient
+
+	productID string `param:"productID,slug,required"`
+	limit     int    `param:"limit"`
+}
+
+func (c *RestClient) NewGetProductTickerRequest() *GetProductTickerRequest {
+	return &GetProductTickerRequest{client: c, limit: 1}
+}
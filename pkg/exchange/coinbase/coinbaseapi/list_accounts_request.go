@@ -0,0 +1,47 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Balance struct {
+	Value    fixedpoint.Value `json:"value"`
+	Currency string           `json:"currency"`
+}
+
+type Account struct {
+	UUID             string                     `json:"uuid"`
+	Name             string                     `json:"name"`
+	Currency         string                     `json:"currency"`
+	AvailableBalance Balance                    `json:"available_balance"`
+	Hold             Balance                    `json:"hold"`
+	Default          bool                       `json:"default"`
+	Active           bool                       `json:"active"`
+	Type             string                     `json:"type"`
+	Ready            bool                       `json:"ready"`
+	CreatedAt        types.MillisecondTimestamp `json:"created_at"`
+	UpdatedAt        types.MillisecondTimestamp `json:"updated_at"`
+}
+
+type AccountsResponse struct {
+	Accounts []Account `json:"accounts"`
+	HasNext  bool      `json:"has_next"`
+	Cursor   string    `json:"cursor"`
+	Size     int       `json:"size"`
+}
+
+//go:generate requestgen -method GET -url "/api/v3/brokerage/accounts" -type ListAccountsRequest -responseType .AccountsResponse
+type ListAccountsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	// limit is the page size, the max value is 250 and the default is 49
+	limit  *int    `param:"limit"`
+	cursor *string `param:"cursor"`
+}
+
+func (c *RestClient) NewListAccountsRequest() *ListAccountsRequest {
+	return &ListAccountsRequest{client: c}
+}
@@ -0,0 +1,30 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type FillsResponse struct {
+	Fills  []Fill `json:"fills"`
+	Cursor string `json:"cursor"`
+}
+
+// ListFillsRequest queries the fills of the orders, the fills are sorted by the sequence timestamp in the descending order.
+//
+// The start sequence timestamp and the end sequence timestamp are in the RFC3339 format.
+//
+//go:generate requestgen -method GET -url "/api/v3/brokerage/orders/historical/fills" -type ListFillsRequest -responseType .FillsResponse
+type ListFillsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	orderID                *string `param:"order_id"`
+	productID              *string `param:"product_id"`
+	startSequenceTimestamp *string `param:"start_sequence_timestamp"`
+	endSequenceTimestamp   *string `param:"end_sequence_timestamp"`
+	limit                  *int    `param:"limit"`
+	cursor                 *string `param:"cursor"`
+}
+
+func (c *RestClient) NewListFillsRequest() *ListFillsRequest {
+	return &ListFillsRequest{client: c}
+}
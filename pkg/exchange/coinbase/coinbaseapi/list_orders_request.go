@@ -0,0 +1,36 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type OrdersResponse struct {
+	Orders   []Order `json:"orders"`
+	Sequence string  `json:"sequence"`
+	HasNext  bool    `json:"has_next"`
+	Cursor   string  `json:"cursor"`
+}
+
+// ListOrdersRequest queries the historical orders, the open orders can be queried with the OPEN order status.
+//
+// The start date and the end date are in the RFC3339 format.
+//
+//go:generate requestgen -method GET -url "/api/v3/brokerage/orders/historical/batch" -type ListOrdersRequest -responseType .OrdersResponse
+type ListOrdersRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	productID   *string      `param:"product_id"`
+	orderStatus *OrderStatus `param:"order_status"`
+	productType ProductType  `param:"product_type" validValues:"SPOT"`
+	startDate   *string      `param:"start_date"`
+	endDate     *string      `param:"end_date"`
+	limit       *int         `param:"limit"`
+	cursor      *string      `param:"cursor"`
+}
+
+func (c *RestClient) NewListOrdersRequest() *ListOrdersRequest {
+	return &ListOrdersRequest{
+		client:      c,
+		productType: ProductTypeSpot,
+	}
+}
@@ -0,0 +1,50 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type Product struct {
+	ProductID                string           `json:"product_id"`
+	Price                    fixedpoint.Value `json:"price"`
+	PricePercentageChange24h fixedpoint.Value `json:"price_percentage_change_24h"`
+	Volume24h                fixedpoint.Value `json:"volume_24h"`
+	BaseIncrement            fixedpoint.Value `json:"base_increment"`
+	QuoteIncrement           fixedpoint.Value `json:"quote_increment"`
+	PriceIncrement           fixedpoint.Value `json:"price_increment"`
+	BaseMinSize              fixedpoint.Value `json:"base_min_size"`
+	BaseMaxSize              fixedpoint.Value `json:"base_max_size"`
+	QuoteMinSize             fixedpoint.Value `json:"quote_min_size"`
+	QuoteMaxSize             fixedpoint.Value `json:"quote_max_size"`
+	BaseCurrencyID           string           `json:"base_currency_id"`
+	QuoteCurrencyID          string           `json:"quote_currency_id"`
+	Status                   string           `json:"status"`
+	TradingDisabled          bool             `json:"trading_disabled"`
+	CancelOnly               bool             `json:"cancel_only"`
+	LimitOnly                bool             `json:"limit_only"`
+	PostOnly                 bool             `json:"post_only"`
+	IsDisabled               bool             `json:"is_disabled"`
+	ProductType              ProductType      `json:"product_type"`
+}
+
+type ProductsResponse struct {
+	Products    []Product `json:"products"`
+	NumProducts int       `json:"num_products"`
+}
+
+//go:generate requestgen -method GET -url "/api/v3/brokerage/market/products" -type ListProductsRequest -responseType .ProductsResponse
+type ListProductsRequest struct {
+	client requestgen.APIClient
+
+	productType ProductType `param:"product_type" validValues:"SPOT"`
+	productIDs  []string    `param:"product_ids"`
+}
+
+func (c *RestClient) NewListProductsRequest() *ListProductsRequest {
+	return &ListProductsRequest{
+		client:      c,
+		productType: ProductTypeSpot,
+	}
+}
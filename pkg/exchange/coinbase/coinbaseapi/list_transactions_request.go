@@ -0,0 +1,83 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type TransactionType string
+
+const (
+	// TransactionTypeSend is the crypto transfer, the amount is negative for the outgoing transfers
+	TransactionTypeSend           TransactionType = "send"
+	TransactionTypeFiatDeposit    TransactionType = "fiat_deposit"
+	TransactionTypeFiatWithdrawal TransactionType = "fiat_withdrawal"
+)
+
+type TransactionStatus string
+
+const (
+	TransactionStatusPending   TransactionStatus = "pending"
+	TransactionStatusCompleted TransactionStatus = "completed"
+	TransactionStatusFailed    TransactionStatus = "failed"
+	TransactionStatusExpired   TransactionStatus = "expired"
+	TransactionStatusCanceled  TransactionStatus = "canceled"
+)
+
+type TransactionNetwork struct {
+	Status         string  `json:"status"`
+	Hash           string  `json:"hash"`
+	Name           string  `json:"name"`
+	TransactionFee *Amount `json:"transaction_fee,omitempty"`
+}
+
+type TransactionParty struct {
+	Resource string `json:"resource"`
+	Address  string `json:"address"`
+	Currency string `json:"currency"`
+}
+
+type Transaction struct {
+	ID           string                     `json:"id"`
+	Type         TransactionType            `json:"type"`
+	Status       TransactionStatus          `json:"status"`
+	Amount       Amount                     `json:"amount"`
+	NativeAmount Amount                     `json:"native_amount"`
+	Description  string                     `json:"description"`
+	CreatedAt    types.MillisecondTimestamp `json:"created_at"`
+	UpdatedAt    types.MillisecondTimestamp `json:"updated_at"`
+	Network      *TransactionNetwork        `json:"network,omitempty"`
+	To           *TransactionParty          `json:"to,omitempty"`
+	From         *TransactionParty          `json:"from,omitempty"`
+}
+
+type Pagination struct {
+	EndingBefore  string `json:"ending_before"`
+	StartingAfter string `json:"starting_after"`
+	Limit         int    `json:"limit"`
+	Order         string `json:"order"`
+	PreviousURI   string `json:"previous_uri"`
+	NextURI       string `json:"next_uri"`
+}
+
+type TransactionsResponse struct {
+	Pagination Pagination    `json:"pagination"`
+	Data       []Transaction `json:"data"`
+}
+
+// ListTransactionsRequest queries the transactions of the account through the v2 API,
+// the transactions are sorted by the creation time in the descending order by default.
+//
+//go:generate requestgen -method GET -url "/v2/accounts/:accountID/transactions" -type ListTransactionsRequest -responseType .TransactionsResponse
+type ListTransactionsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	accountID     string  `param:"accountID,slug,required"`
+	limit         *int    `param:"limit"`
+	startingAfter *string `param:"starting_after"`
+}
+
+func (c *RestClient) NewListTransactionsRequest() *ListTransactionsRequest {
+	return &ListTransactionsRequest{client: c}
+}
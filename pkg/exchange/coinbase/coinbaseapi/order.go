@@ -0,0 +1,145 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// the sizes and the prices of the order configuration are decimal strings
+
+type MarketIOC struct {
+	QuoteSize string `json:"quote_size,omitempty"`
+	BaseSize  string `json:"base_size,omitempty"`
+}
+
+type LimitGTC struct {
+	BaseSize   string `json:"base_size"`
+	LimitPrice string `json:"limit_price"`
+	PostOnly   bool   `json:"post_only"`
+}
+
+type LimitIOC struct {
+	BaseSize   string `json:"base_size"`
+	LimitPrice string `json:"limit_price"`
+}
+
+type LimitFOK struct {
+	BaseSize   string `json:"base_size"`
+	LimitPrice string `json:"limit_price"`
+}
+
+type StopLimitGTC struct {
+	BaseSize      string        `json:"base_size"`
+	LimitPrice    string        `json:"limit_price"`
+	StopPrice     string        `json:"stop_price"`
+	StopDirection StopDirection `json:"stop_direction"`
+}
+
+// OrderConfiguration is the union of the order types, only one of the fields is set
+type OrderConfiguration struct {
+	MarketIOC    *MarketIOC    `json:"market_market_ioc,omitempty"`
+	LimitGTC     *LimitGTC     `json:"limit_limit_gtc,omitempty"`
+	LimitIOC     *LimitIOC     `json:"sor_limit_ioc,omitempty"`
+	LimitFOK     *LimitFOK     `json:"limit_limit_fok,omitempty"`
+	StopLimitGTC *StopLimitGTC `json:"stop_limit_stop_limit_gtc,omitempty"`
+}
+
+// BaseSize returns the order quantity in the base currency, zero is returned for the market orders sized in the quote currency
+func (c OrderConfiguration) BaseSize() fixedpoint.Value {
+	switch {
+	case c.MarketIOC != nil:
+		return parseDecimal(c.MarketIOC.BaseSize)
+	case c.LimitGTC != nil:
+		return parseDecimal(c.LimitGTC.BaseSize)
+	case c.LimitIOC != nil:
+		return parseDecimal(c.LimitIOC.BaseSize)
+	case c.LimitFOK != nil:
+		return parseDecimal(c.LimitFOK.BaseSize)
+	case c.StopLimitGTC != nil:
+		return parseDecimal(c.StopLimitGTC.BaseSize)
+	}
+
+	return fixedpoint.Zero
+}
+
+func (c OrderConfiguration) LimitPrice() fixedpoint.Value {
+	switch {
+	case c.LimitGTC != nil:
+		return parseDecimal(c.LimitGTC.LimitPrice)
+	case c.LimitIOC != nil:
+		return parseDecimal(c.LimitIOC.LimitPrice)
+	case c.LimitFOK != nil:
+		return parseDecimal(c.LimitFOK.LimitPrice)
+	case c.StopLimitGTC != nil:
+		return parseDecimal(c.StopLimitGTC.LimitPrice)
+	}
+
+	return fixedpoint.Zero
+}
+
+func (c OrderConfiguration) StopPrice() fixedpoint.Value {
+	if c.StopLimitGTC != nil {
+		return parseDecimal(c.StopLimitGTC.StopPrice)
+	}
+
+	return fixedpoint.Zero
+}
+
+func (c OrderConfiguration) IsPostOnly() bool {
+	return c.LimitGTC != nil && c.LimitGTC.PostOnly
+}
+
+func parseDecimal(s string) fixedpoint.Value {
+	if s == "" {
+		return fixedpoint.Zero
+	}
+
+	v, err := fixedpoint.NewFromString(s)
+	if err != nil {
+		return fixedpoint.Zero
+	}
+	return v
+}
+
+type Order struct {
+	OrderID              string                      `json:"order_id"`
+	ProductID            string                      `json:"product_id"`
+	UserID               string                      `json:"user_id"`
+	OrderConfiguration   OrderConfiguration          `json:"order_configuration"`
+	Side                 OrderSide                   `json:"side"`
+	ClientOrderID        string                      `json:"client_order_id"`
+	Status               OrderStatus                 `json:"status"`
+	TimeInForce          TimeInForce                 `json:"time_in_force"`
+	CreatedTime          types.MillisecondTimestamp  `json:"created_time"`
+	CompletionPercentage fixedpoint.Value            `json:"completion_percentage"`
+	FilledSize           fixedpoint.Value            `json:"filled_size"`
+	AverageFilledPrice   fixedpoint.Value            `json:"average_filled_price"`
+	NumberOfFills        string                      `json:"number_of_fills"`
+	FilledValue          fixedpoint.Value            `json:"filled_value"`
+	PendingCancel        bool                        `json:"pending_cancel"`
+	SizeInQuote          bool                        `json:"size_in_quote"`
+	TotalFees            fixedpoint.Value            `json:"total_fees"`
+	TotalValueAfterFees  fixedpoint.Value            `json:"total_value_after_fees"`
+	OrderType            OrderType                   `json:"order_type"`
+	RejectReason         string                      `json:"reject_reason"`
+	Settled              bool                        `json:"settled"`
+	ProductType          ProductType                 `json:"product_type"`
+	LastFillTime         *types.MillisecondTimestamp `json:"last_fill_time"`
+}
+
+type Fill struct {
+	EntryID            string                     `json:"entry_id"`
+	TradeID            string                     `json:"trade_id"`
+	OrderID            string                     `json:"order_id"`
+	TradeTime          types.MillisecondTimestamp `json:"trade_time"`
+	TradeType          string                     `json:"trade_type"`
+	Price              fixedpoint.Value           `json:"price"`
+	Size               fixedpoint.Value           `json:"size"`
+	Commission         fixedpoint.Value           `json:"commission"`
+	ProductID          string                     `json:"product_id"`
+	SequenceTimestamp  types.MillisecondTimestamp `json:"sequence_timestamp"`
+	LiquidityIndicator LiquidityIndicator         `json:"liquidity_indicator"`
+	SizeInQuote        bool                       `json:"size_in_quote"`
+	UserID             string                     `json:"user_id"`
+	Side               OrderSide                  `json:"side"`
+}
@@ -0,0 +1,15 @@
+package coinbaseapi
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+// Limiter tracks the requests of the Coinbase api, the private endpoints allow 30 requests per second,
+// and the public market data endpoints allow 10 requests per second.
+// The market data requests are counted in the private rule as well, which keeps the limiter conservative.
+var Limiter = ratelimit.Register(ratelimit.New("coinbase",
+	ratelimit.Rule{Name: "private", Limit: 25, Interval: time.Second, DefaultWeight: 1},
+	ratelimit.Rule{Name: "public", Limit: 9, Interval: time.Second, PathPrefix: advancedTradePathPrefix + "/market/", DefaultWeight: 1},
+))
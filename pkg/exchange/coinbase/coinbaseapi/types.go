@@ -0,0 +1,83 @@
+package coinbaseapi
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type ProductType string
+
+const (
+	ProductTypeSpot   ProductType = "SPOT"
+	ProductTypeFuture ProductType = "FUTURE"
+)
+
+type OrderSide string
+
+const (
+	OrderSideBuy  OrderSide = "BUY"
+	OrderSideSell OrderSide = "SELL"
+)
+
+type OrderType string
+
+const (
+	OrderTypeMarket    OrderType = "MARKET"
+	OrderTypeLimit     OrderType = "LIMIT"
+	OrderTypeStop      OrderType = "STOP"
+	OrderTypeStopLimit OrderType = "STOP_LIMIT"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending      OrderStatus = "PENDING"
+	OrderStatusOpen         OrderStatus = "OPEN"
+	OrderStatusFilled       OrderStatus = "FILLED"
+	OrderStatusCancelled    OrderStatus = "CANCELLED"
+	OrderStatusExpired      OrderStatus = "EXPIRED"
+	OrderStatusFailed       OrderStatus = "FAILED"
+	OrderStatusQueued       OrderStatus = "QUEUED"
+	OrderStatusCancelQueued OrderStatus = "CANCEL_QUEUED"
+)
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GOOD_UNTIL_CANCELLED"
+	TimeInForceGTD TimeInForce = "GOOD_UNTIL_DATE_TIME"
+	TimeInForceIOC TimeInForce = "IMMEDIATE_OR_CANCEL"
+	TimeInForceFOK TimeInForce = "FILL_OR_KILL"
+)
+
+type StopDirection string
+
+const (
+	StopDirectionUp   StopDirection = "STOP_DIRECTION_STOP_UP"
+	StopDirectionDown StopDirection = "STOP_DIRECTION_STOP_DOWN"
+)
+
+type Granularity string
+
+const (
+	GranularityOneMinute     Granularity = "ONE_MINUTE"
+	GranularityFiveMinute    Granularity = "FIVE_MINUTE"
+	GranularityFifteenMinute Granularity = "FIFTEEN_MINUTE"
+	GranularityThirtyMinute  Granularity = "THIRTY_MINUTE"
+	GranularityOneHour       Granularity = "ONE_HOUR"
+	GranularityTwoHour       Granularity = "TWO_HOUR"
+	GranularitySixHour       Granularity = "SIX_HOUR"
+	GranularityOneDay        Granularity = "ONE_DAY"
+)
+
+type LiquidityIndicator string
+
+const (
+	LiquidityIndicatorMaker LiquidityIndicator = "MAKER"
+	LiquidityIndicatorTaker LiquidityIndicator = "TAKER"
+)
+
+// Amount is the amount object of the v2 API
+type Amount struct {
+	Amount   fixedpoint.Value `json:"amount"`
+	Currency string           `json:"currency"`
+}
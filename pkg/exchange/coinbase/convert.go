@@ -0,0 +1,371 @@
+package coinbase
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+//go:generate go run generate_symbol_map.go
+
+func toGlobalSymbol(symbol string) string {
+	return strings.ReplaceAll(symbol, "-", "")
+}
+
+func toGlobalMarket(p coinbaseapi.Product) types.Market {
+	base, quote := splitProductID(p.ProductID)
+	if p.BaseCurrencyID != "" {
+		base = p.BaseCurrencyID
+	}
+	if p.QuoteCurrencyID != "" {
+		quote = p.QuoteCurrencyID
+	}
+
+	tickSize := p.PriceIncrement
+	if tickSize.IsZero() {
+		tickSize = p.QuoteIncrement
+	}
+
+	return types.Market{
+		Symbol:          toGlobalSymbol(p.ProductID),
+		LocalSymbol:     p.ProductID,
+		PricePrecision:  tickSize.NumFractionalDigits(),
+		VolumePrecision: p.BaseIncrement.NumFractionalDigits(),
+		QuoteCurrency:   quote,
+		BaseCurrency:    base,
+		MinNotional:     p.QuoteMinSize,
+		MinAmount:       p.QuoteMinSize,
+		MinQuantity:     p.BaseMinSize,
+		MaxQuantity:     p.BaseMaxSize,
+		StepSize:        p.BaseIncrement,
+
+		MinPrice: fixedpoint.Zero, // not used
+		MaxPrice: fixedpoint.Zero, // not used
+		TickSize: tickSize,
+	}
+}
+
+func splitProductID(productID string) (base, quote string) {
+	parts := strings.SplitN(productID, "-", 2)
+	if len(parts) != 2 {
+		return productID, ""
+	}
+	return parts[0], parts[1]
+}
+
+var granularities = map[types.Interval]coinbaseapi.Granularity{
+	types.Interval1m:  coinbaseapi.GranularityOneMinute,
+	types.Interval5m:  coinbaseapi.GranularityFiveMinute,
+	types.Interval15m: coinbaseapi.GranularityFifteenMinute,
+	types.Interval30m: coinbaseapi.GranularityThirtyMinute,
+	types.Interval1h:  coinbaseapi.GranularityOneHour,
+	types.Interval2h:  coinbaseapi.GranularityTwoHour,
+	types.Interval6h:  coinbaseapi.GranularitySixHour,
+	types.Interval1d:  coinbaseapi.GranularityOneDay,
+}
+
+func toLocalGranularity(interval types.Interval) (coinbaseapi.Granularity, error) {
+	granularity, ok := granularities[interval]
+	if !ok {
+		return "", fmt.Errorf("interval %s is not supported by coinbase", interval)
+	}
+	return granularity, nil
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, c coinbaseapi.Candle) types.KLine {
+	startTime := c.Start.Time()
+	return types.KLine{
+		Exchange:  types.ExchangeCoinbase,
+		Symbol:    symbol,
+		StartTime: types.Time(startTime),
+		EndTime:   types.Time(startTime.Add(interval.Duration() - time.Millisecond)),
+		Interval:  interval,
+		Open:      c.Open,
+		Close:     c.Close,
+		High:      c.High,
+		Low:       c.Low,
+		Volume:    c.Volume,
+		Closed:    true,
+	}
+}
+
+func toLocalSide(side types.SideType) (coinbaseapi.OrderSide, error) {
+	switch side {
+	case types.SideTypeBuy:
+		return coinbaseapi.OrderSideBuy, nil
+
+	case types.SideTypeSell:
+		return coinbaseapi.OrderSideSell, nil
+
+	}
+
+	return "", fmt.Errorf("side type %s is not supported by coinbase", side)
+}
+
+func toGlobalSide(side coinbaseapi.OrderSide) types.SideType {
+	switch side {
+	case coinbaseapi.OrderSideBuy:
+		return types.SideTypeBuy
+
+	case coinbaseapi.OrderSideSell:
+		return types.SideTypeSell
+
+	}
+
+	return types.SideTypeSelf
+}
+
+func toGlobalOrderType(orderType coinbaseapi.OrderType, postOnly bool) types.OrderType {
+	switch orderType {
+	case coinbaseapi.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case coinbaseapi.OrderTypeLimit:
+		if postOnly {
+			return types.OrderTypeLimitMaker
+		}
+		return types.OrderTypeLimit
+
+	case coinbaseapi.OrderTypeStop:
+		return types.OrderTypeStopMarket
+
+	case coinbaseapi.OrderTypeStopLimit:
+		return types.OrderTypeStopLimit
+
+	}
+
+	return types.OrderType(orderType)
+}
+
+func toGlobalOrderStatus(status coinbaseapi.OrderStatus, filledQuantity fixedpoint.Value) types.OrderStatus {
+	switch status {
+	case coinbaseapi.OrderStatusPending, coinbaseapi.OrderStatusQueued, coinbaseapi.OrderStatusOpen, coinbaseapi.OrderStatusCancelQueued:
+		if filledQuantity.Sign() > 0 {
+			return types.OrderStatusPartiallyFilled
+		}
+		return types.OrderStatusNew
+
+	case coinbaseapi.OrderStatusFilled:
+		return types.OrderStatusFilled
+
+	case coinbaseapi.OrderStatusCancelled, coinbaseapi.OrderStatusExpired:
+		return types.OrderStatusCanceled
+
+	case coinbaseapi.OrderStatusFailed:
+		return types.OrderStatusRejected
+
+	}
+
+	return types.OrderStatus(status)
+}
+
+func isWorkingOrderStatus(status coinbaseapi.OrderStatus) bool {
+	switch status {
+	case coinbaseapi.OrderStatusPending, coinbaseapi.OrderStatusQueued, coinbaseapi.OrderStatusOpen, coinbaseapi.OrderStatusCancelQueued:
+		return true
+	}
+
+	return false
+}
+
+func toGlobalTimeInForce(tif coinbaseapi.TimeInForce) types.TimeInForce {
+	switch tif {
+	case coinbaseapi.TimeInForceIOC:
+		return types.TimeInForceIOC
+
+	case coinbaseapi.TimeInForceFOK:
+		return types.TimeInForceFOK
+
+	}
+
+	return types.TimeInForceGTC
+}
+
+func toGlobalOrder(o coinbaseapi.Order) types.Order {
+	config := o.OrderConfiguration
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        toGlobalSymbol(o.ProductID),
+			Side:          toGlobalSide(o.Side),
+			Type:          toGlobalOrderType(o.OrderType, config.IsPostOnly()),
+			Quantity:      config.BaseSize(),
+			Price:         config.LimitPrice(),
+			StopPrice:     config.StopPrice(),
+			TimeInForce:   toGlobalTimeInForce(o.TimeInForce),
+		},
+		Exchange:         types.ExchangeCoinbase,
+		OrderID:          hashStringID(o.OrderID),
+		UUID:             o.OrderID,
+		Status:           toGlobalOrderStatus(o.Status, o.FilledSize),
+		ExecutedQuantity: o.FilledSize,
+		IsWorking:        isWorkingOrderStatus(o.Status),
+		CreationTime:     types.Time(o.CreatedTime.Time()),
+		UpdateTime:       types.Time(updateTime(o)),
+	}
+}
+
+func updateTime(o coinbaseapi.Order) time.Time {
+	// last_fill_time is null if the order is not filled
+	if o.LastFillTime != nil && !o.LastFillTime.Time().IsZero() {
+		return o.LastFillTime.Time()
+	}
+	return o.CreatedTime.Time()
+}
+
+// toGlobalTrade converts the fill to the trade, the commission of the spot fills is charged in the quote currency
+func toGlobalTrade(fill coinbaseapi.Fill) types.Trade {
+	_, quote := splitProductID(fill.ProductID)
+
+	quantity := fill.Size
+	quoteQuantity := fill.Price.Mul(fill.Size)
+	if fill.SizeInQuote {
+		quoteQuantity = fill.Size
+		quantity = fill.Size.Div(fill.Price)
+	}
+
+	return types.Trade{
+		ID:            hashStringID(fill.TradeID),
+		OrderID:       hashStringID(fill.OrderID),
+		Exchange:      types.ExchangeCoinbase,
+		Price:         fill.Price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        toGlobalSymbol(fill.ProductID),
+		Side:          toGlobalSide(fill.Side),
+		IsBuyer:       fill.Side == coinbaseapi.OrderSideBuy,
+		IsMaker:       fill.LiquidityIndicator == coinbaseapi.LiquidityIndicatorMaker,
+		Time:          types.Time(fill.TradeTime.Time()),
+		Fee:           fill.Commission,
+		FeeCurrency:   quote,
+	}
+}
+
+func toGlobalMarketTrade(t coinbaseapi.MarketTrade) types.Trade {
+	return types.Trade{
+		ID:            hashStringID(t.TradeID),
+		Exchange:      types.ExchangeCoinbase,
+		Price:         t.Price,
+		Quantity:      t.Size,
+		QuoteQuantity: t.Price.Mul(t.Size),
+		Symbol:        toGlobalSymbol(t.ProductID),
+		Side:          toGlobalSide(t.Side),
+		IsBuyer:       t.Side == coinbaseapi.OrderSideBuy,
+		Time:          types.Time(t.Time.Time()),
+	}
+}
+
+func toGlobalBalanceMap(accounts []coinbaseapi.Account) types.BalanceMap {
+	balances := types.BalanceMap{}
+	for _, account := range accounts {
+		balance, ok := balances[account.Currency]
+		if !ok {
+			balance = types.Balance{Currency: account.Currency}
+		}
+
+		// there could be multiple accounts (wallets) of the same currency
+		balance.Available = balance.Available.Add(account.AvailableBalance.Value)
+		balance.Locked = balance.Locked.Add(account.Hold.Value)
+		balances[account.Currency] = balance
+	}
+
+	return balances
+}
+
+func toGlobalDepositStatus(status coinbaseapi.TransactionStatus) types.DepositStatus {
+	switch status {
+	case coinbaseapi.TransactionStatusPending:
+		return types.DepositPending
+
+	case coinbaseapi.TransactionStatusCompleted:
+		return types.DepositSuccess
+
+	case coinbaseapi.TransactionStatusFailed:
+		return types.DepositRejected
+
+	case coinbaseapi.TransactionStatusCanceled, coinbaseapi.TransactionStatusExpired:
+		return types.DepositCancelled
+
+	}
+
+	return types.DepositStatus(status)
+}
+
+// isDepositTransaction checks if the transaction is an incoming transfer,
+// the incoming crypto transfers are the send transactions with the positive amount.
+func isDepositTransaction(t coinbaseapi.Transaction) bool {
+	switch t.Type {
+	case coinbaseapi.TransactionTypeFiatDeposit:
+		return true
+	case coinbaseapi.TransactionTypeSend:
+		return t.Amount.Amount.Sign() > 0
+	}
+	return false
+}
+
+func isWithdrawTransaction(t coinbaseapi.Transaction) bool {
+	switch t.Type {
+	case coinbaseapi.TransactionTypeFiatWithdrawal:
+		return true
+	case coinbaseapi.TransactionTypeSend:
+		return t.Amount.Amount.Sign() < 0
+	}
+	return false
+}
+
+func toGlobalDeposit(t coinbaseapi.Transaction) types.Deposit {
+	deposit := types.Deposit{
+		Exchange: types.ExchangeCoinbase,
+		Time:     types.Time(t.CreatedAt.Time()),
+		Amount:   t.Amount.Amount.Abs(),
+		Asset:    t.Amount.Currency,
+		Status:   toGlobalDepositStatus(t.Status),
+	}
+
+	if t.Network != nil {
+		deposit.TransactionID = t.Network.Hash
+	}
+
+	if t.From != nil {
+		deposit.Address = t.From.Address
+	}
+
+	return deposit
+}
+
+func toGlobalWithdraw(t coinbaseapi.Transaction) types.Withdraw {
+	withdraw := types.Withdraw{
+		Exchange:        types.ExchangeCoinbase,
+		Asset:           t.Amount.Currency,
+		Amount:          t.Amount.Amount.Abs(),
+		Status:          string(t.Status),
+		WithdrawOrderID: t.ID,
+		ApplyTime:       types.Time(t.CreatedAt.Time()),
+	}
+
+	if t.Network != nil {
+		withdraw.TransactionID = t.Network.Hash
+		withdraw.Network = t.Network.Name
+		if t.Network.TransactionFee != nil {
+			withdraw.TransactionFee = t.Network.TransactionFee.Amount
+			withdraw.TransactionFeeCurrency = t.Network.TransactionFee.Currency
+		}
+	}
+
+	if t.To != nil {
+		withdraw.Address = t.To.Address
+	}
+
+	return withdraw
+}
+
+func hashStringID(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
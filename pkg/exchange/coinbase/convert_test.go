@@ -0,0 +1,61 @@
+package coinbase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_toGlobalSymbol(t *testing.T) {
+	assert.Equal(t, "BTCUSD", toGlobalSymbol("BTC-USD"))
+	assert.Equal(t, "BTC-USD", toLocalSymbol("BTCUSD"))
+	assert.Equal(t, "ETHBTC", toGlobalSymbol(toLocalSymbol("ETHBTC")))
+}
+
+func Test_toGlobalOrderStatus(t *testing.T) {
+	assert.Equal(t, types.OrderStatusNew, toGlobalOrderStatus(coinbaseapi.OrderStatusOpen, fixedpoint.Zero))
+	assert.Equal(t, types.OrderStatusPartiallyFilled, toGlobalOrderStatus(coinbaseapi.OrderStatusOpen, fixedpoint.One))
+	assert.Equal(t, types.OrderStatusFilled, toGlobalOrderStatus(coinbaseapi.OrderStatusFilled, fixedpoint.One))
+	assert.Equal(t, types.OrderStatusCanceled, toGlobalOrderStatus(coinbaseapi.OrderStatusExpired, fixedpoint.Zero))
+	assert.Equal(t, types.OrderStatusRejected, toGlobalOrderStatus(coinbaseapi.OrderStatusFailed, fixedpoint.Zero))
+}
+
+func Test_toGlobalTrade_SizeInQuote(t *testing.T) {
+	trade := toGlobalTrade(coinbaseapi.Fill{
+		TradeID:            "trade",
+		OrderID:            "order",
+		Price:              fixedpoint.NewFromInt(20000),
+		Size:               fixedpoint.NewFromInt(100),
+		Commission:         fixedpoint.MustNewFromString("0.6"),
+		ProductID:          "BTC-USD",
+		LiquidityIndicator: coinbaseapi.LiquidityIndicatorTaker,
+		SizeInQuote:        true,
+		Side:               coinbaseapi.OrderSideBuy,
+	})
+
+	assert.Equal(t, fixedpoint.MustNewFromString("0.005"), trade.Quantity)
+	assert.Equal(t, fixedpoint.NewFromInt(100), trade.QuoteQuantity)
+	assert.Equal(t, "USD", trade.FeeCurrency)
+	assert.True(t, trade.IsBuyer)
+	assert.False(t, trade.IsMaker)
+}
+
+func Test_isDepositTransaction(t *testing.T) {
+	send := func(amount string) coinbaseapi.Transaction {
+		return coinbaseapi.Transaction{
+			Type:   coinbaseapi.TransactionTypeSend,
+			Amount: coinbaseapi.Amount{Amount: fixedpoint.MustNewFromString(amount), Currency: "BTC"},
+		}
+	}
+
+	assert.True(t, isDepositTransaction(send("0.1")))
+	assert.False(t, isWithdrawTransaction(send("0.1")))
+	assert.True(t, isWithdrawTransaction(send("-0.1")))
+	assert.False(t, isDepositTransaction(send("-0.1")))
+	assert.True(t, isDepositTransaction(coinbaseapi.Transaction{Type: coinbaseapi.TransactionTypeFiatDeposit}))
+	assert.True(t, isWithdrawTransaction(coinbaseapi.Transaction{Type: coinbaseapi.TransactionTypeFiatWithdrawal}))
+}
@@ -0,0 +1,791 @@
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// https://docs.cloud.coinbase.com/advanced-trade-api/docs/rest-api-rate-limits
+var marketDataLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+var queryOrderLimiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 10)
+var queryTradeLimiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 10)
+var submitOrderLimiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 10)
+var cancelOrderLimiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 10)
+var transferLimiter = rate.NewLimiter(rate.Every(100*time.Millisecond), 5)
+
+const (
+	// maxCandleLimit is the max number of the candles of one query
+	maxCandleLimit = 350
+
+	// maxAccountLimit is the max page size of the account query
+	maxAccountLimit = 250
+
+	// maxOrderLimit is the max page size of the order and the fill queries
+	maxOrderLimit = 100
+
+	// maxCancelOrders is the max number of the orders of one batch cancel request
+	maxCancelOrders = 100
+
+	// maxTransactionLimit is the max page size of the v2 transaction query
+	maxTransactionLimit = 100
+)
+
+var log = logrus.WithFields(logrus.Fields{
+	"exchange": "coinbase",
+})
+
+type Exchange struct {
+	key, secret string
+	client      *coinbaseapi.RestClient
+
+	// accountIDs maps the currency to the account uuid, it's used for querying the transfers
+	accountIDsMutex sync.Mutex
+	accountIDs      map[string]string
+}
+
+func New(key, secret string) *Exchange {
+	client := coinbaseapi.NewClient()
+
+	// for public access mode
+	if len(key) > 0 && len(secret) > 0 {
+		client.Auth(key, secret)
+	}
+
+	return &Exchange{
+		key: key,
+		// pragma: allowlist nextline secret
+		secret: secret,
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeCoinbase
+}
+
+// PlatformFeeCurrency returns the empty string, coinbase does not deduct the trading fee from the platform token
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+// DefaultFeeRates returns the fee rates of the lowest tier
+func (e *Exchange) DefaultFeeRates() types.ExchangeFee {
+	return types.ExchangeFee{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.400), // 0.4%
+		TakerFeeRate: fixedpoint.NewFromFloat(0.01 * 0.600), // 0.6%
+	}
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.NewListProductsRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	marketMap := types.MarketMap{}
+	for _, p := range resp.Products {
+		if p.IsDisabled || p.TradingDisabled {
+			continue
+		}
+
+		marketMap.Add(toGlobalMarket(p))
+	}
+
+	return marketMap, nil
+}
+
+// QueryTicker queries the last price and the 24h volume from the product, and the best bid/ask from the product ticker.
+// coinbase does not provide the 24h high/low price in the advanced trade API.
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	productID := toLocalSymbol(symbol)
+	product, err := e.client.NewGetProductRequest().ProductID(productID).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	productTicker, err := e.client.NewGetProductTickerRequest().ProductID(productID).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Ticker{
+		Time:   time.Now(),
+		Volume: product.Volume24h,
+		Last:   product.Price,
+		Buy:    productTicker.BestBid,
+		Sell:   productTicker.BestAsk,
+	}, nil
+}
+
+// QueryTickers queries the tickers of the given symbols, only the last price and the 24h volume are
+// returned if no symbol is given, since the best bid/ask has to be queried product by product.
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	tickers := make(map[string]types.Ticker)
+	if len(symbols) > 0 {
+		for _, symbol := range symbols {
+			ticker, err := e.QueryTicker(ctx, symbol)
+			if err != nil {
+				return nil, err
+			}
+
+			tickers[symbol] = *ticker
+		}
+
+		return tickers, nil
+	}
+
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.NewListProductsRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, p := range resp.Products {
+		tickers[toGlobalSymbol(p.ProductID)] = types.Ticker{
+			Time:   now,
+			Volume: p.Volume24h,
+			Last:   p.Price,
+		}
+	}
+
+	return tickers, nil
+}
+
+var supportedIntervals = map[types.Interval]int{
+	types.Interval1m:  1 * 60,
+	types.Interval5m:  5 * 60,
+	types.Interval15m: 15 * 60,
+	types.Interval30m: 30 * 60,
+	types.Interval1h:  60 * 60,
+	types.Interval2h:  60 * 60 * 2,
+	types.Interval6h:  60 * 60 * 6,
+	types.Interval1d:  60 * 60 * 24,
+}
+
+func (e *Exchange) SupportedInterval() map[types.Interval]int {
+	return supportedIntervals
+}
+
+func (e *Exchange) IsSupportedInterval(interval types.Interval) bool {
+	_, ok := supportedIntervals[interval]
+	return ok
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	granularity, err := toLocalGranularity(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := maxCandleLimit
+	if options.Limit > 0 && options.Limit < limit {
+		limit = options.Limit
+	}
+
+	// both the start time and the end time are required
+	endTime := time.Now()
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	startTime := endTime.Add(-time.Duration(limit) * interval.Duration())
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+		if options.EndTime == nil {
+			endTime = startTime.Add(time.Duration(limit) * interval.Duration())
+		}
+	}
+
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.NewGetCandlesRequest().
+		ProductID(toLocalSymbol(symbol)).
+		Granularity(granularity).
+		StartTime(startTime).
+		EndTime(endTime).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var klines []types.KLine
+	for _, c := range resp.Candles {
+		klines = append(klines, toGlobalKLine(symbol, interval, c))
+	}
+
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].StartTime.Before(klines[j].StartTime.Time())
+	})
+
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+
+	return klines, nil
+}
+
+func (e *Exchange) queryAccounts(ctx context.Context) ([]coinbaseapi.Account, error) {
+	var accounts []coinbaseapi.Account
+	cursor := ""
+	for {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req := e.client.NewListAccountsRequest().Limit(maxAccountLimit)
+		if cursor != "" {
+			req.Cursor(cursor)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, resp.Accounts...)
+		if !resp.HasNext || resp.Cursor == "" {
+			break
+		}
+
+		cursor = resp.Cursor
+	}
+
+	accountIDs := make(map[string]string)
+	for _, account := range accounts {
+		// the default account is the one used by the advanced trade
+		if _, ok := accountIDs[account.Currency]; !ok || account.Default {
+			accountIDs[account.Currency] = account.UUID
+		}
+	}
+
+	e.accountIDsMutex.Lock()
+	e.accountIDs = accountIDs
+	e.accountIDsMutex.Unlock()
+	return accounts, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	accounts, err := e.queryAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalBalanceMap(accounts), nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		createdOrder, err := e.submitOrder(ctx, order)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		createdOrders = append(createdOrders, *createdOrder)
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) submitOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	side, err := toLocalSide(order.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := e.toLocalOrderConfiguration(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	req := e.client.NewCreateOrderRequest().
+		ProductID(toLocalSymbol(order.Symbol)).
+		Side(side).
+		OrderConfiguration(*config)
+
+	if order.ClientOrderID != "" && order.ClientOrderID != types.NoClientOrderID {
+		req.ClientOrderID(order.ClientOrderID)
+	}
+
+	if err := submitOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("coinbase order rejected: %s %s %s",
+			resp.FailureReason, resp.ErrorResponse.Error, resp.ErrorResponse.Message)
+	}
+
+	now := time.Now()
+	orderID := resp.SuccessResponse.OrderID
+	if orderID == "" {
+		orderID = resp.OrderID
+	}
+
+	if order.ClientOrderID == "" || order.ClientOrderID == types.NoClientOrderID {
+		order.ClientOrderID = resp.SuccessResponse.ClientOrderID
+	}
+
+	return &types.Order{
+		SubmitOrder:      order,
+		Exchange:         types.ExchangeCoinbase,
+		OrderID:          hashStringID(orderID),
+		UUID:             orderID,
+		Status:           types.OrderStatusNew,
+		ExecutedQuantity: fixedpoint.Zero,
+		IsWorking:        true,
+		CreationTime:     types.Time(now),
+		UpdateTime:       types.Time(now),
+	}, nil
+}
+
+// toLocalOrderConfiguration converts the order type and the time in force to the order configuration,
+// the market buy orders are sized in the quote currency since coinbase only accepts the quote size for them.
+func (e *Exchange) toLocalOrderConfiguration(ctx context.Context, order types.SubmitOrder) (*coinbaseapi.OrderConfiguration, error) {
+	quantity := order.Market.FormatQuantity(order.Quantity)
+	price := order.Market.FormatPrice(order.Price)
+
+	switch order.Type {
+	case types.OrderTypeMarket:
+		if order.Side == types.SideTypeSell {
+			return &coinbaseapi.OrderConfiguration{
+				MarketIOC: &coinbaseapi.MarketIOC{BaseSize: quantity},
+			}, nil
+		}
+
+		askPrice := order.Price
+		if askPrice.IsZero() {
+			ticker, err := e.QueryTicker(ctx, order.Symbol)
+			if err != nil {
+				return nil, errors.Wrapf(err, "can not query the ticker for the market buy order quote size")
+			}
+
+			askPrice = ticker.Sell
+		}
+
+		return &coinbaseapi.OrderConfiguration{
+			MarketIOC: &coinbaseapi.MarketIOC{QuoteSize: order.Market.FormatPrice(order.Quantity.Mul(askPrice))},
+		}, nil
+
+	case types.OrderTypeLimitMaker:
+		return &coinbaseapi.OrderConfiguration{
+			LimitGTC: &coinbaseapi.LimitGTC{BaseSize: quantity, LimitPrice: price, PostOnly: true},
+		}, nil
+
+	case types.OrderTypeLimit:
+		switch order.TimeInForce {
+		case types.TimeInForceIOC:
+			return &coinbaseapi.OrderConfiguration{
+				LimitIOC: &coinbaseapi.LimitIOC{BaseSize: quantity, LimitPrice: price},
+			}, nil
+
+		case types.TimeInForceFOK:
+			return &coinbaseapi.OrderConfiguration{
+				LimitFOK: &coinbaseapi.LimitFOK{BaseSize: quantity, LimitPrice: price},
+			}, nil
+
+		}
+
+		return &coinbaseapi.OrderConfiguration{
+			LimitGTC: &coinbaseapi.LimitGTC{BaseSize: quantity, LimitPrice: price},
+		}, nil
+
+	case types.OrderTypeStopLimit:
+		direction := coinbaseapi.StopDirectionUp
+		if order.Side == types.SideTypeSell {
+			direction = coinbaseapi.StopDirectionDown
+		}
+
+		return &coinbaseapi.OrderConfiguration{
+			StopLimitGTC: &coinbaseapi.StopLimitGTC{
+				BaseSize:      quantity,
+				LimitPrice:    price,
+				StopPrice:     order.Market.FormatPrice(order.StopPrice),
+				StopDirection: direction,
+			},
+		}, nil
+
+	}
+
+	return nil, fmt.Errorf("order type %s is not supported by coinbase", order.Type)
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	status := coinbaseapi.OrderStatusOpen
+	return e.queryOrders(ctx, symbol, &status, time.Time{}, time.Time{})
+}
+
+func (e *Exchange) queryOrders(ctx context.Context, symbol string, status *coinbaseapi.OrderStatus, since, until time.Time) (orders []types.Order, err error) {
+	cursor := ""
+	for {
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return orders, err
+		}
+
+		req := e.client.NewListOrdersRequest().Limit(maxOrderLimit)
+		if symbol != "" {
+			req.ProductID(toLocalSymbol(symbol))
+		}
+		if status != nil {
+			req.OrderStatus(*status)
+		}
+		if !since.IsZero() {
+			req.StartDate(since.UTC().Format(time.RFC3339))
+		}
+		if !until.IsZero() {
+			req.EndDate(until.UTC().Format(time.RFC3339))
+		}
+		if cursor != "" {
+			req.Cursor(cursor)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		for _, o := range resp.Orders {
+			orders = append(orders, toGlobalOrder(o))
+		}
+
+		if !resp.HasNext || resp.Cursor == "" {
+			break
+		}
+
+		cursor = resp.Cursor
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (errs error) {
+	var orderIDs []string
+	for _, o := range orders {
+		if o.UUID == "" {
+			errs = multierr.Append(errs, fmt.Errorf("the order uuid is required for canceling the coinbase order %d", o.OrderID))
+			continue
+		}
+
+		orderIDs = append(orderIDs, o.UUID)
+	}
+
+	for len(orderIDs) > 0 {
+		n := len(orderIDs)
+		if n > maxCancelOrders {
+			n = maxCancelOrders
+		}
+
+		if err := cancelOrderLimiter.Wait(ctx); err != nil {
+			return multierr.Append(errs, err)
+		}
+
+		resp, err := e.client.NewCancelOrdersRequest().OrderIDs(orderIDs[:n]).Do(ctx)
+		if err != nil {
+			errs = multierr.Append(errs, errors.Wrapf(err, "failed to cancel orders %v", orderIDs[:n]))
+		} else {
+			for _, result := range resp.Results {
+				if !result.Success {
+					errs = multierr.Append(errs, fmt.Errorf("failed to cancel order %s: %s", result.OrderID, result.FailureReason))
+				}
+			}
+		}
+
+		orderIDs = orderIDs[n:]
+	}
+
+	return errs
+}
+
+// QueryOrder queries the order by the order uuid, coinbase does not support querying the order by the client order id
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	if q.OrderID == "" {
+		return nil, errors.New("the order uuid is required for querying the coinbase order")
+	}
+
+	if err := queryOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.NewGetOrderRequest().OrderID(q.OrderID).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	o := toGlobalOrder(resp.Order)
+	return &o, nil
+}
+
+// QueryClosedOrders queries the closed orders by the creation time, the lastOrderID is not supported
+// since the order ids are uuids.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	if until.IsZero() {
+		until = time.Now()
+	}
+
+	allOrders, err := e.queryOrders(ctx, symbol, nil, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range allOrders {
+		if o.IsWorking {
+			continue
+		}
+
+		orders = append(orders, o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime.Time())
+	})
+
+	return orders, nil
+}
+
+// QueryTrades queries the fills by the time range, the LastTradeID option is not supported since the trade ids are uuids.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	cursor := ""
+	for {
+		if err := queryTradeLimiter.Wait(ctx); err != nil {
+			return trades, err
+		}
+
+		req := e.client.NewListFillsRequest().
+			ProductID(toLocalSymbol(symbol)).
+			Limit(maxOrderLimit)
+		if options.StartTime != nil {
+			req.StartSequenceTimestamp(options.StartTime.UTC().Format(time.RFC3339))
+		}
+		if options.EndTime != nil {
+			req.EndSequenceTimestamp(options.EndTime.UTC().Format(time.RFC3339))
+		}
+		if cursor != "" {
+			req.Cursor(cursor)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return trades, err
+		}
+
+		for _, fill := range resp.Fills {
+			trades = append(trades, toGlobalTrade(fill))
+		}
+
+		if resp.Cursor == "" || len(resp.Fills) < maxOrderLimit {
+			break
+		}
+
+		cursor = resp.Cursor
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time.Time())
+	})
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, nil
+}
+
+// queryOrderTrades queries the fills of the given order, it's used by the user data stream for the trade updates
+func (e *Exchange) queryOrderTrades(ctx context.Context, orderID string) (trades []types.Trade, err error) {
+	if err := queryTradeLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.NewListFillsRequest().OrderID(orderID).Limit(maxOrderLimit).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fill := range resp.Fills {
+		trades = append(trades, toGlobalTrade(fill))
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) accountID(ctx context.Context, asset string) (string, error) {
+	e.accountIDsMutex.Lock()
+	accountIDs := e.accountIDs
+	e.accountIDsMutex.Unlock()
+
+	if accountIDs == nil {
+		if _, err := e.queryAccounts(ctx); err != nil {
+			return "", err
+		}
+
+		e.accountIDsMutex.Lock()
+		accountIDs = e.accountIDs
+		e.accountIDsMutex.Unlock()
+	}
+
+	accountID, ok := accountIDs[asset]
+	if !ok {
+		return "", fmt.Errorf("account of the asset %s is not found", asset)
+	}
+
+	return accountID, nil
+}
+
+// queryTransactions queries the v2 transactions of the asset account in the given time range,
+// the transactions are responded in the descending order of the creation time.
+func (e *Exchange) queryTransactions(ctx context.Context, asset string, since, until time.Time) ([]coinbaseapi.Transaction, error) {
+	accountID, err := e.accountID(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	if until.IsZero() {
+		until = time.Now()
+	}
+
+	var transactions []coinbaseapi.Transaction
+	startingAfter := ""
+	for {
+		if err := transferLimiter.Wait(ctx); err != nil {
+			return transactions, err
+		}
+
+		req := e.client.NewListTransactionsRequest().AccountID(accountID).Limit(maxTransactionLimit)
+		if startingAfter != "" {
+			req.StartingAfter(startingAfter)
+		}
+
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return transactions, err
+		}
+
+		reachedSince := false
+		for _, t := range resp.Data {
+			createdAt := t.CreatedAt.Time()
+			if createdAt.Before(since) {
+				reachedSince = true
+				break
+			}
+
+			if createdAt.After(until) {
+				continue
+			}
+
+			transactions = append(transactions, t)
+		}
+
+		if reachedSince || resp.Pagination.NextURI == "" {
+			break
+		}
+
+		startingAfter = nextStartingAfter(resp.Pagination)
+		if startingAfter == "" {
+			break
+		}
+	}
+
+	return transactions, nil
+}
+
+func nextStartingAfter(p coinbaseapi.Pagination) string {
+	u, err := url.Parse(p.NextURI)
+	if err != nil {
+		return ""
+	}
+
+	return u.Query().Get("starting_after")
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	transactions, err := e.queryTransactions(ctx, asset, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range transactions {
+		if !isDepositTransaction(t) {
+			continue
+		}
+
+		allDeposits = append(allDeposits, toGlobalDeposit(t))
+	}
+
+	sort.Slice(allDeposits, func(i, j int) bool {
+		return allDeposits[i].Time.Before(allDeposits[j].Time.Time())
+	})
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	transactions, err := e.queryTransactions(ctx, asset, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range transactions {
+		if !isWithdrawTransaction(t) {
+			continue
+		}
+
+		allWithdraws = append(allWithdraws, toGlobalWithdraw(t))
+	}
+
+	sort.Slice(allWithdraws, func(i, j int) bool {
+		return allWithdraws[i].ApplyTime.Before(allWithdraws[j].ApplyTime.Time())
+	})
+
+	return allWithdraws, nil
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e.client, e)
+}
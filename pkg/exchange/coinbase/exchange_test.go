@@ -0,0 +1,1069 @@
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSD",
+	LocalSymbol:     "BTC-USD",
+	PricePrecision:  2,
+	VolumePrecision: 8,
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USD",
+	TickSize:        fixedpoint.MustNewFromString("0.01"),
+	StepSize:        fixedpoint.MustNewFromString("0.00000001"),
+}
+
+func TestExchange_QueryMarkets(t *testing.T) {
+	resp := `
+{
+  "products": [
+    {
+      "product_id": "BTC-USD",
+      "price": "19210.36",
+      "price_percentage_change_24h": "-1.21",
+      "volume_24h": "21483.91856213",
+      "volume_percentage_change_24h": "3.48",
+      "base_increment": "0.00000001",
+      "quote_increment": "0.01",
+      "quote_min_size": "1",
+      "quote_max_size": "50000000",
+      "base_min_size": "0.000016",
+      "base_max_size": "2600",
+      "base_name": "Bitcoin",
+      "quote_name": "US Dollar",
+      "watched": false,
+      "is_disabled": false,
+      "new": false,
+      "status": "online",
+      "cancel_only": false,
+      "limit_only": false,
+      "post_only": false,
+      "trading_disabled": false,
+      "auction_mode": false,
+      "product_type": "SPOT",
+      "quote_currency_id": "USD",
+      "base_currency_id": "BTC",
+      "mid_market_price": "",
+      "price_increment": "0.01"
+    },
+    {
+      "product_id": "ETH-BTC",
+      "price": "0.06812",
+      "price_percentage_change_24h": "0.47",
+      "volume_24h": "6331.62837931",
+      "volume_percentage_change_24h": "-12.1",
+      "base_increment": "0.00000001",
+      "quote_increment": "0.00001",
+      "quote_min_size": "0.000016",
+      "quote_max_size": "80",
+      "base_min_size": "0.00022",
+      "base_max_size": "2400",
+      "base_name": "Ethereum",
+      "quote_name": "Bitcoin",
+      "is_disabled": false,
+      "status": "online",
+      "cancel_only": false,
+      "limit_only": false,
+      "post_only": false,
+      "trading_disabled": false,
+      "product_type": "SPOT",
+      "quote_currency_id": "BTC",
+      "base_currency_id": "ETH",
+      "price_increment": "0.00001"
+    },
+    {
+      "product_id": "REP-USD",
+      "price": "6.5",
+      "base_increment": "0.000001",
+      "quote_increment": "0.01",
+      "quote_min_size": "1",
+      "base_min_size": "0.1",
+      "is_disabled": true,
+      "status": "delisted",
+      "trading_disabled": true,
+      "product_type": "SPOT",
+      "quote_currency_id": "USD",
+      "base_currency_id": "REP",
+      "price_increment": "0.01"
+    }
+  ],
+  "num_products": 3
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	markets, err := ex.QueryMarkets(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, markets, 2, "the disabled product should be skipped")
+
+	market, ok := markets["ETHBTC"]
+	require.True(t, ok)
+	assert.Equal(t, "ETH-BTC", market.LocalSymbol)
+	assert.Equal(t, "ETH", market.BaseCurrency)
+	assert.Equal(t, "BTC", market.QuoteCurrency)
+	assert.Equal(t, 5, market.PricePrecision)
+	assert.Equal(t, 8, market.VolumePrecision)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.00022"), market.MinQuantity)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.000016"), market.MinNotional)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.00001"), market.TickSize)
+}
+
+func TestExchange_QueryKLines(t *testing.T) {
+	var query url.Values
+	resp := `
+{
+  "candles": [
+    {
+      "start": "1655092800",
+      "low": "26480.01",
+      "high": "26789.93",
+      "open": "26560.28",
+      "close": "26620.09",
+      "volume": "1803.91744681"
+    },
+    {
+      "start": "1655089200",
+      "low": "26422.53",
+      "high": "26935.11",
+      "open": "26901.17",
+      "close": "26560.26",
+      "volume": "2466.70573862"
+    }
+  ]
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	endTime := time.Unix(1655096400, 0)
+	klines, err := ex.QueryKLines(context.Background(), "BTCUSD", types.Interval1h, types.KLineQueryOptions{
+		EndTime: &endTime,
+		Limit:   2,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ONE_HOUR", query.Get("granularity"))
+	assert.Equal(t, "1655089200", query.Get("start"))
+	assert.Equal(t, "1655096400", query.Get("end"))
+
+	require.Len(t, klines, 2)
+	assert.Equal(t, int64(1655089200), klines[0].StartTime.Unix(), "the klines should be sorted in the ascending order")
+	assert.Equal(t, time.Unix(1655092800, 0).Add(-time.Millisecond), klines[0].EndTime.Time())
+	assert.Equal(t, fixedpoint.MustNewFromString("26901.17"), klines[0].Open)
+	assert.Equal(t, fixedpoint.MustNewFromString("26620.09"), klines[1].Close)
+	assert.Equal(t, "BTCUSD", klines[1].Symbol)
+}
+
+func TestExchange_QueryAccountBalances(t *testing.T) {
+	resp := `
+{
+  "accounts": [
+    {
+      "uuid": "8bfc20d7-f7c6-4422-bf07-8243ca4169fe",
+      "name": "BTC Wallet",
+      "currency": "BTC",
+      "available_balance": {
+        "value": "1.23",
+        "currency": "BTC"
+      },
+      "default": true,
+      "active": true,
+      "created_at": "2021-05-31T09:59:59Z",
+      "updated_at": "2021-05-31T09:59:59Z",
+      "type": "ACCOUNT_TYPE_CRYPTO",
+      "ready": true,
+      "hold": {
+        "value": "0.01",
+        "currency": "BTC"
+      }
+    },
+    {
+      "uuid": "2f8f0e1a-5b47-5c43-9b8a-0f5a9c1e3d2b",
+      "name": "Cash (USD)",
+      "currency": "USD",
+      "available_balance": {
+        "value": "1000.5",
+        "currency": "USD"
+      },
+      "default": true,
+      "active": true,
+      "created_at": "2021-05-31T09:59:59Z",
+      "updated_at": "2021-05-31T09:59:59Z",
+      "type": "ACCOUNT_TYPE_FIAT",
+      "ready": true,
+      "hold": {
+        "value": "20",
+        "currency": "USD"
+      }
+    }
+  ],
+  "has_next": false,
+  "cursor": "",
+  "size": 2
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	balances, err := ex.QueryAccountBalances(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, fixedpoint.MustNewFromString("1.23"), balances["BTC"].Available)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.01"), balances["BTC"].Locked)
+	assert.Equal(t, fixedpoint.MustNewFromString("1000.5"), balances["USD"].Available)
+	assert.Equal(t, fixedpoint.NewFromInt(20), balances["USD"].Locked)
+}
+
+func TestExchange_SubmitOrders(t *testing.T) {
+	var payload map[string]interface{}
+	resp := `
+{
+  "success": true,
+  "failure_reason": "UNKNOWN_FAILURE_REASON",
+  "order_id": "a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e",
+  "success_response": {
+    "order_id": "a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e",
+    "product_id": "BTC-USD",
+    "side": "BUY",
+    "client_order_id": "grid-1"
+  },
+  "order_configuration": {
+    "limit_limit_gtc": {
+      "base_size": "0.001",
+      "limit_price": "19000.00",
+      "post_only": true
+    }
+  }
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("CB-ACCESS-KEY"))
+		assert.NotEmpty(t, r.Header.Get("CB-ACCESS-SIGN"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &payload))
+
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	orders, err := ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		ClientOrderID: "grid-1",
+		Symbol:        "BTCUSD",
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeLimitMaker,
+		Quantity:      fixedpoint.MustNewFromString("0.001"),
+		Price:         fixedpoint.NewFromInt(19000),
+		Market:        testMarket,
+	})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	assert.Equal(t, "BTC-USD", payload["product_id"])
+	assert.Equal(t, "BUY", payload["side"])
+	assert.Equal(t, "grid-1", payload["client_order_id"])
+	assert.Equal(t, map[string]interface{}{
+		"limit_limit_gtc": map[string]interface{}{
+			"base_size":   "0.00100000",
+			"limit_price": "19000.00",
+			"post_only":   true,
+		},
+	}, payload["order_configuration"], "the sizes and the prices should be sent as strings")
+
+	order := orders[0]
+	assert.Equal(t, "a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e", order.UUID)
+	assert.Equal(t, hashStringID(order.UUID), order.OrderID)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+	assert.Equal(t, types.ExchangeCoinbase, order.Exchange)
+}
+
+func TestExchange_SubmitOrders_Rejected(t *testing.T) {
+	resp := `
+{
+  "success": false,
+  "failure_reason": "UNKNOWN_FAILURE_REASON",
+  "order_id": "",
+  "error_response": {
+    "error": "INSUFFICIENT_FUND",
+    "message": "Insufficient balance in source account",
+    "error_details": "",
+    "preview_failure_reason": "PREVIEW_INSUFFICIENT_FUND",
+    "new_order_failure_reason": "UNKNOWN_FAILURE_REASON"
+  }
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	_, err = ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSD",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.One,
+		Market:   testMarket,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "INSUFFICIENT_FUND")
+}
+
+func TestExchange_CancelOrders(t *testing.T) {
+	var payload struct {
+		OrderIDs []string `json:"order_ids"`
+	}
+	resp := `
+{
+  "results": [
+    {
+      "success": true,
+      "failure_reason": "UNKNOWN_CANCEL_FAILURE_REASON",
+      "order_id": "a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e"
+    },
+    {
+      "success": false,
+      "failure_reason": "UNKNOWN_CANCEL_ORDER",
+      "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3"
+    }
+  ]
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	err = ex.CancelOrders(context.Background(),
+		types.Order{UUID: "a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e"},
+		types.Order{UUID: "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3"},
+		types.Order{OrderID: 1},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "UNKNOWN_CANCEL_ORDER")
+	assert.Contains(t, err.Error(), "the order uuid is required")
+	assert.Equal(t, []string{
+		"a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e",
+		"0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+	}, payload.OrderIDs)
+}
+
+func TestExchange_QueryClosedOrders(t *testing.T) {
+	var query url.Values
+	resp := `
+{
+  "orders": [
+    {
+      "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+      "product_id": "BTC-USD",
+      "user_id": "5d1c5b8e-2c4b-5a8b-8f8e-7c0f3e2e1a2b",
+      "order_configuration": {
+        "limit_limit_gtc": {
+          "base_size": "0.002",
+          "limit_price": "19500.00",
+          "post_only": false
+        }
+      },
+      "side": "SELL",
+      "client_order_id": "grid-2",
+      "status": "FILLED",
+      "time_in_force": "GOOD_UNTIL_CANCELLED",
+      "created_time": "2022-06-13T04:05:06.123Z",
+      "completion_percentage": "100",
+      "filled_size": "0.002",
+      "average_filled_price": "19500.00",
+      "number_of_fills": "2",
+      "filled_value": "39",
+      "pending_cancel": false,
+      "size_in_quote": false,
+      "total_fees": "0.156",
+      "total_value_after_fees": "38.844",
+      "order_type": "LIMIT",
+      "reject_reason": "",
+      "settled": true,
+      "product_type": "SPOT",
+      "last_fill_time": "2022-06-13T04:10:00.5Z"
+    },
+    {
+      "order_id": "a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e",
+      "product_id": "BTC-USD",
+      "order_configuration": {
+        "limit_limit_gtc": {
+          "base_size": "0.001",
+          "limit_price": "19000.00",
+          "post_only": true
+        }
+      },
+      "side": "BUY",
+      "client_order_id": "grid-1",
+      "status": "OPEN",
+      "time_in_force": "GOOD_UNTIL_CANCELLED",
+      "created_time": "2022-06-13T05:00:00Z",
+      "filled_size": "0.0004",
+      "average_filled_price": "19000.00",
+      "number_of_fills": "1",
+      "order_type": "LIMIT",
+      "product_type": "SPOT",
+      "last_fill_time": "2022-06-13T05:01:00Z"
+    },
+    {
+      "order_id": "6f0e3b10-7d0a-4b5e-9a0a-6c2c5e0b8a11",
+      "product_id": "BTC-USD",
+      "order_configuration": {
+        "market_market_ioc": {
+          "quote_size": "100"
+        }
+      },
+      "side": "BUY",
+      "client_order_id": "",
+      "status": "CANCELLED",
+      "time_in_force": "IMMEDIATE_OR_CANCEL",
+      "created_time": "2022-06-12T01:00:00Z",
+      "filled_size": "0",
+      "number_of_fills": "0",
+      "order_type": "MARKET",
+      "product_type": "SPOT",
+      "last_fill_time": null
+    }
+  ],
+  "sequence": "0",
+  "has_next": false,
+  "cursor": ""
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	since := time.Date(2022, 6, 12, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2022, 6, 14, 0, 0, 0, 0, time.UTC)
+	orders, err := ex.QueryClosedOrders(context.Background(), "BTCUSD", since, until, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTC-USD", query.Get("product_id"))
+	assert.Equal(t, "2022-06-12T00:00:00Z", query.Get("start_date"))
+	assert.Equal(t, "2022-06-14T00:00:00Z", query.Get("end_date"))
+
+	require.Len(t, orders, 2, "the open order should be skipped")
+
+	canceled := orders[0]
+	assert.Equal(t, types.OrderTypeMarket, canceled.Type)
+	assert.Equal(t, types.OrderStatusCanceled, canceled.Status)
+	assert.Equal(t, types.TimeInForceIOC, canceled.TimeInForce)
+	assert.Equal(t, canceled.CreationTime, canceled.UpdateTime)
+
+	filled := orders[1]
+	assert.Equal(t, "grid-2", filled.ClientOrderID)
+	assert.Equal(t, types.SideTypeSell, filled.Side)
+	assert.Equal(t, types.OrderTypeLimit, filled.Type)
+	assert.Equal(t, types.OrderStatusFilled, filled.Status)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.002"), filled.Quantity)
+	assert.Equal(t, fixedpoint.MustNewFromString("19500"), filled.Price)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.002"), filled.ExecutedQuantity)
+	assert.False(t, filled.IsWorking)
+	assert.Equal(t, time.Date(2022, 6, 13, 4, 10, 0, 500000000, time.UTC), filled.UpdateTime.Time().UTC())
+}
+
+func TestExchange_QueryOpenOrders(t *testing.T) {
+	var query url.Values
+	resp := `
+{
+  "orders": [
+    {
+      "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+      "product_id": "BTC-USD",
+      "user_id": "5d1c5b8e-2c4b-5a8b-8f8e-7c0f3e2e1a2b",
+      "order_configuration": {
+        "limit_limit_gtc": {
+          "base_size": "0.002",
+          "limit_price": "19500.00",
+          "post_only": false
+        }
+      },
+      "side": "SELL",
+      "client_order_id": "grid-2",
+      "status": "FILLED",
+      "time_in_force": "GOOD_UNTIL_CANCELLED",
+      "created_time": "2022-06-13T04:05:06.123Z",
+      "completion_percentage": "100",
+      "filled_size": "0.002",
+      "average_filled_price": "19500.00",
+      "number_of_fills": "2",
+      "filled_value": "39",
+      "pending_cancel": false,
+      "size_in_quote": false,
+      "total_fees": "0.156",
+      "total_value_after_fees": "38.844",
+      "order_type": "LIMIT",
+      "reject_reason": "",
+      "settled": true,
+      "product_type": "SPOT",
+      "last_fill_time": "2022-06-13T04:10:00.5Z"
+    },
+    {
+      "order_id": "a2c4a8e1-64a7-4f6f-bf9b-9f0d2b6d0c1e",
+      "product_id": "BTC-USD",
+      "order_configuration": {
+        "limit_limit_gtc": {
+          "base_size": "0.001",
+          "limit_price": "19000.00",
+          "post_only": true
+        }
+      },
+      "side": "BUY",
+      "client_order_id": "grid-1",
+      "status": "OPEN",
+      "time_in_force": "GOOD_UNTIL_CANCELLED",
+      "created_time": "2022-06-13T05:00:00Z",
+      "filled_size": "0.0004",
+      "average_filled_price": "19000.00",
+      "number_of_fills": "1",
+      "order_type": "LIMIT",
+      "product_type": "SPOT",
+      "last_fill_time": "2022-06-13T05:01:00Z"
+    },
+    {
+      "order_id": "6f0e3b10-7d0a-4b5e-9a0a-6c2c5e0b8a11",
+      "product_id": "BTC-USD",
+      "order_configuration": {
+        "market_market_ioc": {
+          "quote_size": "100"
+        }
+      },
+      "side": "BUY",
+      "client_order_id": "",
+      "status": "CANCELLED",
+      "time_in_force": "IMMEDIATE_OR_CANCEL",
+      "created_time": "2022-06-12T01:00:00Z",
+      "filled_size": "0",
+      "number_of_fills": "0",
+      "order_type": "MARKET",
+      "product_type": "SPOT",
+      "last_fill_time": null
+    }
+  ],
+  "sequence": "0",
+  "has_next": false,
+  "cursor": ""
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	orders, err := ex.QueryOpenOrders(context.Background(), "BTCUSD")
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN", query.Get("order_status"))
+
+	require.Len(t, orders, 3)
+	open := orders[1]
+	assert.Equal(t, types.OrderTypeLimitMaker, open.Type)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, open.Status)
+	assert.True(t, open.IsWorking)
+}
+
+func TestExchange_QueryTrades(t *testing.T) {
+	var query url.Values
+	resp := `
+{
+  "fills": [
+    {
+      "entry_id": "22222-2222222-22222222",
+      "trade_id": "3b47a6a4-2f6a-4c44-8d6a-5a3b3a4a8a02",
+      "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+      "trade_time": "2022-06-13T04:10:00.5Z",
+      "trade_type": "FILL",
+      "price": "19500.00",
+      "size": "0.0012",
+      "commission": "0.0936",
+      "product_id": "BTC-USD",
+      "sequence_timestamp": "2022-06-13T04:10:00.512Z",
+      "liquidity_indicator": "MAKER",
+      "size_in_quote": false,
+      "user_id": "5d1c5b8e-2c4b-5a8b-8f8e-7c0f3e2e1a2b",
+      "side": "SELL"
+    },
+    {
+      "entry_id": "11111-1111111-11111111",
+      "trade_id": "3b47a6a4-2f6a-4c44-8d6a-5a3b3a4a8a01",
+      "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+      "trade_time": "2022-06-13T04:08:00Z",
+      "trade_type": "FILL",
+      "price": "19500.00",
+      "size": "0.0008",
+      "commission": "0.0624",
+      "product_id": "BTC-USD",
+      "sequence_timestamp": "2022-06-13T04:08:00.012Z",
+      "liquidity_indicator": "TAKER",
+      "size_in_quote": false,
+      "user_id": "5d1c5b8e-2c4b-5a8b-8f8e-7c0f3e2e1a2b",
+      "side": "SELL"
+    }
+  ],
+  "cursor": ""
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+
+		fmt.Fprintln(w, resp)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	startTime := time.Date(2022, 6, 13, 0, 0, 0, 0, time.UTC)
+	trades, err := ex.QueryTrades(context.Background(), "BTCUSD", &types.TradeQueryOptions{
+		StartTime: &startTime,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTC-USD", query.Get("product_id"))
+	assert.Equal(t, "2022-06-13T00:00:00Z", query.Get("start_sequence_timestamp"))
+
+	require.Len(t, trades, 2)
+	assert.Equal(t, hashStringID("3b47a6a4-2f6a-4c44-8d6a-5a3b3a4a8a01"), trades[0].ID, "the trades should be sorted in the ascending order")
+	assert.Equal(t, hashStringID("0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3"), trades[0].OrderID)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.0008"), trades[0].Quantity)
+	assert.Equal(t, fixedpoint.MustNewFromString("15.6"), trades[0].QuoteQuantity)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.0624"), trades[0].Fee)
+	assert.Equal(t, "USD", trades[0].FeeCurrency)
+	assert.False(t, trades[0].IsMaker)
+	assert.False(t, trades[0].IsBuyer)
+	assert.True(t, trades[1].IsMaker)
+}
+
+func TestExchange_QueryDepositHistory(t *testing.T) {
+	accountsResp := `
+{
+  "accounts": [
+    {
+      "uuid": "8bfc20d7-f7c6-4422-bf07-8243ca4169fe",
+      "name": "BTC Wallet",
+      "currency": "BTC",
+      "available_balance": {
+        "value": "1.23",
+        "currency": "BTC"
+      },
+      "default": true,
+      "active": true,
+      "created_at": "2021-05-31T09:59:59Z",
+      "updated_at": "2021-05-31T09:59:59Z",
+      "type": "ACCOUNT_TYPE_CRYPTO",
+      "ready": true,
+      "hold": {
+        "value": "0.01",
+        "currency": "BTC"
+      }
+    },
+    {
+      "uuid": "2f8f0e1a-5b47-5c43-9b8a-0f5a9c1e3d2b",
+      "name": "Cash (USD)",
+      "currency": "USD",
+      "available_balance": {
+        "value": "1000.5",
+        "currency": "USD"
+      },
+      "default": true,
+      "active": true,
+      "created_at": "2021-05-31T09:59:59Z",
+      "updated_at": "2021-05-31T09:59:59Z",
+      "type": "ACCOUNT_TYPE_FIAT",
+      "ready": true,
+      "hold": {
+        "value": "20",
+        "currency": "USD"
+      }
+    }
+  ],
+  "has_next": false,
+  "cursor": "",
+  "size": 2
+}
+`
+	transactionsResp := `
+{
+  "pagination": {
+    "ending_before": null,
+    "starting_after": null,
+    "limit": 100,
+    "order": "desc",
+    "previous_uri": null,
+    "next_uri": null
+  },
+  "data": [
+    {
+      "id": "57ffb4ae-0c59-5430-bcd3-3f98f797a66c",
+      "type": "send",
+      "status": "completed",
+      "amount": {
+        "amount": "-0.00100000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "-19.21",
+        "currency": "USD"
+      },
+      "description": null,
+      "created_at": "2022-06-13T08:00:00Z",
+      "updated_at": "2022-06-13T08:20:00Z",
+      "resource": "transaction",
+      "network": {
+        "status": "confirmed",
+        "hash": "463397c87beddd9a61ade61359a13adc9efea26062191fe07147037bce7f33ed",
+        "name": "bitcoin",
+        "transaction_fee": {
+          "amount": "0.00001000",
+          "currency": "BTC"
+        }
+      },
+      "to": {
+        "resource": "bitcoin_address",
+        "address": "1AUJ8z5RuHRTqD1eikyfUUetzGmdWLGkpT",
+        "currency": "BTC"
+      }
+    },
+    {
+      "id": "4117f7d6-5694-5b36-bc8f-847509850ea4",
+      "type": "buy",
+      "status": "completed",
+      "amount": {
+        "amount": "0.00500000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "96.05",
+        "currency": "USD"
+      },
+      "created_at": "2022-06-12T12:00:00Z",
+      "updated_at": "2022-06-12T12:00:00Z",
+      "resource": "transaction"
+    },
+    {
+      "id": "3c04e35e-8e5a-5ff1-9155-00675db4ac02",
+      "type": "send",
+      "status": "completed",
+      "amount": {
+        "amount": "0.10000000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "1921.03",
+        "currency": "USD"
+      },
+      "created_at": "2022-06-11T12:00:00Z",
+      "updated_at": "2022-06-11T12:30:00Z",
+      "resource": "transaction",
+      "network": {
+        "status": "confirmed",
+        "hash": "8f1a0e57c3e35b7f4c0ef2f1a9a4c1d2e3f405162738495a6b7c8d9e0f1a2b3c",
+        "name": "bitcoin"
+      },
+      "from": {
+        "resource": "bitcoin_network",
+        "currency": "BTC"
+      }
+    },
+    {
+      "id": "9b5c1c1e-1111-5c36-8a0a-000000000000",
+      "type": "send",
+      "status": "completed",
+      "amount": {
+        "amount": "0.20000000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "5921.03",
+        "currency": "USD"
+      },
+      "created_at": "2022-05-01T12:00:00Z",
+      "updated_at": "2022-05-01T12:30:00Z",
+      "resource": "transaction"
+    }
+  ]
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/brokerage/accounts":
+			fmt.Fprintln(w, accountsResp)
+		case "/v2/accounts/8bfc20d7-f7c6-4422-bf07-8243ca4169fe/transactions":
+			fmt.Fprintln(w, transactionsResp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	since := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	deposits, err := ex.QueryDepositHistory(context.Background(), "BTC", since, time.Time{})
+	require.NoError(t, err)
+
+	require.Len(t, deposits, 1, "the transactions before the since time should be skipped")
+	assert.Equal(t, fixedpoint.MustNewFromString("0.1"), deposits[0].Amount)
+	assert.Equal(t, "BTC", deposits[0].Asset)
+	assert.Equal(t, types.DepositSuccess, deposits[0].Status)
+	assert.Equal(t, "8f1a0e57c3e35b7f4c0ef2f1a9a4c1d2e3f405162738495a6b7c8d9e0f1a2b3c", deposits[0].TransactionID)
+}
+
+func TestExchange_QueryWithdrawHistory(t *testing.T) {
+	accountsResp := `
+{
+  "accounts": [
+    {
+      "uuid": "8bfc20d7-f7c6-4422-bf07-8243ca4169fe",
+      "name": "BTC Wallet",
+      "currency": "BTC",
+      "available_balance": {
+        "value": "1.23",
+        "currency": "BTC"
+      },
+      "default": true,
+      "active": true,
+      "created_at": "2021-05-31T09:59:59Z",
+      "updated_at": "2021-05-31T09:59:59Z",
+      "type": "ACCOUNT_TYPE_CRYPTO",
+      "ready": true,
+      "hold": {
+        "value": "0.01",
+        "currency": "BTC"
+      }
+    },
+    {
+      "uuid": "2f8f0e1a-5b47-5c43-9b8a-0f5a9c1e3d2b",
+      "name": "Cash (USD)",
+      "currency": "USD",
+      "available_balance": {
+        "value": "1000.5",
+        "currency": "USD"
+      },
+      "default": true,
+      "active": true,
+      "created_at": "2021-05-31T09:59:59Z",
+      "updated_at": "2021-05-31T09:59:59Z",
+      "type": "ACCOUNT_TYPE_FIAT",
+      "ready": true,
+      "hold": {
+        "value": "20",
+        "currency": "USD"
+      }
+    }
+  ],
+  "has_next": false,
+  "cursor": "",
+  "size": 2
+}
+`
+	transactionsResp := `
+{
+  "pagination": {
+    "ending_before": null,
+    "starting_after": null,
+    "limit": 100,
+    "order": "desc",
+    "previous_uri": null,
+    "next_uri": null
+  },
+  "data": [
+    {
+      "id": "57ffb4ae-0c59-5430-bcd3-3f98f797a66c",
+      "type": "send",
+      "status": "completed",
+      "amount": {
+        "amount": "-0.00100000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "-19.21",
+        "currency": "USD"
+      },
+      "description": null,
+      "created_at": "2022-06-13T08:00:00Z",
+      "updated_at": "2022-06-13T08:20:00Z",
+      "resource": "transaction",
+      "network": {
+        "status": "confirmed",
+        "hash": "463397c87beddd9a61ade61359a13adc9efea26062191fe07147037bce7f33ed",
+        "name": "bitcoin",
+        "transaction_fee": {
+          "amount": "0.00001000",
+          "currency": "BTC"
+        }
+      },
+      "to": {
+        "resource": "bitcoin_address",
+        "address": "1AUJ8z5RuHRTqD1eikyfUUetzGmdWLGkpT",
+        "currency": "BTC"
+      }
+    },
+    {
+      "id": "4117f7d6-5694-5b36-bc8f-847509850ea4",
+      "type": "buy",
+      "status": "completed",
+      "amount": {
+        "amount": "0.00500000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "96.05",
+        "currency": "USD"
+      },
+      "created_at": "2022-06-12T12:00:00Z",
+      "updated_at": "2022-06-12T12:00:00Z",
+      "resource": "transaction"
+    },
+    {
+      "id": "3c04e35e-8e5a-5ff1-9155-00675db4ac02",
+      "type": "send",
+      "status": "completed",
+      "amount": {
+        "amount": "0.10000000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "1921.03",
+        "currency": "USD"
+      },
+      "created_at": "2022-06-11T12:00:00Z",
+      "updated_at": "2022-06-11T12:30:00Z",
+      "resource": "transaction",
+      "network": {
+        "status": "confirmed",
+        "hash": "8f1a0e57c3e35b7f4c0ef2f1a9a4c1d2e3f405162738495a6b7c8d9e0f1a2b3c",
+        "name": "bitcoin"
+      },
+      "from": {
+        "resource": "bitcoin_network",
+        "currency": "BTC"
+      }
+    },
+    {
+      "id": "9b5c1c1e-1111-5c36-8a0a-000000000000",
+      "type": "send",
+      "status": "completed",
+      "amount": {
+        "amount": "0.20000000",
+        "currency": "BTC"
+      },
+      "native_amount": {
+        "amount": "5921.03",
+        "currency": "USD"
+      },
+      "created_at": "2022-05-01T12:00:00Z",
+      "updated_at": "2022-05-01T12:30:00Z",
+      "resource": "transaction"
+    }
+  ]
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/brokerage/accounts":
+			fmt.Fprintln(w, accountsResp)
+		case "/v2/accounts/8bfc20d7-f7c6-4422-bf07-8243ca4169fe/transactions":
+			fmt.Fprintln(w, transactionsResp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.client.BaseURL = serverURL
+
+	since := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	withdraws, err := ex.QueryWithdrawHistory(context.Background(), "BTC", since, time.Time{})
+	require.NoError(t, err)
+
+	require.Len(t, withdraws, 1)
+	withdraw := withdraws[0]
+	assert.Equal(t, fixedpoint.MustNewFromString("0.001"), withdraw.Amount)
+	assert.Equal(t, "1AUJ8z5RuHRTqD1eikyfUUetzGmdWLGkpT", withdraw.Address)
+	assert.Equal(t, "bitcoin", withdraw.Network)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.00001"), withdraw.TransactionFee)
+	assert.Equal(t, "BTC", withdraw.TransactionFeeCurrency)
+	assert.Equal(t, "57ffb4ae-0c59-5430-bcd3-3f98f797a66c", withdraw.WithdrawOrderID)
+}
@@ -0,0 +1,68 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+)
+
+var packageTemplate = template.Must(template.New("").Parse(`// Code generated by go generate; DO NOT EDIT.
+package coinbase
+
+var symbolMap = map[string]string{
+{{- range $k, $v := . }}
+	{{ printf "%q" $k }}: {{ printf "%q" $v }},
+{{- end }}
+}
+
+func toLocalSymbol(symbol string) string {
+	s, ok := symbolMap[symbol]
+	if ok {
+		return s
+	}
+
+	return symbol
+}
+`))
+
+func main() {
+	const apiUrl = coinbaseapi.RestBaseURL + "/api/v3/brokerage/market/products?product_type=SPOT"
+
+	resp, err := http.Get(apiUrl)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	r := &coinbaseapi.ProductsResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		log.Fatal(err)
+	}
+
+	var data = map[string]string{}
+	for _, p := range r.Products {
+		key := strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(p.ProductID)), "-", "")
+		data[key] = p.ProductID
+	}
+
+	f, err := os.Create("symbols.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer f.Close()
+
+	err = packageTemplate.Execute(f, data)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,232 @@
+package coinbase
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MessageHeader is the common part of the websocket messages,
+// the sequence number is increased by one for every message of the connection.
+type MessageHeader struct {
+	Channel     string                     `json:"channel"`
+	ClientID    string                     `json:"client_id"`
+	Timestamp   types.MillisecondTimestamp `json:"timestamp"`
+	SequenceNum int64                      `json:"sequence_num"`
+}
+
+func (h *MessageHeader) Sequence() int64 {
+	return h.SequenceNum
+}
+
+type ErrorMessage struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type Level2Update struct {
+	// Side is either "bid" or "offer"
+	Side        string                     `json:"side"`
+	EventTime   types.MillisecondTimestamp `json:"event_time"`
+	PriceLevel  fixedpoint.Value           `json:"price_level"`
+	NewQuantity fixedpoint.Value           `json:"new_quantity"`
+}
+
+type Level2Event struct {
+	// Type is either "snapshot" or "update"
+	Type      string         `json:"type"`
+	ProductID string         `json:"product_id"`
+	Updates   []Level2Update `json:"updates"`
+}
+
+// Book converts the level2 event to the order book, the price level is removed if the new quantity is zero
+func (e *Level2Event) Book() types.SliceOrderBook {
+	book := types.SliceOrderBook{
+		Symbol: toGlobalSymbol(e.ProductID),
+	}
+
+	for _, u := range e.Updates {
+		pv := types.PriceVolume{Price: u.PriceLevel, Volume: u.NewQuantity}
+		switch u.Side {
+		case "bid":
+			book.Bids = append(book.Bids, pv)
+		case "offer":
+			book.Asks = append(book.Asks, pv)
+		}
+	}
+
+	return book
+}
+
+type Level2Message struct {
+	MessageHeader
+
+	Events []Level2Event `json:"events"`
+}
+
+type MarketTradesEvent struct {
+	Type   string                    `json:"type"`
+	Trades []coinbaseapi.MarketTrade `json:"trades"`
+}
+
+type MarketTradesMessage struct {
+	MessageHeader
+
+	Events []MarketTradesEvent `json:"events"`
+}
+
+type Ticker struct {
+	Type               string           `json:"type"`
+	ProductID          string           `json:"product_id"`
+	Price              fixedpoint.Value `json:"price"`
+	Volume24H          fixedpoint.Value `json:"volume_24_h"`
+	Low24H             fixedpoint.Value `json:"low_24_h"`
+	High24H            fixedpoint.Value `json:"high_24_h"`
+	PricePercentChg24H fixedpoint.Value `json:"price_percent_chg_24_h"`
+	BestBid            fixedpoint.Value `json:"best_bid"`
+	BestBidQuantity    fixedpoint.Value `json:"best_bid_quantity"`
+	BestAsk            fixedpoint.Value `json:"best_ask"`
+	BestAskQuantity    fixedpoint.Value `json:"best_ask_quantity"`
+}
+
+func (t *Ticker) BookTicker() types.BookTicker {
+	return types.BookTicker{
+		Symbol:   toGlobalSymbol(t.ProductID),
+		Buy:      t.BestBid,
+		BuySize:  t.BestBidQuantity,
+		Sell:     t.BestAsk,
+		SellSize: t.BestAskQuantity,
+	}
+}
+
+type TickerEvent struct {
+	Type    string   `json:"type"`
+	Tickers []Ticker `json:"tickers"`
+}
+
+type TickerMessage struct {
+	MessageHeader
+
+	Events []TickerEvent `json:"events"`
+}
+
+type CandlesEvent struct {
+	Type    string               `json:"type"`
+	Candles []coinbaseapi.Candle `json:"candles"`
+}
+
+type CandlesMessage struct {
+	MessageHeader
+
+	Events []CandlesEvent `json:"events"`
+}
+
+// UserOrder is the order update of the user channel
+type UserOrder struct {
+	OrderID            string                     `json:"order_id"`
+	ClientOrderID      string                     `json:"client_order_id"`
+	CumulativeQuantity fixedpoint.Value           `json:"cumulative_quantity"`
+	LeavesQuantity     fixedpoint.Value           `json:"leaves_quantity"`
+	AvgPrice           fixedpoint.Value           `json:"avg_price"`
+	TotalFees          fixedpoint.Value           `json:"total_fees"`
+	Status             coinbaseapi.OrderStatus    `json:"status"`
+	ProductID          string                     `json:"product_id"`
+	CreationTime       types.MillisecondTimestamp `json:"creation_time"`
+	OrderSide          coinbaseapi.OrderSide      `json:"order_side"`
+	OrderType          coinbaseapi.OrderType      `json:"order_type"`
+	LimitPrice         fixedpoint.Value           `json:"limit_price"`
+	StopPrice          fixedpoint.Value           `json:"stop_price"`
+	PostOnly           bool                       `json:"post_only"`
+	TimeInForce        coinbaseapi.TimeInForce    `json:"time_in_force"`
+	CancelReason       string                     `json:"cancel_reason"`
+	RejectReason       string                     `json:"reject_reason"`
+	NumberOfFills      string                     `json:"number_of_fills"`
+}
+
+func (o *UserOrder) Order(updateTime types.MillisecondTimestamp) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        toGlobalSymbol(o.ProductID),
+			Side:          toGlobalSide(o.OrderSide),
+			Type:          toGlobalOrderType(o.OrderType, o.PostOnly),
+			Quantity:      o.CumulativeQuantity.Add(o.LeavesQuantity),
+			Price:         o.LimitPrice,
+			StopPrice:     o.StopPrice,
+			TimeInForce:   toGlobalTimeInForce(o.TimeInForce),
+		},
+		Exchange:         types.ExchangeCoinbase,
+		OrderID:          hashStringID(o.OrderID),
+		UUID:             o.OrderID,
+		Status:           toGlobalOrderStatus(o.Status, o.CumulativeQuantity),
+		ExecutedQuantity: o.CumulativeQuantity,
+		IsWorking:        isWorkingOrderStatus(o.Status),
+		CreationTime:     types.Time(o.CreationTime.Time()),
+		UpdateTime:       types.Time(updateTime.Time()),
+	}
+}
+
+type UserEvent struct {
+	Type   string      `json:"type"`
+	Orders []UserOrder `json:"orders"`
+}
+
+type UserMessage struct {
+	MessageHeader
+
+	Events []UserEvent `json:"events"`
+}
+
+func parseWebSocketMessage(message []byte) (interface{}, error) {
+	var header struct {
+		MessageHeader
+
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(message, &header); err != nil {
+		return nil, err
+	}
+
+	if header.Type == "error" {
+		var e ErrorMessage
+		if err := json.Unmarshal(message, &e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+
+	var m interface{}
+	switch header.Channel {
+	case "l2_data":
+		m = &Level2Message{}
+
+	case "market_trades":
+		m = &MarketTradesMessage{}
+
+	case "ticker", "ticker_batch":
+		m = &TickerMessage{}
+
+	case "candles":
+		m = &CandlesMessage{}
+
+	case "user":
+		m = &UserMessage{}
+
+	case "heartbeats", "subscriptions":
+		// only the sequence number is used
+		return &header.MessageHeader, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported channel: %s", header.Channel)
+	}
+
+	if err := json.Unmarshal(message, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
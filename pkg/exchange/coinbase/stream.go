@@ -0,0 +1,349 @@
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// queryTradeTimeout is the timeout of the fill query triggered by the order updates
+const queryTradeTimeout = 10 * time.Second
+
+// closedOrderRetention is how long the state of the closed orders is kept,
+// the duplicated updates of the closed orders are ignored within the retention.
+const closedOrderRetention = 10 * time.Minute
+
+// candleInterval is the only interval of the candles channel
+var candleInterval = types.Interval5m
+
+type orderState struct {
+	filledQuantity fixedpoint.Value
+	emittedTrades  map[uint64]struct{}
+	closedAt       time.Time
+}
+
+type WebSocketSubscription struct {
+	Type       string   `json:"type"`
+	Channel    string   `json:"channel"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+	APIKey     string   `json:"api_key,omitempty"`
+	Timestamp  string   `json:"timestamp,omitempty"`
+	Signature  string   `json:"signature,omitempty"`
+}
+
+//go:generate callbackgen -type Stream -interface
+type Stream struct {
+	types.StandardStream
+
+	client   *coinbaseapi.RestClient
+	exchange *Exchange
+
+	// writeMutex is used for the concurrent writes of the subscriptions and the ping worker
+	writeMutex sync.Mutex
+
+	// lastSequence is the sequence number of the last message, the sequence starts from zero on every connection
+	sequenceMutex sync.Mutex
+	lastSequence  int64
+
+	lastCandles map[string]types.KLine
+
+	// orderStates stores the cumulative quantity and the emitted trades of the orders,
+	// the fills are queried when the cumulative quantity is increased.
+	orderStates map[string]*orderState
+
+	level2MessageCallbacks       []func(m *Level2Message)
+	marketTradesMessageCallbacks []func(m *MarketTradesMessage)
+	tickerMessageCallbacks       []func(m *TickerMessage)
+	candlesMessageCallbacks      []func(m *CandlesMessage)
+	userMessageCallbacks         []func(m *UserMessage)
+}
+
+func NewStream(client *coinbaseapi.RestClient, ex *Exchange) *Stream {
+	stream := &Stream{
+		StandardStream: types.NewStandardStream(),
+		client:         client,
+		exchange:       ex,
+		lastSequence:   -1,
+		lastCandles:    make(map[string]types.KLine),
+		orderStates:    make(map[string]*orderState),
+	}
+
+	stream.SetParser(parseWebSocketMessage)
+	stream.SetDispatcher(stream.dispatchEvent)
+	stream.SetEndpointCreator(stream.createEndpoint)
+
+	stream.OnConnect(stream.handleConnect)
+	stream.OnLevel2Message(stream.handleLevel2Message)
+	stream.OnMarketTradesMessage(stream.handleMarketTradesMessage)
+	stream.OnTickerMessage(stream.handleTickerMessage)
+	stream.OnCandlesMessage(stream.handleCandlesMessage)
+	stream.OnUserMessage(stream.handleUserMessage)
+	return stream
+}
+
+func (s *Stream) createEndpoint(ctx context.Context) (string, error) {
+	return coinbaseapi.WebSocketURL, nil
+}
+
+func (s *Stream) writeJSON(v interface{}) error {
+	s.ConnLock.Lock()
+	conn := s.Conn
+	s.ConnLock.Unlock()
+
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	return conn.WriteJSON(v)
+}
+
+func (s *Stream) handleConnect() {
+	s.sequenceMutex.Lock()
+	s.lastSequence = -1
+	s.sequenceMutex.Unlock()
+
+	// the heartbeats channel keeps the connection alive when the subscribed products are not active
+	if err := s.subscribe("heartbeats", nil); err != nil {
+		log.WithError(err).Errorf("heartbeats subscription error")
+	}
+
+	if !s.PublicOnly {
+		if err := s.subscribe("user", nil); err != nil {
+			log.WithError(err).Errorf("user subscription error")
+		}
+		return
+	}
+
+	var channels []string
+	productIDs := make(map[string][]string)
+	for _, sub := range s.Subscriptions {
+		channel, err := convertSubscription(sub)
+		if err != nil {
+			log.WithError(err).Errorf("subscription convert error")
+			continue
+		}
+
+		if _, ok := productIDs[channel]; !ok {
+			channels = append(channels, channel)
+		}
+
+		productIDs[channel] = append(productIDs[channel], toLocalSymbol(sub.Symbol))
+	}
+
+	for _, channel := range channels {
+		if err := s.subscribe(channel, productIDs[channel]); err != nil {
+			log.WithError(err).Errorf("%s subscription error", channel)
+		}
+	}
+}
+
+// subscribe sends the subscription of one channel, the subscription is signed if the api key is given,
+// the signature is the hex encoded HMAC-SHA256 of timestamp + channel + comma separated product ids.
+func (s *Stream) subscribe(channel string, productIDs []string) error {
+	sub := WebSocketSubscription{
+		Type:       "subscribe",
+		Channel:    channel,
+		ProductIDs: productIDs,
+	}
+
+	if len(s.client.Key) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		sub.APIKey = s.client.Key
+		sub.Timestamp = timestamp
+		sub.Signature = coinbaseapi.Sign(s.client.Secret, timestamp+channel+strings.Join(productIDs, ","))
+	}
+
+	log.Infof("subscribing channel %s: %v", channel, productIDs)
+	return s.writeJSON(sub)
+}
+
+func convertSubscription(sub types.Subscription) (string, error) {
+	switch sub.Channel {
+	case types.BookChannel:
+		return "level2", nil
+
+	case types.BookTickerChannel:
+		return "ticker", nil
+
+	case types.MarketTradeChannel:
+		return "market_trades", nil
+
+	case types.KLineChannel:
+		if sub.Options.Interval != candleInterval {
+			return "", fmt.Errorf("kline interval %s is not supported by the coinbase stream, only %s is supported", sub.Options.Interval, candleInterval)
+		}
+		return "candles", nil
+
+	}
+
+	return "", fmt.Errorf("unsupported stream channel: %s", sub.Channel)
+}
+
+// checkSequence returns false if there is a gap of the message sequence
+func (s *Stream) checkSequence(seq int64) bool {
+	s.sequenceMutex.Lock()
+	defer s.sequenceMutex.Unlock()
+
+	last := s.lastSequence
+	s.lastSequence = seq
+	return last < 0 || seq == last+1
+}
+
+func (s *Stream) handleLevel2Message(m *Level2Message) {
+	for _, e := range m.Events {
+		book := e.Book()
+		switch e.Type {
+		case "snapshot":
+			s.EmitBookSnapshot(book)
+		case "update":
+			s.EmitBookUpdate(book)
+		}
+	}
+}
+
+func (s *Stream) handleMarketTradesMessage(m *MarketTradesMessage) {
+	for _, e := range m.Events {
+		// the snapshot contains the recent trades before the subscription
+		if e.Type != "update" {
+			continue
+		}
+
+		for _, t := range e.Trades {
+			s.EmitMarketTrade(toGlobalMarketTrade(t))
+		}
+	}
+}
+
+func (s *Stream) handleTickerMessage(m *TickerMessage) {
+	for _, e := range m.Events {
+		for _, t := range e.Tickers {
+			s.EmitBookTickerUpdate(t.BookTicker())
+		}
+	}
+}
+
+// handleCandlesMessage emits the kline updates, the candles channel doesn't tell if the candle is closed,
+// so the last candle is emitted as the closed kline when the candle of the next interval is received.
+func (s *Stream) handleCandlesMessage(m *CandlesMessage) {
+	for _, e := range m.Events {
+		for _, c := range e.Candles {
+			kline := toGlobalKLine(toGlobalSymbol(c.ProductID), candleInterval, c)
+			kline.Closed = false
+
+			lastKLine, ok := s.lastCandles[kline.Symbol]
+			if ok && lastKLine.StartTime.Time().Before(kline.StartTime.Time()) {
+				lastKLine.Closed = true
+				s.EmitKLineClosed(lastKLine)
+			}
+
+			s.lastCandles[kline.Symbol] = kline
+			s.EmitKLine(kline)
+		}
+	}
+}
+
+func (s *Stream) handleUserMessage(m *UserMessage) {
+	now := time.Now()
+	for _, e := range m.Events {
+		for _, o := range e.Orders {
+			order := o.Order(m.Timestamp)
+			s.EmitOrderUpdate(order)
+
+			state, ok := s.orderStates[o.OrderID]
+			if !ok {
+				state = &orderState{emittedTrades: make(map[uint64]struct{})}
+				s.orderStates[o.OrderID] = state
+			}
+
+			// the snapshot contains the open orders only, we just record the filled quantities.
+			// the filled quantity is not updated if the fills can not be queried, so they are queried again on the next update.
+			if e.Type != "snapshot" && o.CumulativeQuantity.Compare(state.filledQuantity) > 0 {
+				if err := s.emitOrderTrades(o.OrderID, state); err != nil {
+					log.WithError(err).Errorf("can not query the trades of the order %s", o.OrderID)
+					continue
+				}
+			}
+
+			state.filledQuantity = o.CumulativeQuantity
+			if !order.IsWorking && state.closedAt.IsZero() {
+				state.closedAt = now
+			}
+		}
+	}
+
+	for orderID, state := range s.orderStates {
+		if !state.closedAt.IsZero() && now.Sub(state.closedAt) > closedOrderRetention {
+			delete(s.orderStates, orderID)
+		}
+	}
+}
+
+// emitOrderTrades emits the new trades of the order, the user channel doesn't contain the fills,
+// so we query the fills of the order and filter the emitted ones.
+func (s *Stream) emitOrderTrades(orderID string, state *orderState) error {
+	if s.exchange == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTradeTimeout)
+	defer cancel()
+
+	trades, err := s.exchange.queryOrderTrades(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	// the fills are returned in the descending order
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time.Time())
+	})
+
+	for _, trade := range trades {
+		if _, ok := state.emittedTrades[trade.ID]; ok {
+			continue
+		}
+
+		state.emittedTrades[trade.ID] = struct{}{}
+		s.EmitTradeUpdate(trade)
+	}
+
+	return nil
+}
+
+func (s *Stream) dispatchEvent(event interface{}) {
+	if h, ok := event.(interface{ Sequence() int64 }); ok {
+		if !s.checkSequence(h.Sequence()) {
+			log.Warnf("coinbase websocket message sequence gap detected at %d, reconnecting...", h.Sequence())
+			s.Reconnect()
+			return
+		}
+	}
+
+	switch e := event.(type) {
+
+	case *ErrorMessage:
+		log.Errorf("coinbase websocket error: %s", e.Message)
+
+	case *Level2Message:
+		s.EmitLevel2Message(e)
+
+	case *MarketTradesMessage:
+		s.EmitMarketTradesMessage(e)
+
+	case *TickerMessage:
+		s.EmitTickerMessage(e)
+
+	case *CandlesMessage:
+		s.EmitCandlesMessage(e)
+
+	case *UserMessage:
+		s.EmitUserMessage(e)
+
+	}
+}
@@ -0,0 +1,382 @@
+package coinbase
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func dispatchMessage(t *testing.T, s *Stream, message string) {
+	m, err := parseWebSocketMessage([]byte(message))
+	require.NoError(t, err)
+	s.dispatchEvent(m)
+}
+
+func TestStream_dispatchEvent(t *testing.T) {
+	t.Run("handle level2 snapshot and update", func(t *testing.T) {
+		s := NewStream(nil, nil)
+
+		var snapshots, updates []types.SliceOrderBook
+		s.OnBookSnapshot(func(book types.SliceOrderBook) {
+			snapshots = append(snapshots, book)
+		})
+		s.OnBookUpdate(func(book types.SliceOrderBook) {
+			updates = append(updates, book)
+		})
+
+		dispatchMessage(t, s, `{
+  "channel": "l2_data",
+  "client_id": "",
+  "timestamp": "2022-06-13T12:00:00.123456789Z",
+  "sequence_num": 1,
+  "events": [
+    {
+      "type": "snapshot",
+      "product_id": "BTC-USD",
+      "updates": [
+        {"side": "bid", "event_time": "2022-06-13T12:00:00.1Z", "price_level": "19200.01", "new_quantity": "0.5"},
+        {"side": "bid", "event_time": "2022-06-13T12:00:00.1Z", "price_level": "19200", "new_quantity": "1.2"},
+        {"side": "offer", "event_time": "2022-06-13T12:00:00.1Z", "price_level": "19200.5", "new_quantity": "0.3"}
+      ]
+    }
+  ]
+}`)
+		dispatchMessage(t, s, `{
+  "channel": "l2_data",
+  "client_id": "",
+  "timestamp": "2022-06-13T12:00:01Z",
+  "sequence_num": 2,
+  "events": [
+    {
+      "type": "update",
+      "product_id": "BTC-USD",
+      "updates": [
+        {"side": "bid", "event_time": "2022-06-13T12:00:00.9Z", "price_level": "19200.01", "new_quantity": "0"}
+      ]
+    }
+  ]
+}`)
+
+		require.Len(t, snapshots, 1)
+		assert.Equal(t, "BTCUSD", snapshots[0].Symbol)
+		assert.Len(t, snapshots[0].Bids, 2)
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("19200.5"), Volume: fixedpoint.MustNewFromString("0.3")},
+		}, snapshots[0].Asks)
+
+		require.Len(t, updates, 1)
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("19200.01"), Volume: fixedpoint.Zero},
+		}, updates[0].Bids)
+		assert.Empty(t, updates[0].Asks)
+	})
+
+	t.Run("handle ticker", func(t *testing.T) {
+		s := NewStream(nil, nil)
+
+		var bookTickers []types.BookTicker
+		s.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+			bookTickers = append(bookTickers, bookTicker)
+		})
+
+		dispatchMessage(t, s, `{
+  "channel": "ticker",
+  "client_id": "",
+  "timestamp": "2022-06-13T12:00:00Z",
+  "sequence_num": 0,
+  "events": [
+    {
+      "type": "snapshot",
+      "tickers": [
+        {
+          "type": "ticker",
+          "product_id": "ETH-USD",
+          "price": "1054.61",
+          "volume_24_h": "383591.06891337",
+          "low_24_h": "1040.12",
+          "high_24_h": "1211.81",
+          "low_52_w": "880.28",
+          "high_52_w": "4878.26",
+          "price_percent_chg_24_h": "-12.47",
+          "best_bid": "1054.6",
+          "best_bid_quantity": "1.5",
+          "best_ask": "1054.65",
+          "best_ask_quantity": "2.01"
+        }
+      ]
+    }
+  ]
+}`)
+
+		require.Len(t, bookTickers, 1)
+		assert.Equal(t, types.BookTicker{
+			Symbol:   "ETHUSD",
+			Buy:      fixedpoint.MustNewFromString("1054.6"),
+			BuySize:  fixedpoint.MustNewFromString("1.5"),
+			Sell:     fixedpoint.MustNewFromString("1054.65"),
+			SellSize: fixedpoint.MustNewFromString("2.01"),
+		}, bookTickers[0])
+	})
+
+	t.Run("handle market trades", func(t *testing.T) {
+		s := NewStream(nil, nil)
+
+		var trades []types.Trade
+		s.OnMarketTrade(func(trade types.Trade) {
+			trades = append(trades, trade)
+		})
+
+		dispatchMessage(t, s, `{
+  "channel": "market_trades",
+  "client_id": "",
+  "timestamp": "2022-06-13T12:00:00Z",
+  "sequence_num": 0,
+  "events": [
+    {
+      "type": "snapshot",
+      "trades": [
+        {"trade_id": "000000000", "product_id": "BTC-USD", "price": "19000", "size": "1", "side": "BUY", "time": "2022-06-13T11:59:00Z"}
+      ]
+    },
+    {
+      "type": "update",
+      "trades": [
+        {"trade_id": "000000001", "product_id": "BTC-USD", "price": "19210.5", "size": "0.02", "side": "SELL", "time": "2022-06-13T11:59:59.5Z"}
+      ]
+    }
+  ]
+}`)
+
+		require.Len(t, trades, 1, "the snapshot trades should be skipped")
+		assert.Equal(t, types.Trade{
+			ID:            hashStringID("000000001"),
+			Exchange:      types.ExchangeCoinbase,
+			Price:         fixedpoint.MustNewFromString("19210.5"),
+			Quantity:      fixedpoint.MustNewFromString("0.02"),
+			QuoteQuantity: fixedpoint.MustNewFromString("384.21"),
+			Symbol:        "BTCUSD",
+			Side:          types.SideTypeSell,
+			Time:          types.Time(time.Date(2022, 6, 13, 11, 59, 59, 500000000, time.UTC)),
+		}, trades[0])
+	})
+
+	t.Run("handle candles", func(t *testing.T) {
+		s := NewStream(nil, nil)
+
+		var klines, closedKLines []types.KLine
+		s.OnKLine(func(kline types.KLine) {
+			klines = append(klines, kline)
+		})
+		s.OnKLineClosed(func(kline types.KLine) {
+			closedKLines = append(closedKLines, kline)
+		})
+
+		candles := []string{
+			`{"start": "1655121600", "high": "19250", "low": "19190", "open": "19200", "close": "19230", "volume": "12.5", "product_id": "BTC-USD"}`,
+			`{"start": "1655121600", "high": "19260", "low": "19190", "open": "19200", "close": "19255", "volume": "13", "product_id": "BTC-USD"}`,
+			`{"start": "1655121900", "high": "19270", "low": "19255", "open": "19255", "close": "19265", "volume": "0.5", "product_id": "BTC-USD"}`,
+		}
+
+		for i, c := range candles {
+			dispatchMessage(t, s, `{"channel": "candles", "client_id": "", "timestamp": "2022-06-13T12:05:00Z", "sequence_num": `+
+				strconv.Itoa(i+1)+`, "events": [{"type": "update", "candles": [`+c+`]}]}`)
+		}
+
+		require.Len(t, klines, 3)
+		assert.False(t, klines[2].Closed)
+		assert.Equal(t, types.Interval5m, klines[2].Interval)
+
+		require.Len(t, closedKLines, 1)
+		closed := closedKLines[0]
+		assert.True(t, closed.Closed)
+		assert.Equal(t, "BTCUSD", closed.Symbol)
+		assert.Equal(t, int64(1655121600), closed.StartTime.Unix())
+		assert.Equal(t, fixedpoint.NewFromInt(19255), closed.Close)
+		assert.Equal(t, fixedpoint.NewFromInt(13), closed.Volume)
+	})
+
+	t.Run("handle user order and trade updates", func(t *testing.T) {
+		fillsResp := `
+{
+  "fills": [
+    {
+      "entry_id": "22222-2222222-22222222",
+      "trade_id": "3b47a6a4-2f6a-4c44-8d6a-5a3b3a4a8a02",
+      "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+      "trade_time": "2022-06-13T04:10:00.5Z",
+      "trade_type": "FILL",
+      "price": "19500.00",
+      "size": "0.0012",
+      "commission": "0.0936",
+      "product_id": "BTC-USD",
+      "sequence_timestamp": "2022-06-13T04:10:00.512Z",
+      "liquidity_indicator": "MAKER",
+      "size_in_quote": false,
+      "user_id": "5d1c5b8e-2c4b-5a8b-8f8e-7c0f3e2e1a2b",
+      "side": "SELL"
+    },
+    {
+      "entry_id": "11111-1111111-11111111",
+      "trade_id": "3b47a6a4-2f6a-4c44-8d6a-5a3b3a4a8a01",
+      "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+      "trade_time": "2022-06-13T04:08:00Z",
+      "trade_type": "FILL",
+      "price": "19500.00",
+      "size": "0.0008",
+      "commission": "0.0624",
+      "product_id": "BTC-USD",
+      "sequence_timestamp": "2022-06-13T04:08:00.012Z",
+      "liquidity_indicator": "TAKER",
+      "size_in_quote": false,
+      "user_id": "5d1c5b8e-2c4b-5a8b-8f8e-7c0f3e2e1a2b",
+      "side": "SELL"
+    }
+  ],
+  "cursor": ""
+}
+`
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, fillsResp)
+		}))
+		defer ts.Close()
+
+		ex := New("key", "secret")
+		serverURL, err := url.Parse(ts.URL)
+		assert.NoError(t, err)
+		ex.client.BaseURL = serverURL
+
+		s := NewStream(ex.client, ex)
+
+		var orders []types.Order
+		s.OnOrderUpdate(func(order types.Order) {
+			orders = append(orders, order)
+		})
+
+		var trades []types.Trade
+		s.OnTradeUpdate(func(trade types.Trade) {
+			trades = append(trades, trade)
+		})
+
+		userMessage := func(seq, eventType, status, cumulative, leaves string) string {
+			return `{
+  "channel": "user",
+  "client_id": "",
+  "timestamp": "2022-06-13T04:10:01Z",
+  "sequence_num": ` + seq + `,
+  "events": [
+    {
+      "type": "` + eventType + `",
+      "orders": [
+        {
+          "order_id": "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+          "client_order_id": "grid-2",
+          "cumulative_quantity": "` + cumulative + `",
+          "leaves_quantity": "` + leaves + `",
+          "avg_price": "19500",
+          "total_fees": "0",
+          "status": "` + status + `",
+          "product_id": "BTC-USD",
+          "creation_time": "2022-06-13T04:05:06.123Z",
+          "order_side": "SELL",
+          "order_type": "LIMIT",
+          "limit_price": "19500",
+          "stop_price": "",
+          "post_only": false,
+          "time_in_force": "GOOD_UNTIL_CANCELLED",
+          "number_of_fills": "0"
+        }
+      ]
+    }
+  ]
+}`
+		}
+
+		dispatchMessage(t, s, userMessage("1", "snapshot", "OPEN", "0", "0.002"))
+		require.Len(t, orders, 1)
+		assert.Equal(t, types.Order{
+			SubmitOrder: types.SubmitOrder{
+				ClientOrderID: "grid-2",
+				Symbol:        "BTCUSD",
+				Side:          types.SideTypeSell,
+				Type:          types.OrderTypeLimit,
+				Quantity:      fixedpoint.MustNewFromString("0.002"),
+				Price:         fixedpoint.NewFromInt(19500),
+				TimeInForce:   types.TimeInForceGTC,
+			},
+			Exchange:         types.ExchangeCoinbase,
+			OrderID:          hashStringID("0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3"),
+			UUID:             "0d5b7c34-8f7a-4a39-9d6e-2c1ac6a0d7f3",
+			Status:           types.OrderStatusNew,
+			ExecutedQuantity: fixedpoint.Zero,
+			IsWorking:        true,
+			CreationTime:     types.Time(time.Date(2022, 6, 13, 4, 5, 6, 123000000, time.UTC)),
+			UpdateTime:       types.Time(time.Date(2022, 6, 13, 4, 10, 1, 0, time.UTC)),
+		}, orders[0])
+		assert.Empty(t, trades)
+
+		// the fills of the order are queried when the cumulative quantity is increased
+		dispatchMessage(t, s, userMessage("2", "update", "FILLED", "0.002", "0"))
+		require.Len(t, orders, 2)
+		assert.Equal(t, types.OrderStatusFilled, orders[1].Status)
+		assert.False(t, orders[1].IsWorking)
+
+		require.Len(t, trades, 2)
+		assert.Equal(t, hashStringID("3b47a6a4-2f6a-4c44-8d6a-5a3b3a4a8a01"), trades[0].ID)
+		assert.Equal(t, hashStringID("3b47a6a4-2f6a-4c44-8d6a-5a3b3a4a8a02"), trades[1].ID)
+
+		// the duplicated update should not emit the trades again
+		dispatchMessage(t, s, userMessage("3", "update", "FILLED", "0.002", "0"))
+		assert.Len(t, orders, 3)
+		assert.Len(t, trades, 2)
+	})
+
+	t.Run("handle heartbeats and errors", func(t *testing.T) {
+		s := NewStream(nil, nil)
+
+		dispatchMessage(t, s, `{"channel": "heartbeats", "client_id": "", "timestamp": "2022-06-13T12:00:00Z", "sequence_num": 7, "events": [{"current_time": "2022-06-13 12:00:00 +0000 UTC", "heartbeat_counter": 3}]}`)
+		assert.Equal(t, int64(7), s.lastSequence)
+
+		m, err := parseWebSocketMessage([]byte(`{"type": "error", "message": "failure to subscribe"}`))
+		require.NoError(t, err)
+		assert.Equal(t, &ErrorMessage{Type: "error", Message: "failure to subscribe"}, m)
+
+		_, err = parseWebSocketMessage([]byte(`{"channel": "unknown", "sequence_num": 8}`))
+		assert.Error(t, err)
+	})
+}
+
+func TestStream_checkSequence(t *testing.T) {
+	s := NewStream(nil, nil)
+	assert.True(t, s.checkSequence(0))
+	assert.True(t, s.checkSequence(1))
+	assert.True(t, s.checkSequence(2))
+	assert.False(t, s.checkSequence(4))
+
+	// the sequence is reset on the new connection
+	s.lastSequence = -1
+	assert.True(t, s.checkSequence(0))
+	assert.False(t, s.checkSequence(0))
+}
+
+func Test_convertSubscription(t *testing.T) {
+	channel, err := convertSubscription(types.Subscription{Symbol: "BTCUSD", Channel: types.BookChannel})
+	assert.NoError(t, err)
+	assert.Equal(t, "level2", channel)
+
+	channel, err = convertSubscription(types.Subscription{Symbol: "BTCUSD", Channel: types.KLineChannel, Options: types.SubscribeOptions{Interval: types.Interval5m}})
+	assert.NoError(t, err)
+	assert.Equal(t, "candles", channel)
+
+	_, err = convertSubscription(types.Subscription{Symbol: "BTCUSD", Channel: types.KLineChannel, Options: types.SubscribeOptions{Interval: types.Interval1m}})
+	assert.Error(t, err)
+}
@@ -6,10 +6,13 @@ import (
 	"strings"
 
 	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/exchange/bybit"
+	"github.com/c9s/bbgo/pkg/exchange/coinbase"
 	"github.com/c9s/bbgo/pkg/exchange/ftx"
 	"github.com/c9s/bbgo/pkg/exchange/kucoin"
 	"github.com/c9s/bbgo/pkg/exchange/max"
 	"github.com/c9s/bbgo/pkg/exchange/okex"
+	"github.com/c9s/bbgo/pkg/exchange/replay"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
@@ -35,6 +38,16 @@ func NewStandard(n types.ExchangeName, key, secret, passphrase, subAccount strin
 	case types.ExchangeKucoin:
 		return kucoin.New(key, secret, passphrase), nil
 
+	case types.ExchangeBybit:
+		return bybit.New(key, secret), nil
+
+	case types.ExchangeCoinbase:
+		return coinbase.New(key, secret), nil
+
+	case types.ExchangeReplay:
+		// the replay exchange does not need the credentials, the cassette file is defined by the env var
+		return replay.Open(os.Getenv("REPLAY_CASSETTE"), NewPublic)
+
 	default:
 		return nil, fmt.Errorf("unsupported exchange: %v", n)
 
@@ -48,6 +61,10 @@ func NewWithEnvVarPrefix(n types.ExchangeName, varPrefix string) (types.Exchange
 
 	varPrefix = strings.ToUpper(varPrefix)
 
+	if n == types.ExchangeReplay {
+		return replay.Open(os.Getenv(varPrefix+"_CASSETTE"), NewPublic)
+	}
+
 	key := os.Getenv(varPrefix + "_API_KEY")
 	secret := os.Getenv(varPrefix + "_API_SECRET")
 	if len(key) == 0 || len(secret) == 0 {
@@ -16,6 +16,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/exchange/ftx/ftxapi"
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -97,7 +98,7 @@ func NewExchange(key, secret string, subAccount string) *Exchange {
 }
 
 func (e *Exchange) newRest() *restRequest {
-	r := newRestRequest(&http.Client{Timeout: defaultHTTPTimeout}, e.restEndpoint).Auth(e.key, e.secret)
+	r := newRestRequest(&http.Client{Timeout: defaultHTTPTimeout, Transport: ratelimit.NewTransport(ftxapi.Limiter, nil)}, e.restEndpoint).Auth(e.key, e.secret)
 	if len(e.subAccount) > 0 {
 		r.SubAccount(e.subAccount)
 	}
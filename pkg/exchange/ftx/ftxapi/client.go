@@ -18,6 +18,8 @@ import (
 
 	"github.com/c9s/requestgen"
 	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 )
 
 const defaultHTTPTimeout = time.Second * 15
@@ -53,7 +55,8 @@ func NewClient() *RestClient {
 	client := &RestClient{
 		BaseURL: u,
 		client: &http.Client{
-			Timeout: defaultHTTPTimeout,
+			Timeout:   defaultHTTPTimeout,
+			Transport: ratelimit.NewTransport(Limiter, nil),
 		},
 	}
 
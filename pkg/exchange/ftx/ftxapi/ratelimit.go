@@ -0,0 +1,12 @@
+package ftxapi
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+// Limiter tracks the requests of the FTX api, FTX responds 429 when there are more than 30 requests per second
+var Limiter = ratelimit.Register(ratelimit.New("ftx",
+	ratelimit.Rule{Name: "requests", Limit: 28, Interval: time.Second, PathPrefix: "/api/", DefaultWeight: 1},
+))
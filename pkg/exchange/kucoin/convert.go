@@ -223,6 +223,8 @@ func toGlobalOrder(o kucoinapi.Order) types.Order {
 		IsWorking:        o.IsActive,
 		CreationTime:     types.Time(o.CreatedAt.Time()),
 		UpdateTime:       types.Time(o.CreatedAt.Time()), // kucoin does not response updated time
+		IsMargin:         isMarginTradeType(o.TradeType),
+		IsIsolated:       o.TradeType == kucoinapi.TradeTypeIsolatedMarginTrade,
 	}
 	return order
 }
@@ -242,6 +244,77 @@ func toGlobalTrade(fill kucoinapi.Fill) types.Trade {
 		Time:          types.Time(fill.CreatedAt.Time()),
 		Fee:           fill.Fee,
 		FeeCurrency:   toGlobalSymbol(fill.FeeCurrency),
+		IsMargin:      isMarginTradeType(fill.TradeType),
+		IsIsolated:    fill.TradeType == kucoinapi.TradeTypeIsolatedMarginTrade,
 	}
 	return trade
 }
+
+func isMarginTradeType(tradeType kucoinapi.TradeType) bool {
+	return tradeType == kucoinapi.TradeTypeCrossMarginTrade || tradeType == kucoinapi.TradeTypeIsolatedMarginTrade
+}
+
+func toGlobalMarginBalanceMap(account *kucoinapi.MarginAccount) types.BalanceMap {
+	balances := types.BalanceMap{}
+	for _, asset := range account.Accounts {
+		balances[asset.Currency] = types.Balance{
+			Currency:  asset.Currency,
+			Available: asset.AvailableBalance,
+			Locked:    asset.HoldBalance,
+			Borrowed:  asset.Liability,
+			NetAsset:  asset.TotalBalance.Sub(asset.Liability),
+		}
+	}
+
+	return balances
+}
+
+func toGlobalIsolatedMarginBalanceMap(account *kucoinapi.IsolatedMarginAccount) types.BalanceMap {
+	balances := types.BalanceMap{}
+	for _, asset := range []kucoinapi.IsolatedMarginAsset{account.BaseAsset, account.QuoteAsset} {
+		// the liability of the isolated margin asset includes the interest
+		balances[asset.Currency] = types.Balance{
+			Currency:  asset.Currency,
+			Available: asset.AvailableBalance,
+			Locked:    asset.HoldBalance,
+			Borrowed:  asset.Liability.Sub(asset.Interest),
+			Interest:  asset.Interest,
+			NetAsset:  asset.TotalBalance.Sub(asset.Liability),
+		}
+	}
+
+	return balances
+}
+
+func toGlobalLoan(record kucoinapi.BorrowRecord) types.MarginLoan {
+	return types.MarginLoan{
+		Exchange:       types.ExchangeKucoin,
+		TransactionID:  hashStringID(record.OrderNo),
+		Asset:          record.Currency,
+		Principle:      record.ActualSize,
+		Time:           types.Time(record.CreatedTime.Time()),
+		IsolatedSymbol: toGlobalSymbol(record.Symbol),
+	}
+}
+
+func toGlobalRepay(record kucoinapi.RepayRecord) types.MarginRepay {
+	return types.MarginRepay{
+		Exchange:       types.ExchangeKucoin,
+		TransactionID:  hashStringID(record.OrderNo),
+		Asset:          record.Currency,
+		Principle:      record.Principal,
+		Time:           types.Time(record.CreatedTime.Time()),
+		IsolatedSymbol: toGlobalSymbol(record.Symbol),
+	}
+}
+
+func toGlobalInterest(record kucoinapi.InterestRecord, isolatedSymbol string) types.MarginInterest {
+	return types.MarginInterest{
+		Exchange:       types.ExchangeKucoin,
+		Asset:          record.Currency,
+		Interest:       record.InterestAmount,
+		InterestRate:   record.DayRatio,
+		IsolatedSymbol: isolatedSymbol,
+		Time:           types.Time(record.CreatedTime.Time()),
+	}
+}
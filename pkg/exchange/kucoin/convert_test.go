@@ -0,0 +1,366 @@
+package kucoin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// the fixtures are the data of the kucoin api responses
+
+func Test_toGlobalOrder(t *testing.T) {
+	// GET /api/v1/orders/{orderId} and the items of GET /api/v1/orders
+	payload := `[
+	  {
+		"id": "5c35c02703aa673ceec2a168",
+		"symbol": "BTC-USDT",
+		"opType": "DEAL",
+		"type": "limit",
+		"side": "buy",
+		"price": "20000",
+		"size": "0.1",
+		"funds": "0",
+		"dealFunds": "800",
+		"dealSize": "0.04",
+		"fee": "0.8",
+		"feeCurrency": "USDT",
+		"stp": "",
+		"stop": "",
+		"stopTriggered": false,
+		"stopPrice": "0",
+		"timeInForce": "GTC",
+		"postOnly": false,
+		"hidden": false,
+		"iceberg": false,
+		"visibleSize": "0",
+		"cancelAfter": 0,
+		"channel": "API",
+		"clientOid": "b1",
+		"remark": "",
+		"tags": "",
+		"isActive": true,
+		"cancelExist": false,
+		"createdAt": 1547026471000,
+		"tradeType": "TRADE"
+	  },
+	  {
+		"id": "5c35c02703aa673ceec2a169",
+		"symbol": "ETH-USDT",
+		"opType": "DEAL",
+		"type": "limit",
+		"side": "sell",
+		"price": "1500",
+		"size": "1",
+		"dealSize": "0.3",
+		"timeInForce": "GTC",
+		"clientOid": "",
+		"isActive": false,
+		"cancelExist": true,
+		"createdAt": 1547026472000,
+		"tradeType": "MARGIN_ISOLATED_TRADE"
+	  },
+	  {
+		"id": "5c35c02703aa673ceec2a170",
+		"symbol": "ETH-USDT",
+		"opType": "DEAL",
+		"type": "market",
+		"side": "buy",
+		"size": "1",
+		"dealSize": "1",
+		"timeInForce": "GTC",
+		"isActive": false,
+		"cancelExist": false,
+		"createdAt": 1547026473000,
+		"tradeType": "MARGIN_TRADE"
+	  }
+	]`
+
+	var kucoinOrders []kucoinapi.Order
+	assert.NoError(t, json.Unmarshal([]byte(payload), &kucoinOrders))
+	if !assert.Len(t, kucoinOrders, 3) {
+		return
+	}
+
+	createdAt := types.Time(types.NewMillisecondTimestampFromInt(1547026471000).Time())
+	assert.Equal(t, types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: "b1",
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			Type:          types.OrderTypeLimit,
+			Quantity:      fixedpoint.MustNewFromString("0.1"),
+			Price:         fixedpoint.NewFromInt(20000),
+			StopPrice:     fixedpoint.Zero,
+			TimeInForce:   types.TimeInForceGTC,
+		},
+		Exchange:         types.ExchangeKucoin,
+		OrderID:          hashStringID("5c35c02703aa673ceec2a168"),
+		UUID:             "5c35c02703aa673ceec2a168",
+		Status:           types.OrderStatusPartiallyFilled,
+		ExecutedQuantity: fixedpoint.MustNewFromString("0.04"),
+		IsWorking:        true,
+		CreationTime:     createdAt,
+		UpdateTime:       createdAt,
+	}, toGlobalOrder(kucoinOrders[0]))
+
+	order := toGlobalOrder(kucoinOrders[1])
+	assert.Equal(t, types.OrderStatusCanceled, order.Status)
+	assert.Equal(t, types.SideTypeSell, order.Side)
+	assert.False(t, order.IsWorking)
+	assert.True(t, order.IsMargin)
+	assert.True(t, order.IsIsolated)
+
+	order = toGlobalOrder(kucoinOrders[2])
+	assert.Equal(t, types.OrderStatusFilled, order.Status)
+	assert.Equal(t, types.OrderTypeMarket, order.Type)
+	assert.True(t, order.IsMargin)
+	assert.False(t, order.IsIsolated)
+}
+
+func Test_toGlobalTrade(t *testing.T) {
+	// the items of GET /api/v1/fills
+	payload := `[
+	  {
+		"symbol": "BTC-USDT",
+		"tradeId": "5c35c02709e4f67d5266954e",
+		"orderId": "5c35c02703aa673ceec2a168",
+		"counterOrderId": "5c1ab46003aa676e487fa8e3",
+		"side": "buy",
+		"liquidity": "taker",
+		"forceTaker": true,
+		"price": "20000",
+		"size": "0.04",
+		"funds": "800",
+		"fee": "0.8",
+		"feeRate": "0.001",
+		"feeCurrency": "USDT",
+		"stop": "",
+		"type": "limit",
+		"createdAt": 1547026472000,
+		"tradeType": "TRADE"
+	  },
+	  {
+		"symbol": "ETH-USDT",
+		"tradeId": "5c35c02709e4f67d5266954f",
+		"orderId": "5c35c02703aa673ceec2a169",
+		"side": "sell",
+		"liquidity": "maker",
+		"price": "1500",
+		"size": "0.3",
+		"funds": "450",
+		"fee": "0.45",
+		"feeRate": "0.001",
+		"feeCurrency": "USDT",
+		"type": "limit",
+		"createdAt": 1547026473000,
+		"tradeType": "MARGIN_ISOLATED_TRADE"
+	  }
+	]`
+
+	var fills []kucoinapi.Fill
+	assert.NoError(t, json.Unmarshal([]byte(payload), &fills))
+	if !assert.Len(t, fills, 2) {
+		return
+	}
+
+	assert.Equal(t, types.Trade{
+		ID:            hashStringID("5c35c02709e4f67d5266954e"),
+		OrderID:       hashStringID("5c35c02703aa673ceec2a168"),
+		Exchange:      types.ExchangeKucoin,
+		Price:         fixedpoint.NewFromInt(20000),
+		Quantity:      fixedpoint.MustNewFromString("0.04"),
+		QuoteQuantity: fixedpoint.NewFromInt(800),
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		IsMaker:       false,
+		Time:          types.Time(types.NewMillisecondTimestampFromInt(1547026472000).Time()),
+		// the fee of kucoin is always the positive charged fee
+		Fee:         fixedpoint.MustNewFromString("0.8"),
+		FeeCurrency: "USDT",
+	}, toGlobalTrade(fills[0]))
+
+	trade := toGlobalTrade(fills[1])
+	assert.Equal(t, "ETHUSDT", trade.Symbol)
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.False(t, trade.IsBuyer)
+	assert.True(t, trade.IsMaker)
+	assert.True(t, trade.IsMargin)
+	assert.True(t, trade.IsIsolated)
+}
+
+func Test_toGlobalLoan(t *testing.T) {
+	// GET /api/v3/margin/borrow
+	payload := `{
+	  "currentPage": 1,
+	  "pageSize": 50,
+	  "totalNum": 1,
+	  "totalPage": 1,
+	  "items": [
+		{
+		  "orderNo": "148045916803936256",
+		  "symbol": "BTC-USDT",
+		  "currency": "USDT",
+		  "size": "1000",
+		  "actualSize": "1000",
+		  "status": "SUCCESS",
+		  "createdTime": 1697783812257
+		}
+	  ]
+	}`
+
+	var page kucoinapi.BorrowHistoryPage
+	assert.NoError(t, json.Unmarshal([]byte(payload), &page))
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, kucoinapi.BorrowStatusSuccess, page.Items[0].Status)
+		assert.Equal(t, types.MarginLoan{
+			Exchange:       types.ExchangeKucoin,
+			TransactionID:  hashStringID("148045916803936256"),
+			Asset:          "USDT",
+			Principle:      fixedpoint.NewFromInt(1000),
+			Time:           types.Time(types.NewMillisecondTimestampFromInt(1697783812257).Time()),
+			IsolatedSymbol: "BTCUSDT",
+		}, toGlobalLoan(page.Items[0]))
+	}
+}
+
+func Test_toGlobalRepay(t *testing.T) {
+	// GET /api/v3/margin/repay, the cross margin records have no symbol
+	payload := `{
+	  "currentPage": 1,
+	  "pageSize": 50,
+	  "totalNum": 1,
+	  "totalPage": 1,
+	  "items": [
+		{
+		  "orderNo": "148045916803936257",
+		  "symbol": null,
+		  "currency": "USDT",
+		  "size": "1000.01",
+		  "principal": "1000",
+		  "interest": "0.01",
+		  "status": "SUCCESS",
+		  "createdTime": 1697783899672
+		}
+	  ]
+	}`
+
+	var page kucoinapi.RepayHistoryPage
+	assert.NoError(t, json.Unmarshal([]byte(payload), &page))
+	if assert.Len(t, page.Items, 1) {
+		// the principal is repaid, the interest is recorded by the interest history
+		assert.Equal(t, types.MarginRepay{
+			Exchange:      types.ExchangeKucoin,
+			TransactionID: hashStringID("148045916803936257"),
+			Asset:         "USDT",
+			Principle:     fixedpoint.NewFromInt(1000),
+			Time:          types.Time(types.NewMillisecondTimestampFromInt(1697783899672).Time()),
+		}, toGlobalRepay(page.Items[0]))
+	}
+}
+
+func Test_toGlobalInterest(t *testing.T) {
+	// GET /api/v3/margin/interest
+	payload := `{
+	  "currentPage": 1,
+	  "pageSize": 50,
+	  "totalNum": 1,
+	  "totalPage": 1,
+	  "items": [
+		{
+		  "currency": "USDT",
+		  "dayRatio": "0.000296",
+		  "interestAmount": "0.00000012",
+		  "createdTime": 1697785200000
+		}
+	  ]
+	}`
+
+	var page kucoinapi.InterestHistoryPage
+	assert.NoError(t, json.Unmarshal([]byte(payload), &page))
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, types.MarginInterest{
+			Exchange:       types.ExchangeKucoin,
+			Asset:          "USDT",
+			Interest:       fixedpoint.MustNewFromString("0.00000012"),
+			InterestRate:   fixedpoint.MustNewFromString("0.000296"),
+			IsolatedSymbol: "BTCUSDT",
+			Time:           types.Time(types.NewMillisecondTimestampFromInt(1697785200000).Time()),
+		}, toGlobalInterest(page.Items[0], "BTCUSDT"))
+	}
+}
+
+func Test_toGlobalMarginBalanceMap(t *testing.T) {
+	// GET /api/v1/margin/account
+	payload := `{
+	  "debtRatio": "0.33",
+	  "accounts": [
+		{
+		  "currency": "USDT",
+		  "totalBalance": "3000",
+		  "availableBalance": "2500",
+		  "holdBalance": "500",
+		  "liability": "1000",
+		  "maxBorrowSize": "5000"
+		}
+	  ]
+	}`
+
+	var account kucoinapi.MarginAccount
+	assert.NoError(t, json.Unmarshal([]byte(payload), &account))
+	assert.Equal(t, types.Balance{
+		Currency:  "USDT",
+		Available: fixedpoint.NewFromInt(2500),
+		Locked:    fixedpoint.NewFromInt(500),
+		Borrowed:  fixedpoint.NewFromInt(1000),
+		NetAsset:  fixedpoint.NewFromInt(2000),
+	}, toGlobalMarginBalanceMap(&account)["USDT"])
+}
+
+func Test_toGlobalIsolatedMarginBalanceMap(t *testing.T) {
+	// GET /api/v1/isolated/account/{symbol}
+	payload := `{
+	  "symbol": "BTC-USDT",
+	  "status": "DEBT",
+	  "debtRatio": "0.2",
+	  "baseAsset": {
+		"currency": "BTC",
+		"totalBalance": "0.1",
+		"holdBalance": "0",
+		"availableBalance": "0.1",
+		"liability": "0",
+		"interest": "0",
+		"borrowableAmount": "0.5"
+	  },
+	  "quoteAsset": {
+		"currency": "USDT",
+		"totalBalance": "3000",
+		"holdBalance": "0",
+		"availableBalance": "3000",
+		"liability": "1000.5",
+		"interest": "0.5",
+		"borrowableAmount": "2000"
+	  }
+	}`
+
+	var account kucoinapi.IsolatedMarginAccount
+	assert.NoError(t, json.Unmarshal([]byte(payload), &account))
+
+	balances := toGlobalIsolatedMarginBalanceMap(&account)
+	assert.Len(t, balances, 2)
+
+	// the liability includes the interest
+	assert.Equal(t, types.Balance{
+		Currency:  "USDT",
+		Available: fixedpoint.NewFromInt(3000),
+		Locked:    fixedpoint.Zero,
+		Borrowed:  fixedpoint.NewFromInt(1000),
+		Interest:  fixedpoint.MustNewFromString("0.5"),
+		NetAsset:  fixedpoint.MustNewFromString("1999.5"),
+	}, balances["USDT"])
+}
@@ -31,6 +31,8 @@ var log = logrus.WithFields(logrus.Fields{
 })
 
 type Exchange struct {
+	types.MarginSettings
+
 	key, secret, passphrase string
 	client                  *kucoinapi.RestClient
 }
@@ -61,6 +63,12 @@ func (e *Exchange) PlatformFeeCurrency() string {
 }
 
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	if e.IsIsolatedMargin {
+		return e.queryIsolatedMarginAccount(ctx)
+	} else if e.IsMargin {
+		return e.queryCrossMarginAccount(ctx)
+	}
+
 	req := e.client.AccountService.NewListAccountsRequest()
 	accounts, err := req.Do(ctx)
 	if err != nil {
@@ -69,12 +77,22 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 
 	// for now, we only return the trading account
 	a := types.NewAccount()
+	a.AccountType = types.AccountTypeSpot
 	balances := toGlobalBalanceMap(accounts)
 	a.UpdateBalances(balances)
 	return a, nil
 }
 
 func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	if e.IsMargin {
+		account, err := e.QueryAccount(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return account.Balances(), nil
+	}
+
 	req := e.client.AccountService.NewListAccountsRequest()
 	accounts, err := req.Do(ctx)
 	if err != nil {
@@ -213,7 +231,7 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 		req.Symbol(toLocalSymbol(order.Symbol))
 		req.Side(toLocalSide(order.Side))
 
-		if order.ClientOrderID != "" {
+		if order.ClientOrderID != "" && order.ClientOrderID != types.NoClientOrderID {
 			req.ClientOrderID(order.ClientOrderID)
 		}
 
@@ -260,7 +278,13 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			req.OrderType(kucoinapi.OrderTypeMarket)
 		}
 
-		orderResponse, err := req.Do(ctx)
+		var orderResponse *kucoinapi.OrderResponse
+		if e.IsMargin {
+			orderResponse, err = e.client.MarginService.NewPlaceMarginOrderRequest(req, e.localMarginModel()).Do(ctx)
+		} else {
+			orderResponse, err = req.Do(ctx)
+		}
+
 		if err != nil {
 			return createdOrders, err
 		}
@@ -275,6 +299,8 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			IsWorking:        true,
 			CreationTime:     types.Time(time.Now()),
 			UpdateTime:       types.Time(time.Now()),
+			IsMargin:         e.IsMargin,
+			IsIsolated:       e.IsIsolatedMargin,
 		})
 	}
 
@@ -305,6 +331,7 @@ func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders [
 	req := e.client.TradeService.NewListOrdersRequest()
 	req.Symbol(toLocalSymbol(symbol))
 	req.Status("active")
+	req.TradeType(e.localTradeType())
 	orderList, err := req.Do(ctx)
 	if err != nil {
 		return nil, err
@@ -319,79 +346,155 @@ func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders [
 	return orders, err
 }
 
-func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
-	req := e.client.TradeService.NewListOrdersRequest()
-	req.Symbol(toLocalSymbol(symbol))
-	req.Status("done")
-	req.StartAt(since)
-
-	// kucoin:
-	// When you query orders in active status, there is no time limit.
-	// However, when you query orders in done status, the start and end time range cannot exceed 7* 24 hours.
-	// An error will occur if the specified time window exceeds the range.
-	// If you specify the end time only, the system will automatically calculate the start time as end time minus 7*24 hours, and vice versa.
-	if until.Sub(since) < 7*24*time.Hour {
-		req.EndAt(until)
+// queryWindow is the max time range of the done orders and the fills,
+// the start and end time range cannot exceed 7 * 24 hours.
+const queryWindow = 7 * 24 * time.Hour
+
+// queryPageSize is the max page size of the order list and the fill list
+const queryPageSize = 500
+
+// queryTimeWindows queries the time range window by window, the window query returns the number of the records.
+// The empty windows are skipped, and the query stops at the end of the time range or when the records of a full page
+// are collected, so that the batch query continues from the time of the last record.
+func queryTimeWindows(since, until time.Time, query func(startTime, endTime time.Time) (int, error)) error {
+	numOfRecords := 0
+	for startTime := since; startTime.Before(until); startTime = startTime.Add(queryWindow) {
+		endTime := startTime.Add(queryWindow)
+		if endTime.After(until) {
+			endTime = until
+		}
+
+		n, err := query(startTime, endTime)
+		if err != nil {
+			return err
+		}
+
+		numOfRecords += n
+		if numOfRecords >= queryPageSize {
+			return nil
+		}
 	}
 
+	return nil
+}
+
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
 	if err := queryOrderLimiter.Wait(ctx); err != nil {
 		return nil, err
 	}
 
-	orderList, err := req.Do(ctx)
-	if err != nil {
-		return orders, err
+	var o *kucoinapi.Order
+	var err error
+	if len(q.OrderID) > 0 {
+		req := e.client.TradeService.NewGetOrderRequest()
+		req.OrderID(q.OrderID)
+		o, err = req.Do(ctx)
+	} else if len(q.ClientOrderID) > 0 {
+		req := e.client.TradeService.NewGetOrderByClientOrderIDRequest()
+		req.ClientOrderID(q.ClientOrderID)
+		o, err = req.Do(ctx)
+	} else {
+		return nil, errors.New("either order id or client order id is required for querying kucoin order")
 	}
 
-	for _, o := range orderList.Items {
-		order := toGlobalOrder(o)
-		orders = append(orders, order)
+	if err != nil {
+		return nil, err
 	}
 
+	order := toGlobalOrder(*o)
+	return &order, nil
+}
+
+// QueryClosedOrders queries the done orders from the start time window by window, all the pages of a window are returned.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	err = queryTimeWindows(since, until, func(startTime, endTime time.Time) (int, error) {
+		n := 0
+		for page := 1; ; page++ {
+			req := e.client.TradeService.NewListOrdersRequest()
+			req.Symbol(toLocalSymbol(symbol))
+			req.Status("done")
+			req.TradeType(e.localTradeType())
+			req.StartAt(startTime)
+			req.EndAt(endTime)
+			req.CurrentPage(page)
+			req.PageSize(queryPageSize)
+
+			if err := queryOrderLimiter.Wait(ctx); err != nil {
+				return n, err
+			}
+
+			orderList, err := req.Do(ctx)
+			if err != nil {
+				return n, err
+			}
+
+			for _, o := range orderList.Items {
+				orders = append(orders, toGlobalOrder(o))
+			}
+
+			n += len(orderList.Items)
+			if page >= orderList.TotalPage {
+				return n, nil
+			}
+		}
+	})
+
 	return orders, err
 }
 
 var launchDate = time.Date(2017, 9, 0, 0, 0, 0, 0, time.UTC)
 
+// QueryTrades queries the fills from the start time window by window, all the pages of a window are returned.
+// Kucoin does not support the last trade ID query, the batch query moves the start time to the time of the last fill.
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
-	req := e.client.TradeService.NewGetFillsRequest()
-	req.Symbol(toLocalSymbol(symbol))
+	// we always sync trades in the ascending order, hence we need to set the start time here
+	since := launchDate
+	if options.StartTime != nil && options.StartTime.After(launchDate) {
+		since = *options.StartTime
+	}
 
-	// we always sync trades in the ascending order, and kucoin does not support last trade ID query
-	// hence we need to set the start time here
-	if options.StartTime != nil && options.StartTime.Before(launchDate) {
-		// copy the time data object
-		t := launchDate
-		options.StartTime = &t
+	until := time.Now()
+	if options.EndTime != nil {
+		until = *options.EndTime
 	}
 
-	if options.StartTime != nil && options.EndTime != nil {
-		req.StartAt(*options.StartTime)
+	err = queryTimeWindows(since, until, func(startTime, endTime time.Time) (int, error) {
+		n := 0
+		for page := 1; ; page++ {
+			req := e.client.TradeService.NewGetFillsRequest()
+			req.Symbol(toLocalSymbol(symbol))
+			req.TradeType(string(e.localTradeType()))
+			req.StartAt(startTime)
+			req.EndAt(endTime)
+			req.CurrentPage(page)
+			req.PageSize(queryPageSize)
+
+			if err := queryTradeLimiter.Wait(ctx); err != nil {
+				return n, err
+			}
 
-		if options.EndTime.Sub(*options.StartTime) < 7*24*time.Hour {
-			req.EndAt(*options.EndTime)
-		}
-	} else if options.StartTime != nil {
-		req.StartAt(*options.StartTime)
-	} else if options.EndTime != nil {
-		req.EndAt(*options.EndTime)
-	}
+			response, err := req.Do(ctx)
+			if err != nil {
+				return n, err
+			}
 
-	if err := queryTradeLimiter.Wait(ctx); err != nil {
-		return trades, err
-	}
+			for _, fill := range response.Items {
+				trades = append(trades, toGlobalTrade(fill))
+			}
 
-	response, err := req.Do(ctx)
-	if err != nil {
-		return trades, err
-	}
+			n += len(response.Items)
+			if page >= response.TotalPage {
+				return n, nil
+			}
+		}
+	})
 
-	for _, fill := range response.Items {
-		trade := toGlobalTrade(fill)
-		trades = append(trades, trade)
-	}
+	// the fills are returned in the descending order
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time.Time())
+	})
 
-	return trades, nil
+	return trades, err
 }
 
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (errs error) {
@@ -0,0 +1,75 @@
+package kucoin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/batch"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// windowedTradeHistoryService queries the trades window by window like QueryTrades,
+// the start time of the window is inclusive like the startAt parameter of kucoin.
+type windowedTradeHistoryService struct {
+	trades []types.Trade
+}
+
+func (s *windowedTradeHistoryService) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	err = queryTimeWindows(*options.StartTime, *options.EndTime, func(startTime, endTime time.Time) (int, error) {
+		n := 0
+		for _, trade := range s.trades {
+			if trade.Time.Before(startTime) || trade.Time.After(endTime) {
+				continue
+			}
+
+			trades = append(trades, trade)
+			n++
+		}
+		return n, nil
+	})
+	return trades, err
+}
+
+func (s *windowedTradeHistoryService) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	return nil, nil
+}
+
+func Test_queryTimeWindows(t *testing.T) {
+	startTime := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(30 * 24 * time.Hour)
+
+	// the gap between the trades is longer than the query window
+	service := &windowedTradeHistoryService{
+		trades: []types.Trade{
+			{ID: 1, Exchange: types.ExchangeKucoin, Symbol: "BTCUSDT", Time: types.Time(startTime.Add(time.Hour))},
+			{ID: 2, Exchange: types.ExchangeKucoin, Symbol: "BTCUSDT", Time: types.Time(startTime.Add(10 * 24 * time.Hour))},
+			{ID: 3, Exchange: types.ExchangeKucoin, Symbol: "BTCUSDT", Time: types.Time(startTime.Add(25 * 24 * time.Hour))},
+		},
+	}
+
+	q := &batch.TradeBatchQuery{ExchangeTradeHistoryService: service}
+	dataC, errC := q.Query(context.Background(), "BTCUSDT", &types.TradeQueryOptions{
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	})
+
+	var ids []uint64
+	for trade := range dataC {
+		ids = append(ids, trade.ID)
+	}
+
+	assert.NoError(t, <-errC)
+	assert.Equal(t, []uint64{1, 2, 3}, ids)
+
+	// the query stops when the records of a full page are collected
+	var windows []time.Time
+	err := queryTimeWindows(startTime, endTime, func(startTime, endTime time.Time) (int, error) {
+		windows = append(windows, startTime)
+		return queryPageSize, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{startTime}, windows)
+}
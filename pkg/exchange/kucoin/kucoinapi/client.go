@@ -15,6 +15,8 @@ import (
 
 	"github.com/c9s/requestgen"
 	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 )
 
 const defaultHTTPTimeout = time.Second * 15
@@ -31,6 +33,7 @@ type RestClient struct {
 	MarketDataService *MarketDataService
 	TradeService      *TradeService
 	BulletService     *BulletService
+	MarginService     *MarginService
 }
 
 func NewClient() *RestClient {
@@ -43,7 +46,8 @@ func NewClient() *RestClient {
 		BaseAPIClient: requestgen.BaseAPIClient{
 			BaseURL: u,
 			HttpClient: &http.Client{
-				Timeout: defaultHTTPTimeout,
+				Timeout:   defaultHTTPTimeout,
+				Transport: ratelimit.NewTransport(Limiter, nil),
 			},
 		},
 		KeyVersion: "2",
@@ -53,6 +57,7 @@ func NewClient() *RestClient {
 	client.MarketDataService = &MarketDataService{client: client}
 	client.TradeService = &TradeService{client: client}
 	client.BulletService = &BulletService{client: client}
+	client.MarginService = &MarginService{client: client}
 	return client
 }
 
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
 	"time"
@@ -47,9 +48,31 @@ func (r *GetFillsRequest) EndAt(endAt time.Time) *GetFillsRequest {
 	return r
 }
 
+func (r *GetFillsRequest) CurrentPage(currentPage int) *GetFillsRequest {
+	r.currentPage = &currentPage
+	return r
+}
+
+func (r *GetFillsRequest) PageSize(pageSize int) *GetFillsRequest {
+	r.pageSize = &pageSize
+	return r
+}
+
 // GetQueryParameters builds and checks the query parameters and returns url.Values
 func (r *GetFillsRequest) GetQueryParameters() (url.Values, error) {
 	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (r *GetFillsRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
 	// check orderID field -> json key orderId
 	if r.orderID != nil {
 		orderID := *r.orderID
@@ -134,18 +157,22 @@ func (r *GetFillsRequest) GetQueryParameters() (url.Values, error) {
 		params["endAt"] = strconv.FormatInt(endAt.UnixNano()/int64(time.Millisecond), 10)
 	} else {
 	}
+	// check currentPage field -> json key currentPage
+	if r.currentPage != nil {
+		currentPage := *r.currentPage
 
-	query := url.Values{}
-	for k, v := range params {
-		query.Add(k, fmt.Sprintf("%v", v))
+		// assign parameter of currentPage
+		params["currentPage"] = currentPage
+	} else {
 	}
+	// check pageSize field -> json key pageSize
+	if r.pageSize != nil {
+		pageSize := *r.pageSize
 
-	return query, nil
-}
-
-// GetParameters builds and checks the parameters and return the result in a map object
-func (r *GetFillsRequest) GetParameters() (map[string]interface{}, error) {
-	var params = map[string]interface{}{}
+		// assign parameter of pageSize
+		params["pageSize"] = pageSize
+	} else {
+	}
 
 	return params, nil
 }
@@ -159,8 +186,14 @@ func (r *GetFillsRequest) GetParametersQuery() (url.Values, error) {
 		return query, err
 	}
 
-	for k, v := range params {
-		query.Add(k, fmt.Sprintf("%v", v))
+	for _k, _v := range params {
+		if r.isVarSlice(_v) {
+			r.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
 	}
 
 	return query, nil
@@ -184,14 +217,31 @@ func (r *GetFillsRequest) GetSlugParameters() (map[string]interface{}, error) {
 }
 
 func (r *GetFillsRequest) applySlugsToUrl(url string, slugs map[string]string) string {
-	for k, v := range slugs {
-		needleRE := regexp.MustCompile(":" + k + "\\b")
-		url = needleRE.ReplaceAllString(url, v)
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
 	}
 
 	return url
 }
 
+func (r *GetFillsRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (r *GetFillsRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
 func (r *GetFillsRequest) GetSlugsMap() (map[string]string, error) {
 	slugs := map[string]string{}
 	params, err := r.GetSlugParameters()
@@ -199,8 +249,8 @@ func (r *GetFillsRequest) GetSlugsMap() (map[string]string, error) {
 		return slugs, nil
 	}
 
-	for k, v := range params {
-		slugs[k] = fmt.Sprintf("%v", v)
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
 	}
 
 	return slugs, nil
@@ -208,9 +258,9 @@ func (r *GetFillsRequest) GetSlugsMap() (map[string]string, error) {
 
 func (r *GetFillsRequest) Do(ctx context.Context) (*FillListPage, error) {
 
-	// no body params
+	// empty params for GET operation
 	var params interface{}
-	query, err := r.GetQueryParameters()
+	query, err := r.GetParametersQuery()
 	if err != nil {
 		return nil, err
 	}
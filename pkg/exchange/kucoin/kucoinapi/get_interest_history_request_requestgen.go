@@ -0,0 +1,237 @@
+// Code generated by "requestgen -method GET -responseType .APIResponse -responseDataField Data -url /api/v3/margin/interest -type GetInterestHistoryRequest -responseDataType .InterestHistoryPage"; DO NOT EDIT.
+
+package kucoinapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+func (g *GetInterestHistoryRequest) Currency(currency string) *GetInterestHistoryRequest {
+	g.currency = &currency
+	return g
+}
+
+func (g *GetInterestHistoryRequest) IsIsolated(isIsolated bool) *GetInterestHistoryRequest {
+	g.isIsolated = &isIsolated
+	return g
+}
+
+func (g *GetInterestHistoryRequest) Symbol(symbol string) *GetInterestHistoryRequest {
+	g.symbol = &symbol
+	return g
+}
+
+func (g *GetInterestHistoryRequest) StartTime(startTime time.Time) *GetInterestHistoryRequest {
+	g.startTime = &startTime
+	return g
+}
+
+func (g *GetInterestHistoryRequest) EndTime(endTime time.Time) *GetInterestHistoryRequest {
+	g.endTime = &endTime
+	return g
+}
+
+func (g *GetInterestHistoryRequest) CurrentPage(currentPage int) *GetInterestHistoryRequest {
+	g.currentPage = &currentPage
+	return g
+}
+
+func (g *GetInterestHistoryRequest) PageSize(pageSize int) *GetInterestHistoryRequest {
+	g.pageSize = &pageSize
+	return g
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (g *GetInterestHistoryRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (g *GetInterestHistoryRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check currency field -> json key currency
+	if g.currency != nil {
+		currency := *g.currency
+
+		// assign parameter of currency
+		params["currency"] = currency
+	} else {
+	}
+	// check isIsolated field -> json key isIsolated
+	if g.isIsolated != nil {
+		isIsolated := *g.isIsolated
+
+		// assign parameter of isIsolated
+		params["isIsolated"] = isIsolated
+	} else {
+	}
+	// check symbol field -> json key symbol
+	if g.symbol != nil {
+		symbol := *g.symbol
+
+		// assign parameter of symbol
+		params["symbol"] = symbol
+	} else {
+	}
+	// check startTime field -> json key startTime
+	if g.startTime != nil {
+		startTime := *g.startTime
+
+		// assign parameter of startTime
+		// convert time.Time to milliseconds time stamp
+		params["startTime"] = strconv.FormatInt(startTime.UnixNano()/int64(time.Millisecond), 10)
+	} else {
+	}
+	// check endTime field -> json key endTime
+	if g.endTime != nil {
+		endTime := *g.endTime
+
+		// assign parameter of endTime
+		// convert time.Time to milliseconds time stamp
+		params["endTime"] = strconv.FormatInt(endTime.UnixNano()/int64(time.Millisecond), 10)
+	} else {
+	}
+	// check currentPage field -> json key currentPage
+	if g.currentPage != nil {
+		currentPage := *g.currentPage
+
+		// assign parameter of currentPage
+		params["currentPage"] = currentPage
+	} else {
+	}
+	// check pageSize field -> json key pageSize
+	if g.pageSize != nil {
+		pageSize := *g.pageSize
+
+		// assign parameter of pageSize
+		params["pageSize"] = pageSize
+	} else {
+	}
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (g *GetInterestHistoryRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := g.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for _k, _v := range params {
+		if g.isVarSlice(_v) {
+			g.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (g *GetInterestHistoryRequest) GetParametersJSON() ([]byte, error) {
+	params, err := g.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (g *GetInterestHistoryRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (g *GetInterestHistoryRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
+	}
+
+	return url
+}
+
+func (g *GetInterestHistoryRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (g *GetInterestHistoryRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func (g *GetInterestHistoryRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := g.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
+	}
+
+	return slugs, nil
+}
+
+func (g *GetInterestHistoryRequest) Do(ctx context.Context) (*InterestHistoryPage, error) {
+
+	// empty params for GET operation
+	var params interface{}
+	query, err := g.GetParametersQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := "/api/v3/margin/interest"
+
+	req, err := g.client.NewAuthenticatedRequest(ctx, "GET", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := g.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+	var data InterestHistoryPage
+	if err := json.Unmarshal(apiResponse.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
 	"time"
@@ -47,13 +48,23 @@ func (r *ListOrdersRequest) EndAt(endAt time.Time) *ListOrdersRequest {
 	return r
 }
 
+func (r *ListOrdersRequest) CurrentPage(currentPage int) *ListOrdersRequest {
+	r.currentPage = &currentPage
+	return r
+}
+
+func (r *ListOrdersRequest) PageSize(pageSize int) *ListOrdersRequest {
+	r.pageSize = &pageSize
+	return r
+}
+
 // GetQueryParameters builds and checks the query parameters and returns url.Values
 func (r *ListOrdersRequest) GetQueryParameters() (url.Values, error) {
 	var params = map[string]interface{}{}
 
 	query := url.Values{}
-	for k, v := range params {
-		query.Add(k, fmt.Sprintf("%v", v))
+	for _k, _v := range params {
+		query.Add(_k, fmt.Sprintf("%v", _v))
 	}
 
 	return query, nil
@@ -112,6 +123,17 @@ func (r *ListOrdersRequest) GetParameters() (map[string]interface{}, error) {
 	if r.orderType != nil {
 		orderType := *r.orderType
 
+		// TEMPLATE check-valid-values
+		switch orderType {
+		case OrderTypeMarket, OrderTypeLimit, OrderTypeStopLimit:
+			params["type"] = orderType
+
+		default:
+			return nil, fmt.Errorf("type value %v is invalid", orderType)
+
+		}
+		// END TEMPLATE check-valid-values
+
 		// assign parameter of orderType
 		params["type"] = orderType
 	} else {
@@ -120,6 +142,17 @@ func (r *ListOrdersRequest) GetParameters() (map[string]interface{}, error) {
 	if r.tradeType != nil {
 		tradeType := *r.tradeType
 
+		// TEMPLATE check-valid-values
+		switch tradeType {
+		case TradeTypeSpot, TradeTypeMargin:
+			params["tradeType"] = tradeType
+
+		default:
+			return nil, fmt.Errorf("tradeType value %v is invalid", tradeType)
+
+		}
+		// END TEMPLATE check-valid-values
+
 		// assign parameter of tradeType
 		params["tradeType"] = tradeType
 	} else {
@@ -146,6 +179,22 @@ func (r *ListOrdersRequest) GetParameters() (map[string]interface{}, error) {
 		params["endAt"] = strconv.FormatInt(endAt.UnixNano()/int64(time.Millisecond), 10)
 	} else {
 	}
+	// check currentPage field -> json key currentPage
+	if r.currentPage != nil {
+		currentPage := *r.currentPage
+
+		// assign parameter of currentPage
+		params["currentPage"] = currentPage
+	} else {
+	}
+	// check pageSize field -> json key pageSize
+	if r.pageSize != nil {
+		pageSize := *r.pageSize
+
+		// assign parameter of pageSize
+		params["pageSize"] = pageSize
+	} else {
+	}
 
 	return params, nil
 }
@@ -159,8 +208,14 @@ func (r *ListOrdersRequest) GetParametersQuery() (url.Values, error) {
 		return query, err
 	}
 
-	for k, v := range params {
-		query.Add(k, fmt.Sprintf("%v", v))
+	for _k, _v := range params {
+		if r.isVarSlice(_v) {
+			r.iterateSlice(_v, func(it interface{}) {
+				query.Add(_k+"[]", fmt.Sprintf("%v", it))
+			})
+		} else {
+			query.Add(_k, fmt.Sprintf("%v", _v))
+		}
 	}
 
 	return query, nil
@@ -184,14 +239,31 @@ func (r *ListOrdersRequest) GetSlugParameters() (map[string]interface{}, error)
 }
 
 func (r *ListOrdersRequest) applySlugsToUrl(url string, slugs map[string]string) string {
-	for k, v := range slugs {
-		needleRE := regexp.MustCompile(":" + k + "\\b")
-		url = needleRE.ReplaceAllString(url, v)
+	for _k, _v := range slugs {
+		needleRE := regexp.MustCompile(":" + _k + "\\b")
+		url = needleRE.ReplaceAllString(url, _v)
 	}
 
 	return url
 }
 
+func (r *ListOrdersRequest) iterateSlice(slice interface{}, _f func(it interface{})) {
+	sliceValue := reflect.ValueOf(slice)
+	for _i := 0; _i < sliceValue.Len(); _i++ {
+		it := sliceValue.Index(_i).Interface()
+		_f(it)
+	}
+}
+
+func (r *ListOrdersRequest) isVarSlice(_v interface{}) bool {
+	rt := reflect.TypeOf(_v)
+	switch rt.Kind() {
+	case reflect.Slice:
+		return true
+	}
+	return false
+}
+
 func (r *ListOrdersRequest) GetSlugsMap() (map[string]string, error) {
 	slugs := map[string]string{}
 	params, err := r.GetSlugParameters()
@@ -199,8 +271,8 @@ func (r *ListOrdersRequest) GetSlugsMap() (map[string]string, error) {
 		return slugs, nil
 	}
 
-	for k, v := range params {
-		slugs[k] = fmt.Sprintf("%v", v)
+	for _k, _v := range params {
+		slugs[_k] = fmt.Sprintf("%v", _v)
 	}
 
 	return slugs, nil
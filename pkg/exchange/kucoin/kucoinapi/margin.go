@@ -0,0 +1,289 @@
+package kucoinapi
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Data
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Data
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/requestgen"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type MarginService struct {
+	client *RestClient
+}
+
+func (s *MarginService) NewGetMarginAccountRequest() *GetMarginAccountRequest {
+	return &GetMarginAccountRequest{client: s.client}
+}
+
+func (s *MarginService) NewGetIsolatedMarginAccountRequest(symbol string) *GetIsolatedMarginAccountRequest {
+	return &GetIsolatedMarginAccountRequest{client: s.client, symbol: symbol}
+}
+
+func (s *MarginService) NewBorrowRequest() *BorrowRequest {
+	return &BorrowRequest{client: s.client}
+}
+
+func (s *MarginService) NewRepayRequest() *RepayRequest {
+	return &RepayRequest{client: s.client}
+}
+
+func (s *MarginService) NewGetBorrowHistoryRequest() *GetBorrowHistoryRequest {
+	return &GetBorrowHistoryRequest{client: s.client}
+}
+
+func (s *MarginService) NewGetRepayHistoryRequest() *GetRepayHistoryRequest {
+	return &GetRepayHistoryRequest{client: s.client}
+}
+
+func (s *MarginService) NewGetInterestHistoryRequest() *GetInterestHistoryRequest {
+	return &GetInterestHistoryRequest{client: s.client}
+}
+
+// NewPlaceMarginOrderRequest creates the margin order request from the order parameters of the spot order request
+func (s *MarginService) NewPlaceMarginOrderRequest(req *PlaceOrderRequest, marginModel MarginModel) *PlaceMarginOrderRequest {
+	return &PlaceMarginOrderRequest{client: s.client, req: req, marginModel: marginModel}
+}
+
+type MarginAccountAsset struct {
+	Currency         string           `json:"currency"`
+	TotalBalance     fixedpoint.Value `json:"totalBalance"`
+	AvailableBalance fixedpoint.Value `json:"availableBalance"`
+	HoldBalance      fixedpoint.Value `json:"holdBalance"`
+	Liability        fixedpoint.Value `json:"liability"`
+	MaxBorrowSize    fixedpoint.Value `json:"maxBorrowSize"`
+}
+
+type MarginAccount struct {
+	DebtRatio fixedpoint.Value     `json:"debtRatio"`
+	Accounts  []MarginAccountAsset `json:"accounts"`
+}
+
+//go:generate GetRequest -url "/api/v1/margin/account" -type GetMarginAccountRequest -responseDataType .MarginAccount
+type GetMarginAccountRequest struct {
+	client requestgen.AuthenticatedAPIClient
+}
+
+type IsolatedMarginAsset struct {
+	Currency         string           `json:"currency"`
+	TotalBalance     fixedpoint.Value `json:"totalBalance"`
+	HoldBalance      fixedpoint.Value `json:"holdBalance"`
+	AvailableBalance fixedpoint.Value `json:"availableBalance"`
+	Liability        fixedpoint.Value `json:"liability"`
+	Interest         fixedpoint.Value `json:"interest"`
+	BorrowableAmount fixedpoint.Value `json:"borrowableAmount"`
+}
+
+type IsolatedMarginAccount struct {
+	Symbol     string              `json:"symbol"`
+	Status     string              `json:"status"`
+	DebtRatio  fixedpoint.Value    `json:"debtRatio"`
+	BaseAsset  IsolatedMarginAsset `json:"baseAsset"`
+	QuoteAsset IsolatedMarginAsset `json:"quoteAsset"`
+}
+
+//go:generate GetRequest -url "/api/v1/isolated/account/:symbol" -type GetIsolatedMarginAccountRequest -responseDataType .IsolatedMarginAccount
+type GetIsolatedMarginAccountRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	symbol string `param:"symbol,slug"`
+}
+
+type BorrowResponse struct {
+	OrderNo    string           `json:"orderNo"`
+	ActualSize fixedpoint.Value `json:"actualSize"`
+}
+
+//go:generate PostRequest -url "/api/v3/margin/borrow" -type BorrowRequest -responseDataType .BorrowResponse
+type BorrowRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency string `param:"currency,required"`
+
+	size string `param:"size,required"`
+
+	// timeInForce is IOC or FOK
+	timeInForce TimeInForceType `param:"timeInForce,required" default:"FOK"`
+
+	isIsolated *bool `param:"isIsolated"`
+
+	symbol *string `param:"symbol"`
+}
+
+type RepayResponse struct {
+	Timestamp  types.MillisecondTimestamp `json:"timestamp"`
+	OrderNo    string                     `json:"orderNo"`
+	ActualSize fixedpoint.Value           `json:"actualSize"`
+}
+
+//go:generate PostRequest -url "/api/v3/margin/repay" -type RepayRequest -responseDataType .RepayResponse
+type RepayRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency string `param:"currency,required"`
+
+	size string `param:"size,required"`
+
+	isIsolated *bool `param:"isIsolated"`
+
+	symbol *string `param:"symbol"`
+}
+
+type BorrowRecord struct {
+	OrderNo     string                     `json:"orderNo"`
+	Symbol      string                     `json:"symbol"`
+	Currency    string                     `json:"currency"`
+	Size        fixedpoint.Value           `json:"size"`
+	ActualSize  fixedpoint.Value           `json:"actualSize"`
+	Status      BorrowStatus               `json:"status"`
+	CreatedTime types.MillisecondTimestamp `json:"createdTime"`
+}
+
+type BorrowHistoryPage struct {
+	CurrentPage int            `json:"currentPage"`
+	PageSize    int            `json:"pageSize"`
+	TotalNumber int            `json:"totalNum"`
+	TotalPage   int            `json:"totalPage"`
+	Items       []BorrowRecord `json:"items"`
+}
+
+//go:generate GetRequest -url "/api/v3/margin/borrow" -type GetBorrowHistoryRequest -responseDataType .BorrowHistoryPage
+type GetBorrowHistoryRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency string `param:"currency,required"`
+
+	isIsolated *bool `param:"isIsolated"`
+
+	symbol *string `param:"symbol"`
+
+	startTime *time.Time `param:"startTime,milliseconds"`
+
+	endTime *time.Time `param:"endTime,milliseconds"`
+
+	currentPage *int `param:"currentPage"`
+
+	pageSize *int `param:"pageSize"`
+}
+
+type RepayRecord struct {
+	OrderNo     string                     `json:"orderNo"`
+	Symbol      string                     `json:"symbol"`
+	Currency    string                     `json:"currency"`
+	Size        fixedpoint.Value           `json:"size"`
+	Principal   fixedpoint.Value           `json:"principal"`
+	Interest    fixedpoint.Value           `json:"interest"`
+	Status      BorrowStatus               `json:"status"`
+	CreatedTime types.MillisecondTimestamp `json:"createdTime"`
+}
+
+type RepayHistoryPage struct {
+	CurrentPage int           `json:"currentPage"`
+	PageSize    int           `json:"pageSize"`
+	TotalNumber int           `json:"totalNum"`
+	TotalPage   int           `json:"totalPage"`
+	Items       []RepayRecord `json:"items"`
+}
+
+//go:generate GetRequest -url "/api/v3/margin/repay" -type GetRepayHistoryRequest -responseDataType .RepayHistoryPage
+type GetRepayHistoryRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency string `param:"currency,required"`
+
+	isIsolated *bool `param:"isIsolated"`
+
+	symbol *string `param:"symbol"`
+
+	startTime *time.Time `param:"startTime,milliseconds"`
+
+	endTime *time.Time `param:"endTime,milliseconds"`
+
+	currentPage *int `param:"currentPage"`
+
+	pageSize *int `param:"pageSize"`
+}
+
+type InterestRecord struct {
+	Currency       string                     `json:"currency"`
+	DayRatio       fixedpoint.Value           `json:"dayRatio"`
+	InterestAmount fixedpoint.Value           `json:"interestAmount"`
+	CreatedTime    types.MillisecondTimestamp `json:"createdTime"`
+}
+
+type InterestHistoryPage struct {
+	CurrentPage int              `json:"currentPage"`
+	PageSize    int              `json:"pageSize"`
+	TotalNumber int              `json:"totalNum"`
+	TotalPage   int              `json:"totalPage"`
+	Items       []InterestRecord `json:"items"`
+}
+
+//go:generate GetRequest -url "/api/v3/margin/interest" -type GetInterestHistoryRequest -responseDataType .InterestHistoryPage
+type GetInterestHistoryRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency *string `param:"currency"`
+
+	isIsolated *bool `param:"isIsolated"`
+
+	symbol *string `param:"symbol"`
+
+	startTime *time.Time `param:"startTime,milliseconds"`
+
+	endTime *time.Time `param:"endTime,milliseconds"`
+
+	currentPage *int `param:"currentPage"`
+
+	pageSize *int `param:"pageSize"`
+}
+
+// PlaceMarginOrderRequest places the order with the margin account,
+// the order parameters are the same as the spot order except the margin model.
+type PlaceMarginOrderRequest struct {
+	client *RestClient
+
+	req         *PlaceOrderRequest
+	marginModel MarginModel
+}
+
+func (r *PlaceMarginOrderRequest) Do(ctx context.Context) (*OrderResponse, error) {
+	params, err := r.req.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	params["marginModel"] = r.marginModel
+
+	req, err := r.client.NewAuthenticatedRequest(ctx, "POST", "/api/v1/margin/order", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string         `json:"code"`
+		Message string         `json:"msg"`
+		Data    *OrderResponse `json:"data"`
+	}
+
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if apiResponse.Data == nil {
+		return nil, errors.New("api error: [" + apiResponse.Code + "] " + apiResponse.Message)
+	}
+
+	return apiResponse.Data, nil
+}
@@ -0,0 +1,65 @@
+package kucoinapi
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+const (
+	rateLimitRemainingHeader = "gw-ratelimit-remaining"
+	rateLimitLimitHeader     = "gw-ratelimit-limit"
+)
+
+// Limiter tracks the resource pools of the KuCoin api, every endpoint consumes the weight of one pool,
+// and the remaining weight of the pool is returned in the response headers.
+var Limiter = ratelimit.Register(ratelimit.New("kucoin",
+	ratelimit.Rule{
+		Name:            "spot",
+		Limit:           3600,
+		Interval:        30 * time.Second,
+		PathPrefix:      "/api/",
+		DefaultWeight:   1,
+		RemainingHeader: rateLimitRemainingHeader,
+		LimitHeader:     rateLimitLimitHeader,
+	},
+	ratelimit.Rule{
+		Name:            "public",
+		Limit:           1800,
+		Interval:        30 * time.Second,
+		PathPrefix:      "/api/",
+		RemainingHeader: rateLimitRemainingHeader,
+		LimitHeader:     rateLimitLimitHeader,
+	},
+	ratelimit.Rule{
+		Name:            "management",
+		Limit:           1800,
+		Interval:        30 * time.Second,
+		PathPrefix:      "/api/",
+		RemainingHeader: rateLimitRemainingHeader,
+		LimitHeader:     rateLimitLimitHeader,
+	},
+))
+
+func init() {
+	Limiter.RegisterWeight("POST /api/v1/orders", ratelimit.Weight{"spot": 2})
+	Limiter.RegisterWeight("POST /api/v1/orders/multi", ratelimit.Weight{"spot": 3})
+	Limiter.RegisterWeight("POST /api/v1/margin/order", ratelimit.Weight{"spot": 5})
+	Limiter.RegisterWeight("DELETE /api/v1/orders", ratelimit.Weight{"spot": 10})
+	Limiter.RegisterWeight("GET /api/v1/orders", ratelimit.Weight{"spot": 2})
+	Limiter.RegisterWeight("GET /api/v1/fills", ratelimit.Weight{"spot": 10})
+	Limiter.RegisterWeight("GET /api/v3/market/orderbook/level2", ratelimit.Weight{"spot": 3})
+	Limiter.RegisterWeight("POST /api/v1/bullet-private", ratelimit.Weight{"spot": 10})
+
+	Limiter.RegisterWeight("GET /api/v1/accounts", ratelimit.Weight{"spot": 0, "management": 5})
+	Limiter.RegisterWeight("GET /api/v1/sub/user", ratelimit.Weight{"spot": 0, "management": 20})
+
+	Limiter.RegisterWeight("GET /api/v1/symbols", ratelimit.Weight{"spot": 0, "public": 4})
+	Limiter.RegisterWeight("GET /api/v1/market/allTickers", ratelimit.Weight{"spot": 0, "public": 15})
+	Limiter.RegisterWeight("GET /api/v1/market/stats", ratelimit.Weight{"spot": 0, "public": 15})
+	Limiter.RegisterWeight("GET /api/v1/market/candles", ratelimit.Weight{"spot": 0, "public": 3})
+	Limiter.RegisterWeight("GET /api/v1/market/orderbook/level1", ratelimit.Weight{"spot": 0, "public": 2})
+	Limiter.RegisterWeight("GET /api/v1/market/orderbook/level2_20", ratelimit.Weight{"spot": 0, "public": 2})
+	Limiter.RegisterWeight("GET /api/v1/market/orderbook/level2_100", ratelimit.Weight{"spot": 0, "public": 4})
+	Limiter.RegisterWeight("POST /api/v1/bullet-public", ratelimit.Weight{"spot": 0, "public": 10})
+}
@@ -65,6 +65,10 @@ type GetFillsRequest struct {
 	startAt *time.Time `param:"startAt,milliseconds"`
 
 	endAt *time.Time `param:"endAt,milliseconds"`
+
+	currentPage *int `param:"currentPage"`
+
+	pageSize *int `param:"pageSize"`
 }
 
 type FillListPage struct {
@@ -141,6 +145,10 @@ type ListOrdersRequest struct {
 	startAt *time.Time `param:"startAt,milliseconds"`
 
 	endAt *time.Time `param:"endAt,milliseconds"`
+
+	currentPage *int `param:"currentPage"`
+
+	pageSize *int `param:"pageSize"`
 }
 
 type Order struct {
@@ -169,6 +177,7 @@ type Order struct {
 	IsActive       bool                       `json:"isActive"`
 	CancelExist    bool                       `json:"cancelExist"`
 	CreatedAt      types.MillisecondTimestamp `json:"createdAt"`
+	TradeType      TradeType                  `json:"tradeType"`
 }
 
 type OrderListPage struct {
@@ -183,6 +192,28 @@ func (c *TradeService) NewListOrdersRequest() *ListOrdersRequest {
 	return &ListOrdersRequest{client: c.client}
 }
 
+func (c *TradeService) NewGetOrderRequest() *GetOrderRequest {
+	return &GetOrderRequest{client: c.client}
+}
+
+func (c *TradeService) NewGetOrderByClientOrderIDRequest() *GetOrderByClientOrderIDRequest {
+	return &GetOrderByClientOrderIDRequest{client: c.client}
+}
+
+//go:generate GetRequest -url "/api/v1/orders/:orderID" -type GetOrderRequest -responseDataType .Order
+type GetOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	orderID string `param:"orderID,slug"`
+}
+
+//go:generate GetRequest -url "/api/v1/order/client-order/:clientOrderID" -type GetOrderByClientOrderIDRequest -responseDataType .Order
+type GetOrderByClientOrderIDRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	clientOrderID string `param:"clientOrderID,slug"`
+}
+
 //go:generate PostRequest -url /api/v1/orders -type PlaceOrderRequest -responseDataType .OrderResponse
 type PlaceOrderRequest struct {
 	client requestgen.AuthenticatedAPIClient
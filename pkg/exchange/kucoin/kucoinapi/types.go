@@ -14,6 +14,24 @@ type TradeType string
 const (
 	TradeTypeSpot   TradeType = "TRADE"
 	TradeTypeMargin TradeType = "MARGIN"
+
+	TradeTypeCrossMarginTrade    TradeType = "MARGIN_TRADE"
+	TradeTypeIsolatedMarginTrade TradeType = "MARGIN_ISOLATED_TRADE"
+)
+
+type MarginModel string
+
+const (
+	MarginModelCross    MarginModel = "cross"
+	MarginModelIsolated MarginModel = "isolated"
+)
+
+type BorrowStatus string
+
+const (
+	BorrowStatusPending BorrowStatus = "PENDING"
+	BorrowStatusSuccess BorrowStatus = "SUCCESS"
+	BorrowStatusFailed  BorrowStatus = "FAILED"
 )
 
 type SideType string
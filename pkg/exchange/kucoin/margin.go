@@ -0,0 +1,282 @@
+package kucoin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// marginHistoryWindow is the max time range of one margin history query
+const marginHistoryWindow = 30 * 24 * time.Hour
+
+var marginHistoryLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
+
+var errLiquidationHistoryNotSupported = errors.New("kucoin does not provide the margin liquidation history api")
+
+func (e *Exchange) localTradeType() kucoinapi.TradeType {
+	if e.IsIsolatedMargin {
+		return kucoinapi.TradeTypeIsolatedMarginTrade
+	} else if e.IsMargin {
+		return kucoinapi.TradeTypeCrossMarginTrade
+	}
+
+	return kucoinapi.TradeTypeSpot
+}
+
+func (e *Exchange) localMarginModel() kucoinapi.MarginModel {
+	if e.IsIsolatedMargin {
+		return kucoinapi.MarginModelIsolated
+	}
+
+	return kucoinapi.MarginModelCross
+}
+
+func (e *Exchange) queryCrossMarginAccount(ctx context.Context) (*types.Account, error) {
+	marginAccount, err := e.client.MarginService.NewGetMarginAccountRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := types.NewAccount()
+	a.AccountType = types.AccountTypeMargin
+	a.MarginRatio = marginAccount.DebtRatio
+
+	// margin level is the ratio of the total asset to the total liability
+	if marginAccount.DebtRatio.Sign() > 0 {
+		a.MarginLevel = fixedpoint.One.Div(marginAccount.DebtRatio)
+	}
+
+	a.UpdateBalances(toGlobalMarginBalanceMap(marginAccount))
+	return a, nil
+}
+
+func (e *Exchange) queryIsolatedMarginAccount(ctx context.Context) (*types.Account, error) {
+	req := e.client.MarginService.NewGetIsolatedMarginAccountRequest(toLocalSymbol(e.IsolatedMarginSymbol))
+	marginAccount, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := types.NewAccount()
+	a.AccountType = types.AccountTypeIsolatedMargin
+	a.MarginRatio = marginAccount.DebtRatio
+	if marginAccount.DebtRatio.Sign() > 0 {
+		a.MarginLevel = fixedpoint.One.Div(marginAccount.DebtRatio)
+	}
+
+	a.UpdateBalances(toGlobalIsolatedMarginBalanceMap(marginAccount))
+	return a, nil
+}
+
+func (e *Exchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (amount fixedpoint.Value, err error) {
+	if e.IsIsolatedMargin {
+		req := e.client.MarginService.NewGetIsolatedMarginAccountRequest(toLocalSymbol(e.IsolatedMarginSymbol))
+		marginAccount, err := req.Do(ctx)
+		if err != nil {
+			return fixedpoint.Zero, err
+		}
+
+		for _, a := range []kucoinapi.IsolatedMarginAsset{marginAccount.BaseAsset, marginAccount.QuoteAsset} {
+			if a.Currency == asset {
+				return a.BorrowableAmount, nil
+			}
+		}
+
+		return fixedpoint.Zero, fmt.Errorf("asset %s is not found in the isolated margin account %s", asset, e.IsolatedMarginSymbol)
+	}
+
+	marginAccount, err := e.client.MarginService.NewGetMarginAccountRequest().Do(ctx)
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	for _, a := range marginAccount.Accounts {
+		if a.Currency == asset {
+			return a.MaxBorrowSize, nil
+		}
+	}
+
+	return fixedpoint.Zero, nil
+}
+
+func (e *Exchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	req := e.client.MarginService.NewBorrowRequest()
+	req.Currency(asset)
+	req.Size(amount.String())
+	req.TimeInForce(kucoinapi.TimeInForceFOK)
+	if e.IsIsolatedMargin {
+		req.IsIsolated(true)
+		req.Symbol(toLocalSymbol(e.IsolatedMarginSymbol))
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("margin borrowed %f %s, order no: %s", resp.ActualSize.Float64(), asset, resp.OrderNo)
+	return nil
+}
+
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	req := e.client.MarginService.NewRepayRequest()
+	req.Currency(asset)
+	req.Size(amount.String())
+	if e.IsIsolatedMargin {
+		req.IsIsolated(true)
+		req.Symbol(toLocalSymbol(e.IsolatedMarginSymbol))
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("margin repaid %f %s, order no: %s", resp.ActualSize.Float64(), asset, resp.OrderNo)
+	return nil
+}
+
+// marginHistoryTimeRange returns the time range of the margin history query,
+// the end time is truncated to the max window of the query.
+func marginHistoryTimeRange(startTime, endTime *time.Time) (time.Time, time.Time) {
+	until := time.Now()
+	if endTime != nil {
+		until = *endTime
+	}
+
+	since := until.Add(-marginHistoryWindow)
+	if startTime != nil {
+		since = *startTime
+		if until.Sub(since) > marginHistoryWindow {
+			until = since.Add(marginHistoryWindow)
+		}
+	}
+
+	return since, until
+}
+
+func (e *Exchange) QueryLoanHistory(ctx context.Context, asset string, startTime, endTime *time.Time) ([]types.MarginLoan, error) {
+	since, until := marginHistoryTimeRange(startTime, endTime)
+
+	var loans []types.MarginLoan
+	for page := 1; ; page++ {
+		req := e.client.MarginService.NewGetBorrowHistoryRequest()
+		req.Currency(asset)
+		req.StartTime(since)
+		req.EndTime(until)
+		req.CurrentPage(page)
+		req.PageSize(queryPageSize)
+		if e.IsIsolatedMargin {
+			req.IsIsolated(true)
+			req.Symbol(toLocalSymbol(e.IsolatedMarginSymbol))
+		}
+
+		if err := marginHistoryLimiter.Wait(ctx); err != nil {
+			return loans, err
+		}
+
+		records, err := req.Do(ctx)
+		if err != nil {
+			return loans, err
+		}
+
+		for _, record := range records.Items {
+			if record.Status != kucoinapi.BorrowStatusSuccess {
+				continue
+			}
+
+			loans = append(loans, toGlobalLoan(record))
+		}
+
+		if page >= records.TotalPage {
+			return loans, nil
+		}
+	}
+}
+
+func (e *Exchange) QueryRepayHistory(ctx context.Context, asset string, startTime, endTime *time.Time) ([]types.MarginRepay, error) {
+	since, until := marginHistoryTimeRange(startTime, endTime)
+
+	var repays []types.MarginRepay
+	for page := 1; ; page++ {
+		req := e.client.MarginService.NewGetRepayHistoryRequest()
+		req.Currency(asset)
+		req.StartTime(since)
+		req.EndTime(until)
+		req.CurrentPage(page)
+		req.PageSize(queryPageSize)
+		if e.IsIsolatedMargin {
+			req.IsIsolated(true)
+			req.Symbol(toLocalSymbol(e.IsolatedMarginSymbol))
+		}
+
+		if err := marginHistoryLimiter.Wait(ctx); err != nil {
+			return repays, err
+		}
+
+		records, err := req.Do(ctx)
+		if err != nil {
+			return repays, err
+		}
+
+		for _, record := range records.Items {
+			if record.Status != kucoinapi.BorrowStatusSuccess {
+				continue
+			}
+
+			repays = append(repays, toGlobalRepay(record))
+		}
+
+		if page >= records.TotalPage {
+			return repays, nil
+		}
+	}
+}
+
+func (e *Exchange) QueryInterestHistory(ctx context.Context, asset string, startTime, endTime *time.Time) ([]types.MarginInterest, error) {
+	since, until := marginHistoryTimeRange(startTime, endTime)
+
+	var interests []types.MarginInterest
+	for page := 1; ; page++ {
+		req := e.client.MarginService.NewGetInterestHistoryRequest()
+		req.Currency(asset)
+		req.StartTime(since)
+		req.EndTime(until)
+		req.CurrentPage(page)
+		req.PageSize(queryPageSize)
+		if e.IsIsolatedMargin {
+			req.IsIsolated(true)
+			req.Symbol(toLocalSymbol(e.IsolatedMarginSymbol))
+		}
+
+		if err := marginHistoryLimiter.Wait(ctx); err != nil {
+			return interests, err
+		}
+
+		records, err := req.Do(ctx)
+		if err != nil {
+			return interests, err
+		}
+
+		for _, record := range records.Items {
+			interests = append(interests, toGlobalInterest(record, e.IsolatedMarginSymbol))
+		}
+
+		if page >= records.TotalPage {
+			return interests, nil
+		}
+	}
+}
+
+// QueryLiquidationHistory is not supported since kucoin does not provide the liquidation history api,
+// the liquidated positions are closed by the system orders.
+func (e *Exchange) QueryLiquidationHistory(ctx context.Context, startTime, endTime *time.Time) ([]types.MarginLiquidation, error) {
+	return nil, errLiquidationHistoryNotSupported
+}
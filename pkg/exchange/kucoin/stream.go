@@ -2,6 +2,7 @@ package kucoin
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -101,7 +102,26 @@ func (s *Stream) handleOrderBookL2Event(e *WebSocketOrderBookL2Event) {
 
 func (s *Stream) handleTickerEvent(e *WebSocketTickerEvent) {}
 
+// accountRelationPrefix returns the prefix of the relation events of the account used by the exchange,
+// e.g. trade.hold, margin.setted and isolated_BTC-USDT.transfer
+func (s *Stream) accountRelationPrefix() string {
+	if s.exchange != nil {
+		if s.exchange.IsIsolatedMargin {
+			return "isolated_" + toLocalSymbol(s.exchange.IsolatedMarginSymbol) + "."
+		} else if s.exchange.IsMargin {
+			return "margin."
+		}
+	}
+
+	return "trade."
+}
+
 func (s *Stream) handleAccountBalanceEvent(e *WebSocketAccountBalanceEvent) {
+	// the balance events of all the accounts are pushed, we only update the balances of the account we are using
+	if !strings.HasPrefix(e.RelationEvent, s.accountRelationPrefix()) {
+		return
+	}
+
 	bm := types.BalanceMap{}
 	bm[e.Currency] = types.Balance{
 		Currency:  e.Currency,
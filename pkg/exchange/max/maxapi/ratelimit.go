@@ -0,0 +1,35 @@
+package max
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+// Limiter tracks the requests of the MAX api, MAX allows 1200 requests per minute for each ip,
+// and the order endpoints are limited to 100 requests per 10 seconds for each user.
+var Limiter = ratelimit.Register(ratelimit.New("max",
+	ratelimit.Rule{
+		Name:          "requests",
+		Limit:         1100,
+		Interval:      time.Minute,
+		PathPrefix:    "/api/",
+		DefaultWeight: 1,
+	},
+	ratelimit.Rule{
+		Name:       "orders",
+		Limit:      90,
+		Interval:   10 * time.Second,
+		PathPrefix: "/api/",
+	},
+))
+
+func init() {
+	// wallet types: spot and m (margin)
+	for _, walletType := range []string{"spot", "m"} {
+		Limiter.RegisterWeight("POST /api/v3/wallet/"+walletType+"/order", ratelimit.Weight{"orders": 1})
+		Limiter.RegisterWeight("DELETE /api/v3/wallet/"+walletType+"/orders", ratelimit.Weight{"orders": 1})
+	}
+
+	Limiter.RegisterWeight("DELETE /api/v3/order", ratelimit.Weight{"orders": 1})
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 	"github.com/c9s/bbgo/pkg/util"
 	"github.com/c9s/bbgo/pkg/version"
 )
@@ -87,7 +88,7 @@ var httpTransport = &http.Transport{
 
 var defaultHttpClient = &http.Client{
 	Timeout:   defaultHTTPTimeout,
-	Transport: httpTransport,
+	Transport: ratelimit.NewTransport(Limiter, httpTransport),
 }
 
 type RestClient struct {
@@ -0,0 +1,108 @@
+package mockexchange
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// OrderConfig is the external order placed when the server starts
+type OrderConfig struct {
+	Side     types.SideType   `json:"side" yaml:"side"`
+	Price    fixedpoint.Value `json:"price" yaml:"price"`
+	Quantity fixedpoint.Value `json:"quantity" yaml:"quantity"`
+}
+
+type MarketConfig struct {
+	Symbol          string           `json:"symbol" yaml:"symbol"`
+	BaseCurrency    string           `json:"baseCurrency" yaml:"baseCurrency"`
+	QuoteCurrency   string           `json:"quoteCurrency" yaml:"quoteCurrency"`
+	PricePrecision  int              `json:"pricePrecision" yaml:"pricePrecision"`
+	VolumePrecision int              `json:"volumePrecision" yaml:"volumePrecision"`
+	MinQuantity     fixedpoint.Value `json:"minQuantity" yaml:"minQuantity"`
+	StepSize        fixedpoint.Value `json:"stepSize" yaml:"stepSize"`
+	TickSize        fixedpoint.Value `json:"tickSize" yaml:"tickSize"`
+	MinNotional     fixedpoint.Value `json:"minNotional" yaml:"minNotional"`
+
+	// Orders are the external orders of the initial order book
+	Orders []OrderConfig `json:"orders,omitempty" yaml:"orders,omitempty"`
+}
+
+func (c MarketConfig) Market() types.Market {
+	return types.Market{
+		Symbol:          c.Symbol,
+		LocalSymbol:     c.Symbol,
+		BaseCurrency:    c.BaseCurrency,
+		QuoteCurrency:   c.QuoteCurrency,
+		PricePrecision:  c.PricePrecision,
+		VolumePrecision: c.VolumePrecision,
+		MinQuantity:     c.MinQuantity,
+		MaxQuantity:     fixedpoint.NewFromInt(9000000),
+		StepSize:        c.StepSize,
+		MinPrice:        c.TickSize,
+		MaxPrice:        fixedpoint.NewFromInt(10000000),
+		TickSize:        c.TickSize,
+		MinNotional:     c.MinNotional,
+		MinAmount:       c.MinNotional,
+	}
+}
+
+// Config is the config of the mock exchange server
+type Config struct {
+	Markets      []MarketConfig              `json:"markets" yaml:"markets"`
+	Balances     map[string]fixedpoint.Value `json:"balances" yaml:"balances"`
+	MakerFeeRate fixedpoint.Value            `json:"makerFeeRate" yaml:"makerFeeRate"`
+	TakerFeeRate fixedpoint.Value            `json:"takerFeeRate" yaml:"takerFeeRate"`
+}
+
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// NewEngineFromConfig creates the engine with the markets and the balances of the config,
+// and places the external orders of the initial order books
+func NewEngineFromConfig(config *Config) (*Engine, error) {
+	markets := types.MarketMap{}
+	for _, c := range config.Markets {
+		markets[c.Symbol] = c.Market()
+	}
+
+	account := types.NewAccount()
+	account.MakerFeeRate = config.MakerFeeRate
+	account.TakerFeeRate = config.TakerFeeRate
+	for currency, amount := range config.Balances {
+		account.AddBalance(currency, amount)
+	}
+
+	engine := NewEngine(markets, account)
+	for _, c := range config.Markets {
+		for _, o := range c.Orders {
+			_, err := engine.PlaceExternalOrder(types.SubmitOrder{
+				Symbol:   c.Symbol,
+				Side:     o.Side,
+				Type:     types.OrderTypeLimit,
+				Price:    o.Price,
+				Quantity: o.Quantity,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("can not place the initial %s order %+v: %w", c.Symbol, o, err)
+			}
+		}
+	}
+
+	return engine, nil
+}
@@ -0,0 +1,231 @@
+package mockexchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adshao/go-binance/v2"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func toBinanceSymbol(market types.Market) binance.Symbol {
+	return binance.Symbol{
+		Symbol:                 market.Symbol,
+		Status:                 "TRADING",
+		BaseAsset:              market.BaseCurrency,
+		BaseAssetPrecision:     market.VolumePrecision,
+		QuoteAsset:             market.QuoteCurrency,
+		QuotePrecision:         market.PricePrecision,
+		QuoteAssetPrecision:    market.PricePrecision,
+		OrderTypes:             []string{"LIMIT", "LIMIT_MAKER", "MARKET"},
+		IsSpotTradingAllowed:   true,
+		IsMarginTradingAllowed: false,
+		Permissions:            []string{"SPOT"},
+		Filters: []map[string]interface{}{
+			{
+				"filterType": string(binance.SymbolFilterTypePriceFilter),
+				"minPrice":   market.MinPrice.String(),
+				"maxPrice":   market.MaxPrice.String(),
+				"tickSize":   market.TickSize.String(),
+			},
+			{
+				"filterType": string(binance.SymbolFilterTypeLotSize),
+				"minQty":     market.MinQuantity.String(),
+				"maxQty":     market.MaxQuantity.String(),
+				"stepSize":   market.StepSize.String(),
+			},
+			{
+				"filterType":  string(binance.SymbolFilterTypeMinNotional),
+				"minNotional": market.MinNotional.String(),
+			},
+		},
+	}
+}
+
+func toBinanceOrder(o Order) *binance.Order {
+	return &binance.Order{
+		Symbol:                   o.Symbol,
+		OrderID:                  int64(o.OrderID),
+		OrderListId:              -1,
+		ClientOrderID:            o.ClientOrderID,
+		Price:                    o.Price.String(),
+		OrigQuantity:             o.Quantity.String(),
+		ExecutedQuantity:         o.ExecutedQuantity.String(),
+		CummulativeQuoteQuantity: o.ExecutedQuoteQuantity.String(),
+		Status:                   binance.OrderStatusType(o.Status),
+		TimeInForce:              binance.TimeInForceType(o.TimeInForce),
+		Type:                     binance.OrderType(o.Type),
+		Side:                     binance.SideType(o.Side),
+		StopPrice:                o.StopPrice.String(),
+		IcebergQuantity:          "0",
+		Time:                     o.CreationTime.Time().UnixMilli(),
+		UpdateTime:               o.UpdateTime.Time().UnixMilli(),
+		IsWorking:                o.IsWorking,
+		OrigQuoteOrderQuantity:   "0",
+	}
+}
+
+func toBinanceTrade(t types.Trade) *binance.TradeV3 {
+	return &binance.TradeV3{
+		ID:              int64(t.ID),
+		Symbol:          t.Symbol,
+		OrderID:         int64(t.OrderID),
+		OrderListId:     -1,
+		Price:           t.Price.String(),
+		Quantity:        t.Quantity.String(),
+		QuoteQuantity:   t.QuoteQuantity.String(),
+		Commission:      t.Fee.String(),
+		CommissionAsset: t.FeeCurrency,
+		Time:            t.Time.Time().UnixMilli(),
+		IsBuyer:         t.IsBuyer,
+		IsMaker:         t.IsMaker,
+		IsBestMatch:     true,
+	}
+}
+
+// toBinanceKLine converts the kline to the array of the kline REST API response
+func toBinanceKLine(k types.KLine) []interface{} {
+	return []interface{}{
+		k.StartTime.Time().UnixMilli(),
+		k.Open.String(),
+		k.High.String(),
+		k.Low.String(),
+		k.Close.String(),
+		k.Volume.String(),
+		k.EndTime.Time().UnixMilli(),
+		k.QuoteVolume.String(),
+		k.NumberOfTrades,
+		k.TakerBuyBaseAssetVolume.String(),
+		k.TakerBuyQuoteAssetVolume.String(),
+		"0",
+	}
+}
+
+// toBinanceKLineEvent converts the kline to the kline websocket event
+func toBinanceKLineEvent(k types.KLine, eventTime int64) map[string]interface{} {
+	return map[string]interface{}{
+		"e": "kline",
+		"E": eventTime,
+		"s": k.Symbol,
+		"k": map[string]interface{}{
+			"t": k.StartTime.Time().UnixMilli(),
+			"T": k.EndTime.Time().UnixMilli(),
+			"s": k.Symbol,
+			"i": k.Interval.String(),
+			"L": k.LastTradeID,
+			"o": k.Open.String(),
+			"c": k.Close.String(),
+			"h": k.High.String(),
+			"l": k.Low.String(),
+			"v": k.Volume.String(),
+			"n": k.NumberOfTrades,
+			"x": k.Closed,
+			"q": k.QuoteVolume.String(),
+			"V": k.TakerBuyBaseAssetVolume.String(),
+			"Q": k.TakerBuyQuoteAssetVolume.String(),
+			"B": "0",
+		},
+	}
+}
+
+func toBinancePriceLevels(pvs types.PriceVolumeSlice) [][]string {
+	levels := make([][]string, 0, len(pvs))
+	for _, pv := range pvs {
+		levels = append(levels, []string{pv.Price.String(), pv.Volume.String()})
+	}
+	return levels
+}
+
+// toBinanceExecutionReport converts the execution report to the executionReport event of the user data stream
+func toBinanceExecutionReport(report ExecutionReport, eventTime int64) map[string]interface{} {
+	o := report.Order
+	event := map[string]interface{}{
+		"e": "executionReport",
+		"E": eventTime,
+		"s": o.Symbol,
+		"c": o.ClientOrderID,
+		"S": string(o.Side),
+		"o": string(o.Type),
+		"f": string(o.TimeInForce),
+		"q": o.Quantity.String(),
+		"p": o.Price.String(),
+		"P": o.StopPrice.String(),
+		"F": "0",
+		"g": -1,
+		"C": "",
+		"x": report.ExecutionType,
+		"X": string(o.Status),
+		"r": "NONE",
+		"i": o.OrderID,
+		"l": "0",
+		"z": o.ExecutedQuantity.String(),
+		"L": "0",
+		"n": "0",
+		"N": nil,
+		"T": o.UpdateTime.Time().UnixMilli(),
+		"t": -1,
+		"I": 0,
+		"w": o.IsWorking,
+		"m": false,
+		"M": false,
+		"O": o.CreationTime.Time().UnixMilli(),
+		"Z": o.ExecutedQuoteQuantity.String(),
+		"Y": "0",
+		"Q": "0",
+	}
+
+	if t := report.Trade; t != nil {
+		event["l"] = t.Quantity.String()
+		event["L"] = t.Price.String()
+		event["n"] = t.Fee.String()
+		event["N"] = t.FeeCurrency
+		event["T"] = t.Time.Time().UnixMilli()
+		event["t"] = t.ID
+		event["m"] = t.IsMaker
+		event["M"] = true
+		event["Y"] = t.QuoteQuantity.String()
+	}
+
+	return event
+}
+
+func toBinanceBalances(balances types.BalanceMap) []map[string]string {
+	var list []map[string]string
+	for _, b := range balances {
+		list = append(list, map[string]string{
+			"a": b.Currency,
+			"f": b.Available.String(),
+			"l": b.Locked.String(),
+		})
+	}
+	return list
+}
+
+func toGlobalSubmitOrder(symbol, side, orderType, timeInForce, quantity, price, clientOrderID string) (types.SubmitOrder, error) {
+	so := types.SubmitOrder{
+		ClientOrderID: clientOrderID,
+		Symbol:        strings.ToUpper(symbol),
+		Side:          types.SideType(side),
+		Type:          types.OrderType(orderType),
+		TimeInForce:   types.TimeInForce(timeInForce),
+	}
+
+	if quantity == "" {
+		return so, fmt.Errorf("mandatory parameter 'quantity' was not sent, was empty/null, or malformed")
+	}
+
+	var err error
+	if so.Quantity, err = fixedpoint.NewFromString(quantity); err != nil {
+		return so, err
+	}
+
+	if price != "" {
+		if so.Price, err = fixedpoint.NewFromString(price); err != nil {
+			return so, err
+		}
+	}
+
+	return so, nil
+}
@@ -0,0 +1,736 @@
+package mockexchange
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// OrderStatusExpired is the status of the IOC, FOK and market orders that are not fully filled,
+// binance reports it as EXPIRED, which is not one of the global order status
+const OrderStatusExpired = types.OrderStatus("EXPIRED")
+
+// execution types of the execution report
+const (
+	ExecutionTypeNew      = "NEW"
+	ExecutionTypeTrade    = "TRADE"
+	ExecutionTypeCanceled = "CANCELED"
+	ExecutionTypeRejected = "REJECTED"
+	ExecutionTypeExpired  = "EXPIRED"
+)
+
+// the binance error codes used by the engine
+var (
+	ErrInvalidSymbol       = &common.APIError{Code: -1121, Message: "Invalid symbol."}
+	ErrInvalidOrderType    = &common.APIError{Code: -1116, Message: "Invalid orderType."}
+	ErrUnknownOrder        = &common.APIError{Code: -2011, Message: "Unknown order sent."}
+	ErrOrderNotExist       = &common.APIError{Code: -2013, Message: "Order does not exist."}
+	ErrInsufficientBalance = &common.APIError{Code: -2010, Message: "Account has insufficient balance for requested action."}
+	ErrImmediateMatch      = &common.APIError{Code: -2010, Message: "Order would immediately match and take."}
+	ErrOrderRejected       = &common.APIError{Code: -2010, Message: "Order rejected by the mock exchange."}
+)
+
+func newFilterError(filter string) error {
+	return &common.APIError{Code: -1013, Message: "Filter failure: " + filter}
+}
+
+// Order is the order in the matching engine
+type Order struct {
+	types.Order
+
+	// External is true when the order is placed by the other participants, the external orders do not use the account balances
+	External bool
+
+	// ExecutedQuoteQuantity is the cumulative quote quantity of the executed trades
+	ExecutedQuoteQuantity fixedpoint.Value
+
+	// locked is the remaining balance locked by this order
+	locked fixedpoint.Value
+}
+
+func (o *Order) remaining() fixedpoint.Value {
+	return o.Quantity.Sub(o.ExecutedQuantity)
+}
+
+// ExecutionReport is the update of the user order, Trade is only set when the execution type is TRADE
+type ExecutionReport struct {
+	ExecutionType string
+	Order         Order
+	Trade         *types.Trade
+}
+
+// BookUpdate is the changed price levels of the order book, the volume of the removed price level is zero
+type BookUpdate struct {
+	Symbol        string
+	FirstUpdateID int64
+	FinalUpdateID int64
+	Bids          types.PriceVolumeSlice
+	Asks          types.PriceVolumeSlice
+
+	// BestBid and BestAsk are the best price levels after the update, they are zero when the side is empty
+	BestBid types.PriceVolume
+	BestAsk types.PriceVolume
+}
+
+// orderBook keeps the resting orders in the price-time priority
+type orderBook struct {
+	bids []*Order // price descending
+	asks []*Order // price ascending
+
+	updateID int64
+}
+
+func (b *orderBook) side(side types.SideType) []*Order {
+	if side == types.SideTypeBuy {
+		return b.bids
+	}
+	return b.asks
+}
+
+func (b *orderBook) setSide(side types.SideType, orders []*Order) {
+	if side == types.SideTypeBuy {
+		b.bids = orders
+	} else {
+		b.asks = orders
+	}
+}
+
+// insert inserts the order after the orders of the same price level, so that the earlier orders are filled first
+func (b *orderBook) insert(o *Order) {
+	orders := b.side(o.Side)
+	i := sort.Search(len(orders), func(i int) bool {
+		c := orders[i].Price.Compare(o.Price)
+		if o.Side == types.SideTypeBuy {
+			return c < 0
+		}
+		return c > 0
+	})
+
+	orders = append(orders, nil)
+	copy(orders[i+1:], orders[i:])
+	orders[i] = o
+	b.setSide(o.Side, orders)
+}
+
+func (b *orderBook) remove(o *Order) bool {
+	orders := b.side(o.Side)
+	for i, other := range orders {
+		if other == o {
+			b.setSide(o.Side, append(orders[:i], orders[i+1:]...))
+			return true
+		}
+	}
+	return false
+}
+
+// volume returns the total volume of the price level
+func (b *orderBook) volume(side types.SideType, price fixedpoint.Value) fixedpoint.Value {
+	volume := fixedpoint.Zero
+	for _, o := range b.side(side) {
+		if o.Price.Compare(price) == 0 {
+			volume = volume.Add(o.remaining())
+		}
+	}
+	return volume
+}
+
+// levels returns the aggregated price levels of the side, limit <= 0 means all the levels
+func (b *orderBook) levels(side types.SideType, limit int) (pvs types.PriceVolumeSlice) {
+	for _, o := range b.side(side) {
+		n := len(pvs)
+		if n > 0 && pvs[n-1].Price.Compare(o.Price) == 0 {
+			pvs[n-1].Volume = pvs[n-1].Volume.Add(o.remaining())
+			continue
+		}
+
+		if limit > 0 && n >= limit {
+			break
+		}
+
+		pvs = append(pvs, types.PriceVolume{Price: o.Price, Volume: o.remaining()})
+	}
+	return pvs
+}
+
+// Engine is the order book matching engine of the mock exchange.
+//
+// The orders are matched in the price-time priority, the taker order is filled at the prices of the maker orders.
+// The user orders lock and settle the balances of Account, while the external orders placed by PlaceExternalOrder
+// simulate the other participants and provide the liquidity.
+//
+// The callbacks are called with the engine lock held, they must not call the engine methods.
+//
+//go:generate callbackgen -type Engine
+type Engine struct {
+	Markets types.MarketMap
+	Account *types.Account
+
+	mu sync.Mutex
+
+	books        map[string]*orderBook
+	orders       map[uint64]*Order
+	trades       map[string][]types.Trade
+	marketTrades map[string][]types.Trade
+
+	lastOrderID, lastTradeID uint64
+
+	// rejects are the errors returned to the next submitted orders
+	rejects []error
+
+	now func() time.Time
+
+	executionReportCallbacks []func(report ExecutionReport)
+	balanceUpdateCallbacks   []func(balances types.BalanceMap)
+	marketTradeCallbacks     []func(trade types.Trade)
+	bookUpdateCallbacks      []func(update BookUpdate)
+}
+
+func NewEngine(markets types.MarketMap, account *types.Account) *Engine {
+	return &Engine{
+		Markets:      markets,
+		Account:      account,
+		books:        make(map[string]*orderBook),
+		orders:       make(map[uint64]*Order),
+		trades:       make(map[string][]types.Trade),
+		marketTrades: make(map[string][]types.Trade),
+		now:          time.Now,
+	}
+}
+
+func (e *Engine) book(symbol string) *orderBook {
+	book, ok := e.books[symbol]
+	if !ok {
+		book = &orderBook{}
+		e.books[symbol] = book
+	}
+	return book
+}
+
+// RejectOrders rejects the next n submitted user orders with the given error, ErrOrderRejected is used when err is nil
+func (e *Engine) RejectOrders(n int, err error) {
+	if err == nil {
+		err = ErrOrderRejected
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		e.rejects = append(e.rejects, err)
+	}
+}
+
+// PlaceOrder places the user order, the order is matched with the resting orders immediately
+func (e *Engine) PlaceOrder(o types.SubmitOrder) (*Order, error) {
+	return e.placeOrder(o, false)
+}
+
+// PlaceExternalOrder places the order of the other participants,
+// it provides the liquidity for the user orders, or fills the resting user orders (fully or partially) when it crosses the book
+func (e *Engine) PlaceExternalOrder(o types.SubmitOrder) (*Order, error) {
+	return e.placeOrder(o, true)
+}
+
+func (e *Engine) placeOrder(so types.SubmitOrder, external bool) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !external && len(e.rejects) > 0 {
+		err := e.rejects[0]
+		e.rejects = e.rejects[1:]
+		return nil, err
+	}
+
+	market, ok := e.Markets[so.Symbol]
+	if !ok {
+		return nil, ErrInvalidSymbol
+	}
+
+	if err := validateOrder(market, so); err != nil {
+		return nil, err
+	}
+
+	if so.Type == types.OrderTypeLimit && so.TimeInForce == "" {
+		so.TimeInForce = types.TimeInForceGTC
+	}
+
+	book := e.book(so.Symbol)
+	if so.Type == types.OrderTypeLimitMaker && e.crossed(book, so.Side, so.Price, false) {
+		return nil, ErrImmediateMatch
+	}
+
+	now := e.now()
+	e.lastOrderID++
+	order := &Order{
+		Order: types.Order{
+			SubmitOrder:  so,
+			Exchange:     types.ExchangeBinance,
+			OrderID:      e.lastOrderID,
+			Status:       types.OrderStatusNew,
+			IsWorking:    true,
+			CreationTime: types.Time(now),
+			UpdateTime:   types.Time(now),
+		},
+		External: external,
+	}
+	order.Market = market
+
+	if !external {
+		if err := e.lock(order, book); err != nil {
+			return nil, err
+		}
+
+		e.orders[order.OrderID] = order
+		e.EmitExecutionReport(ExecutionReport{ExecutionType: ExecutionTypeNew, Order: *order})
+	}
+
+	// fill or kill orders are expired without any fill when the book is not deep enough
+	if so.TimeInForce == types.TimeInForceFOK && e.fillableQuantity(book, order).Compare(order.Quantity) < 0 {
+		e.expire(order)
+		return order, nil
+	}
+
+	changes := newLevelChanges()
+	userFilled := e.match(book, order, changes)
+
+	if order.remaining().Sign() > 0 {
+		if so.Type == types.OrderTypeMarket || so.TimeInForce == types.TimeInForceIOC || so.TimeInForce == types.TimeInForceFOK {
+			e.expire(order)
+		} else {
+			book.insert(order)
+			changes.add(order.Side, order.Price)
+		}
+	}
+
+	e.emitBookUpdate(book, so.Symbol, changes)
+	if !external || userFilled {
+		e.EmitBalanceUpdate(e.Account.Balances())
+	}
+
+	return order, nil
+}
+
+func validateOrder(market types.Market, so types.SubmitOrder) error {
+	switch so.Type {
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeMarket:
+	default:
+		return ErrInvalidOrderType
+	}
+
+	if so.Side != types.SideTypeBuy && so.Side != types.SideTypeSell {
+		return &common.APIError{Code: -1100, Message: "Illegal characters found in parameter 'side'."}
+	}
+
+	if so.Quantity.Sign() <= 0 || (market.MinQuantity.Sign() > 0 && so.Quantity.Compare(market.MinQuantity) < 0) {
+		return newFilterError("LOT_SIZE")
+	}
+
+	if so.Type == types.OrderTypeMarket {
+		return nil
+	}
+
+	if so.Price.Sign() <= 0 || (market.MinPrice.Sign() > 0 && so.Price.Compare(market.MinPrice) < 0) {
+		return newFilterError("PRICE_FILTER")
+	}
+
+	if market.MinNotional.Sign() > 0 && so.Price.Mul(so.Quantity).Compare(market.MinNotional) < 0 {
+		return newFilterError("MIN_NOTIONAL")
+	}
+
+	return nil
+}
+
+// crosses checks if the taker price crosses the maker price, market orders cross any price
+func crosses(side types.SideType, takerPrice, makerPrice fixedpoint.Value, isMarket bool) bool {
+	if isMarket {
+		return true
+	}
+
+	if side == types.SideTypeBuy {
+		return makerPrice.Compare(takerPrice) <= 0
+	}
+	return makerPrice.Compare(takerPrice) >= 0
+}
+
+// crossed checks if the price crosses the best price of the opposite side
+func (e *Engine) crossed(book *orderBook, side types.SideType, price fixedpoint.Value, isMarket bool) bool {
+	opposite := book.side(side.Reverse())
+	return len(opposite) > 0 && crosses(side, price, opposite[0].Price, isMarket)
+}
+
+// fillableQuantity returns the quantity that can be filled by the opposite side within the order price
+func (e *Engine) fillableQuantity(book *orderBook, o *Order) fixedpoint.Value {
+	quantity := fixedpoint.Zero
+	for _, maker := range book.side(o.Side.Reverse()) {
+		if !crosses(o.Side, o.Price, maker.Price, o.Type == types.OrderTypeMarket) {
+			break
+		}
+		quantity = quantity.Add(maker.remaining())
+	}
+	return quantity
+}
+
+// lock locks the balance of the user order, the market buy order locks the estimated cost of walking through the book
+func (e *Engine) lock(o *Order, book *orderBook) error {
+	currency := o.Market.BaseCurrency
+	amount := o.Quantity
+
+	if o.Side == types.SideTypeBuy {
+		currency = o.Market.QuoteCurrency
+		if o.Type == types.OrderTypeMarket {
+			amount = fixedpoint.Zero
+			remaining := o.Quantity
+			for _, maker := range book.asks {
+				if remaining.Sign() <= 0 {
+					break
+				}
+				quantity := fixedpoint.Min(remaining, maker.remaining())
+				amount = amount.Add(quantity.Mul(maker.Price))
+				remaining = remaining.Sub(quantity)
+			}
+		} else {
+			amount = o.Quantity.Mul(o.Price)
+		}
+	}
+
+	if err := e.Account.LockBalance(currency, amount); err != nil {
+		return ErrInsufficientBalance
+	}
+
+	o.locked = amount
+	return nil
+}
+
+func (e *Engine) unlockRemaining(o *Order) {
+	if o.External || o.locked.Sign() <= 0 {
+		return
+	}
+
+	currency := o.Market.BaseCurrency
+	if o.Side == types.SideTypeBuy {
+		currency = o.Market.QuoteCurrency
+	}
+
+	if err := e.Account.UnlockBalance(currency, o.locked); err != nil {
+		log.WithError(err).Errorf("can not unlock the balance of order %d", o.OrderID)
+	}
+	o.locked = fixedpoint.Zero
+}
+
+func (e *Engine) expire(o *Order) {
+	o.Status = OrderStatusExpired
+	o.IsWorking = false
+	o.UpdateTime = types.Time(e.now())
+	e.unlockRemaining(o)
+
+	if !o.External {
+		e.EmitExecutionReport(ExecutionReport{ExecutionType: ExecutionTypeExpired, Order: *o})
+	}
+}
+
+// match fills the taker order with the resting orders, it returns true when any user order is filled
+func (e *Engine) match(book *orderBook, taker *Order, changes *levelChanges) (userFilled bool) {
+	for taker.remaining().Sign() > 0 && e.crossed(book, taker.Side, taker.Price, taker.Type == types.OrderTypeMarket) {
+		maker := book.side(taker.Side.Reverse())[0]
+		price := maker.Price
+		quantity := fixedpoint.Min(taker.remaining(), maker.remaining())
+
+		// market buy orders can not spend more than the locked balance
+		if !taker.External && taker.Type == types.OrderTypeMarket && taker.Side == types.SideTypeBuy {
+			affordable := taker.Market.TruncateQuantity(taker.locked.Div(price))
+			quantity = fixedpoint.Min(quantity, affordable)
+			if quantity.Sign() <= 0 {
+				return userFilled
+			}
+		}
+
+		e.lastTradeID++
+		tradeID := e.lastTradeID
+		now := e.now()
+
+		marketTrade := types.Trade{
+			ID:            tradeID,
+			Exchange:      types.ExchangeBinance,
+			Symbol:        taker.Symbol,
+			Side:          taker.Side,
+			Price:         price,
+			Quantity:      quantity,
+			QuoteQuantity: price.Mul(quantity),
+			IsBuyer:       taker.Side == types.SideTypeBuy,
+			IsMaker:       false,
+			Time:          types.Time(now),
+		}
+		e.marketTrades[taker.Symbol] = append(e.marketTrades[taker.Symbol], marketTrade)
+
+		e.fill(maker, tradeID, price, quantity, true, now)
+		e.fill(taker, tradeID, price, quantity, false, now)
+
+		if maker.remaining().Sign() <= 0 {
+			book.remove(maker)
+		}
+		changes.add(maker.Side, price)
+		userFilled = userFilled || !maker.External || !taker.External
+
+		e.EmitMarketTrade(marketTrade)
+	}
+
+	return userFilled
+}
+
+// fill updates the order with the executed quantity and settles the balances of the user order
+func (e *Engine) fill(o *Order, tradeID uint64, price, quantity fixedpoint.Value, isMaker bool, now time.Time) {
+	quoteQuantity := price.Mul(quantity)
+	o.ExecutedQuantity = o.ExecutedQuantity.Add(quantity)
+	o.ExecutedQuoteQuantity = o.ExecutedQuoteQuantity.Add(quoteQuantity)
+	o.UpdateTime = types.Time(now)
+	if o.remaining().Sign() <= 0 {
+		o.Status = types.OrderStatusFilled
+		o.IsWorking = false
+	} else {
+		o.Status = types.OrderStatusPartiallyFilled
+	}
+
+	if o.External {
+		return
+	}
+
+	feeRate := e.Account.TakerFeeRate
+	if isMaker {
+		feeRate = e.Account.MakerFeeRate
+	}
+
+	trade := types.Trade{
+		ID:            tradeID,
+		OrderID:       o.OrderID,
+		Exchange:      types.ExchangeBinance,
+		Symbol:        o.Symbol,
+		Side:          o.Side,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		IsBuyer:       o.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          types.Time(now),
+	}
+
+	// the fee is deducted from the received asset
+	var err error
+	switch o.Side {
+	case types.SideTypeBuy:
+		trade.Fee = quantity.Mul(feeRate)
+		trade.FeeCurrency = o.Market.BaseCurrency
+		err = e.Account.UseLockedBalance(o.Market.QuoteCurrency, quoteQuantity)
+		o.locked = o.locked.Sub(quoteQuantity)
+		e.Account.AddBalance(o.Market.BaseCurrency, quantity.Sub(trade.Fee))
+
+	case types.SideTypeSell:
+		trade.Fee = quoteQuantity.Mul(feeRate)
+		trade.FeeCurrency = o.Market.QuoteCurrency
+		err = e.Account.UseLockedBalance(o.Market.BaseCurrency, quantity)
+		o.locked = o.locked.Sub(quantity)
+		e.Account.AddBalance(o.Market.QuoteCurrency, quoteQuantity.Sub(trade.Fee))
+	}
+
+	if err != nil {
+		log.WithError(err).Errorf("can not settle the balance of order %d", o.OrderID)
+	}
+
+	// the limit buy order filled at a better price returns the locked balance when it's done
+	if o.Status == types.OrderStatusFilled {
+		e.unlockRemaining(o)
+	}
+
+	e.trades[o.Symbol] = append(e.trades[o.Symbol], trade)
+	e.EmitExecutionReport(ExecutionReport{ExecutionType: ExecutionTypeTrade, Order: *o, Trade: &trade})
+}
+
+// Deposit adds the balance to the account
+func (e *Engine) Deposit(currency string, amount fixedpoint.Value) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.Account.AddBalance(currency, amount)
+	e.EmitBalanceUpdate(e.Account.Balances())
+}
+
+// CancelOrder cancels the open user order by the order ID, or by the client order ID when the order ID is zero
+func (e *Engine) CancelOrder(symbol string, orderID uint64, clientOrderID string) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, ok := e.findOrder(symbol, orderID, clientOrderID)
+	if !ok || !order.IsWorking {
+		return nil, ErrUnknownOrder
+	}
+
+	book := e.book(symbol)
+	book.remove(order)
+
+	order.Status = types.OrderStatusCanceled
+	order.IsWorking = false
+	order.UpdateTime = types.Time(e.now())
+	e.unlockRemaining(order)
+
+	e.EmitExecutionReport(ExecutionReport{ExecutionType: ExecutionTypeCanceled, Order: *order})
+
+	changes := newLevelChanges()
+	changes.add(order.Side, order.Price)
+	e.emitBookUpdate(book, symbol, changes)
+	e.EmitBalanceUpdate(e.Account.Balances())
+	return order, nil
+}
+
+func (e *Engine) findOrder(symbol string, orderID uint64, clientOrderID string) (*Order, bool) {
+	if orderID > 0 {
+		order, ok := e.orders[orderID]
+		return order, ok && order.Symbol == symbol
+	}
+
+	if clientOrderID == "" {
+		return nil, false
+	}
+
+	for _, order := range e.orders {
+		if order.Symbol == symbol && order.ClientOrderID == clientOrderID {
+			return order, true
+		}
+	}
+	return nil, false
+}
+
+// Order returns the user order by the order ID, or by the client order ID when the order ID is zero
+func (e *Engine) Order(symbol string, orderID uint64, clientOrderID string) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, ok := e.findOrder(symbol, orderID, clientOrderID)
+	if !ok {
+		return nil, ErrOrderNotExist
+	}
+
+	copied := *order
+	return &copied, nil
+}
+
+// Orders returns the user orders of the symbol in the order ID ascending order, the orders with ID less than fromID are skipped
+func (e *Engine) Orders(symbol string, fromID uint64, openOnly bool) (orders []Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range e.orders {
+		if (symbol != "" && order.Symbol != symbol) || order.OrderID < fromID || (openOnly && !order.IsWorking) {
+			continue
+		}
+		orders = append(orders, *order)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OrderID < orders[j].OrderID
+	})
+	return orders
+}
+
+// Trades returns the user trades of the symbol, the trades with ID less than fromID are skipped
+func (e *Engine) Trades(symbol string, fromID uint64) (trades []types.Trade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, trade := range e.trades[symbol] {
+		if trade.ID >= fromID {
+			trades = append(trades, trade)
+		}
+	}
+	return trades
+}
+
+// MarketTrades returns the public trades of the symbol
+func (e *Engine) MarketTrades(symbol string) []types.Trade {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]types.Trade(nil), e.marketTrades[symbol]...)
+}
+
+// Depth returns the order book snapshot of the symbol and its last update ID
+func (e *Engine) Depth(symbol string, limit int) (types.SliceOrderBook, int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	book := e.book(symbol)
+	return types.SliceOrderBook{
+		Symbol: symbol,
+		Bids:   book.levels(types.SideTypeBuy, limit),
+		Asks:   book.levels(types.SideTypeSell, limit),
+	}, book.updateID
+}
+
+// LastPrice returns the price of the last public trade
+func (e *Engine) LastPrice(symbol string) (fixedpoint.Value, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	trades := e.marketTrades[symbol]
+	if len(trades) == 0 {
+		return fixedpoint.Zero, false
+	}
+	return trades[len(trades)-1].Price, true
+}
+
+// levelChanges collects the changed price levels during the order book operation
+type levelChanges struct {
+	bids, asks []fixedpoint.Value
+}
+
+func newLevelChanges() *levelChanges {
+	return &levelChanges{}
+}
+
+func (c *levelChanges) add(side types.SideType, price fixedpoint.Value) {
+	prices := &c.asks
+	if side == types.SideTypeBuy {
+		prices = &c.bids
+	}
+
+	for _, p := range *prices {
+		if p.Compare(price) == 0 {
+			return
+		}
+	}
+	*prices = append(*prices, price)
+}
+
+func (e *Engine) emitBookUpdate(book *orderBook, symbol string, changes *levelChanges) {
+	if len(changes.bids) == 0 && len(changes.asks) == 0 {
+		return
+	}
+
+	book.updateID++
+	update := BookUpdate{
+		Symbol:        symbol,
+		FirstUpdateID: book.updateID,
+		FinalUpdateID: book.updateID,
+	}
+
+	for _, price := range changes.bids {
+		update.Bids = append(update.Bids, types.PriceVolume{Price: price, Volume: book.volume(types.SideTypeBuy, price)})
+	}
+
+	for _, price := range changes.asks {
+		update.Asks = append(update.Asks, types.PriceVolume{Price: price, Volume: book.volume(types.SideTypeSell, price)})
+	}
+
+	if bids := book.levels(types.SideTypeBuy, 1); len(bids) > 0 {
+		update.BestBid = bids[0]
+	}
+
+	if asks := book.levels(types.SideTypeSell, 1); len(asks) > 0 {
+		update.BestAsk = asks[0]
+	}
+
+	e.EmitBookUpdate(update)
+}
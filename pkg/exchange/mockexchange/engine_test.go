@@ -0,0 +1,250 @@
+package mockexchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSDT",
+	LocalSymbol:     "BTCUSDT",
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USDT",
+	PricePrecision:  2,
+	VolumePrecision: 6,
+	MinQuantity:     fixedpoint.NewFromFloat(0.0001),
+	MaxQuantity:     fixedpoint.NewFromInt(1000),
+	StepSize:        fixedpoint.NewFromFloat(0.000001),
+	MinPrice:        fixedpoint.NewFromFloat(0.01),
+	MaxPrice:        fixedpoint.NewFromInt(1000000),
+	TickSize:        fixedpoint.NewFromFloat(0.01),
+	MinNotional:     fixedpoint.NewFromInt(10),
+}
+
+func newTestEngine() *Engine {
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(100000)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromInt(1)},
+	})
+	return NewEngine(types.MarketMap{testMarket.Symbol: testMarket}, account)
+}
+
+func limitOrder(side types.SideType, price, quantity float64) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     side,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.NewFromFloat(price),
+		Quantity: fixedpoint.NewFromFloat(quantity),
+	}
+}
+
+func TestEngine_PriceTimePriority(t *testing.T) {
+	engine := newTestEngine()
+
+	var reports []ExecutionReport
+	engine.OnExecutionReport(func(report ExecutionReport) {
+		reports = append(reports, report)
+	})
+
+	first, err := engine.PlaceOrder(limitOrder(types.SideTypeSell, 20000, 0.1))
+	assert.NoError(t, err)
+	second, err := engine.PlaceOrder(limitOrder(types.SideTypeSell, 20000, 0.1))
+	assert.NoError(t, err)
+	_, err = engine.PlaceExternalOrder(limitOrder(types.SideTypeSell, 19900, 0.05))
+	assert.NoError(t, err)
+
+	// the taker walks through the better price first, then the earlier order of the same price level
+	taker, err := engine.PlaceOrder(limitOrder(types.SideTypeBuy, 20000, 0.1))
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusFilled, taker.Status)
+		assert.Equal(t, "1995", taker.ExecutedQuoteQuantity.String())
+	}
+
+	o, err := engine.Order("BTCUSDT", first.OrderID, "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusPartiallyFilled, o.Status)
+		assert.Equal(t, "0.05", o.ExecutedQuantity.String())
+	}
+
+	o, err = engine.Order("BTCUSDT", second.OrderID, "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusNew, o.Status)
+	}
+
+	book, _ := engine.Depth("BTCUSDT", 0)
+	if assert.Len(t, book.Asks, 1) {
+		assert.Equal(t, "20000", book.Asks[0].Price.String())
+		assert.Equal(t, "0.15", book.Asks[0].Volume.String())
+	}
+
+	var tradeReports int
+	for _, report := range reports {
+		if report.ExecutionType == ExecutionTypeTrade {
+			tradeReports++
+		}
+	}
+	// 2 trades of the taker and 1 trade of the maker
+	assert.Equal(t, 3, tradeReports)
+}
+
+func TestEngine_Balances(t *testing.T) {
+	engine := newTestEngine()
+	engine.Account.MakerFeeRate = fixedpoint.NewFromFloat(0.001)
+	engine.Account.TakerFeeRate = fixedpoint.NewFromFloat(0.001)
+
+	_, err := engine.PlaceExternalOrder(limitOrder(types.SideTypeSell, 19000, 1))
+	assert.NoError(t, err)
+
+	// the limit buy order is filled at the better price, the rest of the locked balance is returned
+	order, err := engine.PlaceOrder(limitOrder(types.SideTypeBuy, 20000, 0.5))
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusFilled, order.Status)
+	}
+
+	usdt, _ := engine.Account.Balance("USDT")
+	assert.Equal(t, "90500", usdt.Available.String())
+	assert.Equal(t, "0", usdt.Locked.String())
+
+	btc, _ := engine.Account.Balance("BTC")
+	assert.Equal(t, "1.4995", btc.Available.String())
+
+	// resting order locks the balance, and it's unlocked after canceled
+	order, err = engine.PlaceOrder(limitOrder(types.SideTypeSell, 21000, 1))
+	assert.NoError(t, err)
+	btc, _ = engine.Account.Balance("BTC")
+	assert.Equal(t, "1", btc.Locked.String())
+
+	_, err = engine.CancelOrder("BTCUSDT", order.OrderID, "")
+	assert.NoError(t, err)
+	btc, _ = engine.Account.Balance("BTC")
+	assert.Equal(t, "0", btc.Locked.String())
+	assert.Equal(t, "1.4995", btc.Available.String())
+
+	_, err = engine.CancelOrder("BTCUSDT", order.OrderID, "")
+	assert.Equal(t, ErrUnknownOrder, err)
+
+	_, err = engine.PlaceOrder(limitOrder(types.SideTypeSell, 21000, 10))
+	assert.Equal(t, ErrInsufficientBalance, err)
+}
+
+func TestEngine_TimeInForce(t *testing.T) {
+	engine := newTestEngine()
+
+	_, err := engine.PlaceExternalOrder(limitOrder(types.SideTypeSell, 20000, 0.1))
+	assert.NoError(t, err)
+
+	// fill or kill order is expired without any fill
+	fok := limitOrder(types.SideTypeBuy, 20000, 0.2)
+	fok.TimeInForce = types.TimeInForceFOK
+	order, err := engine.PlaceOrder(fok)
+	if assert.NoError(t, err) {
+		assert.Equal(t, OrderStatusExpired, order.Status)
+		assert.Equal(t, "0", order.ExecutedQuantity.String())
+	}
+
+	// the rest of the immediate or cancel order is expired
+	ioc := limitOrder(types.SideTypeBuy, 20000, 0.2)
+	ioc.TimeInForce = types.TimeInForceIOC
+	order, err = engine.PlaceOrder(ioc)
+	if assert.NoError(t, err) {
+		assert.Equal(t, OrderStatusExpired, order.Status)
+		assert.Equal(t, "0.1", order.ExecutedQuantity.String())
+	}
+
+	usdt, _ := engine.Account.Balance("USDT")
+	assert.Equal(t, "98000", usdt.Available.String())
+	assert.Equal(t, "0", usdt.Locked.String())
+
+	_, err = engine.PlaceExternalOrder(limitOrder(types.SideTypeSell, 20000, 0.1))
+	assert.NoError(t, err)
+
+	maker := limitOrder(types.SideTypeBuy, 20000, 0.1)
+	maker.Type = types.OrderTypeLimitMaker
+	_, err = engine.PlaceOrder(maker)
+	assert.Equal(t, ErrImmediateMatch, err)
+}
+
+func TestEngine_MarketOrderAndRejects(t *testing.T) {
+	engine := newTestEngine()
+
+	_, err := engine.PlaceExternalOrder(limitOrder(types.SideTypeBuy, 19000, 0.3))
+	assert.NoError(t, err)
+	_, err = engine.PlaceExternalOrder(limitOrder(types.SideTypeBuy, 18000, 0.3))
+	assert.NoError(t, err)
+
+	engine.RejectOrders(1, nil)
+	market := types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(0.5),
+	}
+	_, err = engine.PlaceOrder(market)
+	assert.Equal(t, ErrOrderRejected, err)
+
+	order, err := engine.PlaceOrder(market)
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusFilled, order.Status)
+		assert.Equal(t, "9300", order.ExecutedQuoteQuantity.String())
+	}
+
+	trades := engine.Trades("BTCUSDT", 0)
+	assert.Len(t, trades, 2)
+
+	_, err = engine.PlaceOrder(limitOrder(types.SideTypeSell, 19000, 0.0001))
+	assert.Error(t, err, "min notional")
+
+	startTime := trades[0].Time.Time().Truncate(time.Minute)
+	kLines := engine.KLines("BTCUSDT", types.Interval1m, startTime, startTime, 0)
+	if assert.Len(t, kLines, 1) {
+		assert.Equal(t, "19000", kLines[0].Open.String())
+		assert.Equal(t, "18000", kLines[0].Close.String())
+		assert.Equal(t, "0.5", kLines[0].Volume.String())
+	}
+}
+
+func TestNewEngineFromConfig(t *testing.T) {
+	var config Config
+	err := yaml.Unmarshal([]byte(`
+takerFeeRate: 0.001
+balances:
+  USDT: 1000.0
+markets:
+- symbol: BTCUSDT
+  baseCurrency: BTC
+  quoteCurrency: USDT
+  pricePrecision: 2
+  volumePrecision: 6
+  minQuantity: 0.0001
+  stepSize: 0.000001
+  tickSize: 0.01
+  minNotional: 10.0
+  orders:
+  - { side: BUY, price: 19900.0, quantity: 0.5 }
+  - { side: SELL, price: 20100.0, quantity: 0.5 }
+`), &config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	engine, err := NewEngineFromConfig(&config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	book, _ := engine.Depth("BTCUSDT", 0)
+	assert.Len(t, book.Bids, 1)
+	assert.Len(t, book.Asks, 1)
+
+	usdt, _ := engine.Account.Balance("USDT")
+	assert.Equal(t, "1000", usdt.Available.String())
+	assert.Equal(t, "0.001", engine.Account.TakerFeeRate.String())
+}
@@ -0,0 +1,116 @@
+package mockexchange
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// KLines aggregates the public trades of the symbol into klines,
+// the intervals without trades are filled with the close price of the previous kline.
+// The klines are returned in ascending order, the start time and the end time are inclusive when they are not zero.
+func (e *Engine) KLines(symbol string, interval types.Interval, startTime, endTime time.Time, limit int) []types.KLine {
+	trades := e.MarketTrades(symbol)
+	now := e.now()
+	d := interval.Duration()
+	if len(trades) == 0 || d == 0 {
+		return nil
+	}
+
+	var kLines []types.KLine
+	for _, trade := range trades {
+		t := trade.Time.Time()
+		bucket := t.Truncate(d)
+
+		n := len(kLines)
+		if n > 0 && kLines[n-1].StartTime.Time().Equal(bucket) {
+			updateKLine(&kLines[n-1], trade)
+			continue
+		}
+
+		// fill the gaps with the flat klines
+		if n > 0 {
+			last := kLines[n-1]
+			for s := last.StartTime.Time().Add(d); s.Before(bucket); s = s.Add(d) {
+				kLines = append(kLines, newFlatKLine(symbol, interval, s, last.Close))
+			}
+		}
+
+		kLine := newFlatKLine(symbol, interval, bucket, trade.Price)
+		updateKLine(&kLine, trade)
+		kLines = append(kLines, kLine)
+	}
+
+	// fill the klines till now
+	last := kLines[len(kLines)-1]
+	for s := last.StartTime.Time().Add(d); !s.After(now); s = s.Add(d) {
+		kLines = append(kLines, newFlatKLine(symbol, interval, s, last.Close))
+	}
+
+	var filtered []types.KLine
+	for _, kLine := range kLines {
+		kLine.Closed = kLine.EndTime.Time().Before(now)
+		if !startTime.IsZero() && kLine.StartTime.Time().Before(startTime) {
+			continue
+		}
+		if !endTime.IsZero() && kLine.StartTime.Time().After(endTime) {
+			continue
+		}
+		filtered = append(filtered, kLine)
+	}
+
+	if limit > 0 && len(filtered) > limit {
+		// binance returns the earliest klines when the start time is given, otherwise the latest klines
+		if !startTime.IsZero() {
+			filtered = filtered[:limit]
+		} else {
+			filtered = filtered[len(filtered)-limit:]
+		}
+	}
+
+	return filtered
+}
+
+// KLine returns the kline of the interval that starts at the start time
+func (e *Engine) KLine(symbol string, interval types.Interval, startTime time.Time) (types.KLine, bool) {
+	kLines := e.KLines(symbol, interval, startTime, startTime, 1)
+	if len(kLines) == 0 {
+		return types.KLine{}, false
+	}
+	return kLines[0], true
+}
+
+func newFlatKLine(symbol string, interval types.Interval, startTime time.Time, price fixedpoint.Value) types.KLine {
+	return types.KLine{
+		Exchange:  types.ExchangeBinance,
+		Symbol:    symbol,
+		Interval:  interval,
+		StartTime: types.Time(startTime),
+		EndTime:   types.Time(startTime.Add(interval.Duration() - time.Millisecond)),
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+	}
+}
+
+func updateKLine(k *types.KLine, trade types.Trade) {
+	if k.NumberOfTrades == 0 {
+		k.Open = trade.Price
+		k.High = trade.Price
+		k.Low = trade.Price
+	}
+
+	k.High = fixedpoint.Max(k.High, trade.Price)
+	k.Low = fixedpoint.Min(k.Low, trade.Price)
+	k.Close = trade.Price
+	k.Volume = k.Volume.Add(trade.Quantity)
+	k.QuoteVolume = k.QuoteVolume.Add(trade.QuoteQuantity)
+	if trade.IsBuyer {
+		k.TakerBuyBaseAssetVolume = k.TakerBuyBaseAssetVolume.Add(trade.Quantity)
+		k.TakerBuyQuoteAssetVolume = k.TakerBuyQuoteAssetVolume.Add(trade.QuoteQuantity)
+	}
+	k.LastTradeID = trade.ID
+	k.NumberOfTrades++
+}
@@ -0,0 +1,581 @@
+package mockexchange
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "mock")
+
+var errAPIKeyRequired = &common.APIError{Code: -2014, Message: "API-key format invalid."}
+
+// Server serves the binance compatible REST API and websocket streams of the matching engine,
+// so that the binance exchange of bbgo can run against it with the base urls pointed to the server.
+//
+// The API key is required by the private endpoints, but the signature is not verified.
+//
+// The admin endpoints reproduce the exchange-side edge cases:
+//
+//	POST /mock/orders      places an external order (types.SubmitOrder json) to add the liquidity or to fill the user orders
+//	POST /mock/reject      rejects the next submitted orders, {"count": 1, "code": -2010, "msg": "..."}
+//	POST /mock/disconnect  closes all the websocket connections
+//	POST /mock/deposit     adds the balance to the account, {"currency": "USDT", "amount": "1000"}
+type Server struct {
+	Engine *Engine
+
+	mu         sync.Mutex
+	listenKeys map[string]struct{}
+	conns      map[*streamConn]struct{}
+}
+
+func NewServer(engine *Engine) *Server {
+	s := &Server{
+		Engine:     engine,
+		listenKeys: make(map[string]struct{}),
+		conns:      make(map[*streamConn]struct{}),
+	}
+
+	engine.OnExecutionReport(s.handleExecutionReport)
+	engine.OnBalanceUpdate(s.handleBalanceUpdate)
+	engine.OnMarketTrade(s.handleMarketTrade)
+	engine.OnBookUpdate(s.handleBookUpdate)
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/ping", s.handlePing)
+	mux.HandleFunc("/api/v3/time", s.handleTime)
+	mux.HandleFunc("/api/v3/exchangeInfo", s.handleExchangeInfo)
+	mux.HandleFunc("/api/v3/ticker/24hr", s.handleTicker)
+	mux.HandleFunc("/api/v3/avgPrice", s.handleAveragePrice)
+	mux.HandleFunc("/api/v3/klines", s.handleKLines)
+	mux.HandleFunc("/api/v3/depth", s.handleDepth)
+	mux.HandleFunc("/api/v3/account", s.private(s.handleAccount))
+	mux.HandleFunc("/api/v3/order", s.private(s.handleOrder))
+	mux.HandleFunc("/api/v3/openOrders", s.private(s.handleOpenOrders))
+	mux.HandleFunc("/api/v3/allOrders", s.private(s.handleAllOrders))
+	mux.HandleFunc("/api/v3/myTrades", s.private(s.handleMyTrades))
+	mux.HandleFunc("/api/v3/userDataStream", s.private(s.handleUserDataStream))
+	mux.HandleFunc("/ws", s.handleStream)
+	mux.HandleFunc("/ws/", s.handleStream)
+	mux.HandleFunc("/mock/orders", s.handleExternalOrder)
+	mux.HandleFunc("/mock/reject", s.handleReject)
+	mux.HandleFunc("/mock/disconnect", s.handleDisconnect)
+	mux.HandleFunc("/mock/deposit", s.handleDeposit)
+	return mux
+}
+
+// private checks the API key header of the private endpoints
+func (s *Server) private(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-MBX-APIKEY") == "" {
+			writeJson(w, http.StatusUnauthorized, errAPIKeyRequired)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// parseParams parses the query string and the form body, the form body of the DELETE requests is parsed as well
+func parseParams(r *http.Request) (url.Values, error) {
+	params := r.URL.Query()
+	if r.Body == nil {
+		return params, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, vs := range form {
+		params[k] = append(params[k], vs...)
+	}
+	return params, nil
+}
+
+func parseInt(params url.Values, key string) int64 {
+	v, _ := strconv.ParseInt(params.Get(key), 10, 64)
+	return v
+}
+
+func parseMilliseconds(params url.Values, key string) time.Time {
+	v := parseInt(params, key)
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	apiErr, ok := err.(*common.APIError)
+	if !ok {
+		apiErr = &common.APIError{Code: -1102, Message: err.Error()}
+	}
+	writeJson(w, http.StatusBadRequest, apiErr)
+}
+
+func writeJson(w http.ResponseWriter, status int, o interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		log.WithError(err).Errorf("can not write the response")
+	}
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) handleTime(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]int64{"serverTime": s.Engine.now().UnixMilli()})
+}
+
+func (s *Server) handleExchangeInfo(w http.ResponseWriter, r *http.Request) {
+	info := binance.ExchangeInfo{
+		Timezone:   "UTC",
+		ServerTime: s.Engine.now().UnixMilli(),
+	}
+
+	for _, market := range s.Engine.Markets {
+		info.Symbols = append(info.Symbols, toBinanceSymbol(market))
+	}
+
+	writeJson(w, http.StatusOK, info)
+}
+
+func (s *Server) ticker(symbol string) *binance.PriceChangeStats {
+	now := s.Engine.now()
+	book, _ := s.Engine.Depth(symbol, 1)
+	stats := &binance.PriceChangeStats{
+		Symbol:    symbol,
+		OpenTime:  now.Add(-24 * time.Hour).UnixMilli(),
+		CloseTime: now.UnixMilli(),
+	}
+
+	var open, high, low, last, volume, quoteVolume fixedpoint.Value
+	for _, trade := range s.Engine.MarketTrades(symbol) {
+		if trade.Time.Time().Before(now.Add(-24 * time.Hour)) {
+			continue
+		}
+
+		if stats.Count == 0 {
+			open, high, low = trade.Price, trade.Price, trade.Price
+			stats.FristID = int64(trade.ID)
+		}
+
+		high = fixedpoint.Max(high, trade.Price)
+		low = fixedpoint.Min(low, trade.Price)
+		last = trade.Price
+		volume = volume.Add(trade.Quantity)
+		quoteVolume = quoteVolume.Add(trade.QuoteQuantity)
+		stats.LastID = int64(trade.ID)
+		stats.LastQty = trade.Quantity.String()
+		stats.Count++
+	}
+
+	stats.OpenPrice = open.String()
+	stats.HighPrice = high.String()
+	stats.LowPrice = low.String()
+	stats.LastPrice = last.String()
+	stats.Volume = volume.String()
+	stats.QuoteVolume = quoteVolume.String()
+	stats.BidPrice = fixedpoint.Zero.String()
+	stats.AskPrice = fixedpoint.Zero.String()
+	if bid, ok := book.BestBid(); ok {
+		stats.BidPrice = bid.Price.String()
+	}
+	if ask, ok := book.BestAsk(); ok {
+		stats.AskPrice = ask.Price.String()
+	}
+
+	return stats
+}
+
+func (s *Server) handleTicker(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol != "" {
+		if _, ok := s.Engine.Markets[symbol]; !ok {
+			writeError(w, ErrInvalidSymbol)
+			return
+		}
+
+		writeJson(w, http.StatusOK, s.ticker(symbol))
+		return
+	}
+
+	var stats []*binance.PriceChangeStats
+	for symbol := range s.Engine.Markets {
+		stats = append(stats, s.ticker(symbol))
+	}
+	writeJson(w, http.StatusOK, stats)
+}
+
+func (s *Server) handleAveragePrice(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	price, _ := s.Engine.LastPrice(symbol)
+	writeJson(w, http.StatusOK, binance.AvgPrice{Mins: 5, Price: price.String()})
+}
+
+func (s *Server) handleKLines(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	symbol := params.Get("symbol")
+	if _, ok := s.Engine.Markets[symbol]; !ok {
+		writeError(w, ErrInvalidSymbol)
+		return
+	}
+
+	interval := types.Interval(params.Get("interval"))
+	if _, ok := types.SupportedIntervals[interval]; !ok {
+		writeError(w, &common.APIError{Code: -1120, Message: "Invalid interval."})
+		return
+	}
+
+	limit := int(parseInt(params, "limit"))
+	if limit <= 0 {
+		limit = 500
+	}
+
+	kLines := s.Engine.KLines(symbol, interval, parseMilliseconds(params, "startTime"), parseMilliseconds(params, "endTime"), limit)
+	resp := make([][]interface{}, 0, len(kLines))
+	for _, k := range kLines {
+		resp = append(resp, toBinanceKLine(k))
+	}
+	writeJson(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleDepth(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	symbol := params.Get("symbol")
+	if _, ok := s.Engine.Markets[symbol]; !ok {
+		writeError(w, ErrInvalidSymbol)
+		return
+	}
+
+	limit := int(parseInt(params, "limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+
+	book, updateID := s.Engine.Depth(symbol, limit)
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"lastUpdateId": updateID,
+		"bids":         toBinancePriceLevels(book.Bids),
+		"asks":         toBinancePriceLevels(book.Asks),
+	})
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	account := binance.Account{
+		MakerCommission: s.Engine.Account.MakerFeeRate.Mul(fixedpoint.NewFromInt(10000)).Int64(),
+		TakerCommission: s.Engine.Account.TakerFeeRate.Mul(fixedpoint.NewFromInt(10000)).Int64(),
+		CanTrade:        true,
+		CanWithdraw:     true,
+		CanDeposit:      true,
+		UpdateTime:      uint64(s.Engine.now().UnixMilli()),
+		AccountType:     "SPOT",
+		Permissions:     []string{"SPOT"},
+	}
+
+	for _, b := range s.Engine.Account.Balances() {
+		account.Balances = append(account.Balances, binance.Balance{
+			Asset:  b.Currency,
+			Free:   b.Available.String(),
+			Locked: b.Locked.String(),
+		})
+	}
+
+	writeJson(w, http.StatusOK, account)
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	params, err := parseParams(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	symbol := params.Get("symbol")
+	orderID := uint64(parseInt(params, "orderId"))
+	clientOrderID := params.Get("origClientOrderId")
+
+	switch r.Method {
+	case http.MethodPost:
+		so, err := toGlobalSubmitOrder(symbol, params.Get("side"), params.Get("type"), params.Get("timeInForce"),
+			params.Get("quantity"), params.Get("price"), params.Get("newClientOrderId"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if so.ClientOrderID == "" {
+			so.ClientOrderID = uuid.NewString()
+		}
+
+		order, err := s.Engine.PlaceOrder(so)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		o := toBinanceOrder(*order)
+		writeJson(w, http.StatusOK, binance.CreateOrderResponse{
+			Symbol:                   o.Symbol,
+			OrderID:                  o.OrderID,
+			ClientOrderID:            o.ClientOrderID,
+			TransactTime:             o.UpdateTime,
+			Price:                    o.Price,
+			OrigQuantity:             o.OrigQuantity,
+			ExecutedQuantity:         o.ExecutedQuantity,
+			CummulativeQuoteQuantity: o.CummulativeQuoteQuantity,
+			Status:                   o.Status,
+			TimeInForce:              o.TimeInForce,
+			Type:                     o.Type,
+			Side:                     o.Side,
+		})
+
+	case http.MethodDelete:
+		order, err := s.Engine.CancelOrder(symbol, orderID, clientOrderID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		o := toBinanceOrder(*order)
+		writeJson(w, http.StatusOK, binance.CancelOrderResponse{
+			Symbol:                   o.Symbol,
+			OrigClientOrderID:        o.ClientOrderID,
+			OrderID:                  o.OrderID,
+			OrderListID:              -1,
+			ClientOrderID:            o.ClientOrderID,
+			TransactTime:             o.UpdateTime,
+			Price:                    o.Price,
+			OrigQuantity:             o.OrigQuantity,
+			ExecutedQuantity:         o.ExecutedQuantity,
+			CummulativeQuoteQuantity: o.CummulativeQuoteQuantity,
+			Status:                   o.Status,
+			TimeInForce:              o.TimeInForce,
+			Type:                     o.Type,
+			Side:                     o.Side,
+		})
+
+	case http.MethodGet:
+		order, err := s.Engine.Order(symbol, orderID, clientOrderID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJson(w, http.StatusOK, toBinanceOrder(*order))
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeOrders(w http.ResponseWriter, orders []Order) {
+	resp := make([]*binance.Order, 0, len(orders))
+	for _, order := range orders {
+		resp = append(resp, toBinanceOrder(order))
+	}
+	writeJson(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleOpenOrders(w http.ResponseWriter, r *http.Request) {
+	writeOrders(w, s.Engine.Orders(r.URL.Query().Get("symbol"), 0, true))
+}
+
+func (s *Server) handleAllOrders(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	startTime := parseMilliseconds(params, "startTime")
+	endTime := parseMilliseconds(params, "endTime")
+	limit := int(parseInt(params, "limit"))
+	if limit <= 0 {
+		limit = 500
+	}
+
+	var orders []Order
+	for _, order := range s.Engine.Orders(params.Get("symbol"), uint64(parseInt(params, "orderId")), false) {
+		t := order.CreationTime.Time()
+		if (!startTime.IsZero() && t.Before(startTime)) || (!endTime.IsZero() && t.After(endTime)) {
+			continue
+		}
+
+		orders = append(orders, order)
+		if len(orders) >= limit {
+			break
+		}
+	}
+
+	writeOrders(w, orders)
+}
+
+func (s *Server) handleMyTrades(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	startTime := parseMilliseconds(params, "startTime")
+	endTime := parseMilliseconds(params, "endTime")
+	limit := int(parseInt(params, "limit"))
+	if limit <= 0 {
+		limit = 500
+	}
+
+	resp := make([]*binance.TradeV3, 0)
+	for _, trade := range s.Engine.Trades(params.Get("symbol"), uint64(parseInt(params, "fromId"))) {
+		t := trade.Time.Time()
+		if (!startTime.IsZero() && t.Before(startTime)) || (!endTime.IsZero() && t.After(endTime)) {
+			continue
+		}
+
+		resp = append(resp, toBinanceTrade(trade))
+		if len(resp) >= limit {
+			break
+		}
+	}
+
+	writeJson(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleUserDataStream(w http.ResponseWriter, r *http.Request) {
+	params, err := parseParams(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	listenKey := params.Get("listenKey")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPost:
+		listenKey = strings.ReplaceAll(uuid.NewString(), "-", "")
+		s.listenKeys[listenKey] = struct{}{}
+		writeJson(w, http.StatusOK, map[string]string{"listenKey": listenKey})
+
+	case http.MethodPut:
+		if _, ok := s.listenKeys[listenKey]; !ok {
+			writeError(w, &common.APIError{Code: -1125, Message: "This listenKey does not exist."})
+			return
+		}
+		writeJson(w, http.StatusOK, struct{}{})
+
+	case http.MethodDelete:
+		delete(s.listenKeys, listenKey)
+		for c := range s.conns {
+			if c.listenKey == listenKey {
+				c.close()
+			}
+		}
+		writeJson(w, http.StatusOK, struct{}{})
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleExternalOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var so types.SubmitOrder
+	if err := json.NewDecoder(r.Body).Decode(&so); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if so.Type == "" {
+		so.Type = types.OrderTypeLimit
+	}
+
+	order, err := s.Engine.PlaceExternalOrder(so)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, toBinanceOrder(*order))
+}
+
+// RejectRequest is the request of POST /mock/reject
+type RejectRequest struct {
+	Count   int    `json:"count"`
+	Code    int64  `json:"code,omitempty"`
+	Message string `json:"msg,omitempty"`
+}
+
+func (s *Server) handleReject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := RejectRequest{Count: 1}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if req.Code != 0 || req.Message != "" {
+		err = &common.APIError{Code: req.Code, Message: req.Message}
+	}
+
+	s.Engine.RejectOrders(req.Count, err)
+	writeJson(w, http.StatusOK, req)
+}
+
+func (s *Server) handleDisconnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n := s.DisconnectStreams()
+	writeJson(w, http.StatusOK, map[string]int{"disconnected": n})
+}
+
+// DepositRequest is the request of POST /mock/deposit
+type DepositRequest struct {
+	Currency string           `json:"currency"`
+	Amount   fixedpoint.Value `json:"amount"`
+}
+
+func (s *Server) handleDeposit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DepositRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.Engine.Deposit(req.Currency, req.Amount)
+	writeJson(w, http.StatusOK, req)
+}
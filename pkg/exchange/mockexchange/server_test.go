@@ -0,0 +1,227 @@
+package mockexchange
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestServer_BinanceExchange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	engine := newTestEngine()
+	server := NewServer(engine)
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ex := binance.New("key", "secret",
+		binance.WithBaseURL(httpServer.URL),
+		binance.WithWebSocketURL("ws"+strings.TrimPrefix(httpServer.URL, "http")))
+
+	markets, err := ex.QueryMarkets(ctx)
+	if assert.NoError(t, err) {
+		market, ok := markets["BTCUSDT"]
+		if assert.True(t, ok) {
+			assert.Equal(t, "BTC", market.BaseCurrency)
+			assert.Equal(t, "0.0001", market.MinQuantity.String())
+			assert.Equal(t, "10", market.MinNotional.String())
+		}
+	}
+
+	var mu sync.Mutex
+	var orderUpdates []types.Order
+	var tradeUpdates []types.Trade
+	var balanceUpdates []types.BalanceMap
+	var disconnected bool
+
+	stream := ex.NewStream()
+	stream.OnOrderUpdate(func(order types.Order) {
+		mu.Lock()
+		orderUpdates = append(orderUpdates, order)
+		mu.Unlock()
+	})
+	stream.OnTradeUpdate(func(trade types.Trade) {
+		mu.Lock()
+		tradeUpdates = append(tradeUpdates, trade)
+		mu.Unlock()
+	})
+	stream.OnBalanceSnapshot(func(balances types.BalanceMap) {
+		mu.Lock()
+		balanceUpdates = append(balanceUpdates, balances)
+		mu.Unlock()
+	})
+	stream.OnDisconnect(func() {
+		mu.Lock()
+		disconnected = true
+		mu.Unlock()
+	})
+	assert.NoError(t, stream.Connect(ctx))
+	defer stream.Close()
+
+	createdOrders, err := ex.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.NewFromInt(20000),
+		Quantity: fixedpoint.NewFromFloat(0.5),
+		Market:   markets["BTCUSDT"],
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, createdOrders, 1) {
+		return
+	}
+	assert.Equal(t, types.OrderStatusNew, createdOrders[0].Status)
+
+	openOrders, err := ex.QueryOpenOrders(ctx, "BTCUSDT")
+	if assert.NoError(t, err) {
+		assert.Len(t, openOrders, 1)
+	}
+
+	// the other participant fills the order partially
+	_, err = engine.PlaceExternalOrder(limitOrder(types.SideTypeBuy, 20000, 0.2))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(tradeUpdates) == 1 && len(balanceUpdates) >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	if assert.Len(t, tradeUpdates, 1) {
+		assert.Equal(t, createdOrders[0].OrderID, tradeUpdates[0].OrderID)
+		assert.Equal(t, "0.2", tradeUpdates[0].Quantity.String())
+		assert.True(t, tradeUpdates[0].IsMaker)
+	}
+	if assert.NotEmpty(t, orderUpdates) {
+		assert.Equal(t, types.OrderStatusNew, orderUpdates[0].Status)
+	}
+	if assert.NotEmpty(t, balanceUpdates) {
+		assert.Equal(t, "0.5", balanceUpdates[len(balanceUpdates)-1]["BTC"].Available.String())
+		assert.Equal(t, "0.3", balanceUpdates[len(balanceUpdates)-1]["BTC"].Locked.String())
+	}
+	mu.Unlock()
+
+	trades, err := ex.QueryTrades(ctx, "BTCUSDT", &types.TradeQueryOptions{})
+	if assert.NoError(t, err) {
+		assert.Len(t, trades, 1)
+	}
+
+	order, err := ex.QueryOrder(ctx, types.OrderQuery{Symbol: "BTCUSDT", OrderID: "1"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+		assert.Equal(t, "0.2", order.ExecutedQuantity.String())
+	}
+
+	assert.NoError(t, ex.CancelOrders(ctx, createdOrders...))
+
+	account, err := ex.QueryAccount(ctx)
+	if assert.NoError(t, err) {
+		btc, ok := account.Balance("BTC")
+		assert.True(t, ok)
+		assert.Equal(t, "0.8", btc.Available.String())
+		assert.Equal(t, "0", btc.Locked.String())
+	}
+
+	// the next order is rejected on demand
+	engine.RejectOrders(1, nil)
+	_, err = ex.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.NewFromInt(19000),
+		Quantity: fixedpoint.NewFromFloat(0.1),
+		Market:   markets["BTCUSDT"],
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, server.DisconnectStreams())
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return disconnected
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestServer_PublicStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	engine := newTestEngine()
+	server := NewServer(engine)
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ex := binance.New("", "",
+		binance.WithBaseURL(httpServer.URL),
+		binance.WithWebSocketURL("ws"+strings.TrimPrefix(httpServer.URL, "http")))
+
+	var mu sync.Mutex
+	var marketTrades []types.Trade
+	var bookTickers []types.BookTicker
+
+	stream := ex.NewStream()
+	stream.SetPublicOnly()
+	stream.Subscribe(types.MarketTradeChannel, "BTCUSDT", types.SubscribeOptions{})
+	stream.Subscribe(types.BookTickerChannel, "BTCUSDT", types.SubscribeOptions{})
+	stream.OnMarketTrade(func(trade types.Trade) {
+		mu.Lock()
+		marketTrades = append(marketTrades, trade)
+		mu.Unlock()
+	})
+	stream.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+		mu.Lock()
+		bookTickers = append(bookTickers, bookTicker)
+		mu.Unlock()
+	})
+	assert.NoError(t, stream.Connect(ctx))
+	defer stream.Close()
+
+	// wait for the subscription
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		for c := range server.conns {
+			if c.subscribed("btcusdt@trade") {
+				return true
+			}
+		}
+		return false
+	}, 3*time.Second, 10*time.Millisecond)
+
+	_, err := engine.PlaceExternalOrder(limitOrder(types.SideTypeSell, 20000, 0.5))
+	assert.NoError(t, err)
+	_, err = engine.PlaceExternalOrder(limitOrder(types.SideTypeBuy, 20000, 0.2))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(marketTrades) == 1 && len(bookTickers) >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, marketTrades, 1) {
+		assert.Equal(t, "20000", marketTrades[0].Price.String())
+		assert.Equal(t, "0.2", marketTrades[0].Quantity.String())
+	}
+	if assert.NotEmpty(t, bookTickers) {
+		assert.Equal(t, "20000", bookTickers[len(bookTickers)-1].Sell.String())
+		assert.Equal(t, "0.3", bookTickers[len(bookTickers)-1].SellSize.String())
+	}
+
+	kLines, err := ex.QueryKLines(ctx, "BTCUSDT", types.Interval1m, types.KLineQueryOptions{})
+	if assert.NoError(t, err) && assert.NotEmpty(t, kLines) {
+		assert.Equal(t, "20000", kLines[len(kLines)-1].Close.String())
+	}
+}
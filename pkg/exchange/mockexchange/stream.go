@@ -0,0 +1,353 @@
+package mockexchange
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// KLineCheckInterval is the interval of checking the closed klines of the kline subscriptions
+var KLineCheckInterval = time.Second
+
+const streamSendBufferSize = 1024
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// streamCommand is the SUBSCRIBE / UNSUBSCRIBE command sent by the binance stream
+type streamCommand struct {
+	ID     int      `json:"id"`
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+}
+
+// streamConn is the websocket connection of a public stream or a user data stream (listenKey is not empty)
+type streamConn struct {
+	conn      *websocket.Conn
+	listenKey string
+
+	sendC     chan []byte
+	closeC    chan struct{}
+	closeOnce sync.Once
+
+	mu            sync.Mutex
+	subscriptions map[string]time.Time
+}
+
+func newStreamConn(conn *websocket.Conn, listenKey string) *streamConn {
+	return &streamConn{
+		conn:          conn,
+		listenKey:     listenKey,
+		sendC:         make(chan []byte, streamSendBufferSize),
+		closeC:        make(chan struct{}),
+		subscriptions: make(map[string]time.Time),
+	}
+}
+
+// send queues the message, the slow connection is closed when the send buffer is full
+func (c *streamConn) send(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.WithError(err).Errorf("can not encode the stream message")
+		return
+	}
+
+	select {
+	case c.sendC <- data:
+	case <-c.closeC:
+	default:
+		log.Warnf("stream send buffer is full, closing the connection")
+		c.close()
+	}
+}
+
+func (c *streamConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.closeC)
+		_ = c.conn.Close()
+	})
+}
+
+func (c *streamConn) subscribed(channel string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.subscriptions[channel]
+	return ok
+}
+
+func (c *streamConn) subscribe(channel string, now time.Time) {
+	// the update speed of the depth stream is not simulated, the updates are sent immediately
+	channel = strings.TrimSuffix(strings.TrimSuffix(channel, "@100ms"), "@1000ms")
+
+	c.mu.Lock()
+	c.subscriptions[strings.ToLower(channel)] = now
+	c.mu.Unlock()
+}
+
+func (c *streamConn) unsubscribe(channel string) {
+	channel = strings.TrimSuffix(strings.TrimSuffix(channel, "@100ms"), "@1000ms")
+
+	c.mu.Lock()
+	delete(c.subscriptions, strings.ToLower(channel))
+	c.mu.Unlock()
+}
+
+func (c *streamConn) writeLoop() {
+	for {
+		select {
+		case <-c.closeC:
+			return
+
+		case data := <-c.sendC:
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				log.WithError(err).Warnf("stream write error")
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) readLoop(c *streamConn) {
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd streamCommand
+		if err := json.Unmarshal(message, &cmd); err != nil {
+			c.send(&common.APIError{Code: 2, Message: "Invalid request: " + err.Error()})
+			continue
+		}
+
+		switch cmd.Method {
+		case "SUBSCRIBE":
+			for _, channel := range cmd.Params {
+				c.subscribe(channel, s.Engine.now())
+			}
+			c.send(map[string]interface{}{"result": nil, "id": cmd.ID})
+
+		case "UNSUBSCRIBE":
+			for _, channel := range cmd.Params {
+				c.unsubscribe(channel)
+			}
+			c.send(map[string]interface{}{"result": nil, "id": cmd.ID})
+
+		case "LIST_SUBSCRIPTIONS":
+			c.mu.Lock()
+			var channels []string
+			for channel := range c.subscriptions {
+				channels = append(channels, channel)
+			}
+			c.mu.Unlock()
+			c.send(map[string]interface{}{"result": channels, "id": cmd.ID})
+
+		default:
+			c.send(map[string]interface{}{"error": map[string]interface{}{"code": 2, "msg": "Invalid request: unknown method"}, "id": cmd.ID})
+		}
+	}
+}
+
+// kLineLoop sends the closed klines of the kline subscriptions
+func (s *Server) kLineLoop(c *streamConn) {
+	ticker := time.NewTicker(KLineCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closeC:
+			return
+
+		case <-ticker.C:
+			now := s.Engine.now()
+
+			type closedKLine struct {
+				symbol    string
+				interval  types.Interval
+				startTime time.Time
+			}
+
+			var closed []closedKLine
+
+			c.mu.Lock()
+			for channel, since := range c.subscriptions {
+				// <symbol>@kline_<interval>
+				parts := strings.SplitN(channel, "@kline_", 2)
+				if len(parts) != 2 {
+					continue
+				}
+
+				interval := types.Interval(parts[1])
+				d := interval.Duration()
+				if d == 0 {
+					continue
+				}
+
+				current := now.Truncate(d)
+				last := since.Truncate(d)
+				if current.After(last) {
+					closed = append(closed, closedKLine{symbol: strings.ToUpper(parts[0]), interval: interval, startTime: last})
+					c.subscriptions[channel] = current
+				}
+			}
+			c.mu.Unlock()
+
+			for _, k := range closed {
+				kLine, ok := s.Engine.KLine(k.symbol, k.interval, k.startTime)
+				if !ok {
+					continue
+				}
+
+				kLine.Closed = true
+				c.send(toBinanceKLineEvent(kLine, now.UnixMilli()))
+			}
+		}
+	}
+}
+
+// handleStream serves the websocket streams:
+//
+//	/ws                  the public stream, the channels are subscribed by the SUBSCRIBE command
+//	/ws/<listenKey>      the user data stream
+//	/ws/<stream name>    the public stream subscribed to the stream name, e.g., btcusdt@trade
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/ws"), "/")
+
+	var listenKey string
+	if name != "" && !strings.Contains(name, "@") {
+		s.mu.Lock()
+		_, ok := s.listenKeys[name]
+		s.mu.Unlock()
+
+		if !ok {
+			writeJson(w, http.StatusBadRequest, &common.APIError{Code: -1125, Message: "This listenKey does not exist."})
+			return
+		}
+
+		listenKey = name
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Errorf("stream upgrade error")
+		return
+	}
+
+	c := newStreamConn(conn, listenKey)
+	if listenKey == "" && name != "" {
+		c.subscribe(name, s.Engine.now())
+	}
+
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.close()
+	}()
+
+	go c.writeLoop()
+	if listenKey == "" {
+		go s.kLineLoop(c)
+	}
+
+	s.readLoop(c)
+}
+
+// DisconnectStreams closes all the websocket connections and returns the number of the closed connections,
+// the binance stream of bbgo reconnects after it's disconnected
+func (s *Server) DisconnectStreams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.conns {
+		c.close()
+	}
+	return len(s.conns)
+}
+
+func (s *Server) broadcast(filter func(c *streamConn) bool, message interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.conns {
+		if filter(c) {
+			c.send(message)
+		}
+	}
+}
+
+func isUserStream(c *streamConn) bool {
+	return c.listenKey != ""
+}
+
+func subscribedTo(channel string) func(c *streamConn) bool {
+	return func(c *streamConn) bool {
+		return c.listenKey == "" && c.subscribed(channel)
+	}
+}
+
+func (s *Server) handleExecutionReport(report ExecutionReport) {
+	s.broadcast(isUserStream, toBinanceExecutionReport(report, s.Engine.now().UnixMilli()))
+}
+
+func (s *Server) handleBalanceUpdate(balances types.BalanceMap) {
+	now := s.Engine.now().UnixMilli()
+	s.broadcast(isUserStream, map[string]interface{}{
+		"e": "outboundAccountPosition",
+		"E": now,
+		"u": now,
+		"B": toBinanceBalances(balances),
+	})
+}
+
+func (s *Server) handleMarketTrade(trade types.Trade) {
+	s.broadcast(subscribedTo(strings.ToLower(trade.Symbol)+"@trade"), map[string]interface{}{
+		"e": "trade",
+		"E": s.Engine.now().UnixMilli(),
+		"s": trade.Symbol,
+		"t": trade.ID,
+		"p": trade.Price.String(),
+		"q": trade.Quantity.String(),
+		"b": 0,
+		"a": 0,
+		"T": trade.Time.Time().UnixMilli(),
+		"m": !trade.IsBuyer,
+		"M": true,
+	})
+}
+
+func (s *Server) handleBookUpdate(update BookUpdate) {
+	symbol := strings.ToLower(update.Symbol)
+	s.broadcast(subscribedTo(symbol+"@depth"), map[string]interface{}{
+		"e": "depthUpdate",
+		"E": s.Engine.now().UnixMilli(),
+		"s": update.Symbol,
+		"U": update.FirstUpdateID,
+		"u": update.FinalUpdateID,
+		"b": toBinancePriceLevels(update.Bids),
+		"a": toBinancePriceLevels(update.Asks),
+	})
+
+	s.broadcast(subscribedTo(symbol+"@bookticker"), map[string]interface{}{
+		"u": update.FinalUpdateID,
+		"s": update.Symbol,
+		"b": update.BestBid.Price.String(),
+		"B": update.BestBid.Volume.String(),
+		"a": update.BestAsk.Price.String(),
+		"A": update.BestAsk.Volume.String(),
+	})
+}
@@ -48,6 +48,8 @@ func toGlobalBalance(account *okexapi.Account) types.BalanceMap {
 			Currency:  balanceDetail.Currency,
 			Available: balanceDetail.CashBalance,
 			Locked:    balanceDetail.Frozen,
+			Borrowed:  balanceDetail.Liability,
+			Interest:  balanceDetail.Interest,
 		}
 	}
 	return balanceMap
@@ -151,10 +153,46 @@ func toGlobalTrades(orderDetails []okexapi.OrderDetails) ([]types.Trade, error)
 			IsBuyer:       side == types.SideTypeBuy,
 			IsMaker:       orderDetail.ExecutionType == "M",
 			Time:          types.Time(orderDetail.LastFilledTime),
-			Fee:           orderDetail.LastFilledFee,
+			Fee:           orderDetail.LastFilledFee.Neg(), // the negative fill fee is charged by okex, the positive one is the rebate
 			FeeCurrency:   orderDetail.LastFilledFeeCurrency,
-			IsMargin:      false,
-			IsIsolated:    false,
+			IsMargin:      orderDetail.InstrumentType == string(okexapi.InstrumentTypeMargin),
+			IsIsolated:    orderDetail.TradeMode == string(okexapi.MarginModeIsolated),
+		})
+	}
+
+	return trades, nil
+}
+
+func toGlobalFills(fills []okexapi.Fill) ([]types.Trade, error) {
+	var trades []types.Trade
+	for _, fill := range fills {
+		tradeID, err := strconv.ParseInt(fill.TradeID, 10, 64)
+		if err != nil {
+			return trades, errors.Wrapf(err, "error parsing tradeId value: %s", fill.TradeID)
+		}
+
+		orderID, err := strconv.ParseInt(fill.OrderID, 10, 64)
+		if err != nil {
+			return trades, errors.Wrapf(err, "error parsing ordId value: %s", fill.OrderID)
+		}
+
+		side := types.SideType(strings.ToUpper(string(fill.Side)))
+
+		trades = append(trades, types.Trade{
+			ID:            uint64(tradeID),
+			OrderID:       uint64(orderID),
+			Exchange:      types.ExchangeOKEx,
+			Price:         fill.FillPrice,
+			Quantity:      fill.FillSize,
+			QuoteQuantity: fill.FillPrice.Mul(fill.FillSize),
+			Symbol:        toGlobalSymbol(fill.InstrumentID),
+			Side:          side,
+			IsBuyer:       side == types.SideTypeBuy,
+			IsMaker:       fill.ExecutionType == "M",
+			Time:          types.Time(fill.Timestamp),
+			Fee:           fill.Fee.Neg(),
+			FeeCurrency:   fill.FeeCurrency,
+			IsMargin:      fill.InstrumentType == okexapi.InstrumentTypeMargin,
 		})
 	}
 
@@ -215,8 +253,8 @@ func toGlobalOrders(orderDetails []okexapi.OrderDetails) ([]types.Order, error)
 			IsWorking:        isWorking,
 			CreationTime:     types.Time(orderDetail.CreationTime),
 			UpdateTime:       types.Time(orderDetail.UpdateTime),
-			IsMargin:         false,
-			IsIsolated:       false,
+			IsMargin:         orderDetail.InstrumentType == string(okexapi.InstrumentTypeMargin),
+			IsIsolated:       orderDetail.TradeMode == string(okexapi.MarginModeIsolated),
 		})
 	}
 
@@ -264,8 +302,9 @@ func toGlobalOrderType(orderType okexapi.OrderType) (types.OrderType, error) {
 	case okexapi.OrderTypePostOnly:
 		return types.OrderTypeLimitMaker, nil
 
-	case okexapi.OrderTypeFOK:
-	case okexapi.OrderTypeIOC:
+	// the time in force of the FOK and IOC orders is set by toGlobalOrders
+	case okexapi.OrderTypeFOK, okexapi.OrderTypeIOC:
+		return types.OrderTypeLimit, nil
 
 	}
 	return "", fmt.Errorf("unknown or unsupported okex order type: %s", orderType)
@@ -277,3 +316,70 @@ func toLocalInterval(src string) string {
 		return strings.ToUpper(w)
 	})
 }
+
+func toGlobalLoan(record okexapi.BorrowRepayRecord) types.MarginLoan {
+	return types.MarginLoan{
+		Exchange:      types.ExchangeOKEx,
+		TransactionID: uint64(record.Timestamp.Time().UnixMilli()),
+		Asset:         record.Currency,
+		Principle:     record.Amount,
+		Time:          types.Time(record.Timestamp.Time()),
+	}
+}
+
+func toGlobalRepay(record okexapi.BorrowRepayRecord) types.MarginRepay {
+	return types.MarginRepay{
+		Exchange:      types.ExchangeOKEx,
+		TransactionID: uint64(record.Timestamp.Time().UnixMilli()),
+		Asset:         record.Currency,
+		Principle:     record.Amount,
+		Time:          types.Time(record.Timestamp.Time()),
+	}
+}
+
+func toGlobalInterest(record okexapi.InterestAccrued) types.MarginInterest {
+	return types.MarginInterest{
+		Exchange:       types.ExchangeOKEx,
+		Asset:          record.Currency,
+		Principle:      record.Liability,
+		Interest:       record.Interest,
+		InterestRate:   record.InterestRate,
+		IsolatedSymbol: toGlobalSymbol(record.InstrumentID),
+		Time:           types.Time(record.Timestamp.Time()),
+	}
+}
+
+// toGlobalLiquidation converts the liquidation bill, the bill sub type tells the side of the liquidation order:
+// 100 partial liquidation close long, 101 partial liquidation close short, 102 partial liquidation buy,
+// 103 partial liquidation sell, 104 liquidation long, 105 liquidation short, 106 liquidation buy and 107 liquidation sell.
+func toGlobalLiquidation(bill okexapi.Bill) (types.MarginLiquidation, error) {
+	id := bill.OrderID
+	if len(id) == 0 {
+		id = bill.BillID
+	}
+
+	orderID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return types.MarginLiquidation{}, errors.Wrapf(err, "error parsing liquidation order id: %s", id)
+	}
+
+	side := types.SideTypeSell
+	switch bill.SubType {
+	case "101", "102", "105", "106":
+		side = types.SideTypeBuy
+	}
+
+	return types.MarginLiquidation{
+		Exchange:         types.ExchangeOKEx,
+		AveragePrice:     bill.Price,
+		ExecutedQuantity: bill.Size,
+		OrderID:          orderID,
+		Price:            bill.Price,
+		Quantity:         bill.Size,
+		Side:             side,
+		Symbol:           toGlobalSymbol(bill.InstrumentID),
+		TimeInForce:      types.TimeInForceIOC,
+		IsIsolated:       bill.MarginMode == okexapi.MarginModeIsolated,
+		UpdatedTime:      types.Time(bill.Timestamp.Time()),
+	}, nil
+}
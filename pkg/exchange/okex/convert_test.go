@@ -0,0 +1,266 @@
+package okex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/okex/okexapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_toGlobalLiquidation(t *testing.T) {
+	liquidation, err := toGlobalLiquidation(okexapi.Bill{
+		BillID:       "1001",
+		InstrumentID: "BTC-USDT",
+		MarginMode:   okexapi.MarginModeIsolated,
+		Type:         okexapi.BillTypeLiquidation,
+		SubType:      "105",
+		Price:        fixedpoint.NewFromInt(20000),
+		Size:         fixedpoint.MustNewFromString("0.1"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1001), liquidation.OrderID)
+	assert.Equal(t, "BTCUSDT", liquidation.Symbol)
+	assert.Equal(t, types.SideTypeBuy, liquidation.Side)
+	assert.True(t, liquidation.IsIsolated)
+
+	liquidation, err = toGlobalLiquidation(okexapi.Bill{
+		BillID:       "1002",
+		OrderID:      "2002",
+		InstrumentID: "BTC-USDT",
+		MarginMode:   okexapi.MarginModeCross,
+		SubType:      "104",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2002), liquidation.OrderID)
+	assert.Equal(t, types.SideTypeSell, liquidation.Side)
+	assert.False(t, liquidation.IsIsolated)
+}
+
+// the fixtures are the data of the okex v5 api responses
+
+func Test_toGlobalOrders(t *testing.T) {
+	// GET /api/v5/trade/order and GET /api/v5/trade/orders-history
+	payload := `[
+	  {
+		"instType": "SPOT",
+		"instId": "BTC-USDT",
+		"ccy": "",
+		"ordId": "312269865356374016",
+		"clOrdId": "b1",
+		"tag": "",
+		"px": "20000",
+		"sz": "0.1",
+		"pnl": "0",
+		"ordType": "limit",
+		"side": "buy",
+		"tdMode": "cash",
+		"accFillSz": "0.04",
+		"fillPx": "20000",
+		"tradeId": "143",
+		"fillSz": "0.04",
+		"fillTime": "1597026383085",
+		"state": "partially_filled",
+		"avgPx": "20000",
+		"lever": "",
+		"feeCcy": "BTC",
+		"fee": "-0.00004",
+		"rebateCcy": "USDT",
+		"rebate": "0",
+		"uTime": "1597026383085",
+		"cTime": "1597026383000"
+	  },
+	  {
+		"instType": "MARGIN",
+		"instId": "ETH-USDT",
+		"ordId": "312269865356374017",
+		"clOrdId": "",
+		"px": "1500",
+		"sz": "1",
+		"ordType": "ioc",
+		"side": "sell",
+		"tdMode": "isolated",
+		"accFillSz": "0",
+		"fillPx": "",
+		"tradeId": "",
+		"fillSz": "0",
+		"fillTime": "",
+		"state": "canceled",
+		"avgPx": "",
+		"feeCcy": "USDT",
+		"fee": "0",
+		"uTime": "1597026384000",
+		"cTime": "1597026383500"
+	  }
+	]`
+
+	var orderDetails []okexapi.OrderDetails
+	assert.NoError(t, json.Unmarshal([]byte(payload), &orderDetails))
+
+	orders, err := toGlobalOrders(orderDetails)
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.Order{
+			SubmitOrder: types.SubmitOrder{
+				ClientOrderID: "b1",
+				Symbol:        "BTCUSDT",
+				Side:          types.SideTypeBuy,
+				Type:          types.OrderTypeLimit,
+				Price:         fixedpoint.NewFromInt(20000),
+				Quantity:      fixedpoint.MustNewFromString("0.1"),
+				StopPrice:     fixedpoint.Zero,
+				TimeInForce:   types.TimeInForceGTC,
+			},
+			Exchange:         types.ExchangeOKEx,
+			OrderID:          312269865356374016,
+			Status:           types.OrderStatusPartiallyFilled,
+			ExecutedQuantity: fixedpoint.MustNewFromString("0.04"),
+			IsWorking:        true,
+			CreationTime:     types.Time(types.NewMillisecondTimestampFromInt(1597026383000).Time()),
+			UpdateTime:       types.Time(types.NewMillisecondTimestampFromInt(1597026383085).Time()),
+		}, orders[0])
+
+		assert.Equal(t, "ETHUSDT", orders[1].Symbol)
+		assert.Equal(t, types.SideTypeSell, orders[1].Side)
+		assert.Equal(t, types.OrderTypeLimit, orders[1].Type)
+		assert.Equal(t, types.TimeInForceIOC, orders[1].TimeInForce)
+		assert.Equal(t, types.OrderStatusCanceled, orders[1].Status)
+		assert.False(t, orders[1].IsWorking)
+		assert.True(t, orders[1].IsMargin)
+		assert.True(t, orders[1].IsIsolated)
+	}
+
+	// only the order details with the trade id are the trades
+	trades, _ := segmentOrderDetails(orderDetails)
+	assert.Len(t, trades, 1)
+}
+
+func Test_toGlobalTrades(t *testing.T) {
+	// the orders channel of the private websocket, the fill fee is negative when it's charged and positive when it's rebated
+	payload := `[
+	  {
+		"instType": "SPOT",
+		"instId": "BTC-USDT",
+		"ordId": "312269865356374016",
+		"clOrdId": "b1",
+		"px": "20000",
+		"sz": "0.1",
+		"ordType": "limit",
+		"side": "buy",
+		"tdMode": "cash",
+		"accFillSz": "0.04",
+		"fillPx": "20000",
+		"tradeId": "143",
+		"fillSz": "0.04",
+		"fillTime": "1597026383085",
+		"fillFee": "-0.00004",
+		"fillFeeCcy": "BTC",
+		"execType": "T",
+		"state": "partially_filled",
+		"uTime": "1597026383085",
+		"cTime": "1597026383000"
+	  },
+	  {
+		"instType": "SPOT",
+		"instId": "BTC-USDT",
+		"ordId": "312269865356374018",
+		"clOrdId": "s1",
+		"px": "21000",
+		"sz": "0.1",
+		"ordType": "post_only",
+		"side": "sell",
+		"tdMode": "cash",
+		"accFillSz": "0.1",
+		"fillPx": "21000",
+		"tradeId": "144",
+		"fillSz": "0.1",
+		"fillTime": "1597026390000",
+		"fillFee": "0.21",
+		"fillFeeCcy": "USDT",
+		"execType": "M",
+		"state": "filled",
+		"uTime": "1597026390000",
+		"cTime": "1597026383000"
+	  }
+	]`
+
+	var orderDetails []okexapi.OrderDetails
+	assert.NoError(t, json.Unmarshal([]byte(payload), &orderDetails))
+
+	trades, err := toGlobalTrades(orderDetails)
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, types.Trade{
+			ID:            143,
+			OrderID:       312269865356374016,
+			Exchange:      types.ExchangeOKEx,
+			Price:         fixedpoint.NewFromInt(20000),
+			Quantity:      fixedpoint.MustNewFromString("0.04"),
+			QuoteQuantity: fixedpoint.NewFromInt(800),
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			IsMaker:       false,
+			Time:          types.Time(types.NewMillisecondTimestampFromInt(1597026383085).Time()),
+			// the charged fee is a positive fee of the global trade
+			Fee:         fixedpoint.MustNewFromString("0.00004"),
+			FeeCurrency: "BTC",
+		}, trades[0])
+
+		// the maker rebate is a negative fee of the global trade
+		assert.True(t, trades[1].IsMaker)
+		assert.False(t, trades[1].IsBuyer)
+		assert.Equal(t, fixedpoint.MustNewFromString("-0.21"), trades[1].Fee)
+		assert.Equal(t, "USDT", trades[1].FeeCurrency)
+	}
+}
+
+func Test_toGlobalFills(t *testing.T) {
+	// GET /api/v5/trade/fills-history
+	payload := `[
+	  {
+		"instType": "MARGIN",
+		"instId": "BTC-USDT",
+		"tradeId": "123",
+		"ordId": "312269865356374016",
+		"clOrdId": "b1",
+		"billId": "1111",
+		"tag": "",
+		"fillPx": "20000",
+		"fillSz": "0.01",
+		"side": "sell",
+		"posSide": "net",
+		"execType": "M",
+		"feeCcy": "USDT",
+		"fee": "-0.16",
+		"ts": "1597026383085"
+	  }
+	]`
+
+	var fills []okexapi.Fill
+	assert.NoError(t, json.Unmarshal([]byte(payload), &fills))
+
+	trades, err := toGlobalFills(fills)
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, types.Trade{
+			ID:            123,
+			OrderID:       312269865356374016,
+			Exchange:      types.ExchangeOKEx,
+			Price:         fixedpoint.NewFromInt(20000),
+			Quantity:      fixedpoint.MustNewFromString("0.01"),
+			QuoteQuantity: fixedpoint.NewFromInt(200),
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeSell,
+			IsBuyer:       false,
+			IsMaker:       true,
+			Time:          types.Time(types.NewMillisecondTimestampFromInt(1597026383085).Time()),
+			Fee:           fixedpoint.MustNewFromString("0.16"),
+			FeeCurrency:   "USDT",
+			IsMargin:      true,
+		}, trades[0])
+	}
+}
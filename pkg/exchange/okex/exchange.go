@@ -3,6 +3,7 @@ package okex
 import (
 	"context"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 
@@ -17,6 +18,13 @@ import (
 
 var marketDataLimiter = rate.NewLimiter(rate.Every(time.Second/10), 1)
 
+// the order history archive api allows 5 requests per 2 seconds, and the fills history api allows 10 requests per 2 seconds
+var queryOrderLimiter = rate.NewLimiter(rate.Every(time.Second/2), 1)
+var queryTradeLimiter = rate.NewLimiter(rate.Every(time.Second/4), 1)
+
+// historyQueryLimit is the max number of records returned by the history apis
+const historyQueryLimit = 100
+
 // OKB is the platform currency of OKEx, pre-allocate static string here
 const OKB = "OKB"
 
@@ -25,6 +33,8 @@ var log = logrus.WithFields(logrus.Fields{
 })
 
 type Exchange struct {
+	types.MarginSettings
+
 	key, secret, passphrase string
 
 	client *okexapi.RestClient
@@ -144,6 +154,14 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 		AccountType: "SPOT",
 	}
 
+	if e.IsIsolatedMargin {
+		account.AccountType = types.AccountTypeIsolatedMargin
+		account.MarginRatio = accountBalance.MarginRatio
+	} else if e.IsMargin {
+		account.AccountType = types.AccountTypeMargin
+		account.MarginRatio = accountBalance.MarginRatio
+	}
+
 	var balanceMap = toGlobalBalance(accountBalance)
 	account.UpdateBalances(balanceMap)
 	return &account, nil
@@ -170,8 +188,13 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 		}
 
 		orderReq.InstrumentID(toLocalSymbol(order.Symbol))
+		orderReq.TradeMode(e.localTradeMode())
 		orderReq.Side(toLocalSideType(order.Side))
 
+		if len(order.ClientOrderID) > 0 && order.ClientOrderID != types.NoClientOrderID {
+			orderReq.ClientOrderID(order.ClientOrderID)
+		}
+
 		if order.Market.Symbol != "" {
 			orderReq.Quantity(order.Market.FormatQuantity(order.Quantity))
 		} else {
@@ -225,17 +248,70 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			IsWorking:        true,
 			CreationTime:     types.Time(time.Now()),
 			UpdateTime:       types.Time(time.Now()),
-			IsMargin:         false,
-			IsIsolated:       false,
+			IsMargin:         e.IsMargin,
+			IsIsolated:       e.IsIsolatedMargin,
 		})
 	}
 
 	return createdOrders, nil
 }
 
+// ReplaceOrder amends the price and the quantity of the open order in place, the order ID is kept.
+// The quantity of the new order is the remaining quantity, the new size is the executed quantity of the live order
+// plus the remaining quantity, since the given order may not include the latest fills.
+// Changing the symbol, the side or the order type is not supported by the amend endpoint.
+func (e *Exchange) ReplaceOrder(ctx context.Context, order types.Order, newOrder types.SubmitOrder) (*types.Order, *types.Order, error) {
+	if order.Symbol != newOrder.Symbol || order.Side != newOrder.Side || order.Type != newOrder.Type || order.TimeInForce != newOrder.TimeInForce {
+		return nil, nil, types.ErrOrderReplaceNotSupported
+	}
+
+	liveOrder, err := e.QueryOrder(ctx, types.OrderQuery{
+		Symbol:  order.Symbol,
+		OrderID: strconv.FormatUint(order.OrderID, 10),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !liveOrder.IsWorking {
+		return nil, nil, errors.Errorf("can not amend order %d, the order is %s", order.OrderID, liveOrder.Status)
+	}
+
+	req := e.client.TradeService.NewAmendOrderRequest()
+	req.InstrumentID(toLocalSymbol(order.Symbol))
+	req.OrderID(strconv.FormatUint(order.OrderID, 10))
+
+	newSize := liveOrder.ExecutedQuantity.Add(newOrder.Quantity)
+	if newOrder.Market.Symbol != "" {
+		req.NewQuantity(newOrder.Market.FormatQuantity(newSize))
+	} else {
+		req.NewQuantity(newSize.FormatString(8))
+	}
+
+	switch newOrder.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		if newOrder.Market.Symbol != "" {
+			req.NewPrice(newOrder.Market.FormatPrice(newOrder.Price))
+		} else {
+			req.NewPrice(newOrder.Price.FormatString(8))
+		}
+	}
+
+	if _, err := req.Do(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	amendedOrder := order
+	amendedOrder.ExecutedQuantity = liveOrder.ExecutedQuantity
+	amendedOrder.Quantity = newSize
+	amendedOrder.Price = newOrder.Price
+	amendedOrder.UpdateTime = types.Time(time.Now())
+	return nil, &amendedOrder, nil
+}
+
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
 	instrumentID := toLocalSymbol(symbol)
-	req := e.client.TradeService.NewGetPendingOrderRequest().InstrumentType(okexapi.InstrumentTypeSpot).InstrumentID(instrumentID)
+	req := e.client.TradeService.NewGetPendingOrderRequest().InstrumentType(e.instrumentType()).InstrumentID(instrumentID)
 	orderDetails, err := req.Do(ctx)
 	if err != nil {
 		return orders, err
@@ -271,8 +347,141 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) erro
 	return err
 }
 
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	if len(q.Symbol) == 0 {
+		return nil, errors.New("symbol is required for querying an okex order")
+	}
+
+	req := e.client.TradeService.NewGetOrderDetailsRequest()
+	req.InstrumentID(toLocalSymbol(q.Symbol))
+
+	if len(q.OrderID) > 0 {
+		req.OrderID(q.OrderID)
+	} else if len(q.ClientOrderID) > 0 {
+		req.ClientOrderID(q.ClientOrderID)
+	} else {
+		return nil, errors.New("either order id or client order id is required for querying an okex order")
+	}
+
+	if err := queryOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	orderDetails, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := toGlobalOrders([]okexapi.OrderDetails{*orderDetails})
+	if err != nil {
+		return nil, err
+	}
+
+	return &orders[0], nil
+}
+
+// QueryClosedOrders queries the completed orders of the last 3 months, the history api returns the orders
+// in the descending order, so all the pages of the time range are queried with the order id pagination.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	var after string
+	for {
+		req := e.client.TradeService.NewGetOrderHistoryRequest()
+		req.InstrumentType(e.instrumentType()).
+			InstrumentID(toLocalSymbol(symbol)).
+			Begin(since).
+			End(until).
+			Limit(historyQueryLimit)
+
+		if len(after) > 0 {
+			req.After(after)
+		}
+
+		if err := queryOrderLimiter.Wait(ctx); err != nil {
+			return orders, err
+		}
+
+		orderDetails, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		pageOrders, err := toGlobalOrders(orderDetails)
+		if err != nil {
+			return orders, err
+		}
+
+		orders = append(orders, pageOrders...)
+
+		if len(orderDetails) < historyQueryLimit {
+			break
+		}
+
+		after = orderDetails[len(orderDetails)-1].OrderID
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime.Time())
+	})
+
+	return orders, nil
+}
+
+// QueryTrades queries the fills of the last 3 months, the history api returns the fills in the descending order,
+// so all the pages of the time range are queried with the bill id pagination.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	var after string
+	for {
+		req := e.client.TradeService.NewGetFillsHistoryRequest()
+		req.InstrumentType(e.instrumentType()).
+			InstrumentID(toLocalSymbol(symbol)).
+			Limit(historyQueryLimit)
+
+		if options.StartTime != nil {
+			req.Begin(*options.StartTime)
+		}
+
+		if options.EndTime != nil {
+			req.End(*options.EndTime)
+		}
+
+		if len(after) > 0 {
+			req.After(after)
+		}
+
+		if err := queryTradeLimiter.Wait(ctx); err != nil {
+			return trades, err
+		}
+
+		fills, err := req.Do(ctx)
+		if err != nil {
+			return trades, err
+		}
+
+		pageTrades, err := toGlobalFills(fills)
+		if err != nil {
+			return trades, err
+		}
+
+		trades = append(trades, pageTrades...)
+
+		if len(fills) < historyQueryLimit {
+			break
+		}
+
+		after = fills[len(fills)-1].BillID
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time.Time())
+	})
+
+	return trades, nil
+}
+
 func (e *Exchange) NewStream() types.Stream {
-	return NewStream(e.client)
+	stream := NewStream(e.client)
+	stream.MarginSettings = e.MarginSettings
+	return stream
 }
 
 func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
@@ -0,0 +1,295 @@
+package okex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/exchange/okex/okexapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// the borrow and repay apis allow 5 requests per second, and the history apis allow 5 requests per 2 seconds
+var marginLimiter = rate.NewLimiter(rate.Every(time.Second/5), 1)
+var marginHistoryLimiter = rate.NewLimiter(rate.Every(time.Second/2), 1)
+
+var errIsolatedMarginBorrowNotSupported = errors.New("okex isolated margin loans are borrowed and repaid by the orders automatically")
+
+func (e *Exchange) localTradeMode() string {
+	if e.IsIsolatedMargin {
+		return string(okexapi.MarginModeIsolated)
+	} else if e.IsMargin {
+		return string(okexapi.MarginModeCross)
+	}
+
+	return "cash"
+}
+
+func (e *Exchange) instrumentType() okexapi.InstrumentType {
+	if e.IsMargin {
+		return okexapi.InstrumentTypeMargin
+	}
+
+	return okexapi.InstrumentTypeSpot
+}
+
+func (e *Exchange) marginMode() okexapi.MarginMode {
+	if e.IsIsolatedMargin {
+		return okexapi.MarginModeIsolated
+	}
+
+	return okexapi.MarginModeCross
+}
+
+func (e *Exchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (amount fixedpoint.Value, err error) {
+	if err := marginLimiter.Wait(ctx); err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	if e.IsIsolatedMargin {
+		req := e.client.AccountService.NewGetMaxLoanRequest()
+		req.InstrumentID(toLocalSymbol(e.IsolatedMarginSymbol))
+		req.MarginMode(okexapi.MarginModeIsolated)
+
+		maxLoans, err := req.Do(ctx)
+		if err != nil {
+			return fixedpoint.Zero, err
+		}
+
+		for _, maxLoan := range maxLoans {
+			if maxLoan.Currency == asset {
+				return maxLoan.MaxLoan, nil
+			}
+		}
+
+		return fixedpoint.Zero, fmt.Errorf("asset %s is not found in the max loans of %s", asset, e.IsolatedMarginSymbol)
+	}
+
+	account, err := e.client.AccountBalances()
+	if err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	for _, detail := range account.Details {
+		if detail.Currency == asset {
+			return detail.MaxLoan, nil
+		}
+	}
+
+	return fixedpoint.Zero, nil
+}
+
+func (e *Exchange) borrowRepay(ctx context.Context, side okexapi.BorrowRepaySide, asset string, amount fixedpoint.Value) error {
+	if e.IsIsolatedMargin {
+		return errIsolatedMarginBorrowNotSupported
+	}
+
+	if err := marginLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	req := e.client.AccountService.NewSpotManualBorrowRepayRequest()
+	req.Currency(asset).
+		Side(side).
+		Amount(amount.String())
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("margin %s %f %s", side, resp.Amount.Float64(), asset)
+	return nil
+}
+
+func (e *Exchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	return e.borrowRepay(ctx, okexapi.BorrowRepaySideBorrow, asset, amount)
+}
+
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	return e.borrowRepay(ctx, okexapi.BorrowRepaySideRepay, asset, amount)
+}
+
+// marginHistoryTimeRange returns the inclusive time range of the margin history query
+func marginHistoryTimeRange(startTime, endTime *time.Time) (since, until time.Time) {
+	until = time.Now()
+	if endTime != nil {
+		until = *endTime
+	}
+
+	if startTime != nil {
+		since = *startTime
+	}
+
+	return since, until
+}
+
+// queryBorrowRepayHistory queries the borrow and repay records of the time range,
+// the records are returned in the descending order, so all the pages are queried with the timestamp pagination.
+func (e *Exchange) queryBorrowRepayHistory(ctx context.Context, asset string, startTime, endTime *time.Time) ([]okexapi.BorrowRepayRecord, error) {
+	since, until := marginHistoryTimeRange(startTime, endTime)
+
+	// after and before are exclusive
+	after := until.Add(time.Millisecond)
+	var records []okexapi.BorrowRepayRecord
+	for {
+		if err := marginHistoryLimiter.Wait(ctx); err != nil {
+			return records, err
+		}
+
+		req := e.client.AccountService.NewGetSpotBorrowRepayHistoryRequest()
+		req.Currency(asset).
+			After(after).
+			Before(since.Add(-time.Millisecond)).
+			Limit(historyQueryLimit)
+
+		page, err := req.Do(ctx)
+		if err != nil {
+			return records, err
+		}
+
+		records = append(records, page...)
+		if len(page) < historyQueryLimit {
+			return records, nil
+		}
+
+		after = page[len(page)-1].Timestamp.Time()
+	}
+}
+
+// QueryLoanHistory returns the manual and the auto borrow records of the spot mode account,
+// the isolated margin loans are borrowed by the orders and there is no loan history of them.
+func (e *Exchange) QueryLoanHistory(ctx context.Context, asset string, startTime, endTime *time.Time) ([]types.MarginLoan, error) {
+	if e.IsIsolatedMargin {
+		return nil, nil
+	}
+
+	records, err := e.queryBorrowRepayHistory(ctx, asset, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var loans []types.MarginLoan
+	for _, record := range records {
+		switch record.Type {
+		case okexapi.BorrowRepayTypeManualBorrow, okexapi.BorrowRepayTypeAutoBorrow:
+			loans = append(loans, toGlobalLoan(record))
+		}
+	}
+
+	return loans, nil
+}
+
+// QueryRepayHistory returns the manual and the auto repay records of the spot mode account
+func (e *Exchange) QueryRepayHistory(ctx context.Context, asset string, startTime, endTime *time.Time) ([]types.MarginRepay, error) {
+	if e.IsIsolatedMargin {
+		return nil, nil
+	}
+
+	records, err := e.queryBorrowRepayHistory(ctx, asset, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var repays []types.MarginRepay
+	for _, record := range records {
+		switch record.Type {
+		case okexapi.BorrowRepayTypeManualRepay, okexapi.BorrowRepayTypeAutoRepay:
+			repays = append(repays, toGlobalRepay(record))
+		}
+	}
+
+	return repays, nil
+}
+
+func (e *Exchange) QueryInterestHistory(ctx context.Context, asset string, startTime, endTime *time.Time) ([]types.MarginInterest, error) {
+	since, until := marginHistoryTimeRange(startTime, endTime)
+
+	after := until.Add(time.Millisecond)
+	var interests []types.MarginInterest
+	for {
+		if err := marginHistoryLimiter.Wait(ctx); err != nil {
+			return interests, err
+		}
+
+		req := e.client.AccountService.NewGetInterestAccruedRequest()
+		req.Currency(asset).
+			MarginMode(e.marginMode()).
+			After(after).
+			Before(since.Add(-time.Millisecond)).
+			Limit(historyQueryLimit)
+
+		if e.IsIsolatedMargin {
+			req.InstrumentID(toLocalSymbol(e.IsolatedMarginSymbol))
+		}
+
+		records, err := req.Do(ctx)
+		if err != nil {
+			return interests, err
+		}
+
+		for _, record := range records {
+			interests = append(interests, toGlobalInterest(record))
+		}
+
+		if len(records) < historyQueryLimit {
+			return interests, nil
+		}
+
+		after = records[len(records)-1].Timestamp.Time()
+	}
+}
+
+// QueryLiquidationHistory queries the liquidation bills of the margin account,
+// the bills are returned in the descending order, so all the pages are queried with the bill id pagination.
+func (e *Exchange) QueryLiquidationHistory(ctx context.Context, startTime, endTime *time.Time) ([]types.MarginLiquidation, error) {
+	since, until := marginHistoryTimeRange(startTime, endTime)
+
+	var after string
+	var liquidations []types.MarginLiquidation
+	for {
+		if err := marginHistoryLimiter.Wait(ctx); err != nil {
+			return liquidations, err
+		}
+
+		req := e.client.AccountService.NewGetBillsArchiveRequest()
+		req.InstrumentType(okexapi.InstrumentTypeMargin).
+			MarginMode(e.marginMode()).
+			Type(okexapi.BillTypeLiquidation).
+			Begin(since).
+			End(until).
+			Limit(historyQueryLimit)
+
+		if len(after) > 0 {
+			req.After(after)
+		}
+
+		bills, err := req.Do(ctx)
+		if err != nil {
+			return liquidations, err
+		}
+
+		for _, bill := range bills {
+			if e.IsIsolatedMargin && bill.InstrumentID != toLocalSymbol(e.IsolatedMarginSymbol) {
+				continue
+			}
+
+			liquidation, err := toGlobalLiquidation(bill)
+			if err != nil {
+				return liquidations, err
+			}
+
+			liquidations = append(liquidations, liquidation)
+		}
+
+		if len(bills) < historyQueryLimit {
+			return liquidations, nil
+		}
+
+		after = bills[len(bills)-1].BillID
+	}
+}
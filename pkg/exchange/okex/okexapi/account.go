@@ -0,0 +1,592 @@
+package okexapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type MarginMode string
+
+const (
+	MarginModeCross    MarginMode = "cross"
+	MarginModeIsolated MarginMode = "isolated"
+)
+
+type BorrowRepaySide string
+
+const (
+	BorrowRepaySideBorrow BorrowRepaySide = "borrow"
+	BorrowRepaySideRepay  BorrowRepaySide = "repay"
+)
+
+type BorrowRepayType string
+
+const (
+	BorrowRepayTypeAutoBorrow   BorrowRepayType = "auto_borrow"
+	BorrowRepayTypeAutoRepay    BorrowRepayType = "auto_repay"
+	BorrowRepayTypeManualBorrow BorrowRepayType = "manual_borrow"
+	BorrowRepayTypeManualRepay  BorrowRepayType = "manual_repay"
+)
+
+// BillType is the type of the account bills, see https://www.okx.com/docs-v5/en/#rest-api-account-get-bills-details-last-3-months
+type BillType string
+
+const (
+	BillTypeLiquidation BillType = "5"
+)
+
+type AccountService struct {
+	client *RestClient
+}
+
+func (c *AccountService) NewSpotManualBorrowRepayRequest() *SpotManualBorrowRepayRequest {
+	return &SpotManualBorrowRepayRequest{
+		client: c.client,
+	}
+}
+
+func (c *AccountService) NewGetMaxLoanRequest() *GetMaxLoanRequest {
+	return &GetMaxLoanRequest{
+		client: c.client,
+	}
+}
+
+func (c *AccountService) NewGetSpotBorrowRepayHistoryRequest() *GetSpotBorrowRepayHistoryRequest {
+	return &GetSpotBorrowRepayHistoryRequest{
+		client: c.client,
+	}
+}
+
+func (c *AccountService) NewGetInterestAccruedRequest() *GetInterestAccruedRequest {
+	return &GetInterestAccruedRequest{
+		client: c.client,
+	}
+}
+
+func (c *AccountService) NewGetBillsArchiveRequest() *GetBillsArchiveRequest {
+	return &GetBillsArchiveRequest{
+		client: c.client,
+	}
+}
+
+type BorrowRepayResponse struct {
+	Currency string           `json:"ccy"`
+	Side     BorrowRepaySide  `json:"side"`
+	Amount   fixedpoint.Value `json:"amt"`
+}
+
+// SpotManualBorrowRepayRequest borrows or repays the currency manually,
+// it's only applicable to the spot mode account with the borrowing enabled.
+type SpotManualBorrowRepayRequest struct {
+	client *RestClient
+
+	currency string
+
+	side BorrowRepaySide
+
+	amount string
+}
+
+func (r *SpotManualBorrowRepayRequest) Currency(currency string) *SpotManualBorrowRepayRequest {
+	r.currency = currency
+	return r
+}
+
+func (r *SpotManualBorrowRepayRequest) Side(side BorrowRepaySide) *SpotManualBorrowRepayRequest {
+	r.side = side
+	return r
+}
+
+func (r *SpotManualBorrowRepayRequest) Amount(amount string) *SpotManualBorrowRepayRequest {
+	r.amount = amount
+	return r
+}
+
+func (r *SpotManualBorrowRepayRequest) Do(ctx context.Context) (*BorrowRepayResponse, error) {
+	payload := map[string]interface{}{
+		"ccy":  r.currency,
+		"side": r.side,
+		"amt":  r.amount,
+	}
+
+	req, err := r.client.newAuthenticatedRequest("POST", "/api/v5/account/spot-manual-borrow-repay", nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string                `json:"code"`
+		Message string                `json:"msg"`
+		Data    []BorrowRepayResponse `json:"data"`
+	}
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if apiResponse.Code != "0" {
+		return nil, fmt.Errorf("%s error: [%s] %s", r.side, apiResponse.Code, apiResponse.Message)
+	}
+
+	if len(apiResponse.Data) == 0 {
+		return nil, fmt.Errorf("%s error: empty response", r.side)
+	}
+
+	return &apiResponse.Data[0], nil
+}
+
+type MaxLoan struct {
+	InstrumentID   string           `json:"instId"`
+	MarginMode     MarginMode       `json:"mgnMode"`
+	MarginCurrency string           `json:"mgnCcy"`
+	MaxLoan        fixedpoint.Value `json:"maxLoan"`
+	Currency       string           `json:"ccy"`
+	Side           SideType         `json:"side"`
+}
+
+// GetMaxLoanRequest queries the max loan of the instrument, the max loans of both sides are returned
+type GetMaxLoanRequest struct {
+	client *RestClient
+
+	instId string
+
+	marginMode MarginMode
+
+	marginCurrency *string
+}
+
+func (r *GetMaxLoanRequest) InstrumentID(instId string) *GetMaxLoanRequest {
+	r.instId = instId
+	return r
+}
+
+func (r *GetMaxLoanRequest) MarginMode(marginMode MarginMode) *GetMaxLoanRequest {
+	r.marginMode = marginMode
+	return r
+}
+
+func (r *GetMaxLoanRequest) MarginCurrency(currency string) *GetMaxLoanRequest {
+	r.marginCurrency = &currency
+	return r
+}
+
+func (r *GetMaxLoanRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	values.Add("instId", r.instId)
+	values.Add("mgnMode", string(r.marginMode))
+
+	if r.marginCurrency != nil {
+		values.Add("mgnCcy", *r.marginCurrency)
+	}
+
+	return values
+}
+
+func (r *GetMaxLoanRequest) Do(ctx context.Context) ([]MaxLoan, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/account/max-loan", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string    `json:"code"`
+		Message string    `json:"msg"`
+		Data    []MaxLoan `json:"data"`
+	}
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if apiResponse.Code != "0" {
+		return nil, fmt.Errorf("max loan query error: [%s] %s", apiResponse.Code, apiResponse.Message)
+	}
+
+	return apiResponse.Data, nil
+}
+
+type BorrowRepayRecord struct {
+	Currency      string                     `json:"ccy"`
+	Type          BorrowRepayType            `json:"type"`
+	Amount        fixedpoint.Value           `json:"amt"`
+	AccumBorrowed fixedpoint.Value           `json:"accBorrowed"`
+	Timestamp     types.MillisecondTimestamp `json:"ts"`
+}
+
+// GetSpotBorrowRepayHistoryRequest queries the borrow and repay records of the spot mode account,
+// the records are returned in the descending order of the time.
+type GetSpotBorrowRepayHistoryRequest struct {
+	client *RestClient
+
+	currency *string
+
+	recordType *BorrowRepayType
+
+	// after is the timestamp, the records earlier than the timestamp are returned
+	after *time.Time
+
+	// before is the timestamp, the records newer than the timestamp are returned
+	before *time.Time
+
+	limit *int
+}
+
+func (r *GetSpotBorrowRepayHistoryRequest) Currency(currency string) *GetSpotBorrowRepayHistoryRequest {
+	r.currency = &currency
+	return r
+}
+
+func (r *GetSpotBorrowRepayHistoryRequest) Type(recordType BorrowRepayType) *GetSpotBorrowRepayHistoryRequest {
+	r.recordType = &recordType
+	return r
+}
+
+func (r *GetSpotBorrowRepayHistoryRequest) After(after time.Time) *GetSpotBorrowRepayHistoryRequest {
+	r.after = &after
+	return r
+}
+
+func (r *GetSpotBorrowRepayHistoryRequest) Before(before time.Time) *GetSpotBorrowRepayHistoryRequest {
+	r.before = &before
+	return r
+}
+
+func (r *GetSpotBorrowRepayHistoryRequest) Limit(limit int) *GetSpotBorrowRepayHistoryRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetSpotBorrowRepayHistoryRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	if r.currency != nil {
+		values.Add("ccy", *r.currency)
+	}
+
+	if r.recordType != nil {
+		values.Add("type", string(*r.recordType))
+	}
+
+	if r.after != nil {
+		values.Add("after", strconv.FormatInt(r.after.UnixMilli(), 10))
+	}
+
+	if r.before != nil {
+		values.Add("before", strconv.FormatInt(r.before.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetSpotBorrowRepayHistoryRequest) Do(ctx context.Context) ([]BorrowRepayRecord, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/account/spot-borrow-repay-history", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string              `json:"code"`
+		Message string              `json:"msg"`
+		Data    []BorrowRepayRecord `json:"data"`
+	}
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if apiResponse.Code != "0" {
+		return nil, fmt.Errorf("borrow repay history query error: [%s] %s", apiResponse.Code, apiResponse.Message)
+	}
+
+	return apiResponse.Data, nil
+}
+
+type InterestAccrued struct {
+	Currency     string                     `json:"ccy"`
+	InstrumentID string                     `json:"instId"`
+	MarginMode   MarginMode                 `json:"mgnMode"`
+	Interest     fixedpoint.Value           `json:"interest"`
+	InterestRate fixedpoint.Value           `json:"interestRate"`
+	Liability    fixedpoint.Value           `json:"liab"`
+	Timestamp    types.MillisecondTimestamp `json:"ts"`
+}
+
+// GetInterestAccruedRequest queries the accrued interest of the market loans,
+// the records are returned in the descending order of the time.
+type GetInterestAccruedRequest struct {
+	client *RestClient
+
+	currency *string
+
+	instId *string
+
+	marginMode *MarginMode
+
+	// after is the timestamp, the records earlier than the timestamp are returned
+	after *time.Time
+
+	// before is the timestamp, the records newer than the timestamp are returned
+	before *time.Time
+
+	limit *int
+}
+
+func (r *GetInterestAccruedRequest) Currency(currency string) *GetInterestAccruedRequest {
+	r.currency = &currency
+	return r
+}
+
+func (r *GetInterestAccruedRequest) InstrumentID(instId string) *GetInterestAccruedRequest {
+	r.instId = &instId
+	return r
+}
+
+func (r *GetInterestAccruedRequest) MarginMode(marginMode MarginMode) *GetInterestAccruedRequest {
+	r.marginMode = &marginMode
+	return r
+}
+
+func (r *GetInterestAccruedRequest) After(after time.Time) *GetInterestAccruedRequest {
+	r.after = &after
+	return r
+}
+
+func (r *GetInterestAccruedRequest) Before(before time.Time) *GetInterestAccruedRequest {
+	r.before = &before
+	return r
+}
+
+func (r *GetInterestAccruedRequest) Limit(limit int) *GetInterestAccruedRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetInterestAccruedRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	// type 2 is the market loans
+	values.Add("type", "2")
+
+	if r.currency != nil {
+		values.Add("ccy", *r.currency)
+	}
+
+	if r.instId != nil {
+		values.Add("instId", *r.instId)
+	}
+
+	if r.marginMode != nil {
+		values.Add("mgnMode", string(*r.marginMode))
+	}
+
+	if r.after != nil {
+		values.Add("after", strconv.FormatInt(r.after.UnixMilli(), 10))
+	}
+
+	if r.before != nil {
+		values.Add("before", strconv.FormatInt(r.before.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetInterestAccruedRequest) Do(ctx context.Context) ([]InterestAccrued, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/account/interest-accrued", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string            `json:"code"`
+		Message string            `json:"msg"`
+		Data    []InterestAccrued `json:"data"`
+	}
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if apiResponse.Code != "0" {
+		return nil, fmt.Errorf("interest accrued query error: [%s] %s", apiResponse.Code, apiResponse.Message)
+	}
+
+	return apiResponse.Data, nil
+}
+
+type Bill struct {
+	BillID         string                     `json:"billId"`
+	InstrumentType InstrumentType             `json:"instType"`
+	InstrumentID   string                     `json:"instId"`
+	Currency       string                     `json:"ccy"`
+	MarginMode     MarginMode                 `json:"mgnMode"`
+	Type           BillType                   `json:"type"`
+	SubType        string                     `json:"subType"`
+	Price          fixedpoint.Value           `json:"px"`
+	Size           fixedpoint.Value           `json:"sz"`
+	BalanceChange  fixedpoint.Value           `json:"balChg"`
+	OrderID        string                     `json:"ordId"`
+	Timestamp      types.MillisecondTimestamp `json:"ts"`
+}
+
+// GetBillsArchiveRequest queries the account bills of the last 3 months,
+// the bills are returned in the descending order of the bill id.
+type GetBillsArchiveRequest struct {
+	client *RestClient
+
+	instType *InstrumentType
+
+	currency *string
+
+	marginMode *MarginMode
+
+	billType *BillType
+
+	// after is the bill id, the records earlier than the bill id are returned
+	after *string
+
+	begin *time.Time
+
+	end *time.Time
+
+	limit *int
+}
+
+func (r *GetBillsArchiveRequest) InstrumentType(instType InstrumentType) *GetBillsArchiveRequest {
+	r.instType = &instType
+	return r
+}
+
+func (r *GetBillsArchiveRequest) Currency(currency string) *GetBillsArchiveRequest {
+	r.currency = &currency
+	return r
+}
+
+func (r *GetBillsArchiveRequest) MarginMode(marginMode MarginMode) *GetBillsArchiveRequest {
+	r.marginMode = &marginMode
+	return r
+}
+
+func (r *GetBillsArchiveRequest) Type(billType BillType) *GetBillsArchiveRequest {
+	r.billType = &billType
+	return r
+}
+
+func (r *GetBillsArchiveRequest) After(billID string) *GetBillsArchiveRequest {
+	r.after = &billID
+	return r
+}
+
+func (r *GetBillsArchiveRequest) Begin(begin time.Time) *GetBillsArchiveRequest {
+	r.begin = &begin
+	return r
+}
+
+func (r *GetBillsArchiveRequest) End(end time.Time) *GetBillsArchiveRequest {
+	r.end = &end
+	return r
+}
+
+func (r *GetBillsArchiveRequest) Limit(limit int) *GetBillsArchiveRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetBillsArchiveRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	if r.instType != nil {
+		values.Add("instType", string(*r.instType))
+	}
+
+	if r.currency != nil {
+		values.Add("ccy", *r.currency)
+	}
+
+	if r.marginMode != nil {
+		values.Add("mgnMode", string(*r.marginMode))
+	}
+
+	if r.billType != nil {
+		values.Add("type", string(*r.billType))
+	}
+
+	if r.after != nil {
+		values.Add("after", *r.after)
+	}
+
+	if r.begin != nil {
+		values.Add("begin", strconv.FormatInt(r.begin.UnixMilli(), 10))
+	}
+
+	if r.end != nil {
+		values.Add("end", strconv.FormatInt(r.end.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetBillsArchiveRequest) Do(ctx context.Context) ([]Bill, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/account/bills-archive", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string `json:"code"`
+		Message string `json:"msg"`
+		Data    []Bill `json:"data"`
+	}
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if apiResponse.Code != "0" {
+		return nil, fmt.Errorf("bills query error: [%s] %s", apiResponse.Code, apiResponse.Message)
+	}
+
+	return apiResponse.Data, nil
+}
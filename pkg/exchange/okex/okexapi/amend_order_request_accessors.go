@@ -0,0 +1,113 @@
+package okexapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+func (a *AmendOrderRequest) InstrumentID(instrumentID string) *AmendOrderRequest {
+	a.instrumentID = instrumentID
+	return a
+}
+
+func (a *AmendOrderRequest) OrderID(orderID string) *AmendOrderRequest {
+	a.orderID = &orderID
+	return a
+}
+
+func (a *AmendOrderRequest) ClientOrderID(clientOrderID string) *AmendOrderRequest {
+	a.clientOrderID = &clientOrderID
+	return a
+}
+
+func (a *AmendOrderRequest) CancelOnFail(cancelOnFail bool) *AmendOrderRequest {
+	a.cancelOnFail = &cancelOnFail
+	return a
+}
+
+func (a *AmendOrderRequest) NewQuantity(newQuantity string) *AmendOrderRequest {
+	a.newQuantity = &newQuantity
+	return a
+}
+
+func (a *AmendOrderRequest) NewPrice(newPrice string) *AmendOrderRequest {
+	a.newPrice = &newPrice
+	return a
+}
+
+func (a *AmendOrderRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	// check instrumentID field -> json key instId
+	instrumentID := a.instrumentID
+
+	// assign parameter of instrumentID
+	params["instId"] = instrumentID
+
+	// check orderID field -> json key ordId
+	if a.orderID != nil {
+		orderID := *a.orderID
+
+		// assign parameter of orderID
+		params["ordId"] = orderID
+	}
+
+	// check clientOrderID field -> json key clOrdId
+	if a.clientOrderID != nil {
+		clientOrderID := *a.clientOrderID
+
+		// assign parameter of clientOrderID
+		params["clOrdId"] = clientOrderID
+	}
+
+	// check cancelOnFail field -> json key cxlOnFail
+	if a.cancelOnFail != nil {
+		cancelOnFail := *a.cancelOnFail
+
+		// assign parameter of cancelOnFail
+		params["cxlOnFail"] = cancelOnFail
+	}
+
+	// check newQuantity field -> json key newSz
+	if a.newQuantity != nil {
+		newQuantity := *a.newQuantity
+
+		// assign parameter of newQuantity
+		params["newSz"] = newQuantity
+	}
+
+	// check newPrice field -> json key newPx
+	if a.newPrice != nil {
+		newPrice := *a.newPrice
+
+		// assign parameter of newPrice
+		params["newPx"] = newPrice
+	}
+
+	return params, nil
+}
+
+func (a *AmendOrderRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := a.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for k, v := range params {
+		query.Add(k, fmt.Sprintf("%v", v))
+	}
+
+	return query, nil
+}
+
+func (a *AmendOrderRequest) GetParametersJSON() ([]byte, error) {
+	params, err := a.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
@@ -44,6 +45,7 @@ type InstrumentType string
 
 const (
 	InstrumentTypeSpot    InstrumentType = "SPOT"
+	InstrumentTypeMargin  InstrumentType = "MARGIN"
 	InstrumentTypeSwap    InstrumentType = "SWAP"
 	InstrumentTypeFutures InstrumentType = "FUTURES"
 	InstrumentTypeOption  InstrumentType = "OPTION"
@@ -68,6 +70,7 @@ type RestClient struct {
 	TradeService      *TradeService
 	PublicDataService *PublicDataService
 	MarketDataService *MarketDataService
+	AccountService    *AccountService
 }
 
 func NewClient() *RestClient {
@@ -79,13 +82,15 @@ func NewClient() *RestClient {
 	client := &RestClient{
 		BaseURL: u,
 		client: &http.Client{
-			Timeout: defaultHTTPTimeout,
+			Timeout:   defaultHTTPTimeout,
+			Transport: ratelimit.NewTransport(Limiter, nil),
 		},
 	}
 
 	client.TradeService = &TradeService{client: client}
 	client.PublicDataService = &PublicDataService{client: client}
 	client.MarketDataService = &MarketDataService{client: client}
+	client.AccountService = &AccountService{client: client}
 	return client
 }
 
@@ -206,10 +211,16 @@ type BalanceDetail struct {
 	EquityInUSD             fixedpoint.Value           `json:"eqUsd"`
 	UpdateTime              types.MillisecondTimestamp `json:"uTime"`
 	UnrealizedProfitAndLoss fixedpoint.Value           `json:"upl"`
+
+	// margin related fields
+	Liability fixedpoint.Value `json:"liab"`
+	Interest  fixedpoint.Value `json:"interest"`
+	MaxLoan   fixedpoint.Value `json:"maxLoan"`
 }
 
 type Account struct {
 	TotalEquityInUSD fixedpoint.Value `json:"totalEq"`
+	MarginRatio      fixedpoint.Value `json:"mgnRatio"`
 	UpdateTime       string           `json:"uTime"`
 	Details          []BalanceDetail  `json:"details"`
 }
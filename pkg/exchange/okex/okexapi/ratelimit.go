@@ -0,0 +1,44 @@
+package okexapi
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ratelimit"
+)
+
+// Limiter tracks the requests of the OKEx v5 api, OKEx limits every endpoint separately and does not return the usage headers,
+// so the endpoints are registered with the rules of their own groups.
+var Limiter = ratelimit.Register(ratelimit.New("okex",
+	// the public and the market data endpoints allow 20 requests per 2 seconds for each ip
+	ratelimit.Rule{Name: "market", Limit: 18, Interval: 2 * time.Second, PathPrefix: "/api/v5/market/", DefaultWeight: 1},
+	ratelimit.Rule{Name: "public", Limit: 18, Interval: 2 * time.Second, PathPrefix: "/api/v5/public/", DefaultWeight: 1},
+
+	// the trade endpoints allow 60 requests per 2 seconds for each user
+	ratelimit.Rule{Name: "place_order", Limit: 55, Interval: 2 * time.Second, PathPrefix: "/api/v5/trade/"},
+	ratelimit.Rule{Name: "cancel_order", Limit: 55, Interval: 2 * time.Second, PathPrefix: "/api/v5/trade/"},
+	ratelimit.Rule{Name: "amend_order", Limit: 55, Interval: 2 * time.Second, PathPrefix: "/api/v5/trade/"},
+	ratelimit.Rule{Name: "query_order", Limit: 55, Interval: 2 * time.Second, PathPrefix: "/api/v5/trade/"},
+
+	// the history endpoints allow 5 or 10 requests per 2 seconds
+	ratelimit.Rule{Name: "history", Limit: 5, Interval: 2 * time.Second, PathPrefix: "/api/v5/"},
+
+	ratelimit.Rule{Name: "account", Limit: 9, Interval: 2 * time.Second, PathPrefix: "/api/v5/account/", DefaultWeight: 1},
+	ratelimit.Rule{Name: "asset", Limit: 5, Interval: time.Second, PathPrefix: "/api/v5/asset/", DefaultWeight: 1},
+))
+
+func init() {
+	Limiter.RegisterWeight("POST /api/v5/trade/order", ratelimit.Weight{"place_order": 1})
+	Limiter.RegisterWeight("POST /api/v5/trade/batch-orders", ratelimit.Weight{"place_order": 1})
+	Limiter.RegisterWeight("POST /api/v5/trade/cancel-order", ratelimit.Weight{"cancel_order": 1})
+	Limiter.RegisterWeight("POST /api/v5/trade/cancel-batch-orders", ratelimit.Weight{"cancel_order": 1})
+	Limiter.RegisterWeight("POST /api/v5/trade/amend-order", ratelimit.Weight{"amend_order": 1})
+	Limiter.RegisterWeight("GET /api/v5/trade/order", ratelimit.Weight{"query_order": 1})
+	Limiter.RegisterWeight("GET /api/v5/trade/orders-pending", ratelimit.Weight{"query_order": 1})
+
+	Limiter.RegisterWeight("GET /api/v5/trade/fills", ratelimit.Weight{"history": 1})
+	Limiter.RegisterWeight("GET /api/v5/trade/fills-history", ratelimit.Weight{"history": 1})
+	Limiter.RegisterWeight("GET /api/v5/trade/orders-history-archive", ratelimit.Weight{"history": 1})
+	Limiter.RegisterWeight("GET /api/v5/account/bills-archive", ratelimit.Weight{"history": 1, "account": 0})
+	Limiter.RegisterWeight("GET /api/v5/account/interest-accrued", ratelimit.Weight{"history": 1, "account": 0})
+	Limiter.RegisterWeight("GET /api/v5/account/spot-borrow-repay-history", ratelimit.Weight{"history": 1, "account": 0})
+}
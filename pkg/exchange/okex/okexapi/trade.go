@@ -2,8 +2,11 @@ package okexapi
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -46,6 +49,12 @@ func (c *TradeService) NewBatchCancelOrderRequest() *BatchCancelOrderRequest {
 	}
 }
 
+func (c *TradeService) NewAmendOrderRequest() *AmendOrderRequest {
+	return &AmendOrderRequest{
+		client: c.client,
+	}
+}
+
 func (c *TradeService) NewGetOrderDetailsRequest() *GetOrderDetailsRequest {
 	return &GetOrderDetailsRequest{
 		client: c.client,
@@ -64,6 +73,18 @@ func (c *TradeService) NewGetTransactionDetailsRequest() *GetTransactionDetailsR
 	}
 }
 
+func (c *TradeService) NewGetOrderHistoryRequest() *GetOrderHistoryRequest {
+	return &GetOrderHistoryRequest{
+		client: c.client,
+	}
+}
+
+func (c *TradeService) NewGetFillsHistoryRequest() *GetFillsHistoryRequest {
+	return &GetFillsHistoryRequest{
+		client: c.client,
+	}
+}
+
 //go:generate requestgen -type PlaceOrderRequest
 type PlaceOrderRequest struct {
 	client *RestClient
@@ -212,6 +233,69 @@ func (r *BatchCancelOrderRequest) Do(ctx context.Context) ([]OrderResponse, erro
 	return orderResponse.Data, nil
 }
 
+// AmendOrderRequest is the request of POST /api/v5/trade/amend-order,
+// the accessors are maintained by hand in amend_order_request_accessors.go
+type AmendOrderRequest struct {
+	client *RestClient
+
+	instrumentID  string  `param:"instId"`
+	orderID       *string `param:"ordId"`
+	clientOrderID *string `param:"clOrdId"`
+
+	// cancelOnFail cancels the order when the amendment fails
+	cancelOnFail *bool `param:"cxlOnFail"`
+
+	// newQuantity is the new total quantity of the order, it includes the executed quantity
+	newQuantity *string `param:"newSz"`
+
+	newPrice *string `param:"newPx"`
+}
+
+func (r *AmendOrderRequest) Parameters() map[string]interface{} {
+	params, _ := r.GetParameters()
+	return params
+}
+
+func (r *AmendOrderRequest) Do(ctx context.Context) (*OrderResponse, error) {
+	payload, err := r.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.clientOrderID == nil && r.orderID == nil {
+		return nil, errors.New("either orderID or clientOrderID is required for amending order")
+	}
+
+	req, err := r.client.newAuthenticatedRequest("POST", "/api/v5/trade/amend-order", nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderResponse struct {
+		Code    string          `json:"code"`
+		Message string          `json:"msg"`
+		Data    []OrderResponse `json:"data"`
+	}
+	if err := response.DecodeJSON(&orderResponse); err != nil {
+		return nil, err
+	}
+
+	if len(orderResponse.Data) == 0 {
+		return nil, fmt.Errorf("order amend error: %s %s", orderResponse.Code, orderResponse.Message)
+	}
+
+	if data := orderResponse.Data[0]; data.Code != "0" {
+		return nil, fmt.Errorf("order amend error: %s %s", data.Code, data.Message)
+	}
+
+	return &orderResponse.Data[0], nil
+}
+
 type BatchPlaceOrderRequest struct {
 	client *RestClient
 
@@ -256,6 +340,7 @@ func (r *BatchPlaceOrderRequest) Do(ctx context.Context) ([]OrderResponse, error
 type OrderDetails struct {
 	InstrumentType string           `json:"instType"`
 	InstrumentID   string           `json:"instId"`
+	TradeMode      string           `json:"tdMode"`
 	Tag            string           `json:"tag"`
 	Price          fixedpoint.Value `json:"px"`
 	Quantity       fixedpoint.Value `json:"sz"`
@@ -518,3 +603,241 @@ func (r *GetTransactionDetailsRequest) Do(ctx context.Context) ([]OrderDetails,
 
 	return orderResponse.Data, nil
 }
+
+// GetOrderHistoryRequest queries the completed orders of the last 3 months,
+// the orders are returned in the descending order of the creation time.
+type GetOrderHistoryRequest struct {
+	client *RestClient
+
+	instType InstrumentType
+
+	instId *string
+
+	state *OrderState
+
+	// after is the order id, the records earlier than the order id are returned
+	after *string
+
+	begin *time.Time
+
+	end *time.Time
+
+	limit *int
+}
+
+func (r *GetOrderHistoryRequest) InstrumentType(instType InstrumentType) *GetOrderHistoryRequest {
+	r.instType = instType
+	return r
+}
+
+func (r *GetOrderHistoryRequest) InstrumentID(instId string) *GetOrderHistoryRequest {
+	r.instId = &instId
+	return r
+}
+
+func (r *GetOrderHistoryRequest) State(state OrderState) *GetOrderHistoryRequest {
+	r.state = &state
+	return r
+}
+
+func (r *GetOrderHistoryRequest) After(orderID string) *GetOrderHistoryRequest {
+	r.after = &orderID
+	return r
+}
+
+func (r *GetOrderHistoryRequest) Begin(begin time.Time) *GetOrderHistoryRequest {
+	r.begin = &begin
+	return r
+}
+
+func (r *GetOrderHistoryRequest) End(end time.Time) *GetOrderHistoryRequest {
+	r.end = &end
+	return r
+}
+
+func (r *GetOrderHistoryRequest) Limit(limit int) *GetOrderHistoryRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetOrderHistoryRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	values.Add("instType", string(r.instType))
+
+	if r.instId != nil {
+		values.Add("instId", *r.instId)
+	}
+
+	if r.state != nil {
+		values.Add("state", string(*r.state))
+	}
+
+	if r.after != nil {
+		values.Add("after", *r.after)
+	}
+
+	if r.begin != nil {
+		values.Add("begin", strconv.FormatInt(r.begin.UnixMilli(), 10))
+	}
+
+	if r.end != nil {
+		values.Add("end", strconv.FormatInt(r.end.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetOrderHistoryRequest) Do(ctx context.Context) ([]OrderDetails, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/orders-history-archive", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderResponse struct {
+		Code    string         `json:"code"`
+		Message string         `json:"msg"`
+		Data    []OrderDetails `json:"data"`
+	}
+	if err := response.DecodeJSON(&orderResponse); err != nil {
+		return nil, err
+	}
+
+	if orderResponse.Code != "0" {
+		return nil, fmt.Errorf("order history query error: [%s] %s", orderResponse.Code, orderResponse.Message)
+	}
+
+	return orderResponse.Data, nil
+}
+
+type Fill struct {
+	InstrumentType InstrumentType             `json:"instType"`
+	InstrumentID   string                     `json:"instId"`
+	TradeID        string                     `json:"tradeId"`
+	OrderID        string                     `json:"ordId"`
+	ClientOrderID  string                     `json:"clOrdId"`
+	BillID         string                     `json:"billId"`
+	Tag            string                     `json:"tag"`
+	FillPrice      fixedpoint.Value           `json:"fillPx"`
+	FillSize       fixedpoint.Value           `json:"fillSz"`
+	Side           SideType                   `json:"side"`
+	ExecutionType  string                     `json:"execType"`
+	FeeCurrency    string                     `json:"feeCcy"`
+	Fee            fixedpoint.Value           `json:"fee"`
+	Timestamp      types.MillisecondTimestamp `json:"ts"`
+}
+
+// GetFillsHistoryRequest queries the transaction details of the last 3 months,
+// the fills are returned in the descending order of the bill id.
+type GetFillsHistoryRequest struct {
+	client *RestClient
+
+	instType InstrumentType
+
+	instId *string
+
+	// after is the bill id, the records earlier than the bill id are returned
+	after *string
+
+	begin *time.Time
+
+	end *time.Time
+
+	limit *int
+}
+
+func (r *GetFillsHistoryRequest) InstrumentType(instType InstrumentType) *GetFillsHistoryRequest {
+	r.instType = instType
+	return r
+}
+
+func (r *GetFillsHistoryRequest) InstrumentID(instId string) *GetFillsHistoryRequest {
+	r.instId = &instId
+	return r
+}
+
+func (r *GetFillsHistoryRequest) After(billID string) *GetFillsHistoryRequest {
+	r.after = &billID
+	return r
+}
+
+func (r *GetFillsHistoryRequest) Begin(begin time.Time) *GetFillsHistoryRequest {
+	r.begin = &begin
+	return r
+}
+
+func (r *GetFillsHistoryRequest) End(end time.Time) *GetFillsHistoryRequest {
+	r.end = &end
+	return r
+}
+
+func (r *GetFillsHistoryRequest) Limit(limit int) *GetFillsHistoryRequest {
+	r.limit = &limit
+	return r
+}
+
+func (r *GetFillsHistoryRequest) QueryParameters() url.Values {
+	var values = url.Values{}
+
+	values.Add("instType", string(r.instType))
+
+	if r.instId != nil {
+		values.Add("instId", *r.instId)
+	}
+
+	if r.after != nil {
+		values.Add("after", *r.after)
+	}
+
+	if r.begin != nil {
+		values.Add("begin", strconv.FormatInt(r.begin.UnixMilli(), 10))
+	}
+
+	if r.end != nil {
+		values.Add("end", strconv.FormatInt(r.end.UnixMilli(), 10))
+	}
+
+	if r.limit != nil {
+		values.Add("limit", strconv.Itoa(*r.limit))
+	}
+
+	return values
+}
+
+func (r *GetFillsHistoryRequest) Do(ctx context.Context) ([]Fill, error) {
+	params := r.QueryParameters()
+	req, err := r.client.newAuthenticatedRequest("GET", "/api/v5/trade/fills-history", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var fillResponse struct {
+		Code    string `json:"code"`
+		Message string `json:"msg"`
+		Data    []Fill `json:"data"`
+	}
+	if err := response.DecodeJSON(&fillResponse); err != nil {
+		return nil, err
+	}
+
+	if fillResponse.Code != "0" {
+		return nil, fmt.Errorf("fills history query error: [%s] %s", fillResponse.Code, fillResponse.Message)
+	}
+
+	return fillResponse.Data, nil
+}
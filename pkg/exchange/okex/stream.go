@@ -24,6 +24,7 @@ type WebsocketLogin struct {
 //go:generate callbackgen -type Stream -interface
 type Stream struct {
 	types.StandardStream
+	types.MarginSettings
 
 	client *okexapi.RestClient
 
@@ -120,7 +121,7 @@ func (s *Stream) handleEvent(event WebSocketEvent) {
 		if event.Code == "0" {
 			var subs = []WebsocketSubscription{
 				{Channel: "account"},
-				{Channel: "orders", InstrumentType: string(okexapi.InstrumentTypeSpot)},
+				{Channel: "orders", InstrumentType: string(s.instrumentType())},
 			}
 
 			log.Infof("subscribing private channels: %+v", subs)
@@ -158,6 +159,14 @@ func (s *Stream) handleOrderDetailsEvent(orderDetails []okexapi.OrderDetails) {
 	}
 }
 
+func (s *Stream) instrumentType() okexapi.InstrumentType {
+	if s.IsMargin {
+		return okexapi.InstrumentTypeMargin
+	}
+
+	return okexapi.InstrumentTypeSpot
+}
+
 func (s *Stream) handleAccountEvent(account okexapi.Account) {
 	balances := toGlobalBalance(&account)
 	s.EmitBalanceSnapshot(balances)
@@ -0,0 +1,449 @@
+package paper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "paper")
+
+// DefaultTickerPollInterval is the interval of polling the ticker of the symbols with open orders,
+// it feeds the matching engine when the market data stream of the exchange does not support the book ticker
+// or the market trade channel.
+const DefaultTickerPollInterval = 5 * time.Second
+
+var ErrOrderTypeNotSupported = errors.New("order type is not supported by paper trading")
+
+var ErrOrderNotFound = errors.New("order not found")
+
+// State is the paper trading account state stored in the persistence service
+type State struct {
+	Balances    types.BalanceMap `json:"balances"`
+	OpenOrders  []types.Order    `json:"openOrders,omitempty"`
+	LastOrderID uint64           `json:"lastOrderID"`
+	LastTradeID uint64           `json:"lastTradeID"`
+}
+
+// quote is the latest market data of a symbol
+type quote struct {
+	bid, ask, last fixedpoint.Value
+}
+
+// Exchange is the paper trading exchange.
+//
+// The market data APIs and the public streams are served by the real exchange, while the orders are matched
+// by the internal matching engine against the live book tickers and market trades of the real exchange,
+// and the balances are virtual. The private APIs of the real exchange are never called.
+//
+// Taker orders are filled at the best bid or ask price without slippage, resting orders are filled
+// at the order price when the opposite side of the book crosses the order price, or when the market trades
+// through the order price.
+type Exchange struct {
+	types.Exchange
+
+	// TickerPollInterval is the interval of polling the ticker of the symbols with open orders
+	TickerPollInterval time.Duration
+
+	mu sync.Mutex
+
+	account      *types.Account
+	markets      types.MarketMap
+	quotes       map[string]*quote
+	openOrders   []*types.Order
+	closedOrders map[uint64]types.Order
+	trades       []types.Trade
+	lastOrderID  uint64
+	lastTradeID  uint64
+
+	store service.Store
+
+	// saveMu serializes the saves of the state, the store is written outside mu
+	saveMu sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	feeds  map[string]types.Stream
+
+	userDataStreams map[*Stream]struct{}
+}
+
+// NewExchange creates the paper trading exchange of the given public exchange with the initial virtual balances,
+// the default fee rates of the exchange are used when the given fee rates are zero.
+func NewExchange(ex types.Exchange, balances map[string]fixedpoint.Value, makerFeeRate, takerFeeRate fixedpoint.Value) *Exchange {
+	if feeRateProvider, ok := ex.(types.ExchangeDefaultFeeRates); ok {
+		defaultFeeRates := feeRateProvider.DefaultFeeRates()
+		if makerFeeRate.IsZero() {
+			makerFeeRate = defaultFeeRates.MakerFeeRate
+		}
+		if takerFeeRate.IsZero() {
+			takerFeeRate = defaultFeeRates.TakerFeeRate
+		}
+	}
+
+	account := types.NewAccount()
+	account.MakerFeeRate = makerFeeRate
+	account.TakerFeeRate = takerFeeRate
+	for currency, amount := range balances {
+		account.AddBalance(currency, amount)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Exchange{
+		Exchange:           ex,
+		TickerPollInterval: DefaultTickerPollInterval,
+		account:            account,
+		quotes:             make(map[string]*quote),
+		closedOrders:       make(map[uint64]types.Order),
+		ctx:                ctx,
+		cancel:             cancel,
+		feeds:              make(map[string]types.Stream),
+		userDataStreams:    make(map[*Stream]struct{}),
+	}
+}
+
+// Close stops the market data feeds of the matching engine
+func (e *Exchange) Close() error {
+	e.cancel()
+
+	e.mu.Lock()
+	feeds := e.feeds
+	e.feeds = make(map[string]types.Stream)
+	e.mu.Unlock()
+
+	for _, feed := range feeds {
+		if err := feed.Close(); err != nil {
+			log.WithError(err).Warnf("can not close the market data feed")
+		}
+	}
+	return nil
+}
+
+func (e *Exchange) DefaultFeeRates() types.ExchangeFee {
+	return types.ExchangeFee{
+		MakerFeeRate: e.account.MakerFeeRate,
+		TakerFeeRate: e.account.TakerFeeRate,
+	}
+}
+
+// LoadState loads the virtual balances and the open orders from the store,
+// and the state is saved to the store whenever it's changed.
+// If the state does not exist in the store, the initial balances are saved.
+func (e *Exchange) LoadState(store service.Store) error {
+	var state State
+	if err := store.Load(&state); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return err
+		}
+
+		e.mu.Lock()
+		e.store = store
+		e.mu.Unlock()
+		return e.saveState()
+	}
+
+	e.mu.Lock()
+	e.store = store
+	e.account.UpdateBalances(state.Balances)
+	e.lastOrderID = state.LastOrderID
+	e.lastTradeID = state.LastTradeID
+	e.openOrders = nil
+	for i := range state.OpenOrders {
+		o := state.OpenOrders[i]
+		e.openOrders = append(e.openOrders, &o)
+	}
+	e.mu.Unlock()
+
+	for _, o := range state.OpenOrders {
+		e.startFeed(o.Symbol)
+	}
+
+	return nil
+}
+
+// saveState writes a copy of the state to the store, it must be called without holding mu.
+// The copy is taken under saveMu so that a newer state is never overwritten by an older one.
+func (e *Exchange) saveState() error {
+	e.saveMu.Lock()
+	defer e.saveMu.Unlock()
+
+	e.mu.Lock()
+	if e.store == nil {
+		e.mu.Unlock()
+		return nil
+	}
+
+	store := e.store
+	state := State{
+		Balances:    e.account.Balances(),
+		LastOrderID: e.lastOrderID,
+		LastTradeID: e.lastTradeID,
+	}
+	for _, o := range e.openOrders {
+		state.OpenOrders = append(state.OpenOrders, *o)
+	}
+	e.mu.Unlock()
+
+	return store.Save(state)
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return &Stream{
+		StandardStream: types.NewStandardStream(),
+		exchange:       e,
+	}
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account := types.NewAccount()
+	account.MakerFeeRate = e.account.MakerFeeRate
+	account.TakerFeeRate = e.account.TakerFeeRate
+	account.UpdateBalances(e.account.Balances())
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.account.Balances(), nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, o := range orders {
+		market, err := e.market(ctx, o.Symbol)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		if err := e.loadQuote(ctx, o.Symbol); err != nil {
+			return createdOrders, err
+		}
+
+		e.mu.Lock()
+		order, events, err := e.placeOrder(o, market)
+		e.mu.Unlock()
+
+		e.dispatch(events)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		createdOrders = append(createdOrders, *order)
+		e.startFeed(o.Symbol)
+	}
+
+	if err := e.saveState(); err != nil {
+		log.WithError(err).Errorf("can not save the paper trading state")
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			orders = append(orders, *o)
+		}
+	}
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	var err error
+
+	for _, o := range orders {
+		e.mu.Lock()
+		events, err2 := e.cancelOrder(o)
+		e.mu.Unlock()
+
+		e.dispatch(events)
+		if err2 != nil {
+			err = err2
+		}
+	}
+
+	if err2 := e.saveState(); err2 != nil {
+		log.WithError(err2).Errorf("can not save the paper trading state")
+	}
+
+	return err
+}
+
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.openOrders {
+		if matchOrderQuery(*o, q) {
+			order := *o
+			return &order, nil
+		}
+	}
+
+	for _, o := range e.closedOrders {
+		if matchOrderQuery(o, q) {
+			return &o, nil
+		}
+	}
+
+	return nil, ErrOrderNotFound
+}
+
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.closedOrders {
+		if o.Symbol != symbol || o.OrderID <= lastOrderID {
+			continue
+		}
+
+		t := o.CreationTime.Time()
+		if t.Before(since) || t.After(until) {
+			continue
+		}
+
+		orders = append(orders, o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OrderID < orders[j].OrderID
+	})
+	return orders, nil
+}
+
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, t := range e.trades {
+		if t.Symbol != symbol {
+			continue
+		}
+
+		if options != nil {
+			if options.LastTradeID > 0 && t.ID <= options.LastTradeID {
+				continue
+			}
+			if options.StartTime != nil && t.Time.Time().Before(*options.StartTime) {
+				continue
+			}
+			if options.EndTime != nil && t.Time.Time().After(*options.EndTime) {
+				continue
+			}
+		}
+
+		trades = append(trades, t)
+	}
+
+	if options != nil && options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) market(ctx context.Context, symbol string) (types.Market, error) {
+	e.mu.Lock()
+	markets := e.markets
+	e.mu.Unlock()
+
+	if markets == nil {
+		var err error
+		markets, err = e.Exchange.QueryMarkets(ctx)
+		if err != nil {
+			return types.Market{}, err
+		}
+
+		e.mu.Lock()
+		e.markets = markets
+		e.mu.Unlock()
+	}
+
+	market, ok := markets[symbol]
+	if !ok {
+		return market, fmt.Errorf("market %s is not defined", symbol)
+	}
+
+	return market, nil
+}
+
+// loadQuote queries the ticker of the symbol if the market data is not received yet
+func (e *Exchange) loadQuote(ctx context.Context, symbol string) error {
+	e.mu.Lock()
+	_, ok := e.quotes[symbol]
+	e.mu.Unlock()
+
+	if ok {
+		return nil
+	}
+
+	ticker, err := e.Exchange.QueryTicker(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	e.handleTicker(symbol, *ticker)
+	return nil
+}
+
+func (e *Exchange) dispatch(events []interface{}) {
+	if len(events) == 0 {
+		return
+	}
+
+	e.mu.Lock()
+	var streams []*Stream
+	for s := range e.userDataStreams {
+		streams = append(streams, s)
+	}
+	e.mu.Unlock()
+
+	for _, s := range streams {
+		for _, event := range events {
+			switch event := event.(type) {
+			case types.Order:
+				s.EmitOrderUpdate(event)
+			case types.Trade:
+				s.EmitTradeUpdate(event)
+			case types.BalanceMap:
+				s.EmitBalanceUpdate(event)
+			}
+		}
+	}
+}
+
+func (e *Exchange) addUserDataStream(s *Stream) {
+	e.mu.Lock()
+	e.userDataStreams[s] = struct{}{}
+	e.mu.Unlock()
+}
+
+func (e *Exchange) removeUserDataStream(s *Stream) {
+	e.mu.Lock()
+	delete(e.userDataStreams, s)
+	e.mu.Unlock()
+}
+
+func matchOrderQuery(o types.Order, q types.OrderQuery) bool {
+	if q.Symbol != "" && o.Symbol != q.Symbol {
+		return false
+	}
+
+	if q.ClientOrderID != "" {
+		return o.ClientOrderID == q.ClientOrderID
+	}
+
+	return strconv.FormatUint(o.OrderID, 10) == q.OrderID
+}
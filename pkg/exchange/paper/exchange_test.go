@@ -0,0 +1,259 @@
+package paper
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testStream struct {
+	types.StandardStream
+}
+
+func (s *testStream) Connect(ctx context.Context) error {
+	s.EmitConnect()
+	s.EmitStart()
+	return nil
+}
+
+func (s *testStream) Close() error {
+	return nil
+}
+
+// testExchange is the public exchange with the fixed ticker, the feeds of the created streams are emitted by the tests
+type testExchange struct {
+	types.Exchange
+
+	mu      sync.Mutex
+	streams []*testStream
+}
+
+func (e *testExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *testExchange) NewStream() types.Stream {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stream := &testStream{StandardStream: types.NewStandardStream()}
+	e.streams = append(e.streams, stream)
+	return stream
+}
+
+func (e *testExchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	return types.MarketMap{
+		"BTCUSDT": {
+			Symbol:        "BTCUSDT",
+			BaseCurrency:  "BTC",
+			QuoteCurrency: "USDT",
+			MinQuantity:   fixedpoint.NewFromFloat(0.0001),
+			MinNotional:   fixedpoint.NewFromInt(10),
+		},
+	}, nil
+}
+
+func (e *testExchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return &types.Ticker{
+		Buy:  fixedpoint.NewFromInt(19990),
+		Sell: fixedpoint.NewFromInt(20000),
+		Last: fixedpoint.NewFromInt(20000),
+	}, nil
+}
+
+func (e *testExchange) emitBookTicker(bookTicker types.BookTicker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, s := range e.streams {
+		s.EmitBookTickerUpdate(bookTicker)
+	}
+}
+
+func (e *testExchange) emitMarketTrade(trade types.Trade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, s := range e.streams {
+		s.EmitMarketTrade(trade)
+	}
+}
+
+func newTestExchange() (*Exchange, *testExchange) {
+	public := &testExchange{}
+	ex := NewExchange(public, map[string]fixedpoint.Value{
+		"USDT": fixedpoint.NewFromInt(10000),
+	}, fixedpoint.NewFromFloat(0.001), fixedpoint.NewFromFloat(0.001))
+	ex.TickerPollInterval = 0
+	return ex, public
+}
+
+func TestExchange_TakerOrder(t *testing.T) {
+	ctx := context.Background()
+	ex, _ := newTestExchange()
+	defer ex.Close()
+
+	var trades []types.Trade
+	var orders []types.Order
+	stream := ex.NewStream()
+	stream.OnTradeUpdate(func(trade types.Trade) { trades = append(trades, trade) })
+	stream.OnOrderUpdate(func(order types.Order) { orders = append(orders, order) })
+	assert.NoError(t, stream.Connect(ctx))
+
+	// the market buy order is filled at the ask price of the ticker
+	createdOrders, err := ex.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(0.1),
+	})
+	if assert.NoError(t, err) && assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, types.OrderStatusFilled, createdOrders[0].Status)
+		assert.Equal(t, types.ExchangeBinance, createdOrders[0].Exchange)
+	}
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "20000", trades[0].Price.String())
+		assert.Equal(t, "0.0001", trades[0].Fee.String())
+		assert.Equal(t, "BTC", trades[0].FeeCurrency)
+		assert.False(t, trades[0].IsMaker)
+	}
+
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.OrderStatusNew, orders[0].Status)
+		assert.Equal(t, types.OrderStatusFilled, orders[1].Status)
+	}
+
+	balances, err := ex.QueryAccountBalances(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "8000", balances["USDT"].Available.String())
+		assert.Equal(t, "0.0999", balances["BTC"].Available.String())
+	}
+
+	// the limit maker order is rejected when it crosses the best price
+	_, err = ex.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimitMaker,
+		Price:    fixedpoint.NewFromInt(19000),
+		Quantity: fixedpoint.NewFromFloat(0.05),
+	})
+	assert.Error(t, err)
+
+	_, err = ex.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:    "BTCUSDT",
+		Side:      types.SideTypeSell,
+		Type:      types.OrderTypeStopLimit,
+		Price:     fixedpoint.NewFromInt(19000),
+		StopPrice: fixedpoint.NewFromInt(19000),
+		Quantity:  fixedpoint.NewFromFloat(0.05),
+	})
+	assert.ErrorIs(t, err, ErrOrderTypeNotSupported)
+}
+
+func TestExchange_MakerOrder(t *testing.T) {
+	ctx := context.Background()
+	ex, public := newTestExchange()
+	defer ex.Close()
+
+	buy := types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.NewFromInt(19900),
+		Quantity: fixedpoint.NewFromFloat(0.2),
+	}
+	createdOrders, err := ex.SubmitOrders(ctx, buy, buy)
+	if !assert.NoError(t, err) || !assert.Len(t, createdOrders, 2) {
+		return
+	}
+	assert.Equal(t, types.OrderStatusNew, createdOrders[0].Status)
+
+	usdt, _ := ex.account.Balance("USDT")
+	assert.Equal(t, "7960", usdt.Locked.String())
+
+	// the trade at the order price fills the earlier order first
+	public.emitMarketTrade(types.Trade{Symbol: "BTCUSDT", Price: fixedpoint.NewFromInt(19900), Quantity: fixedpoint.NewFromFloat(0.3)})
+
+	first, err := ex.QueryOrder(ctx, types.OrderQuery{Symbol: "BTCUSDT", OrderID: "1"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusFilled, first.Status)
+	}
+
+	second, err := ex.QueryOrder(ctx, types.OrderQuery{Symbol: "BTCUSDT", OrderID: "2"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.OrderStatusPartiallyFilled, second.Status)
+		assert.Equal(t, "0.1", second.ExecutedQuantity.String())
+	}
+
+	// the ask price crosses the order price, the rest of the order is filled at the order price
+	public.emitBookTicker(types.BookTicker{Symbol: "BTCUSDT", Buy: fixedpoint.NewFromInt(19800), Sell: fixedpoint.NewFromInt(19850)})
+
+	openOrders, err := ex.QueryOpenOrders(ctx, "BTCUSDT")
+	if assert.NoError(t, err) {
+		assert.Empty(t, openOrders)
+	}
+
+	trades, err := ex.QueryTrades(ctx, "BTCUSDT", &types.TradeQueryOptions{})
+	if assert.NoError(t, err) && assert.Len(t, trades, 3) {
+		assert.Equal(t, "19900", trades[2].Price.String())
+		assert.True(t, trades[2].IsMaker)
+	}
+
+	usdt, _ = ex.account.Balance("USDT")
+	assert.Equal(t, "0", usdt.Locked.String())
+	assert.Equal(t, "2040", usdt.Available.String())
+
+	// the canceled order unlocks the balance
+	buy.Price = fixedpoint.NewFromInt(19000)
+	buy.Quantity = fixedpoint.NewFromFloat(0.1)
+	createdOrders, err = ex.SubmitOrders(ctx, buy)
+	if assert.NoError(t, err) {
+		assert.NoError(t, ex.CancelOrders(ctx, createdOrders...))
+	}
+
+	usdt, _ = ex.account.Balance("USDT")
+	assert.Equal(t, "0", usdt.Locked.String())
+	assert.Equal(t, "2040", usdt.Available.String())
+}
+
+func TestExchange_LoadState(t *testing.T) {
+	ctx := context.Background()
+	store := service.NewMemoryService().NewStore("paper-trade", "binance")
+
+	ex, _ := newTestExchange()
+	defer ex.Close()
+	assert.NoError(t, ex.LoadState(store))
+
+	_, err := ex.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.NewFromInt(19000),
+		Quantity: fixedpoint.NewFromFloat(0.1),
+	})
+	assert.NoError(t, err)
+
+	// the restarted exchange restores the balances and the open orders
+	ex2, public2 := newTestExchange()
+	defer ex2.Close()
+	assert.NoError(t, ex2.LoadState(store))
+
+	usdt, _ := ex2.account.Balance("USDT")
+	assert.Equal(t, "1900", usdt.Locked.String())
+	assert.Equal(t, "8100", usdt.Available.String())
+
+	public2.emitBookTicker(types.BookTicker{Symbol: "BTCUSDT", Buy: fixedpoint.NewFromInt(18900), Sell: fixedpoint.NewFromInt(18950)})
+
+	btc, _ := ex2.account.Balance("BTC")
+	assert.Equal(t, "0.0999", btc.Available.String())
+
+	var state State
+	assert.NoError(t, store.Load(&state))
+	assert.Empty(t, state.OpenOrders)
+	assert.Equal(t, uint64(1), state.LastTradeID)
+}
@@ -0,0 +1,323 @@
+package paper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// placeOrder creates the order and fills it immediately if it crosses the best price,
+// it returns the order and the user data events to dispatch.
+func (e *Exchange) placeOrder(o types.SubmitOrder, market types.Market) (*types.Order, []interface{}, error) {
+	switch o.Type {
+	case types.OrderTypeMarket, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrOrderTypeNotSupported, o.Type)
+	}
+
+	q := e.quotes[o.Symbol]
+	bestPrice := q.bestPrice(o.Side)
+
+	price := o.Price
+	if o.Type == types.OrderTypeMarket {
+		if bestPrice.IsZero() {
+			return nil, nil, fmt.Errorf("can not place market order, the price of %s is not loaded yet", o.Symbol)
+		}
+		price = bestPrice
+	}
+
+	isTaker := o.Type == types.OrderTypeMarket || isCrossingPrice(o.Side, o.Price, bestPrice)
+	if o.Type == types.OrderTypeLimitMaker && isTaker {
+		return nil, nil, fmt.Errorf("limit maker order would immediately match and take, best price %s, order: %+v", bestPrice.String(), o)
+	}
+
+	if o.Quantity.Compare(market.MinQuantity) < 0 {
+		return nil, nil, fmt.Errorf("order quantity %s is less than minQuantity %s, order: %+v", o.Quantity.String(), market.MinQuantity.String(), o)
+	}
+
+	quoteQuantity := o.Quantity.Mul(price)
+	if quoteQuantity.Compare(market.MinNotional) < 0 {
+		return nil, nil, fmt.Errorf("order amount %s is less than minNotional %s, order: %+v", quoteQuantity.String(), market.MinNotional.String(), o)
+	}
+
+	switch o.Side {
+	case types.SideTypeBuy:
+		if err := e.account.LockBalance(market.QuoteCurrency, quoteQuantity); err != nil {
+			return nil, nil, err
+		}
+
+	case types.SideTypeSell:
+		if err := e.account.LockBalance(market.BaseCurrency, o.Quantity); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	e.lastOrderID++
+
+	o.Market = market
+	o.Price = price
+	order := &types.Order{
+		SubmitOrder:      o,
+		Exchange:         e.Name(),
+		OrderID:          e.lastOrderID,
+		Status:           types.OrderStatusNew,
+		ExecutedQuantity: fixedpoint.Zero,
+		IsWorking:        true,
+		CreationTime:     types.Time(now),
+		UpdateTime:       types.Time(now),
+	}
+
+	events := []interface{}{e.account.Balances(), *order}
+
+	if isTaker {
+		events = append(events, e.fill(order, bestPrice, order.Quantity, false)...)
+	}
+
+	if order.Status == types.OrderStatusFilled {
+		e.closedOrders[order.OrderID] = *order
+	} else {
+		e.openOrders = append(e.openOrders, order)
+	}
+
+	return order, events, nil
+}
+
+func (e *Exchange) cancelOrder(o types.Order) ([]interface{}, error) {
+	for i, order := range e.openOrders {
+		if order.OrderID != o.OrderID {
+			continue
+		}
+
+		e.openOrders = append(e.openOrders[:i], e.openOrders[i+1:]...)
+
+		market := order.Market
+		remaining := order.Quantity.Sub(order.ExecutedQuantity)
+		switch order.Side {
+		case types.SideTypeBuy:
+			if err := e.account.UnlockBalance(market.QuoteCurrency, order.Price.Mul(remaining)); err != nil {
+				return nil, err
+			}
+
+		case types.SideTypeSell:
+			if err := e.account.UnlockBalance(market.BaseCurrency, remaining); err != nil {
+				return nil, err
+			}
+		}
+
+		order.Status = types.OrderStatusCanceled
+		order.IsWorking = false
+		order.UpdateTime = types.Time(time.Now())
+		e.closedOrders[order.OrderID] = *order
+		return []interface{}{*order, e.account.Balances()}, nil
+	}
+
+	return nil, fmt.Errorf("cancel order failed, order %d not found: %w", o.OrderID, ErrOrderNotFound)
+}
+
+// fill executes the quantity of the order at the given price, the fee is deducted from the received asset
+func (e *Exchange) fill(order *types.Order, price, quantity fixedpoint.Value, isMaker bool) []interface{} {
+	market := order.Market
+	feeRate := e.account.TakerFeeRate
+	if isMaker {
+		feeRate = e.account.MakerFeeRate
+	}
+
+	quoteQuantity := quantity.Mul(price)
+
+	var fee fixedpoint.Value
+	var feeCurrency string
+	var err error
+	switch order.Side {
+	case types.SideTypeBuy:
+		fee = quantity.Mul(feeRate)
+		feeCurrency = market.BaseCurrency
+
+		// the buy order locked the balance by the order price, release the price difference
+		err = e.account.UseLockedBalance(market.QuoteCurrency, quoteQuantity)
+		if diff := order.Price.Sub(price).Mul(quantity); err == nil && diff.Sign() > 0 {
+			err = e.account.UnlockBalance(market.QuoteCurrency, diff)
+		}
+		e.account.AddBalance(market.BaseCurrency, quantity.Sub(fee))
+
+	case types.SideTypeSell:
+		fee = quoteQuantity.Mul(feeRate)
+		feeCurrency = market.QuoteCurrency
+
+		err = e.account.UseLockedBalance(market.BaseCurrency, quantity)
+		e.account.AddBalance(market.QuoteCurrency, quoteQuantity.Sub(fee))
+	}
+
+	if err != nil {
+		log.WithError(err).Errorf("can not settle the balance of order %d", order.OrderID)
+	}
+
+	now := time.Now()
+	order.ExecutedQuantity = order.ExecutedQuantity.Add(quantity)
+	order.UpdateTime = types.Time(now)
+	if order.ExecutedQuantity.Compare(order.Quantity) >= 0 {
+		order.ExecutedQuantity = order.Quantity
+		order.Status = types.OrderStatusFilled
+		order.IsWorking = false
+	} else {
+		order.Status = types.OrderStatusPartiallyFilled
+	}
+
+	e.lastTradeID++
+	trade := types.Trade{
+		ID:            e.lastTradeID,
+		OrderID:       order.OrderID,
+		Exchange:      e.Name(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          types.Time(now),
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	}
+	e.trades = append(e.trades, trade)
+
+	return []interface{}{trade, e.account.Balances(), *order}
+}
+
+// matchOpenOrders fills the open orders of the symbol by the quantity returned from fillQuantity,
+// the filled orders are moved to the closed orders.
+func (e *Exchange) matchOpenOrders(symbol string, fillQuantity func(o *types.Order) fixedpoint.Value) (events []interface{}) {
+	var openOrders []*types.Order
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			if quantity := fillQuantity(o); quantity.Sign() > 0 {
+				events = append(events, e.fill(o, o.Price, quantity, true)...)
+			}
+		}
+
+		if o.Status == types.OrderStatusFilled {
+			e.closedOrders[o.OrderID] = *o
+			continue
+		}
+
+		openOrders = append(openOrders, o)
+	}
+
+	e.openOrders = openOrders
+	return events
+}
+
+// handleBookTicker fills the open orders crossed by the best bid or ask price
+func (e *Exchange) handleBookTicker(bookTicker types.BookTicker) {
+	e.mu.Lock()
+	q := e.quote(bookTicker.Symbol)
+	q.bid = bookTicker.Buy
+	q.ask = bookTicker.Sell
+
+	events := e.matchOpenOrders(bookTicker.Symbol, func(o *types.Order) fixedpoint.Value {
+		if isCrossingPrice(o.Side, o.Price, q.bestPrice(o.Side)) {
+			return o.Quantity.Sub(o.ExecutedQuantity)
+		}
+		return fixedpoint.Zero
+	})
+	e.mu.Unlock()
+
+	e.handleEvents(events)
+}
+
+// handleMarketTrade fills the open orders when the market trades through the order price,
+// the orders at the trade price are filled by the trade quantity in the order of creation.
+func (e *Exchange) handleMarketTrade(trade types.Trade) {
+	e.mu.Lock()
+	e.quote(trade.Symbol).last = trade.Price
+
+	volume := trade.Quantity
+	events := e.matchOpenOrders(trade.Symbol, func(o *types.Order) fixedpoint.Value {
+		remaining := o.Quantity.Sub(o.ExecutedQuantity)
+
+		c := o.Price.Compare(trade.Price)
+		if (o.Side == types.SideTypeBuy && c > 0) || (o.Side == types.SideTypeSell && c < 0) {
+			return remaining
+		}
+
+		if c == 0 && volume.Sign() > 0 {
+			quantity := fixedpoint.Min(remaining, volume)
+			volume = volume.Sub(quantity)
+			return quantity
+		}
+
+		return fixedpoint.Zero
+	})
+	e.mu.Unlock()
+
+	e.handleEvents(events)
+}
+
+// handleTicker updates the quote by the polled ticker, and fills the open orders crossed by the bid or ask price
+func (e *Exchange) handleTicker(symbol string, ticker types.Ticker) {
+	e.mu.Lock()
+	e.quote(symbol).last = ticker.Last
+	e.mu.Unlock()
+
+	if ticker.Buy.IsZero() || ticker.Sell.IsZero() {
+		return
+	}
+
+	e.handleBookTicker(types.BookTicker{
+		Symbol: symbol,
+		Buy:    ticker.Buy,
+		Sell:   ticker.Sell,
+	})
+}
+
+func (e *Exchange) handleEvents(events []interface{}) {
+	if len(events) == 0 {
+		return
+	}
+
+	e.dispatch(events)
+	if err := e.saveState(); err != nil {
+		log.WithError(err).Errorf("can not save the paper trading state")
+	}
+}
+
+func (e *Exchange) quote(symbol string) *quote {
+	q, ok := e.quotes[symbol]
+	if !ok {
+		q = &quote{}
+		e.quotes[symbol] = q
+	}
+	return q
+}
+
+// bestPrice returns the price the taker order of the side is filled at
+func (q *quote) bestPrice(side types.SideType) fixedpoint.Value {
+	if q == nil {
+		return fixedpoint.Zero
+	}
+
+	switch side {
+	case types.SideTypeBuy:
+		if q.ask.Sign() > 0 {
+			return q.ask
+		}
+	case types.SideTypeSell:
+		if q.bid.Sign() > 0 {
+			return q.bid
+		}
+	}
+
+	return q.last
+}
+
+// isCrossingPrice checks if the order price crosses the best price of the opposite side
+func isCrossingPrice(side types.SideType, price, bestPrice fixedpoint.Value) bool {
+	if bestPrice.IsZero() {
+		return false
+	}
+
+	return (side == types.SideTypeBuy && price.Compare(bestPrice) >= 0) ||
+		(side == types.SideTypeSell && price.Compare(bestPrice) <= 0)
+}
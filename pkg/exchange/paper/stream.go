@@ -0,0 +1,129 @@
+package paper
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Stream is the stream of the paper trading exchange.
+//
+// The public stream connects to the market data stream of the real exchange and forwards its events,
+// while the user data stream emits the order, trade and balance updates of the paper trading matching engine.
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+
+	// stream is the market data stream of the real exchange, it's created when the public stream is connected
+	stream types.Stream
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if !s.PublicOnly {
+		s.exchange.addUserDataStream(s)
+		s.EmitConnect()
+		s.EmitStart()
+		return nil
+	}
+
+	stream := s.exchange.Exchange.NewStream()
+	stream.SetPublicOnly()
+	for _, sub := range s.Subscriptions {
+		stream.Subscribe(sub.Channel, sub.Symbol, sub.Options)
+	}
+
+	stream.OnStart(s.EmitStart)
+	stream.OnConnect(s.EmitConnect)
+	stream.OnDisconnect(s.EmitDisconnect)
+	stream.OnKLineClosed(s.EmitKLineClosed)
+	stream.OnKLine(s.EmitKLine)
+	stream.OnBookSnapshot(s.EmitBookSnapshot)
+	stream.OnBookUpdate(s.EmitBookUpdate)
+	stream.OnBookTickerUpdate(s.EmitBookTickerUpdate)
+	stream.OnMarketTrade(s.EmitMarketTrade)
+	s.stream = stream
+
+	return stream.Connect(ctx)
+}
+
+func (s *Stream) Close() error {
+	if !s.PublicOnly {
+		s.exchange.removeUserDataStream(s)
+		return nil
+	}
+
+	if s.stream != nil {
+		return s.stream.Close()
+	}
+
+	return nil
+}
+
+// startFeed subscribes the book ticker and the market trades of the symbol from the real exchange for matching the orders,
+// and polls the ticker of the symbol while there are open orders.
+func (e *Exchange) startFeed(symbol string) {
+	e.mu.Lock()
+	if _, ok := e.feeds[symbol]; ok {
+		e.mu.Unlock()
+		return
+	}
+
+	stream := e.Exchange.NewStream()
+	e.feeds[symbol] = stream
+	e.mu.Unlock()
+
+	stream.SetPublicOnly()
+	stream.Subscribe(types.BookTickerChannel, symbol, types.SubscribeOptions{})
+	stream.Subscribe(types.MarketTradeChannel, symbol, types.SubscribeOptions{})
+	stream.OnBookTickerUpdate(e.handleBookTicker)
+	stream.OnMarketTrade(e.handleMarketTrade)
+
+	go func() {
+		if err := stream.Connect(e.ctx); err != nil {
+			log.WithError(err).Errorf("can not connect the %s market data feed", symbol)
+		}
+	}()
+
+	if e.TickerPollInterval > 0 {
+		go e.pollTicker(symbol)
+	}
+}
+
+func (e *Exchange) pollTicker(symbol string) {
+	ticker := time.NewTicker(e.TickerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+
+		case <-ticker.C:
+			if !e.hasOpenOrders(symbol) {
+				continue
+			}
+
+			t, err := e.Exchange.QueryTicker(e.ctx, symbol)
+			if err != nil {
+				log.WithError(err).Warnf("can not query the %s ticker", symbol)
+				continue
+			}
+
+			e.handleTicker(symbol, *t)
+		}
+	}
+}
+
+func (e *Exchange) hasOpenOrders(symbol string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
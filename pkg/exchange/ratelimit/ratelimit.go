@@ -0,0 +1,376 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrLimitExceeded is returned when the request can not be sent before the rate limit is reset
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// Rule is a rate limit rule of the exchange, the used weight of the rule is reset on every interval.
+type Rule struct {
+	// Name is the name of the rule, e.g., request_weight or orders
+	Name string
+
+	// Limit is the max weight of the interval, it should be set lower than the exchange limit
+	// so that the requests are queued before the exchange limit is hit.
+	Limit int
+
+	// Interval is the time window of the rule, the window is aligned to the clock
+	Interval time.Duration
+
+	// PathPrefix limits the rule to the request paths with the prefix, e.g., /api/ or /sapi/
+	PathPrefix string
+
+	// DefaultWeight is the weight of the endpoints that are not registered with the rule
+	DefaultWeight int
+
+	// UsedHeader is the response header of the used weight of the window, e.g., X-MBX-USED-WEIGHT-1M
+	UsedHeader string
+
+	// RemainingHeader is the response header of the remaining weight of the window, e.g., gw-ratelimit-remaining
+	RemainingHeader string
+
+	// LimitHeader is the response header of the exchange limit, the used weight is the exchange limit minus the remaining weight
+	LimitHeader string
+}
+
+// Weight is the request weight of an endpoint, map: rule name -> weight
+type Weight map[string]int
+
+// WeightFunc returns the request weight by the query parameters of the request
+type WeightFunc func(params url.Values) Weight
+
+type bucket struct {
+	rule        Rule
+	used        int
+	windowStart time.Time
+}
+
+// refresh resets the used weight if the window is passed
+func (b *bucket) refresh(now time.Time) {
+	windowStart := now.Truncate(b.rule.Interval)
+	if windowStart.After(b.windowStart) {
+		b.windowStart = windowStart
+		b.used = 0
+	}
+}
+
+func (b *bucket) resetTime() time.Time {
+	return b.windowStart.Add(b.rule.Interval)
+}
+
+// Limiter tracks the used weights of the rate limit rules of an exchange,
+// the requests are queued when the rules are going to be exceeded.
+type Limiter struct {
+	// Name is the name of the limiter, it's usually the exchange name
+	Name string
+
+	// MaxWait is the max waiting time of a request, the request is rejected if it needs to wait longer, zero means no limit
+	MaxWait time.Duration
+
+	mu           sync.Mutex
+	buckets      []*bucket
+	weights      map[string]WeightFunc
+	blockedUntil time.Time
+
+	now func() time.Time
+}
+
+func New(name string, rules ...Rule) *Limiter {
+	l := &Limiter{
+		Name:    name,
+		weights: make(map[string]WeightFunc),
+		now:     time.Now,
+	}
+
+	for _, rule := range rules {
+		l.buckets = append(l.buckets, &bucket{rule: rule})
+	}
+
+	return l
+}
+
+// RegisterWeight registers the weight of the endpoint, the endpoint is a path with an optional method,
+// e.g., "GET /api/v3/openOrders" or "/api/v3/klines".
+func (l *Limiter) RegisterWeight(endpoint string, weight Weight) {
+	l.RegisterWeightFunc(endpoint, func(params url.Values) Weight {
+		return weight
+	})
+}
+
+// RegisterWeightFunc registers the weight function of the endpoint,
+// it's used when the weight depends on the parameters, e.g., the open orders query without symbol.
+func (l *Limiter) RegisterWeightFunc(endpoint string, f WeightFunc) {
+	l.mu.Lock()
+	l.weights[endpoint] = f
+	l.mu.Unlock()
+}
+
+func (l *Limiter) lookupWeight(method, path string, params url.Values) Weight {
+	if f, ok := l.weights[method+" "+path]; ok {
+		return f(params)
+	}
+
+	if f, ok := l.weights[path]; ok {
+		return f(params)
+	}
+
+	return nil
+}
+
+// weightOf returns the weight of the rule, the rule does not apply if the path does not have the prefix of the rule
+func weightOf(rule Rule, path string, weight Weight) int {
+	if !strings.HasPrefix(path, rule.PathPrefix) {
+		return 0
+	}
+
+	if w, ok := weight[rule.Name]; ok {
+		return w
+	}
+
+	return rule.DefaultWeight
+}
+
+// Wait reserves the weight of the request, it blocks until the weight is available,
+// ErrLimitExceeded is returned if the request can not be sent within the max wait or before the context deadline.
+func (l *Limiter) Wait(ctx context.Context, method, path string, params url.Values) error {
+	endpoint := method + " " + path
+	waited := false
+	for {
+		delay, err := l.reserve(method, path, params)
+		if err != nil {
+			emitThrottle(l.Name, endpoint, true)
+			return err
+		}
+
+		if delay <= 0 {
+			return nil
+		}
+
+		if l.MaxWait > 0 && delay > l.MaxWait {
+			emitThrottle(l.Name, endpoint, true)
+			return fmt.Errorf("%s %s needs to wait %s: %w", l.Name, endpoint, delay, ErrLimitExceeded)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && l.now().Add(delay).After(deadline) {
+			emitThrottle(l.Name, endpoint, true)
+			return fmt.Errorf("%s %s needs to wait %s: %w", l.Name, endpoint, delay, ErrLimitExceeded)
+		}
+
+		if !waited {
+			waited = true
+			emitThrottle(l.Name, endpoint, false)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve adds the weight of the request to the used weights if all the rules are not exceeded,
+// otherwise the delay to the next window is returned.
+func (l *Limiter) reserve(method, path string, params url.Values) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now), nil
+	}
+
+	weight := l.lookupWeight(method, path, params)
+
+	var delay time.Duration
+	for _, b := range l.buckets {
+		w := weightOf(b.rule, path, weight)
+		if w == 0 {
+			continue
+		}
+
+		if w > b.rule.Limit {
+			return 0, fmt.Errorf("%s %s %s weight %d is greater than the limit %d of %s: %w", l.Name, method, path, w, b.rule.Limit, b.rule.Name, ErrLimitExceeded)
+		}
+
+		b.refresh(now)
+		if b.used+w > b.rule.Limit {
+			if d := b.resetTime().Sub(now); d > delay {
+				delay = d
+			}
+		}
+	}
+
+	if delay > 0 {
+		return delay, nil
+	}
+
+	for _, b := range l.buckets {
+		w := weightOf(b.rule, path, weight)
+		if w == 0 {
+			continue
+		}
+
+		b.used += w
+		emitUsage(l.Name, b.rule.Name, b.used, b.rule.Limit)
+	}
+
+	return 0, nil
+}
+
+// UpdateFromHeader syncs the used weights of the rules applied to the request from the response headers,
+// the used weights reported by the exchange are applied when they are higher than the local ones,
+// e.g., the same api key or ip is used by other processes.
+func (l *Limiter) UpdateFromHeader(method, path string, params url.Values, header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	weight := l.lookupWeight(method, path, params)
+	for _, b := range l.buckets {
+		if weightOf(b.rule, path, weight) == 0 {
+			continue
+		}
+
+		used, ok := usedWeightFromHeader(b.rule, header)
+		if !ok {
+			continue
+		}
+
+		b.refresh(now)
+		if used > b.used {
+			b.used = used
+		}
+
+		emitUsage(l.Name, b.rule.Name, b.used, b.rule.Limit)
+	}
+}
+
+func usedWeightFromHeader(rule Rule, header http.Header) (int, bool) {
+	if len(rule.UsedHeader) > 0 {
+		if v := header.Get(rule.UsedHeader); len(v) > 0 {
+			used, err := strconv.Atoi(v)
+			return used, err == nil
+		}
+	}
+
+	if len(rule.RemainingHeader) > 0 {
+		if v := header.Get(rule.RemainingHeader); len(v) > 0 {
+			remaining, err := strconv.Atoi(v)
+			if err != nil {
+				return 0, false
+			}
+
+			limit := rule.Limit
+			if len(rule.LimitHeader) > 0 {
+				if l, err := strconv.Atoi(header.Get(rule.LimitHeader)); err == nil {
+					limit = l
+				}
+			}
+
+			return limit - remaining, true
+		}
+	}
+
+	return 0, false
+}
+
+// Block blocks all the requests for the duration, it's used when the exchange responds the rate limit error
+func (l *Limiter) Block(duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := l.now().Add(duration)
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// Usage returns the used weights of the rules in the current windows, map: rule name -> used weight
+func (l *Limiter) Usage() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	usage := make(map[string]int, len(l.buckets))
+	for _, b := range l.buckets {
+		b.refresh(now)
+		usage[b.rule.Name] = b.used
+	}
+
+	return usage
+}
+
+var registry = struct {
+	sync.Mutex
+	limiters map[string]*Limiter
+}{limiters: make(map[string]*Limiter)}
+
+// Register registers the limiter with its name, the registered limiter is returned
+// so that the exchange packages can register their limiters in the variable declarations.
+func Register(l *Limiter) *Limiter {
+	registry.Lock()
+	registry.limiters[l.Name] = l
+	registry.Unlock()
+	return l
+}
+
+// Get returns the registered limiter of the name
+func Get(name string) (*Limiter, bool) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	l, ok := registry.limiters[name]
+	return l, ok
+}
+
+var callbacks struct {
+	sync.Mutex
+	usage    []func(name, rule string, used, limit int)
+	throttle []func(name, endpoint string, rejected bool)
+}
+
+// OnUsage adds the callback of the used weight updates
+func OnUsage(cb func(name, rule string, used, limit int)) {
+	callbacks.Lock()
+	callbacks.usage = append(callbacks.usage, cb)
+	callbacks.Unlock()
+}
+
+// OnThrottle adds the callback of the throttled requests, rejected is false when the request is queued
+func OnThrottle(cb func(name, endpoint string, rejected bool)) {
+	callbacks.Lock()
+	callbacks.throttle = append(callbacks.throttle, cb)
+	callbacks.Unlock()
+}
+
+func emitUsage(name, rule string, used, limit int) {
+	callbacks.Lock()
+	defer callbacks.Unlock()
+
+	for _, cb := range callbacks.usage {
+		cb(name, rule, used, limit)
+	}
+}
+
+func emitThrottle(name, endpoint string, rejected bool) {
+	callbacks.Lock()
+	defer callbacks.Unlock()
+
+	for _, cb := range callbacks.throttle {
+		cb(name, endpoint, rejected)
+	}
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLimiter(now *time.Time, rules ...Rule) *Limiter {
+	l := New("test", rules...)
+	l.now = func() time.Time {
+		return *now
+	}
+	return l
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 10, 0, time.UTC)
+	l := newTestLimiter(&now,
+		Rule{Name: "request_weight", Limit: 10, Interval: time.Minute, PathPrefix: "/api/", DefaultWeight: 1},
+		Rule{Name: "orders", Limit: 2, Interval: 10 * time.Second, PathPrefix: "/api/"},
+	)
+	l.MaxWait = time.Second
+	l.RegisterWeight("GET /api/v3/account", Weight{"request_weight": 5})
+	l.RegisterWeight("POST /api/v3/order", Weight{"orders": 1})
+	l.RegisterWeightFunc("GET /api/v3/openOrders", func(params url.Values) Weight {
+		if params.Get("symbol") == "" {
+			return Weight{"request_weight": 40}
+		}
+		return Weight{"request_weight": 3}
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, l.Wait(ctx, "GET", "/api/v3/account", nil))
+	assert.NoError(t, l.Wait(ctx, "POST", "/api/v3/order", nil))
+	assert.NoError(t, l.Wait(ctx, "POST", "/api/v3/order", nil))
+	assert.Equal(t, map[string]int{"request_weight": 7, "orders": 2}, l.Usage())
+
+	// the orders rule is exceeded, and the window is reset after 10 seconds which is longer than the max wait
+	err := l.Wait(ctx, "POST", "/api/v3/order", nil)
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+
+	// the weight is greater than the limit
+	err = l.Wait(ctx, "GET", "/api/v3/openOrders", nil)
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+
+	// the paths without the prefix are not limited
+	assert.NoError(t, l.Wait(ctx, "GET", "/sapi/v1/margin/account", nil))
+
+	assert.NoError(t, l.Wait(ctx, "GET", "/api/v3/openOrders", url.Values{"symbol": []string{"BTCUSDT"}}))
+	assert.Equal(t, map[string]int{"request_weight": 10, "orders": 2}, l.Usage())
+
+	// the windows are reset
+	now = now.Add(time.Minute)
+	assert.Equal(t, map[string]int{"request_weight": 0, "orders": 0}, l.Usage())
+	assert.NoError(t, l.Wait(ctx, "POST", "/api/v3/order", nil))
+}
+
+func TestLimiter_UpdateFromHeader(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 10, 0, time.UTC)
+	l := newTestLimiter(&now,
+		Rule{Name: "request_weight", Limit: 1200, Interval: time.Minute, DefaultWeight: 1, UsedHeader: "X-Mbx-Used-Weight-1m"},
+		Rule{Name: "pool", Limit: 100, Interval: 30 * time.Second, DefaultWeight: 1, RemainingHeader: "gw-ratelimit-remaining"},
+		Rule{Name: "orders", Limit: 10, Interval: 10 * time.Second, UsedHeader: "X-Mbx-Order-Count-10s"},
+	)
+
+	header := http.Header{}
+	header.Set("X-Mbx-Used-Weight-1m", "600")
+	header.Set("gw-ratelimit-remaining", "30")
+	header.Set("X-Mbx-Order-Count-10s", "5")
+	l.UpdateFromHeader("GET", "/api/v3/account", nil, header)
+
+	// the orders rule is not applied to the request
+	assert.Equal(t, map[string]int{"request_weight": 600, "pool": 70, "orders": 0}, l.Usage())
+
+	l.RegisterWeight("POST /api/v3/order", Weight{"orders": 1})
+	l.UpdateFromHeader("POST", "/api/v3/order", nil, header)
+	assert.Equal(t, map[string]int{"request_weight": 600, "pool": 70, "orders": 5}, l.Usage())
+
+	// the used weight is the exchange limit minus the remaining weight
+	header.Set("gw-ratelimit-remaining", "10")
+	header.Set("gw-ratelimit-limit", "90")
+	l.buckets[1].rule.LimitHeader = "gw-ratelimit-limit"
+	l.UpdateFromHeader("GET", "/api/v3/account", nil, header)
+	assert.Equal(t, 80, l.Usage()["pool"])
+
+	// the lower used weight is ignored
+	header.Set("X-Mbx-Used-Weight-1m", "10")
+	header.Del("gw-ratelimit-remaining")
+	l.UpdateFromHeader("GET", "/api/v3/account", nil, header)
+	assert.Equal(t, 600, l.Usage()["request_weight"])
+}
+
+func TestTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Mbx-Used-Weight-1m", "50")
+		if requests > 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	l := New("test", Rule{Name: "request_weight", Limit: 100, Interval: time.Minute, DefaultWeight: 1, UsedHeader: "X-Mbx-Used-Weight-1m"})
+	l.MaxWait = time.Second
+	client := &http.Client{Transport: NewTransport(l, nil)}
+
+	resp, err := client.Get(server.URL + "/api/v3/time")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 50, l.Usage()["request_weight"])
+
+	resp, err = client.Get(server.URL + "/api/v3/time")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// the requests are blocked after the rate limit error
+	_, err = client.Get(server.URL + "/api/v3/time")
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+	assert.Equal(t, 2, requests)
+}
+
+type closeRecorder struct {
+	io.Reader
+
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestTransport_closeBodyOnLimitExceeded(t *testing.T) {
+	l := New("test", Rule{Name: "request_weight", Limit: 100, Interval: time.Minute, DefaultWeight: 1})
+	l.MaxWait = time.Second
+	l.Block(time.Minute)
+
+	body := &closeRecorder{Reader: strings.NewReader("symbol=BTCUSDT")}
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/api/v3/order", body)
+	assert.NoError(t, err)
+
+	_, err = NewTransport(l, nil).RoundTrip(req)
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+	assert.True(t, body.closed)
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultBlockDuration is used when the exchange responds the rate limit error without the Retry-After header
+const defaultBlockDuration = time.Minute
+
+// Transport is a http.RoundTripper that reserves the request weights before sending the requests,
+// and syncs the used weights from the response headers.
+type Transport struct {
+	Limiter *Limiter
+
+	// Base is the underlying round tripper, http.DefaultTransport is used if it's nil
+	Base http.RoundTripper
+}
+
+func NewTransport(limiter *Limiter, base http.RoundTripper) *Transport {
+	return &Transport{
+		Limiter: limiter,
+		Base:    base,
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	params := req.URL.Query()
+	if err := t.Limiter.Wait(req.Context(), req.Method, req.URL.Path, params); err != nil {
+		// the round tripper must close the request body even on errors
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, err
+	}
+
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	t.Limiter.UpdateFromHeader(req.Method, req.URL.Path, params, resp.Header)
+
+	// 418 is used by binance when the ip is banned for violating the rate limits
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusTeapot:
+		t.Limiter.Block(retryAfter(resp.Header))
+	}
+
+	return resp, nil
+}
+
+func retryAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return defaultBlockDuration
+}
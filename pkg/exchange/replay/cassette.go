@@ -0,0 +1,127 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "replay")
+
+// MethodHeader is the method of the first record, which describes the recorded exchange
+const MethodHeader = "Header"
+
+// Record is a recorded exchange API call or a raw websocket message,
+// the cassette file stores one JSON record per line in the recording order.
+type Record struct {
+	Seq  int64     `json:"seq"`
+	Time time.Time `json:"time"`
+
+	// Method is the name of the exchange method, the request is the JSON array of the call arguments
+	Method   string          `json:"method,omitempty"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+
+	// Stream is the sequence number (starting from 1) of the stream created by NewStream,
+	// Message is the raw websocket message received by the stream
+	Stream  int    `json:"stream,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Header is the response of the header record
+type Header struct {
+	Exchange            types.ExchangeName `json:"exchange"`
+	PlatformFeeCurrency string             `json:"platformFeeCurrency"`
+}
+
+// CassetteWriter appends the records to the cassette file
+type CassetteWriter struct {
+	file    *os.File
+	encoder *json.Encoder
+	seq     int64
+	mu      sync.Mutex
+}
+
+func NewCassetteWriter(filename string) (*CassetteWriter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CassetteWriter{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Write assigns the sequence number to the record and writes it
+func (w *CassetteWriter) Write(record Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	record.Seq = w.seq
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+
+	return w.encoder.Encode(record)
+}
+
+func (w *CassetteWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// ReadCassette reads all the records of the cassette file
+func ReadCassette(filename string) ([]Record, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+
+	// the websocket messages and the market responses could be larger than the default token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("cassette %s record #%d decode error: %w", filename, len(records)+1, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func encodeArgs(args ...interface{}) json.RawMessage {
+	if len(args) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		log.WithError(err).Errorf("can not encode the call arguments")
+		return nil
+	}
+
+	return data
+}
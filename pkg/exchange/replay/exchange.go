@@ -0,0 +1,390 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultMaxMessageDelay is the max time a websocket message waits for the API calls recorded before it
+const DefaultMaxMessageDelay = 10 * time.Second
+
+// ExchangeFactory creates the public exchange of the recorded exchange name,
+// the streams of the public exchange parse the recorded websocket messages
+type ExchangeFactory func(n types.ExchangeName) (types.Exchange, error)
+
+// Exchange replays the API calls and the websocket messages recorded in the cassette file.
+//
+// The API responses are returned in the recording order of each method.
+// The websocket messages of each stream are served by a local websocket server,
+// so that they are parsed by the parser of the recorded exchange.
+// A message is sent after the API calls recorded before it are replayed,
+// or after MaxMessageDelay when the calls are not made, e.g., the market data is loaded from the cache.
+type Exchange struct {
+	Header Header
+
+	MaxMessageDelay time.Duration
+
+	publicExchange types.Exchange
+
+	mu sync.Mutex
+
+	// calls are the recorded API calls that are not replayed yet, indexed by the method name
+	calls map[string][]Record
+
+	// callSeqs are the sequence numbers of all the recorded API calls in order
+	callSeqs []int64
+
+	// lastReplayedSeq is the max sequence number of the replayed API calls
+	lastReplayedSeq int64
+
+	// messages are the websocket messages indexed by the stream sequence number
+	messages map[int][]Record
+
+	numOfStreams int
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// Open loads the cassette file and creates the public exchange of the recorded exchange by the factory
+func Open(filename string, factory ExchangeFactory) (*Exchange, error) {
+	if filename == "" {
+		return nil, errors.New("replay cassette file is not defined")
+	}
+
+	records, err := ReadCassette(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(records, factory)
+}
+
+func New(records []Record, factory ExchangeFactory) (*Exchange, error) {
+	if len(records) == 0 || records[0].Method != MethodHeader {
+		return nil, errors.New("replay cassette header is not found")
+	}
+
+	var header Header
+	if err := json.Unmarshal(records[0].Response, &header); err != nil {
+		return nil, err
+	}
+
+	if header.Exchange == types.ExchangeReplay {
+		return nil, errors.New("can not replay the cassette recorded from the replay exchange")
+	}
+
+	publicExchange, err := factory(header.Exchange)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exchange{
+		Header:          header,
+		MaxMessageDelay: DefaultMaxMessageDelay,
+		publicExchange:  publicExchange,
+		calls:           make(map[string][]Record),
+		messages:        make(map[int][]Record),
+	}
+
+	for _, record := range records[1:] {
+		if record.Stream > 0 {
+			e.messages[record.Stream] = append(e.messages[record.Stream], record)
+		} else {
+			e.calls[record.Method] = append(e.calls[record.Method], record)
+			e.callSeqs = append(e.callSeqs, record.Seq)
+		}
+	}
+
+	return e, nil
+}
+
+// Name returns the recorded exchange name, so that the exchange specific settings still work
+func (e *Exchange) Name() types.ExchangeName {
+	return e.Header.Exchange
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return e.Header.PlatformFeeCurrency
+}
+
+// Close stops the websocket server
+func (e *Exchange) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.server == nil {
+		return nil
+	}
+
+	return e.server.Close()
+}
+
+// replay pops the next recorded call of the method and decodes its response
+func (e *Exchange) replay(method string, response interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	queue := e.calls[method]
+	if len(queue) == 0 {
+		return fmt.Errorf("replay: no more recorded %s call", method)
+	}
+
+	record := queue[0]
+	e.calls[method] = queue[1:]
+	if record.Seq > e.lastReplayedSeq {
+		e.lastReplayedSeq = record.Seq
+	}
+
+	if record.Error != "" {
+		return errors.New(record.Error)
+	}
+
+	if response == nil || len(record.Response) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(record.Response, response)
+}
+
+// messageReady checks if the API calls recorded before the message are replayed
+func (e *Exchange) messageReady(seq int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var lastCallSeq int64
+	for _, callSeq := range e.callSeqs {
+		if callSeq > seq {
+			break
+		}
+		lastCallSeq = callSeq
+	}
+
+	return lastCallSeq <= e.lastReplayedSeq
+}
+
+// NewStream creates the stream of the recorded exchange and connects it to the local websocket server,
+// the streams are matched with the recorded streams by the creation order
+func (e *Exchange) NewStream() types.Stream {
+	e.mu.Lock()
+	e.numOfStreams++
+	streamID := e.numOfStreams
+	e.mu.Unlock()
+
+	stream := e.publicExchange.NewStream()
+	endpointStream, ok := stream.(interface {
+		SetEndpointCreator(creator types.EndpointCreator)
+	})
+	if !ok {
+		log.Errorf("stream %T does not support endpoint creator, the messages can not be replayed", stream)
+		return stream
+	}
+
+	endpointStream.SetEndpointCreator(func(ctx context.Context) (string, error) {
+		addr, err := e.startServer()
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("ws://%s/streams/%d", addr, streamID), nil
+	})
+
+	return stream
+}
+
+func (e *Exchange) startServer() (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.listener != nil {
+		return e.listener.Addr().String(), nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/streams/", e.handleStream)
+
+	e.listener = listener
+	e.server = &http.Server{Handler: mux}
+	go func() {
+		if err := e.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Errorf("replay websocket server error")
+		}
+	}()
+
+	return listener.Addr().String(), nil
+}
+
+var upgrader = websocket.Upgrader{}
+
+func (e *Exchange) handleStream(w http.ResponseWriter, r *http.Request) {
+	streamID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/streams/"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Errorf("replay websocket upgrade error")
+		return
+	}
+	defer conn.Close()
+
+	closeC := make(chan struct{})
+	go func() {
+		defer close(closeC)
+
+		// read the subscription commands and the control messages until the connection is closed
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		record, ok := e.nextMessage(streamID)
+		if !ok {
+			break
+		}
+
+		if !e.waitMessage(record.Seq, closeC) {
+			return
+		}
+
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(record.Message)); err != nil {
+			log.WithError(err).Errorf("replay websocket write error")
+			return
+		}
+	}
+
+	// all the messages are sent, keep the connection until the stream is closed
+	<-closeC
+}
+
+// nextMessage pops the next message of the stream, the messages are not sent again after re-connecting
+func (e *Exchange) nextMessage(streamID int) (Record, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	queue := e.messages[streamID]
+	if len(queue) == 0 {
+		return Record{}, false
+	}
+
+	e.messages[streamID] = queue[1:]
+	return queue[0], true
+}
+
+func (e *Exchange) waitMessage(seq int64, closeC chan struct{}) bool {
+	deadline := time.Now().Add(e.MaxMessageDelay)
+	for !e.messageReady(seq) {
+		if time.Now().After(deadline) {
+			log.Warnf("replay message #%d is sent before the recorded API calls are replayed", seq)
+			return true
+		}
+
+		select {
+		case <-closeC:
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	return true
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	var markets types.MarketMap
+	err := e.replay("QueryMarkets", &markets)
+	return markets, err
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	var ticker *types.Ticker
+	err := e.replay("QueryTicker", &ticker)
+	return ticker, err
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	var tickers map[string]types.Ticker
+	err := e.replay("QueryTickers", &tickers)
+	return tickers, err
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	var kLines []types.KLine
+	err := e.replay("QueryKLines", &kLines)
+	return kLines, err
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	var resp accountResponse
+	if err := e.replay("QueryAccount", &resp); err != nil {
+		return nil, err
+	}
+
+	account := resp.Account
+	if account == nil {
+		account = types.NewAccount()
+	}
+	account.UpdateBalances(resp.Balances)
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	var balances types.BalanceMap
+	err := e.replay("QueryAccountBalances", &balances)
+	return balances, err
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var createdOrders types.OrderSlice
+	err := e.replay("SubmitOrders", &createdOrders)
+	return createdOrders, err
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	var orders []types.Order
+	err := e.replay("QueryOpenOrders", &orders)
+	return orders, err
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	return e.replay("CancelOrders", nil)
+}
+
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	var order *types.Order
+	err := e.replay("QueryOrder", &order)
+	return order, err
+}
+
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	var trades []types.Trade
+	err := e.replay("QueryTrades", &trades)
+	return trades, err
+}
+
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	var orders []types.Order
+	err := e.replay("QueryClosedOrders", &orders)
+	return orders, err
+}
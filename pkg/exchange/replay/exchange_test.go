@@ -0,0 +1,229 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testStream struct {
+	types.StandardStream
+}
+
+func newTestStream() *testStream {
+	stream := &testStream{StandardStream: types.NewStandardStream()}
+	stream.SetParser(func(message []byte) (interface{}, error) {
+		var order types.Order
+		err := json.Unmarshal(message, &order)
+		return order, err
+	})
+	stream.SetDispatcher(func(e interface{}) {
+		stream.EmitOrderUpdate(e.(types.Order))
+	})
+	return stream
+}
+
+// testExchange returns the fixed responses, the unused methods are not implemented
+type testExchange struct {
+	types.Exchange
+
+	stream *testStream
+}
+
+func (e *testExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *testExchange) PlatformFeeCurrency() string {
+	return "BNB"
+}
+
+func (e *testExchange) NewStream() types.Stream {
+	e.stream = newTestStream()
+	return e.stream
+}
+
+func (e *testExchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(1000)},
+	})
+	return account, nil
+}
+
+func (e *testExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var createdOrders types.OrderSlice
+	for i, order := range orders {
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder: order,
+			Exchange:    types.ExchangeBinance,
+			OrderID:     uint64(i + 1),
+			Status:      types.OrderStatusNew,
+		})
+	}
+	return createdOrders, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	filename := filepath.Join(t.TempDir(), "cassette.jsonl")
+	inner := &testExchange{}
+	recorder, err := NewRecordingExchange(inner, filename)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_ = recorder.NewStream()
+
+	_, err = recorder.QueryAccount(ctx)
+	assert.NoError(t, err)
+
+	submitOrder := types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(0.01),
+		Price:    fixedpoint.NewFromInt(20000),
+	}
+	_, err = recorder.SubmitOrders(ctx, submitOrder)
+	assert.NoError(t, err)
+
+	// the order update received after the order is submitted
+	inner.stream.EmitRawMessage([]byte(`{"symbol":"BTCUSDT","side":"BUY","type":"LIMIT","orderID":1,"status":"FILLED"}`))
+	assert.NoError(t, recorder.(io.Closer).Close())
+
+	ex, err := Open(filename, func(n types.ExchangeName) (types.Exchange, error) {
+		assert.Equal(t, types.ExchangeBinance, n)
+		return &testExchange{}, nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer ex.Close()
+
+	assert.Equal(t, types.ExchangeBinance, ex.Name())
+	assert.Equal(t, "BNB", ex.PlatformFeeCurrency())
+
+	var mu sync.Mutex
+	var orderUpdates []types.Order
+	stream := ex.NewStream()
+	stream.OnOrderUpdate(func(order types.Order) {
+		mu.Lock()
+		orderUpdates = append(orderUpdates, order)
+		mu.Unlock()
+	})
+	assert.NoError(t, stream.Connect(ctx))
+	defer stream.Close()
+
+	account, err := ex.QueryAccount(ctx)
+	if assert.NoError(t, err) {
+		balance, ok := account.Balance("USDT")
+		assert.True(t, ok)
+		assert.Equal(t, "1000", balance.Available.String())
+	}
+
+	// the order update is not sent before the order is submitted
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	assert.Len(t, orderUpdates, 0)
+	mu.Unlock()
+
+	createdOrders, err := ex.SubmitOrders(ctx, submitOrder)
+	if assert.NoError(t, err) && assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, uint64(1), createdOrders[0].OrderID)
+		assert.Equal(t, "BTCUSDT", createdOrders[0].Symbol)
+	}
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(orderUpdates) == 1 && orderUpdates[0].Status == types.OrderStatusFilled
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// the recorded calls are used up
+	_, err = ex.SubmitOrders(ctx, submitOrder)
+	assert.Error(t, err)
+}
+
+// testHistoryExchange supports the order query, the trade history and the default fee rates
+type testHistoryExchange struct {
+	testExchange
+}
+
+func (e *testHistoryExchange) DefaultFeeRates() types.ExchangeFee {
+	return types.ExchangeFee{MakerFeeRate: fixedpoint.NewFromFloat(0.001)}
+}
+
+func (e *testHistoryExchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit},
+		Exchange:    types.ExchangeBinance,
+		OrderID:     1,
+		Status:      types.OrderStatusFilled,
+	}, nil
+}
+
+func (e *testHistoryExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	return nil, nil
+}
+
+func (e *testHistoryExchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	return nil, nil
+}
+
+func TestNewRecordingExchange_Services(t *testing.T) {
+	recorder, err := NewRecordingExchange(&testExchange{}, filepath.Join(t.TempDir(), "base.jsonl"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the services that the wrapped exchange does not have are not claimed
+	_, ok := recorder.(types.ExchangeOrderQueryService)
+	assert.False(t, ok)
+	_, ok = recorder.(types.ExchangeTradeHistoryService)
+	assert.False(t, ok)
+	assert.NoError(t, recorder.(io.Closer).Close())
+
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	recorder, err = NewRecordingExchange(&testHistoryExchange{}, filename)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	feeRates, ok := recorder.(types.ExchangeDefaultFeeRates)
+	if assert.True(t, ok) {
+		assert.Equal(t, "0.001", feeRates.DefaultFeeRates().MakerFeeRate.String())
+	}
+
+	_, ok = recorder.(types.FuturesService)
+	assert.False(t, ok)
+
+	service, ok := recorder.(types.ExchangeOrderQueryService)
+	if assert.True(t, ok) {
+		_, err = service.QueryOrder(context.Background(), types.OrderQuery{Symbol: "BTCUSDT", OrderID: "1"})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, recorder.(io.Closer).Close())
+
+	// the order query is recorded and can be replayed
+	ex, err := Open(filename, func(n types.ExchangeName) (types.Exchange, error) {
+		return &testExchange{}, nil
+	})
+	if assert.NoError(t, err) {
+		order, err := ex.QueryOrder(context.Background(), types.OrderQuery{Symbol: "BTCUSDT", OrderID: "1"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.OrderStatusFilled, order.Status)
+		}
+	}
+}
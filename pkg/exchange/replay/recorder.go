@@ -0,0 +1,156 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// accountResponse carries the balances of the account, which are not exported in the account JSON
+type accountResponse struct {
+	Account  *types.Account   `json:"account"`
+	Balances types.BalanceMap `json:"balances"`
+}
+
+// RecordingExchange wraps the exchange and records the API calls and the raw websocket messages
+// of its streams into the cassette file, the cassette can be replayed by the replay exchange.
+// Use NewRecordingExchange to create it with the optional services of the wrapped exchange.
+type RecordingExchange struct {
+	types.Exchange
+
+	writer *CassetteWriter
+
+	numOfStreams int
+	mu           sync.Mutex
+}
+
+// NewRecordingExchange creates the recording exchange of the given exchange, the returned exchange implements
+// the same optional services (order query, margin, futures ...) of the given exchange, see newServiceExchange.
+// It also implements io.Closer to close the cassette file.
+func NewRecordingExchange(ex types.Exchange, filename string) (types.Exchange, error) {
+	writer, err := NewCassetteWriter(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &RecordingExchange{
+		Exchange: ex,
+		writer:   writer,
+	}
+
+	e.record(MethodHeader, nil, Header{
+		Exchange:            ex.Name(),
+		PlatformFeeCurrency: ex.PlatformFeeCurrency(),
+	}, nil)
+
+	return newServiceExchange(e), nil
+}
+
+func (e *RecordingExchange) Close() error {
+	return e.writer.Close()
+}
+
+func (e *RecordingExchange) record(method string, request json.RawMessage, response interface{}, err error) {
+	record := Record{
+		Method:  method,
+		Request: request,
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	} else if response != nil {
+		data, err2 := json.Marshal(response)
+		if err2 != nil {
+			log.WithError(err2).Errorf("can not encode the %s response", method)
+		}
+		record.Response = data
+	}
+
+	if err := e.writer.Write(record); err != nil {
+		log.WithError(err).Errorf("can not write the %s record", method)
+	}
+}
+
+// NewStream records the raw websocket messages of the stream,
+// the stream must embed types.StandardStream, otherwise its messages are not recorded
+func (e *RecordingExchange) NewStream() types.Stream {
+	e.mu.Lock()
+	e.numOfStreams++
+	streamID := e.numOfStreams
+	e.mu.Unlock()
+
+	stream := e.Exchange.NewStream()
+	rawStream, ok := stream.(interface{ OnRawMessage(cb func(raw []byte)) })
+	if !ok {
+		log.Warnf("stream %T does not support raw messages, the messages are not recorded", stream)
+		return stream
+	}
+
+	rawStream.OnRawMessage(func(raw []byte) {
+		if err := e.writer.Write(Record{Stream: streamID, Message: string(raw)}); err != nil {
+			log.WithError(err).Errorf("can not write the websocket message record")
+		}
+	})
+
+	return stream
+}
+
+func (e *RecordingExchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	markets, err := e.Exchange.QueryMarkets(ctx)
+	e.record("QueryMarkets", nil, markets, err)
+	return markets, err
+}
+
+func (e *RecordingExchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	ticker, err := e.Exchange.QueryTicker(ctx, symbol)
+	e.record("QueryTicker", encodeArgs(symbol), ticker, err)
+	return ticker, err
+}
+
+func (e *RecordingExchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	tickers, err := e.Exchange.QueryTickers(ctx, symbol...)
+	e.record("QueryTickers", encodeArgs(symbol), tickers, err)
+	return tickers, err
+}
+
+func (e *RecordingExchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	kLines, err := e.Exchange.QueryKLines(ctx, symbol, interval, options)
+	e.record("QueryKLines", encodeArgs(symbol, interval, options), kLines, err)
+	return kLines, err
+}
+
+func (e *RecordingExchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account, err := e.Exchange.QueryAccount(ctx)
+	if err != nil {
+		e.record("QueryAccount", nil, nil, err)
+	} else {
+		e.record("QueryAccount", nil, accountResponse{Account: account, Balances: account.Balances()}, nil)
+	}
+	return account, err
+}
+
+func (e *RecordingExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	balances, err := e.Exchange.QueryAccountBalances(ctx)
+	e.record("QueryAccountBalances", nil, balances, err)
+	return balances, err
+}
+
+func (e *RecordingExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	createdOrders, err := e.Exchange.SubmitOrders(ctx, orders...)
+	e.record("SubmitOrders", encodeArgs(orders), createdOrders, err)
+	return createdOrders, err
+}
+
+func (e *RecordingExchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	orders, err := e.Exchange.QueryOpenOrders(ctx, symbol)
+	e.record("QueryOpenOrders", encodeArgs(symbol), orders, err)
+	return orders, err
+}
+
+func (e *RecordingExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	err := e.Exchange.CancelOrders(ctx, orders...)
+	e.record("CancelOrders", encodeArgs(orders), nil, err)
+	return err
+}
@@ -0,0 +1,321 @@
+package replay
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// service is a bit of the optional services of the wrapped exchange
+type service uint
+
+const (
+	serviceOrderQuery service = 1 << iota
+	serviceTradeHistory
+	serviceDefaultFeeRates
+	serviceDepth
+	serviceOrderReplace
+	serviceTransfer
+	serviceWithdrawal
+	serviceReward
+	serviceMargin
+	serviceMarginBorrowRepay
+	serviceMarginHistory
+	serviceFutures
+	serviceFuturesPosition
+)
+
+func servicesOf(ex types.Exchange) (s service) {
+	if _, ok := ex.(types.ExchangeOrderQueryService); ok {
+		s |= serviceOrderQuery
+	}
+	if _, ok := ex.(types.ExchangeTradeHistoryService); ok {
+		s |= serviceTradeHistory
+	}
+	if _, ok := ex.(types.ExchangeDefaultFeeRates); ok {
+		s |= serviceDefaultFeeRates
+	}
+	if _, ok := ex.(types.ExchangeDepthService); ok {
+		s |= serviceDepth
+	}
+	if _, ok := ex.(types.ExchangeOrderReplaceService); ok {
+		s |= serviceOrderReplace
+	}
+	if _, ok := ex.(types.ExchangeTransferService); ok {
+		s |= serviceTransfer
+	}
+	if _, ok := ex.(types.ExchangeWithdrawalService); ok {
+		s |= serviceWithdrawal
+	}
+	if _, ok := ex.(types.ExchangeRewardService); ok {
+		s |= serviceReward
+	}
+	if _, ok := ex.(types.MarginExchange); ok {
+		s |= serviceMargin
+	}
+	if _, ok := ex.(types.MarginBorrowRepayService); ok {
+		s |= serviceMarginBorrowRepay
+	}
+	if _, ok := ex.(types.MarginHistory); ok {
+		s |= serviceMarginHistory
+	}
+	if _, ok := ex.(types.FuturesExchange); ok {
+		s |= serviceFutures
+	}
+	if _, ok := ex.(types.FuturesService); ok {
+		s |= serviceFuturesPosition
+	}
+	return s
+}
+
+func (s service) count() (n int) {
+	for ; s > 0; s &= s - 1 {
+		n++
+	}
+	return n
+}
+
+// serviceSet is a combination of the optional services that the recording exchange can expose.
+// Go can not add methods to a type at runtime, so a struct type is defined for each combination of the exchanges,
+// an exchange with a new combination needs a new service set, otherwise some of its services are not exposed.
+type serviceSet struct {
+	services service
+	wrap     func(e *RecordingExchange) types.Exchange
+}
+
+const (
+	historyServices = serviceOrderQuery | serviceTradeHistory
+	marginServices  = serviceMargin | serviceMarginBorrowRepay | serviceMarginHistory
+)
+
+var serviceSets = []serviceSet{
+	// ftx
+	{historyServices, func(e *RecordingExchange) types.Exchange {
+		return &historyRecordingExchange{
+			RecordingExchange:    e,
+			orderQueryRecorder:   orderQueryRecorder{e},
+			tradeHistoryRecorder: tradeHistoryRecorder{e},
+		}
+	}},
+
+	// bybit, paper
+	{historyServices | serviceDefaultFeeRates, func(e *RecordingExchange) types.Exchange {
+		return &feeRatesRecordingExchange{
+			RecordingExchange:       e,
+			orderQueryRecorder:      orderQueryRecorder{e},
+			tradeHistoryRecorder:    tradeHistoryRecorder{e},
+			ExchangeDefaultFeeRates: e.Exchange.(types.ExchangeDefaultFeeRates),
+		}
+	}},
+
+	// coinbase
+	{historyServices | serviceDefaultFeeRates | serviceTransfer, func(e *RecordingExchange) types.Exchange {
+		return &transferRecordingExchange{
+			RecordingExchange:       e,
+			orderQueryRecorder:      orderQueryRecorder{e},
+			tradeHistoryRecorder:    tradeHistoryRecorder{e},
+			ExchangeDefaultFeeRates: e.Exchange.(types.ExchangeDefaultFeeRates),
+			ExchangeTransferService: e.Exchange.(types.ExchangeTransferService),
+		}
+	}},
+
+	// kucoin
+	{historyServices | serviceDepth | marginServices, func(e *RecordingExchange) types.Exchange {
+		return &depthMarginRecordingExchange{
+			RecordingExchange:        e,
+			orderQueryRecorder:       orderQueryRecorder{e},
+			tradeHistoryRecorder:     tradeHistoryRecorder{e},
+			ExchangeDepthService:     e.Exchange.(types.ExchangeDepthService),
+			MarginExchange:           e.Exchange.(types.MarginExchange),
+			MarginBorrowRepayService: e.Exchange.(types.MarginBorrowRepayService),
+			MarginHistory:            e.Exchange.(types.MarginHistory),
+		}
+	}},
+
+	// okex
+	{historyServices | serviceOrderReplace | marginServices, func(e *RecordingExchange) types.Exchange {
+		return &replaceMarginRecordingExchange{
+			RecordingExchange:           e,
+			orderQueryRecorder:          orderQueryRecorder{e},
+			tradeHistoryRecorder:        tradeHistoryRecorder{e},
+			ExchangeOrderReplaceService: e.Exchange.(types.ExchangeOrderReplaceService),
+			MarginExchange:              e.Exchange.(types.MarginExchange),
+			MarginBorrowRepayService:    e.Exchange.(types.MarginBorrowRepayService),
+			MarginHistory:               e.Exchange.(types.MarginHistory),
+		}
+	}},
+
+	// max
+	{historyServices | serviceDefaultFeeRates | serviceTransfer | serviceWithdrawal | serviceReward | serviceMargin | serviceMarginBorrowRepay, func(e *RecordingExchange) types.Exchange {
+		return &walletMarginRecordingExchange{
+			RecordingExchange:         e,
+			orderQueryRecorder:        orderQueryRecorder{e},
+			tradeHistoryRecorder:      tradeHistoryRecorder{e},
+			ExchangeDefaultFeeRates:   e.Exchange.(types.ExchangeDefaultFeeRates),
+			ExchangeTransferService:   e.Exchange.(types.ExchangeTransferService),
+			ExchangeWithdrawalService: e.Exchange.(types.ExchangeWithdrawalService),
+			ExchangeRewardService:     e.Exchange.(types.ExchangeRewardService),
+			MarginExchange:            e.Exchange.(types.MarginExchange),
+			MarginBorrowRepayService:  e.Exchange.(types.MarginBorrowRepayService),
+		}
+	}},
+
+	// binance
+	{historyServices | serviceDefaultFeeRates | serviceDepth | serviceOrderReplace | serviceTransfer | serviceWithdrawal | serviceReward | marginServices | serviceFutures | serviceFuturesPosition, func(e *RecordingExchange) types.Exchange {
+		return &fullRecordingExchange{
+			RecordingExchange:           e,
+			orderQueryRecorder:          orderQueryRecorder{e},
+			tradeHistoryRecorder:        tradeHistoryRecorder{e},
+			ExchangeDefaultFeeRates:     e.Exchange.(types.ExchangeDefaultFeeRates),
+			ExchangeDepthService:        e.Exchange.(types.ExchangeDepthService),
+			ExchangeOrderReplaceService: e.Exchange.(types.ExchangeOrderReplaceService),
+			ExchangeTransferService:     e.Exchange.(types.ExchangeTransferService),
+			ExchangeWithdrawalService:   e.Exchange.(types.ExchangeWithdrawalService),
+			ExchangeRewardService:       e.Exchange.(types.ExchangeRewardService),
+			MarginExchange:              e.Exchange.(types.MarginExchange),
+			MarginBorrowRepayService:    e.Exchange.(types.MarginBorrowRepayService),
+			MarginHistory:               e.Exchange.(types.MarginHistory),
+			FuturesExchange:             e.Exchange.(types.FuturesExchange),
+			FuturesService:              e.Exchange.(types.FuturesService),
+		}
+	}},
+}
+
+// newServiceExchange returns the recording exchange with the largest service set that the wrapped exchange implements,
+// so that the recording exchange never claims a service that the wrapped exchange does not have
+func newServiceExchange(e *RecordingExchange) types.Exchange {
+	services := servicesOf(e.Exchange)
+
+	var best *serviceSet
+	for i, set := range serviceSets {
+		if set.services&^services != 0 {
+			continue
+		}
+
+		if best == nil || set.services.count() > best.services.count() {
+			best = &serviceSets[i]
+		}
+	}
+
+	if (best == nil && services != 0) || (best != nil && best.services != services) {
+		log.Warnf("the recording exchange does not support all the services of %s, some of the services are not exposed", e.Name())
+	}
+
+	if best == nil {
+		return e
+	}
+
+	return best.wrap(e)
+}
+
+// orderQueryRecorder records the order queries, it's only embedded when the wrapped exchange supports querying order
+type orderQueryRecorder struct {
+	e *RecordingExchange
+}
+
+func (r orderQueryRecorder) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	order, err := r.e.Exchange.(types.ExchangeOrderQueryService).QueryOrder(ctx, q)
+	r.e.record("QueryOrder", encodeArgs(q), order, err)
+	return order, err
+}
+
+// tradeHistoryRecorder records the trade and closed order queries,
+// it's only embedded when the wrapped exchange supports the trade history
+type tradeHistoryRecorder struct {
+	e *RecordingExchange
+}
+
+func (r tradeHistoryRecorder) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	trades, err := r.e.Exchange.(types.ExchangeTradeHistoryService).QueryTrades(ctx, symbol, options)
+	r.e.record("QueryTrades", encodeArgs(symbol, options), trades, err)
+	return trades, err
+}
+
+func (r tradeHistoryRecorder) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	orders, err := r.e.Exchange.(types.ExchangeTradeHistoryService).QueryClosedOrders(ctx, symbol, since, until, lastOrderID)
+	r.e.record("QueryClosedOrders", encodeArgs(symbol, since, until, lastOrderID), orders, err)
+	return orders, err
+}
+
+var _ types.ExchangeOrderQueryService = &historyRecordingExchange{}
+var _ types.ExchangeTradeHistoryService = &historyRecordingExchange{}
+var _ types.ExchangeDefaultFeeRates = &feeRatesRecordingExchange{}
+var _ types.ExchangeTransferService = &transferRecordingExchange{}
+var _ types.MarginHistory = &depthMarginRecordingExchange{}
+var _ types.ExchangeOrderReplaceService = &replaceMarginRecordingExchange{}
+var _ types.ExchangeWithdrawalService = &walletMarginRecordingExchange{}
+var _ types.FuturesService = &fullRecordingExchange{}
+var _ types.FuturesExchange = &fullRecordingExchange{}
+
+// the services other than the order query and the trade history are forwarded to the wrapped exchange without recording
+
+type historyRecordingExchange struct {
+	*RecordingExchange
+	orderQueryRecorder
+	tradeHistoryRecorder
+}
+
+type feeRatesRecordingExchange struct {
+	*RecordingExchange
+	orderQueryRecorder
+	tradeHistoryRecorder
+	types.ExchangeDefaultFeeRates
+}
+
+type transferRecordingExchange struct {
+	*RecordingExchange
+	orderQueryRecorder
+	tradeHistoryRecorder
+	types.ExchangeDefaultFeeRates
+	types.ExchangeTransferService
+}
+
+type depthMarginRecordingExchange struct {
+	*RecordingExchange
+	orderQueryRecorder
+	tradeHistoryRecorder
+	types.ExchangeDepthService
+	types.MarginExchange
+	types.MarginBorrowRepayService
+	types.MarginHistory
+}
+
+type replaceMarginRecordingExchange struct {
+	*RecordingExchange
+	orderQueryRecorder
+	tradeHistoryRecorder
+	types.ExchangeOrderReplaceService
+	types.MarginExchange
+	types.MarginBorrowRepayService
+	types.MarginHistory
+}
+
+type walletMarginRecordingExchange struct {
+	*RecordingExchange
+	orderQueryRecorder
+	tradeHistoryRecorder
+	types.ExchangeDefaultFeeRates
+	types.ExchangeTransferService
+	types.ExchangeWithdrawalService
+	types.ExchangeRewardService
+	types.MarginExchange
+	types.MarginBorrowRepayService
+}
+
+type fullRecordingExchange struct {
+	*RecordingExchange
+	orderQueryRecorder
+	tradeHistoryRecorder
+	types.ExchangeDefaultFeeRates
+	types.ExchangeDepthService
+	types.ExchangeOrderReplaceService
+	types.ExchangeTransferService
+	types.ExchangeWithdrawalService
+	types.ExchangeRewardService
+	types.MarginExchange
+	types.MarginBorrowRepayService
+	types.MarginHistory
+	types.FuturesExchange
+	types.FuturesService
+}
@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddCoinbaseKlines, downAddCoinbaseKlines)
+
+}
+
+func upAddCoinbaseKlines(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `coinbase_klines` LIKE `binance_klines`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddCoinbaseKlines(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `coinbase_klines`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,44 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upMarketTrades, downMarketTrades)
+
+}
+
+func upMarketTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `market_trades`\n(\n    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,\n    `id`             INTEGER        NOT NULL,\n    `exchange`       TEXT           NOT NULL DEFAULT '',\n    `symbol`         TEXT           NOT NULL,\n    `price`          DECIMAL(16, 8) NOT NULL,\n    `quantity`       DECIMAL(16, 8) NOT NULL,\n    `quote_quantity` DECIMAL(16, 8) NOT NULL,\n    `side`           VARCHAR(4)     NOT NULL DEFAULT '',\n    `is_buyer`       BOOLEAN        NOT NULL DEFAULT FALSE,\n    `is_maker`       BOOLEAN        NOT NULL DEFAULT FALSE,\n    `traded_at`      DATETIME(3)    NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX market_trades_unique_id ON market_trades (exchange, symbol, id);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX market_trades_traded_at_symbol ON market_trades (exchange, traded_at, symbol);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downMarketTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `market_trades`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
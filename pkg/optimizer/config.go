@@ -1,11 +1,15 @@
 package optimizer
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 type SelectorConfig struct {
@@ -22,15 +26,121 @@ type LocalExecutorConfig struct {
 	MaxNumberOfProcesses int `json:"maxNumberOfProcesses" yaml:"maxNumberOfProcesses"`
 }
 
+// RemoteExecutorConfig configures the worker processes started by the optimizer-worker command
+type RemoteExecutorConfig struct {
+	// Workers are the base urls of the workers, e.g. http://192.168.1.2:8090
+	Workers []string `json:"workers" yaml:"workers"`
+
+	// Token is the shared token of the workers, which is the --token option of the optimizer-worker command,
+	// defaults to the BBGO_OPTIMIZER_WORKER_TOKEN environment variable
+	Token string `json:"token" yaml:"token"`
+
+	// MaxTasksPerWorker is the number of the tasks sent to a worker concurrently, it's limited by
+	// the --max-processes option of the worker, zero means using the --max-processes option of the worker
+	MaxTasksPerWorker int `json:"maxTasksPerWorker" yaml:"maxTasksPerWorker"`
+
+	// MaxRetries is the max number of times a task is retried when its worker is lost, defaults to 2, -1 disables the retry
+	MaxRetries int `json:"maxRetries" yaml:"maxRetries"`
+
+	HeartbeatInterval types.Duration `json:"heartbeatInterval" yaml:"heartbeatInterval"`
+	PollInterval      types.Duration `json:"pollInterval" yaml:"pollInterval"`
+
+	// TaskTimeout is the max running time of a task, the task is retried when it's timed out, zero means no timeout
+	TaskTimeout types.Duration `json:"taskTimeout,omitempty" yaml:"taskTimeout,omitempty"`
+
+	// WorkerTimeout is how long the queued tasks wait when no worker is healthy, the tasks fail after the timeout
+	WorkerTimeout types.Duration `json:"workerTimeout" yaml:"workerTimeout"`
+}
+
 type ExecutorConfig struct {
-	Type                string               `json:"type" yaml:"type"`
-	LocalExecutorConfig *LocalExecutorConfig `json:"local" yaml:"local"`
+	Type                 string                `json:"type" yaml:"type"`
+	LocalExecutorConfig  *LocalExecutorConfig  `json:"local" yaml:"local"`
+	RemoteExecutorConfig *RemoteExecutorConfig `json:"remote" yaml:"remote"`
+}
+
+const (
+	SearchTypeGrid   = "grid"
+	SearchTypeRandom = "random"
+	SearchTypeTPE    = "tpe"
+)
+
+// SearchConfig selects the algorithm used for searching the parameters in the matrix
+type SearchConfig struct {
+	// Type is the search algorithm, one of grid, random and tpe, defaults to grid
+	Type string `json:"type" yaml:"type"`
+
+	// MaxTrials is the trial budget of the random and tpe search, the resumed trials are counted
+	MaxTrials int `json:"maxTrials,omitempty" yaml:"maxTrials,omitempty"`
+
+	// Objective is the metric key maximized by the random and tpe search,
+	// defaults to the weighted objective if it's defined, otherwise totalProfit.
+	// The metrics that are better when they are lower (maxDrawdown) can not be used as the objective.
+	Objective string `json:"objective,omitempty" yaml:"objective,omitempty"`
+
+	// Seed is the random seed, the current time is used when it's zero
+	Seed int64 `json:"seed,omitempty" yaml:"seed,omitempty"`
+
+	// NumOfStartupTrials is the number of the random trials before the tpe search starts modeling, defaults to 10
+	NumOfStartupTrials int `json:"numOfStartupTrials,omitempty" yaml:"numOfStartupTrials,omitempty"`
+
+	// Gamma is the ratio of the trials treated as the good trials in the tpe search, defaults to 0.25
+	Gamma float64 `json:"gamma,omitempty" yaml:"gamma,omitempty"`
+
+	// NumOfCandidates is the number of the candidates drawn from the good trials distribution, defaults to 24
+	NumOfCandidates int `json:"numOfCandidates,omitempty" yaml:"numOfCandidates,omitempty"`
+
+	EarlyStopping *EarlyStoppingConfig `json:"earlyStopping,omitempty" yaml:"earlyStopping,omitempty"`
+
+	// ResultsFile is the JSON lines file for recording the trial results,
+	// the recorded trials are loaded and skipped when the search is restarted
+	ResultsFile string `json:"resultsFile,omitempty" yaml:"resultsFile,omitempty"`
+}
+
+// EarlyStoppingConfig stops the search when the objective is not improved by MinDelta in the last Patience trials
+type EarlyStoppingConfig struct {
+	Patience int              `json:"patience" yaml:"patience"`
+	MinDelta fixedpoint.Value `json:"minDelta,omitempty" yaml:"minDelta,omitempty"`
+}
+
+// ObjectiveConfig defines the weighted objective metric and the constraints of the back-test results
+type ObjectiveConfig struct {
+	// Weights is the weight of each metric, the "objective" metric is the weighted sum of the metric values.
+	// Use a negative weight for the metric that is better when it's lower, e.g. maxDrawdown.
+	Weights map[string]fixedpoint.Value `json:"weights,omitempty" yaml:"weights,omitempty"`
+
+	// Constraints filters out the back-test results that do not satisfy the conditions, e.g. "maxDrawdown < 20%"
+	Constraints []string `json:"constraints,omitempty" yaml:"constraints,omitempty"`
+}
+
+// WalkForwardConfig splits the back-test time range into rolling windows,
+// the parameters are optimized in the in-sample range and evaluated in the following out-of-sample range.
+type WalkForwardConfig struct {
+	// InSampleDays is the length of the in-sample range in days
+	InSampleDays int `json:"inSampleDays" yaml:"inSampleDays"`
+
+	// OutOfSampleDays is the length of the out-of-sample range in days
+	OutOfSampleDays int `json:"outOfSampleDays" yaml:"outOfSampleDays"`
+
+	// StepDays is the days the window moves forward, defaults to OutOfSampleDays,
+	// it can not be less than OutOfSampleDays
+	StepDays int `json:"stepDays,omitempty" yaml:"stepDays,omitempty"`
+
+	// Anchored makes all the in-sample ranges start from the back-test start time
+	Anchored bool `json:"anchored,omitempty" yaml:"anchored,omitempty"`
+
+	// Objective is the metric key used for selecting the best parameters (the highest value),
+	// defaults to the weighted objective if it's defined, otherwise totalProfit.
+	// The metrics that are better when they are lower (maxDrawdown) can not be used as the objective.
+	Objective string `json:"objective,omitempty" yaml:"objective,omitempty"`
 }
 
 type Config struct {
-	Executor  *ExecutorConfig  `json:"executor" yaml:"executor"`
-	MaxThread int              `yaml:"maxThread,omitempty"`
-	Matrix    []SelectorConfig `yaml:"matrix"`
+	Executor    *ExecutorConfig    `json:"executor" yaml:"executor"`
+	MaxThread   int                `yaml:"maxThread,omitempty"`
+	Matrix      []SelectorConfig   `yaml:"matrix"`
+	Objective   *ObjectiveConfig   `json:"objective,omitempty" yaml:"objective,omitempty"`
+	Search      *SearchConfig      `json:"search,omitempty" yaml:"search,omitempty"`
+	WalkForward *WalkForwardConfig `json:"walkForward,omitempty" yaml:"walkForward,omitempty"`
 }
 
 var defaultExecutorConfig = &ExecutorConfig{
@@ -61,9 +171,138 @@ func LoadConfig(yamlConfigFileName string) (*Config, error) {
 		optConfig.Executor.Type = "local"
 	}
 
-	if optConfig.Executor.Type == "local" && optConfig.Executor.LocalExecutorConfig == nil {
-		optConfig.Executor.LocalExecutorConfig = defaultLocalExecutorConfig
+	switch optConfig.Executor.Type {
+	case "local":
+		if optConfig.Executor.LocalExecutorConfig == nil {
+			optConfig.Executor.LocalExecutorConfig = defaultLocalExecutorConfig
+		}
+
+	case "remote":
+		if optConfig.Executor.RemoteExecutorConfig == nil || len(optConfig.Executor.RemoteExecutorConfig.Workers) == 0 {
+			return nil, fmt.Errorf("executor.remote.workers is not defined")
+		}
+
+		optConfig.Executor.RemoteExecutorConfig.setDefaults()
+
+		if optConfig.Executor.RemoteExecutorConfig.Token == "" {
+			return nil, fmt.Errorf("executor.remote.token is not defined, set it or the %s environment variable", WorkerTokenEnvKey)
+		}
+
+	default:
+		return nil, fmt.Errorf("executor type %s is not supported", optConfig.Executor.Type)
+	}
+
+	evaluator, err := newMetricEvaluator(optConfig.Objective)
+	if err != nil {
+		return nil, err
+	}
+
+	if optConfig.Search == nil {
+		optConfig.Search = &SearchConfig{}
+	}
+
+	if err := optConfig.Search.setDefaults(evaluator); err != nil {
+		return nil, err
+	}
+
+	if optConfig.WalkForward != nil {
+		if optConfig.WalkForward.InSampleDays <= 0 || optConfig.WalkForward.OutOfSampleDays <= 0 {
+			return nil, fmt.Errorf("walkForward.inSampleDays and walkForward.outOfSampleDays must be positive")
+		}
+
+		if optConfig.WalkForward.StepDays == 0 {
+			optConfig.WalkForward.StepDays = optConfig.WalkForward.OutOfSampleDays
+		}
+
+		// the out-of-sample ranges can not overlap, otherwise the equity curve can not be stitched
+		if optConfig.WalkForward.StepDays < optConfig.WalkForward.OutOfSampleDays {
+			return nil, fmt.Errorf("walkForward.stepDays can not be less than walkForward.outOfSampleDays")
+		}
+
+		if optConfig.WalkForward.Objective == "" {
+			optConfig.WalkForward.Objective = evaluator.defaultObjective()
+		}
+
+		if err := evaluator.checkObjective(optConfig.WalkForward.Objective); err != nil {
+			return nil, fmt.Errorf("walkForward.%w", err)
+		}
 	}
 
 	return &optConfig, nil
 }
+
+func (c *RemoteExecutorConfig) setDefaults() {
+	if c.Token == "" {
+		c.Token = os.Getenv(WorkerTokenEnvKey)
+	}
+
+	if c.MaxTasksPerWorker < 0 {
+		c.MaxTasksPerWorker = 0
+	}
+
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = 2
+	}
+
+	if c.HeartbeatInterval == 0 {
+		c.HeartbeatInterval = types.Duration(5 * time.Second)
+	}
+
+	if c.PollInterval == 0 {
+		c.PollInterval = types.Duration(time.Second)
+	}
+
+	if c.WorkerTimeout == 0 {
+		c.WorkerTimeout = types.Duration(5 * time.Minute)
+	}
+}
+
+func (c *SearchConfig) setDefaults(evaluator *metricEvaluator) error {
+	if c.Type == "" {
+		c.Type = SearchTypeGrid
+	}
+
+	switch c.Type {
+	case SearchTypeGrid:
+		return nil
+
+	case SearchTypeRandom, SearchTypeTPE:
+
+	default:
+		return fmt.Errorf("search.type %s is not supported", c.Type)
+	}
+
+	if c.MaxTrials <= 0 {
+		return fmt.Errorf("search.maxTrials must be positive")
+	}
+
+	if c.Objective == "" {
+		c.Objective = evaluator.defaultObjective()
+	}
+
+	if err := evaluator.checkObjective(c.Objective); err != nil {
+		return fmt.Errorf("search.%w", err)
+	}
+
+	if c.NumOfStartupTrials == 0 {
+		c.NumOfStartupTrials = 10
+	}
+
+	if c.Gamma == 0 {
+		c.Gamma = 0.25
+	} else if c.Gamma < 0 || c.Gamma >= 1 {
+		return fmt.Errorf("search.gamma must be between 0 and 1")
+	}
+
+	if c.NumOfCandidates == 0 {
+		c.NumOfCandidates = 24
+	}
+
+	if c.EarlyStopping != nil && c.EarlyStopping.Patience <= 0 {
+		return fmt.Errorf("search.earlyStopping.patience must be positive")
+	}
+
+	return nil
+}
@@ -30,6 +30,47 @@ var TotalVolume = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
 	return buyVolume.Add(sellVolume)
 }
 
+var MaxDrawdown = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
+	return summaryReport.MaxDrawdown
+}
+
+var SharpeRatio = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
+	return summaryReport.SharpeRatio
+}
+
+var SortinoRatio = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
+	return summaryReport.SortinoRatio
+}
+
+var CalmarRatio = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
+	return summaryReport.CalmarRatio
+}
+
+var ProfitFactor = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
+	return summaryReport.ProfitFactor
+}
+
+var WinRate = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
+	return summaryReport.WinRate
+}
+
+var NumOfTrades = func(summaryReport *backtest.SummaryReport) fixedpoint.Value {
+	return fixedpoint.NewFromInt(int64(summaryReport.NumOfTrades))
+}
+
+// metricValueFunctions is the metric value functions indexed by the metric key
+var metricValueFunctions = map[string]MetricValueFunc{
+	"totalProfit":  TotalProfitMetricValueFunc,
+	"totalVolume":  TotalVolume,
+	"maxDrawdown":  MaxDrawdown,
+	"sharpeRatio":  SharpeRatio,
+	"sortinoRatio": SortinoRatio,
+	"calmarRatio":  CalmarRatio,
+	"profitFactor": ProfitFactor,
+	"winRate":      WinRate,
+	"numOfTrades":  NumOfTrades,
+}
+
 type Metric struct {
 	// Labels is the labels of the given parameters
 	Labels []string `json:"labels,omitempty"`
@@ -185,12 +226,13 @@ func (o *GridOptimizer) buildOps() []OpFunc {
 func (o *GridOptimizer) Run(executor Executor, configJson []byte) (map[string][]Metric, error) {
 	o.CurrentParams = make([]interface{}, len(o.Config.Matrix))
 
-	var valueFunctions = map[string]MetricValueFunc{
-		"totalProfit": TotalProfitMetricValueFunc,
-		"totalVolume": TotalVolume,
-	}
 	var metrics = map[string][]Metric{}
 
+	evaluator, err := newMetricEvaluator(o.Config.Objective)
+	if err != nil {
+		return nil, err
+	}
+
 	var ops = o.buildOps()
 
 	var taskC = make(chan BacktestTask, 10000)
@@ -246,8 +288,13 @@ func (o *GridOptimizer) Run(executor Executor, configJson []byte) (map[string][]
 			continue
 		}
 
-		for metricKey, metricFunc := range valueFunctions {
-			var metricValue = metricFunc(result.Report)
+		values, err := evaluator.evaluate(result.Report)
+		if err != nil {
+			log.Infof("params %+v are filtered out: %v", result.Params, err)
+			continue
+		}
+
+		for metricKey, metricValue := range values {
 			bar.Set("log", fmt.Sprintf("params: %+v => %s %+v", result.Params, metricKey, metricValue))
 
 			metrics[metricKey] = append(metrics[metricKey], Metric{
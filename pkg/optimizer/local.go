@@ -53,7 +53,7 @@ func (e *LocalProcessExecutor) ExecuteAsync(configJson []byte) *AsyncHandle {
 
 	go func() {
 		defer close(handle.Done)
-		report, err := e.execute(configJson)
+		report, err := e.Execute(configJson)
 		handle.Error = err
 		handle.Report = report
 	}()
@@ -123,7 +123,7 @@ func (e *LocalProcessExecutor) Run(ctx context.Context, taskC chan BacktestTask,
 					bar.Set("log", fmt.Sprintf("local worker #%d received param task: %v", id, task.Params))
 					bar.Write()
 
-					report, err := e.execute(task.ConfigJson)
+					report, err := e.Execute(task.ConfigJson)
 					if err != nil {
 						if err2, ok := err.(*exec.ExitError); ok {
 							log.WithError(err).Errorf("execute error: %s", err2.Stderr)
@@ -144,9 +144,9 @@ func (e *LocalProcessExecutor) Run(ctx context.Context, taskC chan BacktestTask,
 	return resultsC, nil
 }
 
-// execute runs the config json and returns the summary report
+// Execute runs the config json and returns the summary report
 // this is a blocking operation
-func (e *LocalProcessExecutor) execute(configJson []byte) (*backtest.SummaryReport, error) {
+func (e *LocalProcessExecutor) Execute(configJson []byte) (*backtest.SummaryReport, error) {
 	tf, err := jsonToYamlConfig(e.ConfigDir, configJson)
 	if err != nil {
 		return nil, err
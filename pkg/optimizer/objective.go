@@ -0,0 +1,176 @@
+package optimizer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// ObjectiveMetricKey is the metric key of the weighted objective defined in ObjectiveConfig
+const ObjectiveMetricKey = "objective"
+
+var constraintRegExp = regexp.MustCompile(`^\s*(\w+)\s*(<=|>=|==|!=|<|>)\s*(\S+)\s*$`)
+
+type constraint struct {
+	key   string
+	op    string
+	value fixedpoint.Value
+	text  string
+}
+
+// parseConstraint parses the constraint expression like "maxDrawdown < 20%"
+func parseConstraint(text string) (*constraint, error) {
+	matches := constraintRegExp.FindStringSubmatch(text)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid constraint %q, the format is: <metric> <operator> <value>", text)
+	}
+
+	value, err := fixedpoint.NewFromString(matches[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %q: %w", text, err)
+	}
+
+	return &constraint{
+		key:   matches[1],
+		op:    matches[2],
+		value: value,
+		text:  text,
+	}, nil
+}
+
+func (c *constraint) satisfied(v fixedpoint.Value) bool {
+	cmp := v.Compare(c.value)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	}
+	return false
+}
+
+// lowerIsBetterMetrics is the metric keys that are better when they are lower
+var lowerIsBetterMetrics = map[string]bool{
+	"maxDrawdown": true,
+}
+
+// metricEvaluator calculates the metric values of the summary report,
+// including the weighted objective, and checks the constraints
+type metricEvaluator struct {
+	weights     map[string]fixedpoint.Value
+	constraints []constraint
+}
+
+func newMetricEvaluator(config *ObjectiveConfig) (*metricEvaluator, error) {
+	var e = &metricEvaluator{}
+	if config == nil {
+		return e, nil
+	}
+
+	for key, weight := range config.Weights {
+		if _, ok := metricValueFunctions[key]; !ok {
+			return nil, fmt.Errorf("objective weight metric %s is not supported", key)
+		}
+
+		if e.weights == nil {
+			e.weights = map[string]fixedpoint.Value{}
+		}
+		e.weights[key] = weight
+	}
+
+	for _, text := range config.Constraints {
+		c, err := parseConstraint(text)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.hasMetric(c.key) {
+			return nil, fmt.Errorf("constraint metric %s is not supported", c.key)
+		}
+
+		e.constraints = append(e.constraints, *c)
+	}
+
+	return e, nil
+}
+
+func (e *metricEvaluator) hasMetric(key string) bool {
+	if key == ObjectiveMetricKey {
+		return len(e.weights) > 0
+	}
+
+	_, ok := metricValueFunctions[key]
+	return ok
+}
+
+// checkObjective checks if the metric can be used as the objective, which is always maximized.
+// The metrics that are better when they are lower can only be used in the weighted objective with a negative weight.
+func (e *metricEvaluator) checkObjective(key string) error {
+	if !e.hasMetric(key) {
+		return fmt.Errorf("objective %s is not supported", key)
+	}
+
+	if lowerIsBetterMetrics[key] {
+		return fmt.Errorf("objective %s is better when it's lower, use the weighted objective with a negative weight instead", key)
+	}
+
+	return nil
+}
+
+// defaultObjective returns the weighted objective when the weights are defined, otherwise the total profit
+func (e *metricEvaluator) defaultObjective() string {
+	if len(e.weights) > 0 {
+		return ObjectiveMetricKey
+	}
+
+	return "totalProfit"
+}
+
+// values returns all the metric values of the summary report
+func (e *metricEvaluator) values(report *backtest.SummaryReport) map[string]fixedpoint.Value {
+	var values = map[string]fixedpoint.Value{}
+	for metricKey, metricFunc := range metricValueFunctions {
+		values[metricKey] = metricFunc(report)
+	}
+
+	if len(e.weights) > 0 {
+		// sum in the key order, so that the result is deterministic
+		var keys []string
+		for key := range e.weights {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var objective fixedpoint.Value
+		for _, key := range keys {
+			objective = objective.Add(values[key].Mul(e.weights[key]))
+		}
+		values[ObjectiveMetricKey] = objective
+	}
+
+	return values
+}
+
+// evaluate returns all the metric values of the summary report,
+// an error is returned when the report does not satisfy the constraints
+func (e *metricEvaluator) evaluate(report *backtest.SummaryReport) (map[string]fixedpoint.Value, error) {
+	values := e.values(report)
+	for _, c := range e.constraints {
+		if !c.satisfied(values[c.key]) {
+			return values, fmt.Errorf("constraint %q is not satisfied: %s = %v", c.text, c.key, values[c.key])
+		}
+	}
+
+	return values, nil
+}
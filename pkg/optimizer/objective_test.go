@@ -0,0 +1,70 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func Test_parseConstraint(t *testing.T) {
+	c, err := parseConstraint("maxDrawdown < 20%")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "maxDrawdown", c.key)
+		assert.Equal(t, "<", c.op)
+		assert.Equal(t, "0.2", c.value.String())
+		assert.True(t, c.satisfied(fixedpoint.NewFromFloat(0.1)))
+		assert.False(t, c.satisfied(fixedpoint.NewFromFloat(0.2)))
+	}
+
+	c, err = parseConstraint("numOfTrades>=10")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ">=", c.op)
+		assert.True(t, c.satisfied(fixedpoint.NewFromInt(10)))
+	}
+
+	_, err = parseConstraint("maxDrawdown is small")
+	assert.Error(t, err)
+}
+
+func Test_metricEvaluator(t *testing.T) {
+	evaluator, err := newMetricEvaluator(&ObjectiveConfig{
+		Weights: map[string]fixedpoint.Value{
+			"sharpeRatio": fixedpoint.One,
+			"maxDrawdown": fixedpoint.NewFromInt(-2),
+		},
+		Constraints: []string{"maxDrawdown < 20%", "objective > 0"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ObjectiveMetricKey, evaluator.defaultObjective())
+	assert.NoError(t, evaluator.checkObjective(ObjectiveMetricKey))
+	assert.NoError(t, evaluator.checkObjective("sharpeRatio"))
+	assert.Error(t, evaluator.checkObjective("maxDrawdown"))
+	assert.Error(t, evaluator.checkObjective("unknown"))
+
+	values, err := evaluator.evaluate(&backtest.SummaryReport{
+		SharpeRatio: fixedpoint.NewFromFloat(1.5),
+		MaxDrawdown: fixedpoint.NewFromFloat(0.1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3", values[ObjectiveMetricKey].String())
+	assert.Equal(t, "1.5", values["sharpeRatio"].String())
+
+	_, err = evaluator.evaluate(&backtest.SummaryReport{
+		SharpeRatio: fixedpoint.NewFromFloat(1.5),
+		MaxDrawdown: fixedpoint.NewFromFloat(0.3),
+	})
+	assert.Error(t, err)
+
+	_, err = newMetricEvaluator(&ObjectiveConfig{
+		Weights: map[string]fixedpoint.Value{"unknown": fixedpoint.One},
+	})
+	assert.Error(t, err)
+
+	_, err = newMetricEvaluator(&ObjectiveConfig{
+		Constraints: []string{"objective > 0"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,463 @@
+package optimizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+)
+
+// errTaskLost is returned when the task should be retried on another worker
+var errTaskLost = errors.New("task lost")
+
+// errTaskTimeout is returned when the task is not finished in the task timeout, the task is retried but the worker is still healthy
+var errTaskTimeout = errors.New("task timeout")
+
+// errWorkerBusy is returned when the worker is running its max number of tasks,
+// the task is queued again without using up a retry
+var errWorkerBusy = errors.New("worker is busy")
+
+type remoteWorker struct {
+	url string
+
+	mu         sync.Mutex
+	healthy    bool
+	instanceID string
+	maxTasks   int
+}
+
+func (w *remoteWorker) isHealthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.healthy
+}
+
+func (w *remoteWorker) setHealthy(healthy bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.healthy != healthy {
+		if healthy {
+			log.Infof("worker %s is healthy", w.url)
+		} else {
+			log.Warnf("worker %s is unhealthy", w.url)
+		}
+	}
+	w.healthy = healthy
+}
+
+type remoteSlot struct {
+	id     int
+	worker *remoteWorker
+}
+
+type remoteTask struct {
+	task    BacktestTask
+	retries int
+}
+
+type remoteOutcome struct {
+	slot   *remoteSlot
+	task   *remoteTask
+	report *backtest.SummaryReport
+	err    error
+}
+
+// RemoteExecutor dispatches the back-test tasks to the optimizer workers over http.
+// The workers are checked by the heartbeat, and the tasks of the lost workers are retried on the other workers.
+type RemoteExecutor struct {
+	Config *RemoteExecutorConfig
+	Client *http.Client
+
+	workers []*remoteWorker
+}
+
+func (e *RemoteExecutor) client() *http.Client {
+	if e.Client != nil {
+		return e.Client
+	}
+	return http.DefaultClient
+}
+
+func (e *RemoteExecutor) initWorkers() {
+	if e.workers != nil {
+		return
+	}
+
+	for _, url := range e.Config.Workers {
+		e.workers = append(e.workers, &remoteWorker{url: strings.TrimSuffix(url, "/")})
+	}
+}
+
+// Prepare asks all the workers to sync the back-test data
+// this is a blocking operation
+func (e *RemoteExecutor) Prepare(configJson []byte) error {
+	e.initWorkers()
+
+	var wg sync.WaitGroup
+	var errs = make([]error, len(e.workers))
+	for i, w := range e.workers {
+		wg.Add(1)
+		go func(i int, w *remoteWorker) {
+			defer wg.Done()
+			errs[i] = e.post(context.Background(), w.url+"/prepare", configJson, nil)
+		}(i, w)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("worker %s prepare error: %w", e.workers[i].url, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *RemoteExecutor) Run(ctx context.Context, taskC chan BacktestTask, bar *pb.ProgressBar) (chan BacktestTask, error) {
+	e.initWorkers()
+
+	var numOfHealthyWorkers = 0
+	for _, w := range e.workers {
+		if e.checkHealth(ctx, w) {
+			numOfHealthyWorkers++
+		}
+	}
+
+	if numOfHealthyWorkers == 0 {
+		return nil, fmt.Errorf("no healthy optimizer worker")
+	}
+
+	var freeSlots []*remoteSlot
+	for _, w := range e.workers {
+		for i := 0; i < e.numOfSlots(w); i++ {
+			freeSlots = append(freeSlots, &remoteSlot{id: len(freeSlots) + 1, worker: w})
+		}
+	}
+
+	var resultsC = make(chan BacktestTask, len(freeSlots)*2)
+	var outcomeC = make(chan remoteOutcome, len(freeSlots))
+
+	runCtx, cancelRun := context.WithCancel(ctx)
+	for _, w := range e.workers {
+		go e.heartbeat(runCtx, w)
+	}
+
+	go func() {
+		defer close(resultsC)
+		defer cancelRun()
+
+		var queue []*remoteTask
+		var busySlots []*remoteSlot
+		var numOfOutstanding = 0
+		var taskInputC = taskC
+		var lastHealthyTime = time.Now()
+
+		ticker := time.NewTicker(e.Config.HeartbeatInterval.Duration())
+		defer ticker.Stop()
+
+		for {
+			// assign the queued tasks to the free slots of the healthy workers
+			for len(queue) > 0 {
+				slot := takeHealthySlot(&freeSlots)
+				if slot == nil {
+					break
+				}
+
+				task := queue[0]
+				queue = queue[1:]
+
+				bar.Set("log", fmt.Sprintf("remote worker %s slot #%d received param task: %v", slot.worker.url, slot.id, task.task.Params))
+				bar.Write()
+
+				go func() {
+					report, err := e.runTask(runCtx, slot.worker, task.task)
+					outcomeC <- remoteOutcome{slot: slot, task: task, report: report, err: err}
+				}()
+			}
+
+			if taskInputC == nil && len(queue) == 0 && numOfOutstanding == 0 {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case task, ok := <-taskInputC:
+				if !ok {
+					taskInputC = nil
+					continue
+				}
+
+				queue = append(queue, &remoteTask{task: task})
+				numOfOutstanding++
+
+			case outcome := <-outcomeC:
+				// the slot of the busy worker is not used until the next heartbeat tick, the task is queued again
+				if errors.Is(outcome.err, errWorkerBusy) {
+					log.WithError(outcome.err).Debugf("worker %s is busy, requeue task %v", outcome.slot.worker.url, outcome.task.task.Params)
+					busySlots = append(busySlots, outcome.slot)
+					queue = append([]*remoteTask{outcome.task}, queue...)
+					continue
+				}
+
+				freeSlots = append(freeSlots, outcome.slot)
+
+				if errors.Is(outcome.err, errTaskLost) {
+					outcome.slot.worker.setHealthy(false)
+				}
+
+				if errors.Is(outcome.err, errTaskLost) || errors.Is(outcome.err, errTaskTimeout) {
+					if outcome.task.retries < e.Config.MaxRetries {
+						outcome.task.retries++
+						log.WithError(outcome.err).Warnf("retrying task %v (%d/%d)", outcome.task.task.Params, outcome.task.retries, e.Config.MaxRetries)
+						queue = append(queue, outcome.task)
+						continue
+					}
+				}
+
+				if outcome.err != nil {
+					log.WithError(outcome.err).Errorf("remote task error, params: %v", outcome.task.task.Params)
+				}
+
+				task := outcome.task.task
+				task.Report = outcome.report
+				task.Error = outcome.err
+				numOfOutstanding--
+				resultsC <- task
+
+			case <-ticker.C:
+				freeSlots = append(freeSlots, busySlots...)
+				busySlots = nil
+
+				if hasHealthyWorker(e.workers) {
+					lastHealthyTime = time.Now()
+					continue
+				}
+
+				if len(queue) > 0 && time.Since(lastHealthyTime) > e.Config.WorkerTimeout.Duration() {
+					log.Errorf("no healthy worker in %s, %d queued tasks failed", e.Config.WorkerTimeout.Duration(), len(queue))
+					for _, task := range queue {
+						t := task.task
+						t.Error = fmt.Errorf("no healthy worker")
+						numOfOutstanding--
+						resultsC <- t
+					}
+					queue = nil
+				}
+			}
+		}
+	}()
+
+	return resultsC, nil
+}
+
+// numOfSlots returns the number of the tasks sent to the worker concurrently, it's limited by the max tasks of the worker
+// reported by the health check, MaxTasksPerWorker is used when the worker is not reachable at the beginning
+func (e *RemoteExecutor) numOfSlots(w *remoteWorker) int {
+	w.mu.Lock()
+	maxTasks := w.maxTasks
+	w.mu.Unlock()
+
+	n := e.Config.MaxTasksPerWorker
+	if maxTasks > 0 && (n == 0 || maxTasks < n) {
+		n = maxTasks
+	}
+
+	if n <= 0 {
+		n = 1
+	}
+
+	return n
+}
+
+func takeHealthySlot(slots *[]*remoteSlot) *remoteSlot {
+	for i, slot := range *slots {
+		if slot.worker.isHealthy() {
+			*slots = append((*slots)[:i], (*slots)[i+1:]...)
+			return slot
+		}
+	}
+	return nil
+}
+
+func hasHealthyWorker(workers []*remoteWorker) bool {
+	for _, w := range workers {
+		if w.isHealthy() {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RemoteExecutor) heartbeat(ctx context.Context, w *remoteWorker) {
+	ticker := time.NewTicker(e.Config.HeartbeatInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			e.checkHealth(ctx, w)
+		}
+	}
+}
+
+func (e *RemoteExecutor) checkHealth(ctx context.Context, w *remoteWorker) bool {
+	ctx, cancel := context.WithTimeout(ctx, e.Config.HeartbeatInterval.Duration())
+	defer cancel()
+
+	var health WorkerHealth
+	if err := e.get(ctx, w.url+"/health", &health); err != nil {
+		log.WithError(err).Debugf("worker %s heartbeat error", w.url)
+		w.setHealthy(false)
+		return false
+	}
+
+	w.mu.Lock()
+	if w.instanceID != "" && w.instanceID != health.InstanceID {
+		log.Warnf("worker %s is restarted", w.url)
+	}
+	w.instanceID = health.InstanceID
+	w.maxTasks = health.MaxTasks
+	w.mu.Unlock()
+
+	w.setHealthy(true)
+	return true
+}
+
+// runTask submits the task to the worker and polls the task status until it's finished,
+// errTaskLost is returned when the worker can not be reached or the task is not found on the worker,
+// errWorkerBusy is returned when the worker rejects the task because it's busy.
+func (e *RemoteExecutor) runTask(ctx context.Context, w *remoteWorker, task BacktestTask) (*backtest.SummaryReport, error) {
+	req := WorkerTaskRequest{
+		ConfigJson: task.ConfigJson,
+		Params:     task.Params,
+		Labels:     task.Labels,
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp WorkerTaskResponse
+	if err := e.post(ctx, w.url+"/tasks", body, &resp); err != nil {
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusServiceUnavailable {
+			return nil, fmt.Errorf("%w: %v", errWorkerBusy, err)
+		}
+
+		return nil, fmt.Errorf("%w: submit task error: %v", errTaskLost, err)
+	}
+
+	var parentCtx = ctx
+	if taskTimeout := e.Config.TaskTimeout.Duration(); taskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, taskTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(e.Config.PollInterval.Duration())
+	defer ticker.Stop()
+
+	const maxPollErrors = 3
+	var numOfPollErrors = 0
+	for {
+		select {
+		case <-ctx.Done():
+			if parentCtx.Err() == nil {
+				return nil, fmt.Errorf("%w: task %s on worker %s", errTaskTimeout, resp.ID, w.url)
+			}
+
+			return nil, fmt.Errorf("%w: %v", errTaskLost, ctx.Err())
+
+		case <-ticker.C:
+			var status WorkerTaskResponse
+			if err := e.get(ctx, w.url+"/tasks/"+resp.ID, &status); err != nil {
+				var statusErr *httpStatusError
+				if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+					return nil, fmt.Errorf("%w: task %s not found on worker %s", errTaskLost, resp.ID, w.url)
+				}
+
+				numOfPollErrors++
+				if numOfPollErrors >= maxPollErrors {
+					return nil, fmt.Errorf("%w: poll task error: %v", errTaskLost, err)
+				}
+				continue
+			}
+
+			numOfPollErrors = 0
+
+			switch status.Status {
+			case WorkerTaskStatusDone:
+				return status.Report, nil
+
+			case WorkerTaskStatusFailed:
+				return nil, errors.New(status.Error)
+			}
+		}
+	}
+}
+
+type httpStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("http status %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *RemoteExecutor) get(ctx context.Context, url string, o interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	return e.do(req, o)
+}
+
+func (e *RemoteExecutor) post(ctx context.Context, url string, body []byte, o interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return e.do(req, o)
+}
+
+func (e *RemoteExecutor) do(req *http.Request, o interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+e.Config.Token)
+
+	resp, err := e.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return &httpStatusError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+
+	if o == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(o)
+}
@@ -0,0 +1,254 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type fakeRunner struct {
+	mu              sync.Mutex
+	numOfPrepares   int
+	numOfExecutions int
+	err             error
+	delay           time.Duration
+}
+
+func (r *fakeRunner) Prepare(configJson []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.numOfPrepares++
+	return nil
+}
+
+func (r *fakeRunner) Execute(configJson []byte) (*backtest.SummaryReport, error) {
+	time.Sleep(r.delay)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.numOfExecutions++
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &backtest.SummaryReport{
+		Symbols: []string{string(configJson)},
+	}, nil
+}
+
+const testWorkerToken = "test-token"
+
+func newTestRemoteExecutor(urls ...string) *RemoteExecutor {
+	config := &RemoteExecutorConfig{
+		Workers:           urls,
+		Token:             testWorkerToken,
+		MaxTasksPerWorker: 2,
+		MaxRetries:        3,
+		HeartbeatInterval: types.Duration(50 * time.Millisecond),
+		PollInterval:      types.Duration(10 * time.Millisecond),
+		WorkerTimeout:     types.Duration(time.Second),
+	}
+	config.setDefaults()
+	return &RemoteExecutor{Config: config}
+}
+
+func runRemoteTasks(t *testing.T, executor *RemoteExecutor, numOfTasks int) []BacktestTask {
+	taskC := make(chan BacktestTask)
+	resultsC, err := executor.Run(context.Background(), taskC, pb.New(numOfTasks))
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	go func() {
+		defer close(taskC)
+		for i := 0; i < numOfTasks; i++ {
+			taskC <- BacktestTask{
+				ConfigJson: []byte{byte('a' + i)},
+				Params:     []interface{}{i},
+			}
+		}
+	}()
+
+	var results []BacktestTask
+	for task := range resultsC {
+		results = append(results, task)
+	}
+	return results
+}
+
+func TestRemoteExecutor_Run(t *testing.T) {
+	runner1, runner2 := &fakeRunner{}, &fakeRunner{}
+	worker1 := httptest.NewServer(NewWorkerServer(runner1, 2, testWorkerToken).Handler())
+	defer worker1.Close()
+	worker2 := httptest.NewServer(NewWorkerServer(runner2, 2, testWorkerToken).Handler())
+	defer worker2.Close()
+
+	executor := newTestRemoteExecutor(worker1.URL, worker2.URL)
+	assert.NoError(t, executor.Prepare([]byte(`{}`)))
+	assert.Equal(t, 1, runner1.numOfPrepares)
+	assert.Equal(t, 1, runner2.numOfPrepares)
+
+	results := runRemoteTasks(t, executor, 10)
+	assert.Len(t, results, 10)
+	for _, task := range results {
+		if assert.NoError(t, task.Error) && assert.NotNil(t, task.Report) {
+			// the report belongs to the config of the task
+			assert.Equal(t, []string{string(task.ConfigJson)}, task.Report.Symbols)
+		}
+	}
+
+	assert.Equal(t, 10, runner1.numOfExecutions+runner2.numOfExecutions)
+}
+
+func TestRemoteExecutor_RetryLostWorker(t *testing.T) {
+	goodRunner := &fakeRunner{}
+	goodWorker := httptest.NewServer(NewWorkerServer(goodRunner, 2, testWorkerToken).Handler())
+	defer goodWorker.Close()
+
+	// the worker crashes after it receives the first task
+	var crashed int32
+	crashingHandler := NewWorkerServer(&fakeRunner{}, 2, testWorkerToken).Handler()
+	crashingWorker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&crashed) == 1 {
+			http.Error(w, "crashed", http.StatusBadGateway)
+			return
+		}
+
+		if r.URL.Path == "/tasks" {
+			atomic.StoreInt32(&crashed, 1)
+		}
+		crashingHandler.ServeHTTP(w, r)
+	}))
+	defer crashingWorker.Close()
+
+	executor := newTestRemoteExecutor(crashingWorker.URL, goodWorker.URL)
+	results := runRemoteTasks(t, executor, 6)
+	assert.Len(t, results, 6)
+	for _, task := range results {
+		assert.NoError(t, task.Error)
+		assert.NotNil(t, task.Report)
+	}
+
+	assert.Equal(t, 6, goodRunner.numOfExecutions)
+}
+
+func TestRemoteExecutor_TaskError(t *testing.T) {
+	runner := &fakeRunner{err: errors.New("backtest error")}
+	worker := httptest.NewServer(NewWorkerServer(runner, 1, testWorkerToken).Handler())
+	defer worker.Close()
+
+	executor := newTestRemoteExecutor(worker.URL)
+	results := runRemoteTasks(t, executor, 2)
+	assert.Len(t, results, 2)
+	for _, task := range results {
+		assert.EqualError(t, task.Error, "backtest error")
+		assert.Nil(t, task.Report)
+	}
+
+	// the failed back-test is not retried
+	assert.Equal(t, 2, runner.numOfExecutions)
+}
+
+func TestRemoteExecutor_NoHealthyWorker(t *testing.T) {
+	worker := httptest.NewServer(http.NotFoundHandler())
+	worker.Close()
+
+	executor := newTestRemoteExecutor(worker.URL)
+	_, err := executor.Run(context.Background(), make(chan BacktestTask), pb.New(0))
+	assert.Error(t, err)
+}
+
+func TestRemoteExecutor_Unauthorized(t *testing.T) {
+	worker := httptest.NewServer(NewWorkerServer(&fakeRunner{}, 1, testWorkerToken).Handler())
+	defer worker.Close()
+
+	resp, err := http.Get(worker.URL + "/health")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	executor := newTestRemoteExecutor(worker.URL)
+	executor.Config.Token = "wrong-token"
+	_, err = executor.Run(context.Background(), make(chan BacktestTask), pb.New(0))
+	assert.Error(t, err)
+}
+
+func TestRemoteExecutor_RequeueBusyWorker(t *testing.T) {
+	runner := &fakeRunner{}
+	handler := NewWorkerServer(runner, 2, testWorkerToken).Handler()
+
+	// the worker is busy for the first 2 task submissions
+	var numOfRejected int32
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tasks" && atomic.AddInt32(&numOfRejected, 1) <= 2 {
+			http.Error(w, "worker is busy", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer worker.Close()
+
+	// the busy worker does not use up the retries
+	executor := newTestRemoteExecutor(worker.URL)
+	executor.Config.MaxRetries = 0
+
+	results := runRemoteTasks(t, executor, 4)
+	assert.Len(t, results, 4)
+	for _, task := range results {
+		assert.NoError(t, task.Error)
+		assert.NotNil(t, task.Report)
+	}
+
+	assert.Equal(t, 4, runner.numOfExecutions)
+	assert.True(t, executor.workers[0].isHealthy())
+}
+
+func TestRemoteExecutor_TaskTimeout(t *testing.T) {
+	runner := &fakeRunner{delay: 200 * time.Millisecond}
+	worker := httptest.NewServer(NewWorkerServer(runner, 1, testWorkerToken).Handler())
+	defer worker.Close()
+
+	executor := newTestRemoteExecutor(worker.URL)
+	executor.Config.MaxRetries = 0
+	executor.Config.TaskTimeout = types.Duration(50 * time.Millisecond)
+
+	results := runRemoteTasks(t, executor, 1)
+	if assert.Len(t, results, 1) {
+		assert.True(t, errors.Is(results[0].Error, errTaskTimeout))
+	}
+
+	// the worker is still healthy since the task is just slow
+	assert.True(t, executor.workers[0].isHealthy())
+}
+
+func TestRemoteExecutor_numOfSlots(t *testing.T) {
+	worker := httptest.NewServer(NewWorkerServer(&fakeRunner{}, 3, testWorkerToken).Handler())
+	defer worker.Close()
+
+	executor := newTestRemoteExecutor(worker.URL)
+	executor.initWorkers()
+
+	// MaxTasksPerWorker is used before the worker reports its max tasks
+	assert.Equal(t, 2, executor.numOfSlots(executor.workers[0]))
+
+	assert.True(t, executor.checkHealth(context.Background(), executor.workers[0]))
+	assert.Equal(t, 2, executor.numOfSlots(executor.workers[0]))
+
+	executor.Config.MaxTasksPerWorker = 0
+	assert.Equal(t, 3, executor.numOfSlots(executor.workers[0]))
+
+	executor.Config.MaxTasksPerWorker = 5
+	assert.Equal(t, 3, executor.numOfSlots(executor.workers[0]))
+}
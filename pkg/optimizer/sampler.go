@@ -0,0 +1,154 @@
+package optimizer
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type sampler interface {
+	// sample returns the value indexes of the next trial by the completed trials
+	sample(trials []Trial) []int
+}
+
+type randomSampler struct {
+	space *searchSpace
+	rand  *rand.Rand
+}
+
+func (s *randomSampler) sample(trials []Trial) []int {
+	return s.space.randomIndexes(s.rand)
+}
+
+// tpeSampler implements the tree-structured parzen estimator search.
+// The completed trials are split into the good trials and the bad trials by the objective value,
+// the candidates are drawn from the distribution of the good trials l(x),
+// and the candidate with the max l(x) / g(x) is selected, where g(x) is the distribution of the bad trials.
+// Each dimension is modeled independently.
+type tpeSampler struct {
+	space     *searchSpace
+	rand      *rand.Rand
+	objective string
+
+	numOfStartupTrials int
+	gamma              float64
+	numOfCandidates    int
+}
+
+func newTPESampler(space *searchSpace, config *SearchConfig, r *rand.Rand) *tpeSampler {
+	return &tpeSampler{
+		space:              space,
+		rand:               r,
+		objective:          config.Objective,
+		numOfStartupTrials: config.NumOfStartupTrials,
+		gamma:              config.Gamma,
+		numOfCandidates:    config.NumOfCandidates,
+	}
+}
+
+func (s *tpeSampler) sample(trials []Trial) []int {
+	var completed []Trial
+	for _, trial := range trials {
+		if _, ok := trial.objectiveValue(s.objective); ok && trial.indexes != nil {
+			completed = append(completed, trial)
+		}
+	}
+
+	if len(completed) < s.numOfStartupTrials || len(completed) < 2 {
+		return s.space.randomIndexes(s.rand)
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		a, _ := completed[i].objectiveValue(s.objective)
+		b, _ := completed[j].objectiveValue(s.objective)
+		return a.Compare(b) > 0
+	})
+
+	numOfGood := int(math.Ceil(s.gamma * float64(len(completed))))
+	if numOfGood < 1 {
+		numOfGood = 1
+	} else if numOfGood >= len(completed) {
+		numOfGood = len(completed) - 1
+	}
+
+	good, bad := completed[:numOfGood], completed[numOfGood:]
+
+	var goodDensities, badDensities [][]float64
+	for i, d := range s.space.dimensions {
+		goodDensities = append(goodDensities, density(d, i, good))
+		badDensities = append(badDensities, density(d, i, bad))
+	}
+
+	var best []int
+	var bestScore = math.Inf(-1)
+	for c := 0; c < s.numOfCandidates; c++ {
+		var candidate = make([]int, len(s.space.dimensions))
+		var score float64
+		for i := range s.space.dimensions {
+			j := sampleIndex(s.rand, goodDensities[i])
+			candidate[i] = j
+			score += math.Log(goodDensities[i][j]) - math.Log(badDensities[i][j])
+		}
+
+		if score > bestScore {
+			best = candidate
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// density estimates the probability of each value of the dimension from the trials,
+// the ordinal values are smoothed by a gaussian kernel, and a uniform prior is added to avoid zero probability.
+func density(d searchDimension, dimensionIndex int, trials []Trial) []float64 {
+	var n = len(d.values)
+	var weights = make([]float64, n)
+	for j := range weights {
+		weights[j] = 1.0
+	}
+
+	bandwidth := math.Max(1.0, float64(n)/10.0)
+	for _, trial := range trials {
+		idx := trial.indexes[dimensionIndex]
+		if !d.ordinal {
+			weights[idx] += 1.0
+			continue
+		}
+
+		var kernel = make([]float64, n)
+		var sum float64
+		for j := range kernel {
+			x := float64(j-idx) / bandwidth
+			kernel[j] = math.Exp(-0.5 * x * x)
+			sum += kernel[j]
+		}
+
+		for j := range kernel {
+			weights[j] += kernel[j] / sum
+		}
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	for j := range weights {
+		weights[j] /= total
+	}
+
+	return weights
+}
+
+func sampleIndex(r *rand.Rand, probabilities []float64) int {
+	x := r.Float64()
+	for j, p := range probabilities {
+		x -= p
+		if x < 0 {
+			return j
+		}
+	}
+
+	return len(probabilities) - 1
+}
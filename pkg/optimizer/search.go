@@ -0,0 +1,481 @@
+package optimizer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// Optimizer searches the parameters in the matrix and returns the metrics sorted by the metric value
+type Optimizer interface {
+	Run(executor Executor, configJson []byte) (map[string][]Metric, error)
+}
+
+// NewOptimizer creates the optimizer of the configured search type
+func NewOptimizer(config *Config) Optimizer {
+	if config.Search != nil {
+		switch config.Search.Type {
+		case SearchTypeRandom, SearchTypeTPE:
+			return &SearchOptimizer{Config: config}
+		}
+	}
+
+	return &GridOptimizer{Config: config}
+}
+
+// Trial is the result of one parameter set, it's recorded in the results file as a JSON line
+type Trial struct {
+	Labels  []string                    `json:"labels"`
+	Params  []interface{}               `json:"params"`
+	Metrics map[string]fixedpoint.Value `json:"metrics,omitempty"`
+	Error   string                      `json:"error,omitempty"`
+
+	indexes []int
+}
+
+func (t *Trial) objectiveValue(objective string) (fixedpoint.Value, bool) {
+	if t.Error != "" {
+		return fixedpoint.Zero, false
+	}
+
+	v, ok := t.Metrics[objective]
+	return v, ok
+}
+
+type searchDimension struct {
+	label  string
+	path   string
+	values []interface{}
+
+	// ordinal is true when the values are ordered, so that the neighbor values are similar
+	ordinal bool
+}
+
+// searchSpace is the discrete parameter space of the matrix, a point in the space is the value indexes of the dimensions
+type searchSpace struct {
+	dimensions []searchDimension
+}
+
+func newSearchSpace(matrix []SelectorConfig) (*searchSpace, error) {
+	var space = &searchSpace{}
+	for _, selector := range matrix {
+		dimension := searchDimension{
+			label: selector.Label,
+			path:  selector.Path,
+		}
+
+		if dimension.label == "" {
+			dimension.label = selector.Path
+		}
+
+		switch selector.Type {
+		case "range":
+			step := selector.Step
+			if step.IsZero() {
+				step = fixedpoint.One
+			}
+
+			for val := selector.Min; val.Compare(selector.Max) <= 0; val = val.Add(step) {
+				dimension.values = append(dimension.values, val)
+			}
+			dimension.ordinal = true
+
+		case "iterate":
+			for _, val := range selector.Values {
+				dimension.values = append(dimension.values, val)
+			}
+
+		case "bool":
+			dimension.values = []interface{}{true, false}
+
+		default:
+			return nil, fmt.Errorf("selector type %s of %s is not supported", selector.Type, selector.Path)
+		}
+
+		if len(dimension.values) == 0 {
+			return nil, fmt.Errorf("selector %s has no value", selector.Path)
+		}
+
+		space.dimensions = append(space.dimensions, dimension)
+	}
+
+	return space, nil
+}
+
+func (s *searchSpace) labels() (labels []string) {
+	for _, d := range s.dimensions {
+		labels = append(labels, d.label)
+	}
+	return labels
+}
+
+func (s *searchSpace) params(indexes []int) (params []interface{}) {
+	for i, d := range s.dimensions {
+		params = append(params, d.values[indexes[i]])
+	}
+	return params
+}
+
+// indexes finds the value indexes of the params, the params may be decoded from the results file,
+// so the values are compared by their JSON encoding
+func (s *searchSpace) indexes(params []interface{}) ([]int, bool) {
+	if len(params) != len(s.dimensions) {
+		return nil, false
+	}
+
+	var indexes = make([]int, len(params))
+	for i, d := range s.dimensions {
+		found := false
+		for j, v := range d.values {
+			if canonicalJson(v) == canonicalJson(params[i]) {
+				indexes[i] = j
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, false
+		}
+	}
+
+	return indexes, true
+}
+
+// size returns the number of the points in the space, it's capped at math.MaxInt32
+func (s *searchSpace) size() int {
+	size := 1
+	for _, d := range s.dimensions {
+		size *= len(d.values)
+		if size > 1<<31-1 {
+			return 1<<31 - 1
+		}
+	}
+	return size
+}
+
+func (s *searchSpace) randomIndexes(r *rand.Rand) []int {
+	var indexes = make([]int, len(s.dimensions))
+	for i, d := range s.dimensions {
+		indexes[i] = r.Intn(len(d.values))
+	}
+	return indexes
+}
+
+func indexesKey(indexes []int) string {
+	return fmt.Sprint(indexes)
+}
+
+func canonicalJson(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	var o interface{}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return string(data)
+	}
+
+	data, _ = json.Marshal(o)
+	return string(data)
+}
+
+// earlyStopper counts the trials since the last improvement of the objective
+type earlyStopper struct {
+	config *EarlyStoppingConfig
+
+	best           fixedpoint.Value
+	hasBest        bool
+	numOfNoImprove int
+}
+
+func (s *earlyStopper) update(value fixedpoint.Value) (stop bool) {
+	if !s.hasBest || value.Compare(s.best.Add(s.config.MinDelta)) > 0 {
+		s.best = value
+		s.hasBest = true
+		s.numOfNoImprove = 0
+		return false
+	}
+
+	s.numOfNoImprove++
+	return s.numOfNoImprove >= s.config.Patience
+}
+
+// SearchOptimizer runs the random or the tpe search within the trial budget,
+// the parameters of the next trial are sampled from the trials completed so far.
+type SearchOptimizer struct {
+	Config *Config
+}
+
+func (o *SearchOptimizer) Run(executor Executor, configJson []byte) (map[string][]Metric, error) {
+	searchConfig := o.Config.Search
+
+	space, err := newSearchSpace(o.Config.Matrix)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator, err := newMetricEvaluator(o.Config.Objective)
+	if err != nil {
+		return nil, err
+	}
+
+	trials, err := loadTrials(searchConfig.ResultsFile, space)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(trials) > 0 {
+		log.Infof("resumed %d trials from %s", len(trials), searchConfig.ResultsFile)
+	}
+
+	var resultsWriter *json.Encoder
+	if searchConfig.ResultsFile != "" {
+		f, err := os.OpenFile(searchConfig.ResultsFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		resultsWriter = json.NewEncoder(f)
+	}
+
+	seed := searchConfig.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	var smp sampler
+	switch searchConfig.Type {
+	case SearchTypeTPE:
+		smp = newTPESampler(space, searchConfig, rand.New(rand.NewSource(seed)))
+	default:
+		smp = &randomSampler{space: space, rand: rand.New(rand.NewSource(seed))}
+	}
+
+	var stopper *earlyStopper
+	var stopped bool
+	if searchConfig.EarlyStopping != nil {
+		stopper = &earlyStopper{config: searchConfig.EarlyStopping}
+	}
+
+	var tried = map[string]struct{}{}
+	for _, trial := range trials {
+		tried[indexesKey(trial.indexes)] = struct{}{}
+		if stopper != nil {
+			if value, ok := trial.objectiveValue(searchConfig.Objective); ok && stopper.update(value) {
+				stopped = true
+			}
+		}
+	}
+
+	if stopped {
+		log.Infof("early stopped by the resumed trials")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var numOfTrials = len(trials)
+	var maxTrials = searchConfig.MaxTrials
+	if size := space.size(); maxTrials > size {
+		maxTrials = size
+	}
+
+	var taskC = make(chan BacktestTask)
+	var taskGenErr error
+
+	var bar = pb.Full.New(maxTrials)
+	bar.SetTemplateString(`{{ string . "log" | green}} | {{counters . }} {{bar . }} {{percent . }} {{etime . }} {{rtime . "ETA %s"}}`)
+	bar.SetCurrent(int64(numOfTrials))
+
+	go func() {
+		defer close(taskC)
+
+		for {
+			mu.Lock()
+			if stopped || numOfTrials >= maxTrials {
+				mu.Unlock()
+				return
+			}
+
+			indexes, ok := nextUntried(smp, trials, tried)
+			if !ok {
+				mu.Unlock()
+				return
+			}
+
+			tried[indexesKey(indexes)] = struct{}{}
+			numOfTrials++
+			mu.Unlock()
+
+			params := space.params(indexes)
+			patchedJson, err := patchParams(configJson, o.Config.Matrix, params)
+			if err != nil {
+				taskGenErr = err
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case taskC <- BacktestTask{
+				ConfigJson: patchedJson,
+				Params:     params,
+				Labels:     space.labels(),
+			}:
+			}
+		}
+	}()
+
+	resultsC, err := executor.Run(ctx, taskC, bar)
+	if err != nil {
+		return nil, err
+	}
+
+	for result := range resultsC {
+		bar.Increment()
+
+		trial := Trial{
+			Labels: result.Labels,
+			Params: result.Params,
+		}
+		trial.indexes, _ = space.indexes(result.Params)
+
+		if result.Report == nil {
+			log.Errorf("no summaryReport found for params: %+v", result.Params)
+			trial.Error = "no summary report"
+			if result.Error != nil {
+				trial.Error = result.Error.Error()
+			}
+		} else {
+			trial.Metrics, err = evaluator.evaluate(result.Report)
+			if err != nil {
+				log.Infof("params %+v are filtered out: %v", result.Params, err)
+				trial.Error = err.Error()
+			}
+
+			bar.Set("log", fmt.Sprintf("params: %+v => %s %+v", trial.Params, searchConfig.Objective, trial.Metrics[searchConfig.Objective]))
+		}
+
+		if resultsWriter != nil {
+			if err := resultsWriter.Encode(trial); err != nil {
+				log.WithError(err).Errorf("can not write the trial result")
+			}
+		}
+
+		mu.Lock()
+		trials = append(trials, trial)
+		if stopper != nil && !stopped {
+			if value, ok := trial.objectiveValue(searchConfig.Objective); ok && stopper.update(value) {
+				log.Infof("early stopped, %s is not improved in the last %d trials", searchConfig.Objective, searchConfig.EarlyStopping.Patience)
+				stopped = true
+				cancel()
+			}
+		}
+		mu.Unlock()
+	}
+	bar.Finish()
+
+	if taskGenErr != nil {
+		return nil, taskGenErr
+	}
+
+	return trialsToMetrics(trials), nil
+}
+
+// nextUntried samples the next point that is not tried yet, it returns false when no untried point is found
+func nextUntried(smp sampler, trials []Trial, tried map[string]struct{}) ([]int, bool) {
+	const maxAttempts = 1000
+	for i := 0; i < maxAttempts; i++ {
+		indexes := smp.sample(trials)
+		if _, ok := tried[indexesKey(indexes)]; !ok {
+			return indexes, true
+		}
+	}
+
+	return nil, false
+}
+
+func trialsToMetrics(trials []Trial) map[string][]Metric {
+	var metrics = map[string][]Metric{}
+	for _, trial := range trials {
+		if trial.Error != "" {
+			continue
+		}
+
+		for metricKey, value := range trial.Metrics {
+			metrics[metricKey] = append(metrics[metricKey], Metric{
+				Params: trial.Params,
+				Labels: trial.Labels,
+				Key:    metricKey,
+				Value:  value,
+			})
+		}
+	}
+
+	for n := range metrics {
+		sort.Slice(metrics[n], func(i, j int) bool {
+			a := metrics[n][i].Value
+			b := metrics[n][j].Value
+			return a.Compare(b) > 0
+		})
+	}
+
+	return metrics
+}
+
+// loadTrials loads the trials recorded in the results file, the trials not in the current search space are skipped
+func loadTrials(filename string, space *searchSpace) (trials []Trial, err error) {
+	if filename == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var trial Trial
+		if err := json.Unmarshal(line, &trial); err != nil {
+			return nil, fmt.Errorf("can not parse the trial result %s: %w", line, err)
+		}
+
+		indexes, ok := space.indexes(trial.Params)
+		if !ok {
+			log.Warnf("skipped the trial params %v, they are not in the search space", trial.Params)
+			continue
+		}
+
+		trial.indexes = indexes
+		trial.Params = space.params(indexes)
+		trial.Labels = space.labels()
+		trials = append(trials, trial)
+	}
+
+	return trials, scanner.Err()
+}
@@ -0,0 +1,139 @@
+package optimizer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func newTestSearchConfig(search *SearchConfig) *Config {
+	if err := search.setDefaults(&metricEvaluator{}); err != nil {
+		panic(err)
+	}
+
+	return &Config{
+		Matrix: []SelectorConfig{
+			{
+				Type: "range",
+				Path: "/window",
+				Min:  fixedpoint.NewFromInt(0),
+				Max:  fixedpoint.NewFromInt(100),
+				Step: fixedpoint.NewFromInt(1),
+			},
+		},
+		Search: search,
+	}
+}
+
+var testSearchConfigJson = []byte(`{ "window": 1 }`)
+
+func TestSearchOptimizer_Random(t *testing.T) {
+	executor := &fakeExecutor{}
+	optz := NewOptimizer(newTestSearchConfig(&SearchConfig{
+		Type:      SearchTypeRandom,
+		MaxTrials: 20,
+		Seed:      1,
+	}))
+
+	metrics, err := optz.Run(executor, testSearchConfigJson)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, executor.numOfTasks)
+	assert.Len(t, metrics["totalProfit"], 20)
+
+	// the params are not repeated
+	var seen = map[string]struct{}{}
+	for _, m := range metrics["totalProfit"] {
+		seen[canonicalJson(m.Params)] = struct{}{}
+	}
+	assert.Len(t, seen, 20)
+}
+
+func TestSearchOptimizer_Resume(t *testing.T) {
+	resultsFile := filepath.Join(t.TempDir(), "results.jsonl")
+
+	executor := &fakeExecutor{}
+	_, err := NewOptimizer(newTestSearchConfig(&SearchConfig{
+		Type:        SearchTypeRandom,
+		MaxTrials:   10,
+		Seed:        1,
+		ResultsFile: resultsFile,
+	})).Run(executor, testSearchConfigJson)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, executor.numOfTasks)
+
+	// only the rest of the trial budget is executed
+	executor = &fakeExecutor{}
+	metrics, err := NewOptimizer(newTestSearchConfig(&SearchConfig{
+		Type:        SearchTypeRandom,
+		MaxTrials:   15,
+		Seed:        1,
+		ResultsFile: resultsFile,
+	})).Run(executor, testSearchConfigJson)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, executor.numOfTasks)
+	assert.Len(t, metrics["totalProfit"], 15)
+
+	var seen = map[string]struct{}{}
+	for _, m := range metrics["totalProfit"] {
+		seen[canonicalJson(m.Params)] = struct{}{}
+	}
+	assert.Len(t, seen, 15)
+}
+
+func TestSearchOptimizer_EarlyStopping(t *testing.T) {
+	executor := &fakeExecutor{}
+	config := newTestSearchConfig(&SearchConfig{
+		Type:      SearchTypeRandom,
+		MaxTrials: 50,
+		Seed:      1,
+		EarlyStopping: &EarlyStoppingConfig{
+			Patience: 3,
+			MinDelta: fixedpoint.NewFromInt(1000),
+		},
+	})
+
+	// no trial can improve the first trial by 1000
+	_, err := NewOptimizer(config).Run(executor, testSearchConfigJson)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, executor.numOfTasks, 4)
+	assert.Less(t, executor.numOfTasks, 50)
+}
+
+func TestSearchOptimizer_TPE(t *testing.T) {
+	executor := &fakeExecutor{}
+	optz := NewOptimizer(newTestSearchConfig(&SearchConfig{
+		Type:               SearchTypeTPE,
+		MaxTrials:          30,
+		NumOfStartupTrials: 10,
+		Seed:               1,
+	}))
+
+	metrics, err := optz.Run(executor, testSearchConfigJson)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, executor.numOfTasks)
+
+	// the profit is max when the window is 20
+	best := metrics["totalProfit"][0]
+	assert.GreaterOrEqual(t, best.Value.Float64(), 98.0)
+}
+
+func Test_searchSpace_indexes(t *testing.T) {
+	space, err := newSearchSpace([]SelectorConfig{
+		{Type: "range", Path: "/a", Min: fixedpoint.NewFromFloat(0.1), Max: fixedpoint.NewFromFloat(0.3), Step: fixedpoint.NewFromFloat(0.1)},
+		{Type: "iterate", Path: "/b", Values: []string{"1m", "5m"}},
+		{Type: "bool", Path: "/c"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, space.size())
+
+	// the params decoded from the results file
+	indexes, ok := space.indexes([]interface{}{0.2, "5m", false})
+	assert.True(t, ok)
+	assert.Equal(t, []int{1, 1, 1}, indexes)
+
+	_, ok = space.indexes([]interface{}{0.4, "5m", false})
+	assert.False(t, ok)
+}
@@ -0,0 +1,351 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// WalkForwardWindow is the result of one in-sample optimization and its out-of-sample evaluation
+type WalkForwardWindow struct {
+	InSampleStartTime    time.Time `json:"inSampleStartTime"`
+	InSampleEndTime      time.Time `json:"inSampleEndTime"`
+	OutOfSampleStartTime time.Time `json:"outOfSampleStartTime"`
+	OutOfSampleEndTime   time.Time `json:"outOfSampleEndTime"`
+
+	// Labels and Params are the best parameters found in the in-sample range
+	Labels []string      `json:"labels,omitempty"`
+	Params []interface{} `json:"params,omitempty"`
+
+	// InSampleValue and OutOfSampleValue are the objective metric values of the best parameters
+	InSampleValue    fixedpoint.Value `json:"inSampleValue"`
+	OutOfSampleValue fixedpoint.Value `json:"outOfSampleValue"`
+
+	// InitialEquityValue and FinalEquityValue are the equity values of the out-of-sample back-test
+	InitialEquityValue fixedpoint.Value `json:"initialEquityValue"`
+	FinalEquityValue   fixedpoint.Value `json:"finalEquityValue"`
+}
+
+type EquityPoint struct {
+	Time   time.Time        `json:"time"`
+	Equity fixedpoint.Value `json:"equity"`
+}
+
+// ParamStability shows how often the best value of a parameter changes between the windows
+type ParamStability struct {
+	Label string `json:"label"`
+
+	// Values is the best value of each window
+	Values []interface{} `json:"values"`
+
+	NumOfDistinctValues int `json:"numOfDistinctValues"`
+
+	// MostFrequentValue is the value selected by the most windows,
+	// Stability is the ratio of these windows to all the windows
+	MostFrequentValue interface{} `json:"mostFrequentValue"`
+	Stability         float64     `json:"stability"`
+
+	// Mean and StdDev are only calculated for the numeric parameters
+	Mean   float64 `json:"mean,omitempty"`
+	StdDev float64 `json:"stdDev,omitempty"`
+}
+
+type WalkForwardReport struct {
+	Objective string              `json:"objective"`
+	Windows   []WalkForwardWindow `json:"windows"`
+
+	// EquityCurve is the stitched equity curve of the out-of-sample back-tests,
+	// the return of each window is compounded from the initial equity value of the first window
+	EquityCurve []EquityPoint `json:"equityCurve"`
+
+	ParamStability []ParamStability `json:"paramStability"`
+}
+
+type WalkForwardOptimizer struct {
+	Config *Config
+}
+
+func (o *WalkForwardOptimizer) Run(executor Executor, configJson []byte) (*WalkForwardReport, error) {
+	wfConfig := o.Config.WalkForward
+	if wfConfig == nil {
+		return nil, fmt.Errorf("walkForward config is not defined")
+	}
+
+	evaluator, err := newMetricEvaluator(o.Config.Objective)
+	if err != nil {
+		return nil, err
+	}
+
+	if !evaluator.hasMetric(wfConfig.Objective) {
+		return nil, fmt.Errorf("walkForward.objective %s is not supported", wfConfig.Objective)
+	}
+
+	startTime, endTime, err := parseBacktestTimeRange(configJson)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := buildWalkForwardWindows(wfConfig, startTime, endTime)
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("backtest time range %s ~ %s is too short for the walk-forward windows", startTime, endTime)
+	}
+
+	for i := range windows {
+		w := &windows[i]
+		log.Infof("walk-forward window #%d: in-sample %s ~ %s, out-of-sample %s ~ %s",
+			i+1, w.InSampleStartTime, w.InSampleEndTime, w.OutOfSampleStartTime, w.OutOfSampleEndTime)
+
+		inSampleJson, err := patchTimeRange(configJson, w.InSampleStartTime, w.InSampleEndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		optz := NewOptimizer(o.windowConfig(i))
+		metrics, err := optz.Run(executor, inSampleJson)
+		if err != nil {
+			return nil, err
+		}
+
+		best := metrics[wfConfig.Objective]
+		if len(best) == 0 {
+			return nil, fmt.Errorf("walk-forward window #%d: no in-sample result found", i+1)
+		}
+
+		w.Labels = best[0].Labels
+		w.Params = best[0].Params
+		w.InSampleValue = best[0].Value
+
+		outOfSampleJson, err := patchTimeRange(configJson, w.OutOfSampleStartTime, w.OutOfSampleEndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		outOfSampleJson, err = patchParams(outOfSampleJson, o.Config.Matrix, w.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		report, err := runTask(executor, BacktestTask{
+			ConfigJson: outOfSampleJson,
+			Params:     w.Params,
+			Labels:     w.Labels,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk-forward window #%d: out-of-sample backtest error: %w", i+1, err)
+		}
+
+		w.OutOfSampleValue = evaluator.values(report)[wfConfig.Objective]
+		w.InitialEquityValue = report.InitialEquityValue
+		w.FinalEquityValue = report.FinalEquityValue
+	}
+
+	return &WalkForwardReport{
+		Objective:      wfConfig.Objective,
+		Windows:        windows,
+		EquityCurve:    stitchEquityCurve(windows),
+		ParamStability: buildParamStability(windows),
+	}, nil
+}
+
+// windowConfig returns the optimizer config of the window, each window records its trials in its own results file
+func (o *WalkForwardOptimizer) windowConfig(i int) *Config {
+	if o.Config.Search == nil || o.Config.Search.ResultsFile == "" {
+		return o.Config
+	}
+
+	config := *o.Config
+	search := *o.Config.Search
+	ext := filepath.Ext(search.ResultsFile)
+	search.ResultsFile = fmt.Sprintf("%s-window%d%s", strings.TrimSuffix(search.ResultsFile, ext), i+1, ext)
+	config.Search = &search
+	return &config
+}
+
+// buildWalkForwardWindows splits the time range into the windows, the last window that exceeds the end time is dropped
+func buildWalkForwardWindows(config *WalkForwardConfig, startTime, endTime time.Time) (windows []WalkForwardWindow) {
+	for t := startTime; ; t = t.AddDate(0, 0, config.StepDays) {
+		inSampleStartTime := t
+		if config.Anchored {
+			inSampleStartTime = startTime
+		}
+
+		inSampleEndTime := t.AddDate(0, 0, config.InSampleDays)
+		outOfSampleEndTime := inSampleEndTime.AddDate(0, 0, config.OutOfSampleDays)
+		if outOfSampleEndTime.After(endTime) {
+			return windows
+		}
+
+		windows = append(windows, WalkForwardWindow{
+			InSampleStartTime:    inSampleStartTime,
+			InSampleEndTime:      inSampleEndTime,
+			OutOfSampleStartTime: inSampleEndTime,
+			OutOfSampleEndTime:   outOfSampleEndTime,
+		})
+	}
+}
+
+func parseBacktestTimeRange(configJson []byte) (startTime, endTime time.Time, err error) {
+	var config struct {
+		Backtest *bbgo.Backtest `json:"backtest"`
+	}
+
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return startTime, endTime, err
+	}
+
+	if config.Backtest == nil || config.Backtest.EndTime == nil {
+		return startTime, endTime, fmt.Errorf("walk-forward optimization requires backtest.startTime and backtest.endTime")
+	}
+
+	return config.Backtest.StartTime.Time(), config.Backtest.EndTime.Time(), nil
+}
+
+// patchTimeRange sets the back-test time range, the end time is moved back by one second
+// so that the in-sample range does not include the first kline of the out-of-sample range
+func patchTimeRange(configJson []byte, startTime, endTime time.Time) ([]byte, error) {
+	configJson, err := patchJsonValue(configJson, "add", "/backtest/startTime", startTime.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	return patchJsonValue(configJson, "add", "/backtest/endTime", endTime.Add(-time.Second).Format(time.RFC3339))
+}
+
+// patchParams applies the parameters to the paths of the matrix selectors
+func patchParams(configJson []byte, matrix []SelectorConfig, params []interface{}) ([]byte, error) {
+	if len(matrix) != len(params) {
+		return nil, fmt.Errorf("params length %d does not match the matrix length %d", len(params), len(matrix))
+	}
+
+	var err error
+	for i, selector := range matrix {
+		configJson, err = patchJsonValue(configJson, "replace", selector.Path, params[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return configJson, nil
+}
+
+func patchJsonValue(configJson []byte, op, path string, value interface{}) ([]byte, error) {
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := jsonpatch.DecodePatch([]byte(fmt.Sprintf(`[{"op": "%s", "path": "%s", "value": %s}]`, op, path, valueJson)))
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.ApplyIndent(configJson, "  ")
+}
+
+// runTask runs a single back-test task and returns its summary report
+func runTask(executor Executor, task BacktestTask) (*backtest.SummaryReport, error) {
+	var taskC = make(chan BacktestTask, 1)
+	taskC <- task
+	close(taskC)
+
+	var bar = pb.Full.New(1)
+	resultsC, err := executor.Run(context.Background(), taskC, bar)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *backtest.SummaryReport
+	for result := range resultsC {
+		bar.Increment()
+		report, err = result.Report, result.Error
+	}
+	bar.Finish()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if report == nil {
+		return nil, fmt.Errorf("no summaryReport found for params: %+v", task.Params)
+	}
+
+	return report, nil
+}
+
+func stitchEquityCurve(windows []WalkForwardWindow) (curve []EquityPoint) {
+	if len(windows) == 0 {
+		return nil
+	}
+
+	equity := windows[0].InitialEquityValue
+	curve = append(curve, EquityPoint{Time: windows[0].OutOfSampleStartTime, Equity: equity})
+	for _, w := range windows {
+		if w.InitialEquityValue.Sign() > 0 {
+			equity = equity.Mul(w.FinalEquityValue).Div(w.InitialEquityValue)
+		}
+
+		curve = append(curve, EquityPoint{Time: w.OutOfSampleEndTime, Equity: equity})
+	}
+
+	return curve
+}
+
+func buildParamStability(windows []WalkForwardWindow) (stabilities []ParamStability) {
+	if len(windows) == 0 {
+		return nil
+	}
+
+	for i, label := range windows[0].Labels {
+		stability := ParamStability{Label: label}
+
+		var counts = map[string]int{}
+		var maxCount = 0
+		var numbers []float64
+		for _, w := range windows {
+			value := w.Params[i]
+			stability.Values = append(stability.Values, value)
+
+			key := fmt.Sprint(value)
+			counts[key]++
+			if counts[key] > maxCount {
+				maxCount = counts[key]
+				stability.MostFrequentValue = value
+			}
+
+			if v, ok := value.(fixedpoint.Value); ok {
+				numbers = append(numbers, v.Float64())
+			}
+		}
+
+		stability.NumOfDistinctValues = len(counts)
+		stability.Stability = float64(maxCount) / float64(len(windows))
+
+		if len(numbers) == len(windows) {
+			var sum, sqSum float64
+			for _, n := range numbers {
+				sum += n
+			}
+			stability.Mean = sum / float64(len(numbers))
+
+			for _, n := range numbers {
+				sqSum += (n - stability.Mean) * (n - stability.Mean)
+			}
+			stability.StdDev = math.Sqrt(sqSum / float64(len(numbers)))
+		}
+
+		stabilities = append(stabilities, stability)
+	}
+
+	return stabilities
+}
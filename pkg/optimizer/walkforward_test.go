@@ -0,0 +1,128 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// fakeExecutor reports the profit of the window parameter, the equity grows by 10% in every back-test
+type fakeExecutor struct {
+	numOfTasks int
+}
+
+func (e *fakeExecutor) Run(ctx context.Context, taskC chan BacktestTask, bar *pb.ProgressBar) (chan BacktestTask, error) {
+	var resultsC = make(chan BacktestTask, 10)
+	go func() {
+		defer close(resultsC)
+		for task := range taskC {
+			e.numOfTasks++
+
+			var config struct {
+				Window fixedpoint.Value `json:"window"`
+			}
+			if err := json.Unmarshal(task.ConfigJson, &config); err != nil {
+				task.Error = err
+				resultsC <- task
+				continue
+			}
+
+			task.Report = &backtest.SummaryReport{
+				TotalProfit:        fixedpoint.NewFromInt(100).Sub(config.Window.Sub(fixedpoint.NewFromInt(20)).Abs()),
+				InitialEquityValue: fixedpoint.NewFromInt(1000),
+				FinalEquityValue:   fixedpoint.NewFromInt(1100),
+			}
+			resultsC <- task
+		}
+	}()
+	return resultsC, nil
+}
+
+func Test_buildWalkForwardWindows(t *testing.T) {
+	startTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	windows := buildWalkForwardWindows(&WalkForwardConfig{
+		InSampleDays:    30,
+		OutOfSampleDays: 10,
+		StepDays:        10,
+	}, startTime, endTime)
+	if assert.Len(t, windows, 2) {
+		assert.Equal(t, startTime, windows[0].InSampleStartTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 30), windows[0].InSampleEndTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 30), windows[0].OutOfSampleStartTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 40), windows[0].OutOfSampleEndTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 10), windows[1].InSampleStartTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 50), windows[1].OutOfSampleEndTime)
+	}
+
+	windows = buildWalkForwardWindows(&WalkForwardConfig{
+		InSampleDays:    30,
+		OutOfSampleDays: 10,
+		StepDays:        10,
+		Anchored:        true,
+	}, startTime, endTime)
+	if assert.Len(t, windows, 2) {
+		assert.Equal(t, startTime, windows[1].InSampleStartTime)
+		assert.Equal(t, startTime.AddDate(0, 0, 40), windows[1].InSampleEndTime)
+	}
+}
+
+func TestWalkForwardOptimizer_Run(t *testing.T) {
+	configJson := []byte(`{
+		"backtest": { "startTime": "2022-01-01", "endTime": "2022-03-01" },
+		"window": 1
+	}`)
+
+	optz := &WalkForwardOptimizer{
+		Config: &Config{
+			Matrix: []SelectorConfig{
+				{
+					Type: "range",
+					Path: "/window",
+					Min:  fixedpoint.NewFromInt(10),
+					Max:  fixedpoint.NewFromInt(30),
+					Step: fixedpoint.NewFromInt(5),
+				},
+			},
+			WalkForward: &WalkForwardConfig{
+				InSampleDays:    30,
+				OutOfSampleDays: 10,
+				StepDays:        10,
+				Objective:       "totalProfit",
+			},
+		},
+	}
+
+	executor := &fakeExecutor{}
+	report, err := optz.Run(executor, configJson)
+	assert.NoError(t, err)
+
+	// 5 in-sample back-tests and 1 out-of-sample back-test for each window
+	assert.Equal(t, 12, executor.numOfTasks)
+
+	if assert.Len(t, report.Windows, 2) {
+		assert.Equal(t, []string{"/window"}, report.Windows[0].Labels)
+		assert.Equal(t, "20", report.Windows[0].Params[0].(fixedpoint.Value).String())
+		assert.Equal(t, "100", report.Windows[0].InSampleValue.String())
+		assert.Equal(t, "100", report.Windows[0].OutOfSampleValue.String())
+	}
+
+	if assert.Len(t, report.EquityCurve, 3) {
+		assert.Equal(t, "1000", report.EquityCurve[0].Equity.String())
+		assert.Equal(t, "1210", report.EquityCurve[2].Equity.String())
+	}
+
+	if assert.Len(t, report.ParamStability, 1) {
+		assert.Equal(t, 1, report.ParamStability[0].NumOfDistinctValues)
+		assert.Equal(t, 1.0, report.ParamStability[0].Stability)
+		assert.Equal(t, 20.0, report.ParamStability[0].Mean)
+	}
+}
@@ -0,0 +1,226 @@
+package optimizer
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+)
+
+// BacktestRunner runs the back-test of the config json on the worker
+type BacktestRunner interface {
+	Prepare(configJson []byte) error
+	Execute(configJson []byte) (*backtest.SummaryReport, error)
+}
+
+// WorkerTokenEnvKey is the environment variable of the shared token between the optimizer and the workers
+const WorkerTokenEnvKey = "BBGO_OPTIMIZER_WORKER_TOKEN"
+
+type WorkerTaskStatus string
+
+const (
+	WorkerTaskStatusRunning = WorkerTaskStatus("running")
+	WorkerTaskStatusDone    = WorkerTaskStatus("done")
+	WorkerTaskStatusFailed  = WorkerTaskStatus("failed")
+)
+
+type WorkerTaskRequest struct {
+	ConfigJson []byte        `json:"configJson"`
+	Params     []interface{} `json:"params,omitempty"`
+	Labels     []string      `json:"labels,omitempty"`
+}
+
+type WorkerTaskResponse struct {
+	ID     string                  `json:"id"`
+	Status WorkerTaskStatus        `json:"status"`
+	Report *backtest.SummaryReport `json:"report,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+type WorkerHealth struct {
+	// InstanceID changes when the worker is restarted, the tasks of the previous instance are lost
+	InstanceID        string `json:"instanceID"`
+	NumOfRunningTasks int    `json:"numOfRunningTasks"`
+	MaxTasks          int    `json:"maxTasks"`
+}
+
+// WorkerServer is the http server of the optimizer worker, the tasks are executed asynchronously,
+// and the coordinator polls the task status until the task is finished.
+// All the requests must have the shared token in the "Authorization: Bearer <token>" header.
+//
+//	GET  /health      returns the WorkerHealth
+//	POST /prepare     syncs the back-test data of the config json
+//	POST /tasks       starts a task, 503 is returned when the worker is busy
+//	GET  /tasks/{id}  returns the task status, the finished task is removed after it's returned
+type WorkerServer struct {
+	Runner   BacktestRunner
+	MaxTasks int
+	Token    string
+
+	instanceID        string
+	numOfRunningTasks int
+	tasks             map[string]*WorkerTaskResponse
+	mu                sync.Mutex
+}
+
+func NewWorkerServer(runner BacktestRunner, maxTasks int, token string) *WorkerServer {
+	if maxTasks <= 0 {
+		maxTasks = 1
+	}
+
+	return &WorkerServer{
+		Runner:     runner,
+		MaxTasks:   maxTasks,
+		Token:      token,
+		instanceID: uuid.NewString(),
+		tasks:      make(map[string]*WorkerTaskResponse),
+	}
+}
+
+func (s *WorkerServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/prepare", s.handlePrepare)
+	mux.HandleFunc("/tasks", s.handleSubmitTask)
+	mux.HandleFunc("/tasks/", s.handleQueryTask)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// authorized checks the bearer token of the request, the requests are always rejected when the token is not set
+func (s *WorkerServer) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *WorkerServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	health := WorkerHealth{
+		InstanceID:        s.instanceID,
+		NumOfRunningTasks: s.numOfRunningTasks,
+		MaxTasks:          s.MaxTasks,
+	}
+	s.mu.Unlock()
+
+	writeJson(w, http.StatusOK, health)
+}
+
+func (s *WorkerServer) handlePrepare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	configJson, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Runner.Prepare(configJson); err != nil {
+		log.WithError(err).Errorf("worker prepare error")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *WorkerServer) handleSubmitTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req WorkerTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	if s.numOfRunningTasks >= s.MaxTasks {
+		s.mu.Unlock()
+		http.Error(w, "worker is busy", http.StatusServiceUnavailable)
+		return
+	}
+
+	task := &WorkerTaskResponse{
+		ID:     uuid.NewString(),
+		Status: WorkerTaskStatusRunning,
+	}
+	s.tasks[task.ID] = task
+	s.numOfRunningTasks++
+	resp := *task
+	s.mu.Unlock()
+
+	log.Infof("worker received task %s params: %v", task.ID, req.Params)
+
+	go func() {
+		report, err := s.Runner.Execute(req.ConfigJson)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.numOfRunningTasks--
+		if err != nil {
+			log.WithError(err).Errorf("worker task %s error", task.ID)
+			task.Status = WorkerTaskStatusFailed
+			task.Error = err.Error()
+			return
+		}
+
+		task.Status = WorkerTaskStatusDone
+		task.Report = report
+	}()
+
+	writeJson(w, http.StatusAccepted, resp)
+}
+
+func (s *WorkerServer) handleQueryTask(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/tasks/")
+
+	s.mu.Lock()
+	task, ok := s.tasks[id]
+	var resp WorkerTaskResponse
+	if ok {
+		resp = *task
+		if task.Status != WorkerTaskStatusRunning {
+			delete(s.tasks, id)
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("task %s not found", id), http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, http.StatusOK, resp)
+}
+
+func writeJson(w http.ResponseWriter, status int, o interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		log.WithError(err).Errorf("can not write the response")
+	}
+}
@@ -113,6 +113,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 
 		exchange := c.Query("exchange")
 		symbol := c.Query("symbol")
+		strategyInstanceID := c.Query("strategyInstanceID")
 		gidStr := c.DefaultQuery("gid", "0")
 		lastGID, err := strconv.ParseInt(gidStr, 10, 64)
 		if err != nil {
@@ -122,10 +123,11 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 		}
 
 		trades, err := s.Environ.TradeService.Query(service.QueryTradesOptions{
-			Exchange: types.ExchangeName(exchange),
-			Symbol:   symbol,
-			LastGID:  lastGID,
-			Ordering: "DESC",
+			Exchange:           types.ExchangeName(exchange),
+			Symbol:             symbol,
+			LastGID:            lastGID,
+			Ordering:           "DESC",
+			StrategyInstanceID: strategyInstanceID,
 		})
 		if err != nil {
 			c.Status(http.StatusBadRequest)
@@ -248,6 +250,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	})
 
 	r.GET("/api/strategies/single", s.listStrategies)
+	r.GET("/api/exposure", s.getExposure)
 	r.NoRoute(s.assetsHandler)
 	return r
 }
@@ -287,6 +290,7 @@ func (s *Server) listClosedOrders(c *gin.Context) {
 
 	exchange := c.Query("exchange")
 	symbol := c.Query("symbol")
+	strategyInstanceID := c.Query("strategyInstanceID")
 	gidStr := c.DefaultQuery("gid", "0")
 
 	lastGID, err := strconv.ParseInt(gidStr, 10, 64)
@@ -297,10 +301,11 @@ func (s *Server) listClosedOrders(c *gin.Context) {
 	}
 
 	orders, err := s.Environ.OrderService.Query(service.QueryOrdersOptions{
-		Exchange: types.ExchangeName(exchange),
-		Symbol:   symbol,
-		LastGID:  lastGID,
-		Ordering: "DESC",
+		Exchange:           types.ExchangeName(exchange),
+		Symbol:             symbol,
+		LastGID:            lastGID,
+		Ordering:           "DESC",
+		StrategyInstanceID: strategyInstanceID,
 	})
 	if err != nil {
 		c.Status(http.StatusBadRequest)
@@ -480,6 +485,15 @@ func (s *Server) listAssets(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"assets": totalAssets})
 }
 
+func (s *Server) getExposure(c *gin.Context) {
+	if s.Trader == nil || s.Trader.ExposureManager() == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "exposure manager is not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exposure": s.Trader.ExposureManager().Report()})
+}
+
 func (s *Server) setupSaveConfig(c *gin.Context) {
 	if len(s.Config.Sessions) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "session is not configured"})
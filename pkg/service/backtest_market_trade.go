@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/batch"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SyncMarketTrades synchronizes the public market trades of the symbol into the market_trades table for the trade replay back-testing
+func (s *BacktestService) SyncMarketTrades(ctx context.Context, exchange types.Exchange, symbol string, startTime, endTime time.Time) error {
+	marketTradeService, ok := exchange.(types.ExchangeMarketTradeService)
+	if !ok {
+		return fmt.Errorf("exchange %s does not support querying market trades", exchange.Name())
+	}
+
+	log.Infof("synchronizing %s %s market trades: %s <=> %s", exchange.Name(), symbol, startTime, endTime)
+
+	tasks := []SyncTask{
+		{
+			Type:   types.Trade{},
+			Select: SelectLastMarketTrades(exchange.Name(), symbol, startTime, endTime, 100),
+			Time: func(obj interface{}) time.Time {
+				return obj.(types.Trade).Time.Time()
+			},
+			ID: func(obj interface{}) string {
+				trade := obj.(types.Trade)
+				return strconv.FormatUint(trade.ID, 10)
+			},
+			BatchQuery: func(ctx context.Context, startTime, endTime time.Time) (interface{}, chan error) {
+				q := &batch.MarketTradeBatchQuery{ExchangeMarketTradeService: marketTradeService}
+				return q.Query(ctx, symbol, startTime, endTime)
+			},
+			BatchInsertBuffer: 1000,
+			BatchInsert: func(obj interface{}) error {
+				trades := obj.([]types.Trade)
+				return s.BatchInsertMarketTrades(trades)
+			},
+			LogInsert: log.GetLevel() == log.DebugLevel,
+		},
+	}
+
+	for _, sel := range tasks {
+		if err := sel.execute(ctx, s.DB, startTime, endTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BatchInsertMarketTrades inserts the market trades, the exchange field of the trades should not be empty
+func (s *BacktestService) BatchInsertMarketTrades(trades []types.Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	sql := "INSERT INTO `market_trades` (`exchange`, `id`, `symbol`, `price`, `quantity`, `quote_quantity`, `side`, `is_buyer`, `is_maker`, `traded_at`)" +
+		" VALUES (:exchange, :id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :traded_at)"
+
+	tx := s.DB.MustBegin()
+	if _, err := tx.NamedExec(sql, trades); err != nil {
+		if e := tx.Rollback(); e != nil {
+			log.WithError(e).Fatalf("cannot rollback insertion %v", err)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// QueryMarketTradesCh queries the market trades of the symbols in time order for the trade replay back-testing
+func (s *BacktestService) QueryMarketTradesCh(since, until time.Time, exchange types.ExchangeName, symbols []string) (chan types.Trade, chan error) {
+	if len(symbols) == 0 {
+		return returnTradeError(errors.Errorf("symbols is empty when querying market trades, plesae check your strategy setting. "))
+	}
+
+	query := "SELECT `gid`, `exchange`, `id`, `symbol`, `price`, `quantity`, `quote_quantity`, `side`, `is_buyer`, `is_maker`, `traded_at` FROM `market_trades`" +
+		" WHERE `exchange` = :exchange AND `traded_at` BETWEEN :since AND :until AND `symbol` IN (:symbols) ORDER BY traded_at ASC, id ASC"
+
+	sql, args, err := sqlx.Named(query, map[string]interface{}{
+		"exchange": exchange.String(),
+		"since":    since,
+		"until":    until,
+		"symbols":  symbols,
+	})
+	if err != nil {
+		return returnTradeError(err)
+	}
+
+	sql, args, err = sqlx.In(sql, args...)
+	if err != nil {
+		return returnTradeError(err)
+	}
+	sql = s.DB.Rebind(sql)
+
+	rows, err := s.DB.Queryx(sql, args...)
+	if err != nil {
+		return returnTradeError(err)
+	}
+
+	ch := make(chan types.Trade, 1000)
+	errC := make(chan error, 1)
+
+	go func() {
+		defer close(errC)
+		defer close(ch)
+		defer rows.Close()
+
+		for rows.Next() {
+			var trade types.Trade
+			if err := rows.StructScan(&trade); err != nil {
+				errC <- err
+				return
+			}
+
+			ch <- trade
+		}
+
+		if err := rows.Err(); err != nil {
+			errC <- err
+			return
+		}
+	}()
+
+	return ch, errC
+}
+
+func returnTradeError(err error) (chan types.Trade, chan error) {
+	ch := make(chan types.Trade)
+	close(ch)
+	log.WithError(err).Error("backtest query error")
+
+	errC := make(chan error, 1)
+	errC <- err
+	close(errC)
+	return ch, errC
+}
+
+func SelectLastMarketTrades(ex types.ExchangeName, symbol string, startTime, endTime time.Time, limit uint64) sq.SelectBuilder {
+	return sq.Select("gid", "exchange", "id", "symbol", "price", "quantity", "quote_quantity", "side", "is_buyer", "is_maker", "traded_at").
+		From("market_trades").
+		Where(sq.And{
+			sq.Eq{"exchange": ex},
+			sq.Eq{"symbol": symbol},
+			sq.Expr("traded_at BETWEEN ? AND ?", startTime, endTime),
+		}).
+		OrderBy("traded_at DESC").
+		Limit(limit)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestBacktestService_MarketTrades(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db.DB, "sqlite3")
+	service := &BacktestService{DB: dbx}
+
+	t1 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	var trades []types.Trade
+	for i, symbol := range []string{"BTCUSDT", "ETHUSDT", "BTCUSDT"} {
+		trades = append(trades, types.Trade{
+			ID:            uint64(i + 1),
+			Exchange:      types.ExchangeBinance,
+			Symbol:        symbol,
+			Price:         fixedpoint.NewFromInt(20000),
+			Quantity:      fixedpoint.NewFromFloat(0.1),
+			QuoteQuantity: fixedpoint.NewFromInt(2000),
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			Time:          types.Time(t1.Add(time.Duration(i) * time.Second)),
+		})
+	}
+
+	err = service.BatchInsertMarketTrades(trades)
+	assert.NoError(t, err)
+
+	tradeC, errC := service.QueryMarketTradesCh(t1, t1.Add(time.Minute), types.ExchangeBinance, []string{"BTCUSDT"})
+
+	var replayed []types.Trade
+	for trade := range tradeC {
+		replayed = append(replayed, trade)
+	}
+	assert.NoError(t, <-errC)
+
+	if assert.Len(t, replayed, 2) {
+		assert.Equal(t, uint64(1), replayed[0].ID)
+		assert.Equal(t, uint64(3), replayed[1].ID)
+		assert.Equal(t, "0.1", replayed[1].Quantity.String())
+		assert.Equal(t, types.SideTypeBuy, replayed[1].Side)
+	}
+}
@@ -109,15 +109,19 @@ type QueryOrdersOptions struct {
 	Symbol   string
 	LastGID  int64
 	Ordering string
+
+	// StrategyInstanceID filters the orders submitted by the strategy instance via the client order IDs
+	StrategyInstanceID string
 }
 
 func (s *OrderService) Query(options QueryOrdersOptions) ([]AggOrder, error) {
 	sql := genOrderSQL(options)
 
 	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
-		"exchange": options.Exchange,
-		"symbol":   options.Symbol,
-		"gid":      options.LastGID,
+		"exchange":     options.Exchange,
+		"symbol":       options.Symbol,
+		"gid":          options.LastGID,
+		"strategy_tag": "%" + types.StrategyTag(options.StrategyInstanceID),
 	})
 	if err != nil {
 		return nil, err
@@ -153,6 +157,9 @@ func genOrderSQL(options QueryOrdersOptions) string {
 	if len(options.Symbol) > 0 {
 		where = append(where, "symbol = :symbol")
 	}
+	if len(options.StrategyInstanceID) > 0 {
+		where = append(where, "orders.client_order_id LIKE :strategy_tag")
+	}
 
 	sql := `SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders` +
 		` LEFT JOIN trades AS t ON (t.order_id = orders.order_id)`
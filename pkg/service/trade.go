@@ -25,6 +25,9 @@ type QueryTradesOptions struct {
 	LastGID  int64
 	Since    *time.Time
 
+	// StrategyInstanceID filters the trades of the orders submitted by the strategy instance via the client order IDs
+	StrategyInstanceID string
+
 	// ASC or DESC
 	Ordering string
 	Limit    uint64
@@ -287,6 +290,11 @@ func (s *TradeService) Query(options QueryTradesOptions) ([]types.Trade, error)
 		sel = sel.Where(sq.Eq{"exchange": options.Sessions})
 	}
 
+	if options.StrategyInstanceID != "" {
+		sel = sel.Where(sq.Expr("EXISTS (SELECT 1 FROM orders WHERE orders.exchange = trades.exchange AND orders.order_id = trades.order_id AND orders.client_order_id LIKE ?)",
+			"%"+types.StrategyTag(options.StrategyInstanceID)))
+	}
+
 	if options.Ordering != "" {
 		sel = sel.OrderBy("traded_at " + options.Ordering)
 	} else {
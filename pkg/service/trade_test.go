@@ -37,6 +37,73 @@ func Test_tradeService(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_tradeService_QueryStrategyInstance(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	orderService := &OrderService{DB: xdb}
+	tradeService := &TradeService{DB: xdb}
+
+	now := time.Now()
+	for orderID, clientOrderID := range map[uint64]string{
+		1: "x-NSUYEBKM" + types.NewStrategyClientOrderID("grid:BTCUSDT"),
+		2: types.NewStrategyClientOrderID("bollmaker:BTCUSDT"),
+		3: "manual",
+	} {
+		err = orderService.Insert(types.Order{
+			SubmitOrder: types.SubmitOrder{
+				ClientOrderID: clientOrderID,
+				Symbol:        "BTCUSDT",
+				Side:          types.SideTypeBuy,
+				Type:          types.OrderTypeLimit,
+				Quantity:      fixedpoint.NewFromFloat(0.1),
+				Price:         fixedpoint.NewFromInt(1000),
+			},
+			Exchange:     "binance",
+			OrderID:      orderID,
+			Status:       types.OrderStatusFilled,
+			CreationTime: types.Time(now),
+			UpdateTime:   types.Time(now),
+		})
+		assert.NoError(t, err)
+
+		err = tradeService.Insert(types.Trade{
+			ID:            orderID,
+			OrderID:       orderID,
+			Exchange:      "binance",
+			Price:         fixedpoint.NewFromInt(1000),
+			Quantity:      fixedpoint.NewFromFloat(0.1),
+			QuoteQuantity: fixedpoint.NewFromFloat(1000.0 * 0.1),
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			Time:          types.Time(now),
+		})
+		assert.NoError(t, err)
+	}
+
+	trades, err := tradeService.Query(QueryTradesOptions{Symbol: "BTCUSDT", StrategyInstanceID: "grid:BTCUSDT"})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, uint64(1), trades[0].OrderID)
+	}
+
+	trades, err = tradeService.Query(QueryTradesOptions{Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+	assert.Len(t, trades, 3)
+
+	orders, err := orderService.Query(QueryOrdersOptions{StrategyInstanceID: "bollmaker:BTCUSDT"})
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, uint64(2), orders[0].OrderID)
+	}
+}
+
 func Test_queryTradingVolumeSQL(t *testing.T) {
 	t.Run("group by different period", func(t *testing.T) {
 		o := TradingVolumeQueryOptions{
@@ -1 +1,45 @@
 package statistics
+
+import (
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Sortino: Calculates the sortino ratio of excess returns,
+// the downside deviation is the root mean square of the negative returns
+//
+// @param periods (int): Freq. of returns (252/365 for daily, 12 for monthy)
+// @param annualize (bool): return annualize sortino?
+// @param smart (bool): return smart sortino ratio
+func Sortino(returns types.Series, periods int, annualize bool, smart bool) float64 {
+	data := returns
+	num := data.Length()
+	if types.Lowest(data, num) >= 0 && types.Highest(data, num) > 1 {
+		data = types.PercentageChange(returns)
+		num = data.Length()
+	}
+
+	var sum = 0.
+	for i := 0; i < num; i++ {
+		if r := data.Index(i); r < 0 {
+			sum += r * r
+		}
+	}
+
+	divisor := math.Sqrt(sum / float64(num))
+	if smart {
+		sum := 0.
+		coef := math.Abs(types.Correlation(data, types.Shift(data, 1), num-1))
+		for i := 1; i < num; i++ {
+			sum += float64(num-i) / float64(num) * math.Pow(coef, float64(i))
+		}
+		divisor = divisor * math.Sqrt(1.+2.*sum)
+	}
+
+	result := types.Mean(data) / divisor
+	if annualize {
+		return result * math.Sqrt(float64(periods))
+	}
+	return result
+}
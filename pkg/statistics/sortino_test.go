@@ -0,0 +1,26 @@
+package statistics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+/*
+python
+
+import quantstats as qx
+import pandas as pd
+
+print(qx.stats.sortino(pd.Series([0.01, -0.03, 0.1, -0.02, 0.001]), 0, 252, False, False))
+print(qx.stats.sortino(pd.Series([0.01, -0.03, 0.1, -0.02, 0.001]), 0, 252, True, False))
+*/
+func TestSortino(t *testing.T) {
+	var a types.Series = &types.Float64Slice{0.01, -0.03, 0.1, -0.02, 0.001}
+	output := Sortino(a, 252, false, false)
+	assert.InDelta(t, output, 0.75661, 0.0001)
+	output = Sortino(a, 252, true, false)
+	assert.InDelta(t, output, 12.01084, 0.0001)
+}
@@ -20,7 +20,7 @@ type PositionMap map[string]Position
 type IsolatedMarginAssetMap map[string]IsolatedMarginAsset
 type MarginAssetMap map[string]MarginUserAsset
 type FuturesAssetMap map[string]FuturesUserAsset
-type FuturesPositionMap map[string]FuturesPosition
+type FuturesPositionMap map[string]*FuturesPosition
 
 type AccountType string
 
@@ -205,6 +205,61 @@ func (a *Account) LockBalance(currency string, locked fixedpoint.Value) error {
 	return fmt.Errorf("insufficient available balance %s for lock: want to lock %v, available %v", currency, locked, balance.Available)
 }
 
+// BorrowBalance adds the borrowed fund to the available balance and records it as the debt
+func (a *Account) BorrowBalance(currency string, borrowed fixedpoint.Value) {
+	a.Lock()
+	defer a.Unlock()
+
+	balance, ok := a.balances[currency]
+	if !ok {
+		balance = Balance{Currency: currency}
+	}
+
+	balance.Available = balance.Available.Add(borrowed)
+	balance.Borrowed = balance.Borrowed.Add(borrowed)
+	a.balances[currency] = balance
+}
+
+// RepayBalance repays the debt from the available balance, the interest is repaid before the borrowed fund
+func (a *Account) RepayBalance(currency string, repaid fixedpoint.Value) error {
+	a.Lock()
+	defer a.Unlock()
+
+	balance, ok := a.balances[currency]
+	if !ok {
+		return fmt.Errorf("trying to repay inexisted balance: %s", currency)
+	}
+
+	if balance.Available.Compare(repaid) < 0 {
+		return fmt.Errorf("insufficient available balance %s for repay: want to repay %v, available %v", currency, repaid, balance.Available)
+	}
+
+	if balance.Borrowed.Add(balance.Interest).Compare(repaid) < 0 {
+		return fmt.Errorf("trying to repay more than the debt %s: borrowed %v, interest %v < want to repay %v", currency, balance.Borrowed, balance.Interest, repaid)
+	}
+
+	interest := fixedpoint.Min(balance.Interest, repaid)
+	balance.Interest = balance.Interest.Sub(interest)
+	balance.Borrowed = balance.Borrowed.Sub(repaid.Sub(interest))
+	balance.Available = balance.Available.Sub(repaid)
+	a.balances[currency] = balance
+	return nil
+}
+
+// AddInterest adds the accrued interest to the debt of the balance
+func (a *Account) AddInterest(currency string, interest fixedpoint.Value) {
+	a.Lock()
+	defer a.Unlock()
+
+	balance, ok := a.balances[currency]
+	if !ok {
+		balance = Balance{Currency: currency}
+	}
+
+	balance.Interest = balance.Interest.Add(interest)
+	a.balances[currency] = balance
+}
+
 func (a *Account) UpdateBalances(balances BalanceMap) {
 	a.Lock()
 	defer a.Unlock()
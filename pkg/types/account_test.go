@@ -60,3 +60,30 @@ func TestAccountLockAndUse(t *testing.T) {
 	assert.Equal(t, balance.Available, fixedpoint.NewFromInt(900))
 	assert.Equal(t, balance.Locked, fixedpoint.Zero)
 }
+
+func TestAccountBorrowAndRepay(t *testing.T) {
+	a := NewAccount()
+	a.AddBalance("USDT", fixedpoint.NewFromInt(1000))
+
+	a.BorrowBalance("BTC", fixedpoint.NewFromInt(2))
+	a.AddInterest("BTC", fixedpoint.MustNewFromString("0.01"))
+
+	balance, ok := a.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "2", balance.Available.String())
+	assert.Equal(t, "2", balance.Borrowed.String())
+	assert.Equal(t, "-0.01", balance.Net().String())
+
+	// the interest is repaid first
+	err := a.RepayBalance("BTC", fixedpoint.NewFromInt(1))
+	assert.NoError(t, err)
+
+	balance, ok = a.Balance("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "1", balance.Available.String())
+	assert.Equal(t, "1.01", balance.Borrowed.String())
+	assert.Equal(t, "0", balance.Interest.String())
+
+	err = a.RepayBalance("BTC", fixedpoint.NewFromInt(2))
+	assert.Error(t, err)
+}
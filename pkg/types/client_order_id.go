@@ -0,0 +1,41 @@
+package types
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// StrategyTagLength is the length of the strategy tag at the end of the client order IDs
+const StrategyTagLength = 8
+
+// strategyClientOrderIDLength is the length of the generated client order IDs, it fits the 32 characters limit with the
+// broker prefixes of the exchanges, and it only uses the alphanumeric characters for the exchanges like OKEx.
+const strategyClientOrderIDLength = 22
+
+// StrategyTag returns the short tag of the strategy instance ID, the tag is the hex encoded FNV-32a hash of the ID,
+// so that it can be derived from the strategy instance ID after a restart or in another process.
+func StrategyTag(strategyInstanceID string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strategyInstanceID))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewStrategyClientOrderID returns a new client order ID that encodes the strategy instance ID, the format is the random
+// hex characters followed by the strategy tag. The exchange adapters only add the broker prefix to it, so the tag is
+// always kept at the end of the client order ID.
+func NewStrategyClientOrderID(strategyInstanceID string) string {
+	random := strings.ReplaceAll(uuid.New().String(), "-", "")
+	return random[:strategyClientOrderIDLength-StrategyTagLength] + StrategyTag(strategyInstanceID)
+}
+
+// IsStrategyClientOrderID checks if the client order ID is generated for the strategy instance
+func IsStrategyClientOrderID(clientOrderID, strategyInstanceID string) bool {
+	if len(strategyInstanceID) == 0 || len(clientOrderID) < strategyClientOrderIDLength {
+		return false
+	}
+
+	return strings.HasSuffix(clientOrderID, StrategyTag(strategyInstanceID))
+}
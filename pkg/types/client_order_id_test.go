@@ -0,0 +1,23 @@
+package types
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStrategyClientOrderID(t *testing.T) {
+	clientOrderID := NewStrategyClientOrderID("bollmaker:BTCUSDT:1m")
+	assert.Len(t, clientOrderID, 22)
+	assert.Regexp(t, regexp.MustCompile("^[0-9a-f]+$"), clientOrderID)
+	assert.NotEqual(t, clientOrderID, NewStrategyClientOrderID("bollmaker:BTCUSDT:1m"))
+
+	assert.True(t, IsStrategyClientOrderID(clientOrderID, "bollmaker:BTCUSDT:1m"))
+	assert.False(t, IsStrategyClientOrderID(clientOrderID, "bollmaker:ETHUSDT:1m"))
+	assert.False(t, IsStrategyClientOrderID(clientOrderID, ""))
+
+	// the broker prefix of the exchange adapters is kept at the beginning
+	assert.True(t, IsStrategyClientOrderID("x-NSUYEBKM"+clientOrderID, "bollmaker:BTCUSDT:1m"))
+	assert.False(t, IsStrategyClientOrderID(StrategyTag("bollmaker:BTCUSDT:1m"), "bollmaker:BTCUSDT:1m"))
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
@@ -26,13 +28,13 @@ func (n *ExchangeName) UnmarshalJSON(data []byte) error {
 	}
 
 	switch s {
-	case "max", "binance", "ftx", "okex":
+	case "max", "binance", "ftx", "okex", "kucoin", "bybit", "coinbase", "replay":
 		*n = ExchangeName(s)
 		return nil
 
 	}
 
-	return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: max, binance, ftx", s)
+	return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: max, binance, ftx, okex, kucoin, bybit, coinbase", s)
 }
 
 func (n ExchangeName) String() string {
@@ -45,7 +47,12 @@ const (
 	ExchangeFTX      ExchangeName = "ftx"
 	ExchangeOKEx     ExchangeName = "okex"
 	ExchangeKucoin   ExchangeName = "kucoin"
+	ExchangeBybit    ExchangeName = "bybit"
+	ExchangeCoinbase ExchangeName = "coinbase"
 	ExchangeBacktest ExchangeName = "backtest"
+
+	// ExchangeReplay replays the exchange API calls and the websocket messages recorded in the cassette file
+	ExchangeReplay ExchangeName = "replay"
 )
 
 var SupportedExchanges = []ExchangeName{
@@ -54,6 +61,8 @@ var SupportedExchanges = []ExchangeName{
 	ExchangeFTX,
 	ExchangeOKEx,
 	ExchangeKucoin,
+	ExchangeBybit,
+	ExchangeCoinbase,
 	// note: we are not using "backtest"
 }
 
@@ -69,6 +78,12 @@ func ValidExchangeName(a string) (ExchangeName, error) {
 		return ExchangeOKEx, nil
 	case "kucoin":
 		return ExchangeKucoin, nil
+	case "bybit":
+		return ExchangeBybit, nil
+	case "coinbase", "cb":
+		return ExchangeCoinbase, nil
+	case "replay":
+		return ExchangeReplay, nil
 	}
 
 	return "", fmt.Errorf("invalid exchange name: %s", a)
@@ -90,6 +105,18 @@ type ExchangeOrderQueryService interface {
 	QueryOrder(ctx context.Context, q OrderQuery) (*Order, error)
 }
 
+// ErrOrderReplaceNotSupported is returned by ReplaceOrder when the exchange can not replace the order natively,
+// e.g., the cancel-replace endpoint is only provided for the spot orders.
+var ErrOrderReplaceNotSupported = errors.New("order replace is not supported")
+
+// ExchangeOrderReplaceService replaces an open order via the native amend or cancel-replace endpoint of the exchange.
+// The created order keeps the order ID if the exchange amends the order in place, and the canceled order is nil.
+// Otherwise, the canceled order is the final state of the replaced order, its executed quantity includes the quantity
+// filled before the cancellation, which is not deducted from the quantity of the created order.
+type ExchangeOrderReplaceService interface {
+	ReplaceOrder(ctx context.Context, order Order, newOrder SubmitOrder) (canceledOrder, createdOrder *Order, err error)
+}
+
 type ExchangeTradeService interface {
 	QueryAccount(ctx context.Context) (*Account, error)
 
@@ -111,6 +138,16 @@ type ExchangeTradeHistoryService interface {
 	QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []Order, err error)
 }
 
+// ExchangeMarketTradeService provides the public market trades (the trades of all the users) for the trade replay back-testing
+type ExchangeMarketTradeService interface {
+	QueryMarketTrades(ctx context.Context, symbol string, options *TradeQueryOptions) ([]Trade, error)
+}
+
+// ExchangeDepthService provides the order book snapshot of the symbol
+type ExchangeDepthService interface {
+	QueryDepth(ctx context.Context, symbol string) (snapshot SliceOrderBook, finalUpdateID int64, err error)
+}
+
 type ExchangeMarketDataService interface {
 	NewStream() Stream
 
@@ -14,6 +14,10 @@ func ExchangeFooterIcon(exName ExchangeName) string {
 		footerIcon = "https://static.okex.com/cdn/assets/imgs/MjAxODg/D91A7323087D31A588E0D2A379DD7747.png"
 	case ExchangeKucoin:
 		footerIcon = "https://assets.staticimg.com/cms/media/7AV75b9jzr9S8H3eNuOuoqj8PwdUjaDQGKGczGqTS.png"
+	case ExchangeBybit:
+		footerIcon = "https://www.bybit.com/favicon.ico"
+	case ExchangeCoinbase:
+		footerIcon = "https://www.coinbase.com/favicon.ico"
 	}
 
 	return footerIcon
@@ -7,7 +7,7 @@ import (
 )
 
 type FundingRate struct {
-	FundingRate fixedpoint.Value
-	FundingTime time.Time
-	Time        time.Time
+	FundingRate fixedpoint.Value `json:"fundingRate"`
+	FundingTime time.Time        `json:"fundingTime"`
+	Time        time.Time        `json:"time"`
 }
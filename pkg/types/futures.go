@@ -0,0 +1,39 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type MarginType string
+
+const (
+	MarginTypeIsolated = MarginType("isolated")
+	MarginTypeCross    = MarginType("cross")
+)
+
+// FuturesService provides the position and the position setting actions of a futures exchange
+type FuturesService interface {
+	// QueryFuturesPositions returns the non-zero positions of the futures account
+	QueryFuturesPositions(ctx context.Context) (FuturesPositionMap, error)
+
+	SetFuturesLeverage(ctx context.Context, symbol string, leverage int) error
+
+	SetFuturesMarginType(ctx context.Context, symbol string, marginType MarginType) error
+}
+
+// FundingFee is the funding fee income of the futures position, a negative amount is the funding fee paid
+type FundingFee struct {
+	Exchange      ExchangeName     `json:"exchange"`
+	Symbol        string           `json:"symbol"`
+	Asset         string           `json:"asset"`
+	Amount        fixedpoint.Value `json:"amount"`
+	TransactionID int64            `json:"transactionID"`
+	Time          Time             `json:"time"`
+}
+
+type FuturesFundingFeeService interface {
+	QueryFundingFeeHistory(ctx context.Context, symbol string, since, until time.Time) ([]FundingFee, error)
+}
@@ -24,6 +24,20 @@ func (d *Duration) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	return d.set(o)
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var o interface{}
+
+	if err := unmarshal(&o); err != nil {
+		return err
+	}
+
+	return d.set(o)
+}
+
+func (d *Duration) set(o interface{}) error {
 	switch t := o.(type) {
 	case string:
 		dd, err := time.ParseDuration(t)
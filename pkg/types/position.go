@@ -27,6 +27,8 @@ type ExchangeFee struct {
 type PositionRisk struct {
 	Leverage         fixedpoint.Value `json:"leverage"`
 	LiquidationPrice fixedpoint.Value `json:"liquidationPrice"`
+	MarkPrice        fixedpoint.Value `json:"markPrice,omitempty"`
+	UnrealizedProfit fixedpoint.Value `json:"unrealizedProfit,omitempty"`
 }
 
 type Position struct {
@@ -253,6 +255,20 @@ func (p *Position) Reset() {
 	p.AverageCost = fixedpoint.Zero
 }
 
+// SyncFuturesPosition overwrites the base, quote and average cost by the position maintained by the futures exchange
+func (p *Position) SyncFuturesPosition(fp *FuturesPosition) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.Base = fp.Base
+	p.AverageCost = fp.AverageCost
+	p.ApproximateAverageCost = fp.AverageCost
+	p.Quote = fp.Base.Neg().Mul(fp.AverageCost)
+	if fp.UpdateTime > 0 {
+		p.ChangedAt = time.UnixMilli(fp.UpdateTime)
+	}
+}
+
 func (p *Position) SetFeeRate(exchangeFee ExchangeFee) {
 	p.FeeRate = &exchangeFee
 }
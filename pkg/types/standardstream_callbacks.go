@@ -34,6 +34,16 @@ func (s *StandardStream) EmitDisconnect() {
 	}
 }
 
+func (s *StandardStream) OnRawMessage(cb func(raw []byte)) {
+	s.rawMessageCallbacks = append(s.rawMessageCallbacks, cb)
+}
+
+func (s *StandardStream) EmitRawMessage(raw []byte) {
+	for _, cb := range s.rawMessageCallbacks {
+		cb(raw)
+	}
+}
+
 func (s *StandardStream) OnTradeUpdate(cb func(trade Trade)) {
 	s.tradeUpdateCallbacks = append(s.tradeUpdateCallbacks, cb)
 }
@@ -161,6 +171,8 @@ type StandardStreamEventHub interface {
 
 	OnDisconnect(cb func())
 
+	OnRawMessage(cb func(raw []byte))
+
 	OnTradeUpdate(cb func(trade Trade))
 
 	OnOrderUpdate(cb func(order Order))
@@ -35,6 +35,11 @@ type Stream interface {
 	Close() error
 }
 
+// Reconnector is implemented by the streams that can drop the current connection and connect again
+type Reconnector interface {
+	Reconnect()
+}
+
 type EndpointCreator func(ctx context.Context) (string, error)
 
 type Parser func(message []byte) (interface{}, error)
@@ -77,6 +82,9 @@ type StandardStream struct {
 
 	disconnectCallbacks []func()
 
+	// rawMessageCallbacks receive the raw websocket messages before they are parsed
+	rawMessageCallbacks []func(raw []byte)
+
 	// private trade update callbacks
 	tradeUpdateCallbacks []func(trade Trade)
 
@@ -195,6 +203,12 @@ func (s *StandardStream) Read(ctx context.Context, conn *websocket.Conn, cancel
 
 			mt, message, err := conn.ReadMessage()
 			if err != nil {
+				// the connection is dropped by the re-connector or the stream is closed,
+				// so we should not re-connect again.
+				if ctx.Err() != nil {
+					return
+				}
+
 				// if it's a network timeout error, we should re-connect
 				switch err := err.(type) {
 
@@ -235,6 +249,8 @@ func (s *StandardStream) Read(ctx context.Context, conn *websocket.Conn, cancel
 				log.Info(string(message))
 			}
 
+			s.EmitRawMessage(message)
+
 			var e interface{}
 			if s.parser != nil {
 				e, err = s.parser(message)
@@ -323,6 +339,9 @@ func (s *StandardStream) reconnector(ctx context.Context) {
 			return
 
 		case <-s.ReconnectC:
+			// drop the current connection first, so that the disconnect event is emitted before the new connection is created
+			s.closeConn()
+
 			log.Warnf("received reconnect signal, cooling for %s...", reconnectCoolDownPeriod)
 			time.Sleep(reconnectCoolDownPeriod)
 
@@ -337,6 +356,21 @@ func (s *StandardStream) reconnector(ctx context.Context) {
 	}
 }
 
+// closeConn cancels the context of the current connection and closes the connection,
+// the reader and the ping worker of the connection will be stopped.
+func (s *StandardStream) closeConn() {
+	s.ConnLock.Lock()
+	defer s.ConnLock.Unlock()
+
+	if s.ConnCancel != nil {
+		s.ConnCancel()
+	}
+
+	if s.Conn != nil {
+		_ = s.Conn.Close()
+	}
+}
+
 func (s *StandardStream) DialAndConnect(ctx context.Context) error {
 	conn, err := s.Dial(ctx)
 	if err != nil {